@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net/http"
 	"os"
@@ -12,8 +13,34 @@ import (
 	_ "github.com/joho/godotenv/autoload"
 	"github.com/zde37/Numeris-Task/internal/config"
 	"github.com/zde37/Numeris-Task/internal/controller"
+	"github.com/zde37/Numeris-Task/internal/encryption"
+	"github.com/zde37/Numeris-Task/internal/exchangerate"
+	"github.com/zde37/Numeris-Task/internal/gateway"
+	"github.com/zde37/Numeris-Task/internal/mailer"
 	"github.com/zde37/Numeris-Task/internal/repository"
 	"github.com/zde37/Numeris-Task/internal/service"
+	"github.com/zde37/Numeris-Task/internal/webhook"
+	"github.com/zde37/Numeris-Task/internal/worker"
+)
+
+const (
+	// reminderInterval is how often the background worker looks for invoices that need a payment reminder.
+	reminderInterval = time.Hour
+	// overdueInterval is how often the background worker marks pending invoices past their due date as overdue.
+	overdueInterval = time.Hour
+	// lateFeeInterval is how often the background worker brings late fees and interest on overdue invoices up to date.
+	lateFeeInterval = time.Hour
+	// exchangeRateInterval is how often the background worker refreshes the stored exchange rates.
+	exchangeRateInterval = 24 * time.Hour
+	// eventListenRetryInterval is how often the background worker listens for new activity events again after its
+	// connection to the database failed.
+	eventListenRetryInterval = 5 * time.Second
+	// webhookInterval is how often the background worker queues deliveries of new events to webhooks and sends the
+	// deliveries that are due.
+	webhookInterval = 30 * time.Second
+	// refundInterval is how often the background worker sends the refunds whose outcome is not known yet to the payment
+	// provider again.
+	refundInterval = 5 * time.Minute
 )
 
 func main() {
@@ -24,10 +51,25 @@ func main() {
 
 // run is the main entry point for the application. It sets up the application configuration,
 // initializes the database connection, creates the service and handler instances, starts the
-// HTTP server, and handles the graceful shutdown of the server.
+// background workers and the HTTP server, and handles the graceful shutdown of the server.
 func run() error {
 	cfg := config.Load(os.Getenv("ENVIRONMENT"), os.Getenv("HTTP_SERVER_ADDRESS"),
 		os.Getenv("DSN"))
+	mailCfg := config.LoadMailer(os.Getenv("SMTP_HOST"), os.Getenv("SMTP_PORT"), os.Getenv("SMTP_USERNAME"),
+		os.Getenv("SMTP_PASSWORD"), os.Getenv("SMTP_FROM"))
+	encryptionCfg := config.LoadEncryption(os.Getenv("ENCRYPTION_KEYS"), os.Getenv("ENCRYPTION_KEY_ID"))
+	paymentCfg := config.LoadPayment(os.Getenv("PAYMENT_PROVIDER"), os.Getenv("PAYMENT_CALLBACK_SECRET"),
+		os.Getenv("PAYMENT_CHECKOUT_BASE_URL"))
+
+	keyring, err := encryption.ParseKeyring(encryptionCfg.ActiveKeyID, encryptionCfg.Keys)
+	if err != nil {
+		return err
+	}
+
+	payments, err := gateway.New(paymentCfg)
+	if err != nil {
+		return err
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -38,10 +80,105 @@ func run() error {
 	}
 	defer dbPool.Close()
 
-	repo := repository.NewRepository(dbPool)
-	srvc := service.NewService(repo)
+	var rates exchangerate.Source
+	if ratesFile := os.Getenv("EXCHANGE_RATES_FILE"); ratesFile != "" {
+		rates = exchangerate.NewFileSource(ratesFile)
+	}
+
+	repo := repository.NewRepository(dbPool, keyring)
+	srvc := service.NewService(repo, mailer.New(mailCfg), rates, payments, webhook.NewSender())
 	hndl := controller.NewHandlerImpl(cfg.Environment, srvc)
 
+	jobs := []worker.Job{
+		{
+			Name:     "payment reminders",
+			Interval: reminderInterval,
+			Run: func(ctx context.Context) error {
+				sent, err := srvc.Reminder.SendDueReminders(ctx, time.Now())
+				if sent > 0 {
+					log.Printf("sent %d payment reminders", sent)
+				}
+				return err
+			},
+		},
+		{
+			Name:     "overdue invoices",
+			Interval: overdueInterval,
+			Run: func(ctx context.Context) error {
+				marked, err := srvc.Invoice.MarkOverdueInvoices(ctx, time.Now())
+				if marked > 0 {
+					log.Printf("marked %d invoices as overdue", marked)
+				}
+				return err
+			},
+		},
+		{
+			Name:     "late fees",
+			Interval: lateFeeInterval,
+			Run: func(ctx context.Context) error {
+				applied, err := srvc.LateFee.ApplyLateFees(ctx, time.Now())
+				if applied > 0 {
+					log.Printf("applied %d late fees", applied)
+				}
+				return err
+			},
+		},
+		{
+			Name:     "event stream",
+			Interval: eventListenRetryInterval,
+			Run:      srvc.Event.Listen,
+		},
+		{
+			Name:     "webhooks",
+			Interval: webhookInterval,
+			Run: func(ctx context.Context) error {
+				queued, queueErr := srvc.Webhook.QueueWebhookDeliveries(ctx)
+				if queued > 0 {
+					log.Printf("queued %d webhook deliveries", queued)
+				}
+				delivered, err := srvc.Webhook.SendDueWebhookDeliveries(ctx, time.Now())
+				if delivered > 0 {
+					log.Printf("delivered %d webhook deliveries", delivered)
+				}
+				return errors.Join(queueErr, err)
+			},
+		},
+	}
+	if payments != nil {
+		jobs = append(jobs, worker.Job{
+			Name:     "payment refunds",
+			Interval: refundInterval,
+			Run: func(ctx context.Context) error {
+				completed, err := srvc.Payment.SendPendingRefunds(ctx, time.Now())
+				if completed > 0 {
+					log.Printf("completed %d pending refunds", completed)
+				}
+				return err
+			},
+		})
+	}
+	if rates != nil {
+		jobs = append(jobs, worker.Job{
+			Name:     "exchange rates",
+			Interval: exchangeRateInterval,
+			Run: func(ctx context.Context) error {
+				saved, err := srvc.Currency.RefreshExchangeRates(ctx)
+				if saved > 0 {
+					log.Printf("refreshed %d exchange rates", saved)
+				}
+				return err
+			},
+		})
+	}
+
+	workerCtx, stopWorkers := context.WithCancel(ctx)
+	scheduler := worker.NewScheduler(jobs...)
+	scheduler.Start(workerCtx)
+	defer func() {
+		stopWorkers()
+		scheduler.Wait()
+	}()
+
 	srv := &http.Server{
 		Addr:    cfg.HTTPServerAddr,
 		Handler: hndl.GetRouter(),
@@ -55,11 +192,12 @@ func run() error {
 		}
 	}()
 
-	return gracefulShutdown(ctx, srv)
+	return gracefulShutdown(ctx, srv, srvc.Event.Close)
 }
 
 // gracefulShutdown is a function that handles the graceful shutdown of an HTTP server. 
-func gracefulShutdown(ctx context.Context, srv *http.Server) error {
+// Event streams never end on their own, so closeStreams ends them for the server to stop waiting for their handlers.
+func gracefulShutdown(ctx context.Context, srv *http.Server, closeStreams func()) error {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
@@ -70,6 +208,7 @@ func gracefulShutdown(ctx context.Context, srv *http.Server) error {
 	defer cancel()
 
 	srv.SetKeepAlivesEnabled(false)
+	srv.RegisterOnShutdown(closeStreams)
 	if err := srv.Shutdown(ctx); err != nil {
 		return err
 	}
@@ -0,0 +1,63 @@
+// Command rotate-keys encrypts every bank account number that is not encrypted with the active encryption key with
+// it, including account numbers stored in plaintext before account numbers were encrypted. Run it after making a new
+// key active with ENCRYPTION_KEY_ID, and only remove the previous key from ENCRYPTION_KEYS once it completes.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	_ "github.com/joho/godotenv/autoload"
+	"github.com/zde37/Numeris-Task/internal/config"
+	"github.com/zde37/Numeris-Task/internal/encryption"
+	"github.com/zde37/Numeris-Task/internal/repository"
+)
+
+// rotateBatchSize is the number of account numbers encrypted per transaction.
+const rotateBatchSize = 100
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run encrypts the account numbers in batches until none is left that is not encrypted with the active key.
+func run() error {
+	cfg := config.Load(os.Getenv("ENVIRONMENT"), os.Getenv("HTTP_SERVER_ADDRESS"), os.Getenv("DSN"))
+	encryptionCfg := config.LoadEncryption(os.Getenv("ENCRYPTION_KEYS"), os.Getenv("ENCRYPTION_KEY_ID"))
+
+	keyring, err := encryption.ParseKeyring(encryptionCfg.ActiveKeyID, encryptionCfg.Keys)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	dbPool, err := config.SetupDatabase(ctx, cfg, "file://migrations")
+	if err != nil {
+		return err
+	}
+	defer dbPool.Close()
+
+	repo := repository.NewRepository(dbPool, keyring)
+	total := 0
+	for {
+		encrypted, err := repo.User.ReencryptAccountNumbers(ctx, rotateBatchSize)
+		if err != nil {
+			return err
+		}
+		if encrypted == 0 {
+			break
+		}
+		total += encrypted
+		log.Printf("encrypted %d account numbers with key %s", total, keyring.ActiveKeyID())
+	}
+
+	log.Printf("all account numbers are encrypted with key %s", keyring.ActiveKeyID())
+	return nil
+}
@@ -6,6 +6,25 @@ type Config struct {
 	DSN            string 
 }
 
+type EncryptionConfig struct {
+	Keys        string
+	ActiveKeyID string
+}
+
+type PaymentConfig struct {
+	Provider        string
+	CallbackSecret  string
+	CheckoutBaseURL string
+}
+
+type MailerConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
 // Load creates a new Config struct with the provided environment, HTTP server address and data source name.
 func Load(environment, httpServerAddr, dsn string) Config {
 	return Config{
@@ -14,3 +33,37 @@ func Load(environment, httpServerAddr, dsn string) Config {
 		DSN:            dsn, 
 	}
 }
+
+// LoadMailer creates a new MailerConfig struct with the provided SMTP host, port, credentials and sender address.
+// The port defaults to 587 when it is not provided.
+func LoadMailer(host, port, username, password, from string) MailerConfig {
+	if port == "" {
+		port = "587"
+	}
+	return MailerConfig{
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: password,
+		From:     from,
+	}
+}
+
+// LoadEncryption creates a new EncryptionConfig struct with the provided master keys, given as comma separated
+// id:base64 pairs, and the ID of the key new values are encrypted with.
+func LoadEncryption(keys, activeKeyID string) EncryptionConfig {
+	return EncryptionConfig{
+		Keys:        keys,
+		ActiveKeyID: activeKeyID,
+	}
+}
+
+// LoadPayment creates a new PaymentConfig struct with the provided payment provider name, the secret its callbacks
+// are signed with and the base URL of its checkout pages.
+func LoadPayment(provider, callbackSecret, checkoutBaseURL string) PaymentConfig {
+	return PaymentConfig{
+		Provider:        provider,
+		CallbackSecret:  callbackSecret,
+		CheckoutBaseURL: checkoutBaseURL,
+	}
+}
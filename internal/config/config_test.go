@@ -40,3 +40,36 @@ func TestLoad(t *testing.T) {
 		require.Equal(t, "mysql://root:p@ssw0rd@localhost/testdb", config.DSN) 
 	})
 }
+
+func TestLoadMailer(t *testing.T) {
+	t.Run("load mailer config with all fields", func(t *testing.T) {
+		config := LoadMailer("smtp.example.com", "2525", "user", "pass", "billing@example.com")
+
+		require.Equal(t, "smtp.example.com", config.Host)
+		require.Equal(t, "2525", config.Port)
+		require.Equal(t, "user", config.Username)
+		require.Equal(t, "pass", config.Password)
+		require.Equal(t, "billing@example.com", config.From)
+	})
+
+	t.Run("default port", func(t *testing.T) {
+		config := LoadMailer("smtp.example.com", "", "", "", "billing@example.com")
+
+		require.Equal(t, "587", config.Port)
+	})
+}
+
+func TestLoadEncryption(t *testing.T) {
+	config := LoadEncryption("2024-01:a2V5,2024-07:bmV3", "2024-07")
+
+	require.Equal(t, "2024-01:a2V5,2024-07:bmV3", config.Keys)
+	require.Equal(t, "2024-07", config.ActiveKeyID)
+}
+
+func TestLoadPayment(t *testing.T) {
+	config := LoadPayment("fake", "secret", "http://localhost:8080")
+
+	require.Equal(t, "fake", config.Provider)
+	require.Equal(t, "secret", config.CallbackSecret)
+	require.Equal(t, "http://localhost:8080", config.CheckoutBaseURL)
+}
@@ -6,13 +6,54 @@ type Handler interface {
 	HelloWorld(ctx *gin.Context)
 	CreateInvoice(ctx *gin.Context)
 	GetInvoiceDetails(ctx *gin.Context)
+	GetInvoiceDocument(ctx *gin.Context)
+	GetInvoiceQRCode(ctx *gin.Context)
 	AddInvoiceActivity(ctx *gin.Context)
 	GetTotalByStatus(ctx *gin.Context)
+	GetTotalForStatus(ctx *gin.Context)
 	GetRecentInvoices(ctx *gin.Context)
+	SearchInvoices(ctx *gin.Context)
+	ExportInvoices(ctx *gin.Context)
 	GetRecentActivities(ctx *gin.Context)
 	GetInvoiceActivities(ctx *gin.Context)
 	CreateUser(ctx *gin.Context)
 	AddPaymentMethod(ctx *gin.Context)
+	GetPaymentMethods(ctx *gin.Context)
+	UpdatePaymentMethod(ctx *gin.Context)
+	DeletePaymentMethod(ctx *gin.Context)
+	SetDefaultPaymentMethod(ctx *gin.Context)
 	AddCustomer(ctx *gin.Context)
+	AddReminderRule(ctx *gin.Context)
+	GetReminderRules(ctx *gin.Context)
+	DeleteReminderRule(ctx *gin.Context)
+	SetLateFeePolicy(ctx *gin.Context)
+	GetLateFeePolicy(ctx *gin.Context)
+	CreateTaxRate(ctx *gin.Context)
+	GetTaxRates(ctx *gin.Context)
+	DeactivateTaxRate(ctx *gin.Context)
+	GetTaxReport(ctx *gin.Context)
+	RecordPayment(ctx *gin.Context)
+	CreatePaymentLink(ctx *gin.Context)
+	HandlePaymentCallback(ctx *gin.Context)
+	RefundPayment(ctx *gin.Context)
+	ImportBankStatement(ctx *gin.Context)
+	GetReconciliationInbox(ctx *gin.Context)
+	ConfirmBankTransaction(ctx *gin.Context)
+	DismissBankTransaction(ctx *gin.Context)
+	GetExchangeRate(ctx *gin.Context)
+	GetDashboard(ctx *gin.Context)
+	GetAgingReport(ctx *gin.Context)
+	GetAgingInvoices(ctx *gin.Context)
+	GetRevenueReport(ctx *gin.Context)
+	GetCashFlowReport(ctx *gin.Context)
+	ImportCustomers(ctx *gin.Context)
+	ImportInvoices(ctx *gin.Context)
+	StreamEvents(ctx *gin.Context)
+	CreateWebhook(ctx *gin.Context)
+	GetWebhooks(ctx *gin.Context)
+	DeleteWebhook(ctx *gin.Context)
+	EnableWebhook(ctx *gin.Context)
+	GetWebhookDeliveries(ctx *gin.Context)
+	ReplayWebhookDelivery(ctx *gin.Context)
 	GetRouter() *gin.Engine 
 }
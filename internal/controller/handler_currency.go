@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+// GetExchangeRate is a handler function that retrieves the rate converting one currency into another on a given date.
+// The date defaults to today.
+func (h *handlerImpl) GetExchangeRate(ctx *gin.Context) {
+	from := strings.ToUpper(ctx.Query("from"))
+	to := strings.ToUpper(ctx.Query("to"))
+
+	on := time.Now()
+	if date := ctx.Query("date"); date != "" {
+		parsed, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "date has invalid date format"})
+			return
+		}
+		on = parsed
+	}
+
+	rate, err := h.service.Currency.GetExchangeRate(ctx, from, to, on)
+	if errors.Is(err, models.ErrExchangeRateNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, rate)
+}
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/service"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetExchangeRate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCurrencyService := mocked.NewMockCurrencyService(ctrl)
+	srv := &service.Service{
+		Currency: mockCurrencyService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	t.Run("successful retrieval", func(t *testing.T) {
+		on := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+		expectedRate := &models.ExchangeRate{
+			FromCurrency:  "USD",
+			ToCurrency:    "NGN",
+			Rate:          1480.5,
+			EffectiveDate: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			Source:        "file",
+		}
+
+		mockCurrencyService.EXPECT().
+			GetExchangeRate(gomock.Any(), "USD", "NGN", on).
+			Return(expectedRate, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/exchange-rates?from=usd&to=NGN&date=2024-06-03", nil)
+
+		handler.GetExchangeRate(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response models.ExchangeRate
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, *expectedRate, response)
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/exchange-rates?from=USD&to=NGN&date=03-06-2024", nil)
+
+		handler.GetExchangeRate(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, "date has invalid date format", response["error"])
+	})
+
+	t.Run("no exchange rate", func(t *testing.T) {
+		mockCurrencyService.EXPECT().
+			GetExchangeRate(gomock.Any(), "EUR", "NGN", gomock.Any()).
+			Return(nil, fmt.Errorf("%w from EUR to NGN on 2024-06-03", models.ErrExchangeRateNotFound))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/exchange-rates?from=EUR&to=NGN", nil)
+
+		handler.GetExchangeRate(c)
+
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		expectedError := errors.New("connection refused")
+		mockCurrencyService.EXPECT().
+			GetExchangeRate(gomock.Any(), "EUR", "NGN", gomock.Any()).
+			Return(nil, expectedError)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/exchange-rates?from=EUR&to=NGN", nil)
+
+		handler.GetExchangeRate(c)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, expectedError.Error(), response["error"])
+	})
+}
@@ -0,0 +1,25 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetDashboard is a handler function that retrieves everything the home screen of a sender shows in a single response.
+func (h *handlerImpl) GetDashboard(ctx *gin.Context) {
+	senderID, err := uuid.Parse(ctx.Query("sender_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sender ID"})
+		return
+	}
+
+	dashboard, err := h.service.Dashboard.GetDashboard(ctx, senderID, time.Now())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, dashboard)
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/service"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetDashboard(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDashboardService := mocked.NewMockDashboardService(ctrl)
+	srv := &service.Service{
+		Dashboard: mockDashboardService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	t.Run("successful retrieval", func(t *testing.T) {
+		senderID := uuid.New()
+		expectedDashboard := &models.Dashboard{
+			SenderID: senderID,
+			AsOf:     time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			Statuses: []models.StatusTotals{
+				{Status: models.InvoiceStatusPending, Count: 1, Currencies: []models.CurrencyTotal{{Currency: "NGN", Count: 1, TotalAmount: 500}}},
+			},
+			Balances: []models.OpenBalance{
+				{Currency: "NGN", InvoiceCount: 1, Outstanding: 500, DueNext7Days: 500, DueNext30Days: 500},
+			},
+			RecentInvoices:   []models.Invoice{{InvoiceID: uuid.New(), SenderID: senderID, Currency: "NGN"}},
+			RecentActivities: []models.RecentActivity{{ActivityID: uuid.New(), UserID: senderID, Title: "Invoice Creation"}},
+		}
+
+		mockDashboardService.EXPECT().
+			GetDashboard(gomock.Any(), senderID, gomock.Any()).
+			Return(expectedDashboard, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/dashboard?sender_id="+senderID.String(), nil)
+
+		handler.GetDashboard(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response models.Dashboard
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, expectedDashboard.SenderID, response.SenderID)
+		require.Equal(t, expectedDashboard.Statuses, response.Statuses)
+		require.Equal(t, expectedDashboard.Balances, response.Balances)
+		require.Len(t, response.RecentInvoices, 1)
+		require.Len(t, response.RecentActivities, 1)
+	})
+
+	t.Run("invalid sender ID", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/dashboard?sender_id=invalid-uuid", nil)
+
+		handler.GetDashboard(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, "Invalid sender ID", response["error"])
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		expectedError := errors.New("service error")
+		mockDashboardService.EXPECT().
+			GetDashboard(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, expectedError)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/dashboard?sender_id="+uuid.New().String(), nil)
+
+		handler.GetDashboard(c)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, expectedError.Error(), response["error"])
+	})
+}
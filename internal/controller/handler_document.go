@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/zde37/Numeris-Task/internal/epc"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+//go:embed templates/invoice.html
+var invoiceDocumentTemplate string
+
+// invoiceDocument renders the details of an invoice as an HTML document to send to the customer.
+var invoiceDocument = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"amount": func(amount float64) string { return strconv.FormatFloat(amount, 'f', 2, 64) },
+	"date":   func(date time.Time) string { return date.Format("2 January 2006") },
+}).Parse(invoiceDocumentTemplate))
+
+// invoiceDocumentData is what the invoice document is rendered from: the details of the invoice, and the data URI of
+// its EPC QR code image when it can be paid with one.
+type invoiceDocumentData struct {
+	*models.InvoiceDetails
+	QRCode template.URL
+}
+
+// GetInvoiceDocument is a handler function that renders an invoice as an HTML document to send to the customer.
+// Unlike the other responses, the document shows the full account number the invoice is to be paid into, and EUR
+// invoices paid into an IBAN account show the EPC QR code banking apps scan to pay them.
+func (h *handlerImpl) GetInvoiceDocument(ctx *gin.Context) {
+	invoiceID, err := uuid.Parse(ctx.Param("invoiceID"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invoice ID"})
+		return
+	}
+
+	details, err := h.service.Invoice.GetInvoiceDetails(ctx, invoiceID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	data := invoiceDocumentData{InvoiceDetails: details}
+	if transfer, err := epc.FromInvoice(details); err == nil {
+		if image, err := epc.QRCode(transfer); err == nil {
+			data.QRCode = template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(image))
+		}
+	}
+
+	var document bytes.Buffer
+	if err := invoiceDocument.Execute(&document, data); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", document.Bytes())
+}
+
+// GetInvoiceQRCode is a handler function that renders the EPC QR code of an invoice as a PNG image, which European
+// banking apps scan to fill in a SEPA credit transfer of its final amount into its payment method, with the invoice
+// number as the remittance text. Only EUR invoices paid into an IBAN account have one.
+func (h *handlerImpl) GetInvoiceQRCode(ctx *gin.Context) {
+	invoiceID, err := uuid.Parse(ctx.Param("invoiceID"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invoice ID"})
+		return
+	}
+
+	details, err := h.service.Invoice.GetInvoiceDetails(ctx, invoiceID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	transfer, err := epc.FromInvoice(details)
+	var image []byte
+	if err == nil {
+		image, err = epc.QRCode(transfer)
+	}
+	if err != nil {
+		if errors.Is(err, epc.ErrNotPayableByQRCode) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Data(http.StatusOK, "image/png", image)
+}
@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"errors"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/service"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetInvoiceDocument(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockInvoiceService := mocked.NewMockInvoiceService(ctrl)
+	srv := &service.Service{
+		Invoice: mockInvoiceService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	invoiceID := uuid.New()
+
+	t.Run("successful rendering", func(t *testing.T) {
+		mockInvoiceService.EXPECT().
+			GetInvoiceDetails(gomock.Any(), invoiceID).
+			Return(&models.InvoiceDetails{
+				Invoice: models.Invoice{
+					InvoiceID: invoiceID, InvoiceNumber: "INV-001", Status: "pending", Currency: "NGN",
+					IssueDate: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), DueDate: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+				},
+				CustomerName: "Acme <Ltd>",
+				PaymentInformation: models.UserPaymentMethod{
+					AccountName: "Sender", AccountNumber: "0123456789", MaskedAccountNumber: "****6789", BankName: "Test Bank",
+				},
+				Items:  []models.InvoiceItem{{Name: "Design", Quantity: 2, UnitPrice: 100, TotalPrice: 200}},
+				Totals: models.InvoiceTotals{Subtotal: 200, GrandTotal: 200, BalanceDue: 200},
+			}, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "invoiceID", Value: invoiceID.String()}}
+
+		handler.GetInvoiceDocument(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+		body := w.Body.String()
+		require.Contains(t, body, "Invoice INV-001")
+		require.Contains(t, body, "Account number: 0123456789")
+		require.Contains(t, body, "Acme &lt;Ltd&gt;")
+		require.Contains(t, body, "1 July 2024")
+		require.Contains(t, body, "200.00")
+		require.NotContains(t, body, "EPC QR code")
+	})
+
+	t.Run("eur invoice with qr code", func(t *testing.T) {
+		mockInvoiceService.EXPECT().GetInvoiceDetails(gomock.Any(), invoiceID).Return(euroInvoiceDetails(invoiceID), nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "invoiceID", Value: invoiceID.String()}}
+
+		handler.GetInvoiceDocument(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), `<img src="data:image/png;base64,`)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		mockInvoiceService.EXPECT().
+			GetInvoiceDetails(gomock.Any(), invoiceID).
+			Return(nil, errors.New("database error"))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "invoiceID", Value: invoiceID.String()}}
+
+		handler.GetInvoiceDocument(c)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("invalid invoice ID", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "invoiceID", Value: "invalid-uuid"}}
+
+		handler.GetInvoiceDocument(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+// euroInvoiceDetails returns the details of a EUR invoice paid into an IBAN account.
+func euroInvoiceDetails(invoiceID uuid.UUID) *models.InvoiceDetails {
+	return &models.InvoiceDetails{
+		Invoice: models.Invoice{
+			InvoiceID: invoiceID, InvoiceNumber: "INV-002", Status: "pending", Currency: "EUR", FinalAmount: 200,
+			IssueDate: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), DueDate: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+		},
+		PaymentInformation: models.UserPaymentMethod{
+			AccountName: "Sender GmbH", AccountType: models.AccountTypeIBAN, AccountNumber: "DE89370400440532013000",
+			SwiftCode: "COBADEFFXXX", BankName: "Commerzbank",
+		},
+		Totals: models.InvoiceTotals{GrandTotal: 200, BalanceDue: 200},
+	}
+}
+
+func TestGetInvoiceQRCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockInvoiceService := mocked.NewMockInvoiceService(ctrl)
+	srv := &service.Service{
+		Invoice: mockInvoiceService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	invoiceID := uuid.New()
+	newContext := func(invoiceID string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "invoiceID", Value: invoiceID}}
+		return c, w
+	}
+
+	t.Run("successful rendering", func(t *testing.T) {
+		mockInvoiceService.EXPECT().GetInvoiceDetails(gomock.Any(), invoiceID).Return(euroInvoiceDetails(invoiceID), nil)
+
+		c, w := newContext(invoiceID.String())
+		handler.GetInvoiceQRCode(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "image/png", w.Header().Get("Content-Type"))
+		_, err := png.Decode(w.Body)
+		require.NoError(t, err)
+	})
+
+	t.Run("non eur invoice", func(t *testing.T) {
+		details := euroInvoiceDetails(invoiceID)
+		details.Invoice.Currency = "NGN"
+		mockInvoiceService.EXPECT().GetInvoiceDetails(gomock.Any(), invoiceID).Return(details, nil)
+
+		c, w := newContext(invoiceID.String())
+		handler.GetInvoiceQRCode(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "EPC QR codes are for EUR invoices only")
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		mockInvoiceService.EXPECT().GetInvoiceDetails(gomock.Any(), invoiceID).Return(nil, errors.New("database error"))
+
+		c, w := newContext(invoiceID.String())
+		handler.GetInvoiceQRCode(c)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("invalid invoice ID", func(t *testing.T) {
+		c, w := newContext("invalid-uuid")
+		handler.GetInvoiceQRCode(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+// streamHeartbeatInterval is how often an idle event stream sends a comment, so proxies keep the connection open and
+// clients notice when it is lost.
+const streamHeartbeatInterval = 15 * time.Second
+
+// StreamEvents is a handler function that streams the activity events of a user as server-sent events, from new
+// comments and emails to invoice status changes, recorded by any server. Each event carries the position the stream
+// resumes from as ID, so a client that lost its connection resumes with the Last-Event-ID header, or the last_event_id
+// query parameter for clients that cannot set headers. Events commit out of order, so a resumed stream may send again
+// the events recorded around the time the connection was lost; clients skip the activity IDs they already received.
+func (h *handlerImpl) StreamEvents(ctx *gin.Context) {
+	userID, err := uuid.Parse(ctx.Query("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	lastEventID, err := getLastEventID(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid last event ID"})
+		return
+	}
+
+	subscription, err := h.service.Event.Subscribe(ctx, userID, lastEventID)
+	if errors.Is(err, models.ErrEventStreamUnavailable) {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer subscription.Close()
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	// the missed events may be received again from the subscription, as may events older than the position the
+	// stream resumes from, which the client received before
+	position := lastEventID
+	sent := make(map[uuid.UUID]struct{}, len(subscription.Missed))
+	for _, event := range subscription.Missed {
+		position = max(position, event.Position)
+		if err := writeEvent(ctx, position, event); err != nil {
+			return
+		}
+		sent[event.ActivityID] = struct{}{}
+	}
+	// the client resumes from the last missed event sent to read the rest
+	if subscription.More {
+		return
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return
+			}
+			if _, ok := sent[event.ActivityID]; ok {
+				delete(sent, event.ActivityID)
+				continue
+			}
+			if event.Xid < lastEventID {
+				continue
+			}
+			position = max(position, event.Position)
+			if err := writeEvent(ctx, position, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(ctx.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			ctx.Writer.Flush()
+		}
+	}
+}
+
+// getLastEventID returns the ID of the last event a client received, which is the position its stream resumes from,
+// from the Last-Event-ID header or the last_event_id query parameter, or 0 when it connects for the first time.
+func getLastEventID(ctx *gin.Context) (int64, error) {
+	value := ctx.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = ctx.Query("last_event_id")
+	}
+	if value == "" {
+		return 0, nil
+	}
+	lastEventID, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || lastEventID < 0 {
+		return 0, fmt.Errorf("invalid last event ID: %q", value)
+	}
+	return lastEventID, nil
+}
+
+// writeEvent sends an activity event to the client as a server-sent event named after its type, with the position the
+// stream resumes from as ID.
+func writeEvent(ctx *gin.Context, position int64, event models.ActivityEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(ctx.Writer, "id: %d\nevent: %s\ndata: %s\n\n", position, event.EventType, data); err != nil {
+		return err
+	}
+	ctx.Writer.Flush()
+	return nil
+}
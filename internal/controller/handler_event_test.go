@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/service"
+	"go.uber.org/mock/gomock"
+)
+
+// activityEvent returns an activity event of a user recorded by the given transaction, at its position.
+func activityEvent(userID uuid.UUID, xid int64) models.ActivityEvent {
+	return models.ActivityEvent{
+		Sequence: xid,
+		Xid:      xid,
+		Position: xid,
+		InvoiceActivity: models.InvoiceActivity{
+			ActivityID: uuid.New(),
+			UserID:     userID,
+			EventType:  models.ActivityEventStatusChanged,
+			Source:     models.ActivitySourceSystem,
+			Payload:    json.RawMessage(`{"from":"pending","to":"paid"}`),
+			Title:      "Status Changed",
+		},
+	}
+}
+
+func TestStreamEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEventService := mocked.NewMockEventService(ctrl)
+	srv := &service.Service{
+		Event: mockEventService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	t.Run("missed and new events", func(t *testing.T) {
+		userID := uuid.New()
+		events := make(chan models.ActivityEvent, 4)
+		closed := false
+		// the second missed event stops at a transaction still in progress
+		inProgress := activityEvent(userID, 7)
+		inProgress.Position = 5
+		mockEventService.EXPECT().
+			Subscribe(gomock.Any(), userID, int64(3)).
+			Return(&models.EventSubscription{
+				Missed: []models.ActivityEvent{activityEvent(userID, 4), inProgress},
+				Events: events,
+				Close:  func() { closed = true },
+			}, nil)
+		// missed events received again and events older than the position resumed from are skipped
+		events <- inProgress
+		events <- activityEvent(userID, 2)
+		// an event that committed late does not move the position back
+		late := activityEvent(userID, 6)
+		late.Position = 4
+		events <- activityEvent(userID, 8)
+		events <- late
+		close(events)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/events/stream?user_id="+userID.String(), nil)
+		c.Request.Header.Set("Last-Event-ID", "3")
+
+		handler.StreamEvents(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		require.True(t, closed)
+
+		messages := strings.Split(strings.TrimSuffix(w.Body.String(), "\n\n"), "\n\n")
+		require.Len(t, messages, 4)
+		for i, id := range []string{"4", "5", "8", "8"} {
+			lines := strings.Split(messages[i], "\n")
+			require.Equal(t, "id: "+id, lines[0])
+			require.Equal(t, "event: status_changed", lines[1])
+
+			var event models.ActivityEvent
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(lines[2], "data: ")), &event))
+			require.Equal(t, userID, event.UserID)
+		}
+	})
+
+	t.Run("more missed events", func(t *testing.T) {
+		userID := uuid.New()
+		mockEventService.EXPECT().
+			Subscribe(gomock.Any(), userID, int64(1)).
+			Return(&models.EventSubscription{
+				Missed: []models.ActivityEvent{activityEvent(userID, 2)},
+				More:   true,
+				Events: make(chan models.ActivityEvent),
+				Close:  func() {},
+			}, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/events/stream?last_event_id=1&user_id="+userID.String(), nil)
+
+		handler.StreamEvents(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, 1, strings.Count(w.Body.String(), "id: "))
+	})
+
+	t.Run("heartbeat until the client disconnects", func(t *testing.T) {
+		userID := uuid.New()
+		mockEventService.EXPECT().
+			Subscribe(gomock.Any(), userID, int64(0)).
+			Return(&models.EventSubscription{Events: make(chan models.ActivityEvent), Close: func() {}}, nil)
+
+		heartbeatHandler := &handlerImpl{service: srv, heartbeat: time.Millisecond}
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequestWithContext(ctx, http.MethodGet, "/events/stream?user_id="+userID.String(), nil)
+
+		heartbeatHandler.StreamEvents(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), ": heartbeat\n\n")
+	})
+
+	t.Run("invalid user ID", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/events/stream?user_id=invalid-uuid", nil)
+
+		handler.StreamEvents(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, "Invalid user ID", response["error"])
+	})
+
+	t.Run("invalid last event ID", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/events/stream?user_id="+uuid.New().String(), nil)
+		c.Request.Header.Set("Last-Event-ID", "abc")
+
+		handler.StreamEvents(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, "Invalid last event ID", response["error"])
+	})
+
+	t.Run("stream unavailable", func(t *testing.T) {
+		mockEventService.EXPECT().
+			Subscribe(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, models.ErrEventStreamUnavailable)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/events/stream?user_id="+uuid.New().String(), nil)
+
+		handler.StreamEvents(c)
+
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		mockEventService.EXPECT().
+			Subscribe(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("database error"))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/events/stream?user_id="+uuid.New().String(), nil)
+
+		handler.StreamEvents(c)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
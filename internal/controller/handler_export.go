@@ -0,0 +1,214 @@
+package controller
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+	"github.com/zde37/Numeris-Task/internal/helpers"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+// invoiceExportSheet is the name of the worksheet of an XLSX invoice export.
+const invoiceExportSheet = "Invoices"
+
+// ExportInvoices is a handler function that exports the invoices of a sender as a file, CSV unless the format query
+// parameter is xlsx. It takes the same filter and sort query parameters as SearchInvoices, and lists one row per
+// line item when items is true. Rows are written as they are read from the database.
+func (h *handlerImpl) ExportInvoices(ctx *gin.Context) {
+	filter, ok := getInvoiceFilter(ctx)
+	if !ok {
+		return
+	}
+
+	format := ctx.DefaultQuery("format", string(models.ExportFormatCSV))
+	if err := helpers.ValidateExportFormat(format); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	withItems := false
+	if items := ctx.Query("items"); items != "" {
+		value, err := strconv.ParseBool(items)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "items must be true or false"})
+			return
+		}
+		withItems = value
+	}
+
+	filename := fmt.Sprintf("invoices-%s.%s", time.Now().Format("2006-01-02"), format)
+	if models.ExportFormat(format) == models.ExportFormatXLSX {
+		h.exportInvoicesXLSX(ctx, filter, withItems, filename)
+		return
+	}
+	h.exportInvoicesCSV(ctx, filter, withItems, filename)
+}
+
+// exportInvoicesCSV streams an invoice export to the response as CSV. The response starts with the first row, so an
+// error before it is answered with a 500 status, while an error after it ends the file early.
+func (h *handlerImpl) exportInvoicesCSV(ctx *gin.Context, filter models.InvoiceFilter, withItems bool, filename string) {
+	writer := csv.NewWriter(ctx.Writer)
+	started := false
+	start := func() error {
+		started = true
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		ctx.Header("Content-Type", "text/csv")
+		ctx.Status(http.StatusOK)
+		return writer.Write(invoiceExportHeader(withItems))
+	}
+
+	err := h.service.Invoice.ExportInvoices(ctx, filter, withItems, func(row models.InvoiceExportRow) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		values := invoiceExportValues(row, withItems)
+		record := make([]string, len(values))
+		for i, value := range values {
+			record[i] = csvField(value)
+		}
+		return writer.Write(record)
+	})
+	if err == nil && !started {
+		err = start()
+	}
+	if err != nil {
+		if !started {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.Error(err)
+		return
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		ctx.Error(err)
+	}
+}
+
+// exportInvoicesXLSX writes an invoice export to the response as an XLSX workbook. Rows are streamed into the
+// worksheet, which spills to a temporary file once it grows large, and the workbook is sent when it is complete.
+func (h *handlerImpl) exportInvoicesXLSX(ctx *gin.Context, filter models.InvoiceFilter, withItems bool, filename string) {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	stream, dateStyle, err := newInvoiceExportSheet(file, withItems)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	rowNumber := 1
+	err = h.service.Invoice.ExportInvoices(ctx, filter, withItems, func(row models.InvoiceExportRow) error {
+		rowNumber++
+		values := invoiceExportValues(row, withItems)
+		for i, value := range values {
+			if date, ok := value.(time.Time); ok {
+				values[i] = excelize.Cell{StyleID: dateStyle, Value: date}
+			}
+		}
+		cell, err := excelize.CoordinatesToCellName(1, rowNumber)
+		if err != nil {
+			return err
+		}
+		return stream.SetRow(cell, values)
+	})
+	if err == nil {
+		err = stream.Flush()
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	ctx.Status(http.StatusOK)
+	if err := file.Write(ctx.Writer); err != nil {
+		ctx.Error(err)
+	}
+}
+
+// newInvoiceExportSheet sets up the worksheet of an XLSX invoice export with its header row, and returns a stream
+// writer for the remaining rows and the style of date cells.
+func newInvoiceExportSheet(file *excelize.File, withItems bool) (*excelize.StreamWriter, int, error) {
+	if err := file.SetSheetName(file.GetSheetName(0), invoiceExportSheet); err != nil {
+		return nil, 0, err
+	}
+	dateStyle, err := file.NewStyle(&excelize.Style{NumFmt: 14})
+	if err != nil {
+		return nil, 0, err
+	}
+	stream, err := file.NewStreamWriter(invoiceExportSheet)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	columns := invoiceExportHeader(withItems)
+	header := make([]any, len(columns))
+	for i, column := range columns {
+		header[i] = column
+	}
+	if err := stream.SetRow("A1", header); err != nil {
+		return nil, 0, err
+	}
+	return stream, dateStyle, nil
+}
+
+// invoiceExportHeader returns the column names of an invoice export.
+func invoiceExportHeader(withItems bool) []string {
+	header := []string{
+		"invoice_id", "invoice_number", "status", "customer_id", "customer_name", "issue_date", "due_date",
+		"currency", "total_amount", "discount_percentage", "discounted_amount", "final_amount",
+		"base_currency", "exchange_rate", "notes",
+	}
+	if withItems {
+		header = append(header, "item_name", "item_description", "quantity", "unit_price", "item_total")
+	}
+	return header
+}
+
+// invoiceExportValues returns the values of the columns of an invoice export for a row. Dates are time.Time and
+// missing values, such as the item of an invoice without items, are nil.
+func invoiceExportValues(row models.InvoiceExportRow, withItems bool) []any {
+	var exchangeRate any
+	if row.ExchangeRate != nil {
+		exchangeRate = *row.ExchangeRate
+	}
+	values := []any{
+		row.InvoiceID.String(), row.InvoiceNumber, row.Status, row.CustomerID.String(), row.CustomerName,
+		row.IssueDate, row.DueDate, row.Currency, row.TotalAmount, row.DiscountPercentage, row.DiscountedAmount,
+		row.FinalAmount, row.BaseCurrency, exchangeRate, row.Notes,
+	}
+	if withItems {
+		if row.Item == nil {
+			return append(values, nil, nil, nil, nil, nil)
+		}
+		values = append(values, row.Item.Name, row.Item.Description, row.Item.Quantity, row.Item.UnitPrice, row.Item.TotalPrice)
+	}
+	return values
+}
+
+// csvField formats a value of an invoice export as a CSV field.
+func csvField(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case int:
+		return strconv.Itoa(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case time.Time:
+		return value.Format("2006-01-02")
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,181 @@
+package controller
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/service"
+	"go.uber.org/mock/gomock"
+)
+
+func TestExportInvoices(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockInvoiceService := mocked.NewMockInvoiceService(ctrl)
+	srv := &service.Service{
+		Invoice: mockInvoiceService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	senderID := uuid.New()
+	rate := 1500.0
+	rows := []models.InvoiceExportRow{
+		{
+			Invoice: models.Invoice{
+				InvoiceID: uuid.New(), InvoiceNumber: "INV-001", CustomerID: uuid.New(), Status: "pending",
+				IssueDate: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), DueDate: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+				TotalAmount: 200, DiscountedAmount: 200, FinalAmount: 200, Currency: "USD", BaseCurrency: "NGN", ExchangeRate: &rate,
+			},
+			CustomerName: "Acme",
+			Item:         &models.InvoiceItem{Name: "Design", Description: "Logo", Quantity: 2, UnitPrice: 100, TotalPrice: 200},
+		},
+		{
+			Invoice: models.Invoice{
+				InvoiceID: uuid.New(), InvoiceNumber: "INV-002", CustomerID: uuid.New(), Status: "draft",
+				IssueDate: time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC), DueDate: time.Date(2024, 7, 2, 0, 0, 0, 0, time.UTC),
+				Currency: "NGN", BaseCurrency: "NGN",
+			},
+			CustomerName: "Globex",
+		},
+	}
+	export := func(_ any, _ models.InvoiceFilter, _ bool, fn func(models.InvoiceExportRow) error) error {
+		for _, row := range rows {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	t.Run("csv with items", func(t *testing.T) {
+		mockInvoiceService.EXPECT().
+			ExportInvoices(gomock.Any(), models.InvoiceFilter{
+				SenderID:  senderID,
+				Statuses:  []models.InvoiceStatus{models.InvoiceStatusPending},
+				SortBy:    models.InvoiceSortFieldDueDate,
+				SortOrder: models.SortOrderAsc,
+			}, true, gomock.Any()).
+			DoAndReturn(export)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/invoices/export?sender_id="+senderID.String()+"&status=pending&sort=due_date&order=asc&items=true", nil)
+
+		handler.ExportInvoices(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+		require.Contains(t, w.Header().Get("Content-Disposition"), ".csv")
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		require.Equal(t, invoiceExportHeader(true), records[0])
+		require.Equal(t, []string{
+			rows[0].InvoiceID.String(), "INV-001", "pending", rows[0].CustomerID.String(), "Acme", "2024-06-01", "2024-07-01",
+			"USD", "200", "0", "200", "200", "NGN", "1500", "", "Design", "Logo", "2", "100", "200",
+		}, records[1])
+		require.Equal(t, "Globex", records[2][4])
+		require.Equal(t, []string{"", "", "", "", ""}, records[2][15:])
+	})
+
+	t.Run("csv without rows", func(t *testing.T) {
+		mockInvoiceService.EXPECT().
+			ExportInvoices(gomock.Any(), gomock.Any(), false, gomock.Any()).
+			Return(nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/invoices/export?sender_id="+senderID.String(), nil)
+
+		handler.ExportInvoices(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.Equal(t, [][]string{invoiceExportHeader(false)}, records)
+	})
+
+	t.Run("xlsx", func(t *testing.T) {
+		mockInvoiceService.EXPECT().
+			ExportInvoices(gomock.Any(), gomock.Any(), false, gomock.Any()).
+			DoAndReturn(export)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/invoices/export?sender_id="+senderID.String()+"&format=xlsx", nil)
+
+		handler.ExportInvoices(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Header().Get("Content-Disposition"), ".xlsx")
+		file, err := excelize.OpenReader(w.Body)
+		require.NoError(t, err)
+		defer file.Close()
+		sheetRows, err := file.GetRows(invoiceExportSheet)
+		require.NoError(t, err)
+		require.Len(t, sheetRows, 3)
+		require.Equal(t, invoiceExportHeader(false), sheetRows[0])
+		require.Equal(t, "INV-001", sheetRows[1][1])
+		require.Equal(t, "Globex", sheetRows[2][4])
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		expectedError := errors.New("database error")
+		mockInvoiceService.EXPECT().
+			ExportInvoices(gomock.Any(), gomock.Any(), false, gomock.Any()).
+			Return(expectedError)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/invoices/export?sender_id="+senderID.String(), nil)
+
+		handler.ExportInvoices(c)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.Empty(t, w.Header().Get("Content-Disposition"))
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, expectedError.Error(), response["error"])
+	})
+
+	invalid := []struct {
+		name  string
+		query string
+		err   string
+	}{
+		{"invalid sender ID", "sender_id=invalid", "Invalid sender ID"},
+		{"invalid format", "sender_id=" + senderID.String() + "&format=pdf", "invalid export format: pdf"},
+		{"invalid items", "sender_id=" + senderID.String() + "&items=maybe", "items must be true or false"},
+		{"invalid filter", "sender_id=" + senderID.String() + "&status=unknown", "invalid invoice status: unknown"},
+		{"invalid range", "sender_id=" + senderID.String() + "&due_from=2024-02-01&due_to=2024-01-01", "due_from must not be after due_to"},
+		{"format and range", "sender_id=" + senderID.String() + "&format=xlsx&min_amount=100&max_amount=10", "min_amount must not be greater than max_amount"},
+	}
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/invoices/export?"+tc.query, nil)
+
+			handler.ExportInvoices(c)
+
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			var response map[string]string
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			require.NoError(t, err)
+			require.Equal(t, tc.err, response["error"])
+		})
+	}
+}
@@ -1,26 +1,32 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/zde37/Numeris-Task/internal/helpers"
 	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/pagination"
 	"github.com/zde37/Numeris-Task/internal/service"
 )
 
 type handlerImpl struct {
-	service *service.Service
-	router  *gin.Engine
+	service   *service.Service
+	router    *gin.Engine
+	heartbeat time.Duration
 }
 
 // NewHandlerImpl creates a new instance of the handlerImpl struct, which implements the Handler interface. 
 func NewHandlerImpl(environment string, service *service.Service) Handler {
 	h := &handlerImpl{
-		service: service,
-		router:  gin.Default(),
+		service:   service,
+		router:    gin.Default(),
+		heartbeat: streamHeartbeatInterval,
 	}
 
 	if environment == "prod" {
@@ -42,13 +48,54 @@ func (h *handlerImpl) GetRouter() *gin.Engine {
 // POST /v1/invoices - Handles the creation of a new invoice.
 // POST /v1/user - Handles the creation of a new user.
 // POST /v1/payment - Handles the addition of a new payment method.
+// GET /v1/payment-methods - Handles the retrieval of a user's payment methods, the default first.
+// PATCH /v1/payment-methods/:paymentMethodID - Handles the update of a user's payment method.
+// DELETE /v1/payment-methods/:paymentMethodID - Handles the deletion of a user's payment method, kept for invoices already sent with it.
+// POST /v1/payment-methods/:paymentMethodID/make-default - Handles making a payment method the user's default.
 // POST /v1/customer - Handles the addition of a new customer.
 // GET /v1/invoices/:invoiceID - Handles the retrieval of invoice details.
-// POST /v1/invoices/activity - Handles the addition of a new invoice activity.
-// GET /v1/invoices/total/:status - Handles the retrieval of the total invoices by status.
+// GET /v1/invoices/:invoiceID/document - Handles the rendering of an invoice as an HTML document, with the full account number.
+// GET /v1/invoices/:invoiceID/qr.png - Handles the rendering of the EPC QR code a EUR invoice is paid by SEPA credit transfer with.
+// POST /v1/invoices/activity - Handles the addition of a comment to the activities of an invoice.
+// GET /v1/invoices/totals/:senderID - Handles the retrieval of a sender's invoice totals for every status, per currency and in the base currency.
+// GET /v1/invoices/total/:status - Deprecated: handles the retrieval of a sender's invoice totals for a single status, kept for existing clients.
+// GET /v1/invoices - Handles the search of a sender's invoices with filters, sorting and pagination.
+// GET /v1/invoices/export - Handles the export of a sender's invoices, and optionally their line items, as CSV or XLSX.
 // GET /v1/invoices/recent/:senderID - Handles the retrieval of the most recent invoices for a given sender.
 // GET /v1/activities/recent/:userID - Handles the retrieval of the most recent activities for a given user.
-// GET /v1/invoices/:invoiceID/activities/:userID - Handles the retrieval of the activities for a given invoice and user.
+// GET /v1/invoices/:invoiceID/activities/:userID - Handles the retrieval of the activities for a given invoice and user, filterable by event type and source.
+// POST /v1/reminders/rules - Handles the addition of a new payment reminder rule.
+// GET /v1/reminders/rules/:userID - Handles the retrieval of the payment reminder rules of a given user.
+// DELETE /v1/reminders/rules/:userID/:ruleID - Handles the deletion of a payment reminder rule.
+// PUT /v1/late-fees/policy - Handles the creation or replacement of a sender's late fee policy.
+// GET /v1/late-fees/policy/:userID - Handles the retrieval of the late fee policy of a given user.
+// POST /v1/tax-rates - Handles the creation of a new tax rate.
+// GET /v1/tax-rates/:userID - Handles the retrieval of the active tax rates of a given user.
+// DELETE /v1/tax-rates/:userID/:taxRateID - Handles the deactivation of a tax rate.
+// GET /v1/reports/tax - Handles the retrieval of a sender's tax report for a filing period, as JSON or CSV.
+// POST /v1/invoices/payments - Handles the recording of a payment received on an invoice.
+// POST /v1/invoices/:invoiceID/payment-link - Handles the creation of a link the customer pays an invoice online with.
+// POST /v1/payments/callback - Handles the callbacks of the payment provider, recording the payments made online.
+// POST /v1/payments/:paymentID/refund - Handles the refund of a payment made online through the payment provider.
+// POST /v1/reconciliation/statements - Handles the import of a bank statement, applying the credits that match an invoice as payments.
+// GET /v1/reconciliation/inbox - Handles the retrieval of the imported bank transactions waiting to be confirmed or dismissed.
+// POST /v1/reconciliation/inbox/:bankTransactionID/confirm - Handles the confirmation of the invoice a bank transaction pays.
+// POST /v1/reconciliation/inbox/:bankTransactionID/dismiss - Handles the dismissal of a bank transaction that pays no invoice.
+// GET /v1/exchange-rates - Handles the retrieval of the exchange rate between two currencies on a given date.
+// GET /v1/dashboard - Handles the retrieval of a sender's dashboard: totals, open balances, recent invoices and activities.
+// GET /v1/reports/aging - Handles the retrieval of a sender's accounts receivable aging report, as JSON or CSV.
+// GET /v1/reports/aging/invoices - Handles the retrieval of the invoices in a bucket of the aging report, as JSON or CSV.
+// GET /v1/reports/revenue - Handles the retrieval of the amount a sender invoiced per day, week or month.
+// GET /v1/reports/cash-flow - Handles the retrieval of the amount a sender collected per day, week or month.
+// POST /v1/import/customers - Handles the import of customers from an uploaded CSV file, optionally as a dry run.
+// POST /v1/import/invoices - Handles the import of a sender's invoices from an uploaded CSV file, optionally as a dry run.
+// GET /v1/events/stream - Handles the streaming of a user's new activities and invoice status changes as server-sent events.
+// POST /v1/webhooks - Handles the subscription of a URL to a user's invoice lifecycle events, returning the signing secret.
+// GET /v1/webhooks - Handles the retrieval of a user's webhooks.
+// DELETE /v1/webhooks/:webhookID - Handles the deletion of a webhook and its delivery log.
+// POST /v1/webhooks/:webhookID/enable - Handles enabling a webhook again after it was disabled for failing.
+// GET /v1/webhooks/:webhookID/deliveries - Handles the retrieval of the delivery log of a webhook.
+// POST /v1/webhooks/deliveries/:deliveryID/replay - Handles sending a webhook delivery again.
 func (h *handlerImpl) registerRoutes() {
 	v1 := h.router.Group("v1")
 	{
@@ -56,13 +103,54 @@ func (h *handlerImpl) registerRoutes() {
 		v1.POST("/invoices", h.CreateInvoice)
 		v1.POST("/user", h.CreateUser)
 		v1.POST("/payment", h.AddPaymentMethod)
+		v1.GET("/payment-methods", h.GetPaymentMethods)
+		v1.PATCH("/payment-methods/:paymentMethodID", h.UpdatePaymentMethod)
+		v1.DELETE("/payment-methods/:paymentMethodID", h.DeletePaymentMethod)
+		v1.POST("/payment-methods/:paymentMethodID/make-default", h.SetDefaultPaymentMethod)
 		v1.POST("/customer", h.AddCustomer)
 		v1.GET("/invoices/:invoiceID", h.GetInvoiceDetails)
+		v1.GET("/invoices/:invoiceID/document", h.GetInvoiceDocument)
+		v1.GET("/invoices/:invoiceID/qr.png", h.GetInvoiceQRCode)
 		v1.POST("/invoices/activity", h.AddInvoiceActivity)
-		v1.GET("/invoices/total/:status", h.GetTotalByStatus)
+		v1.GET("/invoices/totals/:senderID", h.GetTotalByStatus)
+		v1.GET("/invoices/total/:status", h.GetTotalForStatus)
+		v1.GET("/invoices", h.SearchInvoices)
+		v1.GET("/invoices/export", h.ExportInvoices)
 		v1.GET("/invoices/recent/:senderID", h.GetRecentInvoices)
 		v1.GET("/activities/recent/:userID", h.GetRecentActivities)
 		v1.GET("/invoices/:invoiceID/activities/:userID", h.GetInvoiceActivities)
+		v1.POST("/reminders/rules", h.AddReminderRule)
+		v1.GET("/reminders/rules/:userID", h.GetReminderRules)
+		v1.DELETE("/reminders/rules/:userID/:ruleID", h.DeleteReminderRule)
+		v1.PUT("/late-fees/policy", h.SetLateFeePolicy)
+		v1.GET("/late-fees/policy/:userID", h.GetLateFeePolicy)
+		v1.POST("/tax-rates", h.CreateTaxRate)
+		v1.GET("/tax-rates/:userID", h.GetTaxRates)
+		v1.DELETE("/tax-rates/:userID/:taxRateID", h.DeactivateTaxRate)
+		v1.GET("/reports/tax", h.GetTaxReport)
+		v1.POST("/invoices/payments", h.RecordPayment)
+		v1.POST("/invoices/:invoiceID/payment-link", h.CreatePaymentLink)
+		v1.POST("/payments/callback", h.HandlePaymentCallback)
+		v1.POST("/payments/:paymentID/refund", h.RefundPayment)
+		v1.POST("/reconciliation/statements", h.ImportBankStatement)
+		v1.GET("/reconciliation/inbox", h.GetReconciliationInbox)
+		v1.POST("/reconciliation/inbox/:bankTransactionID/confirm", h.ConfirmBankTransaction)
+		v1.POST("/reconciliation/inbox/:bankTransactionID/dismiss", h.DismissBankTransaction)
+		v1.GET("/exchange-rates", h.GetExchangeRate)
+		v1.GET("/dashboard", h.GetDashboard)
+		v1.GET("/reports/aging", h.GetAgingReport)
+		v1.GET("/reports/aging/invoices", h.GetAgingInvoices)
+		v1.GET("/reports/revenue", h.GetRevenueReport)
+		v1.GET("/reports/cash-flow", h.GetCashFlowReport)
+		v1.POST("/import/customers", h.ImportCustomers)
+		v1.POST("/import/invoices", h.ImportInvoices)
+		v1.GET("/events/stream", h.StreamEvents)
+		v1.POST("/webhooks", h.CreateWebhook)
+		v1.GET("/webhooks", h.GetWebhooks)
+		v1.DELETE("/webhooks/:webhookID", h.DeleteWebhook)
+		v1.POST("/webhooks/:webhookID/enable", h.EnableWebhook)
+		v1.GET("/webhooks/:webhookID/deliveries", h.GetWebhookDeliveries)
+		v1.POST("/webhooks/deliveries/:deliveryID/replay", h.ReplayWebhookDelivery)
 	}
 }
 
@@ -80,6 +168,10 @@ func (h *handlerImpl) CreateInvoice(ctx *gin.Context) {
 	}
 
 	invoiceID, err := h.service.Invoice.CreateInvoice(ctx, req)
+	if errors.Is(err, models.ErrCustomerNotOwned) || errors.Is(err, models.ErrPaymentMethodNotOwned) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -103,7 +195,7 @@ func (h *handlerImpl) GetInvoiceDetails(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, details)
 }
 
-// AddInvoiceActivity is a handler function that adds a new activity to an invoice. 
+// AddInvoiceActivity is a handler function that adds a comment to the activities of an invoice.
 func (h *handlerImpl) AddInvoiceActivity(ctx *gin.Context) {
 	var activity models.AddInvoiceActivityRequest
 	if err := ctx.ShouldBind(&activity); err != nil {
@@ -119,20 +211,102 @@ func (h *handlerImpl) AddInvoiceActivity(ctx *gin.Context) {
 	ctx.JSON(http.StatusCreated, gin.H{"activity_id": activityID})
 }
 
-// GetTotalByStatus is a handler function that retrieves the total amount and count of invoices by a given status. 
+// GetTotalByStatus is a handler function that retrieves the totals of a sender's invoices for every status, per currency
+// and converted into the sender's base currency. The optional from and to query parameters restrict the invoices to a
+// period of their issue date, or of their due date when date_field is due_date, and customer_id to a single customer.
 func (h *handlerImpl) GetTotalByStatus(ctx *gin.Context) {
-	if err := helpers.ValidateInvoiceStatus(ctx.Param("status")); err != nil {
+	senderID, err := uuid.Parse(ctx.Param("senderID"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sender ID"})
+		return
+	}
+	filter := models.TotalsFilter{
+		SenderID:  senderID,
+		DateField: models.TotalsDateField(ctx.DefaultQuery("date_field", string(models.TotalsDateFieldIssue))),
+	}
+	if err := helpers.ValidateTotalsDateField(string(filter.DateField)); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	if customer := ctx.Query("customer_id"); customer != "" {
+		customerID, err := uuid.Parse(customer)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid customer ID"})
+			return
+		}
+		filter.CustomerID = &customerID
+	}
+
+	layout := "2006-01-02"
+	if from := ctx.Query("from"); from != "" {
+		date, err := time.Parse(layout, from)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "from has invalid date format"})
+			return
+		}
+		filter.From = &date
+	}
+	if to := ctx.Query("to"); to != "" {
+		date, err := time.Parse(layout, to)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "to has invalid date format"})
+			return
+		}
+		filter.To = &date
+	}
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+
+	summary, err := h.service.Invoice.GetTotalByStatus(ctx, filter)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, summary)
+}
+
+// GetTotalForStatus is a handler function that retrieves the total amount and count of a sender's invoices with a
+// single status, in the shape the totals had before they were scoped to a sender, with the total amount in the base
+// currency. It is deprecated in favour of GetTotalByStatus and requires the sender in the sender_id query parameter,
+// as totals across every sender are no longer served.
+func (h *handlerImpl) GetTotalForStatus(ctx *gin.Context) {
+	if err := helpers.ValidateInvoiceStatus(ctx.Param("status")); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	status := models.InvoiceStatus(ctx.Param("status"))
 
-	totalAmount, count, err := h.service.Invoice.GetTotalByStatus(ctx, status)
+	senderID, err := uuid.Parse(ctx.Query("sender_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sender ID"})
+		return
+	}
+
+	summary, err := h.service.Invoice.GetTotalByStatus(ctx, models.TotalsFilter{
+		SenderID:  senderID,
+		DateField: models.TotalsDateFieldIssue,
+	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+
+	var totalAmount float64
+	var count int
+	for _, total := range summary.Statuses {
+		if total.Status != status {
+			continue
+		}
+		count = total.Count
+		for _, base := range total.BaseCurrencies {
+			totalAmount += base.TotalAmount
+		}
+	}
+	ctx.Header("Deprecation", "true")
+	ctx.Header("Link", `</v1/invoices/totals/`+senderID.String()+`>; rel="successor-version"`)
 	ctx.JSON(http.StatusOK, gin.H{"total_amount": totalAmount, "count": count})
 }
 
@@ -144,17 +318,143 @@ func (h *handlerImpl) GetRecentInvoices(ctx *gin.Context) {
 		return
 	}
 
-	limit, page := h.getPaginationParams(ctx)
+	page, ok := h.getPageRequest(ctx)
+	if !ok {
+		return
+	}
 
-	invoices, err := h.service.Invoice.GetRecentInvoices(ctx, senderID, page, limit)
+	invoices, meta, err := h.service.Invoice.GetRecentInvoices(ctx, senderID, page)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondWithListError(ctx, err)
 		return
 	}
-	ctx.JSON(http.StatusOK, invoices)
+	respondWithPage(ctx, invoices, meta)
+}
+
+// SearchInvoices is a handler function that searches the invoices of a sender. Invoices can be filtered by status
+// (repeated or comma separated), customer_id, currency, issue_from/issue_to and due_from/due_to dates,
+// min_amount/max_amount, number prefix and q, a text matched against the notes and item names. sort can be
+// due_date, amount, number or created_at, and order asc or desc.
+func (h *handlerImpl) SearchInvoices(ctx *gin.Context) {
+	filter, ok := getInvoiceFilter(ctx)
+	if !ok {
+		return
+	}
+	page, ok := h.getPageRequest(ctx)
+	if !ok {
+		return
+	}
+
+	invoices, meta, err := h.service.Invoice.SearchInvoices(ctx, filter, page)
+	if err != nil {
+		respondWithListError(ctx, err)
+		return
+	}
+	respondWithPage(ctx, invoices, meta)
+}
+
+// getInvoiceFilter is a helper function that reads the sender_id, filter and sort query parameters of SearchInvoices.
+// It responds with a 400 status and returns false when a parameter or a range is invalid.
+func getInvoiceFilter(ctx *gin.Context) (models.InvoiceFilter, bool) {
+	senderID, err := uuid.Parse(ctx.Query("sender_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sender ID"})
+		return models.InvoiceFilter{}, false
+	}
+	filter := models.InvoiceFilter{
+		SenderID:     senderID,
+		Currency:     strings.ToUpper(ctx.Query("currency")),
+		NumberPrefix: ctx.Query("number"),
+		Search:       ctx.Query("q"),
+		SortBy:       models.InvoiceSortField(ctx.DefaultQuery("sort", string(models.InvoiceSortFieldCreatedAt))),
+		SortOrder:    models.SortOrder(ctx.DefaultQuery("order", string(models.SortOrderDesc))),
+	}
+
+	for _, statuses := range ctx.QueryArray("status") {
+		for _, status := range strings.Split(statuses, ",") {
+			if err := helpers.ValidateInvoiceStatus(status); err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return filter, false
+			}
+			filter.Statuses = append(filter.Statuses, models.InvoiceStatus(status))
+		}
+	}
+	if filter.Currency != "" {
+		if err := helpers.ValidateCurrency(filter.Currency); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return filter, false
+		}
+	}
+	if err := helpers.ValidateInvoiceSortField(string(filter.SortBy)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, false
+	}
+	if err := helpers.ValidateSortOrder(string(filter.SortOrder)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, false
+	}
+
+	if customer := ctx.Query("customer_id"); customer != "" {
+		customerID, err := uuid.Parse(customer)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid customer ID"})
+			return filter, false
+		}
+		filter.CustomerID = &customerID
+	}
+
+	dates := map[string]**time.Time{
+		"issue_from": &filter.IssueFrom,
+		"issue_to":   &filter.IssueTo,
+		"due_from":   &filter.DueFrom,
+		"due_to":     &filter.DueTo,
+	}
+	for name, field := range dates {
+		if value := ctx.Query(name); value != "" {
+			date, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": name + " has invalid date format"})
+				return filter, false
+			}
+			*field = &date
+		}
+	}
+
+	amounts := map[string]**float64{
+		"min_amount": &filter.MinAmount,
+		"max_amount": &filter.MaxAmount,
+	}
+	for name, field := range amounts {
+		if value := ctx.Query(name); value != "" {
+			amount, err := strconv.ParseFloat(value, 64)
+			if err != nil || amount < 0 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": name + " must be a non-negative number"})
+				return filter, false
+			}
+			*field = &amount
+		}
+	}
+
+	// the ranges are checked here as well as by the service, so an export can reject them before streaming
+	var invalid string
+	switch {
+	case filter.IssueFrom != nil && filter.IssueTo != nil && filter.IssueTo.Before(*filter.IssueFrom):
+		invalid = "issue_from must not be after issue_to"
+	case filter.DueFrom != nil && filter.DueTo != nil && filter.DueTo.Before(*filter.DueFrom):
+		invalid = "due_from must not be after due_to"
+	case filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MaxAmount < *filter.MinAmount:
+		invalid = "min_amount must not be greater than max_amount"
+	}
+	if invalid != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": invalid})
+		return filter, false
+	}
+
+	return filter, true
 }
 
-// GetRecentActivities is a handler function that retrieves the recent activities for a given user. 
+// GetRecentActivities is a handler function that retrieves the recent activities for a given user. The optional type
+// and source query parameters restrict them as they do the activities of an invoice.
 func (h *handlerImpl) GetRecentActivities(ctx *gin.Context) {
 	userID, err := uuid.Parse(ctx.Param("userID"))
 	if err != nil {
@@ -162,17 +462,26 @@ func (h *handlerImpl) GetRecentActivities(ctx *gin.Context) {
 		return
 	}
 
-	limit, page := h.getPaginationParams(ctx)
+	filter, ok := getActivityFilter(ctx)
+	if !ok {
+		return
+	}
+	page, ok := h.getPageRequest(ctx)
+	if !ok {
+		return
+	}
 
-	activities, err := h.service.Invoice.GetRecentActivities(ctx, userID, page, limit)
+	activities, meta, err := h.service.Invoice.GetRecentActivities(ctx, userID, filter, page)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondWithListError(ctx, err)
 		return
 	}
-	ctx.JSON(http.StatusOK, activities)
+	respondWithPage(ctx, activities, meta)
 }
 
-// GetInvoiceActivities is a handler function that retrieves the recent activities for a given invoice and user. 
+// GetInvoiceActivities is a handler function that retrieves the recent activities for a given invoice and user. The
+// optional type query parameter, repeated or comma separated, restricts them to event types, and the optional source
+// query parameter to the comments of users (user) or the events recorded by the system (system).
 func (h *handlerImpl) GetInvoiceActivities(ctx *gin.Context) {
 	userID, err := uuid.Parse(ctx.Param("userID"))
 	if err != nil {
@@ -186,14 +495,43 @@ func (h *handlerImpl) GetInvoiceActivities(ctx *gin.Context) {
 		return
 	}
 
-	limit, page := h.getPaginationParams(ctx)
+	filter, ok := getActivityFilter(ctx)
+	if !ok {
+		return
+	}
+	page, ok := h.getPageRequest(ctx)
+	if !ok {
+		return
+	}
 
-	activities, err := h.service.Invoice.GetInvoiceActivities(ctx, userID, invoiceID, page, limit)
+	activities, meta, err := h.service.Invoice.GetInvoiceActivities(ctx, userID, invoiceID, filter, page)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondWithListError(ctx, err)
 		return
 	}
-	ctx.JSON(http.StatusOK, activities)
+	respondWithPage(ctx, activities, meta)
+}
+
+// getActivityFilter is a helper function that parses the activity filter from the type and source query parameters.
+// It responds with a 400 status and returns false when either is invalid.
+func getActivityFilter(ctx *gin.Context) (models.ActivityFilter, bool) {
+	filter := models.ActivityFilter{Source: models.ActivitySource(ctx.Query("source"))}
+	for _, eventTypes := range ctx.QueryArray("type") {
+		for _, eventType := range strings.Split(eventTypes, ",") {
+			if err := helpers.ValidateActivityEventType(eventType); err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return filter, false
+			}
+			filter.EventTypes = append(filter.EventTypes, models.ActivityEventType(eventType))
+		}
+	}
+	if filter.Source != "" {
+		if err := helpers.ValidateActivitySource(string(filter.Source)); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return filter, false
+		}
+	}
+	return filter, true
 }
 
 // CreateUser is a handler function that creates a new user. 
@@ -222,13 +560,13 @@ func (h *handlerImpl) AddPaymentMethod(ctx *gin.Context) {
 
 	paymentMethodID, err := h.service.User.AddPaymentMethod(ctx, req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondWithPaymentMethodError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusCreated, gin.H{"payment_method_id": paymentMethodID})
 }
 
-// AddCustomer is a handler function that creates a new customer. 
+// AddCustomer is a handler function that creates a new customer of the user in the request body, who must exist.
 func (h *handlerImpl) AddCustomer(ctx *gin.Context) {
 	var req models.AddCustomerRequest
 	if err := ctx.ShouldBind(&req); err != nil {
@@ -236,7 +574,16 @@ func (h *handlerImpl) AddCustomer(ctx *gin.Context) {
 		return
 	}
 
+	if _, err := uuid.Parse(req.UserID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
 	customerID, err := h.service.User.AddCustomer(ctx, req)
+	if errors.Is(err, models.ErrUserNotFound) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "User not found"})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -244,26 +591,36 @@ func (h *handlerImpl) AddCustomer(ctx *gin.Context) {
 	ctx.JSON(http.StatusCreated, gin.H{"customer_id": customerID})
 }
 
-// getPaginationParams is a helper function that extracts the limit and page
-// parameters from the request context. If the parameters are not provided,
-// it uses default values of 10 for limit and 1 for page. 
-func (h *handlerImpl) getPaginationParams(ctx *gin.Context) (limit, page int32) {
-	limitStr := ctx.DefaultQuery("limit", "10")
-	pageStr := ctx.DefaultQuery("page", "1")
-
-	limit64, err := strconv.ParseInt(limitStr, 10, 32)
-	if err != nil {
-		limit = 10
-	} else {
-		limit = int32(limit64)
+// getPageRequest is a helper function that reads the page of a list requested from the limit, page and cursor
+// query parameters: the rows after or before the position of the cursor when it is set, or else the page number.
+// The limit defaults to 10 and the page to 1. It responds with a 400 status and returns false when a parameter
+// is invalid or out of bounds.
+func (h *handlerImpl) getPageRequest(ctx *gin.Context) (pagination.Request, bool) {
+	request, err := pagination.ParseRequest(ctx.Query("limit"), ctx.Query("page"), ctx.Query("cursor"))
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+		return request, false
 	}
-
-	page64, err := strconv.ParseInt(pageStr, 10, 32)
 	if err != nil {
-		page = 1
-	} else {
-		page = int32(page64)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return request, false
 	}
+	return request, true
+}
+
+// respondWithPage responds with the rows of a page in the list envelope, with the links to the next and
+// previous pages built from the URL of the request.
+func respondWithPage[T any](ctx *gin.Context, items []T, meta *pagination.Meta) {
+	meta.SetLinks(ctx.Request.URL)
+	ctx.JSON(http.StatusOK, pagination.NewPage(items, meta))
+}
 
-	return limit, page
+// respondWithListError responds with a 400 status for cursors issued for a list sorted another way and a 500 status
+// for any other error reading a page of a list.
+func respondWithListError(ctx *gin.Context, err error) {
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 }
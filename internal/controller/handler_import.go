@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/service"
+)
+
+// maxImportSize is the largest CSV file or bank statement, in bytes, that can be uploaded for an import.
+const maxImportSize = 10 << 20
+
+// ImportCustomers is a handler function that imports customers of the user in the user_id query parameter from a
+// CSV file uploaded in the file form field. Nothing is stored when dry_run is true. The response lists the errors
+// of the rows that were not imported.
+func (h *handlerImpl) ImportCustomers(ctx *gin.Context) {
+	userID, err := uuid.Parse(ctx.Query("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	file, dryRun, ok := getImportFile(ctx)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	result, err := h.service.Import.ImportCustomers(ctx, userID, file, dryRun)
+	respondWithImport(ctx, result, err)
+}
+
+// ImportInvoices is a handler function that imports the invoices of the sender in the sender_id query parameter
+// from a CSV file uploaded in the file form field, with one row per invoice item. Nothing is stored when dry_run
+// is true. The response lists the errors of the rows that were not imported.
+func (h *handlerImpl) ImportInvoices(ctx *gin.Context) {
+	senderID, err := uuid.Parse(ctx.Query("sender_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sender ID"})
+		return
+	}
+	file, dryRun, ok := getImportFile(ctx)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	result, err := h.service.Import.ImportInvoices(ctx, senderID, file, dryRun)
+	respondWithImport(ctx, result, err)
+}
+
+// getImportFile is a helper function that opens the file uploaded for an import and reads the dry_run query
+// parameter. It responds with a 400 status and returns false when the file is missing or too large, or dry_run
+// is invalid.
+func getImportFile(ctx *gin.Context) (multipart.File, bool, bool) {
+	dryRun := false
+	if value := ctx.Query("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "dry_run must be true or false"})
+			return nil, false, false
+		}
+		dryRun = parsed
+	}
+
+	file, ok := getUploadedFile(ctx)
+	return file, dryRun, ok
+}
+
+// getUploadedFile is a helper function that opens the file uploaded in the file form field. It responds with a 400
+// status and returns false when the file is missing or larger than maxImportSize.
+func getUploadedFile(ctx *gin.Context) (multipart.File, bool) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportSize)
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "file must be at most 10 MB"})
+			return nil, false
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return nil, false
+	}
+	file, err := header.Open()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return file, true
+}
+
+// respondWithImport responds with the result of an import, or with a 400 status when the file could not be read
+// and a 500 status for any other error.
+func respondWithImport(ctx *gin.Context, result *models.ImportResult, err error) {
+	if errors.Is(err, service.ErrInvalidImportFile) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
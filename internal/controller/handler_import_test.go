@@ -0,0 +1,198 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/service"
+	"go.uber.org/mock/gomock"
+)
+
+// newImportRequest builds a request uploading the content as the file of an import.
+func newImportRequest(t *testing.T, target, content string) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "import.csv")
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req, err := http.NewRequest(http.MethodPost, target, &body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestImportCustomers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockImportService := mocked.NewMockImportService(ctrl)
+	srv := &service.Service{
+		Import: mockImportService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	userID := uuid.New()
+	content := "name,email\nAcme,billing@acme.com\n"
+
+	t.Run("successful import", func(t *testing.T) {
+		expectedResult := &models.ImportResult{
+			DryRun: true, Rows: 1, Valid: 1,
+			Errors: []models.ImportError{},
+		}
+		mockImportService.EXPECT().
+			ImportCustomers(gomock.Any(), userID, gomock.Any(), true).
+			DoAndReturn(func(_ any, _ uuid.UUID, file io.Reader, _ bool) (*models.ImportResult, error) {
+				data, err := io.ReadAll(file)
+				require.NoError(t, err)
+				require.Equal(t, content, string(data))
+				return expectedResult, nil
+			})
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newImportRequest(t, "/import/customers?dry_run=true&user_id="+userID.String(), content)
+
+		handler.ImportCustomers(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response models.ImportResult
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, *expectedResult, response)
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		mockImportService.EXPECT().
+			ImportCustomers(gomock.Any(), userID, gomock.Any(), false).
+			Return(nil, fmt.Errorf("%w: missing column email", service.ErrInvalidImportFile))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newImportRequest(t, "/import/customers?user_id="+userID.String(), "name\nAcme\n")
+
+		handler.ImportCustomers(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, "invalid import file: missing column email", response["error"])
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		expectedError := errors.New("database error")
+		mockImportService.EXPECT().
+			ImportCustomers(gomock.Any(), userID, gomock.Any(), false).
+			Return(nil, expectedError)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newImportRequest(t, "/import/customers?user_id="+userID.String(), content)
+
+		handler.ImportCustomers(c)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/import/customers?user_id="+userID.String(), nil)
+
+		handler.ImportCustomers(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, "file is required", response["error"])
+	})
+
+	t.Run("invalid dry run", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newImportRequest(t, "/import/customers?dry_run=maybe&user_id="+userID.String(), content)
+
+		handler.ImportCustomers(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("invalid user ID", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newImportRequest(t, "/import/customers", content)
+
+		handler.ImportCustomers(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, "Invalid user ID", response["error"])
+	})
+}
+
+func TestImportInvoices(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockImportService := mocked.NewMockImportService(ctrl)
+	srv := &service.Service{
+		Import: mockImportService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	senderID := uuid.New()
+
+	t.Run("successful import", func(t *testing.T) {
+		expectedResult := &models.ImportResult{
+			Rows: 3, Valid: 1, Imported: 1,
+			Errors: []models.ImportError{{Line: 4, Error: "quantity must be a positive whole number"}},
+		}
+		mockImportService.EXPECT().
+			ImportInvoices(gomock.Any(), senderID, gomock.Any(), false).
+			Return(expectedResult, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newImportRequest(t, "/import/invoices?sender_id="+senderID.String(), "reference\n")
+
+		handler.ImportInvoices(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response models.ImportResult
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, *expectedResult, response)
+	})
+
+	t.Run("invalid sender ID", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newImportRequest(t, "/import/invoices?sender_id=invalid", "reference\n")
+
+		handler.ImportInvoices(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, "Invalid sender ID", response["error"])
+	})
+}
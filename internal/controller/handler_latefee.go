@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/zde37/Numeris-Task/internal/helpers"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+// SetLateFeePolicy is a handler function that creates or replaces the late fee policy of a sender.
+func (h *handlerImpl) SetLateFeePolicy(ctx *gin.Context) {
+	var req models.SetLateFeePolicyRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := helpers.ValidateLateFeePolicy(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policyID, err := h.service.LateFee.SetLateFeePolicy(ctx, req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"policy_id": policyID})
+}
+
+// GetLateFeePolicy is a handler function that retrieves the late fee policy of a given user.
+func (h *handlerImpl) GetLateFeePolicy(ctx *gin.Context) {
+	userID, err := uuid.Parse(ctx.Param("userID"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	policy, err := h.service.LateFee.GetLateFeePolicy(ctx, userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if policy == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Late fee policy not found"})
+		return
+	}
+	ctx.JSON(http.StatusOK, policy)
+}
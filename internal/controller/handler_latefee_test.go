@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/service"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSetLateFeePolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLateFeeService := mocked.NewMockLateFeeService(ctrl)
+	srv := &service.Service{
+		LateFee: mockLateFeeService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	t.Run("successful policy update", func(t *testing.T) {
+		req := models.SetLateFeePolicyRequest{
+			UserID:       uuid.New().String(),
+			FeeType:      string(models.LateFeeTypeMonthlyInterest),
+			InterestRate: 1.5,
+			GraceDays:    7,
+		}
+		expectedPolicyID := uuid.New()
+
+		mockLateFeeService.EXPECT().
+			SetLateFeePolicy(gomock.Any(), req).
+			Return(expectedPolicyID, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		jsonData, _ := json.Marshal(req)
+		c.Request, _ = http.NewRequest(http.MethodPut, "/late-fees/policy", bytes.NewBuffer(jsonData))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.SetLateFeePolicy(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, expectedPolicyID.String(), response["policy_id"])
+	})
+
+	t.Run("missing fee type", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		body := `{"user_id": "` + uuid.New().String() + `", "flat_amount": 10}`
+		c.Request, _ = http.NewRequest(http.MethodPut, "/late-fees/policy", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.SetLateFeePolicy(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("incomplete policy", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		body := `{"user_id": "` + uuid.New().String() + `", "fee_type": "flat"}`
+		c.Request, _ = http.NewRequest(http.MethodPut, "/late-fees/policy", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.SetLateFeePolicy(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Contains(t, response["error"], "flat_amount")
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		expectedError := errors.New("service error")
+		mockLateFeeService.EXPECT().
+			SetLateFeePolicy(gomock.Any(), gomock.Any()).
+			Return(uuid.Nil, expectedError)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		body := `{"user_id": "` + uuid.New().String() + `", "fee_type": "flat", "flat_amount": 10}`
+		c.Request, _ = http.NewRequest(http.MethodPut, "/late-fees/policy", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.SetLateFeePolicy(c)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, expectedError.Error(), response["error"])
+	})
+}
+
+func TestGetLateFeePolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLateFeeService := mocked.NewMockLateFeeService(ctrl)
+	srv := &service.Service{
+		LateFee: mockLateFeeService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	t.Run("successful retrieval", func(t *testing.T) {
+		userID := uuid.New()
+		expectedPolicy := &models.LateFeePolicy{
+			PolicyID: uuid.New(), UserID: userID, FeeType: models.LateFeeTypeFlat, FlatAmount: 25, IsActive: true,
+		}
+
+		mockLateFeeService.EXPECT().
+			GetLateFeePolicy(gomock.Any(), userID).
+			Return(expectedPolicy, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "userID", Value: userID.String()}}
+
+		handler.GetLateFeePolicy(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response models.LateFeePolicy
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, *expectedPolicy, response)
+	})
+
+	t.Run("policy not found", func(t *testing.T) {
+		userID := uuid.New()
+		mockLateFeeService.EXPECT().
+			GetLateFeePolicy(gomock.Any(), userID).
+			Return(nil, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "userID", Value: userID.String()}}
+
+		handler.GetLateFeePolicy(c)
+
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid user ID", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "userID", Value: "invalid-uuid"}}
+
+		handler.GetLateFeePolicy(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		userID := uuid.New()
+		mockLateFeeService.EXPECT().
+			GetLateFeePolicy(gomock.Any(), userID).
+			Return(nil, errors.New("service error"))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "userID", Value: userID.String()}}
+
+		handler.GetLateFeePolicy(c)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,177 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/zde37/Numeris-Task/internal/gateway"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/service"
+)
+
+// maxCallbackSize is the largest payment provider callback accepted, in bytes.
+const maxCallbackSize = 1 << 20
+
+// RecordPayment is a handler function that records a payment received on an invoice. Payments larger than the
+// balance due are rejected, and so are payments on drafts and on invoices that are already paid.
+func (h *handlerImpl) RecordPayment(ctx *gin.Context) {
+	var req models.RecordPaymentRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := uuid.Parse(req.InvoiceID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invoice ID"})
+		return
+	}
+	if _, err := uuid.Parse(req.UserID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	if req.Amount <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "amount must be greater than 0"})
+		return
+	}
+	if _, err := time.Parse("2006-01-02", req.PaidOn); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "paid on date has invalid date format"})
+		return
+	}
+
+	paymentID, err := h.service.Payment.RecordPayment(ctx, req)
+	switch {
+	case errors.Is(err, models.ErrInvoiceNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Invoice not found"})
+		return
+	case errors.Is(err, models.ErrInvoiceAlreadyPaid), errors.Is(err, models.ErrInvoiceIsDraft):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, models.ErrPaymentExceedsBalance):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"payment_id": paymentID})
+}
+
+// CreatePaymentLink is a handler function that creates a checkout session with the payment provider, whose URL the
+// customer pays the balance due on an invoice online with.
+func (h *handlerImpl) CreatePaymentLink(ctx *gin.Context) {
+	invoiceID, err := uuid.Parse(ctx.Param("invoiceID"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invoice ID"})
+		return
+	}
+	userID, err := uuid.Parse(ctx.Query("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	session, err := h.service.Payment.CreatePaymentLink(ctx, userID, invoiceID)
+	switch {
+	case errors.Is(err, service.ErrPaymentsNotConfigured):
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, models.ErrInvoiceNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Invoice not found"})
+		return
+	case errors.Is(err, service.ErrInvoiceNotPayable):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, session)
+}
+
+// HandlePaymentCallback is a handler function that receives the callbacks of the payment provider. Callbacks that
+// fail with a server error are expected to be retried by the provider, so callbacks that can never succeed, such as
+// a payment on an invoice turned back into a draft, fail with a client error.
+func (h *handlerImpl) HandlePaymentCallback(ctx *gin.Context) {
+	payload, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxCallbackSize))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid callback body"})
+		return
+	}
+
+	err = h.service.Payment.HandlePaymentCallback(ctx, payload, ctx.Request.Header)
+	switch {
+	case errors.Is(err, gateway.ErrInvalidSignature):
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, gateway.ErrInvalidCallback):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, models.ErrPaymentSessionNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, models.ErrInvoiceIsDraft):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrPaymentsNotConfigured):
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// RefundPayment is a handler function that refunds a payment made online through the payment provider, in full or in
+// part, and records the refund on its invoice. A refund whose outcome is not known yet is accepted as pending, and is
+// sent to the provider again until it is made or rejected.
+func (h *handlerImpl) RefundPayment(ctx *gin.Context) {
+	paymentID, err := uuid.Parse(ctx.Param("paymentID"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment ID"})
+		return
+	}
+
+	var req models.RefundPaymentRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	if req.Amount < 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "amount must be greater than 0"})
+		return
+	}
+
+	refund, err := h.service.Payment.RefundPayment(ctx, userID, paymentID, req.Amount)
+	switch {
+	case errors.Is(err, service.ErrPaymentsNotConfigured):
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, models.ErrPaymentNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+		return
+	case errors.Is(err, models.ErrPaymentNotRefundable), errors.Is(err, gateway.ErrRefundRejected):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, models.ErrRefundExceedsPayment):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if refund.Status == models.PaymentRefundStatusPending {
+		ctx.JSON(http.StatusAccepted, refund)
+		return
+	}
+	ctx.JSON(http.StatusCreated, refund)
+}
@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/zde37/Numeris-Task/internal/helpers"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/validation"
+)
+
+// GetPaymentMethods is a handler function that retrieves the payment methods of the user in the user_id query
+// parameter, the default first.
+func (h *handlerImpl) GetPaymentMethods(ctx *gin.Context) {
+	userID, err := uuid.Parse(ctx.Query("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	paymentMethods, err := h.service.User.GetPaymentMethods(ctx, userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, paymentMethods)
+}
+
+// UpdatePaymentMethod is a handler function that changes the fields set in the request body on a payment method of
+// the user in the user_id query parameter. A payment method that invoices were sent with cannot be changed; a new one
+// is added instead.
+func (h *handlerImpl) UpdatePaymentMethod(ctx *gin.Context) {
+	userID, paymentMethodID, ok := getPaymentMethodParams(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.UpdatePaymentMethodRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := helpers.ValidatePaymentMethodUpdate(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	paymentMethod, err := h.service.User.UpdatePaymentMethod(ctx, userID, paymentMethodID, req)
+	if err != nil {
+		respondWithPaymentMethodError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, paymentMethod)
+}
+
+// DeletePaymentMethod is a handler function that deletes a payment method of the user in the user_id query
+// parameter. Invoices already sent with the payment method keep showing it.
+func (h *handlerImpl) DeletePaymentMethod(ctx *gin.Context) {
+	userID, paymentMethodID, ok := getPaymentMethodParams(ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.service.User.DeletePaymentMethod(ctx, userID, paymentMethodID); err != nil {
+		respondWithPaymentMethodError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// SetDefaultPaymentMethod is a handler function that makes a payment method the default of the user in the user_id
+// query parameter.
+func (h *handlerImpl) SetDefaultPaymentMethod(ctx *gin.Context) {
+	userID, paymentMethodID, ok := getPaymentMethodParams(ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.service.User.SetDefaultPaymentMethod(ctx, userID, paymentMethodID); err != nil {
+		respondWithPaymentMethodError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// getPaymentMethodParams is a helper function that parses the user_id query parameter and the paymentMethodID
+// path parameter. It responds with a 400 status and returns false when either is not a valid ID.
+func getPaymentMethodParams(ctx *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := uuid.Parse(ctx.Query("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	paymentMethodID, err := uuid.Parse(ctx.Param("paymentMethodID"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment method ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, paymentMethodID, true
+}
+
+// respondWithPaymentMethodError responds with a 400 status listing the invalid fields when the bank details are
+// invalid, a 404 status when the payment method was not found, a 409 status when a payment method invoices were sent
+// with is changed and a 500 status for any other error.
+func respondWithPaymentMethodError(ctx *gin.Context, err error) {
+	if fieldErrs := validation.FieldErrors(err); fieldErrs != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "fields": fieldErrs})
+		return
+	}
+	if errors.Is(err, models.ErrPaymentMethodNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Payment method not found"})
+		return
+	}
+	if errors.Is(err, models.ErrPaymentMethodInUse) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,274 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/service"
+	"github.com/zde37/Numeris-Task/internal/validation"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetPaymentMethods(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocked.NewMockUserService(ctrl)
+	srv := &service.Service{
+		User: mockUserService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	userID := uuid.New()
+
+	t.Run("successful retrieval", func(t *testing.T) {
+		expected := []models.UserPaymentMethod{
+			{PaymentMethodID: uuid.New(), UserID: userID, BankName: "Test Bank", IsDefault: true},
+			{PaymentMethodID: uuid.New(), UserID: userID, BankName: "Other Bank"},
+		}
+		mockUserService.EXPECT().
+			GetPaymentMethods(gomock.Any(), userID).
+			Return(expected, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/payment-methods?user_id="+userID.String(), nil)
+
+		handler.GetPaymentMethods(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response []models.UserPaymentMethod
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, expected, response)
+	})
+
+	t.Run("invalid user ID", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/payment-methods?user_id=invalid", nil)
+
+		handler.GetPaymentMethods(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestUpdatePaymentMethod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocked.NewMockUserService(ctrl)
+	srv := &service.Service{
+		User: mockUserService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	userID, paymentMethodID := uuid.New(), uuid.New()
+	newRequest := func(body string) *http.Request {
+		req, _ := http.NewRequest(http.MethodPatch, "/payment-methods/"+paymentMethodID.String()+"?user_id="+userID.String(), bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	t.Run("successful update", func(t *testing.T) {
+		bankName := "New Bank"
+		expected := &models.UserPaymentMethod{PaymentMethodID: paymentMethodID, UserID: userID, BankName: bankName}
+		mockUserService.EXPECT().
+			UpdatePaymentMethod(gomock.Any(), userID, paymentMethodID, models.UpdatePaymentMethodRequest{BankName: &bankName}).
+			Return(expected, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newRequest(`{"bank_name": "New Bank"}`)
+		c.Params = gin.Params{{Key: "paymentMethodID", Value: paymentMethodID.String()}}
+
+		handler.UpdatePaymentMethod(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response models.UserPaymentMethod
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, *expected, response)
+	})
+
+	t.Run("payment method not found", func(t *testing.T) {
+		mockUserService.EXPECT().
+			UpdatePaymentMethod(gomock.Any(), userID, paymentMethodID, gomock.Any()).
+			Return(nil, models.ErrPaymentMethodNotFound)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newRequest(`{"bank_name": "New Bank"}`)
+		c.Params = gin.Params{{Key: "paymentMethodID", Value: paymentMethodID.String()}}
+
+		handler.UpdatePaymentMethod(c)
+
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("payment method used by invoices", func(t *testing.T) {
+		mockUserService.EXPECT().
+			UpdatePaymentMethod(gomock.Any(), userID, paymentMethodID, gomock.Any()).
+			Return(nil, models.ErrPaymentMethodInUse)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newRequest(`{"bank_name": "New Bank"}`)
+		c.Params = gin.Params{{Key: "paymentMethodID", Value: paymentMethodID.String()}}
+
+		handler.UpdatePaymentMethod(c)
+
+		require.Equal(t, http.StatusConflict, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, models.ErrPaymentMethodInUse.Error(), response["error"])
+	})
+
+	t.Run("invalid bank details", func(t *testing.T) {
+		mockUserService.EXPECT().
+			UpdatePaymentMethod(gomock.Any(), userID, paymentMethodID, gomock.Any()).
+			Return(nil, validation.Errors{{Field: "swift_code", Message: "must be 8 or 11 characters"}})
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newRequest(`{"swift_code": "TESTSWIFT"}`)
+		c.Params = gin.Params{{Key: "paymentMethodID", Value: paymentMethodID.String()}}
+
+		handler.UpdatePaymentMethod(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response struct {
+			Error  string            `json:"error"`
+			Fields validation.Errors `json:"fields"`
+		}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, "swift_code: must be 8 or 11 characters", response.Error)
+		require.Equal(t, validation.Errors{{Field: "swift_code", Message: "must be 8 or 11 characters"}}, response.Fields)
+	})
+
+	t.Run("empty update", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newRequest(`{}`)
+		c.Params = gin.Params{{Key: "paymentMethodID", Value: paymentMethodID.String()}}
+
+		handler.UpdatePaymentMethod(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, "at least one field must be updated", response["error"])
+	})
+
+	t.Run("invalid payment method ID", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newRequest(`{"bank_name": "New Bank"}`)
+		c.Params = gin.Params{{Key: "paymentMethodID", Value: "invalid-uuid"}}
+
+		handler.UpdatePaymentMethod(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, "Invalid payment method ID", response["error"])
+	})
+}
+
+func TestDeletePaymentMethod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocked.NewMockUserService(ctrl)
+	srv := &service.Service{
+		User: mockUserService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	userID, paymentMethodID := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"successful deletion", nil, http.StatusNoContent},
+		{"payment method not found", models.ErrPaymentMethodNotFound, http.StatusNotFound},
+		{"service error", errors.New("database error"), http.StatusInternalServerError},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUserService.EXPECT().
+				DeletePaymentMethod(gomock.Any(), userID, paymentMethodID).
+				Return(tc.err)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodDelete, "/payment-methods/"+paymentMethodID.String()+"?user_id="+userID.String(), nil)
+			c.Params = gin.Params{{Key: "paymentMethodID", Value: paymentMethodID.String()}}
+
+			handler.DeletePaymentMethod(c)
+			c.Writer.WriteHeaderNow()
+
+			require.Equal(t, tc.code, w.Code)
+		})
+	}
+}
+
+func TestSetDefaultPaymentMethod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocked.NewMockUserService(ctrl)
+	srv := &service.Service{
+		User: mockUserService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	userID, paymentMethodID := uuid.New(), uuid.New()
+
+	t.Run("successful change", func(t *testing.T) {
+		mockUserService.EXPECT().
+			SetDefaultPaymentMethod(gomock.Any(), userID, paymentMethodID).
+			Return(nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/payment-methods/"+paymentMethodID.String()+"/make-default?user_id="+userID.String(), nil)
+		c.Params = gin.Params{{Key: "paymentMethodID", Value: paymentMethodID.String()}}
+
+		handler.SetDefaultPaymentMethod(c)
+		c.Writer.WriteHeaderNow()
+
+		require.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("invalid user ID", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/payment-methods/"+paymentMethodID.String()+"/make-default", nil)
+		c.Params = gin.Params{{Key: "paymentMethodID", Value: paymentMethodID.String()}}
+
+		handler.SetDefaultPaymentMethod(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, "Invalid user ID", response["error"])
+	})
+}
@@ -0,0 +1,404 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Numeris-Task/internal/gateway"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/service"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRecordPayment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPaymentService := mocked.NewMockPaymentService(ctrl)
+	srv := &service.Service{
+		Payment: mockPaymentService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	t.Run("successful payment", func(t *testing.T) {
+		req := models.RecordPaymentRequest{
+			InvoiceID: uuid.New().String(),
+			UserID:    uuid.New().String(),
+			Amount:    500,
+			PaidOn:    "2024-02-15",
+			Reference: "TRF-001",
+		}
+		expectedPaymentID := uuid.New()
+
+		mockPaymentService.EXPECT().
+			RecordPayment(gomock.Any(), req).
+			Return(expectedPaymentID, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		jsonData, _ := json.Marshal(req)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/invoices/payments", bytes.NewBuffer(jsonData))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.RecordPayment(c)
+
+		require.Equal(t, http.StatusCreated, w.Code)
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, expectedPaymentID.String(), response["payment_id"])
+	})
+
+	t.Run("missing paid on date", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		body := `{"invoice_id": "` + uuid.New().String() + `", "user_id": "` + uuid.New().String() + `", "amount": 100}`
+		c.Request, _ = http.NewRequest(http.MethodPost, "/invoices/payments", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.RecordPayment(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("negative amount", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		body := `{"invoice_id": "` + uuid.New().String() + `", "user_id": "` + uuid.New().String() + `", "amount": -100, "paid_on": "2024-02-15"}`
+		c.Request, _ = http.NewRequest(http.MethodPost, "/invoices/payments", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.RecordPayment(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, "amount must be greater than 0", response["error"])
+	})
+
+	t.Run("invalid paid on date", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		body := `{"invoice_id": "` + uuid.New().String() + `", "user_id": "` + uuid.New().String() + `", "amount": 100, "paid_on": "15-02-2024"}`
+		c.Request, _ = http.NewRequest(http.MethodPost, "/invoices/payments", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.RecordPayment(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("invalid invoice id", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		body := `{"invoice_id": "invalid", "user_id": "` + uuid.New().String() + `", "amount": 100, "paid_on": "2024-02-15"}`
+		c.Request, _ = http.NewRequest(http.MethodPost, "/invoices/payments", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.RecordPayment(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	for _, tc := range []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"invoice not found", models.ErrInvoiceNotFound, http.StatusNotFound},
+		{"already paid", models.ErrInvoiceAlreadyPaid, http.StatusConflict},
+		{"draft", models.ErrInvoiceIsDraft, http.StatusConflict},
+		{"exceeds balance", fmt.Errorf("%w of NGN 100.00", models.ErrPaymentExceedsBalance), http.StatusBadRequest},
+		{"service error", errors.New("connection refused"), http.StatusInternalServerError},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mockPaymentService.EXPECT().
+				RecordPayment(gomock.Any(), gomock.Any()).
+				Return(uuid.Nil, tc.err)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			body := `{"invoice_id": "` + uuid.New().String() + `", "user_id": "` + uuid.New().String() + `", "amount": 100, "paid_on": "2024-02-15"}`
+			c.Request, _ = http.NewRequest(http.MethodPost, "/invoices/payments", bytes.NewBufferString(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.RecordPayment(c)
+
+			require.Equal(t, tc.status, w.Code)
+		})
+	}
+}
+
+func TestCreatePaymentLink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPaymentService := mocked.NewMockPaymentService(ctrl)
+	srv := &service.Service{
+		Payment: mockPaymentService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+	invoiceID, userID := uuid.New(), uuid.New()
+
+	newContext := func(invoiceID, userID string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/invoices/"+invoiceID+"/payment-link?user_id="+userID, nil)
+		c.Params = gin.Params{{Key: "invoiceID", Value: invoiceID}}
+		return c, w
+	}
+
+	t.Run("successful link", func(t *testing.T) {
+		session := &models.PaymentSession{
+			PaymentSessionID:  uuid.New(),
+			InvoiceID:         invoiceID,
+			Provider:          "fake",
+			ProviderSessionID: "fake_cs_1",
+			URL:               "http://localhost:8080/checkout/fake_cs_1",
+			Amount:            150,
+			Currency:          "NGN",
+			Status:            models.PaymentSessionStatusOpen,
+			ExpiresAt:         time.Now().Add(time.Hour),
+		}
+		mockPaymentService.EXPECT().
+			CreatePaymentLink(gomock.Any(), userID, invoiceID).
+			Return(session, nil)
+
+		c, w := newContext(invoiceID.String(), userID.String())
+		handler.CreatePaymentLink(c)
+
+		require.Equal(t, http.StatusCreated, w.Code)
+		var response models.PaymentSession
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Equal(t, session.URL, response.URL)
+		require.Equal(t, session.Amount, response.Amount)
+		require.Equal(t, models.PaymentSessionStatusOpen, response.Status)
+	})
+
+	t.Run("invalid invoice id", func(t *testing.T) {
+		c, w := newContext("invalid", userID.String())
+		handler.CreatePaymentLink(c)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "Invalid invoice ID")
+	})
+
+	t.Run("invalid user id", func(t *testing.T) {
+		c, w := newContext(invoiceID.String(), "invalid")
+		handler.CreatePaymentLink(c)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "Invalid user ID")
+	})
+
+	for _, tc := range []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"provider not configured", service.ErrPaymentsNotConfigured, http.StatusServiceUnavailable},
+		{"invoice not found", models.ErrInvoiceNotFound, http.StatusNotFound},
+		{"invoice not payable", fmt.Errorf("%w: invoice is a draft", service.ErrInvoiceNotPayable), http.StatusBadRequest},
+		{"service error", errors.New("database error"), http.StatusInternalServerError},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mockPaymentService.EXPECT().
+				CreatePaymentLink(gomock.Any(), userID, invoiceID).
+				Return(nil, tc.err)
+
+			c, w := newContext(invoiceID.String(), userID.String())
+			handler.CreatePaymentLink(c)
+			require.Equal(t, tc.code, w.Code)
+		})
+	}
+}
+
+func TestHandlePaymentCallback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPaymentService := mocked.NewMockPaymentService(ctrl)
+	srv := &service.Service{
+		Payment: mockPaymentService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+	payload := `{"session_id": "fake_cs_1", "status": "paid", "amount": 150, "currency": "NGN"}`
+
+	newContext := func() (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/payments/callback", bytes.NewBufferString(payload))
+		c.Request.Header.Set(gateway.FakeSignatureHeader, "signature")
+		return c, w
+	}
+
+	t.Run("successful callback", func(t *testing.T) {
+		mockPaymentService.EXPECT().
+			HandlePaymentCallback(gomock.Any(), []byte(payload), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ []byte, header http.Header) error {
+				require.Equal(t, "signature", header.Get(gateway.FakeSignatureHeader))
+				return nil
+			})
+
+		c, w := newContext()
+		handler.HandlePaymentCallback(c)
+		c.Writer.WriteHeaderNow()
+		require.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	for _, tc := range []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"invalid signature", gateway.ErrInvalidSignature, http.StatusUnauthorized},
+		{"invalid callback", fmt.Errorf("%w: missing session id", gateway.ErrInvalidCallback), http.StatusBadRequest},
+		{"unknown session", models.ErrPaymentSessionNotFound, http.StatusNotFound},
+		{"draft invoice", models.ErrInvoiceIsDraft, http.StatusConflict},
+		{"provider not configured", service.ErrPaymentsNotConfigured, http.StatusServiceUnavailable},
+		{"service error", errors.New("database error"), http.StatusInternalServerError},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mockPaymentService.EXPECT().
+				HandlePaymentCallback(gomock.Any(), []byte(payload), gomock.Any()).
+				Return(tc.err)
+
+			c, w := newContext()
+			handler.HandlePaymentCallback(c)
+			require.Equal(t, tc.code, w.Code)
+		})
+	}
+}
+
+func TestRefundPayment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPaymentService := mocked.NewMockPaymentService(ctrl)
+	srv := &service.Service{
+		Payment: mockPaymentService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+	paymentID, userID := uuid.New(), uuid.New()
+
+	newContext := func(paymentID, body string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/payments/"+paymentID+"/refund", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "paymentID", Value: paymentID}}
+		return c, w
+	}
+	body := fmt.Sprintf(`{"user_id": %q, "amount": 50}`, userID)
+
+	t.Run("successful refund", func(t *testing.T) {
+		refund := &models.PaymentRefund{
+			RefundID:         uuid.New(),
+			PaymentID:        paymentID,
+			InvoiceID:        uuid.New(),
+			UserID:           userID,
+			Provider:         "fake",
+			PaymentReference: "PAY-1",
+			Amount:           50,
+			Currency:         "NGN",
+			Status:           models.PaymentRefundStatusCompleted,
+			ProviderRefundID: "fake_re_1",
+		}
+		mockPaymentService.EXPECT().
+			RefundPayment(gomock.Any(), userID, paymentID, 50.0).
+			Return(refund, nil)
+
+		c, w := newContext(paymentID.String(), body)
+		handler.RefundPayment(c)
+
+		require.Equal(t, http.StatusCreated, w.Code)
+		var response models.PaymentRefund
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Equal(t, 50.0, response.Amount)
+		require.Equal(t, paymentID, response.PaymentID)
+		require.Equal(t, models.PaymentRefundStatusCompleted, response.Status)
+	})
+
+	t.Run("full refund without amount", func(t *testing.T) {
+		mockPaymentService.EXPECT().
+			RefundPayment(gomock.Any(), userID, paymentID, 0.0).
+			Return(&models.PaymentRefund{PaymentID: paymentID, Amount: 150, Status: models.PaymentRefundStatusCompleted}, nil)
+
+		c, w := newContext(paymentID.String(), fmt.Sprintf(`{"user_id": %q}`, userID))
+		handler.RefundPayment(c)
+		require.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("refund pending", func(t *testing.T) {
+		mockPaymentService.EXPECT().
+			RefundPayment(gomock.Any(), userID, paymentID, 50.0).
+			Return(&models.PaymentRefund{PaymentID: paymentID, Amount: 50, Status: models.PaymentRefundStatusPending}, nil)
+
+		c, w := newContext(paymentID.String(), body)
+		handler.RefundPayment(c)
+
+		require.Equal(t, http.StatusAccepted, w.Code)
+		var response models.PaymentRefund
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Equal(t, models.PaymentRefundStatusPending, response.Status)
+	})
+
+	t.Run("invalid payment id", func(t *testing.T) {
+		c, w := newContext("invalid", body)
+		handler.RefundPayment(c)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "Invalid payment ID")
+	})
+
+	t.Run("invalid user id", func(t *testing.T) {
+		c, w := newContext(paymentID.String(), `{"user_id": "invalid"}`)
+		handler.RefundPayment(c)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "Invalid user ID")
+	})
+
+	t.Run("negative amount", func(t *testing.T) {
+		c, w := newContext(paymentID.String(), fmt.Sprintf(`{"user_id": %q, "amount": -5}`, userID))
+		handler.RefundPayment(c)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	for _, tc := range []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"provider not configured", service.ErrPaymentsNotConfigured, http.StatusServiceUnavailable},
+		{"payment not found", models.ErrPaymentNotFound, http.StatusNotFound},
+		{"payment not refundable", fmt.Errorf("%w: it was not made online", models.ErrPaymentNotRefundable), http.StatusConflict},
+		{"refund rejected", fmt.Errorf("failed to refund payment: %w", gateway.ErrRefundRejected), http.StatusConflict},
+		{"refund exceeds payment", fmt.Errorf("%w of NGN 20.00", models.ErrRefundExceedsPayment), http.StatusBadRequest},
+		{"service error", errors.New("database error"), http.StatusInternalServerError},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mockPaymentService.EXPECT().
+				RefundPayment(gomock.Any(), userID, paymentID, 50.0).
+				Return(nil, tc.err)
+
+			c, w := newContext(paymentID.String(), body)
+			handler.RefundPayment(c)
+			require.Equal(t, tc.code, w.Code)
+		})
+	}
+}
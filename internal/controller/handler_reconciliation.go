@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/statement"
+)
+
+// ImportBankStatement is a handler function that imports a bank statement of the user in the user_id query
+// parameter, uploaded in the file form field, and reconciles its credits with the user's unpaid invoices. The
+// format query parameter is csv, ofx or camt.053, and is detected from the statement when omitted.
+func (h *handlerImpl) ImportBankStatement(ctx *gin.Context) {
+	userID, err := uuid.Parse(ctx.Query("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	file, ok := getUploadedFile(ctx)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	result, err := h.service.Reconciliation.ImportStatement(ctx, userID, ctx.Query("format"), file)
+	if err != nil {
+		respondWithReconciliationError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// GetReconciliationInbox is a handler function that retrieves the bank transactions of the user in the user_id query
+// parameter that wait to be confirmed or dismissed, with the invoices each may pay, oldest booking first, with
+// pagination.
+func (h *handlerImpl) GetReconciliationInbox(ctx *gin.Context) {
+	userID, err := uuid.Parse(ctx.Query("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	page, ok := h.getPageRequest(ctx)
+	if !ok {
+		return
+	}
+
+	transactions, meta, err := h.service.Reconciliation.GetReconciliationInbox(ctx, userID, page)
+	if err != nil {
+		respondWithListError(ctx, err)
+		return
+	}
+	respondWithPage(ctx, transactions, meta)
+}
+
+// ConfirmBankTransaction is a handler function that records a bank transaction in the reconciliation inbox as a
+// payment on the invoice in the request body.
+func (h *handlerImpl) ConfirmBankTransaction(ctx *gin.Context) {
+	userID, bankTransactionID, ok := getBankTransactionIDs(ctx)
+	if !ok {
+		return
+	}
+	var req models.ConfirmBankTransactionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := uuid.Parse(req.InvoiceID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invoice ID"})
+		return
+	}
+
+	transaction, err := h.service.Reconciliation.ConfirmBankTransaction(ctx, userID, bankTransactionID, req)
+	if err != nil {
+		respondWithReconciliationError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, transaction)
+}
+
+// DismissBankTransaction is a handler function that removes a bank transaction from the reconciliation inbox without
+// recording a payment.
+func (h *handlerImpl) DismissBankTransaction(ctx *gin.Context) {
+	userID, bankTransactionID, ok := getBankTransactionIDs(ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Reconciliation.DismissBankTransaction(ctx, userID, bankTransactionID); err != nil {
+		respondWithReconciliationError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// getBankTransactionIDs is a helper function that parses the user_id query parameter and the bankTransactionID path
+// parameter. It responds with a 400 status and returns false when either is invalid.
+func getBankTransactionIDs(ctx *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := uuid.Parse(ctx.Query("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	bankTransactionID, err := uuid.Parse(ctx.Param("bankTransactionID"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bank transaction ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, bankTransactionID, true
+}
+
+// respondWithReconciliationError responds with a 400 status for statements that cannot be read and payments in
+// the wrong currency, a 404 status for unknown bank transactions and invoices, a 409 status for bank transactions
+// that already left the inbox and a 500 status for any other error.
+func respondWithReconciliationError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, statement.ErrInvalidStatement), errors.Is(err, models.ErrCurrencyMismatch):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrBankTransactionNotFound), errors.Is(err, models.ErrInvoiceNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrBankTransactionReconciled):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
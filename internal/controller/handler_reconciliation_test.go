@@ -0,0 +1,313 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/pagination"
+	"github.com/zde37/Numeris-Task/internal/service"
+	"github.com/zde37/Numeris-Task/internal/statement"
+	"go.uber.org/mock/gomock"
+)
+
+func TestImportBankStatement(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReconciliationService := mocked.NewMockReconciliationService(ctrl)
+	srv := &service.Service{
+		Reconciliation: mockReconciliationService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	userID := uuid.New()
+	content := "date,amount,currency\n2024-06-01,100,NGN\n"
+
+	t.Run("successful import", func(t *testing.T) {
+		expectedResult := &models.ReconciliationResult{
+			Format: "csv", Credits: 1, Pending: 1, Transactions: []models.BankTransaction{},
+		}
+		mockReconciliationService.EXPECT().
+			ImportStatement(gomock.Any(), userID, "csv", gomock.Any()).
+			DoAndReturn(func(_ any, _ uuid.UUID, _ string, file io.Reader) (*models.ReconciliationResult, error) {
+				data, err := io.ReadAll(file)
+				require.NoError(t, err)
+				require.Equal(t, content, string(data))
+				return expectedResult, nil
+			})
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newImportRequest(t, "/reconciliation/statements?format=csv&user_id="+userID.String(), content)
+
+		handler.ImportBankStatement(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response models.ReconciliationResult
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Equal(t, *expectedResult, response)
+	})
+
+	t.Run("invalid statement", func(t *testing.T) {
+		mockReconciliationService.EXPECT().
+			ImportStatement(gomock.Any(), userID, "", gomock.Any()).
+			Return(nil, fmt.Errorf("%w: missing column amount", statement.ErrInvalidStatement))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newImportRequest(t, "/reconciliation/statements?user_id="+userID.String(), "date\n")
+
+		handler.ImportBankStatement(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "missing column amount")
+	})
+
+	t.Run("invalid user id", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newImportRequest(t, "/reconciliation/statements?user_id=invalid", content)
+
+		handler.ImportBankStatement(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "Invalid user ID")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/reconciliation/statements?user_id="+userID.String(), nil)
+
+		handler.ImportBankStatement(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "file is required")
+	})
+}
+
+func TestGetReconciliationInbox(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReconciliationService := mocked.NewMockReconciliationService(ctrl)
+	srv := &service.Service{
+		Reconciliation: mockReconciliationService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+	userID := uuid.New()
+
+	t.Run("successful retrieval", func(t *testing.T) {
+		transactions := []models.BankTransaction{{
+			BankTransactionID:   uuid.New(),
+			UserID:              userID,
+			Amount:              100,
+			Currency:            "NGN",
+			Status:              models.BankTransactionStatusPending,
+			SuggestedInvoiceIDs: []uuid.UUID{uuid.New()},
+		}}
+		mockReconciliationService.EXPECT().
+			GetReconciliationInbox(gomock.Any(), userID, pagination.Request{Limit: 5, Page: 1}).
+			Return(transactions, &pagination.Meta{Total: 1, Limit: 5}, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/reconciliation/inbox?limit=5&user_id="+userID.String(), nil)
+
+		handler.GetReconciliationInbox(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response pagination.Page[models.BankTransaction]
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Items, 1)
+		require.Equal(t, transactions[0].SuggestedInvoiceIDs, response.Items[0].SuggestedInvoiceIDs)
+		require.Equal(t, int64(1), response.Meta.Total)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/reconciliation/inbox?limit=abc&user_id="+userID.String(), nil)
+
+		handler.GetReconciliationInbox(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		mockReconciliationService.EXPECT().
+			GetReconciliationInbox(gomock.Any(), userID, gomock.Any()).
+			Return(nil, nil, pagination.ErrInvalidCursor)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/reconciliation/inbox?user_id="+userID.String(), nil)
+
+		handler.GetReconciliationInbox(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("invalid user id", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/reconciliation/inbox?user_id=invalid", nil)
+
+		handler.GetReconciliationInbox(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		mockReconciliationService.EXPECT().
+			GetReconciliationInbox(gomock.Any(), userID, gomock.Any()).
+			Return(nil, nil, errors.New("database error"))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/reconciliation/inbox?user_id="+userID.String(), nil)
+
+		handler.GetReconciliationInbox(c)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestConfirmBankTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReconciliationService := mocked.NewMockReconciliationService(ctrl)
+	srv := &service.Service{
+		Reconciliation: mockReconciliationService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+	userID, bankTransactionID, invoiceID := uuid.New(), uuid.New(), uuid.New()
+
+	newContext := func(bankTransactionID, body string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodPost,
+			"/reconciliation/inbox/"+bankTransactionID+"/confirm?user_id="+userID.String(), bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "bankTransactionID", Value: bankTransactionID}}
+		return c, w
+	}
+	body := `{"invoice_id": "` + invoiceID.String() + `"}`
+
+	t.Run("successful confirmation", func(t *testing.T) {
+		confirmed := &models.BankTransaction{
+			BankTransactionID: bankTransactionID,
+			Status:            models.BankTransactionStatusConfirmed,
+			InvoiceID:         &invoiceID,
+		}
+		mockReconciliationService.EXPECT().
+			ConfirmBankTransaction(gomock.Any(), userID, bankTransactionID,
+				models.ConfirmBankTransactionRequest{InvoiceID: invoiceID.String()}).
+			Return(confirmed, nil)
+
+		c, w := newContext(bankTransactionID.String(), body)
+		handler.ConfirmBankTransaction(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response models.BankTransaction
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Equal(t, models.BankTransactionStatusConfirmed, response.Status)
+		require.Equal(t, &invoiceID, response.InvoiceID)
+	})
+
+	t.Run("invalid bank transaction id", func(t *testing.T) {
+		c, w := newContext("invalid", body)
+		handler.ConfirmBankTransaction(c)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "Invalid bank transaction ID")
+	})
+
+	t.Run("invalid invoice id", func(t *testing.T) {
+		c, w := newContext(bankTransactionID.String(), `{"invoice_id": "invalid"}`)
+		handler.ConfirmBankTransaction(c)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "Invalid invoice ID")
+	})
+
+	t.Run("missing invoice id", func(t *testing.T) {
+		c, w := newContext(bankTransactionID.String(), `{}`)
+		handler.ConfirmBankTransaction(c)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	for _, tc := range []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"unknown bank transaction", models.ErrBankTransactionNotFound, http.StatusNotFound},
+		{"unknown invoice", models.ErrInvoiceNotFound, http.StatusNotFound},
+		{"already reconciled", models.ErrBankTransactionReconciled, http.StatusConflict},
+		{"currency mismatch", fmt.Errorf("%w: the transaction is in EUR and the invoice in NGN", models.ErrCurrencyMismatch), http.StatusBadRequest},
+		{"service error", errors.New("invoice is already paid"), http.StatusInternalServerError},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mockReconciliationService.EXPECT().
+				ConfirmBankTransaction(gomock.Any(), userID, bankTransactionID, gomock.Any()).
+				Return(nil, tc.err)
+
+			c, w := newContext(bankTransactionID.String(), body)
+			handler.ConfirmBankTransaction(c)
+			require.Equal(t, tc.code, w.Code)
+		})
+	}
+}
+
+func TestDismissBankTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReconciliationService := mocked.NewMockReconciliationService(ctrl)
+	srv := &service.Service{
+		Reconciliation: mockReconciliationService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+	userID, bankTransactionID := uuid.New(), uuid.New()
+
+	newContext := func() (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodPost,
+			"/reconciliation/inbox/"+bankTransactionID.String()+"/dismiss?user_id="+userID.String(), nil)
+		c.Params = gin.Params{{Key: "bankTransactionID", Value: bankTransactionID.String()}}
+		return c, w
+	}
+
+	t.Run("successful dismissal", func(t *testing.T) {
+		mockReconciliationService.EXPECT().DismissBankTransaction(gomock.Any(), userID, bankTransactionID).Return(nil)
+
+		c, w := newContext()
+		handler.DismissBankTransaction(c)
+		c.Writer.WriteHeaderNow()
+
+		require.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("already reconciled", func(t *testing.T) {
+		mockReconciliationService.EXPECT().
+			DismissBankTransaction(gomock.Any(), userID, bankTransactionID).
+			Return(models.ErrBankTransactionReconciled)
+
+		c, w := newContext()
+		handler.DismissBankTransaction(c)
+
+		require.Equal(t, http.StatusConflict, w.Code)
+	})
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/zde37/Numeris-Task/internal/helpers"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+// maxReminderOffset is the furthest a reminder rule can be placed from the due date, in days.
+const maxReminderOffset = 365
+
+// AddReminderRule is a handler function that adds a new payment reminder rule for a sender.
+func (h *handlerImpl) AddReminderRule(ctx *gin.Context) {
+	var req models.AddReminderRuleRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if *req.DaysOffset < -maxReminderOffset || *req.DaysOffset > maxReminderOffset {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "days_offset must be between -365 and 365"})
+		return
+	}
+	if req.Tone != "" {
+		if err := helpers.ValidateReminderTone(req.Tone); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	ruleID, err := h.service.Reminder.AddReminderRule(ctx, req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"rule_id": ruleID})
+}
+
+// GetReminderRules is a handler function that retrieves the payment reminder rules of a given user.
+func (h *handlerImpl) GetReminderRules(ctx *gin.Context) {
+	userID, err := uuid.Parse(ctx.Param("userID"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	rules, err := h.service.Reminder.GetReminderRules(ctx, userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, rules)
+}
+
+// DeleteReminderRule is a handler function that deletes a payment reminder rule of a given user.
+func (h *handlerImpl) DeleteReminderRule(ctx *gin.Context) {
+	userID, err := uuid.Parse(ctx.Param("userID"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	ruleID, err := uuid.Parse(ctx.Param("ruleID"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	if err := h.service.Reminder.DeleteReminderRule(ctx, userID, ruleID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
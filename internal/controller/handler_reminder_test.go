@@ -0,0 +1,264 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/service"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAddReminderRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReminderService := mocked.NewMockReminderService(ctrl)
+	srv := &service.Service{
+		Reminder: mockReminderService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	t.Run("successful rule creation", func(t *testing.T) {
+		offset := 7
+		req := models.AddReminderRuleRequest{
+			UserID:     uuid.New().String(),
+			DaysOffset: &offset,
+			Tone:       string(models.ReminderToneFirm),
+		}
+		expectedRuleID := uuid.New()
+
+		mockReminderService.EXPECT().
+			AddReminderRule(gomock.Any(), req).
+			Return(expectedRuleID, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		jsonData, _ := json.Marshal(req)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/reminders/rules", bytes.NewBuffer(jsonData))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.AddReminderRule(c)
+
+		require.Equal(t, http.StatusCreated, w.Code)
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, expectedRuleID.String(), response["rule_id"])
+	})
+
+	t.Run("on due date rule", func(t *testing.T) {
+		mockReminderService.EXPECT().
+			AddReminderRule(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, req models.AddReminderRuleRequest) (uuid.UUID, error) {
+				require.NotNil(t, req.DaysOffset)
+				require.Equal(t, 0, *req.DaysOffset)
+				return uuid.New(), nil
+			})
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		body := `{"user_id": "` + uuid.New().String() + `", "days_offset": 0}`
+		c.Request, _ = http.NewRequest(http.MethodPost, "/reminders/rules", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.AddReminderRule(c)
+
+		require.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("missing days offset", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		body := `{"user_id": "` + uuid.New().String() + `"}`
+		c.Request, _ = http.NewRequest(http.MethodPost, "/reminders/rules", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.AddReminderRule(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("offset out of range", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		body := `{"user_id": "` + uuid.New().String() + `", "days_offset": 400}`
+		c.Request, _ = http.NewRequest(http.MethodPost, "/reminders/rules", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.AddReminderRule(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Contains(t, response["error"], "days_offset")
+	})
+
+	t.Run("invalid tone", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		body := `{"user_id": "` + uuid.New().String() + `", "days_offset": 3, "tone": "rude"}`
+		c.Request, _ = http.NewRequest(http.MethodPost, "/reminders/rules", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.AddReminderRule(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Contains(t, response["error"], "invalid reminder tone")
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		expectedError := errors.New("service error")
+		mockReminderService.EXPECT().
+			AddReminderRule(gomock.Any(), gomock.Any()).
+			Return(uuid.Nil, expectedError)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		body := `{"user_id": "` + uuid.New().String() + `", "days_offset": -3}`
+		c.Request, _ = http.NewRequest(http.MethodPost, "/reminders/rules", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.AddReminderRule(c)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, expectedError.Error(), response["error"])
+	})
+}
+
+func TestGetReminderRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReminderService := mocked.NewMockReminderService(ctrl)
+	srv := &service.Service{
+		Reminder: mockReminderService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	t.Run("successful retrieval", func(t *testing.T) {
+		userID := uuid.New()
+		expectedRules := []models.ReminderRule{
+			{RuleID: uuid.New(), UserID: userID, DaysOffset: -3, Tone: models.ReminderToneFriendly, IsActive: true},
+		}
+
+		mockReminderService.EXPECT().
+			GetReminderRules(gomock.Any(), userID).
+			Return(expectedRules, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "userID", Value: userID.String()}}
+
+		handler.GetReminderRules(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response []models.ReminderRule
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, expectedRules, response)
+	})
+
+	t.Run("invalid user ID", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "userID", Value: "invalid-uuid"}}
+
+		handler.GetReminderRules(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		userID := uuid.New()
+		mockReminderService.EXPECT().
+			GetReminderRules(gomock.Any(), userID).
+			Return(nil, errors.New("service error"))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "userID", Value: userID.String()}}
+
+		handler.GetReminderRules(c)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestDeleteReminderRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReminderService := mocked.NewMockReminderService(ctrl)
+	srv := &service.Service{
+		Reminder: mockReminderService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	t.Run("successful deletion", func(t *testing.T) {
+		userID, ruleID := uuid.New(), uuid.New()
+		mockReminderService.EXPECT().
+			DeleteReminderRule(gomock.Any(), userID, ruleID).
+			Return(nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "userID", Value: userID.String()}, {Key: "ruleID", Value: ruleID.String()}}
+
+		handler.DeleteReminderRule(c)
+		c.Writer.WriteHeaderNow()
+
+		require.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("invalid rule ID", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "userID", Value: uuid.New().String()}, {Key: "ruleID", Value: "invalid-uuid"}}
+
+		handler.DeleteReminderRule(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, "Invalid rule ID", response["error"])
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		userID, ruleID := uuid.New(), uuid.New()
+		mockReminderService.EXPECT().
+			DeleteReminderRule(gomock.Any(), userID, ruleID).
+			Return(errors.New("reminder rule not found"))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "userID", Value: userID.String()}, {Key: "ruleID", Value: ruleID.String()}}
+
+		handler.DeleteReminderRule(c)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,337 @@
+package controller
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/zde37/Numeris-Task/internal/helpers"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+// GetTaxReport is a handler function that aggregates the tax collected by a sender over a filing period.
+// The report is returned as JSON, or as a CSV file when the format query parameter is csv.
+func (h *handlerImpl) GetTaxReport(ctx *gin.Context) {
+	senderID, err := uuid.Parse(ctx.Query("sender_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sender ID"})
+		return
+	}
+
+	layout := "2006-01-02"
+	from, err := time.Parse(layout, ctx.Query("from"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "from has invalid date format"})
+		return
+	}
+	to, err := time.Parse(layout, ctx.Query("to"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "to has invalid date format"})
+		return
+	}
+	if to.Before(from) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+
+	basis := ctx.DefaultQuery("basis", string(models.TaxReportBasisInvoice))
+	if err := helpers.ValidateTaxReportBasis(basis); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	format := ctx.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+
+	report, err := h.service.Tax.GetTaxReport(ctx, senderID, from, to, models.TaxReportBasis(basis))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if format == "csv" {
+		filename := fmt.Sprintf("tax-report-%s-%s.csv", from.Format(layout), to.Format(layout))
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		ctx.Header("Content-Type", "text/csv")
+		ctx.Status(http.StatusOK)
+		if err := writeTaxReportCSV(ctx.Writer, report); err != nil {
+			ctx.Error(err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
+
+// writeTaxReportCSV writes the lines of a tax report as CSV, one row per tax rate and currency.
+func writeTaxReportCSV(w http.ResponseWriter, report *models.TaxReport) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{"tax_rate_id", "name", "rate", "currency", "taxable_amount", "tax_amount", "invoice_count"})
+	if err != nil {
+		return err
+	}
+
+	for _, line := range report.Lines {
+		err := writer.Write([]string{
+			line.TaxRateID.String(),
+			line.Name,
+			strconv.FormatFloat(line.Rate, 'f', -1, 64),
+			line.Currency,
+			strconv.FormatFloat(line.TaxableAmount, 'f', 2, 64),
+			strconv.FormatFloat(line.TaxAmount, 'f', 2, 64),
+			strconv.Itoa(line.InvoiceCount),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// GetAgingReport is a handler function that buckets the outstanding balances of a sender by days past due, per
+// customer and in total per currency. as_of defaults to today and customer_id limits the report to a single customer.
+// The report is returned as JSON, or as a CSV file when the format query parameter is csv.
+func (h *handlerImpl) GetAgingReport(ctx *gin.Context) {
+	params, ok := parseAgingParams(ctx)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.Aging.GetAgingReport(ctx, params.senderID, params.asOf, params.customerID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if params.format == "csv" {
+		filename := fmt.Sprintf("aging-report-%s.csv", params.asOf.Format("2006-01-02"))
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		ctx.Header("Content-Type", "text/csv")
+		ctx.Status(http.StatusOK)
+		if err := writeAgingReportCSV(ctx.Writer, report); err != nil {
+			ctx.Error(err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
+
+// GetAgingInvoices is a handler function that drills down into an aging bucket, listing the invoices of a sender
+// whose outstanding balance falls in it. It takes the same query parameters as GetAgingReport plus the bucket.
+func (h *handlerImpl) GetAgingInvoices(ctx *gin.Context) {
+	params, ok := parseAgingParams(ctx)
+	if !ok {
+		return
+	}
+
+	bucket := ctx.Query("bucket")
+	if err := helpers.ValidateAgingBucket(bucket); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invoices, err := h.service.Aging.GetAgingInvoices(ctx, params.senderID, params.asOf, models.AgingBucket(bucket), params.customerID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if params.format == "csv" {
+		filename := fmt.Sprintf("aging-invoices-%s-%s.csv", bucket, params.asOf.Format("2006-01-02"))
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		ctx.Header("Content-Type", "text/csv")
+		ctx.Status(http.StatusOK)
+		if err := writeAgingInvoicesCSV(ctx.Writer, invoices); err != nil {
+			ctx.Error(err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, invoices)
+}
+
+// agingParams holds the query parameters shared by the aging report and its drill-down.
+type agingParams struct {
+	senderID   uuid.UUID
+	asOf       time.Time
+	customerID *uuid.UUID
+	format     string
+}
+
+// parseAgingParams reads and validates the query parameters shared by the aging report and its drill-down.
+// It writes a 400 response and returns false when any of them is invalid.
+func parseAgingParams(ctx *gin.Context) (agingParams, bool) {
+	var params agingParams
+	senderID, err := uuid.Parse(ctx.Query("sender_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sender ID"})
+		return params, false
+	}
+	params.senderID = senderID
+
+	params.asOf = time.Now()
+	if asOf := ctx.Query("as_of"); asOf != "" {
+		params.asOf, err = time.Parse("2006-01-02", asOf)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "as_of has invalid date format"})
+			return params, false
+		}
+	}
+
+	if customer := ctx.Query("customer_id"); customer != "" {
+		customerID, err := uuid.Parse(customer)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid customer ID"})
+			return params, false
+		}
+		params.customerID = &customerID
+	}
+
+	params.format = ctx.DefaultQuery("format", "json")
+	if params.format != "json" && params.format != "csv" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return params, false
+	}
+	return params, true
+}
+
+// writeAgingReportCSV writes an aging report as CSV, one row per customer and currency followed by one total row
+// per currency with an empty customer.
+func writeAgingReportCSV(w http.ResponseWriter, report *models.AgingReport) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{
+		"customer_id", "customer_name", "currency", "invoice_count",
+		"current", "days_1_30", "days_31_60", "days_61_90", "over_90", "total",
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, line := range report.Customers {
+		row := append([]string{line.CustomerID.String(), line.CustomerName, line.Currency, strconv.Itoa(line.InvoiceCount)}, agingBalancesCSV(line.AgingBalances)...)
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	for _, total := range report.Totals {
+		row := append([]string{"", "Total", total.Currency, strconv.Itoa(total.InvoiceCount)}, agingBalancesCSV(total.AgingBalances)...)
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeAgingInvoicesCSV writes the invoices of an aging bucket as CSV, one row per invoice.
+func writeAgingInvoicesCSV(w http.ResponseWriter, invoices []models.AgingInvoice) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{
+		"invoice_id", "invoice_number", "customer_id", "customer_name", "currency",
+		"issue_date", "due_date", "days_past_due", "balance", "bucket",
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, invoice := range invoices {
+		err := writer.Write([]string{
+			invoice.InvoiceID.String(),
+			invoice.InvoiceNumber,
+			invoice.CustomerID.String(),
+			invoice.CustomerName,
+			invoice.Currency,
+			invoice.IssueDate.Format("2006-01-02"),
+			invoice.DueDate.Format("2006-01-02"),
+			strconv.Itoa(invoice.DaysPastDue),
+			strconv.FormatFloat(invoice.Balance, 'f', 2, 64),
+			string(invoice.Bucket),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// agingBalancesCSV formats the buckets of aging balances as CSV fields.
+func agingBalancesCSV(balances models.AgingBalances) []string {
+	amounts := []float64{balances.Current, balances.Days1To30, balances.Days31To60, balances.Days61To90, balances.Over90, balances.Total}
+	fields := make([]string, len(amounts))
+	for i, amount := range amounts {
+		fields[i] = strconv.FormatFloat(amount, 'f', 2, 64)
+	}
+	return fields
+}
+
+// GetRevenueReport is a handler function that retrieves the amount a sender invoiced per day, week or month
+// between the from and to dates, by issue date. interval defaults to month and group_by can be customer or currency.
+func (h *handlerImpl) GetRevenueReport(ctx *gin.Context) {
+	h.getSeriesReport(ctx, models.SeriesMetricInvoiced)
+}
+
+// GetCashFlowReport is a handler function that retrieves the amount a sender collected per day, week or month
+// between the from and to dates, by payment date. It takes the same query parameters as GetRevenueReport.
+func (h *handlerImpl) GetCashFlowReport(ctx *gin.Context) {
+	h.getSeriesReport(ctx, models.SeriesMetricCollected)
+}
+
+// getSeriesReport reads the query parameters of a time series report and responds with the report of the given metric.
+func (h *handlerImpl) getSeriesReport(ctx *gin.Context, metric models.SeriesMetric) {
+	senderID, err := uuid.Parse(ctx.Query("sender_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sender ID"})
+		return
+	}
+
+	layout := "2006-01-02"
+	from, err := time.Parse(layout, ctx.Query("from"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "from has invalid date format"})
+		return
+	}
+	to, err := time.Parse(layout, ctx.Query("to"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "to has invalid date format"})
+		return
+	}
+	if to.Before(from) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+
+	interval := ctx.DefaultQuery("interval", string(models.SeriesIntervalMonth))
+	if err := helpers.ValidateSeriesInterval(interval); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	groupBy := ctx.Query("group_by")
+	if err := helpers.ValidateSeriesGroupBy(groupBy); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.service.Report.GetSeriesReport(ctx, models.SeriesFilter{
+		SenderID: senderID,
+		Metric:   metric,
+		Interval: models.SeriesInterval(interval),
+		GroupBy:  models.SeriesGroupBy(groupBy),
+		From:     from,
+		To:       to,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,401 @@
+package controller
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/service"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetTaxReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTaxService := mocked.NewMockTaxService(ctrl)
+	srv := &service.Service{
+		Tax: mockTaxService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	senderID := uuid.New()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	report := &models.TaxReport{
+		SenderID: senderID,
+		From:     from,
+		To:       to,
+		Basis:    models.TaxReportBasisInvoice,
+		Lines: []models.TaxReportLine{
+			{TaxRateID: uuid.New(), Name: "VAT", Rate: 7.5, Currency: "NGN", TaxableAmount: 1000, TaxAmount: 75, InvoiceCount: 2},
+			{TaxRateID: uuid.New(), Name: "GST", Rate: 10, Currency: "USD", TaxableAmount: 200.5, TaxAmount: 20.05, InvoiceCount: 1},
+		},
+	}
+
+	t.Run("json report", func(t *testing.T) {
+		mockTaxService.EXPECT().
+			GetTaxReport(gomock.Any(), senderID, from, to, models.TaxReportBasisInvoice).
+			Return(report, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/reports/tax?sender_id="+senderID.String()+"&from=2024-01-01&to=2024-03-31", nil)
+
+		handler.GetTaxReport(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response models.TaxReport
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, *report, response)
+	})
+
+	t.Run("csv report on cash basis", func(t *testing.T) {
+		mockTaxService.EXPECT().
+			GetTaxReport(gomock.Any(), senderID, from, to, models.TaxReportBasisCash).
+			Return(report, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/reports/tax?sender_id="+senderID.String()+"&from=2024-01-01&to=2024-03-31&basis=cash&format=csv", nil)
+
+		handler.GetTaxReport(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+		require.Contains(t, w.Header().Get("Content-Disposition"), "tax-report-2024-01-01-2024-03-31.csv")
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		require.Equal(t, []string{"tax_rate_id", "name", "rate", "currency", "taxable_amount", "tax_amount", "invoice_count"}, records[0])
+		require.Equal(t, []string{report.Lines[0].TaxRateID.String(), "VAT", "7.5", "NGN", "1000.00", "75.00", "2"}, records[1])
+		require.Equal(t, []string{report.Lines[1].TaxRateID.String(), "GST", "10", "USD", "200.50", "20.05", "1"}, records[2])
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for name, query := range map[string]string{
+			"invalid sender ID": "sender_id=invalid&from=2024-01-01&to=2024-03-31",
+			"missing from":      "sender_id=" + senderID.String() + "&to=2024-03-31",
+			"invalid to":        "sender_id=" + senderID.String() + "&from=2024-01-01&to=31-03-2024",
+			"reversed period":   "sender_id=" + senderID.String() + "&from=2024-03-31&to=2024-01-01",
+			"invalid basis":     "sender_id=" + senderID.String() + "&from=2024-01-01&to=2024-03-31&basis=accrual",
+			"invalid format":    "sender_id=" + senderID.String() + "&from=2024-01-01&to=2024-03-31&format=pdf",
+		} {
+			t.Run(name, func(t *testing.T) {
+				w := httptest.NewRecorder()
+				c, _ := gin.CreateTestContext(w)
+				c.Request, _ = http.NewRequest(http.MethodGet, "/reports/tax?"+query, nil)
+
+				handler.GetTaxReport(c)
+
+				require.Equal(t, http.StatusBadRequest, w.Code)
+			})
+		}
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		mockTaxService.EXPECT().
+			GetTaxReport(gomock.Any(), senderID, from, to, models.TaxReportBasisInvoice).
+			Return(nil, errors.New("service error"))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/reports/tax?sender_id="+senderID.String()+"&from=2024-01-01&to=2024-03-31", nil)
+
+		handler.GetTaxReport(c)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestGetAgingReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAgingService := mocked.NewMockAgingService(ctrl)
+	srv := &service.Service{
+		Aging: mockAgingService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	senderID := uuid.New()
+	customerID := uuid.New()
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	report := &models.AgingReport{
+		SenderID: senderID,
+		AsOf:     asOf,
+		Customers: []models.AgingLine{
+			{CustomerID: customerID, CustomerName: "Acme", Currency: "NGN", InvoiceCount: 2, AgingBalances: models.AgingBalances{
+				Current: 50, Over90: 100.5, Total: 150.5,
+			}},
+		},
+		Totals: []models.AgingTotal{
+			{Currency: "NGN", InvoiceCount: 2, AgingBalances: models.AgingBalances{Current: 50, Over90: 100.5, Total: 150.5}},
+		},
+	}
+
+	t.Run("json report", func(t *testing.T) {
+		mockAgingService.EXPECT().
+			GetAgingReport(gomock.Any(), senderID, asOf, (*uuid.UUID)(nil)).
+			Return(report, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/reports/aging?sender_id="+senderID.String()+"&as_of=2024-06-30", nil)
+
+		handler.GetAgingReport(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response models.AgingReport
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, *report, response)
+	})
+
+	t.Run("csv report for a customer", func(t *testing.T) {
+		mockAgingService.EXPECT().
+			GetAgingReport(gomock.Any(), senderID, asOf, &customerID).
+			Return(report, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/reports/aging?sender_id="+senderID.String()+"&as_of=2024-06-30&customer_id="+customerID.String()+"&format=csv", nil)
+
+		handler.GetAgingReport(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+		require.Contains(t, w.Header().Get("Content-Disposition"), "aging-report-2024-06-30.csv")
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		require.Equal(t, []string{"customer_id", "customer_name", "currency", "invoice_count", "current", "days_1_30", "days_31_60", "days_61_90", "over_90", "total"}, records[0])
+		require.Equal(t, []string{customerID.String(), "Acme", "NGN", "2", "50.00", "0.00", "0.00", "0.00", "100.50", "150.50"}, records[1])
+		require.Equal(t, []string{"", "Total", "NGN", "2", "50.00", "0.00", "0.00", "0.00", "100.50", "150.50"}, records[2])
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for name, query := range map[string]string{
+			"invalid sender ID":   "sender_id=invalid",
+			"invalid as_of":       "sender_id=" + senderID.String() + "&as_of=30-06-2024",
+			"invalid customer ID": "sender_id=" + senderID.String() + "&customer_id=invalid",
+			"invalid format":      "sender_id=" + senderID.String() + "&format=pdf",
+		} {
+			t.Run(name, func(t *testing.T) {
+				w := httptest.NewRecorder()
+				c, _ := gin.CreateTestContext(w)
+				c.Request, _ = http.NewRequest(http.MethodGet, "/reports/aging?"+query, nil)
+
+				handler.GetAgingReport(c)
+
+				require.Equal(t, http.StatusBadRequest, w.Code)
+			})
+		}
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		mockAgingService.EXPECT().
+			GetAgingReport(gomock.Any(), senderID, asOf, (*uuid.UUID)(nil)).
+			Return(nil, errors.New("service error"))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/reports/aging?sender_id="+senderID.String()+"&as_of=2024-06-30", nil)
+
+		handler.GetAgingReport(c)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestGetAgingInvoices(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAgingService := mocked.NewMockAgingService(ctrl)
+	srv := &service.Service{
+		Aging: mockAgingService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	senderID := uuid.New()
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	invoices := []models.AgingInvoice{
+		{
+			InvoiceID:     uuid.New(),
+			InvoiceNumber: "INV-001",
+			CustomerID:    uuid.New(),
+			CustomerName:  "Acme",
+			Currency:      "NGN",
+			IssueDate:     time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			DueDate:       time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			DaysPastDue:   60,
+			Balance:       250,
+			Bucket:        models.AgingBucket31To60,
+		},
+	}
+
+	t.Run("json invoices", func(t *testing.T) {
+		mockAgingService.EXPECT().
+			GetAgingInvoices(gomock.Any(), senderID, asOf, models.AgingBucket31To60, (*uuid.UUID)(nil)).
+			Return(invoices, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/reports/aging/invoices?sender_id="+senderID.String()+"&as_of=2024-06-30&bucket=31-60", nil)
+
+		handler.GetAgingInvoices(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response []models.AgingInvoice
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, invoices, response)
+	})
+
+	t.Run("csv invoices", func(t *testing.T) {
+		mockAgingService.EXPECT().
+			GetAgingInvoices(gomock.Any(), senderID, asOf, models.AgingBucket31To60, (*uuid.UUID)(nil)).
+			Return(invoices, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/reports/aging/invoices?sender_id="+senderID.String()+"&as_of=2024-06-30&bucket=31-60&format=csv", nil)
+
+		handler.GetAgingInvoices(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Header().Get("Content-Disposition"), "aging-invoices-31-60-2024-06-30.csv")
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		require.Equal(t, []string{
+			invoices[0].InvoiceID.String(), "INV-001", invoices[0].CustomerID.String(), "Acme", "NGN",
+			"2024-04-01", "2024-05-01", "60", "250.00", "31-60",
+		}, records[1])
+	})
+
+	t.Run("invalid bucket", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/reports/aging/invoices?sender_id="+senderID.String()+"&bucket=120%2B", nil)
+
+		handler.GetAgingInvoices(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		mockAgingService.EXPECT().
+			GetAgingInvoices(gomock.Any(), senderID, asOf, models.AgingBucketOver90, (*uuid.UUID)(nil)).
+			Return(nil, errors.New("service error"))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/reports/aging/invoices?sender_id="+senderID.String()+"&as_of=2024-06-30&bucket=90%2B", nil)
+
+		handler.GetAgingInvoices(c)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestGetSeriesReports(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReportService := mocked.NewMockReportService(ctrl)
+	srv := &service.Service{
+		Report: mockReportService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	senderID := uuid.New()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	query := "sender_id=" + senderID.String() + "&from=2024-01-01&to=2024-03-31"
+
+	t.Run("monthly revenue", func(t *testing.T) {
+		filter := models.SeriesFilter{SenderID: senderID, Metric: models.SeriesMetricInvoiced, Interval: models.SeriesIntervalMonth, From: from, To: to}
+		report := &models.SeriesReport{
+			SenderID: senderID, Metric: filter.Metric, Interval: filter.Interval, From: from, To: to,
+			Series: []models.Series{{Currency: "NGN", Total: 300, Points: []models.SeriesPoint{
+				{Period: from, Count: 1, Amount: 300},
+			}}},
+		}
+		mockReportService.EXPECT().GetSeriesReport(gomock.Any(), filter).Return(report, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/reports/revenue?"+query, nil)
+
+		handler.GetRevenueReport(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response models.SeriesReport
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, *report, response)
+	})
+
+	t.Run("weekly cash flow per customer", func(t *testing.T) {
+		filter := models.SeriesFilter{
+			SenderID: senderID, Metric: models.SeriesMetricCollected, Interval: models.SeriesIntervalWeek,
+			GroupBy: models.SeriesGroupByCustomer, From: from, To: to,
+		}
+		mockReportService.EXPECT().GetSeriesReport(gomock.Any(), filter).Return(&models.SeriesReport{Series: []models.Series{}}, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/reports/cash-flow?"+query+"&interval=week&group_by=customer", nil)
+
+		handler.GetCashFlowReport(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for name, query := range map[string]string{
+			"invalid sender ID": "sender_id=invalid&from=2024-01-01&to=2024-03-31",
+			"missing to":        "sender_id=" + senderID.String() + "&from=2024-01-01",
+			"reversed period":   "sender_id=" + senderID.String() + "&from=2024-03-31&to=2024-01-01",
+			"invalid interval":  query + "&interval=year",
+			"invalid group by":  query + "&group_by=status",
+		} {
+			t.Run(name, func(t *testing.T) {
+				w := httptest.NewRecorder()
+				c, _ := gin.CreateTestContext(w)
+				c.Request, _ = http.NewRequest(http.MethodGet, "/reports/revenue?"+query, nil)
+
+				handler.GetRevenueReport(c)
+
+				require.Equal(t, http.StatusBadRequest, w.Code)
+			})
+		}
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		mockReportService.EXPECT().GetSeriesReport(gomock.Any(), gomock.Any()).Return(nil, errors.New("service error"))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/reports/cash-flow?"+query, nil)
+
+		handler.GetCashFlowReport(c)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/zde37/Numeris-Task/internal/helpers"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+// CreateTaxRate is a handler function that creates a new tax rate for a sender.
+func (h *handlerImpl) CreateTaxRate(ctx *gin.Context) {
+	var req models.CreateTaxRateRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := helpers.ValidateTaxRate(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	taxRateID, err := h.service.Tax.CreateTaxRate(ctx, req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"tax_rate_id": taxRateID})
+}
+
+// GetTaxRates is a handler function that retrieves the active tax rates of a given user.
+func (h *handlerImpl) GetTaxRates(ctx *gin.Context) {
+	userID, err := uuid.Parse(ctx.Param("userID"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	taxRates, err := h.service.Tax.GetTaxRates(ctx, userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, taxRates)
+}
+
+// DeactivateTaxRate is a handler function that stops a tax rate of a given user from being used on new invoices.
+func (h *handlerImpl) DeactivateTaxRate(ctx *gin.Context) {
+	userID, err := uuid.Parse(ctx.Param("userID"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	taxRateID, err := uuid.Parse(ctx.Param("taxRateID"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tax rate ID"})
+		return
+	}
+
+	if err := h.service.Tax.DeactivateTaxRate(ctx, userID, taxRateID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
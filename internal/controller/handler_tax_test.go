@@ -0,0 +1,225 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/service"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateTaxRate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTaxService := mocked.NewMockTaxService(ctrl)
+	srv := &service.Service{
+		Tax: mockTaxService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	t.Run("successful creation", func(t *testing.T) {
+		rate := 7.5
+		req := models.CreateTaxRateRequest{
+			UserID: uuid.New().String(),
+			Name:   "VAT",
+			Rate:   &rate,
+		}
+		expectedTaxRateID := uuid.New()
+
+		mockTaxService.EXPECT().
+			CreateTaxRate(gomock.Any(), req).
+			Return(expectedTaxRateID, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		jsonData, _ := json.Marshal(req)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/tax-rates", bytes.NewBuffer(jsonData))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.CreateTaxRate(c)
+
+		require.Equal(t, http.StatusCreated, w.Code)
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, expectedTaxRateID.String(), response["tax_rate_id"])
+	})
+
+	t.Run("missing rate", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		body := `{"user_id": "` + uuid.New().String() + `", "name": "VAT"}`
+		c.Request, _ = http.NewRequest(http.MethodPost, "/tax-rates", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.CreateTaxRate(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("rate out of range", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		body := `{"user_id": "` + uuid.New().String() + `", "name": "VAT", "rate": 150}`
+		c.Request, _ = http.NewRequest(http.MethodPost, "/tax-rates", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.CreateTaxRate(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Contains(t, response["error"], "rate must be between 0 and 100")
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		expectedError := errors.New("service error")
+		mockTaxService.EXPECT().
+			CreateTaxRate(gomock.Any(), gomock.Any()).
+			Return(uuid.Nil, expectedError)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		body := `{"user_id": "` + uuid.New().String() + `", "name": "VAT", "rate": 0}`
+		c.Request, _ = http.NewRequest(http.MethodPost, "/tax-rates", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.CreateTaxRate(c)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, expectedError.Error(), response["error"])
+	})
+}
+
+func TestGetTaxRates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTaxService := mocked.NewMockTaxService(ctrl)
+	srv := &service.Service{
+		Tax: mockTaxService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	t.Run("successful retrieval", func(t *testing.T) {
+		userID := uuid.New()
+		expectedTaxRates := []models.TaxRate{
+			{TaxRateID: uuid.New(), UserID: userID, Name: "VAT", Rate: 7.5, IsActive: true},
+		}
+
+		mockTaxService.EXPECT().
+			GetTaxRates(gomock.Any(), userID).
+			Return(expectedTaxRates, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "userID", Value: userID.String()}}
+
+		handler.GetTaxRates(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response []models.TaxRate
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, expectedTaxRates, response)
+	})
+
+	t.Run("invalid user ID", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "userID", Value: "invalid-uuid"}}
+
+		handler.GetTaxRates(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		userID := uuid.New()
+		mockTaxService.EXPECT().
+			GetTaxRates(gomock.Any(), userID).
+			Return(nil, errors.New("service error"))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "userID", Value: userID.String()}}
+
+		handler.GetTaxRates(c)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestDeactivateTaxRate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTaxService := mocked.NewMockTaxService(ctrl)
+	srv := &service.Service{
+		Tax: mockTaxService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	t.Run("successful deactivation", func(t *testing.T) {
+		userID, taxRateID := uuid.New(), uuid.New()
+		mockTaxService.EXPECT().
+			DeactivateTaxRate(gomock.Any(), userID, taxRateID).
+			Return(nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "userID", Value: userID.String()}, {Key: "taxRateID", Value: taxRateID.String()}}
+
+		handler.DeactivateTaxRate(c)
+		c.Writer.WriteHeaderNow()
+
+		require.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("invalid tax rate ID", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "userID", Value: uuid.New().String()}, {Key: "taxRateID", Value: "invalid-uuid"}}
+
+		handler.DeactivateTaxRate(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, "Invalid tax rate ID", response["error"])
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		userID, taxRateID := uuid.New(), uuid.New()
+		mockTaxService.EXPECT().
+			DeactivateTaxRate(gomock.Any(), userID, taxRateID).
+			Return(errors.New("tax rate not found"))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "userID", Value: userID.String()}, {Key: "taxRateID", Value: taxRateID.String()}}
+
+		handler.DeactivateTaxRate(c)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
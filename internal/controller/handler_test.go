@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 	mocked "github.com/zde37/Numeris-Task/internal/mock"
 	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/pagination"
 	"github.com/zde37/Numeris-Task/internal/service"
 	"go.uber.org/mock/gomock"
 )
@@ -140,6 +141,45 @@ func TestCreateInvoice(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, expectedError.Error(), response["error"])
 	})
+
+	t.Run("payment method of another user", func(t *testing.T) {
+		req := models.CreateInvoiceRequest{
+			Invoice: models.InvoiceInfo{
+				Status:             string(models.InvoiceStatusPending),
+				SenderID:           uuid.New().String(),
+				IssueDate:          time.Now().Format("2006-01-02"),
+				DueDate:            time.Now().Format("2006-01-02"),
+				TotalAmount:        10,
+				DiscountPercentage: 10,
+				DiscountedAmount:   1,
+				FinalAmount:        9,
+				Currency:           "NGN",
+				Notes:              "Test invoice",
+			},
+			InvoiceItems:    []models.InvoiceItemDetails{{Name: "Test Item", Description: "Test Description", Quantity: 1, UnitPrice: 10.0}},
+			CustomerID:      uuid.New().String(),
+			PaymentMethodID: uuid.New().String(),
+		}
+
+		mockInvoiceService.EXPECT().
+			CreateInvoice(gomock.Any(), gomock.Any()).
+			Return(uuid.Nil, models.ErrPaymentMethodNotOwned)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		jsonData, _ := json.Marshal(req)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/invoices", bytes.NewBuffer(jsonData))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.CreateInvoice(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, "payment method does not belong to the sender", response["error"])
+	})
 }
 
 func TestGetInvoiceDetails(t *testing.T) {
@@ -180,6 +220,25 @@ func TestGetInvoiceDetails(t *testing.T) {
 		require.Equal(t, expectedDetails, response)
 	})
 
+	t.Run("masked account number", func(t *testing.T) {
+		invoiceID := uuid.New()
+		mockInvoiceService.EXPECT().
+			GetInvoiceDetails(gomock.Any(), invoiceID).
+			Return(&models.InvoiceDetails{
+				PaymentInformation: models.UserPaymentMethod{AccountNumber: "0123456789", MaskedAccountNumber: "****6789"},
+			}, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "invoiceID", Value: invoiceID.String()}}
+
+		handler.GetInvoiceDetails(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), `"account_number":"****6789"`)
+		require.NotContains(t, w.Body.String(), "0123456789")
+	})
+
 	t.Run("invalid invoice ID", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -228,10 +287,9 @@ func TestAddInvoiceActivity(t *testing.T) {
 
 	t.Run("successful activity addition", func(t *testing.T) {
 		req := models.AddInvoiceActivityRequest{
-			InvoiceID:   uuid.New().String(),
-			UserID:      uuid.New().String(),
-			Title:       "Test Title",
-			Description: "Test Desc",
+			InvoiceID: uuid.New().String(),
+			UserID:    uuid.New().String(),
+			Comment:   "Test Comment",
 		}
 		expectedActivityID := uuid.New()
 
@@ -273,10 +331,9 @@ func TestAddInvoiceActivity(t *testing.T) {
 
 	t.Run("service error", func(t *testing.T) {
 		req := models.AddInvoiceActivityRequest{
-			InvoiceID:   uuid.New().String(),
-			UserID:      uuid.New().String(),
-			Title:       "Test Title",
-			Description: "Test Desc",
+			InvoiceID: uuid.New().String(),
+			UserID:    uuid.New().String(),
+			Comment:   "Test Comment",
 		}
 		expectedError := errors.New("service error")
 
@@ -312,32 +369,101 @@ func TestGetTotalByStatus(t *testing.T) {
 	handler := NewHandlerImpl("dev", srv)
 
 	t.Run("successful total retrieval", func(t *testing.T) {
-		status := models.InvoiceStatusPending
-		expectedTotal := float64(1000)
-		expectedCount := int(5)
+		senderID := uuid.New()
+		expectedSummary := &models.InvoiceSummary{
+			SenderID:  senderID,
+			DateField: models.TotalsDateFieldIssue,
+			Statuses: []models.StatusTotals{
+				{
+					Status: models.InvoiceStatusPending,
+					Count:  5,
+					Currencies: []models.CurrencyTotal{
+						{Currency: "NGN", Count: 4, TotalAmount: 1000},
+						{Currency: "USD", Count: 1, TotalAmount: 10},
+					},
+					BaseCurrencies: []models.CurrencyTotal{{Currency: "NGN", Count: 5, TotalAmount: 15805}},
+				},
+			},
+		}
 
 		mockInvoiceService.EXPECT().
-			GetTotalByStatus(gomock.Any(), status).
-			Return(expectedTotal, expectedCount, nil)
+			GetTotalByStatus(gomock.Any(), models.TotalsFilter{SenderID: senderID, DateField: models.TotalsDateFieldIssue}).
+			Return(expectedSummary, nil)
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
-		c.Params = gin.Params{{Key: "status", Value: string(status)}}
+		c.Params = gin.Params{{Key: "senderID", Value: senderID.String()}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/invoices/totals/"+senderID.String(), nil)
 
 		handler.GetTotalByStatus(c)
 
 		require.Equal(t, http.StatusOK, w.Code)
-		var response map[string]interface{}
+		var response models.InvoiceSummary
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		require.NoError(t, err)
-		require.Equal(t, expectedTotal, response["total_amount"])
-		require.Equal(t, float64(expectedCount), response["count"])
+		require.Equal(t, *expectedSummary, response)
 	})
 
-	t.Run("invalid status", func(t *testing.T) {
+	t.Run("filters", func(t *testing.T) {
+		senderID, customerID := uuid.New(), uuid.New()
+		from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+
+		mockInvoiceService.EXPECT().
+			GetTotalByStatus(gomock.Any(), models.TotalsFilter{
+				SenderID:   senderID,
+				CustomerID: &customerID,
+				DateField:  models.TotalsDateFieldDue,
+				From:       &from,
+				To:         &to,
+			}).
+			Return(&models.InvoiceSummary{SenderID: senderID}, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "senderID", Value: senderID.String()}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/invoices/totals/"+senderID.String()+
+			"?date_field=due_date&from=2024-01-01&to=2024-03-31&customer_id="+customerID.String(), nil)
+
+		handler.GetTotalByStatus(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	badRequests := []struct {
+		name  string
+		query string
+		error string
+	}{
+		{name: "invalid date field", query: "?date_field=created_at", error: "invalid date field: created_at"},
+		{name: "invalid customer ID", query: "?customer_id=invalid-uuid", error: "Invalid customer ID"},
+		{name: "invalid from", query: "?from=01-01-2024", error: "from has invalid date format"},
+		{name: "invalid to", query: "?to=31-03-2024", error: "to has invalid date format"},
+		{name: "from after to", query: "?from=2024-03-31&to=2024-01-01", error: "from must not be after to"},
+	}
+	for _, tc := range badRequests {
+		t.Run(tc.name, func(t *testing.T) {
+			senderID := uuid.New()
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "senderID", Value: senderID.String()}}
+			c.Request, _ = http.NewRequest(http.MethodGet, "/invoices/totals/"+senderID.String()+tc.query, nil)
+
+			handler.GetTotalByStatus(c)
+
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			var response map[string]string
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			require.NoError(t, err)
+			require.Equal(t, tc.error, response["error"])
+		})
+	}
+
+	t.Run("invalid sender ID", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
-		c.Params = gin.Params{{Key: "status", Value: "invalid_status"}}
+		c.Params = gin.Params{{Key: "senderID", Value: "invalid-uuid"}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/invoices/totals/invalid-uuid", nil)
 
 		handler.GetTotalByStatus(c)
 
@@ -345,20 +471,21 @@ func TestGetTotalByStatus(t *testing.T) {
 		var response map[string]string
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		require.NoError(t, err)
-		require.Contains(t, response["error"], "invalid_status")
+		require.Equal(t, "Invalid sender ID", response["error"])
 	})
 
 	t.Run("service error", func(t *testing.T) {
-		status := models.InvoiceStatusPaid
+		senderID := uuid.New()
 		expectedError := errors.New("service error")
 
 		mockInvoiceService.EXPECT().
-			GetTotalByStatus(gomock.Any(), status).
-			Return(float64(0), int(0), expectedError)
+			GetTotalByStatus(gomock.Any(), gomock.Any()).
+			Return(nil, expectedError)
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
-		c.Params = gin.Params{{Key: "status", Value: string(status)}}
+		c.Params = gin.Params{{Key: "senderID", Value: senderID.String()}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/invoices/totals/"+senderID.String(), nil)
 
 		handler.GetTotalByStatus(c)
 
@@ -370,6 +497,75 @@ func TestGetTotalByStatus(t *testing.T) {
 	})
 }
 
+func TestGetTotalForStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockInvoiceService := mocked.NewMockInvoiceService(ctrl)
+	srv := &service.Service{
+		Invoice: mockInvoiceService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	t.Run("successful total retrieval", func(t *testing.T) {
+		senderID := uuid.New()
+		pending := models.StatusTotals{
+			Status:         models.InvoiceStatusPending,
+			Count:          4,
+			Currencies:     []models.CurrencyTotal{{Currency: "NGN", Count: 4, TotalAmount: 1000}},
+			BaseCurrencies: []models.CurrencyTotal{{Currency: "NGN", Count: 4, TotalAmount: 1000}},
+		}
+
+		mockInvoiceService.EXPECT().
+			GetTotalByStatus(gomock.Any(), models.TotalsFilter{SenderID: senderID, DateField: models.TotalsDateFieldIssue}).
+			Return(&models.InvoiceSummary{
+				SenderID: senderID,
+				Statuses: []models.StatusTotals{{Status: models.InvoiceStatusDraft}, pending},
+			}, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "status", Value: "pending"}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/invoices/total/pending?sender_id="+senderID.String(), nil)
+
+		handler.GetTotalForStatus(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "true", w.Header().Get("Deprecation"))
+		require.Equal(t, "</v1/invoices/totals/"+senderID.String()+`>; rel="successor-version"`, w.Header().Get("Link"))
+		var response map[string]float64
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, map[string]float64{"total_amount": 1000, "count": 4}, response)
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "status", Value: "unknown"}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/invoices/total/unknown?sender_id="+uuid.New().String(), nil)
+
+		handler.GetTotalForStatus(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("missing sender ID", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "status", Value: "paid"}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/invoices/total/paid", nil)
+
+		handler.GetTotalForStatus(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, "Invalid sender ID", response["error"])
+	})
+}
+
 func TestGetRecentInvoices(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -390,8 +586,8 @@ func TestGetRecentInvoices(t *testing.T) {
 		}
 
 		mockInvoiceService.EXPECT().
-			GetRecentInvoices(gomock.Any(), senderID, page, limit).
-			Return(expectedInvoices, nil)
+			GetRecentInvoices(gomock.Any(), senderID, pagination.Request{Limit: limit, Page: page}).
+			Return(expectedInvoices, &pagination.Meta{Total: int64(len(expectedInvoices)), Limit: 10}, nil)
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -401,10 +597,10 @@ func TestGetRecentInvoices(t *testing.T) {
 		handler.GetRecentInvoices(c)
 
 		require.Equal(t, http.StatusOK, w.Code)
-		var response []models.Invoice
+		var response pagination.Page[models.Invoice]
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		require.NoError(t, err)
-		require.Equal(t, expectedInvoices, response)
+		require.Equal(t, expectedInvoices, response.Items)
 	})
 
 	t.Run("invalid sender ID", func(t *testing.T) {
@@ -426,8 +622,8 @@ func TestGetRecentInvoices(t *testing.T) {
 		expectedError := errors.New("service error")
 
 		mockInvoiceService.EXPECT().
-			GetRecentInvoices(gomock.Any(), senderID, gomock.Any(), gomock.Any()).
-			Return(nil, expectedError)
+			GetRecentInvoices(gomock.Any(), senderID, gomock.Any()).
+			Return(nil, nil, expectedError)
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -450,8 +646,8 @@ func TestGetRecentInvoices(t *testing.T) {
 		expectedInvoices := []models.Invoice{}
 
 		mockInvoiceService.EXPECT().
-			GetRecentInvoices(gomock.Any(), senderID, page, limit).
-			Return(expectedInvoices, nil)
+			GetRecentInvoices(gomock.Any(), senderID, pagination.Request{Limit: limit, Page: page}).
+			Return(expectedInvoices, &pagination.Meta{Total: int64(len(expectedInvoices)), Limit: 10}, nil)
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -461,10 +657,198 @@ func TestGetRecentInvoices(t *testing.T) {
 		handler.GetRecentInvoices(c)
 
 		require.Equal(t, http.StatusOK, w.Code)
-		var response []models.Invoice
+		var response pagination.Page[models.Invoice]
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, expectedInvoices, response.Items)
+	})
+
+	t.Run("cursor pagination", func(t *testing.T) {
+		senderID := uuid.New()
+		cursor := pagination.Cursor{Key: "2024-06-01T10:00:00Z", ID: uuid.New()}
+		expectedInvoices := []models.Invoice{{InvoiceID: uuid.New()}}
+		meta := &pagination.Meta{Total: 25, Limit: 10, NextCursor: "next-token", PrevCursor: "prev-token"}
+
+		mockInvoiceService.EXPECT().
+			GetRecentInvoices(gomock.Any(), senderID, pagination.Request{Limit: 10, Page: 3, Cursor: &cursor}).
+			Return(expectedInvoices, meta, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "senderID", Value: senderID.String()}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/v1/invoices/recent/"+senderID.String()+"?page=3&cursor="+cursor.Encode(), nil)
+
+		handler.GetRecentInvoices(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response pagination.Page[models.Invoice]
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, expectedInvoices, response.Items)
+		require.Equal(t, int64(25), response.Total)
+		require.Equal(t, "/v1/invoices/recent/"+senderID.String()+"?cursor=next-token", response.Next)
+		require.Equal(t, "/v1/invoices/recent/"+senderID.String()+"?cursor=prev-token", response.Prev)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "senderID", Value: uuid.New().String()}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/invoices/recent?cursor=not-a-cursor", nil)
+
+		handler.GetRecentInvoices(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, "Invalid cursor", response["error"])
+	})
+
+	t.Run("limit out of bounds", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "senderID", Value: uuid.New().String()}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/invoices/recent?limit=1000000", nil)
+
+		handler.GetRecentInvoices(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, pagination.ErrInvalidLimit.Error(), response["error"])
+	})
+}
+
+func TestSearchInvoices(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockInvoiceService := mocked.NewMockInvoiceService(ctrl)
+	srv := &service.Service{
+		Invoice: mockInvoiceService,
+	}
+	handler := NewHandlerImpl("dev", srv)
+
+	senderID := uuid.New()
+
+	t.Run("successful search with every filter", func(t *testing.T) {
+		customerID := uuid.New()
+		issueFrom := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		dueTo := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+		minAmount, maxAmount := 100.0, 2500.5
+		expectedFilter := models.InvoiceFilter{
+			SenderID:     senderID,
+			Statuses:     []models.InvoiceStatus{models.InvoiceStatusPending, models.InvoiceStatusOverDue, models.InvoiceStatusDraft},
+			CustomerID:   &customerID,
+			Currency:     "USD",
+			IssueFrom:    &issueFrom,
+			DueTo:        &dueTo,
+			MinAmount:    &minAmount,
+			MaxAmount:    &maxAmount,
+			NumberPrefix: "INV-2024",
+			Search:       "design",
+			SortBy:       models.InvoiceSortFieldAmount,
+			SortOrder:    models.SortOrderAsc,
+		}
+		expectedInvoices := []models.Invoice{{Status: string(models.InvoiceStatusPending)}}
+
+		mockInvoiceService.EXPECT().
+			SearchInvoices(gomock.Any(), expectedFilter, pagination.Request{Limit: 20, Page: 2}).
+			Return(expectedInvoices, &pagination.Meta{Total: int64(len(expectedInvoices)), Limit: 10}, nil)
+
+		query := "sender_id=" + senderID.String() + "&status=pending,overdue&status=draft&customer_id=" + customerID.String() +
+			"&currency=usd&issue_from=2024-01-01&due_to=2024-06-30&min_amount=100&max_amount=2500.5" +
+			"&number=INV-2024&q=design&sort=amount&order=asc&page=2&limit=20"
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/invoices?"+query, nil)
+
+		handler.SearchInvoices(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response pagination.Page[models.Invoice]
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		require.NoError(t, err)
-		require.Equal(t, expectedInvoices, response)
+		require.Equal(t, expectedInvoices, response.Items)
+	})
+
+	t.Run("defaults to newest first", func(t *testing.T) {
+		mockInvoiceService.EXPECT().
+			SearchInvoices(gomock.Any(), models.InvoiceFilter{
+				SenderID: senderID, SortBy: models.InvoiceSortFieldCreatedAt, SortOrder: models.SortOrderDesc,
+			}, pagination.Request{Limit: 10, Page: 1}).
+			Return([]models.Invoice{}, &pagination.Meta{Limit: 10}, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/invoices?sender_id="+senderID.String(), nil)
+
+		handler.SearchInvoices(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		sender := "sender_id=" + senderID.String()
+		for name, query := range map[string]string{
+			"invalid sender ID":   "sender_id=invalid",
+			"invalid status":      sender + "&status=pending,void",
+			"invalid customer ID": sender + "&customer_id=invalid",
+			"invalid currency":    sender + "&currency=XYZ",
+			"invalid date":        sender + "&due_from=01-01-2024",
+			"invalid amount":      sender + "&min_amount=ten",
+			"negative amount":     sender + "&max_amount=-5",
+			"invalid sort":        sender + "&sort=customer",
+			"invalid order":       sender + "&order=up",
+			"issue range":         sender + "&issue_from=2024-02-01&issue_to=2024-01-01",
+			"due range":           sender + "&due_from=2024-02-01&due_to=2024-01-01",
+			"amount range":        sender + "&min_amount=100&max_amount=10",
+		} {
+			t.Run(name, func(t *testing.T) {
+				w := httptest.NewRecorder()
+				c, _ := gin.CreateTestContext(w)
+				c.Request, _ = http.NewRequest(http.MethodGet, "/invoices?"+query, nil)
+
+				handler.SearchInvoices(c)
+
+				require.Equal(t, http.StatusBadRequest, w.Code)
+			})
+		}
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		mockInvoiceService.EXPECT().
+			SearchInvoices(gomock.Any(), gomock.Any(), pagination.Request{Limit: 10, Page: 1}).
+			Return(nil, nil, errors.New("service error"))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/invoices?sender_id="+senderID.String(), nil)
+
+		handler.SearchInvoices(c)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("cursor of another sort", func(t *testing.T) {
+		cursor := pagination.Cursor{Key: "2024-06-01T10:00:00Z", ID: uuid.New(), Sort: "created_at desc"}
+		mockInvoiceService.EXPECT().
+			SearchInvoices(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, nil, pagination.ErrInvalidCursor)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/invoices?sort=amount&sender_id="+senderID.String()+
+			"&cursor="+cursor.Encode(), nil)
+
+		handler.SearchInvoices(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]string
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Equal(t, "Invalid cursor", response["error"])
 	})
 }
 
@@ -488,8 +872,8 @@ func TestGetRecentActivities(t *testing.T) {
 		}
 
 		mockInvoiceService.EXPECT().
-			GetRecentActivities(gomock.Any(), userID, page, limit).
-			Return(expectedActivities, nil)
+			GetRecentActivities(gomock.Any(), userID, models.ActivityFilter{}, pagination.Request{Limit: limit, Page: page}).
+			Return(expectedActivities, &pagination.Meta{Total: int64(len(expectedActivities)), Limit: 10}, nil)
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -499,10 +883,10 @@ func TestGetRecentActivities(t *testing.T) {
 		handler.GetRecentActivities(c)
 
 		require.Equal(t, http.StatusOK, w.Code)
-		var response []models.RecentActivity
+		var response pagination.Page[models.RecentActivity]
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		require.NoError(t, err)
-		require.Equal(t, expectedActivities, response)
+		require.Equal(t, expectedActivities, response.Items)
 	})
 
 	t.Run("invalid user ID", func(t *testing.T) {
@@ -525,7 +909,7 @@ func TestGetRecentActivities(t *testing.T) {
 
 		mockInvoiceService.EXPECT().
 			GetRecentActivities(gomock.Any(), userID, gomock.Any(), gomock.Any()).
-			Return(nil, expectedError)
+			Return(nil, nil, expectedError)
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -548,8 +932,8 @@ func TestGetRecentActivities(t *testing.T) {
 		expectedActivities := []models.RecentActivity{}
 
 		mockInvoiceService.EXPECT().
-			GetRecentActivities(gomock.Any(), userID, page, limit).
-			Return(expectedActivities, nil)
+			GetRecentActivities(gomock.Any(), userID, models.ActivityFilter{}, pagination.Request{Limit: limit, Page: page}).
+			Return(expectedActivities, &pagination.Meta{Total: int64(len(expectedActivities)), Limit: 10}, nil)
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -559,10 +943,42 @@ func TestGetRecentActivities(t *testing.T) {
 		handler.GetRecentActivities(c)
 
 		require.Equal(t, http.StatusOK, w.Code)
-		var response []models.RecentActivity
+		var response pagination.Page[models.RecentActivity]
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		require.NoError(t, err)
-		require.Equal(t, expectedActivities, response)
+		require.Equal(t, expectedActivities, response.Items)
+	})
+
+	t.Run("type and source filters", func(t *testing.T) {
+		userID := uuid.New()
+		filter := models.ActivityFilter{
+			EventTypes: []models.ActivityEventType{models.ActivityEventPaymentRecorded, models.ActivityEventStatusChanged},
+			Source:     models.ActivitySourceSystem,
+		}
+
+		mockInvoiceService.EXPECT().
+			GetRecentActivities(gomock.Any(), userID, filter, gomock.Any()).
+			Return([]models.RecentActivity{}, &pagination.Meta{Limit: 10}, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "userID", Value: userID.String()}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/activities/recent?type=payment_recorded,status_changed&source=system", nil)
+
+		handler.GetRecentActivities(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("invalid activity type", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "userID", Value: uuid.NewString()}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/activities/recent?type=unknown", nil)
+
+		handler.GetRecentActivities(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
 	})
 }
 
@@ -582,13 +998,13 @@ func TestGetInvoiceActivities(t *testing.T) {
 		limit := int32(10)
 		page := int32(1)
 		expectedActivities := []models.InvoiceActivity{
-			{Title: "Activity 1"},
-			{Title: "Activity 2"},
+			{Title: "Activity 1", EventType: models.ActivityEventComment, Source: models.ActivitySourceUser, Payload: json.RawMessage(`{}`)},
+			{Title: "Activity 2", EventType: models.ActivityEventStatusChanged, Source: models.ActivitySourceSystem, Payload: json.RawMessage(`{"from":"pending","to":"paid"}`)},
 		}
 
 		mockInvoiceService.EXPECT().
-			GetInvoiceActivities(gomock.Any(), userID, invoiceID, page, limit).
-			Return(expectedActivities, nil)
+			GetInvoiceActivities(gomock.Any(), userID, invoiceID, models.ActivityFilter{}, pagination.Request{Limit: limit, Page: page}).
+			Return(expectedActivities, &pagination.Meta{Total: int64(len(expectedActivities)), Limit: 10}, nil)
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -601,10 +1017,64 @@ func TestGetInvoiceActivities(t *testing.T) {
 		handler.GetInvoiceActivities(c)
 
 		require.Equal(t, http.StatusOK, w.Code)
-		var response []models.InvoiceActivity
+		var response pagination.Page[models.InvoiceActivity]
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		require.NoError(t, err)
-		require.Equal(t, expectedActivities, response)
+		require.Equal(t, expectedActivities, response.Items)
+	})
+
+	t.Run("filtered by event type and source", func(t *testing.T) {
+		userID := uuid.New()
+		invoiceID := uuid.New()
+		filter := models.ActivityFilter{
+			EventTypes: []models.ActivityEventType{models.ActivityEventPaymentRecorded, models.ActivityEventStatusChanged},
+			Source:     models.ActivitySourceSystem,
+		}
+
+		mockInvoiceService.EXPECT().
+			GetInvoiceActivities(gomock.Any(), userID, invoiceID, filter, gomock.Any()).
+			Return([]models.InvoiceActivity{}, &pagination.Meta{Limit: 10}, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{
+			{Key: "userID", Value: userID.String()},
+			{Key: "invoiceID", Value: invoiceID.String()},
+		}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/invoices/activities?type=payment_recorded,status_changed&source=system", nil)
+
+		handler.GetInvoiceActivities(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("invalid event type", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{
+			{Key: "userID", Value: uuid.New().String()},
+			{Key: "invoiceID", Value: uuid.New().String()},
+		}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/invoices/activities?type=renamed", nil)
+
+		handler.GetInvoiceActivities(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "invalid activity event type: renamed")
+	})
+
+	t.Run("invalid source", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{
+			{Key: "userID", Value: uuid.New().String()},
+			{Key: "invoiceID", Value: uuid.New().String()},
+		}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/invoices/activities?source=bot", nil)
+
+		handler.GetInvoiceActivities(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
 	})
 
 	t.Run("invalid user ID", func(t *testing.T) {
@@ -648,7 +1118,7 @@ func TestGetInvoiceActivities(t *testing.T) {
 
 		mockInvoiceService.EXPECT().
 			GetInvoiceActivities(gomock.Any(), userID, invoiceID, gomock.Any(), gomock.Any()).
-			Return(nil, expectedError)
+			Return(nil, nil, expectedError)
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -862,6 +1332,7 @@ func TestAddCustomer(t *testing.T) {
 
 	t.Run("successful customer addition", func(t *testing.T) {
 		req := models.AddCustomerRequest{
+			UserID:  uuid.NewString(),
 			Name:    "John Doe",
 			Email:   "john@example.com",
 			PhoneNumber:   "+1234567890",
@@ -907,6 +1378,7 @@ func TestAddCustomer(t *testing.T) {
 
 	t.Run("service error", func(t *testing.T) {
 		req := models.AddCustomerRequest{
+			UserID:  uuid.NewString(),
 			Name:    "John Doe",
 			Email:   "john@example.com",
 			PhoneNumber:   "+1234567890",
@@ -933,76 +1405,128 @@ func TestAddCustomer(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, expectedError.Error(), response["error"])
 	})
-}
 
-func TestGetPaginationParams(t *testing.T) {
-	handler := &handlerImpl{}
+	t.Run("invalid user ID", func(t *testing.T) {
+		req := models.AddCustomerRequest{
+			UserID:      "not-a-uuid",
+			Name:        "John Doe",
+			Email:       "john@example.com",
+			PhoneNumber: "+1234567890",
+			Address:     "123 Main St",
+		}
 
-	t.Run("default values", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
-		c.Request, _ = http.NewRequest(http.MethodGet, "/test", nil)
 
-		limit, page := handler.getPaginationParams(c)
+		jsonData, _ := json.Marshal(req)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/customers", bytes.NewBuffer(jsonData))
+		c.Request.Header.Set("Content-Type", "application/json")
 
-		require.Equal(t, int32(10), limit)
-		require.Equal(t, int32(1), page)
-	})
+		handler.AddCustomer(c)
 
-	t.Run("custom valid values", func(t *testing.T) {
-		w := httptest.NewRecorder()
-		c, _ := gin.CreateTestContext(w)
-		c.Request, _ = http.NewRequest(http.MethodGet, "/test?limit=20&page=2", nil)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, "Invalid user ID", response["error"])
+	})
 
-		limit, page := handler.getPaginationParams(c)
+	t.Run("unknown user", func(t *testing.T) {
+		req := models.AddCustomerRequest{
+			UserID:      uuid.NewString(),
+			Name:        "John Doe",
+			Email:       "john@example.com",
+			PhoneNumber: "+1234567890",
+			Address:     "123 Main St",
+		}
 
-		require.Equal(t, int32(20), limit)
-		require.Equal(t, int32(2), page)
-	})
+		mockUserService.EXPECT().
+			AddCustomer(gomock.Any(), req).
+			Return(uuid.Nil, models.ErrUserNotFound)
 
-	t.Run("invalid limit", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
-		c.Request, _ = http.NewRequest(http.MethodGet, "/test?limit=invalid&page=2", nil)
 
-		limit, page := handler.getPaginationParams(c)
+		jsonData, _ := json.Marshal(req)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/customers", bytes.NewBuffer(jsonData))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.AddCustomer(c)
 
-		require.Equal(t, int32(10), limit)
-		require.Equal(t, int32(2), page)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]interface{}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Equal(t, "User not found", response["error"])
 	})
+}
+
+func TestGetPageRequest(t *testing.T) {
+	handler := &handlerImpl{}
 
-	t.Run("invalid page", func(t *testing.T) {
+	t.Run("default values", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
-		c.Request, _ = http.NewRequest(http.MethodGet, "/test?limit=20&page=invalid", nil)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/test", nil)
 
-		limit, page := handler.getPaginationParams(c)
+		page, ok := handler.getPageRequest(c)
 
-		require.Equal(t, int32(20), limit)
-		require.Equal(t, int32(1), page)
+		require.True(t, ok)
+		require.Equal(t, pagination.Request{Limit: 10, Page: 1}, page)
 	})
 
-	t.Run("negative values", func(t *testing.T) {
+	t.Run("custom valid values", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
-		c.Request, _ = http.NewRequest(http.MethodGet, "/test?limit=-5&page=-1", nil)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/test?limit=20&page=2", nil)
 
-		limit, page := handler.getPaginationParams(c)
+		page, ok := handler.getPageRequest(c)
 
-		require.Equal(t, int32(-5), limit)
-		require.Equal(t, int32(-1), page)
+		require.True(t, ok)
+		require.Equal(t, pagination.Request{Limit: 20, Page: 2}, page)
 	})
 
-	t.Run("zero values", func(t *testing.T) {
+	t.Run("maximum limit", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
-		c.Request, _ = http.NewRequest(http.MethodGet, "/test?limit=0&page=0", nil)
+		c.Request, _ = http.NewRequest(http.MethodGet, fmt.Sprintf("/test?limit=%d", pagination.MaxLimit), nil)
 
-		limit, page := handler.getPaginationParams(c)
+		page, ok := handler.getPageRequest(c)
 
-		require.Equal(t, int32(0), limit)
-		require.Equal(t, int32(0), page)
+		require.True(t, ok)
+		require.Equal(t, pagination.MaxLimit, page.Limit)
 	})
+
+	invalid := []struct {
+		name  string
+		query string
+		err   error
+	}{
+		{"invalid limit", "limit=invalid&page=2", pagination.ErrInvalidLimit},
+		{"zero limit", "limit=0", pagination.ErrInvalidLimit},
+		{"negative limit", "limit=-5", pagination.ErrInvalidLimit},
+		{"limit above maximum", "limit=1000000", pagination.ErrInvalidLimit},
+		{"invalid page", "limit=20&page=invalid", pagination.ErrInvalidPage},
+		{"zero page", "page=0", pagination.ErrInvalidPage},
+		{"negative page", "page=-1", pagination.ErrInvalidPage},
+		{"offset overflow", "limit=100&page=2000000000", pagination.ErrInvalidPage},
+	}
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/test?"+tc.query, nil)
+
+			_, ok := handler.getPageRequest(c)
+
+			require.False(t, ok)
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			var response map[string]string
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			require.NoError(t, err)
+			require.Equal(t, tc.err.Error(), response["error"])
+		})
+	}
 }
 
 func TestHelloWorld(t *testing.T) {
@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/zde37/Numeris-Task/internal/helpers"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/pagination"
+)
+
+// CreateWebhook is a handler function that subscribes a URL to the invoice lifecycle events of a user. The response
+// holds the secret the deliveries are signed with, which is not shown again.
+func (h *handlerImpl) CreateWebhook(ctx *gin.Context) {
+	var req models.CreateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := uuid.Parse(req.UserID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	if err := helpers.ValidateWebhook(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook, err := h.service.Webhook.CreateWebhook(ctx, req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, webhook)
+}
+
+// GetWebhooks is a handler function that retrieves the webhooks of the user in the user_id query parameter.
+func (h *handlerImpl) GetWebhooks(ctx *gin.Context) {
+	userID, err := uuid.Parse(ctx.Query("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	webhooks, err := h.service.Webhook.GetWebhooks(ctx, userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, webhooks)
+}
+
+// DeleteWebhook is a handler function that deletes a webhook of the user in the user_id query parameter, along with
+// its delivery log.
+func (h *handlerImpl) DeleteWebhook(ctx *gin.Context) {
+	userID, webhookID, ok := getWebhookIDs(ctx, "webhookID", "Invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Webhook.DeleteWebhook(ctx, userID, webhookID); err != nil {
+		respondWithWebhookError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// EnableWebhook is a handler function that enables a webhook of the user in the user_id query parameter again after
+// it was disabled for failing. Deliveries start again from the events recorded after it is enabled.
+func (h *handlerImpl) EnableWebhook(ctx *gin.Context) {
+	userID, webhookID, ok := getWebhookIDs(ctx, "webhookID", "Invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	webhook, err := h.service.Webhook.EnableWebhook(ctx, userID, webhookID)
+	if err != nil {
+		respondWithWebhookError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, webhook)
+}
+
+// GetWebhookDeliveries is a handler function that retrieves the delivery log of a webhook of the user in the user_id
+// query parameter, newest first, with pagination.
+func (h *handlerImpl) GetWebhookDeliveries(ctx *gin.Context) {
+	userID, webhookID, ok := getWebhookIDs(ctx, "webhookID", "Invalid webhook ID")
+	if !ok {
+		return
+	}
+	page, ok := h.getPageRequest(ctx)
+	if !ok {
+		return
+	}
+
+	deliveries, meta, err := h.service.Webhook.GetWebhookDeliveries(ctx, userID, webhookID, page)
+	if err != nil {
+		respondWithWebhookError(ctx, err)
+		return
+	}
+	respondWithPage(ctx, deliveries, meta)
+}
+
+// ReplayWebhookDelivery is a handler function that sends a delivery of a webhook of the user in the user_id query
+// parameter again. The replay is a new delivery of the same event, sent by the background worker.
+func (h *handlerImpl) ReplayWebhookDelivery(ctx *gin.Context) {
+	userID, deliveryID, ok := getWebhookIDs(ctx, "deliveryID", "Invalid delivery ID")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.service.Webhook.ReplayWebhookDelivery(ctx, userID, deliveryID)
+	if err != nil {
+		respondWithWebhookError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusAccepted, delivery)
+}
+
+// getWebhookIDs is a helper function that parses the user_id query parameter and the given path parameter. It
+// responds with a 400 status and returns false when either is invalid.
+func getWebhookIDs(ctx *gin.Context, param, invalid string) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := uuid.Parse(ctx.Query("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(ctx.Param(param))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": invalid})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, id, true
+}
+
+// respondWithWebhookError responds with a 404 status for unknown webhooks and deliveries, a 400 status for cursors
+// issued for another list and a 500 status for any other error.
+func respondWithWebhookError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrWebhookNotFound), errors.Is(err, models.ErrWebhookDeliveryNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, pagination.ErrInvalidCursor):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,306 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/pagination"
+	"github.com/zde37/Numeris-Task/internal/service"
+	"go.uber.org/mock/gomock"
+)
+
+// newWebhookContext returns a test context for a webhook request with the given path parameter.
+func newWebhookContext(method, url, param, value string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(method, url, nil)
+	c.Params = gin.Params{{Key: param, Value: value}}
+	return c, w
+}
+
+func TestCreateWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWebhookService := mocked.NewMockWebhookService(ctrl)
+	handler := NewHandlerImpl("dev", &service.Service{Webhook: mockWebhookService})
+	userID := uuid.New()
+
+	newContext := func(body string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/webhooks", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		return c, w
+	}
+
+	t.Run("successful creation", func(t *testing.T) {
+		created := &models.Webhook{
+			WebhookID:  uuid.New(),
+			UserID:     userID,
+			URL:        "https://example.com/hooks",
+			EventTypes: []models.WebhookEventType{models.WebhookEventInvoicePaid},
+			Secret:     "whsec_secret",
+		}
+		mockWebhookService.EXPECT().
+			CreateWebhook(gomock.Any(), models.CreateWebhookRequest{
+				UserID:     userID.String(),
+				URL:        "https://example.com/hooks",
+				EventTypes: []string{"invoice.paid"},
+			}).
+			Return(created, nil)
+
+		c, w := newContext(`{"user_id": "` + userID.String() + `", "url": "https://example.com/hooks", "event_types": ["invoice.paid"]}`)
+		handler.CreateWebhook(c)
+
+		require.Equal(t, http.StatusCreated, w.Code)
+		var response models.Webhook
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Equal(t, created.WebhookID, response.WebhookID)
+		require.Equal(t, "whsec_secret", response.Secret)
+	})
+
+	t.Run("invalid user ID", func(t *testing.T) {
+		c, w := newContext(`{"user_id": "invalid", "url": "https://example.com/hooks", "event_types": ["invoice.paid"]}`)
+		handler.CreateWebhook(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "Invalid user ID")
+	})
+
+	t.Run("invalid URL", func(t *testing.T) {
+		c, w := newContext(`{"user_id": "` + userID.String() + `", "url": "ftp://example.com", "event_types": ["invoice.paid"]}`)
+		handler.CreateWebhook(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("invalid event type", func(t *testing.T) {
+		c, w := newContext(`{"user_id": "` + userID.String() + `", "url": "https://example.com/hooks", "event_types": ["invoice.deleted"]}`)
+		handler.CreateWebhook(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		mockWebhookService.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).Return(nil, errors.New("database error"))
+
+		c, w := newContext(`{"user_id": "` + userID.String() + `", "url": "https://example.com/hooks", "event_types": ["invoice.paid"]}`)
+		handler.CreateWebhook(c)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestGetWebhooks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWebhookService := mocked.NewMockWebhookService(ctrl)
+	handler := NewHandlerImpl("dev", &service.Service{Webhook: mockWebhookService})
+	userID := uuid.New()
+
+	t.Run("successful retrieval", func(t *testing.T) {
+		webhooks := []models.Webhook{{WebhookID: uuid.New(), UserID: userID, URL: "https://example.com/hooks"}}
+		mockWebhookService.EXPECT().GetWebhooks(gomock.Any(), userID).Return(webhooks, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/webhooks?user_id="+userID.String(), nil)
+		handler.GetWebhooks(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response []models.Webhook
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response, 1)
+		require.Empty(t, response[0].Secret)
+	})
+
+	t.Run("invalid user ID", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/webhooks?user_id=invalid", nil)
+		handler.GetWebhooks(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWebhookService := mocked.NewMockWebhookService(ctrl)
+	handler := NewHandlerImpl("dev", &service.Service{Webhook: mockWebhookService})
+	userID, webhookID := uuid.New(), uuid.New()
+	url := "/webhooks/" + webhookID.String() + "?user_id=" + userID.String()
+
+	t.Run("successful deletion", func(t *testing.T) {
+		mockWebhookService.EXPECT().DeleteWebhook(gomock.Any(), userID, webhookID).Return(nil)
+
+		c, w := newWebhookContext(http.MethodDelete, url, "webhookID", webhookID.String())
+		handler.DeleteWebhook(c)
+		c.Writer.WriteHeaderNow()
+
+		require.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("webhook not found", func(t *testing.T) {
+		mockWebhookService.EXPECT().DeleteWebhook(gomock.Any(), userID, webhookID).Return(models.ErrWebhookNotFound)
+
+		c, w := newWebhookContext(http.MethodDelete, url, "webhookID", webhookID.String())
+		handler.DeleteWebhook(c)
+
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid webhook ID", func(t *testing.T) {
+		c, w := newWebhookContext(http.MethodDelete, "/webhooks/invalid?user_id="+userID.String(), "webhookID", "invalid")
+		handler.DeleteWebhook(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "Invalid webhook ID")
+	})
+}
+
+func TestEnableWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWebhookService := mocked.NewMockWebhookService(ctrl)
+	handler := NewHandlerImpl("dev", &service.Service{Webhook: mockWebhookService})
+	userID, webhookID := uuid.New(), uuid.New()
+	url := "/webhooks/" + webhookID.String() + "/enable?user_id=" + userID.String()
+
+	t.Run("successful enabling", func(t *testing.T) {
+		mockWebhookService.EXPECT().
+			EnableWebhook(gomock.Any(), userID, webhookID).
+			Return(&models.Webhook{WebhookID: webhookID, UserID: userID}, nil)
+
+		c, w := newWebhookContext(http.MethodPost, url, "webhookID", webhookID.String())
+		handler.EnableWebhook(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response models.Webhook
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Nil(t, response.DisabledAt)
+	})
+
+	t.Run("webhook not found", func(t *testing.T) {
+		mockWebhookService.EXPECT().EnableWebhook(gomock.Any(), userID, webhookID).Return(nil, models.ErrWebhookNotFound)
+
+		c, w := newWebhookContext(http.MethodPost, url, "webhookID", webhookID.String())
+		handler.EnableWebhook(c)
+
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestGetWebhookDeliveries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWebhookService := mocked.NewMockWebhookService(ctrl)
+	handler := NewHandlerImpl("dev", &service.Service{Webhook: mockWebhookService})
+	userID, webhookID := uuid.New(), uuid.New()
+	url := "/webhooks/" + webhookID.String() + "/deliveries?limit=5&user_id=" + userID.String()
+
+	t.Run("successful retrieval", func(t *testing.T) {
+		deliveries := []models.WebhookDelivery{{
+			DeliveryID: uuid.New(),
+			WebhookID:  webhookID,
+			EventType:  models.WebhookEventInvoicePaid,
+			Payload:    json.RawMessage(`{"type":"invoice.paid"}`),
+			Status:     models.WebhookDeliveryStatusSucceeded,
+			Attempts:   1,
+		}}
+		mockWebhookService.EXPECT().
+			GetWebhookDeliveries(gomock.Any(), userID, webhookID, pagination.Request{Limit: 5, Page: 1}).
+			Return(deliveries, &pagination.Meta{Total: 1, Limit: 5}, nil)
+
+		c, w := newWebhookContext(http.MethodGet, url, "webhookID", webhookID.String())
+		handler.GetWebhookDeliveries(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response pagination.Page[models.WebhookDelivery]
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Items, 1)
+		require.Equal(t, deliveries[0].DeliveryID, response.Items[0].DeliveryID)
+		require.JSONEq(t, `{"type":"invoice.paid"}`, string(response.Items[0].Payload))
+	})
+
+	t.Run("webhook not found", func(t *testing.T) {
+		mockWebhookService.EXPECT().
+			GetWebhookDeliveries(gomock.Any(), userID, webhookID, gomock.Any()).
+			Return(nil, nil, models.ErrWebhookNotFound)
+
+		c, w := newWebhookContext(http.MethodGet, url, "webhookID", webhookID.String())
+		handler.GetWebhookDeliveries(c)
+
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		c, w := newWebhookContext(http.MethodGet, "/webhooks/"+webhookID.String()+"/deliveries?limit=abc&user_id="+userID.String(),
+			"webhookID", webhookID.String())
+		handler.GetWebhookDeliveries(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestReplayWebhookDelivery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWebhookService := mocked.NewMockWebhookService(ctrl)
+	handler := NewHandlerImpl("dev", &service.Service{Webhook: mockWebhookService})
+	userID, deliveryID := uuid.New(), uuid.New()
+	url := "/webhooks/deliveries/" + deliveryID.String() + "/replay?user_id=" + userID.String()
+
+	t.Run("successful replay", func(t *testing.T) {
+		replay := &models.WebhookDelivery{
+			DeliveryID: uuid.New(),
+			Payload:    json.RawMessage(`{}`),
+			Status:     models.WebhookDeliveryStatusPending,
+			ReplayOf:   &deliveryID,
+		}
+		mockWebhookService.EXPECT().ReplayWebhookDelivery(gomock.Any(), userID, deliveryID).Return(replay, nil)
+
+		c, w := newWebhookContext(http.MethodPost, url, "deliveryID", deliveryID.String())
+		handler.ReplayWebhookDelivery(c)
+
+		require.Equal(t, http.StatusAccepted, w.Code)
+		var response models.WebhookDelivery
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Equal(t, deliveryID, *response.ReplayOf)
+	})
+
+	t.Run("delivery not found", func(t *testing.T) {
+		mockWebhookService.EXPECT().
+			ReplayWebhookDelivery(gomock.Any(), userID, deliveryID).
+			Return(nil, models.ErrWebhookDeliveryNotFound)
+
+		c, w := newWebhookContext(http.MethodPost, url, "deliveryID", deliveryID.String())
+		handler.ReplayWebhookDelivery(c)
+
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid delivery ID", func(t *testing.T) {
+		c, w := newWebhookContext(http.MethodPost, "/webhooks/deliveries/invalid/replay?user_id="+userID.String(), "deliveryID", "invalid")
+		handler.ReplayWebhookDelivery(c)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "Invalid delivery ID")
+	})
+}
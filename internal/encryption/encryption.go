@@ -0,0 +1,151 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// keySize is the size, in bytes, of the master keys and of the data keys they encrypt (AES-256).
+const keySize = 32
+
+// Envelope is a value encrypted with its own data key, stored alongside it encrypted with a master key. Rotating
+// the master key only requires the data key to be decrypted and encrypted again.
+type Envelope struct {
+	// KeyID identifies the master key the data key is encrypted with.
+	KeyID string
+	// DataKey is the data key, encrypted with the master key and prefixed with its nonce.
+	DataKey []byte
+	// Ciphertext is the value, encrypted with the data key and prefixed with its nonce.
+	Ciphertext []byte
+}
+
+// Keyring holds the master keys values can be decrypted with, by key ID, and the ID of the active key new values
+// are encrypted with. Keys that are no longer active stay in the keyring until no value is encrypted with them.
+type Keyring struct {
+	activeKeyID string
+	keys        map[string]cipher.AEAD
+}
+
+// NewKeyring creates a keyring of the given 32 byte master keys, by key ID, which encrypts new values with the key
+// whose ID is activeKeyID.
+func NewKeyring(activeKeyID string, keys map[string][]byte) (*Keyring, error) {
+	keyring := &Keyring{
+		activeKeyID: activeKeyID,
+		keys:        make(map[string]cipher.AEAD, len(keys)),
+	}
+	for id, key := range keys {
+		if len(key) != keySize {
+			return nil, fmt.Errorf("encryption key %s must be %d bytes", id, keySize)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		keyring.keys[id] = aead
+	}
+	if _, ok := keyring.keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("active encryption key %q is not configured", activeKeyID)
+	}
+	return keyring, nil
+}
+
+// ParseKeyring creates a keyring from a comma separated list of master keys, each given as its ID and its base64
+// encoded value separated by a colon, such as "2024-01:c2VjcmV0...,2024-07:a2V5...".
+func ParseKeyring(activeKeyID, keys string) (*Keyring, error) {
+	parsed := make(map[string][]byte)
+	for _, entry := range strings.Split(keys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, value, ok := strings.Cut(entry, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("encryption keys must be given as id:base64 pairs")
+		}
+		key, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %s is not valid base64", id)
+		}
+		parsed[id] = key
+	}
+	return NewKeyring(activeKeyID, parsed)
+}
+
+// ActiveKeyID returns the ID of the master key new values are encrypted with.
+func (k *Keyring) ActiveKeyID() string {
+	return k.activeKeyID
+}
+
+// Encrypt encrypts the plaintext with a new data key, which is encrypted with the active master key.
+func (k *Keyring) Encrypt(plaintext []byte) (Envelope, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return Envelope{}, err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return Envelope{}, err
+	}
+	ciphertext, err := seal(aead, plaintext, nil)
+	if err != nil {
+		return Envelope{}, err
+	}
+	// the key ID is authenticated with the data key, so an envelope cannot be pointed at another master key
+	encryptedKey, err := seal(k.keys[k.activeKeyID], dataKey, []byte(k.activeKeyID))
+	if err != nil {
+		return Envelope{}, err
+	}
+	return Envelope{KeyID: k.activeKeyID, DataKey: encryptedKey, Ciphertext: ciphertext}, nil
+}
+
+// Decrypt decrypts the data key of the envelope with the master key it names, and the value with the data key.
+func (k *Keyring) Decrypt(envelope Envelope) ([]byte, error) {
+	masterKey, ok := k.keys[envelope.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("encryption key %q is not configured", envelope.KeyID)
+	}
+	dataKey, err := open(masterKey, envelope.DataKey, []byte(envelope.KeyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data key: %w", err)
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := open(aead, envelope.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt value: %w", err)
+	}
+	return plaintext, nil
+}
+
+// newAEAD returns AES-GCM with the given key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts the plaintext with a random nonce, which is prepended to the ciphertext.
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts a ciphertext produced by seal.
+func open(aead cipher.AEAD, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, fmt.Errorf("ciphertext is too short")
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, sealed, additionalData)
+}
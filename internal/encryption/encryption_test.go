@@ -0,0 +1,77 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring(t *testing.T) {
+	oldKey, newKey := bytes.Repeat([]byte{1}, keySize), bytes.Repeat([]byte{2}, keySize)
+
+	old, err := NewKeyring("old", map[string][]byte{"old": oldKey})
+	require.NoError(t, err)
+	envelope, err := old.Encrypt([]byte("0123456789"))
+	require.NoError(t, err)
+	require.Equal(t, "old", envelope.KeyID)
+	require.NotContains(t, string(envelope.Ciphertext), "0123456789")
+
+	t.Run("decrypt with a rotated keyring", func(t *testing.T) {
+		rotated, err := NewKeyring("new", map[string][]byte{"old": oldKey, "new": newKey})
+		require.NoError(t, err)
+
+		plaintext, err := rotated.Decrypt(envelope)
+		require.NoError(t, err)
+		require.Equal(t, "0123456789", string(plaintext))
+
+		reencrypted, err := rotated.Encrypt(plaintext)
+		require.NoError(t, err)
+		require.Equal(t, "new", reencrypted.KeyID)
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		other, err := NewKeyring("new", map[string][]byte{"new": newKey})
+		require.NoError(t, err)
+
+		_, err = other.Decrypt(envelope)
+		require.ErrorContains(t, err, `encryption key "old" is not configured`)
+	})
+
+	t.Run("tampered envelope", func(t *testing.T) {
+		tampered := envelope
+		tampered.Ciphertext = append([]byte(nil), envelope.Ciphertext...)
+		tampered.Ciphertext[len(tampered.Ciphertext)-1] ^= 1
+
+		_, err := old.Decrypt(tampered)
+		require.ErrorContains(t, err, "failed to decrypt value")
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		_, err := NewKeyring("old", map[string][]byte{"old": []byte("short")})
+		require.ErrorContains(t, err, "must be 32 bytes")
+
+		_, err = NewKeyring("new", map[string][]byte{"old": oldKey})
+		require.ErrorContains(t, err, "is not configured")
+	})
+}
+
+func TestParseKeyring(t *testing.T) {
+	oldKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, keySize))
+	newKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, keySize))
+
+	keyring, err := ParseKeyring("2024-07", "2024-01:"+oldKey+", 2024-07:"+newKey)
+	require.NoError(t, err)
+	require.Equal(t, "2024-07", keyring.ActiveKeyID())
+	require.Len(t, keyring.keys, 2)
+
+	_, err = ParseKeyring("2024-01", "2024-01")
+	require.ErrorContains(t, err, "id:base64")
+
+	_, err = ParseKeyring("2024-01", "2024-01:not base64")
+	require.ErrorContains(t, err, "not valid base64")
+
+	_, err = ParseKeyring("2024-01", "")
+	require.ErrorContains(t, err, "is not configured")
+}
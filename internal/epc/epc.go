@@ -0,0 +1,114 @@
+package epc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/validation"
+)
+
+const (
+	// maxName and maxRemittance are the longest beneficiary name and unstructured remittance text of a transfer.
+	maxName       = 70
+	maxRemittance = 140
+	// minAmount and maxAmount are the smallest and largest amounts of a transfer, in euros.
+	minAmount = 0.01
+	maxAmount = 999999999.99
+	// qrCodeSize is the width and height of QR code images, in pixels.
+	qrCodeSize = 256
+)
+
+// ErrNotPayableByQRCode is returned when an invoice cannot be paid with an EPC QR code, because it is not in euros or
+// is not paid into an IBAN account.
+var ErrNotPayableByQRCode = errors.New("invoice cannot be paid with an EPC QR code")
+
+// Transfer is a SEPA credit transfer a banking app fills in from an EPC QR code.
+type Transfer struct {
+	// BIC is optional for transfers within the EEA.
+	BIC        string
+	Name       string
+	IBAN       string
+	Amount     float64
+	Remittance string
+}
+
+// FromInvoice returns the transfer paying the final amount of an invoice into its payment method, with the invoice
+// number as the remittance text. It returns ErrNotPayableByQRCode for invoices that are not in euros or not paid into
+// an IBAN account.
+func FromInvoice(details *models.InvoiceDetails) (Transfer, error) {
+	if details.Invoice.Currency != "EUR" {
+		return Transfer{}, fmt.Errorf("%w: the invoice is in %s, and EPC QR codes are for EUR invoices only",
+			ErrNotPayableByQRCode, details.Invoice.Currency)
+	}
+	method := details.PaymentInformation
+	if method.AccountType != models.AccountTypeIBAN {
+		return Transfer{}, fmt.Errorf("%w: the invoice is not paid into an IBAN account", ErrNotPayableByQRCode)
+	}
+
+	return Transfer{
+		BIC:        method.SwiftCode,
+		Name:       method.AccountName,
+		IBAN:       method.AccountNumber,
+		Amount:     details.Invoice.FinalAmount,
+		Remittance: details.Invoice.InvoiceNumber,
+	}, nil
+}
+
+// Payload returns the EPC069-12 version 002 payload of a transfer, in UTF-8. Names and remittance texts longer than
+// the standard allows are cut short.
+func Payload(transfer Transfer) (string, error) {
+	iban := strings.ToUpper(strings.ReplaceAll(transfer.IBAN, " ", ""))
+	if err := validation.IBAN(iban); err != nil {
+		return "", fmt.Errorf("%w: invalid IBAN: %v", ErrNotPayableByQRCode, err)
+	}
+	bic := strings.ToUpper(strings.TrimSpace(transfer.BIC))
+	if bic != "" {
+		if err := validation.BIC(bic); err != nil {
+			return "", fmt.Errorf("%w: invalid BIC: %v", ErrNotPayableByQRCode, err)
+		}
+	}
+	name := field(transfer.Name, maxName)
+	if name == "" {
+		return "", fmt.Errorf("%w: the account name is required", ErrNotPayableByQRCode)
+	}
+	if transfer.Amount < minAmount || transfer.Amount > maxAmount {
+		return "", fmt.Errorf("%w: the amount must be between %.2f and %.2f EUR", ErrNotPayableByQRCode,
+			minAmount, maxAmount)
+	}
+
+	lines := []string{
+		"BCD", // service tag
+		"002", // version
+		"1",   // UTF-8
+		"SCT", // SEPA credit transfer
+		bic,
+		name,
+		iban,
+		fmt.Sprintf("EUR%.2f", transfer.Amount),
+		"", // purpose
+		"", // structured creditor reference, which is left out for the remittance text
+		field(transfer.Remittance, maxRemittance),
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// QRCode returns the EPC QR code of a transfer as a PNG image, with the medium error correction the standard asks for.
+func QRCode(transfer Transfer) ([]byte, error) {
+	payload, err := Payload(transfer)
+	if err != nil {
+		return nil, err
+	}
+	return qrcode.Encode(payload, qrcode.Medium, qrCodeSize)
+}
+
+// field returns the value on a single line without surrounding spaces, cut short to at most limit characters.
+func field(value string, limit int) string {
+	value = strings.Join(strings.Fields(value), " ")
+	if runes := []rune(value); len(runes) > limit {
+		value = strings.TrimSpace(string(runes[:limit]))
+	}
+	return value
+}
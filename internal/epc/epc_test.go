@@ -0,0 +1,98 @@
+package epc
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+func TestFromInvoice(t *testing.T) {
+	details := &models.InvoiceDetails{
+		Invoice: models.Invoice{InvoiceNumber: "INV-001", Currency: "EUR", FinalAmount: 1250.5},
+		PaymentInformation: models.UserPaymentMethod{
+			AccountName: "Sender GmbH", AccountType: models.AccountTypeIBAN,
+			AccountNumber: "DE89370400440532013000", SwiftCode: "COBADEFFXXX",
+		},
+	}
+
+	t.Run("eur invoice", func(t *testing.T) {
+		transfer, err := FromInvoice(details)
+		require.NoError(t, err)
+		require.Equal(t, Transfer{
+			BIC: "COBADEFFXXX", Name: "Sender GmbH", IBAN: "DE89370400440532013000", Amount: 1250.5, Remittance: "INV-001",
+		}, transfer)
+	})
+
+	t.Run("other currency", func(t *testing.T) {
+		usd := *details
+		usd.Invoice.Currency = "USD"
+		_, err := FromInvoice(&usd)
+		require.ErrorIs(t, err, ErrNotPayableByQRCode)
+		require.ErrorContains(t, err, "the invoice is in USD")
+	})
+
+	t.Run("not an iban account", func(t *testing.T) {
+		other := *details
+		other.PaymentInformation.AccountType = models.AccountTypeOther
+		_, err := FromInvoice(&other)
+		require.ErrorIs(t, err, ErrNotPayableByQRCode)
+	})
+}
+
+func TestPayload(t *testing.T) {
+	transfer := Transfer{
+		BIC: "cobadeffxxx", Name: "Sender\nGmbH", IBAN: "DE89 3704 0044 0532 0130 00", Amount: 1250.5, Remittance: "INV-001",
+	}
+
+	t.Run("valid transfer", func(t *testing.T) {
+		payload, err := Payload(transfer)
+		require.NoError(t, err)
+		require.Equal(t, "BCD\n002\n1\nSCT\nCOBADEFFXXX\nSender GmbH\nDE89370400440532013000\nEUR1250.50\n\n\nINV-001", payload)
+	})
+
+	t.Run("without bic", func(t *testing.T) {
+		withoutBIC := transfer
+		withoutBIC.BIC = ""
+		payload, err := Payload(withoutBIC)
+		require.NoError(t, err)
+		require.Equal(t, "", strings.Split(payload, "\n")[4])
+	})
+
+	t.Run("long name", func(t *testing.T) {
+		long := transfer
+		long.Name = strings.Repeat("a", 80)
+		payload, err := Payload(long)
+		require.NoError(t, err)
+		require.Equal(t, strings.Repeat("a", 70), strings.Split(payload, "\n")[5])
+	})
+
+	for _, tc := range []struct {
+		name   string
+		change func(*Transfer)
+	}{
+		{"invalid iban", func(transfer *Transfer) { transfer.IBAN = "DE00370400440532013000" }},
+		{"invalid bic", func(transfer *Transfer) { transfer.BIC = "COBA" }},
+		{"missing name", func(transfer *Transfer) { transfer.Name = " " }},
+		{"zero amount", func(transfer *Transfer) { transfer.Amount = 0 }},
+		{"amount too large", func(transfer *Transfer) { transfer.Amount = 1e9 }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			invalid := transfer
+			tc.change(&invalid)
+			_, err := Payload(invalid)
+			require.ErrorIs(t, err, ErrNotPayableByQRCode)
+		})
+	}
+}
+
+func TestQRCode(t *testing.T) {
+	image, err := QRCode(Transfer{Name: "Sender GmbH", IBAN: "DE89370400440532013000", Amount: 10, Remittance: "INV-001"})
+	require.NoError(t, err)
+	decoded, err := png.Decode(bytes.NewReader(image))
+	require.NoError(t, err)
+	require.Equal(t, qrCodeSize, decoded.Bounds().Dx())
+}
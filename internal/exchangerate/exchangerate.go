@@ -0,0 +1,103 @@
+package exchangerate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zde37/Numeris-Task/internal/helpers"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+// fileSourceName is the source recorded on the rates loaded from a file.
+const fileSourceName = "file"
+
+// Source provides the latest exchange rates, typically from a rates provider's API.
+type Source interface {
+	Fetch(ctx context.Context) ([]models.ExchangeRate, error)
+}
+
+// snapshot is a set of rates from one base currency on a single day, in the shape most rates APIs respond with.
+type snapshot struct {
+	Date  string             `json:"date"`
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+type fileSource struct {
+	path string
+}
+
+// NewFileSource returns a Source that reads exchange rates from a JSON file, standing in for a rates API.
+// The file holds either a single snapshot or a list of them, each shaped like
+// {"date": "2024-06-01", "base": "USD", "rates": {"NGN": 1480.5, "EUR": 0.92}}.
+// The file is read on every fetch, so it can be updated without restarting the application.
+func NewFileSource(path string) Source {
+	return &fileSource{
+		path: path,
+	}
+}
+
+// Fetch reads and validates the rates in the file.
+func (f *fileSource) Fetch(ctx context.Context) ([]models.ExchangeRate, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exchange rates: %w", err)
+	}
+	return parse(data, fileSourceName)
+}
+
+// parse decodes one or more snapshots into exchange rates, rejecting unknown currencies and non positive rates.
+func parse(data []byte, source string) ([]models.ExchangeRate, error) {
+	var snapshots []snapshot
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("{")) {
+		snapshots = make([]snapshot, 1)
+		if err := json.Unmarshal(data, &snapshots[0]); err != nil {
+			return nil, fmt.Errorf("failed to decode exchange rates: %w", err)
+		}
+	} else if err := json.Unmarshal(data, &snapshots); err != nil {
+		return nil, fmt.Errorf("failed to decode exchange rates: %w", err)
+	}
+
+	rates := []models.ExchangeRate{}
+	for _, snap := range snapshots {
+		date, err := time.Parse("2006-01-02", snap.Date)
+		if err != nil {
+			return nil, fmt.Errorf("exchange rates have invalid date format: %s", snap.Date)
+		}
+		base := strings.ToUpper(snap.Base)
+		if err := helpers.ValidateCurrency(base); err != nil {
+			return nil, err
+		}
+
+		for currency, rate := range snap.Rates {
+			currency = strings.ToUpper(currency)
+			if currency == base {
+				continue
+			}
+			if err := helpers.ValidateCurrency(currency); err != nil {
+				return nil, err
+			}
+			if rate <= 0 {
+				return nil, fmt.Errorf("exchange rate from %s to %s must be greater than zero", base, currency)
+			}
+			rates = append(rates, models.ExchangeRate{
+				FromCurrency:  base,
+				ToCurrency:    currency,
+				Rate:          rate,
+				EffectiveDate: date,
+				Source:        source,
+			})
+		}
+	}
+	return rates, nil
+}
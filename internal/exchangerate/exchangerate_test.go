@@ -0,0 +1,76 @@
+package exchangerate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+func writeRates(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestFileSource(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("single snapshot", func(t *testing.T) {
+		path := writeRates(t, `{"date": "2024-06-01", "base": "usd", "rates": {"NGN": 1480.5, "EUR": 0.92, "USD": 1}}`)
+
+		rates, err := NewFileSource(path).Fetch(ctx)
+		require.NoError(t, err)
+		sort.Slice(rates, func(i, j int) bool { return rates[i].ToCurrency < rates[j].ToCurrency })
+
+		date := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+		require.Equal(t, []models.ExchangeRate{
+			{FromCurrency: "USD", ToCurrency: "EUR", Rate: 0.92, EffectiveDate: date, Source: "file"},
+			{FromCurrency: "USD", ToCurrency: "NGN", Rate: 1480.5, EffectiveDate: date, Source: "file"},
+		}, rates)
+	})
+
+	t.Run("list of snapshots", func(t *testing.T) {
+		path := writeRates(t, `[
+			{"date": "2024-06-01", "base": "USD", "rates": {"NGN": 1480.5}},
+			{"date": "2024-06-02", "base": "EUR", "rates": {"NGN": 1610}}
+		]`)
+
+		rates, err := NewFileSource(path).Fetch(ctx)
+		require.NoError(t, err)
+		require.Len(t, rates, 2)
+		require.Equal(t, "EUR", rates[1].FromCurrency)
+		require.Equal(t, float64(1610), rates[1].Rate)
+	})
+
+	t.Run("unknown currency", func(t *testing.T) {
+		path := writeRates(t, `{"date": "2024-06-01", "base": "USD", "rates": {"XYZ": 2}}`)
+
+		_, err := NewFileSource(path).Fetch(ctx)
+		require.ErrorContains(t, err, "invalid currency: XYZ")
+	})
+
+	t.Run("non positive rate", func(t *testing.T) {
+		path := writeRates(t, `{"date": "2024-06-01", "base": "USD", "rates": {"NGN": 0}}`)
+
+		_, err := NewFileSource(path).Fetch(ctx)
+		require.ErrorContains(t, err, "greater than zero")
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		path := writeRates(t, `{"date": "01/06/2024", "base": "USD", "rates": {"NGN": 1480.5}}`)
+
+		_, err := NewFileSource(path).Fetch(ctx)
+		require.ErrorContains(t, err, "invalid date format")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := NewFileSource(filepath.Join(t.TempDir(), "missing.json")).Fetch(ctx)
+		require.ErrorContains(t, err, "failed to read exchange rates")
+	})
+}
@@ -0,0 +1,143 @@
+package gateway
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// fakeProviderName is the name the fake provider is configured with and records sessions under.
+	fakeProviderName = "fake"
+	// FakeSignatureHeader is the header the fake provider signs callbacks in.
+	FakeSignatureHeader = "X-Fake-Signature"
+	// fakeSessionTTL is how long the checkout sessions of the fake provider can be paid for.
+	fakeSessionTTL = 24 * time.Hour
+)
+
+// fakeCallback is the payload of the callbacks of the fake provider.
+type fakeCallback struct {
+	SessionID string         `json:"session_id"`
+	Status    CallbackStatus `json:"status"`
+	Amount    float64        `json:"amount"`
+	Currency  string         `json:"currency"`
+	Reference string         `json:"reference"`
+	PaidAt    time.Time      `json:"paid_at"`
+}
+
+type fakeProvider struct {
+	checkoutBaseURL string
+	secret          []byte
+}
+
+// NewFakeProvider returns a PaymentProvider for local testing, which moves no money. Its checkout URLs point below
+// the checkout base URL, and nothing is served there: a payment is simulated by sending the callback endpoint a
+// payload like {"session_id": "...", "status": "paid", "amount": 100, "currency": "NGN", "reference": "...",
+// "paid_at": "2024-06-01T10:00:00Z"}, with the hex encoded HMAC-SHA256 of the payload under the secret in the
+// X-Fake-Signature header.
+func NewFakeProvider(checkoutBaseURL string, secret []byte) PaymentProvider {
+	return &fakeProvider{
+		checkoutBaseURL: strings.TrimSuffix(checkoutBaseURL, "/"),
+		secret:          secret,
+	}
+}
+
+// Name returns the name of the fake provider.
+func (f *fakeProvider) Name() string {
+	return fakeProviderName
+}
+
+// CreateCheckoutSession creates a checkout session without contacting any gateway.
+func (f *fakeProvider) CreateCheckoutSession(ctx context.Context, req CheckoutRequest) (*CheckoutSession, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("checkout amount must be greater than 0")
+	}
+
+	sessionID := "fake_cs_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	return &CheckoutSession{
+		SessionID: sessionID,
+		URL:       f.checkoutBaseURL + "/checkout/" + sessionID,
+		ExpiresAt: time.Now().Add(fakeSessionTTL),
+	}, nil
+}
+
+// HandleCallback verifies the signature of a fake callback and decodes it.
+func (f *fakeProvider) HandleCallback(ctx context.Context, payload []byte, header http.Header) (*CallbackEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	signature, err := hex.DecodeString(header.Get(FakeSignatureHeader))
+	if err != nil || !hmac.Equal(signature, sign(f.secret, payload)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var callback fakeCallback
+	if err := json.Unmarshal(payload, &callback); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCallback, err)
+	}
+	if callback.SessionID == "" {
+		return nil, fmt.Errorf("%w: missing session id", ErrInvalidCallback)
+	}
+	if callback.Status != CallbackStatusPaid && callback.Status != CallbackStatusFailed {
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidCallback, callback.Status)
+	}
+	if callback.Status == CallbackStatusPaid && callback.Amount <= 0 {
+		return nil, fmt.Errorf("%w: amount must be greater than 0", ErrInvalidCallback)
+	}
+
+	return &CallbackEvent{
+		SessionID: callback.SessionID,
+		Status:    callback.Status,
+		Amount:    callback.Amount,
+		Currency:  strings.ToUpper(callback.Currency),
+		Reference: callback.Reference,
+		PaidAt:    callback.PaidAt,
+	}, nil
+}
+
+// Refund accepts every valid refund without contacting any gateway. The refund ID is derived from the idempotency
+// key, so a refund sent again returns the same refund.
+func (f *fakeProvider) Refund(ctx context.Context, req RefundRequest) (*Refund, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if req.IdempotencyKey == "" {
+		return nil, fmt.Errorf("%w: idempotency key is required", ErrRefundRejected)
+	}
+	if req.Reference == "" {
+		return nil, fmt.Errorf("%w: refund reference is required", ErrRefundRejected)
+	}
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("%w: refund amount must be greater than 0", ErrRefundRejected)
+	}
+
+	return &Refund{
+		RefundID: "fake_re_" + hex.EncodeToString(sign(f.secret, []byte(req.IdempotencyKey)))[:32],
+		Amount:   req.Amount,
+		Currency: req.Currency,
+	}, nil
+}
+
+// SignFakeCallback returns the signature the fake provider expects in the X-Fake-Signature header of a callback
+// with the payload.
+func SignFakeCallback(secret, payload []byte) string {
+	return hex.EncodeToString(sign(secret, payload))
+}
+
+// sign returns the HMAC-SHA256 of the payload under the secret.
+func sign(secret, payload []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
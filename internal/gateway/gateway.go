@@ -0,0 +1,106 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zde37/Numeris-Task/internal/config"
+)
+
+// ErrInvalidSignature is returned when the signature of a callback does not match its payload, so it cannot be
+// trusted to come from the provider.
+var ErrInvalidSignature = errors.New("invalid callback signature")
+
+// ErrInvalidCallback is returned when a callback is signed but cannot be understood, so retrying it cannot succeed.
+var ErrInvalidCallback = errors.New("invalid callback")
+
+// ErrRefundRejected is returned when the provider rejects a refund, so it returned no money and sending it again
+// cannot succeed. Other errors of a refund leave it unknown whether the provider made it.
+var ErrRefundRejected = errors.New("refund rejected")
+
+// PaymentProvider is a payment gateway hosting the checkout pages customers pay invoices online on.
+type PaymentProvider interface {
+	// Name identifies the provider the payment sessions were created with.
+	Name() string
+	// CreateCheckoutSession creates a hosted checkout session for the customer to pay the amount on.
+	CreateCheckoutSession(ctx context.Context, req CheckoutRequest) (*CheckoutSession, error)
+	// HandleCallback verifies the signature of a callback the provider sent about a checkout session, and returns
+	// the event it describes. It returns ErrInvalidSignature when the signature does not match, and an error
+	// wrapping ErrInvalidCallback when the payload is not a valid callback.
+	HandleCallback(ctx context.Context, payload []byte, header http.Header) (*CallbackEvent, error)
+	// Refund returns an amount of a payment made through the provider to the customer. A refund sent again with the
+	// same idempotency key is made once, and returns the refund made the first time. It returns an error wrapping
+	// ErrRefundRejected when the provider rejects the refund.
+	Refund(ctx context.Context, req RefundRequest) (*Refund, error)
+}
+
+// CheckoutRequest is the amount of an invoice a customer is asked to pay in a checkout session.
+type CheckoutRequest struct {
+	InvoiceID     uuid.UUID
+	InvoiceNumber string
+	CustomerEmail string
+	Amount        float64
+	Currency      string
+}
+
+// CheckoutSession is a checkout session hosted by the provider, which the customer pays on at URL until it expires.
+type CheckoutSession struct {
+	SessionID string
+	URL       string
+	ExpiresAt time.Time
+}
+
+// CallbackStatus is the outcome of a checkout session reported in a callback.
+type CallbackStatus string
+
+const (
+	CallbackStatusPaid   CallbackStatus = "paid"
+	CallbackStatusFailed CallbackStatus = "failed"
+)
+
+// CallbackEvent is the outcome of a checkout session, as reported by the provider. Reference identifies the payment
+// at the provider, and is what refunds are requested with.
+type CallbackEvent struct {
+	SessionID string
+	Status    CallbackStatus
+	Amount    float64
+	Currency  string
+	Reference string
+	PaidAt    time.Time
+}
+
+// RefundRequest is an amount to return of the payment with the reference. IdempotencyKey identifies the refund, so
+// the provider makes it once however often it is sent.
+type RefundRequest struct {
+	IdempotencyKey string
+	Reference      string
+	Amount         float64
+	Currency       string
+}
+
+// Refund is a refund made by the provider.
+type Refund struct {
+	RefundID string
+	Amount   float64
+	Currency string
+}
+
+// New returns the PaymentProvider named in the configuration. It returns nil when no provider is configured, in
+// which case invoices cannot be paid online.
+func New(cfg config.PaymentConfig) (PaymentProvider, error) {
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case fakeProviderName:
+		if cfg.CallbackSecret == "" {
+			return nil, fmt.Errorf("the fake payment provider requires a callback secret")
+		}
+		return NewFakeProvider(cfg.CheckoutBaseURL, []byte(cfg.CallbackSecret)), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q", cfg.Provider)
+	}
+}
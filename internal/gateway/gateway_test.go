@@ -0,0 +1,142 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Numeris-Task/internal/config"
+)
+
+func signedHeader(secret []byte, payload string) http.Header {
+	header := http.Header{}
+	header.Set(FakeSignatureHeader, SignFakeCallback(secret, []byte(payload)))
+	return header
+}
+
+func TestNew(t *testing.T) {
+	t.Run("no provider", func(t *testing.T) {
+		provider, err := New(config.PaymentConfig{})
+		require.NoError(t, err)
+		require.Nil(t, provider)
+	})
+
+	t.Run("fake provider", func(t *testing.T) {
+		provider, err := New(config.PaymentConfig{Provider: "fake", CallbackSecret: "secret"})
+		require.NoError(t, err)
+		require.Equal(t, "fake", provider.Name())
+	})
+
+	t.Run("fake provider without secret", func(t *testing.T) {
+		_, err := New(config.PaymentConfig{Provider: "fake"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "requires a callback secret")
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		_, err := New(config.PaymentConfig{Provider: "stripe", CallbackSecret: "secret"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `unknown payment provider "stripe"`)
+	})
+}
+
+func TestFakeProvider(t *testing.T) {
+	ctx := context.Background()
+	secret := []byte("secret")
+	provider := NewFakeProvider("http://localhost:8080/", secret)
+
+	t.Run("checkout session", func(t *testing.T) {
+		session, err := provider.CreateCheckoutSession(ctx, CheckoutRequest{
+			InvoiceID: uuid.New(), InvoiceNumber: "INV-001", Amount: 100, Currency: "NGN",
+		})
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(session.SessionID, "fake_cs_"))
+		require.Equal(t, "http://localhost:8080/checkout/"+session.SessionID, session.URL)
+		require.WithinDuration(t, time.Now().Add(24*time.Hour), session.ExpiresAt, time.Minute)
+	})
+
+	t.Run("checkout session without amount", func(t *testing.T) {
+		_, err := provider.CreateCheckoutSession(ctx, CheckoutRequest{InvoiceID: uuid.New(), Currency: "NGN"})
+		require.Error(t, err)
+	})
+
+	t.Run("paid callback", func(t *testing.T) {
+		payload := `{"session_id": "fake_cs_1", "status": "paid", "amount": 100, "currency": "ngn",
+			"reference": "PAY-1", "paid_at": "2024-06-01T10:00:00Z"}`
+
+		event, err := provider.HandleCallback(ctx, []byte(payload), signedHeader(secret, payload))
+		require.NoError(t, err)
+		require.Equal(t, &CallbackEvent{
+			SessionID: "fake_cs_1",
+			Status:    CallbackStatusPaid,
+			Amount:    100,
+			Currency:  "NGN",
+			Reference: "PAY-1",
+			PaidAt:    time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC),
+		}, event)
+	})
+
+	t.Run("failed callback", func(t *testing.T) {
+		payload := `{"session_id": "fake_cs_1", "status": "failed"}`
+
+		event, err := provider.HandleCallback(ctx, []byte(payload), signedHeader(secret, payload))
+		require.NoError(t, err)
+		require.Equal(t, CallbackStatusFailed, event.Status)
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		payload := `{"session_id": "fake_cs_1", "status": "paid", "amount": 100, "currency": "NGN"}`
+
+		for _, header := range []http.Header{
+			{},
+			signedHeader([]byte("other secret"), payload),
+			signedHeader(secret, `{"session_id": "fake_cs_1", "status": "paid", "amount": 1, "currency": "NGN"}`),
+			{FakeSignatureHeader: []string{"not hex"}},
+		} {
+			_, err := provider.HandleCallback(ctx, []byte(payload), header)
+			require.ErrorIs(t, err, ErrInvalidSignature)
+		}
+	})
+
+	t.Run("invalid callback", func(t *testing.T) {
+		for _, payload := range []string{
+			`not json`,
+			`{"status": "paid", "amount": 100, "currency": "NGN"}`,
+			`{"session_id": "fake_cs_1", "status": "pending"}`,
+			`{"session_id": "fake_cs_1", "status": "paid", "currency": "NGN"}`,
+		} {
+			_, err := provider.HandleCallback(ctx, []byte(payload), signedHeader(secret, payload))
+			require.ErrorIs(t, err, ErrInvalidCallback, payload)
+		}
+	})
+
+	t.Run("refund", func(t *testing.T) {
+		req := RefundRequest{IdempotencyKey: "refund-1", Reference: "PAY-1", Amount: 40, Currency: "NGN"}
+		refund, err := provider.Refund(ctx, req)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(refund.RefundID, "fake_re_"))
+		require.Equal(t, 40.0, refund.Amount)
+
+		// the same refund sent again returns the same refund, and another refund a new one
+		again, err := provider.Refund(ctx, req)
+		require.NoError(t, err)
+		require.Equal(t, refund.RefundID, again.RefundID)
+		req.IdempotencyKey = "refund-2"
+		other, err := provider.Refund(ctx, req)
+		require.NoError(t, err)
+		require.NotEqual(t, refund.RefundID, other.RefundID)
+
+		for _, req := range []RefundRequest{
+			{Reference: "PAY-1", Amount: 40, Currency: "NGN"},
+			{IdempotencyKey: "refund-3", Amount: 40, Currency: "NGN"},
+			{IdempotencyKey: "refund-3", Reference: "PAY-1", Currency: "NGN"},
+		} {
+			_, err = provider.Refund(ctx, req)
+			require.ErrorIs(t, err, ErrRefundRejected)
+		}
+	})
+}
@@ -2,10 +2,15 @@ package helpers
 
 import (
 	"fmt"
+	"math"
+	"net/netip"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/webhook"
 	"golang.org/x/crypto/bcrypt"
 	"golang.org/x/exp/rand"
 )
@@ -40,3 +45,235 @@ func ValidateInvoiceStatus(status string) error {
 	}
 	return nil
 }
+
+// ValidateActivityEventType checks if the provided activity event type is one of the valid event types (comment,
+// invoice_created, status_changed, payment_recorded, payment_refunded, email_sent or late_fee_applied)
+func ValidateActivityEventType(eventType string) error {
+	switch models.ActivityEventType(eventType) {
+	case models.ActivityEventComment, models.ActivityEventInvoiceCreated, models.ActivityEventStatusChanged,
+		models.ActivityEventPaymentRecorded, models.ActivityEventPaymentRefunded, models.ActivityEventEmailSent,
+		models.ActivityEventLateFeeApplied:
+		return nil
+	}
+	return fmt.Errorf("invalid activity event type: %s", eventType)
+}
+
+// ValidateActivitySource checks if the provided activity source is one of the valid sources (user or system)
+func ValidateActivitySource(source string) error {
+	if source != string(models.ActivitySourceUser) && source != string(models.ActivitySourceSystem) {
+		return fmt.Errorf("invalid activity source: %s", source)
+	}
+	return nil
+}
+
+// ValidateReminderTone checks if the provided reminder tone is one of the valid tones (friendly, firm or final)
+func ValidateReminderTone(tone string) error {
+	if tone != string(models.ReminderToneFriendly) && tone != string(models.ReminderToneFirm) &&
+		tone != string(models.ReminderToneFinal) {
+		return fmt.Errorf("invalid reminder tone: %s", tone)
+	}
+	return nil
+}
+
+// ValidateLateFeeType checks if the provided late fee type is one of the valid types (flat, daily_interest or monthly_interest)
+func ValidateLateFeeType(feeType string) error {
+	if feeType != string(models.LateFeeTypeFlat) && feeType != string(models.LateFeeTypeDailyInterest) &&
+		feeType != string(models.LateFeeTypeMonthlyInterest) {
+		return fmt.Errorf("invalid late fee type: %s", feeType)
+	}
+	return nil
+}
+
+// RoundAmount rounds a monetary amount to two decimal places, matching the precision amounts are stored with.
+func RoundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// DaysBetween returns the number of calendar days from one date to another, ignoring the time of day.
+// The result is negative when to is before from.
+func DaysBetween(from, to time.Time) int {
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(end.Sub(start).Hours() / 24)
+}
+
+// ValidateLateFeePolicy checks that a late fee policy is complete for its fee type: flat fees need a positive
+// amount, interest needs a rate between 0 and 100 percent, and the grace period and caps cannot be negative.
+func ValidateLateFeePolicy(data models.SetLateFeePolicyRequest) error {
+	if err := ValidateLateFeeType(data.FeeType); err != nil {
+		return err
+	}
+	if data.FeeType == string(models.LateFeeTypeFlat) && data.FlatAmount <= 0 {
+		return fmt.Errorf("flat_amount must be greater than 0 for flat late fees")
+	}
+	if data.FeeType != string(models.LateFeeTypeFlat) && (data.InterestRate <= 0 || data.InterestRate > 100) {
+		return fmt.Errorf("interest_rate must be greater than 0 and at most 100 for interest late fees")
+	}
+	if data.GraceDays < 0 {
+		return fmt.Errorf("grace_days cannot be negative")
+	}
+	if data.MaxAmount != nil && *data.MaxAmount <= 0 {
+		return fmt.Errorf("max_amount must be greater than 0")
+	}
+	if data.MaxPercentage != nil && (*data.MaxPercentage <= 0 || *data.MaxPercentage > 100) {
+		return fmt.Errorf("max_percentage must be greater than 0 and at most 100")
+	}
+	return nil
+}
+
+// ValidateTaxRate checks that a tax rate has a name and a rate between 0 and 100 percent. Taxes included in the
+// item price cannot be compound, since compound taxes are charged on top of the other taxes.
+func ValidateTaxRate(data models.CreateTaxRateRequest) error {
+	if strings.TrimSpace(data.Name) == "" {
+		return fmt.Errorf("name cannot be empty")
+	}
+	if data.Rate == nil || *data.Rate < 0 || *data.Rate > 100 {
+		return fmt.Errorf("rate must be between 0 and 100")
+	}
+	if data.IsInclusive && data.IsCompound {
+		return fmt.Errorf("a tax rate cannot be both inclusive and compound")
+	}
+	return nil
+}
+
+// ValidatePaymentMethodUpdate checks that a payment method update changes at least one field, that it does not
+// clear the account name, account number or bank name, and that no field is longer than its column.
+func ValidatePaymentMethodUpdate(data models.UpdatePaymentMethodRequest) error {
+	fields := []struct {
+		name      string
+		value     *string
+		maxLength int
+		required  bool
+	}{
+		{"account_name", data.AccountName, 100, true},
+		{"account_number", data.AccountNumber, 50, true},
+		{"bank_name", data.BankName, 100, true},
+		{"bank_address", data.BankAddress, 0, false},
+		{"swift_code", data.SwiftCode, 20, false},
+	}
+
+	changed := false
+	for _, field := range fields {
+		if field.value == nil {
+			continue
+		}
+		changed = true
+		if field.required && strings.TrimSpace(*field.value) == "" {
+			return fmt.Errorf("%s cannot be empty", field.name)
+		}
+		if field.maxLength > 0 && len(*field.value) > field.maxLength {
+			return fmt.Errorf("%s must be at most %d characters", field.name, field.maxLength)
+		}
+	}
+	if !changed {
+		return fmt.Errorf("at least one field must be updated")
+	}
+	return nil
+}
+
+// ValidateTaxReportBasis checks if the provided tax report basis is one of the valid bases (invoice or cash)
+func ValidateTaxReportBasis(basis string) error {
+	if basis != string(models.TaxReportBasisInvoice) && basis != string(models.TaxReportBasisCash) {
+		return fmt.Errorf("invalid tax report basis: %s", basis)
+	}
+	return nil
+}
+
+// ValidateTotalsDateField checks if the provided date field is one of the dates invoice totals can be filtered on (issue_date or due_date)
+func ValidateTotalsDateField(field string) error {
+	if field != string(models.TotalsDateFieldIssue) && field != string(models.TotalsDateFieldDue) {
+		return fmt.Errorf("invalid date field: %s", field)
+	}
+	return nil
+}
+
+// ValidateAgingBucket checks if the provided bucket is one of the valid aging buckets (current, 1-30, 31-60, 61-90 or 90+)
+func ValidateAgingBucket(bucket string) error {
+	switch models.AgingBucket(bucket) {
+	case models.AgingBucketCurrent, models.AgingBucket1To30, models.AgingBucket31To60, models.AgingBucket61To90, models.AgingBucketOver90:
+		return nil
+	}
+	return fmt.Errorf("invalid aging bucket: %s", bucket)
+}
+
+// ValidateSeriesInterval checks if the provided interval is one of the valid time series intervals (day, week or month)
+func ValidateSeriesInterval(interval string) error {
+	if interval != string(models.SeriesIntervalDay) && interval != string(models.SeriesIntervalWeek) && interval != string(models.SeriesIntervalMonth) {
+		return fmt.Errorf("invalid interval: %s", interval)
+	}
+	return nil
+}
+
+// ValidateSeriesGroupBy checks if the provided grouping is one of the valid time series groupings (none, customer or currency)
+func ValidateSeriesGroupBy(groupBy string) error {
+	if groupBy != string(models.SeriesGroupByNone) && groupBy != string(models.SeriesGroupByCustomer) && groupBy != string(models.SeriesGroupByCurrency) {
+		return fmt.Errorf("invalid group by: %s", groupBy)
+	}
+	return nil
+}
+
+// ValidateInvoiceSortField checks if the provided field is one invoices can be sorted by (due_date, amount, number or created_at)
+func ValidateInvoiceSortField(field string) error {
+	switch models.InvoiceSortField(field) {
+	case models.InvoiceSortFieldDueDate, models.InvoiceSortFieldAmount, models.InvoiceSortFieldNumber, models.InvoiceSortFieldCreatedAt:
+		return nil
+	}
+	return fmt.Errorf("invalid sort field: %s", field)
+}
+
+// ValidateSortOrder checks if the provided order is a valid sort order (asc or desc)
+func ValidateSortOrder(order string) error {
+	if order != string(models.SortOrderAsc) && order != string(models.SortOrderDesc) {
+		return fmt.Errorf("invalid sort order: %s", order)
+	}
+	return nil
+}
+
+// ValidateExportFormat checks if the provided format is a valid export format (csv or xlsx)
+func ValidateExportFormat(format string) error {
+	if format != string(models.ExportFormatCSV) && format != string(models.ExportFormatXLSX) {
+		return fmt.Errorf("invalid export format: %s", format)
+	}
+	return nil
+}
+
+// minWebhookSecretLength is the shortest secret a webhook can be created with.
+const minWebhookSecretLength = 16
+
+// ValidateWebhookEventType checks if the provided webhook event type is one of the valid event types (invoice.created,
+// invoice.sent, invoice.paid or invoice.overdue)
+func ValidateWebhookEventType(eventType string) error {
+	switch models.WebhookEventType(eventType) {
+	case models.WebhookEventInvoiceCreated, models.WebhookEventInvoiceSent, models.WebhookEventInvoicePaid,
+		models.WebhookEventInvoiceOverdue:
+		return nil
+	}
+	return fmt.Errorf("invalid webhook event type: %s", eventType)
+}
+
+// ValidateWebhook checks that a webhook has an absolute HTTP or HTTPS URL that does not point to a private address,
+// at least one valid event type and, when a secret is given, a secret long enough to sign deliveries with. The
+// addresses host names resolve to are checked when deliveries are sent.
+func ValidateWebhook(data models.CreateWebhookRequest) error {
+	target, err := url.Parse(data.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	host := strings.ToLower(strings.TrimSuffix(target.Hostname(), "."))
+	addr, err := netip.ParseAddr(host)
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || (err == nil && !webhook.PublicAddress(addr)) {
+		return fmt.Errorf("url must not point to a private address")
+	}
+	if len(data.EventTypes) == 0 {
+		return fmt.Errorf("at least one event type is required")
+	}
+	for _, eventType := range data.EventTypes {
+		if err := ValidateWebhookEventType(eventType); err != nil {
+			return err
+		}
+	}
+	if data.Secret != "" && len(data.Secret) < minWebhookSecretLength {
+		return fmt.Errorf("secret must be at least %d characters long", minWebhookSecretLength)
+	}
+	return nil
+}
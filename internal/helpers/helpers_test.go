@@ -4,6 +4,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/zde37/Numeris-Task/internal/models"
@@ -196,3 +197,219 @@ func TestValidateInvoiceStatus(t *testing.T) {
 		require.Contains(t, err.Error(), "invalid invoice status:  paid ")
 	})
 }
+
+func TestValidateActivityEventType(t *testing.T) {
+	require.NoError(t, ValidateActivityEventType(string(models.ActivityEventComment)))
+	require.NoError(t, ValidateActivityEventType(string(models.ActivityEventStatusChanged)))
+	require.ErrorContains(t, ValidateActivityEventType("Invoice Creation"), "invalid activity event type: Invoice Creation")
+
+	require.NoError(t, ValidateActivitySource(string(models.ActivitySourceSystem)))
+	require.ErrorContains(t, ValidateActivitySource("bot"), "invalid activity source: bot")
+}
+
+func TestValidateReminderTone(t *testing.T) {
+	t.Run("valid tones", func(t *testing.T) {
+		require.NoError(t, ValidateReminderTone(string(models.ReminderToneFriendly)))
+		require.NoError(t, ValidateReminderTone(string(models.ReminderToneFirm)))
+		require.NoError(t, ValidateReminderTone(string(models.ReminderToneFinal)))
+	})
+
+	t.Run("invalid tone", func(t *testing.T) {
+		err := ValidateReminderTone("rude")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid reminder tone: rude")
+	})
+
+	t.Run("empty tone", func(t *testing.T) {
+		err := ValidateReminderTone("")
+		require.Error(t, err)
+	})
+}
+
+func TestValidateLateFeeType(t *testing.T) {
+	t.Run("valid types", func(t *testing.T) {
+		require.NoError(t, ValidateLateFeeType(string(models.LateFeeTypeFlat)))
+		require.NoError(t, ValidateLateFeeType(string(models.LateFeeTypeDailyInterest)))
+		require.NoError(t, ValidateLateFeeType(string(models.LateFeeTypeMonthlyInterest)))
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		err := ValidateLateFeeType("weekly_interest")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid late fee type: weekly_interest")
+	})
+}
+
+func TestRoundAmount(t *testing.T) {
+	require.Equal(t, 10.0, RoundAmount(10))
+	require.Equal(t, 10.13, RoundAmount(10.125))
+	require.Equal(t, 10.12, RoundAmount(10.1249))
+	require.Equal(t, 0.0, RoundAmount(0.004))
+	require.Equal(t, -5.5, RoundAmount(-5.499999))
+}
+
+func TestDaysBetween(t *testing.T) {
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	require.Equal(t, 0, DaysBetween(day, day.Add(23*time.Hour)))
+	require.Equal(t, 1, DaysBetween(day.Add(23*time.Hour), day.AddDate(0, 0, 1)))
+	require.Equal(t, 30, DaysBetween(day, day.AddDate(0, 0, 30)))
+	require.Equal(t, -3, DaysBetween(day, day.AddDate(0, 0, -3)))
+}
+
+func TestValidateLateFeePolicy(t *testing.T) {
+	maxAmount, maxPercentage, negative := 50.0, 10.0, -1.0
+
+	t.Run("valid flat fee", func(t *testing.T) {
+		err := ValidateLateFeePolicy(models.SetLateFeePolicyRequest{FeeType: "flat", FlatAmount: 25, GraceDays: 3})
+		require.NoError(t, err)
+	})
+
+	t.Run("valid interest with caps", func(t *testing.T) {
+		err := ValidateLateFeePolicy(models.SetLateFeePolicyRequest{
+			FeeType: "monthly_interest", InterestRate: 1.5, MaxAmount: &maxAmount, MaxPercentage: &maxPercentage,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid fee type", func(t *testing.T) {
+		err := ValidateLateFeePolicy(models.SetLateFeePolicyRequest{FeeType: "yearly"})
+		require.ErrorContains(t, err, "invalid late fee type")
+	})
+
+	t.Run("flat fee without amount", func(t *testing.T) {
+		err := ValidateLateFeePolicy(models.SetLateFeePolicyRequest{FeeType: "flat"})
+		require.ErrorContains(t, err, "flat_amount")
+	})
+
+	t.Run("interest without rate", func(t *testing.T) {
+		err := ValidateLateFeePolicy(models.SetLateFeePolicyRequest{FeeType: "daily_interest"})
+		require.ErrorContains(t, err, "interest_rate")
+	})
+
+	t.Run("negative grace days", func(t *testing.T) {
+		err := ValidateLateFeePolicy(models.SetLateFeePolicyRequest{FeeType: "flat", FlatAmount: 10, GraceDays: -1})
+		require.ErrorContains(t, err, "grace_days")
+	})
+
+	t.Run("negative caps", func(t *testing.T) {
+		err := ValidateLateFeePolicy(models.SetLateFeePolicyRequest{FeeType: "flat", FlatAmount: 10, MaxAmount: &negative})
+		require.ErrorContains(t, err, "max_amount")
+
+		err = ValidateLateFeePolicy(models.SetLateFeePolicyRequest{FeeType: "flat", FlatAmount: 10, MaxPercentage: &negative})
+		require.ErrorContains(t, err, "max_percentage")
+	})
+}
+
+func TestValidateTaxRate(t *testing.T) {
+	rate, negative, tooHigh := 7.5, -1.0, 101.0
+
+	require.NoError(t, ValidateTaxRate(models.CreateTaxRateRequest{Name: "VAT", Rate: &rate}))
+	require.NoError(t, ValidateTaxRate(models.CreateTaxRateRequest{Name: "VAT", Rate: &rate, IsInclusive: true}))
+	require.NoError(t, ValidateTaxRate(models.CreateTaxRateRequest{Name: "QST", Rate: &rate, IsCompound: true}))
+
+	require.ErrorContains(t, ValidateTaxRate(models.CreateTaxRateRequest{Name: " ", Rate: &rate}), "name")
+	require.ErrorContains(t, ValidateTaxRate(models.CreateTaxRateRequest{Name: "VAT"}), "rate")
+	require.ErrorContains(t, ValidateTaxRate(models.CreateTaxRateRequest{Name: "VAT", Rate: &negative}), "rate")
+	require.ErrorContains(t, ValidateTaxRate(models.CreateTaxRateRequest{Name: "VAT", Rate: &tooHigh}), "rate")
+	require.ErrorContains(t, ValidateTaxRate(models.CreateTaxRateRequest{Name: "VAT", Rate: &rate, IsInclusive: true, IsCompound: true}), "inclusive and compound")
+}
+
+func TestValidatePaymentMethodUpdate(t *testing.T) {
+	name, empty, long := "Acme Ltd", " ", strings.Repeat("1", 51)
+
+	require.NoError(t, ValidatePaymentMethodUpdate(models.UpdatePaymentMethodRequest{AccountName: &name}))
+	require.NoError(t, ValidatePaymentMethodUpdate(models.UpdatePaymentMethodRequest{BankAddress: &empty, SwiftCode: &empty}))
+
+	require.ErrorContains(t, ValidatePaymentMethodUpdate(models.UpdatePaymentMethodRequest{}), "at least one field")
+	require.ErrorContains(t, ValidatePaymentMethodUpdate(models.UpdatePaymentMethodRequest{BankName: &empty}), "bank_name cannot be empty")
+	require.ErrorContains(t, ValidatePaymentMethodUpdate(models.UpdatePaymentMethodRequest{AccountNumber: &long}), "account_number must be at most 50 characters")
+}
+
+func TestValidateWebhook(t *testing.T) {
+	valid := models.CreateWebhookRequest{URL: "https://example.com/hooks", EventTypes: []string{"invoice.created", "invoice.paid"}}
+	require.NoError(t, ValidateWebhook(valid))
+
+	withSecret := valid
+	withSecret.Secret = strings.Repeat("s", 16)
+	require.NoError(t, ValidateWebhook(withSecret))
+
+	for _, target := range []string{"", "example.com/hooks", "ftp://example.com", "https://"} {
+		invalid := valid
+		invalid.URL = target
+		require.ErrorContains(t, ValidateWebhook(invalid), "url")
+	}
+	for _, target := range []string{"http://localhost:8080/hooks", "https://api.localhost/hooks", "http://127.0.0.1/hooks",
+		"http://10.0.0.5/hooks", "http://169.254.169.254/latest/meta-data", "http://[::1]/hooks", "http://[::ffff:192.168.1.1]/"} {
+		private := valid
+		private.URL = target
+		require.ErrorContains(t, ValidateWebhook(private), "private address", target)
+	}
+	require.ErrorContains(t, ValidateWebhook(models.CreateWebhookRequest{URL: valid.URL}), "at least one event type")
+	require.ErrorContains(t, ValidateWebhook(models.CreateWebhookRequest{URL: valid.URL, EventTypes: []string{"invoice.deleted"}}), "invalid webhook event type")
+
+	shortSecret := valid
+	shortSecret.Secret = "secret"
+	require.ErrorContains(t, ValidateWebhook(shortSecret), "secret")
+}
+
+func TestValidateTaxReportBasis(t *testing.T) {
+	require.NoError(t, ValidateTaxReportBasis("invoice"))
+	require.NoError(t, ValidateTaxReportBasis("cash"))
+	require.ErrorContains(t, ValidateTaxReportBasis("accrual"), "invalid tax report basis")
+}
+
+func TestValidateTotalsDateField(t *testing.T) {
+	require.NoError(t, ValidateTotalsDateField("issue_date"))
+	require.NoError(t, ValidateTotalsDateField("due_date"))
+	require.ErrorContains(t, ValidateTotalsDateField("created_at"), "invalid date field")
+}
+
+func TestValidateInvoiceSortField(t *testing.T) {
+	for _, field := range []string{"due_date", "amount", "number", "created_at"} {
+		require.NoError(t, ValidateInvoiceSortField(field))
+	}
+	require.ErrorContains(t, ValidateInvoiceSortField("customer"), "invalid sort field")
+}
+
+func TestValidateSortOrder(t *testing.T) {
+	require.NoError(t, ValidateSortOrder("asc"))
+	require.NoError(t, ValidateSortOrder("desc"))
+	require.ErrorContains(t, ValidateSortOrder("up"), "invalid sort order")
+}
+
+func TestValidateExportFormat(t *testing.T) {
+	require.NoError(t, ValidateExportFormat("csv"))
+	require.NoError(t, ValidateExportFormat("xlsx"))
+	require.ErrorContains(t, ValidateExportFormat("pdf"), "invalid export format")
+}
+
+func TestValidateSeriesInterval(t *testing.T) {
+	for _, interval := range []string{"day", "week", "month"} {
+		require.NoError(t, ValidateSeriesInterval(interval))
+	}
+	require.ErrorContains(t, ValidateSeriesInterval("year"), "invalid interval")
+}
+
+func TestValidateSeriesGroupBy(t *testing.T) {
+	for _, groupBy := range []string{"", "customer", "currency"} {
+		require.NoError(t, ValidateSeriesGroupBy(groupBy))
+	}
+	require.ErrorContains(t, ValidateSeriesGroupBy("status"), "invalid group by")
+}
+
+func TestValidateAgingBucket(t *testing.T) {
+	for _, bucket := range []string{"current", "1-30", "31-60", "61-90", "90+"} {
+		require.NoError(t, ValidateAgingBucket(bucket))
+	}
+	require.ErrorContains(t, ValidateAgingBucket("91-120"), "invalid aging bucket")
+}
+
+func TestValidateCurrency(t *testing.T) {
+	require.NoError(t, ValidateCurrency("NGN"))
+	require.NoError(t, ValidateCurrency("USD"))
+	require.NoError(t, ValidateCurrency("EUR"))
+	require.ErrorContains(t, ValidateCurrency("usd"), "invalid currency")
+	require.ErrorContains(t, ValidateCurrency("XYZ"), "invalid currency")
+	require.ErrorContains(t, ValidateCurrency(""), "invalid currency")
+}
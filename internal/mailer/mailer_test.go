@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Numeris-Task/internal/config"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("falls back to log mailer without host", func(t *testing.T) {
+		m := New(config.LoadMailer("", "", "", "", ""))
+		require.IsType(t, logMailer{}, m)
+		require.NoError(t, m.Send(context.Background(), Message{To: "customer@example.com", Subject: "Hello"}))
+	})
+
+	t.Run("smtp mailer with host", func(t *testing.T) {
+		m := New(config.LoadMailer("smtp.example.com", "587", "user", "pass", "billing@example.com"))
+		smtpM, ok := m.(*smtpMailer)
+		require.True(t, ok)
+		require.Equal(t, "smtp.example.com:587", smtpM.addr)
+		require.Equal(t, "billing@example.com", smtpM.from)
+		require.NotNil(t, smtpM.auth)
+	})
+
+	t.Run("smtp mailer respects cancelled context", func(t *testing.T) {
+		m := New(config.LoadMailer("smtp.example.com", "587", "", "", "billing@example.com"))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.ErrorIs(t, m.Send(ctx, Message{To: "customer@example.com"}), context.Canceled)
+	})
+}
+
+func TestBuildMessage(t *testing.T) {
+	msg := buildMessage("billing@example.com", Message{
+		To:      "customer@example.com",
+		Subject: "Invoice 123",
+		Body:    "line one\nline two",
+	})
+
+	require.Contains(t, string(msg), "From: billing@example.com\r\n")
+	require.Contains(t, string(msg), "To: customer@example.com\r\n")
+	require.Contains(t, string(msg), "Subject: Invoice 123\r\n")
+	require.Contains(t, string(msg), "\r\n\r\nline one\r\nline two")
+}
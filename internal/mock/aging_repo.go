@@ -0,0 +1,58 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zde37/Numeris-Task/internal/repository (interfaces: AgingRepository)
+//
+// Generated by this command:
+//
+//	mockgen -package mocked -destination internal/mock/aging_repo.go github.com/zde37/Numeris-Task/internal/repository AgingRepository
+//
+
+// Package mocked is a generated GoMock package.
+package mocked
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	models "github.com/zde37/Numeris-Task/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAgingRepository is a mock of AgingRepository interface.
+type MockAgingRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAgingRepositoryMockRecorder
+}
+
+// MockAgingRepositoryMockRecorder is the mock recorder for MockAgingRepository.
+type MockAgingRepositoryMockRecorder struct {
+	mock *MockAgingRepository
+}
+
+// NewMockAgingRepository creates a new mock instance.
+func NewMockAgingRepository(ctrl *gomock.Controller) *MockAgingRepository {
+	mock := &MockAgingRepository{ctrl: ctrl}
+	mock.recorder = &MockAgingRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAgingRepository) EXPECT() *MockAgingRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetOpenInvoices mocks base method.
+func (m *MockAgingRepository) GetOpenInvoices(arg0 context.Context, arg1 uuid.UUID, arg2 time.Time, arg3 *uuid.UUID) ([]models.AgingInvoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOpenInvoices", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.AgingInvoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOpenInvoices indicates an expected call of GetOpenInvoices.
+func (mr *MockAgingRepositoryMockRecorder) GetOpenInvoices(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOpenInvoices", reflect.TypeOf((*MockAgingRepository)(nil).GetOpenInvoices), arg0, arg1, arg2, arg3)
+}
@@ -0,0 +1,73 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zde37/Numeris-Task/internal/service (interfaces: AgingService)
+//
+// Generated by this command:
+//
+//	mockgen -package mocked -destination internal/mock/aging_service.go github.com/zde37/Numeris-Task/internal/service AgingService
+//
+
+// Package mocked is a generated GoMock package.
+package mocked
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	models "github.com/zde37/Numeris-Task/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAgingService is a mock of AgingService interface.
+type MockAgingService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAgingServiceMockRecorder
+}
+
+// MockAgingServiceMockRecorder is the mock recorder for MockAgingService.
+type MockAgingServiceMockRecorder struct {
+	mock *MockAgingService
+}
+
+// NewMockAgingService creates a new mock instance.
+func NewMockAgingService(ctrl *gomock.Controller) *MockAgingService {
+	mock := &MockAgingService{ctrl: ctrl}
+	mock.recorder = &MockAgingServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAgingService) EXPECT() *MockAgingServiceMockRecorder {
+	return m.recorder
+}
+
+// GetAgingInvoices mocks base method.
+func (m *MockAgingService) GetAgingInvoices(arg0 context.Context, arg1 uuid.UUID, arg2 time.Time, arg3 models.AgingBucket, arg4 *uuid.UUID) ([]models.AgingInvoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAgingInvoices", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]models.AgingInvoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAgingInvoices indicates an expected call of GetAgingInvoices.
+func (mr *MockAgingServiceMockRecorder) GetAgingInvoices(arg0, arg1, arg2, arg3, arg4 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAgingInvoices", reflect.TypeOf((*MockAgingService)(nil).GetAgingInvoices), arg0, arg1, arg2, arg3, arg4)
+}
+
+// GetAgingReport mocks base method.
+func (m *MockAgingService) GetAgingReport(arg0 context.Context, arg1 uuid.UUID, arg2 time.Time, arg3 *uuid.UUID) (*models.AgingReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAgingReport", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.AgingReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAgingReport indicates an expected call of GetAgingReport.
+func (mr *MockAgingServiceMockRecorder) GetAgingReport(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAgingReport", reflect.TypeOf((*MockAgingService)(nil).GetAgingReport), arg0, arg1, arg2, arg3)
+}
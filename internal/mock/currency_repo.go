@@ -0,0 +1,88 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zde37/Numeris-Task/internal/repository (interfaces: CurrencyRepository)
+//
+// Generated by this command:
+//
+//	mockgen -package mocked -destination internal/mock/currency_repo.go github.com/zde37/Numeris-Task/internal/repository CurrencyRepository
+//
+
+// Package mocked is a generated GoMock package.
+package mocked
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	models "github.com/zde37/Numeris-Task/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCurrencyRepository is a mock of CurrencyRepository interface.
+type MockCurrencyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCurrencyRepositoryMockRecorder
+}
+
+// MockCurrencyRepositoryMockRecorder is the mock recorder for MockCurrencyRepository.
+type MockCurrencyRepositoryMockRecorder struct {
+	mock *MockCurrencyRepository
+}
+
+// NewMockCurrencyRepository creates a new mock instance.
+func NewMockCurrencyRepository(ctrl *gomock.Controller) *MockCurrencyRepository {
+	mock := &MockCurrencyRepository{ctrl: ctrl}
+	mock.recorder = &MockCurrencyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCurrencyRepository) EXPECT() *MockCurrencyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetBaseCurrency mocks base method.
+func (m *MockCurrencyRepository) GetBaseCurrency(arg0 context.Context, arg1 uuid.UUID) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBaseCurrency", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBaseCurrency indicates an expected call of GetBaseCurrency.
+func (mr *MockCurrencyRepositoryMockRecorder) GetBaseCurrency(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBaseCurrency", reflect.TypeOf((*MockCurrencyRepository)(nil).GetBaseCurrency), arg0, arg1)
+}
+
+// GetExchangeRate mocks base method.
+func (m *MockCurrencyRepository) GetExchangeRate(arg0 context.Context, arg1, arg2 string, arg3 time.Time) (*models.ExchangeRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExchangeRate", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.ExchangeRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExchangeRate indicates an expected call of GetExchangeRate.
+func (mr *MockCurrencyRepositoryMockRecorder) GetExchangeRate(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExchangeRate", reflect.TypeOf((*MockCurrencyRepository)(nil).GetExchangeRate), arg0, arg1, arg2, arg3)
+}
+
+// SaveExchangeRates mocks base method.
+func (m *MockCurrencyRepository) SaveExchangeRates(arg0 context.Context, arg1 []models.ExchangeRate) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveExchangeRates", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveExchangeRates indicates an expected call of SaveExchangeRates.
+func (mr *MockCurrencyRepositoryMockRecorder) SaveExchangeRates(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveExchangeRates", reflect.TypeOf((*MockCurrencyRepository)(nil).SaveExchangeRates), arg0, arg1)
+}
@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zde37/Numeris-Task/internal/service (interfaces: CurrencyService)
+//
+// Generated by this command:
+//
+//	mockgen -package mocked -destination internal/mock/currency_service.go github.com/zde37/Numeris-Task/internal/service CurrencyService
+//
+
+// Package mocked is a generated GoMock package.
+package mocked
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	models "github.com/zde37/Numeris-Task/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCurrencyService is a mock of CurrencyService interface.
+type MockCurrencyService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCurrencyServiceMockRecorder
+}
+
+// MockCurrencyServiceMockRecorder is the mock recorder for MockCurrencyService.
+type MockCurrencyServiceMockRecorder struct {
+	mock *MockCurrencyService
+}
+
+// NewMockCurrencyService creates a new mock instance.
+func NewMockCurrencyService(ctrl *gomock.Controller) *MockCurrencyService {
+	mock := &MockCurrencyService{ctrl: ctrl}
+	mock.recorder = &MockCurrencyServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCurrencyService) EXPECT() *MockCurrencyServiceMockRecorder {
+	return m.recorder
+}
+
+// GetExchangeRate mocks base method.
+func (m *MockCurrencyService) GetExchangeRate(arg0 context.Context, arg1, arg2 string, arg3 time.Time) (*models.ExchangeRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExchangeRate", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.ExchangeRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExchangeRate indicates an expected call of GetExchangeRate.
+func (mr *MockCurrencyServiceMockRecorder) GetExchangeRate(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExchangeRate", reflect.TypeOf((*MockCurrencyService)(nil).GetExchangeRate), arg0, arg1, arg2, arg3)
+}
+
+// RefreshExchangeRates mocks base method.
+func (m *MockCurrencyService) RefreshExchangeRates(arg0 context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshExchangeRates", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshExchangeRates indicates an expected call of RefreshExchangeRates.
+func (mr *MockCurrencyServiceMockRecorder) RefreshExchangeRates(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshExchangeRates", reflect.TypeOf((*MockCurrencyService)(nil).RefreshExchangeRates), arg0)
+}
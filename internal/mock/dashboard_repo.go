@@ -0,0 +1,58 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zde37/Numeris-Task/internal/repository (interfaces: DashboardRepository)
+//
+// Generated by this command:
+//
+//	mockgen -package mocked -destination internal/mock/dashboard_repo.go github.com/zde37/Numeris-Task/internal/repository DashboardRepository
+//
+
+// Package mocked is a generated GoMock package.
+package mocked
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	models "github.com/zde37/Numeris-Task/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDashboardRepository is a mock of DashboardRepository interface.
+type MockDashboardRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDashboardRepositoryMockRecorder
+}
+
+// MockDashboardRepositoryMockRecorder is the mock recorder for MockDashboardRepository.
+type MockDashboardRepositoryMockRecorder struct {
+	mock *MockDashboardRepository
+}
+
+// NewMockDashboardRepository creates a new mock instance.
+func NewMockDashboardRepository(ctrl *gomock.Controller) *MockDashboardRepository {
+	mock := &MockDashboardRepository{ctrl: ctrl}
+	mock.recorder = &MockDashboardRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDashboardRepository) EXPECT() *MockDashboardRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetDashboard mocks base method.
+func (m *MockDashboardRepository) GetDashboard(arg0 context.Context, arg1 uuid.UUID, arg2 time.Time, arg3 int32) (*models.Dashboard, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDashboard", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.Dashboard)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDashboard indicates an expected call of GetDashboard.
+func (mr *MockDashboardRepositoryMockRecorder) GetDashboard(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDashboard", reflect.TypeOf((*MockDashboardRepository)(nil).GetDashboard), arg0, arg1, arg2, arg3)
+}
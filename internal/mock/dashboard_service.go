@@ -0,0 +1,58 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zde37/Numeris-Task/internal/service (interfaces: DashboardService)
+//
+// Generated by this command:
+//
+//	mockgen -package mocked -destination internal/mock/dashboard_service.go github.com/zde37/Numeris-Task/internal/service DashboardService
+//
+
+// Package mocked is a generated GoMock package.
+package mocked
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	models "github.com/zde37/Numeris-Task/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDashboardService is a mock of DashboardService interface.
+type MockDashboardService struct {
+	ctrl     *gomock.Controller
+	recorder *MockDashboardServiceMockRecorder
+}
+
+// MockDashboardServiceMockRecorder is the mock recorder for MockDashboardService.
+type MockDashboardServiceMockRecorder struct {
+	mock *MockDashboardService
+}
+
+// NewMockDashboardService creates a new mock instance.
+func NewMockDashboardService(ctrl *gomock.Controller) *MockDashboardService {
+	mock := &MockDashboardService{ctrl: ctrl}
+	mock.recorder = &MockDashboardServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDashboardService) EXPECT() *MockDashboardServiceMockRecorder {
+	return m.recorder
+}
+
+// GetDashboard mocks base method.
+func (m *MockDashboardService) GetDashboard(arg0 context.Context, arg1 uuid.UUID, arg2 time.Time) (*models.Dashboard, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDashboard", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Dashboard)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDashboard indicates an expected call of GetDashboard.
+func (mr *MockDashboardServiceMockRecorder) GetDashboard(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDashboard", reflect.TypeOf((*MockDashboardService)(nil).GetDashboard), arg0, arg1, arg2)
+}
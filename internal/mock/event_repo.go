@@ -0,0 +1,101 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zde37/Numeris-Task/internal/repository (interfaces: EventRepository)
+//
+// Generated by this command:
+//
+//	mockgen -package mocked -destination internal/mock/event_repo.go github.com/zde37/Numeris-Task/internal/repository EventRepository
+//
+
+// Package mocked is a generated GoMock package.
+package mocked
+
+import (
+	context "context"
+	reflect "reflect"
+
+	uuid "github.com/google/uuid"
+	models "github.com/zde37/Numeris-Task/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockEventRepository is a mock of EventRepository interface.
+type MockEventRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventRepositoryMockRecorder
+}
+
+// MockEventRepositoryMockRecorder is the mock recorder for MockEventRepository.
+type MockEventRepositoryMockRecorder struct {
+	mock *MockEventRepository
+}
+
+// NewMockEventRepository creates a new mock instance.
+func NewMockEventRepository(ctrl *gomock.Controller) *MockEventRepository {
+	mock := &MockEventRepository{ctrl: ctrl}
+	mock.recorder = &MockEventRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventRepository) EXPECT() *MockEventRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetActivityEvent mocks base method.
+func (m *MockEventRepository) GetActivityEvent(arg0 context.Context, arg1 int64) (*models.ActivityEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActivityEvent", arg0, arg1)
+	ret0, _ := ret[0].(*models.ActivityEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActivityEvent indicates an expected call of GetActivityEvent.
+func (mr *MockEventRepositoryMockRecorder) GetActivityEvent(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActivityEvent", reflect.TypeOf((*MockEventRepository)(nil).GetActivityEvent), arg0, arg1)
+}
+
+// GetActivityEvents mocks base method.
+func (m *MockEventRepository) GetActivityEvents(arg0 context.Context, arg1 uuid.UUID, arg2 models.EventCursor, arg3 int32) ([]models.ActivityEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActivityEvents", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.ActivityEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActivityEvents indicates an expected call of GetActivityEvents.
+func (mr *MockEventRepositoryMockRecorder) GetActivityEvents(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActivityEvents", reflect.TypeOf((*MockEventRepository)(nil).GetActivityEvents), arg0, arg1, arg2, arg3)
+}
+
+// GetActivityEventsSince mocks base method.
+func (m *MockEventRepository) GetActivityEventsSince(arg0 context.Context, arg1 uuid.UUID, arg2 int64, arg3 int32) ([]models.ActivityEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActivityEventsSince", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.ActivityEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActivityEventsSince indicates an expected call of GetActivityEventsSince.
+func (mr *MockEventRepositoryMockRecorder) GetActivityEventsSince(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActivityEventsSince", reflect.TypeOf((*MockEventRepository)(nil).GetActivityEventsSince), arg0, arg1, arg2, arg3)
+}
+
+// ListenActivityEvents mocks base method.
+func (m *MockEventRepository) ListenActivityEvents(arg0 context.Context, arg1 func(), arg2 func(models.ActivityNotification)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListenActivityEvents", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListenActivityEvents indicates an expected call of ListenActivityEvents.
+func (mr *MockEventRepositoryMockRecorder) ListenActivityEvents(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListenActivityEvents", reflect.TypeOf((*MockEventRepository)(nil).ListenActivityEvents), arg0, arg1, arg2)
+}
@@ -0,0 +1,73 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zde37/Numeris-Task/internal/service (interfaces: ImportService)
+//
+// Generated by this command:
+//
+//	mockgen -package mocked -destination internal/mock/import_service.go github.com/zde37/Numeris-Task/internal/service ImportService
+//
+
+// Package mocked is a generated GoMock package.
+package mocked
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+
+	uuid "github.com/google/uuid"
+	models "github.com/zde37/Numeris-Task/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockImportService is a mock of ImportService interface.
+type MockImportService struct {
+	ctrl     *gomock.Controller
+	recorder *MockImportServiceMockRecorder
+}
+
+// MockImportServiceMockRecorder is the mock recorder for MockImportService.
+type MockImportServiceMockRecorder struct {
+	mock *MockImportService
+}
+
+// NewMockImportService creates a new mock instance.
+func NewMockImportService(ctrl *gomock.Controller) *MockImportService {
+	mock := &MockImportService{ctrl: ctrl}
+	mock.recorder = &MockImportServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImportService) EXPECT() *MockImportServiceMockRecorder {
+	return m.recorder
+}
+
+// ImportCustomers mocks base method.
+func (m *MockImportService) ImportCustomers(arg0 context.Context, arg1 uuid.UUID, arg2 io.Reader, arg3 bool) (*models.ImportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportCustomers", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.ImportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportCustomers indicates an expected call of ImportCustomers.
+func (mr *MockImportServiceMockRecorder) ImportCustomers(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportCustomers", reflect.TypeOf((*MockImportService)(nil).ImportCustomers), arg0, arg1, arg2, arg3)
+}
+
+// ImportInvoices mocks base method.
+func (m *MockImportService) ImportInvoices(arg0 context.Context, arg1 uuid.UUID, arg2 io.Reader, arg3 bool) (*models.ImportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportInvoices", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.ImportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportInvoices indicates an expected call of ImportInvoices.
+func (mr *MockImportServiceMockRecorder) ImportInvoices(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportInvoices", reflect.TypeOf((*MockImportService)(nil).ImportInvoices), arg0, arg1, arg2, arg3)
+}
@@ -12,9 +12,11 @@ package mocked
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	uuid "github.com/google/uuid"
 	models "github.com/zde37/Numeris-Task/internal/models"
+	pagination "github.com/zde37/Numeris-Task/internal/pagination"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -57,27 +59,57 @@ func (mr *MockInvoiceRepositoryMockRecorder) AddInvoiceActivity(arg0, arg1 any)
 }
 
 // CreateInvoice mocks base method.
-func (m *MockInvoiceRepository) CreateInvoice(arg0 context.Context, arg1 models.Invoice, arg2 []models.InvoiceItem, arg3 uuid.UUID, arg4 models.PaymentInformation) (uuid.UUID, error) {
+func (m *MockInvoiceRepository) CreateInvoice(arg0 context.Context, arg1 models.Invoice, arg2 []models.InvoiceItem, arg3 []models.InvoiceTaxLine, arg4 uuid.UUID, arg5 models.PaymentInformation) (uuid.UUID, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateInvoice", arg0, arg1, arg2, arg3, arg4)
+	ret := m.ctrl.Call(m, "CreateInvoice", arg0, arg1, arg2, arg3, arg4, arg5)
 	ret0, _ := ret[0].(uuid.UUID)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateInvoice indicates an expected call of CreateInvoice.
-func (mr *MockInvoiceRepositoryMockRecorder) CreateInvoice(arg0, arg1, arg2, arg3, arg4 any) *gomock.Call {
+func (mr *MockInvoiceRepositoryMockRecorder) CreateInvoice(arg0, arg1, arg2, arg3, arg4, arg5 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvoice", reflect.TypeOf((*MockInvoiceRepository)(nil).CreateInvoice), arg0, arg1, arg2, arg3, arg4)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvoice", reflect.TypeOf((*MockInvoiceRepository)(nil).CreateInvoice), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// CreateInvoices mocks base method.
+func (m *MockInvoiceRepository) CreateInvoices(arg0 context.Context, arg1 []models.NewInvoice) ([]error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInvoices", arg0, arg1)
+	ret0, _ := ret[0].([]error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInvoices indicates an expected call of CreateInvoices.
+func (mr *MockInvoiceRepositoryMockRecorder) CreateInvoices(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvoices", reflect.TypeOf((*MockInvoiceRepository)(nil).CreateInvoices), arg0, arg1)
+}
+
+// ExportInvoices mocks base method.
+func (m *MockInvoiceRepository) ExportInvoices(arg0 context.Context, arg1 models.InvoiceFilter, arg2 bool, arg3 func(models.InvoiceExportRow) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportInvoices", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportInvoices indicates an expected call of ExportInvoices.
+func (mr *MockInvoiceRepositoryMockRecorder) ExportInvoices(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportInvoices", reflect.TypeOf((*MockInvoiceRepository)(nil).ExportInvoices), arg0, arg1, arg2, arg3)
 }
 
 // GetInvoiceActivities mocks base method.
-func (m *MockInvoiceRepository) GetInvoiceActivities(arg0 context.Context, arg1, arg2 uuid.UUID, arg3, arg4 int32) ([]models.InvoiceActivity, error) {
+func (m *MockInvoiceRepository) GetInvoiceActivities(arg0 context.Context, arg1, arg2 uuid.UUID, arg3 models.ActivityFilter, arg4 pagination.Request) ([]models.InvoiceActivity, *pagination.Meta, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetInvoiceActivities", arg0, arg1, arg2, arg3, arg4)
 	ret0, _ := ret[0].([]models.InvoiceActivity)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(*pagination.Meta)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // GetInvoiceActivities indicates an expected call of GetInvoiceActivities.
@@ -102,12 +134,13 @@ func (mr *MockInvoiceRepositoryMockRecorder) GetInvoiceDetails(arg0, arg1 any) *
 }
 
 // GetRecentActivities mocks base method.
-func (m *MockInvoiceRepository) GetRecentActivities(arg0 context.Context, arg1 uuid.UUID, arg2, arg3 int32) ([]models.RecentActivity, error) {
+func (m *MockInvoiceRepository) GetRecentActivities(arg0 context.Context, arg1 uuid.UUID, arg2 models.ActivityFilter, arg3 pagination.Request) ([]models.RecentActivity, *pagination.Meta, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetRecentActivities", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].([]models.RecentActivity)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(*pagination.Meta)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // GetRecentActivities indicates an expected call of GetRecentActivities.
@@ -117,28 +150,28 @@ func (mr *MockInvoiceRepositoryMockRecorder) GetRecentActivities(arg0, arg1, arg
 }
 
 // GetRecentInvoices mocks base method.
-func (m *MockInvoiceRepository) GetRecentInvoices(arg0 context.Context, arg1 uuid.UUID, arg2, arg3 int32) ([]models.Invoice, error) {
+func (m *MockInvoiceRepository) GetRecentInvoices(arg0 context.Context, arg1 uuid.UUID, arg2 pagination.Request) ([]models.Invoice, *pagination.Meta, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRecentInvoices", arg0, arg1, arg2, arg3)
+	ret := m.ctrl.Call(m, "GetRecentInvoices", arg0, arg1, arg2)
 	ret0, _ := ret[0].([]models.Invoice)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(*pagination.Meta)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // GetRecentInvoices indicates an expected call of GetRecentInvoices.
-func (mr *MockInvoiceRepositoryMockRecorder) GetRecentInvoices(arg0, arg1, arg2, arg3 any) *gomock.Call {
+func (mr *MockInvoiceRepositoryMockRecorder) GetRecentInvoices(arg0, arg1, arg2 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecentInvoices", reflect.TypeOf((*MockInvoiceRepository)(nil).GetRecentInvoices), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecentInvoices", reflect.TypeOf((*MockInvoiceRepository)(nil).GetRecentInvoices), arg0, arg1, arg2)
 }
 
 // GetTotalByStatus mocks base method.
-func (m *MockInvoiceRepository) GetTotalByStatus(arg0 context.Context, arg1 models.InvoiceStatus) (float64, int, error) {
+func (m *MockInvoiceRepository) GetTotalByStatus(arg0 context.Context, arg1 models.TotalsFilter) ([]models.StatusTotals, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetTotalByStatus", arg0, arg1)
-	ret0, _ := ret[0].(float64)
-	ret1, _ := ret[1].(int)
-	ret2, _ := ret[2].(error)
-	return ret0, ret1, ret2
+	ret0, _ := ret[0].([]models.StatusTotals)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // GetTotalByStatus indicates an expected call of GetTotalByStatus.
@@ -146,3 +179,34 @@ func (mr *MockInvoiceRepositoryMockRecorder) GetTotalByStatus(arg0, arg1 any) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTotalByStatus", reflect.TypeOf((*MockInvoiceRepository)(nil).GetTotalByStatus), arg0, arg1)
 }
+
+// MarkOverdueInvoices mocks base method.
+func (m *MockInvoiceRepository) MarkOverdueInvoices(arg0 context.Context, arg1 time.Time, arg2 int32) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOverdueInvoices", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkOverdueInvoices indicates an expected call of MarkOverdueInvoices.
+func (mr *MockInvoiceRepositoryMockRecorder) MarkOverdueInvoices(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOverdueInvoices", reflect.TypeOf((*MockInvoiceRepository)(nil).MarkOverdueInvoices), arg0, arg1, arg2)
+}
+
+// SearchInvoices mocks base method.
+func (m *MockInvoiceRepository) SearchInvoices(arg0 context.Context, arg1 models.InvoiceFilter, arg2 pagination.Request) ([]models.Invoice, *pagination.Meta, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchInvoices", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.Invoice)
+	ret1, _ := ret[1].(*pagination.Meta)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SearchInvoices indicates an expected call of SearchInvoices.
+func (mr *MockInvoiceRepositoryMockRecorder) SearchInvoices(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchInvoices", reflect.TypeOf((*MockInvoiceRepository)(nil).SearchInvoices), arg0, arg1, arg2)
+}
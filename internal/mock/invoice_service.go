@@ -12,9 +12,11 @@ package mocked
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	uuid "github.com/google/uuid"
 	models "github.com/zde37/Numeris-Task/internal/models"
+	pagination "github.com/zde37/Numeris-Task/internal/pagination"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -71,13 +73,28 @@ func (mr *MockInvoiceServiceMockRecorder) CreateInvoice(arg0, arg1 any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvoice", reflect.TypeOf((*MockInvoiceService)(nil).CreateInvoice), arg0, arg1)
 }
 
+// ExportInvoices mocks base method.
+func (m *MockInvoiceService) ExportInvoices(arg0 context.Context, arg1 models.InvoiceFilter, arg2 bool, arg3 func(models.InvoiceExportRow) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportInvoices", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportInvoices indicates an expected call of ExportInvoices.
+func (mr *MockInvoiceServiceMockRecorder) ExportInvoices(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportInvoices", reflect.TypeOf((*MockInvoiceService)(nil).ExportInvoices), arg0, arg1, arg2, arg3)
+}
+
 // GetInvoiceActivities mocks base method.
-func (m *MockInvoiceService) GetInvoiceActivities(arg0 context.Context, arg1, arg2 uuid.UUID, arg3, arg4 int32) ([]models.InvoiceActivity, error) {
+func (m *MockInvoiceService) GetInvoiceActivities(arg0 context.Context, arg1, arg2 uuid.UUID, arg3 models.ActivityFilter, arg4 pagination.Request) ([]models.InvoiceActivity, *pagination.Meta, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetInvoiceActivities", arg0, arg1, arg2, arg3, arg4)
 	ret0, _ := ret[0].([]models.InvoiceActivity)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(*pagination.Meta)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // GetInvoiceActivities indicates an expected call of GetInvoiceActivities.
@@ -102,12 +119,13 @@ func (mr *MockInvoiceServiceMockRecorder) GetInvoiceDetails(arg0, arg1 any) *gom
 }
 
 // GetRecentActivities mocks base method.
-func (m *MockInvoiceService) GetRecentActivities(arg0 context.Context, arg1 uuid.UUID, arg2, arg3 int32) ([]models.RecentActivity, error) {
+func (m *MockInvoiceService) GetRecentActivities(arg0 context.Context, arg1 uuid.UUID, arg2 models.ActivityFilter, arg3 pagination.Request) ([]models.RecentActivity, *pagination.Meta, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetRecentActivities", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].([]models.RecentActivity)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(*pagination.Meta)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // GetRecentActivities indicates an expected call of GetRecentActivities.
@@ -117,28 +135,28 @@ func (mr *MockInvoiceServiceMockRecorder) GetRecentActivities(arg0, arg1, arg2,
 }
 
 // GetRecentInvoices mocks base method.
-func (m *MockInvoiceService) GetRecentInvoices(arg0 context.Context, arg1 uuid.UUID, arg2, arg3 int32) ([]models.Invoice, error) {
+func (m *MockInvoiceService) GetRecentInvoices(arg0 context.Context, arg1 uuid.UUID, arg2 pagination.Request) ([]models.Invoice, *pagination.Meta, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRecentInvoices", arg0, arg1, arg2, arg3)
+	ret := m.ctrl.Call(m, "GetRecentInvoices", arg0, arg1, arg2)
 	ret0, _ := ret[0].([]models.Invoice)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(*pagination.Meta)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // GetRecentInvoices indicates an expected call of GetRecentInvoices.
-func (mr *MockInvoiceServiceMockRecorder) GetRecentInvoices(arg0, arg1, arg2, arg3 any) *gomock.Call {
+func (mr *MockInvoiceServiceMockRecorder) GetRecentInvoices(arg0, arg1, arg2 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecentInvoices", reflect.TypeOf((*MockInvoiceService)(nil).GetRecentInvoices), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecentInvoices", reflect.TypeOf((*MockInvoiceService)(nil).GetRecentInvoices), arg0, arg1, arg2)
 }
 
 // GetTotalByStatus mocks base method.
-func (m *MockInvoiceService) GetTotalByStatus(arg0 context.Context, arg1 models.InvoiceStatus) (float64, int, error) {
+func (m *MockInvoiceService) GetTotalByStatus(arg0 context.Context, arg1 models.TotalsFilter) (*models.InvoiceSummary, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetTotalByStatus", arg0, arg1)
-	ret0, _ := ret[0].(float64)
-	ret1, _ := ret[1].(int)
-	ret2, _ := ret[2].(error)
-	return ret0, ret1, ret2
+	ret0, _ := ret[0].(*models.InvoiceSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // GetTotalByStatus indicates an expected call of GetTotalByStatus.
@@ -146,3 +164,34 @@ func (mr *MockInvoiceServiceMockRecorder) GetTotalByStatus(arg0, arg1 any) *gomo
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTotalByStatus", reflect.TypeOf((*MockInvoiceService)(nil).GetTotalByStatus), arg0, arg1)
 }
+
+// MarkOverdueInvoices mocks base method.
+func (m *MockInvoiceService) MarkOverdueInvoices(arg0 context.Context, arg1 time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOverdueInvoices", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkOverdueInvoices indicates an expected call of MarkOverdueInvoices.
+func (mr *MockInvoiceServiceMockRecorder) MarkOverdueInvoices(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOverdueInvoices", reflect.TypeOf((*MockInvoiceService)(nil).MarkOverdueInvoices), arg0, arg1)
+}
+
+// SearchInvoices mocks base method.
+func (m *MockInvoiceService) SearchInvoices(arg0 context.Context, arg1 models.InvoiceFilter, arg2 pagination.Request) ([]models.Invoice, *pagination.Meta, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchInvoices", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.Invoice)
+	ret1, _ := ret[1].(*pagination.Meta)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SearchInvoices indicates an expected call of SearchInvoices.
+func (mr *MockInvoiceServiceMockRecorder) SearchInvoices(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchInvoices", reflect.TypeOf((*MockInvoiceService)(nil).SearchInvoices), arg0, arg1, arg2)
+}
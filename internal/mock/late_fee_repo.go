@@ -0,0 +1,103 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zde37/Numeris-Task/internal/repository (interfaces: LateFeeRepository)
+//
+// Generated by this command:
+//
+//	mockgen -package mocked -destination internal/mock/late_fee_repo.go github.com/zde37/Numeris-Task/internal/repository LateFeeRepository
+//
+
+// Package mocked is a generated GoMock package.
+package mocked
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	models "github.com/zde37/Numeris-Task/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLateFeeRepository is a mock of LateFeeRepository interface.
+type MockLateFeeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLateFeeRepositoryMockRecorder
+}
+
+// MockLateFeeRepositoryMockRecorder is the mock recorder for MockLateFeeRepository.
+type MockLateFeeRepositoryMockRecorder struct {
+	mock *MockLateFeeRepository
+}
+
+// NewMockLateFeeRepository creates a new mock instance.
+func NewMockLateFeeRepository(ctrl *gomock.Controller) *MockLateFeeRepository {
+	mock := &MockLateFeeRepository{ctrl: ctrl}
+	mock.recorder = &MockLateFeeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLateFeeRepository) EXPECT() *MockLateFeeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ApplyLateFee mocks base method.
+func (m *MockLateFeeRepository) ApplyLateFee(arg0 context.Context, arg1 models.InvoiceAdjustment, arg2 models.InvoiceActivity) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyLateFee", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyLateFee indicates an expected call of ApplyLateFee.
+func (mr *MockLateFeeRepositoryMockRecorder) ApplyLateFee(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyLateFee", reflect.TypeOf((*MockLateFeeRepository)(nil).ApplyLateFee), arg0, arg1, arg2)
+}
+
+// GetLateFeeCandidates mocks base method.
+func (m *MockLateFeeRepository) GetLateFeeCandidates(arg0 context.Context, arg1 time.Time, arg2 uuid.UUID, arg3 int32) ([]models.LateFeeCandidate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLateFeeCandidates", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.LateFeeCandidate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLateFeeCandidates indicates an expected call of GetLateFeeCandidates.
+func (mr *MockLateFeeRepositoryMockRecorder) GetLateFeeCandidates(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLateFeeCandidates", reflect.TypeOf((*MockLateFeeRepository)(nil).GetLateFeeCandidates), arg0, arg1, arg2, arg3)
+}
+
+// GetLateFeePolicy mocks base method.
+func (m *MockLateFeeRepository) GetLateFeePolicy(arg0 context.Context, arg1 uuid.UUID) (*models.LateFeePolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLateFeePolicy", arg0, arg1)
+	ret0, _ := ret[0].(*models.LateFeePolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLateFeePolicy indicates an expected call of GetLateFeePolicy.
+func (mr *MockLateFeeRepositoryMockRecorder) GetLateFeePolicy(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLateFeePolicy", reflect.TypeOf((*MockLateFeeRepository)(nil).GetLateFeePolicy), arg0, arg1)
+}
+
+// SetLateFeePolicy mocks base method.
+func (m *MockLateFeeRepository) SetLateFeePolicy(arg0 context.Context, arg1 models.LateFeePolicy) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLateFeePolicy", arg0, arg1)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetLateFeePolicy indicates an expected call of SetLateFeePolicy.
+func (mr *MockLateFeeRepositoryMockRecorder) SetLateFeePolicy(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLateFeePolicy", reflect.TypeOf((*MockLateFeeRepository)(nil).SetLateFeePolicy), arg0, arg1)
+}
@@ -0,0 +1,88 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zde37/Numeris-Task/internal/service (interfaces: LateFeeService)
+//
+// Generated by this command:
+//
+//	mockgen -package mocked -destination internal/mock/late_fee_service.go github.com/zde37/Numeris-Task/internal/service LateFeeService
+//
+
+// Package mocked is a generated GoMock package.
+package mocked
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	models "github.com/zde37/Numeris-Task/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLateFeeService is a mock of LateFeeService interface.
+type MockLateFeeService struct {
+	ctrl     *gomock.Controller
+	recorder *MockLateFeeServiceMockRecorder
+}
+
+// MockLateFeeServiceMockRecorder is the mock recorder for MockLateFeeService.
+type MockLateFeeServiceMockRecorder struct {
+	mock *MockLateFeeService
+}
+
+// NewMockLateFeeService creates a new mock instance.
+func NewMockLateFeeService(ctrl *gomock.Controller) *MockLateFeeService {
+	mock := &MockLateFeeService{ctrl: ctrl}
+	mock.recorder = &MockLateFeeServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLateFeeService) EXPECT() *MockLateFeeServiceMockRecorder {
+	return m.recorder
+}
+
+// ApplyLateFees mocks base method.
+func (m *MockLateFeeService) ApplyLateFees(arg0 context.Context, arg1 time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyLateFees", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyLateFees indicates an expected call of ApplyLateFees.
+func (mr *MockLateFeeServiceMockRecorder) ApplyLateFees(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyLateFees", reflect.TypeOf((*MockLateFeeService)(nil).ApplyLateFees), arg0, arg1)
+}
+
+// GetLateFeePolicy mocks base method.
+func (m *MockLateFeeService) GetLateFeePolicy(arg0 context.Context, arg1 uuid.UUID) (*models.LateFeePolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLateFeePolicy", arg0, arg1)
+	ret0, _ := ret[0].(*models.LateFeePolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLateFeePolicy indicates an expected call of GetLateFeePolicy.
+func (mr *MockLateFeeServiceMockRecorder) GetLateFeePolicy(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLateFeePolicy", reflect.TypeOf((*MockLateFeeService)(nil).GetLateFeePolicy), arg0, arg1)
+}
+
+// SetLateFeePolicy mocks base method.
+func (m *MockLateFeeService) SetLateFeePolicy(arg0 context.Context, arg1 models.SetLateFeePolicyRequest) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLateFeePolicy", arg0, arg1)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetLateFeePolicy indicates an expected call of SetLateFeePolicy.
+func (mr *MockLateFeeServiceMockRecorder) SetLateFeePolicy(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLateFeePolicy", reflect.TypeOf((*MockLateFeeService)(nil).SetLateFeePolicy), arg0, arg1)
+}
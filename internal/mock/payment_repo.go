@@ -0,0 +1,191 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zde37/Numeris-Task/internal/repository (interfaces: PaymentRepository)
+//
+// Generated by this command:
+//
+//	mockgen -package mocked -destination internal/mock/payment_repo.go github.com/zde37/Numeris-Task/internal/repository PaymentRepository
+//
+
+// Package mocked is a generated GoMock package.
+package mocked
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	models "github.com/zde37/Numeris-Task/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPaymentRepository is a mock of PaymentRepository interface.
+type MockPaymentRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentRepositoryMockRecorder
+}
+
+// MockPaymentRepositoryMockRecorder is the mock recorder for MockPaymentRepository.
+type MockPaymentRepositoryMockRecorder struct {
+	mock *MockPaymentRepository
+}
+
+// NewMockPaymentRepository creates a new mock instance.
+func NewMockPaymentRepository(ctrl *gomock.Controller) *MockPaymentRepository {
+	mock := &MockPaymentRepository{ctrl: ctrl}
+	mock.recorder = &MockPaymentRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPaymentRepository) EXPECT() *MockPaymentRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimPendingPaymentRefunds mocks base method.
+func (m *MockPaymentRepository) ClaimPendingPaymentRefunds(arg0 context.Context, arg1 time.Time, arg2 time.Duration, arg3 int32) ([]models.PaymentRefund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimPendingPaymentRefunds", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.PaymentRefund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimPendingPaymentRefunds indicates an expected call of ClaimPendingPaymentRefunds.
+func (mr *MockPaymentRepositoryMockRecorder) ClaimPendingPaymentRefunds(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimPendingPaymentRefunds", reflect.TypeOf((*MockPaymentRepository)(nil).ClaimPendingPaymentRefunds), arg0, arg1, arg2, arg3)
+}
+
+// CompletePaymentRefund mocks base method.
+func (m *MockPaymentRepository) CompletePaymentRefund(arg0 context.Context, arg1 uuid.UUID, arg2 string) (*models.PaymentRefund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompletePaymentRefund", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.PaymentRefund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompletePaymentRefund indicates an expected call of CompletePaymentRefund.
+func (mr *MockPaymentRepositoryMockRecorder) CompletePaymentRefund(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompletePaymentRefund", reflect.TypeOf((*MockPaymentRepository)(nil).CompletePaymentRefund), arg0, arg1, arg2)
+}
+
+// CompletePaymentSession mocks base method.
+func (m *MockPaymentRepository) CompletePaymentSession(arg0 context.Context, arg1 uuid.UUID, arg2 models.Payment) (*models.PaymentSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompletePaymentSession", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.PaymentSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompletePaymentSession indicates an expected call of CompletePaymentSession.
+func (mr *MockPaymentRepositoryMockRecorder) CompletePaymentSession(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompletePaymentSession", reflect.TypeOf((*MockPaymentRepository)(nil).CompletePaymentSession), arg0, arg1, arg2)
+}
+
+// CreatePaymentRefund mocks base method.
+func (m *MockPaymentRepository) CreatePaymentRefund(arg0 context.Context, arg1, arg2 uuid.UUID, arg3 float64, arg4 string, arg5 time.Time) (*models.PaymentRefund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePaymentRefund", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(*models.PaymentRefund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePaymentRefund indicates an expected call of CreatePaymentRefund.
+func (mr *MockPaymentRepositoryMockRecorder) CreatePaymentRefund(arg0, arg1, arg2, arg3, arg4, arg5 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePaymentRefund", reflect.TypeOf((*MockPaymentRepository)(nil).CreatePaymentRefund), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// CreatePaymentSession mocks base method.
+func (m *MockPaymentRepository) CreatePaymentSession(arg0 context.Context, arg1 models.PaymentSession) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePaymentSession", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePaymentSession indicates an expected call of CreatePaymentSession.
+func (mr *MockPaymentRepositoryMockRecorder) CreatePaymentSession(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePaymentSession", reflect.TypeOf((*MockPaymentRepository)(nil).CreatePaymentSession), arg0, arg1)
+}
+
+// FailPaymentRefund mocks base method.
+func (m *MockPaymentRepository) FailPaymentRefund(arg0 context.Context, arg1 uuid.UUID, arg2 string) (*models.PaymentRefund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailPaymentRefund", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.PaymentRefund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FailPaymentRefund indicates an expected call of FailPaymentRefund.
+func (mr *MockPaymentRepositoryMockRecorder) FailPaymentRefund(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailPaymentRefund", reflect.TypeOf((*MockPaymentRepository)(nil).FailPaymentRefund), arg0, arg1, arg2)
+}
+
+// FailPaymentSession mocks base method.
+func (m *MockPaymentRepository) FailPaymentSession(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailPaymentSession", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailPaymentSession indicates an expected call of FailPaymentSession.
+func (mr *MockPaymentRepositoryMockRecorder) FailPaymentSession(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailPaymentSession", reflect.TypeOf((*MockPaymentRepository)(nil).FailPaymentSession), arg0, arg1)
+}
+
+// GetPayableInvoice mocks base method.
+func (m *MockPaymentRepository) GetPayableInvoice(arg0 context.Context, arg1, arg2 uuid.UUID) (*models.PayableInvoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPayableInvoice", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.PayableInvoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPayableInvoice indicates an expected call of GetPayableInvoice.
+func (mr *MockPaymentRepositoryMockRecorder) GetPayableInvoice(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPayableInvoice", reflect.TypeOf((*MockPaymentRepository)(nil).GetPayableInvoice), arg0, arg1, arg2)
+}
+
+// GetPaymentSession mocks base method.
+func (m *MockPaymentRepository) GetPaymentSession(arg0 context.Context, arg1, arg2 string) (*models.PaymentSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentSession", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.PaymentSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentSession indicates an expected call of GetPaymentSession.
+func (mr *MockPaymentRepositoryMockRecorder) GetPaymentSession(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentSession", reflect.TypeOf((*MockPaymentRepository)(nil).GetPaymentSession), arg0, arg1, arg2)
+}
+
+// RecordPayment mocks base method.
+func (m *MockPaymentRepository) RecordPayment(arg0 context.Context, arg1 uuid.UUID, arg2 models.Payment) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordPayment", arg0, arg1, arg2)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordPayment indicates an expected call of RecordPayment.
+func (mr *MockPaymentRepositoryMockRecorder) RecordPayment(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordPayment", reflect.TypeOf((*MockPaymentRepository)(nil).RecordPayment), arg0, arg1, arg2)
+}
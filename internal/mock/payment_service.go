@@ -0,0 +1,118 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zde37/Numeris-Task/internal/service (interfaces: PaymentService)
+//
+// Generated by this command:
+//
+//	mockgen -package mocked -destination internal/mock/payment_service.go github.com/zde37/Numeris-Task/internal/service PaymentService
+//
+
+// Package mocked is a generated GoMock package.
+package mocked
+
+import (
+	context "context"
+	http "net/http"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	models "github.com/zde37/Numeris-Task/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPaymentService is a mock of PaymentService interface.
+type MockPaymentService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentServiceMockRecorder
+}
+
+// MockPaymentServiceMockRecorder is the mock recorder for MockPaymentService.
+type MockPaymentServiceMockRecorder struct {
+	mock *MockPaymentService
+}
+
+// NewMockPaymentService creates a new mock instance.
+func NewMockPaymentService(ctrl *gomock.Controller) *MockPaymentService {
+	mock := &MockPaymentService{ctrl: ctrl}
+	mock.recorder = &MockPaymentServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPaymentService) EXPECT() *MockPaymentServiceMockRecorder {
+	return m.recorder
+}
+
+// CreatePaymentLink mocks base method.
+func (m *MockPaymentService) CreatePaymentLink(arg0 context.Context, arg1, arg2 uuid.UUID) (*models.PaymentSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePaymentLink", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.PaymentSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePaymentLink indicates an expected call of CreatePaymentLink.
+func (mr *MockPaymentServiceMockRecorder) CreatePaymentLink(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePaymentLink", reflect.TypeOf((*MockPaymentService)(nil).CreatePaymentLink), arg0, arg1, arg2)
+}
+
+// HandlePaymentCallback mocks base method.
+func (m *MockPaymentService) HandlePaymentCallback(arg0 context.Context, arg1 []byte, arg2 http.Header) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HandlePaymentCallback", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HandlePaymentCallback indicates an expected call of HandlePaymentCallback.
+func (mr *MockPaymentServiceMockRecorder) HandlePaymentCallback(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandlePaymentCallback", reflect.TypeOf((*MockPaymentService)(nil).HandlePaymentCallback), arg0, arg1, arg2)
+}
+
+// RecordPayment mocks base method.
+func (m *MockPaymentService) RecordPayment(arg0 context.Context, arg1 models.RecordPaymentRequest) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordPayment", arg0, arg1)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordPayment indicates an expected call of RecordPayment.
+func (mr *MockPaymentServiceMockRecorder) RecordPayment(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordPayment", reflect.TypeOf((*MockPaymentService)(nil).RecordPayment), arg0, arg1)
+}
+
+// RefundPayment mocks base method.
+func (m *MockPaymentService) RefundPayment(arg0 context.Context, arg1, arg2 uuid.UUID, arg3 float64) (*models.PaymentRefund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefundPayment", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.PaymentRefund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefundPayment indicates an expected call of RefundPayment.
+func (mr *MockPaymentServiceMockRecorder) RefundPayment(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefundPayment", reflect.TypeOf((*MockPaymentService)(nil).RefundPayment), arg0, arg1, arg2, arg3)
+}
+
+// SendPendingRefunds mocks base method.
+func (m *MockPaymentService) SendPendingRefunds(arg0 context.Context, arg1 time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendPendingRefunds", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendPendingRefunds indicates an expected call of SendPendingRefunds.
+func (mr *MockPaymentServiceMockRecorder) SendPendingRefunds(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPendingRefunds", reflect.TypeOf((*MockPaymentService)(nil).SendPendingRefunds), arg0, arg1)
+}
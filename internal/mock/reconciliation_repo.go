@@ -0,0 +1,133 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zde37/Numeris-Task/internal/repository (interfaces: ReconciliationRepository)
+//
+// Generated by this command:
+//
+//	mockgen -package mocked -destination internal/mock/reconciliation_repo.go github.com/zde37/Numeris-Task/internal/repository ReconciliationRepository
+//
+
+// Package mocked is a generated GoMock package.
+package mocked
+
+import (
+	context "context"
+	reflect "reflect"
+
+	uuid "github.com/google/uuid"
+	models "github.com/zde37/Numeris-Task/internal/models"
+	pagination "github.com/zde37/Numeris-Task/internal/pagination"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReconciliationRepository is a mock of ReconciliationRepository interface.
+type MockReconciliationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReconciliationRepositoryMockRecorder
+}
+
+// MockReconciliationRepositoryMockRecorder is the mock recorder for MockReconciliationRepository.
+type MockReconciliationRepositoryMockRecorder struct {
+	mock *MockReconciliationRepository
+}
+
+// NewMockReconciliationRepository creates a new mock instance.
+func NewMockReconciliationRepository(ctrl *gomock.Controller) *MockReconciliationRepository {
+	mock := &MockReconciliationRepository{ctrl: ctrl}
+	mock.recorder = &MockReconciliationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReconciliationRepository) EXPECT() *MockReconciliationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddBankTransaction mocks base method.
+func (m *MockReconciliationRepository) AddBankTransaction(arg0 context.Context, arg1 models.BankTransaction, arg2 *models.Payment) (*models.BankTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddBankTransaction", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.BankTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddBankTransaction indicates an expected call of AddBankTransaction.
+func (mr *MockReconciliationRepositoryMockRecorder) AddBankTransaction(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBankTransaction", reflect.TypeOf((*MockReconciliationRepository)(nil).AddBankTransaction), arg0, arg1, arg2)
+}
+
+// ConfirmBankTransaction mocks base method.
+func (m *MockReconciliationRepository) ConfirmBankTransaction(arg0 context.Context, arg1, arg2 uuid.UUID, arg3 models.Payment) (*models.BankTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmBankTransaction", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.BankTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmBankTransaction indicates an expected call of ConfirmBankTransaction.
+func (mr *MockReconciliationRepositoryMockRecorder) ConfirmBankTransaction(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmBankTransaction", reflect.TypeOf((*MockReconciliationRepository)(nil).ConfirmBankTransaction), arg0, arg1, arg2, arg3)
+}
+
+// DismissBankTransaction mocks base method.
+func (m *MockReconciliationRepository) DismissBankTransaction(arg0 context.Context, arg1, arg2 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DismissBankTransaction", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DismissBankTransaction indicates an expected call of DismissBankTransaction.
+func (mr *MockReconciliationRepositoryMockRecorder) DismissBankTransaction(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DismissBankTransaction", reflect.TypeOf((*MockReconciliationRepository)(nil).DismissBankTransaction), arg0, arg1, arg2)
+}
+
+// GetBankTransaction mocks base method.
+func (m *MockReconciliationRepository) GetBankTransaction(arg0 context.Context, arg1, arg2 uuid.UUID) (*models.BankTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBankTransaction", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.BankTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBankTransaction indicates an expected call of GetBankTransaction.
+func (mr *MockReconciliationRepositoryMockRecorder) GetBankTransaction(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBankTransaction", reflect.TypeOf((*MockReconciliationRepository)(nil).GetBankTransaction), arg0, arg1, arg2)
+}
+
+// GetBankTransactions mocks base method.
+func (m *MockReconciliationRepository) GetBankTransactions(arg0 context.Context, arg1 uuid.UUID, arg2 models.BankTransactionStatus, arg3 pagination.Request) ([]models.BankTransaction, *pagination.Meta, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBankTransactions", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.BankTransaction)
+	ret1, _ := ret[1].(*pagination.Meta)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetBankTransactions indicates an expected call of GetBankTransactions.
+func (mr *MockReconciliationRepositoryMockRecorder) GetBankTransactions(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBankTransactions", reflect.TypeOf((*MockReconciliationRepository)(nil).GetBankTransactions), arg0, arg1, arg2, arg3)
+}
+
+// GetUnpaidInvoices mocks base method.
+func (m *MockReconciliationRepository) GetUnpaidInvoices(arg0 context.Context, arg1 uuid.UUID) ([]models.PayableInvoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnpaidInvoices", arg0, arg1)
+	ret0, _ := ret[0].([]models.PayableInvoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnpaidInvoices indicates an expected call of GetUnpaidInvoices.
+func (mr *MockReconciliationRepositoryMockRecorder) GetUnpaidInvoices(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnpaidInvoices", reflect.TypeOf((*MockReconciliationRepository)(nil).GetUnpaidInvoices), arg0, arg1)
+}
@@ -0,0 +1,104 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zde37/Numeris-Task/internal/service (interfaces: ReconciliationService)
+//
+// Generated by this command:
+//
+//	mockgen -package mocked -destination internal/mock/reconciliation_service.go github.com/zde37/Numeris-Task/internal/service ReconciliationService
+//
+
+// Package mocked is a generated GoMock package.
+package mocked
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+
+	uuid "github.com/google/uuid"
+	models "github.com/zde37/Numeris-Task/internal/models"
+	pagination "github.com/zde37/Numeris-Task/internal/pagination"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReconciliationService is a mock of ReconciliationService interface.
+type MockReconciliationService struct {
+	ctrl     *gomock.Controller
+	recorder *MockReconciliationServiceMockRecorder
+}
+
+// MockReconciliationServiceMockRecorder is the mock recorder for MockReconciliationService.
+type MockReconciliationServiceMockRecorder struct {
+	mock *MockReconciliationService
+}
+
+// NewMockReconciliationService creates a new mock instance.
+func NewMockReconciliationService(ctrl *gomock.Controller) *MockReconciliationService {
+	mock := &MockReconciliationService{ctrl: ctrl}
+	mock.recorder = &MockReconciliationServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReconciliationService) EXPECT() *MockReconciliationServiceMockRecorder {
+	return m.recorder
+}
+
+// ConfirmBankTransaction mocks base method.
+func (m *MockReconciliationService) ConfirmBankTransaction(arg0 context.Context, arg1, arg2 uuid.UUID, arg3 models.ConfirmBankTransactionRequest) (*models.BankTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmBankTransaction", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.BankTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmBankTransaction indicates an expected call of ConfirmBankTransaction.
+func (mr *MockReconciliationServiceMockRecorder) ConfirmBankTransaction(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmBankTransaction", reflect.TypeOf((*MockReconciliationService)(nil).ConfirmBankTransaction), arg0, arg1, arg2, arg3)
+}
+
+// DismissBankTransaction mocks base method.
+func (m *MockReconciliationService) DismissBankTransaction(arg0 context.Context, arg1, arg2 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DismissBankTransaction", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DismissBankTransaction indicates an expected call of DismissBankTransaction.
+func (mr *MockReconciliationServiceMockRecorder) DismissBankTransaction(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DismissBankTransaction", reflect.TypeOf((*MockReconciliationService)(nil).DismissBankTransaction), arg0, arg1, arg2)
+}
+
+// GetReconciliationInbox mocks base method.
+func (m *MockReconciliationService) GetReconciliationInbox(arg0 context.Context, arg1 uuid.UUID, arg2 pagination.Request) ([]models.BankTransaction, *pagination.Meta, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReconciliationInbox", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.BankTransaction)
+	ret1, _ := ret[1].(*pagination.Meta)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetReconciliationInbox indicates an expected call of GetReconciliationInbox.
+func (mr *MockReconciliationServiceMockRecorder) GetReconciliationInbox(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReconciliationInbox", reflect.TypeOf((*MockReconciliationService)(nil).GetReconciliationInbox), arg0, arg1, arg2)
+}
+
+// ImportStatement mocks base method.
+func (m *MockReconciliationService) ImportStatement(arg0 context.Context, arg1 uuid.UUID, arg2 string, arg3 io.Reader) (*models.ReconciliationResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportStatement", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.ReconciliationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportStatement indicates an expected call of ImportStatement.
+func (mr *MockReconciliationServiceMockRecorder) ImportStatement(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportStatement", reflect.TypeOf((*MockReconciliationService)(nil).ImportStatement), arg0, arg1, arg2, arg3)
+}
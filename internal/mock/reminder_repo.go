@@ -0,0 +1,130 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zde37/Numeris-Task/internal/repository (interfaces: ReminderRepository)
+//
+// Generated by this command:
+//
+//	mockgen -package mocked -destination internal/mock/reminder_repo.go github.com/zde37/Numeris-Task/internal/repository ReminderRepository
+//
+
+// Package mocked is a generated GoMock package.
+package mocked
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	models "github.com/zde37/Numeris-Task/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReminderRepository is a mock of ReminderRepository interface.
+type MockReminderRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReminderRepositoryMockRecorder
+}
+
+// MockReminderRepositoryMockRecorder is the mock recorder for MockReminderRepository.
+type MockReminderRepositoryMockRecorder struct {
+	mock *MockReminderRepository
+}
+
+// NewMockReminderRepository creates a new mock instance.
+func NewMockReminderRepository(ctrl *gomock.Controller) *MockReminderRepository {
+	mock := &MockReminderRepository{ctrl: ctrl}
+	mock.recorder = &MockReminderRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReminderRepository) EXPECT() *MockReminderRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddReminderRule mocks base method.
+func (m *MockReminderRepository) AddReminderRule(arg0 context.Context, arg1 models.ReminderRule) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddReminderRule", arg0, arg1)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddReminderRule indicates an expected call of AddReminderRule.
+func (mr *MockReminderRepositoryMockRecorder) AddReminderRule(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddReminderRule", reflect.TypeOf((*MockReminderRepository)(nil).AddReminderRule), arg0, arg1)
+}
+
+// ClaimDueReminders mocks base method.
+func (m *MockReminderRepository) ClaimDueReminders(arg0 context.Context, arg1 time.Time, arg2 time.Duration, arg3 int32) ([]models.DueReminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueReminders", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.DueReminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueReminders indicates an expected call of ClaimDueReminders.
+func (mr *MockReminderRepositoryMockRecorder) ClaimDueReminders(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueReminders", reflect.TypeOf((*MockReminderRepository)(nil).ClaimDueReminders), arg0, arg1, arg2, arg3)
+}
+
+// DeleteReminderRule mocks base method.
+func (m *MockReminderRepository) DeleteReminderRule(arg0 context.Context, arg1, arg2 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteReminderRule", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteReminderRule indicates an expected call of DeleteReminderRule.
+func (mr *MockReminderRepositoryMockRecorder) DeleteReminderRule(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReminderRule", reflect.TypeOf((*MockReminderRepository)(nil).DeleteReminderRule), arg0, arg1, arg2)
+}
+
+// GetReminderRules mocks base method.
+func (m *MockReminderRepository) GetReminderRules(arg0 context.Context, arg1 uuid.UUID) ([]models.ReminderRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReminderRules", arg0, arg1)
+	ret0, _ := ret[0].([]models.ReminderRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReminderRules indicates an expected call of GetReminderRules.
+func (mr *MockReminderRepositoryMockRecorder) GetReminderRules(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReminderRules", reflect.TypeOf((*MockReminderRepository)(nil).GetReminderRules), arg0, arg1)
+}
+
+// RecordReminder mocks base method.
+func (m *MockReminderRepository) RecordReminder(arg0 context.Context, arg1 models.InvoiceReminder, arg2 models.InvoiceActivity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordReminder", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordReminder indicates an expected call of RecordReminder.
+func (mr *MockReminderRepositoryMockRecorder) RecordReminder(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordReminder", reflect.TypeOf((*MockReminderRepository)(nil).RecordReminder), arg0, arg1, arg2)
+}
+
+// RecordReminderFailure mocks base method.
+func (m *MockReminderRepository) RecordReminderFailure(arg0 context.Context, arg1 uuid.UUID, arg2 string, arg3 *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordReminderFailure", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordReminderFailure indicates an expected call of RecordReminderFailure.
+func (mr *MockReminderRepositoryMockRecorder) RecordReminderFailure(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordReminderFailure", reflect.TypeOf((*MockReminderRepository)(nil).RecordReminderFailure), arg0, arg1, arg2, arg3)
+}
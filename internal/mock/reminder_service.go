@@ -0,0 +1,102 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zde37/Numeris-Task/internal/service (interfaces: ReminderService)
+//
+// Generated by this command:
+//
+//	mockgen -package mocked -destination internal/mock/reminder_service.go github.com/zde37/Numeris-Task/internal/service ReminderService
+//
+
+// Package mocked is a generated GoMock package.
+package mocked
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	models "github.com/zde37/Numeris-Task/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReminderService is a mock of ReminderService interface.
+type MockReminderService struct {
+	ctrl     *gomock.Controller
+	recorder *MockReminderServiceMockRecorder
+}
+
+// MockReminderServiceMockRecorder is the mock recorder for MockReminderService.
+type MockReminderServiceMockRecorder struct {
+	mock *MockReminderService
+}
+
+// NewMockReminderService creates a new mock instance.
+func NewMockReminderService(ctrl *gomock.Controller) *MockReminderService {
+	mock := &MockReminderService{ctrl: ctrl}
+	mock.recorder = &MockReminderServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReminderService) EXPECT() *MockReminderServiceMockRecorder {
+	return m.recorder
+}
+
+// AddReminderRule mocks base method.
+func (m *MockReminderService) AddReminderRule(arg0 context.Context, arg1 models.AddReminderRuleRequest) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddReminderRule", arg0, arg1)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddReminderRule indicates an expected call of AddReminderRule.
+func (mr *MockReminderServiceMockRecorder) AddReminderRule(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddReminderRule", reflect.TypeOf((*MockReminderService)(nil).AddReminderRule), arg0, arg1)
+}
+
+// DeleteReminderRule mocks base method.
+func (m *MockReminderService) DeleteReminderRule(arg0 context.Context, arg1, arg2 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteReminderRule", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteReminderRule indicates an expected call of DeleteReminderRule.
+func (mr *MockReminderServiceMockRecorder) DeleteReminderRule(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReminderRule", reflect.TypeOf((*MockReminderService)(nil).DeleteReminderRule), arg0, arg1, arg2)
+}
+
+// GetReminderRules mocks base method.
+func (m *MockReminderService) GetReminderRules(arg0 context.Context, arg1 uuid.UUID) ([]models.ReminderRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReminderRules", arg0, arg1)
+	ret0, _ := ret[0].([]models.ReminderRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReminderRules indicates an expected call of GetReminderRules.
+func (mr *MockReminderServiceMockRecorder) GetReminderRules(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReminderRules", reflect.TypeOf((*MockReminderService)(nil).GetReminderRules), arg0, arg1)
+}
+
+// SendDueReminders mocks base method.
+func (m *MockReminderService) SendDueReminders(arg0 context.Context, arg1 time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDueReminders", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendDueReminders indicates an expected call of SendDueReminders.
+func (mr *MockReminderServiceMockRecorder) SendDueReminders(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDueReminders", reflect.TypeOf((*MockReminderService)(nil).SendDueReminders), arg0, arg1)
+}
@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zde37/Numeris-Task/internal/service (interfaces: ReportService)
+//
+// Generated by this command:
+//
+//	mockgen -package mocked -destination internal/mock/report_service.go github.com/zde37/Numeris-Task/internal/service ReportService
+//
+
+// Package mocked is a generated GoMock package.
+package mocked
+
+import (
+	context "context"
+	reflect "reflect"
+
+	models "github.com/zde37/Numeris-Task/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReportService is a mock of ReportService interface.
+type MockReportService struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportServiceMockRecorder
+}
+
+// MockReportServiceMockRecorder is the mock recorder for MockReportService.
+type MockReportServiceMockRecorder struct {
+	mock *MockReportService
+}
+
+// NewMockReportService creates a new mock instance.
+func NewMockReportService(ctrl *gomock.Controller) *MockReportService {
+	mock := &MockReportService{ctrl: ctrl}
+	mock.recorder = &MockReportServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReportService) EXPECT() *MockReportServiceMockRecorder {
+	return m.recorder
+}
+
+// GetSeriesReport mocks base method.
+func (m *MockReportService) GetSeriesReport(arg0 context.Context, arg1 models.SeriesFilter) (*models.SeriesReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSeriesReport", arg0, arg1)
+	ret0, _ := ret[0].(*models.SeriesReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSeriesReport indicates an expected call of GetSeriesReport.
+func (mr *MockReportServiceMockRecorder) GetSeriesReport(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSeriesReport", reflect.TypeOf((*MockReportService)(nil).GetSeriesReport), arg0, arg1)
+}
@@ -0,0 +1,102 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zde37/Numeris-Task/internal/repository (interfaces: TaxRepository)
+//
+// Generated by this command:
+//
+//	mockgen -package mocked -destination internal/mock/tax_repo.go github.com/zde37/Numeris-Task/internal/repository TaxRepository
+//
+
+// Package mocked is a generated GoMock package.
+package mocked
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	models "github.com/zde37/Numeris-Task/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTaxRepository is a mock of TaxRepository interface.
+type MockTaxRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaxRepositoryMockRecorder
+}
+
+// MockTaxRepositoryMockRecorder is the mock recorder for MockTaxRepository.
+type MockTaxRepositoryMockRecorder struct {
+	mock *MockTaxRepository
+}
+
+// NewMockTaxRepository creates a new mock instance.
+func NewMockTaxRepository(ctrl *gomock.Controller) *MockTaxRepository {
+	mock := &MockTaxRepository{ctrl: ctrl}
+	mock.recorder = &MockTaxRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaxRepository) EXPECT() *MockTaxRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateTaxRate mocks base method.
+func (m *MockTaxRepository) CreateTaxRate(arg0 context.Context, arg1 models.TaxRate) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTaxRate", arg0, arg1)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTaxRate indicates an expected call of CreateTaxRate.
+func (mr *MockTaxRepositoryMockRecorder) CreateTaxRate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTaxRate", reflect.TypeOf((*MockTaxRepository)(nil).CreateTaxRate), arg0, arg1)
+}
+
+// DeactivateTaxRate mocks base method.
+func (m *MockTaxRepository) DeactivateTaxRate(arg0 context.Context, arg1, arg2 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateTaxRate", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeactivateTaxRate indicates an expected call of DeactivateTaxRate.
+func (mr *MockTaxRepositoryMockRecorder) DeactivateTaxRate(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateTaxRate", reflect.TypeOf((*MockTaxRepository)(nil).DeactivateTaxRate), arg0, arg1, arg2)
+}
+
+// GetTaxRates mocks base method.
+func (m *MockTaxRepository) GetTaxRates(arg0 context.Context, arg1 uuid.UUID) ([]models.TaxRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaxRates", arg0, arg1)
+	ret0, _ := ret[0].([]models.TaxRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaxRates indicates an expected call of GetTaxRates.
+func (mr *MockTaxRepositoryMockRecorder) GetTaxRates(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaxRates", reflect.TypeOf((*MockTaxRepository)(nil).GetTaxRates), arg0, arg1)
+}
+
+// GetTaxReport mocks base method.
+func (m *MockTaxRepository) GetTaxReport(arg0 context.Context, arg1 uuid.UUID, arg2, arg3 time.Time, arg4 models.TaxReportBasis) ([]models.TaxReportLine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaxReport", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]models.TaxReportLine)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaxReport indicates an expected call of GetTaxReport.
+func (mr *MockTaxRepositoryMockRecorder) GetTaxReport(arg0, arg1, arg2, arg3, arg4 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaxReport", reflect.TypeOf((*MockTaxRepository)(nil).GetTaxReport), arg0, arg1, arg2, arg3, arg4)
+}
@@ -0,0 +1,102 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/zde37/Numeris-Task/internal/service (interfaces: TaxService)
+//
+// Generated by this command:
+//
+//	mockgen -package mocked -destination internal/mock/tax_service.go github.com/zde37/Numeris-Task/internal/service TaxService
+//
+
+// Package mocked is a generated GoMock package.
+package mocked
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	models "github.com/zde37/Numeris-Task/internal/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTaxService is a mock of TaxService interface.
+type MockTaxService struct {
+	ctrl     *gomock.Controller
+	recorder *MockTaxServiceMockRecorder
+}
+
+// MockTaxServiceMockRecorder is the mock recorder for MockTaxService.
+type MockTaxServiceMockRecorder struct {
+	mock *MockTaxService
+}
+
+// NewMockTaxService creates a new mock instance.
+func NewMockTaxService(ctrl *gomock.Controller) *MockTaxService {
+	mock := &MockTaxService{ctrl: ctrl}
+	mock.recorder = &MockTaxServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTaxService) EXPECT() *MockTaxServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateTaxRate mocks base method.
+func (m *MockTaxService) CreateTaxRate(arg0 context.Context, arg1 models.CreateTaxRateRequest) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTaxRate", arg0, arg1)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTaxRate indicates an expected call of CreateTaxRate.
+func (mr *MockTaxServiceMockRecorder) CreateTaxRate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTaxRate", reflect.TypeOf((*MockTaxService)(nil).CreateTaxRate), arg0, arg1)
+}
+
+// DeactivateTaxRate mocks base method.
+func (m *MockTaxService) DeactivateTaxRate(arg0 context.Context, arg1, arg2 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateTaxRate", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeactivateTaxRate indicates an expected call of DeactivateTaxRate.
+func (mr *MockTaxServiceMockRecorder) DeactivateTaxRate(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateTaxRate", reflect.TypeOf((*MockTaxService)(nil).DeactivateTaxRate), arg0, arg1, arg2)
+}
+
+// GetTaxRates mocks base method.
+func (m *MockTaxService) GetTaxRates(arg0 context.Context, arg1 uuid.UUID) ([]models.TaxRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaxRates", arg0, arg1)
+	ret0, _ := ret[0].([]models.TaxRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaxRates indicates an expected call of GetTaxRates.
+func (mr *MockTaxServiceMockRecorder) GetTaxRates(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaxRates", reflect.TypeOf((*MockTaxService)(nil).GetTaxRates), arg0, arg1)
+}
+
+// GetTaxReport mocks base method.
+func (m *MockTaxService) GetTaxReport(arg0 context.Context, arg1 uuid.UUID, arg2, arg3 time.Time, arg4 models.TaxReportBasis) (*models.TaxReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaxReport", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*models.TaxReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaxReport indicates an expected call of GetTaxReport.
+func (mr *MockTaxServiceMockRecorder) GetTaxReport(arg0, arg1, arg2, arg3, arg4 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaxReport", reflect.TypeOf((*MockTaxService)(nil).GetTaxReport), arg0, arg1, arg2, arg3, arg4)
+}
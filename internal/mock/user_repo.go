@@ -56,6 +56,20 @@ func (mr *MockUserRepositoryMockRecorder) AddCustomer(arg0, arg1 any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCustomer", reflect.TypeOf((*MockUserRepository)(nil).AddCustomer), arg0, arg1)
 }
 
+// AddCustomers mocks base method.
+func (m *MockUserRepository) AddCustomers(arg0 context.Context, arg1 []models.Customer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCustomers", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddCustomers indicates an expected call of AddCustomers.
+func (mr *MockUserRepositoryMockRecorder) AddCustomers(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCustomers", reflect.TypeOf((*MockUserRepository)(nil).AddCustomers), arg0, arg1)
+}
+
 // AddPaymentMethod mocks base method.
 func (m *MockUserRepository) AddPaymentMethod(arg0 context.Context, arg1 models.UserPaymentMethod) (uuid.UUID, error) {
 	m.ctrl.T.Helper()
@@ -85,3 +99,106 @@ func (mr *MockUserRepositoryMockRecorder) CreateUser(arg0, arg1 any) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserRepository)(nil).CreateUser), arg0, arg1)
 }
+
+// DeletePaymentMethod mocks base method.
+func (m *MockUserRepository) DeletePaymentMethod(arg0 context.Context, arg1, arg2 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePaymentMethod", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePaymentMethod indicates an expected call of DeletePaymentMethod.
+func (mr *MockUserRepositoryMockRecorder) DeletePaymentMethod(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePaymentMethod", reflect.TypeOf((*MockUserRepository)(nil).DeletePaymentMethod), arg0, arg1, arg2)
+}
+
+// GetDefaultPaymentMethod mocks base method.
+func (m *MockUserRepository) GetDefaultPaymentMethod(arg0 context.Context, arg1 uuid.UUID) (*models.UserPaymentMethod, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDefaultPaymentMethod", arg0, arg1)
+	ret0, _ := ret[0].(*models.UserPaymentMethod)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDefaultPaymentMethod indicates an expected call of GetDefaultPaymentMethod.
+func (mr *MockUserRepositoryMockRecorder) GetDefaultPaymentMethod(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDefaultPaymentMethod", reflect.TypeOf((*MockUserRepository)(nil).GetDefaultPaymentMethod), arg0, arg1)
+}
+
+// GetOwnedCustomerIDs mocks base method.
+func (m *MockUserRepository) GetOwnedCustomerIDs(arg0 context.Context, arg1 uuid.UUID, arg2 []uuid.UUID) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOwnedCustomerIDs", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOwnedCustomerIDs indicates an expected call of GetOwnedCustomerIDs.
+func (mr *MockUserRepositoryMockRecorder) GetOwnedCustomerIDs(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOwnedCustomerIDs", reflect.TypeOf((*MockUserRepository)(nil).GetOwnedCustomerIDs), arg0, arg1, arg2)
+}
+
+// GetPaymentMethods mocks base method.
+func (m *MockUserRepository) GetPaymentMethods(arg0 context.Context, arg1 uuid.UUID) ([]models.UserPaymentMethod, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentMethods", arg0, arg1)
+	ret0, _ := ret[0].([]models.UserPaymentMethod)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentMethods indicates an expected call of GetPaymentMethods.
+func (mr *MockUserRepositoryMockRecorder) GetPaymentMethods(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentMethods", reflect.TypeOf((*MockUserRepository)(nil).GetPaymentMethods), arg0, arg1)
+}
+
+// ReencryptAccountNumbers mocks base method.
+func (m *MockUserRepository) ReencryptAccountNumbers(arg0 context.Context, arg1 int32) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReencryptAccountNumbers", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReencryptAccountNumbers indicates an expected call of ReencryptAccountNumbers.
+func (mr *MockUserRepositoryMockRecorder) ReencryptAccountNumbers(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReencryptAccountNumbers", reflect.TypeOf((*MockUserRepository)(nil).ReencryptAccountNumbers), arg0, arg1)
+}
+
+// SetDefaultPaymentMethod mocks base method.
+func (m *MockUserRepository) SetDefaultPaymentMethod(arg0 context.Context, arg1, arg2 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDefaultPaymentMethod", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDefaultPaymentMethod indicates an expected call of SetDefaultPaymentMethod.
+func (mr *MockUserRepositoryMockRecorder) SetDefaultPaymentMethod(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDefaultPaymentMethod", reflect.TypeOf((*MockUserRepository)(nil).SetDefaultPaymentMethod), arg0, arg1, arg2)
+}
+
+// UpdatePaymentMethod mocks base method.
+func (m *MockUserRepository) UpdatePaymentMethod(arg0 context.Context, arg1, arg2 uuid.UUID, arg3 models.PaymentMethodUpdate) (*models.UserPaymentMethod, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePaymentMethod", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.UserPaymentMethod)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePaymentMethod indicates an expected call of UpdatePaymentMethod.
+func (mr *MockUserRepositoryMockRecorder) UpdatePaymentMethod(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePaymentMethod", reflect.TypeOf((*MockUserRepository)(nil).UpdatePaymentMethod), arg0, arg1, arg2, arg3)
+}
@@ -85,3 +85,61 @@ func (mr *MockUserServiceMockRecorder) CreateUser(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserService)(nil).CreateUser), arg0, arg1)
 }
+
+// DeletePaymentMethod mocks base method.
+func (m *MockUserService) DeletePaymentMethod(arg0 context.Context, arg1, arg2 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePaymentMethod", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePaymentMethod indicates an expected call of DeletePaymentMethod.
+func (mr *MockUserServiceMockRecorder) DeletePaymentMethod(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePaymentMethod", reflect.TypeOf((*MockUserService)(nil).DeletePaymentMethod), arg0, arg1, arg2)
+}
+
+// GetPaymentMethods mocks base method.
+func (m *MockUserService) GetPaymentMethods(arg0 context.Context, arg1 uuid.UUID) ([]models.UserPaymentMethod, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentMethods", arg0, arg1)
+	ret0, _ := ret[0].([]models.UserPaymentMethod)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentMethods indicates an expected call of GetPaymentMethods.
+func (mr *MockUserServiceMockRecorder) GetPaymentMethods(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentMethods", reflect.TypeOf((*MockUserService)(nil).GetPaymentMethods), arg0, arg1)
+}
+
+// SetDefaultPaymentMethod mocks base method.
+func (m *MockUserService) SetDefaultPaymentMethod(arg0 context.Context, arg1, arg2 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDefaultPaymentMethod", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDefaultPaymentMethod indicates an expected call of SetDefaultPaymentMethod.
+func (mr *MockUserServiceMockRecorder) SetDefaultPaymentMethod(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDefaultPaymentMethod", reflect.TypeOf((*MockUserService)(nil).SetDefaultPaymentMethod), arg0, arg1, arg2)
+}
+
+// UpdatePaymentMethod mocks base method.
+func (m *MockUserService) UpdatePaymentMethod(arg0 context.Context, arg1, arg2 uuid.UUID, arg3 models.UpdatePaymentMethodRequest) (*models.UserPaymentMethod, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePaymentMethod", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.UserPaymentMethod)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePaymentMethod indicates an expected call of UpdatePaymentMethod.
+func (mr *MockUserServiceMockRecorder) UpdatePaymentMethod(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePaymentMethod", reflect.TypeOf((*MockUserService)(nil).UpdatePaymentMethod), arg0, arg1, arg2, arg3)
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,12 +11,203 @@ import (
 type InvoiceStatus string
 
 const (
-	InvoiceStatusPaid    InvoiceStatus = "paid"
+	// InvoiceStatusPaid invoices were paid in full, late fees and interest included.
+	InvoiceStatusPaid InvoiceStatus = "paid"
+	// InvoiceStatusOverDue invoices were pending past their due date, and stay overdue until they are paid.
 	InvoiceStatusOverDue InvoiceStatus = "overdue"
-	InvoiceStatusDraft   InvoiceStatus = "draft"
+	// InvoiceStatusDraft invoices were not sent yet.
+	InvoiceStatusDraft InvoiceStatus = "draft"
+	// InvoiceStatusPending invoices were sent and wait to be paid.
 	InvoiceStatusPending InvoiceStatus = "pending"
 )
 
+type BankTransactionStatus string
+
+const (
+	// BankTransactionStatusPending transactions wait in the reconciliation inbox to be confirmed or dismissed.
+	BankTransactionStatusPending BankTransactionStatus = "pending"
+	// BankTransactionStatusApplied transactions were matched to an invoice and recorded as payments on import.
+	BankTransactionStatusApplied BankTransactionStatus = "applied"
+	// BankTransactionStatusConfirmed transactions were recorded as payments on the invoice confirmed by the user.
+	BankTransactionStatusConfirmed BankTransactionStatus = "confirmed"
+	// BankTransactionStatusDismissed transactions were dismissed by the user as not paying any invoice.
+	BankTransactionStatusDismissed BankTransactionStatus = "dismissed"
+)
+
+// ActivityEventType is what happened in an invoice activity. Comments are written by users, and every other type is
+// recorded by the system as it happens, with a payload describing it.
+type ActivityEventType string
+
+const (
+	// ActivityEventComment activities are notes written by the user, without a payload.
+	ActivityEventComment ActivityEventType = "comment"
+	// ActivityEventInvoiceCreated activities have an InvoiceCreatedPayload.
+	ActivityEventInvoiceCreated ActivityEventType = "invoice_created"
+	// ActivityEventStatusChanged activities have a StatusChangedPayload.
+	ActivityEventStatusChanged ActivityEventType = "status_changed"
+	// ActivityEventPaymentRecorded activities have a PaymentRecordedPayload.
+	ActivityEventPaymentRecorded ActivityEventType = "payment_recorded"
+	// ActivityEventPaymentRefunded activities have a PaymentRefundedPayload.
+	ActivityEventPaymentRefunded ActivityEventType = "payment_refunded"
+	// ActivityEventEmailSent activities have an EmailSentPayload.
+	ActivityEventEmailSent ActivityEventType = "email_sent"
+	// ActivityEventLateFeeApplied activities have a LateFeeAppliedPayload.
+	ActivityEventLateFeeApplied ActivityEventType = "late_fee_applied"
+)
+
+// ActivitySource tells the activities written by users apart from the ones recorded by the system.
+type ActivitySource string
+
+const (
+	ActivitySourceUser   ActivitySource = "user"
+	ActivitySourceSystem ActivitySource = "system"
+)
+
+// Source returns who records activities of the event type.
+func (t ActivityEventType) Source() ActivitySource {
+	if t == ActivityEventComment {
+		return ActivitySourceUser
+	}
+	return ActivitySourceSystem
+}
+
+type ReminderStatus string
+
+const (
+	// ReminderStatusPending reminders wait to be sent, or to be sent again after a failed attempt.
+	ReminderStatusPending ReminderStatus = "pending"
+	// ReminderStatusSent reminders were emailed to the customer.
+	ReminderStatusSent ReminderStatus = "sent"
+	// ReminderStatusFailed reminders could not be sent after every attempt.
+	ReminderStatusFailed ReminderStatus = "failed"
+)
+
+type ReminderTone string
+
+const (
+	ReminderToneFriendly ReminderTone = "friendly"
+	ReminderToneFirm     ReminderTone = "firm"
+	ReminderToneFinal    ReminderTone = "final"
+)
+
+type LateFeeType string
+
+const (
+	LateFeeTypeFlat            LateFeeType = "flat"
+	LateFeeTypeDailyInterest   LateFeeType = "daily_interest"
+	LateFeeTypeMonthlyInterest LateFeeType = "monthly_interest"
+)
+
+type AdjustmentType string
+
+const (
+	AdjustmentTypeLateFee  AdjustmentType = "late_fee"
+	AdjustmentTypeInterest AdjustmentType = "interest"
+)
+
+type TaxReportBasis string
+
+const (
+	TaxReportBasisInvoice TaxReportBasis = "invoice"
+	TaxReportBasisCash    TaxReportBasis = "cash"
+)
+
+type AgingBucket string
+
+const (
+	AgingBucketCurrent AgingBucket = "current"
+	AgingBucket1To30   AgingBucket = "1-30"
+	AgingBucket31To60  AgingBucket = "31-60"
+	AgingBucket61To90  AgingBucket = "61-90"
+	AgingBucketOver90  AgingBucket = "90+"
+)
+
+type SeriesMetric string
+
+const (
+	SeriesMetricInvoiced  SeriesMetric = "invoiced"
+	SeriesMetricCollected SeriesMetric = "collected"
+)
+
+type SeriesInterval string
+
+const (
+	SeriesIntervalDay   SeriesInterval = "day"
+	SeriesIntervalWeek  SeriesInterval = "week"
+	SeriesIntervalMonth SeriesInterval = "month"
+)
+
+// SeriesGroupBy is how a time series report is split. Without grouping the amounts are converted into the
+// sender's base currency.
+type SeriesGroupBy string
+
+const (
+	SeriesGroupByNone     SeriesGroupBy = ""
+	SeriesGroupByCustomer SeriesGroupBy = "customer"
+	SeriesGroupByCurrency SeriesGroupBy = "currency"
+)
+
+type InvoiceSortField string
+
+const (
+	InvoiceSortFieldDueDate   InvoiceSortField = "due_date"
+	InvoiceSortFieldAmount    InvoiceSortField = "amount"
+	InvoiceSortFieldNumber    InvoiceSortField = "number"
+	InvoiceSortFieldCreatedAt InvoiceSortField = "created_at"
+)
+
+type SortOrder string
+
+const (
+	SortOrderAsc  SortOrder = "asc"
+	SortOrderDesc SortOrder = "desc"
+)
+
+type ExportFormat string
+
+const (
+	ExportFormatCSV  ExportFormat = "csv"
+	ExportFormatXLSX ExportFormat = "xlsx"
+)
+
+type TotalsDateField string
+
+const (
+	TotalsDateFieldIssue TotalsDateField = "issue_date"
+	TotalsDateFieldDue   TotalsDateField = "due_date"
+)
+
+type PaymentSessionStatus string
+
+const (
+	PaymentSessionStatusOpen      PaymentSessionStatus = "open"
+	PaymentSessionStatusCompleted PaymentSessionStatus = "completed"
+	PaymentSessionStatusFailed    PaymentSessionStatus = "failed"
+)
+
+// PaymentRefundStatus is how far a refund of a payment made online got with the payment provider.
+type PaymentRefundStatus string
+
+const (
+	// PaymentRefundStatusPending refunds were requested, and are sent to the provider until it makes or rejects them.
+	PaymentRefundStatusPending PaymentRefundStatus = "pending"
+	// PaymentRefundStatusCompleted refunds were made by the provider and recorded on their invoice.
+	PaymentRefundStatusCompleted PaymentRefundStatus = "completed"
+	// PaymentRefundStatusFailed refunds were rejected by the provider, and returned no money.
+	PaymentRefundStatusFailed PaymentRefundStatus = "failed"
+)
+
+// AccountType is the kind of bank account a payment method is paid into, which decides how its account number and
+// bank codes are validated.
+type AccountType string
+
+const (
+	AccountTypeIBAN  AccountType = "iban"
+	AccountTypeUS    AccountType = "us"
+	AccountTypeUK    AccountType = "uk"
+	AccountTypeOther AccountType = "other"
+)
+
 type User struct {
 	UserID            uuid.UUID `json:"user_id"`
 	Username          string    `json:"username"`
@@ -25,12 +218,14 @@ type User struct {
 	ProfilePictureURL string    `json:"profile_picture_url"`
 	PhoneNumber       string    `json:"phone_number"`
 	Address           string    `json:"address"`
+	BaseCurrency      string    `json:"base_currency"`
 	CreatedAt         time.Time `json:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at"`
 }
 
 type Customer struct {
-	CustomerID  uuid.UUID `json:"user_id"`
+	CustomerID  uuid.UUID `json:"customer_id"`
+	UserID      uuid.UUID `json:"user_id"`
 	Name        string    `json:"name"`
 	Email       string    `json:"email"`
 	PhoneNumber string    `json:"phone_number"`
@@ -52,34 +247,76 @@ type Invoice struct {
 	FinalAmount        float64   `json:"final_amount"`
 	Status             string    `json:"status"`
 	Currency           string    `json:"currency"`
+	BaseCurrency       string    `json:"base_currency"`
+	ExchangeRate       *float64  `json:"exchange_rate"`
 	Notes              string    `json:"notes"`
 	CreatedAt          time.Time `json:"created_at"`
 	UpdatedAt          time.Time `json:"updated_at"`
 }
 
 type InvoiceItem struct {
-	ItemID      uuid.UUID `json:"item_id"`
-	InvoiceID   uuid.UUID `json:"invoice_id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Quantity    int       `json:"quantity"`
-	UnitPrice   float64   `json:"unit_price"`
-	TotalPrice  float64   `json:"total_price"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ItemID      uuid.UUID        `json:"item_id"`
+	InvoiceID   uuid.UUID        `json:"invoice_id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Quantity    int              `json:"quantity"`
+	UnitPrice   float64          `json:"unit_price"`
+	TotalPrice  float64          `json:"total_price"`
+	Taxes       []InvoiceItemTax `json:"taxes"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
 }
 
 type UserPaymentMethod struct {
-	PaymentMethodID uuid.UUID `json:"payment_method_id"`
-	UserID          uuid.UUID `json:"user_id"`
-	AccountName     string    `json:"account_name"`
-	AccountNumber   string    `json:"account_number"`
-	BankName        string    `json:"bank_name"`
-	BankAddress     string    `json:"bank_address"`
-	SwiftCode       string    `json:"swift_code"`
-	IsDefault       bool      `json:"is_default"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	PaymentMethodID uuid.UUID   `json:"payment_method_id"`
+	UserID          uuid.UUID   `json:"user_id"`
+	AccountName     string      `json:"account_name"`
+	AccountType     AccountType `json:"account_type"`
+	// AccountNumber is the full account number, which is stored encrypted. It is only shown on rendered invoice
+	// documents; API responses show MaskedAccountNumber, with the last four digits, instead.
+	AccountNumber       string `json:"-"`
+	MaskedAccountNumber string `json:"account_number"`
+	// RoutingNumber is the ABA routing number of us accounts, and SortCode the sort code of uk accounts.
+	RoutingNumber string    `json:"routing_number,omitempty"`
+	SortCode      string    `json:"sort_code,omitempty"`
+	BankName      string    `json:"bank_name"`
+	BankAddress   string    `json:"bank_address"`
+	SwiftCode     string    `json:"swift_code"`
+	IsDefault     bool      `json:"is_default"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ErrUserNotFound is returned when a customer is added for a user that does not exist.
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrPaymentMethodNotFound is returned when a payment method does not exist, belongs to another user or was deleted.
+var ErrPaymentMethodNotFound = errors.New("payment method not found")
+
+// ErrPaymentMethodInUse is returned when a payment method that invoices were sent with is updated, since the invoices
+// must keep showing the details they were sent with.
+var ErrPaymentMethodInUse = errors.New("payment method is used by invoices; add a new payment method instead")
+
+// ErrCustomerNotOwned is returned when an invoice is created for a customer that does not exist or belongs to
+// another user than the sender.
+var ErrCustomerNotOwned = errors.New("customer does not belong to the sender")
+
+// ErrPaymentMethodNotOwned is returned when an invoice is created with a payment method that does not exist, was
+// deleted or belongs to another user than the sender.
+var ErrPaymentMethodNotOwned = errors.New("payment method does not belong to the sender")
+
+// PaymentMethodUpdate holds the fields of a payment method to change. Fields that are nil are left unchanged.
+// The account type, account number, routing number and sort code are replaced together: when AccountNumber is set,
+// a nil RoutingNumber or SortCode clears it.
+type PaymentMethodUpdate struct {
+	AccountName   *string
+	AccountType   *AccountType
+	AccountNumber *string
+	RoutingNumber *string
+	SortCode      *string
+	BankName      *string
+	BankAddress   *string
+	SwiftCode     *string
 }
 
 type PaymentInformation struct {
@@ -101,22 +338,730 @@ type InvoiceDetails struct {
 	CustomerPhoneNumber string
 	PaymentInformation  UserPaymentMethod
 	Items               []InvoiceItem
+	TaxLines            []InvoiceTaxLine
+	Adjustments         []InvoiceAdjustment
+	Payments            []Payment
 	Activities          []InvoiceActivity
+	Totals              InvoiceTotals
 }
 
 type InvoiceActivity struct {
-	ActivityID  uuid.UUID `json:"activity_id"`
-	InvoiceID   uuid.UUID `json:"invoice_id"`
-	UserID      uuid.UUID `json:"user_id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
+	ActivityID uuid.UUID         `json:"activity_id"`
+	InvoiceID  uuid.UUID         `json:"invoice_id"`
+	UserID     uuid.UUID         `json:"user_id"`
+	EventType  ActivityEventType `json:"event_type"`
+	Source     ActivitySource    `json:"source"`
+	// Payload describes the event, in the payload struct of its type. Activities recorded before event types were
+	// introduced have an empty payload.
+	Payload     json.RawMessage `json:"payload"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// NewInvoiceActivity returns a new activity of the event type on an invoice, with the payload, which is one of the
+// payload structs below, or nil for comments.
+func NewInvoiceActivity(invoiceID, userID uuid.UUID, eventType ActivityEventType, payload any, title, description string) InvoiceActivity {
+	data := json.RawMessage(`{}`)
+	if payload != nil {
+		// the payload structs only hold plain values, which always marshal
+		data, _ = json.Marshal(payload)
+	}
+	return InvoiceActivity{
+		ActivityID:  uuid.New(),
+		InvoiceID:   invoiceID,
+		UserID:      userID,
+		EventType:   eventType,
+		Source:      eventType.Source(),
+		Payload:     data,
+		Title:       title,
+		Description: description,
+	}
+}
+
+// ActivityEvent is an invoice activity as it is pushed to the event stream of its user, with its sequence number in the
+// event log.
+type ActivityEvent struct {
+	Sequence int64 `json:"sequence"`
+	// Xid is the ID of the transaction that recorded the event.
+	Xid int64 `json:"-"`
+	// Position is where the stream resumes from once the event is sent: the events of every transaction older than it
+	// were sent before. Sequence numbers cannot be resumed after, because events commit out of their order.
+	Position int64 `json:"-"`
+	InvoiceActivity
+}
+
+// Cursor returns the position of the event in the event log, in the order of the transactions that recorded it.
+func (e ActivityEvent) Cursor() EventCursor {
+	return EventCursor{Xid: e.Xid, Sequence: e.Sequence}
+}
+
+// EventCursor is a position in the event log, in the order of the transactions that recorded the events and then of
+// their sequence numbers, which readers of the log move past.
+type EventCursor struct {
+	Xid      int64
+	Sequence int64
+}
+
+// After tells whether the cursor is past the other one.
+func (c EventCursor) After(other EventCursor) bool {
+	return c.Xid > other.Xid || c.Xid == other.Xid && c.Sequence > other.Sequence
+}
+
+// ActivityNotification tells the servers listening for new activities which event was recorded for which user, and
+// its position in the stream.
+type ActivityNotification struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Sequence int64     `json:"sequence"`
+	Position int64     `json:"position"`
+}
+
+// ErrEventStreamUnavailable is returned when subscribing to activity events while the server does not listen for new
+// ones, because its connection to the database failed or it is shutting down.
+var ErrEventStreamUnavailable = errors.New("event stream is unavailable")
+
+// EventSubscription is the stream of the activity events of a user.
+type EventSubscription struct {
+	// Missed are the events recorded since the position the client resumes from, to send first. They may include
+	// events the client received before it lost its connection.
+	Missed []ActivityEvent
+	// More tells that more events were missed than Missed holds.
+	More bool
+	// Events receives the events recorded from now on. It is closed when the stream ends because the server stops
+	// listening or the client falls too far behind, after which the client resumes from the last position it received.
+	Events <-chan ActivityEvent
+	// Close ends the subscription.
+	Close func()
+}
+
+// InvoiceCreatedPayload is the payload of ActivityEventInvoiceCreated activities.
+type InvoiceCreatedPayload struct {
+	InvoiceNumber string        `json:"invoice_number"`
+	Amount        float64       `json:"amount"`
+	Currency      string        `json:"currency"`
+	Status        InvoiceStatus `json:"status,omitempty"`
+}
+
+// StatusChangedPayload is the payload of ActivityEventStatusChanged activities.
+type StatusChangedPayload struct {
+	From InvoiceStatus `json:"from"`
+	To   InvoiceStatus `json:"to"`
+}
+
+// PaymentRecordedPayload is the payload of ActivityEventPaymentRecorded activities.
+type PaymentRecordedPayload struct {
+	PaymentID uuid.UUID `json:"payment_id"`
+	Amount    float64   `json:"amount"`
+	Currency  string    `json:"currency"`
+	Reference string    `json:"reference,omitempty"`
+}
+
+// PaymentRefundedPayload is the payload of ActivityEventPaymentRefunded activities. RefundID is also the ID of the
+// negative payment the refund is recorded as, and Reference identifies the refund at the payment provider.
+type PaymentRefundedPayload struct {
+	RefundID  uuid.UUID `json:"refund_id"`
+	PaymentID uuid.UUID `json:"payment_id"`
+	Amount    float64   `json:"amount"`
+	Currency  string    `json:"currency"`
+	Reference string    `json:"reference,omitempty"`
+}
+
+// EmailSentPayload is the payload of ActivityEventEmailSent activities.
+type EmailSentPayload struct {
+	To string `json:"to"`
+	// Kind is the kind of email sent, such as reminder.
+	Kind string       `json:"kind"`
+	Tone ReminderTone `json:"tone,omitempty"`
+}
+
+// LateFeeAppliedPayload is the payload of ActivityEventLateFeeApplied activities.
+type LateFeeAppliedPayload struct {
+	AdjustmentID   uuid.UUID      `json:"adjustment_id"`
+	AdjustmentType AdjustmentType `json:"adjustment_type"`
+	Amount         float64        `json:"amount"`
+}
+
+// ActivityFilter restricts a list of invoice activities to the event types, when any are given, and to the source,
+// when given.
+type ActivityFilter struct {
+	EventTypes []ActivityEventType
+	Source     ActivitySource
 }
 
 type RecentActivity struct {
-	ActivityID  uuid.UUID `json:"activity_id"`
+	ActivityID uuid.UUID `json:"activity_id"`
+	// InvoiceID is nil for the few activities recorded without an invoice before the activity feeds were merged.
+	InvoiceID   *uuid.UUID        `json:"invoice_id,omitempty"`
+	UserID      uuid.UUID         `json:"user_id"`
+	EventType   ActivityEventType `json:"event_type"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+type ReminderRule struct {
+	RuleID     uuid.UUID    `json:"rule_id"`
+	UserID     uuid.UUID    `json:"user_id"`
+	DaysOffset int          `json:"days_offset"`
+	Tone       ReminderTone `json:"tone"`
+	IsActive   bool         `json:"is_active"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
+
+type InvoiceReminder struct {
+	ReminderID uuid.UUID    `json:"reminder_id"`
+	InvoiceID  uuid.UUID    `json:"invoice_id"`
+	RuleID     uuid.UUID    `json:"rule_id"`
+	DaysOffset int          `json:"days_offset"`
+	Tone       ReminderTone `json:"tone"`
+	SentTo     string       `json:"sent_to"`
+	SentAt     time.Time    `json:"sent_at"`
+}
+
+// DueReminder is an invoice that has reached one of its sender's reminder rules
+// and has not been reminded at that step (or a later one) yet. ReminderID is the pending
+// reminder claimed to send it, and Attempts the number of times sending it failed before.
+// BalanceDue is what is left to pay, late fees and interest included.
+type DueReminder struct {
+	ReminderID    uuid.UUID
+	Attempts      int
+	Invoice       Invoice
+	BalanceDue    float64
+	Rule          ReminderRule
+	SenderName    string
+	SenderEmail   string
+	CustomerName  string
+	CustomerEmail string
+}
+
+type LateFeePolicy struct {
+	PolicyID      uuid.UUID   `json:"policy_id"`
+	UserID        uuid.UUID   `json:"user_id"`
+	FeeType       LateFeeType `json:"fee_type"`
+	FlatAmount    float64     `json:"flat_amount"`
+	InterestRate  float64     `json:"interest_rate"`
+	GraceDays     int         `json:"grace_days"`
+	MaxAmount     *float64    `json:"max_amount"`
+	MaxPercentage *float64    `json:"max_percentage"`
+	IsActive      bool        `json:"is_active"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+}
+
+type InvoiceAdjustment struct {
+	AdjustmentID   uuid.UUID      `json:"adjustment_id"`
+	InvoiceID      uuid.UUID      `json:"invoice_id"`
+	PolicyID       uuid.UUID      `json:"policy_id"`
+	AdjustmentType AdjustmentType `json:"adjustment_type"`
+	Amount         float64        `json:"amount"`
+	Description    string         `json:"description"`
+	AppliedOn      time.Time      `json:"applied_on"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+// LateFeeCandidate is an overdue invoice whose sender has an active late fee policy, together with the
+// late fees and interest already applied to it.
+type LateFeeCandidate struct {
+	Invoice       Invoice
+	Policy        LateFeePolicy
+	AppliedAmount float64
+}
+
+type TaxRate struct {
+	TaxRateID   uuid.UUID `json:"tax_rate_id"`
 	UserID      uuid.UUID `json:"user_id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
+	Name        string    `json:"name"`
+	Rate        float64   `json:"rate"`
+	IsInclusive bool      `json:"is_inclusive"`
+	IsCompound  bool      `json:"is_compound"`
+	IsActive    bool      `json:"is_active"`
 	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// InvoiceItemTax is a tax charged on a single invoice item. The tax rate is copied onto the item
+// so that later changes to the rate do not alter invoices already issued.
+type InvoiceItemTax struct {
+	ItemTaxID     uuid.UUID `json:"item_tax_id"`
+	ItemID        uuid.UUID `json:"item_id"`
+	TaxRateID     uuid.UUID `json:"tax_rate_id"`
+	Name          string    `json:"name"`
+	Rate          float64   `json:"rate"`
+	IsInclusive   bool      `json:"is_inclusive"`
+	IsCompound    bool      `json:"is_compound"`
+	TaxableAmount float64   `json:"taxable_amount"`
+	TaxAmount     float64   `json:"tax_amount"`
+}
+
+// InvoiceTaxLine sums up a single tax rate across every item of an invoice.
+type InvoiceTaxLine struct {
+	TaxLineID     uuid.UUID `json:"tax_line_id"`
+	InvoiceID     uuid.UUID `json:"invoice_id"`
+	TaxRateID     uuid.UUID `json:"tax_rate_id"`
+	Name          string    `json:"name"`
+	Rate          float64   `json:"rate"`
+	IsInclusive   bool      `json:"is_inclusive"`
+	IsCompound    bool      `json:"is_compound"`
+	TaxableAmount float64   `json:"taxable_amount"`
+	TaxAmount     float64   `json:"tax_amount"`
+}
+
+// InvoiceTotals breaks the amount due on an invoice down into its parts. Tax includes taxes already
+// contained in the item prices, which are not added to the grand total again.
+type InvoiceTotals struct {
+	Subtotal    float64 `json:"subtotal"`
+	Discount    float64 `json:"discount"`
+	Tax         float64 `json:"tax"`
+	Adjustments float64 `json:"adjustments"`
+	GrandTotal  float64 `json:"grand_total"`
+	AmountPaid  float64 `json:"amount_paid"`
+	BalanceDue  float64 `json:"balance_due"`
+}
+
+// Payment is money received on an invoice. Refunds are payments of a negative amount, with RefundedPaymentID set to
+// the payment whose money they returned.
+type Payment struct {
+	PaymentID         uuid.UUID  `json:"payment_id"`
+	InvoiceID         uuid.UUID  `json:"invoice_id"`
+	Amount            float64    `json:"amount"`
+	PaidOn            time.Time  `json:"paid_on"`
+	Reference         string     `json:"reference"`
+	RefundedPaymentID *uuid.UUID `json:"refunded_payment_id,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
 }
+
+// PaymentRefund is a refund of a payment made online, sent to the payment provider with RefundID as the idempotency
+// key. It is pending until the provider made it, and is recorded on its invoice as a payment of the negative amount,
+// with RefundID as its PaymentID, once it is completed. PaymentReference identifies the refunded payment at the
+// provider.
+type PaymentRefund struct {
+	RefundID         uuid.UUID           `json:"refund_id"`
+	PaymentID        uuid.UUID           `json:"payment_id"`
+	InvoiceID        uuid.UUID           `json:"invoice_id"`
+	UserID           uuid.UUID           `json:"user_id"`
+	Provider         string              `json:"provider"`
+	PaymentReference string              `json:"payment_reference"`
+	Amount           float64             `json:"amount"`
+	Currency         string              `json:"currency"`
+	Status           PaymentRefundStatus `json:"status"`
+	ProviderRefundID string              `json:"provider_refund_id,omitempty"`
+	FailureReason    string              `json:"failure_reason,omitempty"`
+	CreatedAt        time.Time           `json:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at"`
+}
+
+// PaymentSession is a checkout session hosted by a payment provider, on which the customer of an invoice pays the
+// balance due online at URL until it expires. PaymentID is the payment recorded once the session is paid.
+type PaymentSession struct {
+	PaymentSessionID  uuid.UUID            `json:"payment_session_id"`
+	InvoiceID         uuid.UUID            `json:"invoice_id"`
+	Provider          string               `json:"provider"`
+	ProviderSessionID string               `json:"provider_session_id"`
+	URL               string               `json:"url"`
+	Amount            float64              `json:"amount"`
+	Currency          string               `json:"currency"`
+	Status            PaymentSessionStatus `json:"status"`
+	PaymentID         *uuid.UUID           `json:"payment_id,omitempty"`
+	ExpiresAt         time.Time            `json:"expires_at"`
+	CreatedAt         time.Time            `json:"created_at"`
+	UpdatedAt         time.Time            `json:"updated_at"`
+}
+
+// PayableInvoice is an invoice of a sender with the balance left to pay on it, including late fees.
+type PayableInvoice struct {
+	InvoiceID     uuid.UUID
+	InvoiceNumber string
+	Status        InvoiceStatus
+	Currency      string
+	CustomerName  string
+	CustomerEmail string
+	BalanceDue    float64
+}
+
+// ErrInvoiceNotFound is returned when an invoice does not exist or belongs to another sender.
+var ErrInvoiceNotFound = errors.New("invoice not found")
+
+// ErrInvoiceIsDraft is returned when a payment is recorded on an invoice that was not issued yet.
+var ErrInvoiceIsDraft = errors.New("cannot record a payment on a draft invoice")
+
+// ErrInvoiceAlreadyPaid is returned when a payment is recorded on an invoice that is paid in full.
+var ErrInvoiceAlreadyPaid = errors.New("invoice is already paid")
+
+// ErrPaymentExceedsBalance is returned when a payment recorded by hand is larger than the balance due on its invoice.
+var ErrPaymentExceedsBalance = errors.New("payment exceeds the balance due")
+
+// ErrPaymentNotFound is returned when a payment does not exist, is a refund or was received on an invoice of another
+// sender.
+var ErrPaymentNotFound = errors.New("payment not found")
+
+// ErrPaymentNotRefundable is returned when a refund is requested for a payment that was not made online through the
+// payment provider, or whose every part is refunded or being refunded already.
+var ErrPaymentNotRefundable = errors.New("payment cannot be refunded")
+
+// ErrRefundExceedsPayment is returned when a refund is larger than the part of its payment not refunded or being
+// refunded yet.
+var ErrRefundExceedsPayment = errors.New("refund exceeds the amount left to refund")
+
+// ErrPaymentRefundNotFound is returned when a refund to complete or fail does not exist.
+var ErrPaymentRefundNotFound = errors.New("payment refund not found")
+
+// ErrPaymentSessionNotFound is returned when a payment provider reports on a checkout session that was not created
+// with it.
+var ErrPaymentSessionNotFound = errors.New("payment session not found")
+
+// TaxReport aggregates the tax charged by a sender over a filing period, either on the invoices issued in
+// the period or, on a cash basis, on the share of each invoice paid in the period.
+type TaxReport struct {
+	SenderID uuid.UUID       `json:"sender_id"`
+	From     time.Time       `json:"from"`
+	To       time.Time       `json:"to"`
+	Basis    TaxReportBasis  `json:"basis"`
+	Lines    []TaxReportLine `json:"lines"`
+}
+
+// TaxReportLine is the taxable amount and tax collected for a single tax rate and currency.
+type TaxReportLine struct {
+	TaxRateID     uuid.UUID `json:"tax_rate_id"`
+	Name          string    `json:"name"`
+	Rate          float64   `json:"rate"`
+	Currency      string    `json:"currency"`
+	TaxableAmount float64   `json:"taxable_amount"`
+	TaxAmount     float64   `json:"tax_amount"`
+	InvoiceCount  int       `json:"invoice_count"`
+}
+
+// ErrExchangeRateNotFound is returned when no exchange rate between two currencies took effect by a given date.
+var ErrExchangeRateNotFound = errors.New("no exchange rate")
+
+// ExchangeRate is the number of units of ToCurrency one unit of FromCurrency buys from EffectiveDate on.
+type ExchangeRate struct {
+	FromCurrency  string    `json:"from_currency"`
+	ToCurrency    string    `json:"to_currency"`
+	Rate          float64   `json:"rate"`
+	EffectiveDate time.Time `json:"effective_date"`
+	Source        string    `json:"source"`
+}
+
+// CurrencyTotal is the number and total amount of invoices in a single currency.
+type CurrencyTotal struct {
+	Currency    string  `json:"currency"`
+	Count       int     `json:"count"`
+	TotalAmount float64 `json:"total_amount"`
+}
+
+// StatusTotals summarizes the invoices with a status per invoice currency, and converted into the base currency
+// of their sender at the exchange rate locked on each invoice.
+type StatusTotals struct {
+	Status         InvoiceStatus   `json:"status"`
+	Count          int             `json:"count"`
+	Currencies     []CurrencyTotal `json:"currencies"`
+	BaseCurrencies []CurrencyTotal `json:"base_currencies"`
+}
+
+// TotalsFilter selects the invoices of a sender that are summarized. The optional period applies to either the
+// issue date or the due date and includes both ends.
+type TotalsFilter struct {
+	SenderID   uuid.UUID
+	CustomerID *uuid.UUID
+	DateField  TotalsDateField
+	From       *time.Time
+	To         *time.Time
+}
+
+// InvoiceSummary holds the totals of a sender's invoices for every status, as shown on the dashboard.
+type InvoiceSummary struct {
+	SenderID   uuid.UUID       `json:"sender_id"`
+	CustomerID *uuid.UUID      `json:"customer_id,omitempty"`
+	DateField  TotalsDateField `json:"date_field"`
+	From       *time.Time      `json:"from,omitempty"`
+	To         *time.Time      `json:"to,omitempty"`
+	Statuses   []StatusTotals  `json:"statuses"`
+}
+
+// OpenBalance is the amount still owed on a sender's unpaid invoices in a single currency, after payments and
+// late fees, along with the parts of it that are overdue or fall due soon.
+type OpenBalance struct {
+	Currency      string  `json:"currency"`
+	InvoiceCount  int     `json:"invoice_count"`
+	Outstanding   float64 `json:"outstanding"`
+	Overdue       float64 `json:"overdue"`
+	DueNext7Days  float64 `json:"due_next_7_days"`
+	DueNext30Days float64 `json:"due_next_30_days"`
+}
+
+// Dashboard holds everything the home screen of a sender shows.
+type Dashboard struct {
+	SenderID         uuid.UUID        `json:"sender_id"`
+	AsOf             time.Time        `json:"as_of"`
+	Statuses         []StatusTotals   `json:"statuses"`
+	Balances         []OpenBalance    `json:"balances"`
+	RecentInvoices   []Invoice        `json:"recent_invoices"`
+	RecentActivities []RecentActivity `json:"recent_activities"`
+}
+
+// AgingBalances splits an outstanding balance by how many days past its due date it is.
+type AgingBalances struct {
+	Current    float64 `json:"current"`
+	Days1To30  float64 `json:"days_1_30"`
+	Days31To60 float64 `json:"days_31_60"`
+	Days61To90 float64 `json:"days_61_90"`
+	Over90     float64 `json:"over_90"`
+	Total      float64 `json:"total"`
+}
+
+// AgingLine is the aged balance a single customer owes in a single currency.
+type AgingLine struct {
+	CustomerID   uuid.UUID `json:"customer_id"`
+	CustomerName string    `json:"customer_name"`
+	Currency     string    `json:"currency"`
+	InvoiceCount int       `json:"invoice_count"`
+	AgingBalances
+}
+
+// AgingTotal is the aged balance all customers owe in a single currency.
+type AgingTotal struct {
+	Currency     string `json:"currency"`
+	InvoiceCount int    `json:"invoice_count"`
+	AgingBalances
+}
+
+// AgingReport is the accounts receivable aging of a sender as of a date, per customer and in total.
+type AgingReport struct {
+	SenderID  uuid.UUID    `json:"sender_id"`
+	AsOf      time.Time    `json:"as_of"`
+	Customers []AgingLine  `json:"customers"`
+	Totals    []AgingTotal `json:"totals"`
+}
+
+// AgingInvoice is an invoice with a balance left as of the aging date, and the aging bucket it falls in.
+type AgingInvoice struct {
+	InvoiceID     uuid.UUID   `json:"invoice_id"`
+	InvoiceNumber string      `json:"invoice_number"`
+	CustomerID    uuid.UUID   `json:"customer_id"`
+	CustomerName  string      `json:"customer_name"`
+	Currency      string      `json:"currency"`
+	IssueDate     time.Time   `json:"issue_date"`
+	DueDate       time.Time   `json:"due_date"`
+	DaysPastDue   int         `json:"days_past_due"`
+	Balance       float64     `json:"balance"`
+	Bucket        AgingBucket `json:"bucket"`
+}
+
+// SeriesFilter selects what a time series report covers.
+type SeriesFilter struct {
+	SenderID uuid.UUID
+	Metric   SeriesMetric
+	Interval SeriesInterval
+	GroupBy  SeriesGroupBy
+	From     time.Time
+	To       time.Time
+}
+
+// SeriesPoint is the amount invoiced or collected in the period starting on Period.
+type SeriesPoint struct {
+	Period time.Time `json:"period"`
+	Count  int       `json:"count"`
+	Amount float64   `json:"amount"`
+}
+
+// Series is the amount invoiced or collected over time in a single currency, for a single customer when the
+// report is grouped by customer.
+type Series struct {
+	CustomerID   *uuid.UUID    `json:"customer_id,omitempty"`
+	CustomerName string        `json:"customer_name,omitempty"`
+	Currency     string        `json:"currency"`
+	Total        float64       `json:"total"`
+	Points       []SeriesPoint `json:"points"`
+}
+
+// SeriesReport is the amount a sender invoiced, by issue date, or collected, by payment date, per day, week or month.
+type SeriesReport struct {
+	SenderID uuid.UUID      `json:"sender_id"`
+	Metric   SeriesMetric   `json:"metric"`
+	Interval SeriesInterval `json:"interval"`
+	GroupBy  SeriesGroupBy  `json:"group_by,omitempty"`
+	From     time.Time      `json:"from"`
+	To       time.Time      `json:"to"`
+	Series   []Series       `json:"series"`
+}
+
+// InvoiceFilter selects and orders the invoices of a sender when searching them. Every filter left empty matches
+// all invoices; Search matches the notes and item names of an invoice, ignoring case.
+type InvoiceFilter struct {
+	SenderID     uuid.UUID
+	Statuses     []InvoiceStatus
+	CustomerID   *uuid.UUID
+	Currency     string
+	IssueFrom    *time.Time
+	IssueTo      *time.Time
+	DueFrom      *time.Time
+	DueTo        *time.Time
+	MinAmount    *float64
+	MaxAmount    *float64
+	NumberPrefix string
+	Search       string
+	SortBy       InvoiceSortField
+	SortOrder    SortOrder
+}
+
+// InvoiceExportRow is a row of an invoice export: an invoice with the name of its customer and, when the export
+// lists line items, one of its items. Item is nil for an invoice without items.
+type InvoiceExportRow struct {
+	Invoice
+	CustomerName string
+	Item         *InvoiceItem
+}
+
+// NewInvoice is an invoice ready to be stored, with its items, tax lines and payment information.
+type NewInvoice struct {
+	Invoice     Invoice
+	Items       []InvoiceItem
+	TaxLines    []InvoiceTaxLine
+	PaymentInfo PaymentInformation
+}
+
+// ImportError is the reason a row of an imported file was not imported, by the line of the file it is on.
+type ImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// ImportResult is the outcome of importing a file: the number of rows read, the number of the customers or invoices
+// they hold that are valid and that were imported, and the errors of the rows that were not imported. Nothing is
+// imported in a dry run.
+type ImportResult struct {
+	DryRun   bool          `json:"dry_run"`
+	Rows     int           `json:"rows"`
+	Valid    int           `json:"valid"`
+	Imported int           `json:"imported"`
+	Errors   []ImportError `json:"errors"`
+}
+
+// BankTransaction is an incoming credit on a bank statement imported by a user. ExternalID is the bank's id of the
+// transaction, or a fingerprint of it when the statement has none, so a statement can be imported more than once.
+// Transactions matched to an invoice record a payment on it, and the others wait in the reconciliation inbox with
+// the invoices they may pay.
+type BankTransaction struct {
+	BankTransactionID   uuid.UUID             `json:"bank_transaction_id"`
+	UserID              uuid.UUID             `json:"user_id"`
+	ExternalID          string                `json:"external_id"`
+	BookingDate         time.Time             `json:"booking_date"`
+	Amount              float64               `json:"amount"`
+	Currency            string                `json:"currency"`
+	CounterpartyName    string                `json:"counterparty_name"`
+	RemittanceInfo      string                `json:"remittance_info"`
+	Status              BankTransactionStatus `json:"status"`
+	InvoiceID           *uuid.UUID            `json:"invoice_id,omitempty"`
+	PaymentID           *uuid.UUID            `json:"payment_id,omitempty"`
+	SuggestedInvoiceIDs []uuid.UUID           `json:"suggested_invoice_ids"`
+	CreatedAt           time.Time             `json:"created_at"`
+	UpdatedAt           time.Time             `json:"updated_at"`
+}
+
+// ReconciliationResult is the outcome of importing a bank statement: the number of credits read from it, how many
+// were applied to invoices as payments, queued in the reconciliation inbox or skipped as imported before, and the
+// transactions that were stored.
+type ReconciliationResult struct {
+	Format       string            `json:"format"`
+	Credits      int               `json:"credits"`
+	Applied      int               `json:"applied"`
+	Pending      int               `json:"pending"`
+	Duplicates   int               `json:"duplicates"`
+	Transactions []BankTransaction `json:"transactions"`
+}
+
+// ErrBankTransactionNotFound is returned when a bank transaction does not exist or belongs to another user.
+var ErrBankTransactionNotFound = errors.New("bank transaction not found")
+
+// ErrBankTransactionReconciled is returned when a bank transaction that left the reconciliation inbox is confirmed
+// or dismissed.
+var ErrBankTransactionReconciled = errors.New("bank transaction is already reconciled")
+
+// ErrCurrencyMismatch is returned when a bank transaction is confirmed as paying an invoice in another currency.
+var ErrCurrencyMismatch = errors.New("currency does not match the invoice")
+
+// WebhookEventType is an invoice lifecycle event a webhook subscribes to.
+type WebhookEventType string
+
+const (
+	// WebhookEventInvoiceCreated events are sent when an invoice is created, drafts included.
+	WebhookEventInvoiceCreated WebhookEventType = "invoice.created"
+	// WebhookEventInvoiceSent events are sent when an invoice is issued to its customer, on creation unless it is a
+	// draft.
+	WebhookEventInvoiceSent WebhookEventType = "invoice.sent"
+	// WebhookEventInvoicePaid events are sent when the payments on an invoice settle it.
+	WebhookEventInvoicePaid WebhookEventType = "invoice.paid"
+	// WebhookEventInvoiceOverdue events are sent when an unpaid invoice is past its due date.
+	WebhookEventInvoiceOverdue WebhookEventType = "invoice.overdue"
+)
+
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryStatusPending deliveries wait for their next attempt.
+	WebhookDeliveryStatusPending WebhookDeliveryStatus = "pending"
+	// WebhookDeliveryStatusSucceeded deliveries were accepted by the webhook URL.
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "succeeded"
+	// WebhookDeliveryStatusFailed deliveries were not accepted after every attempt, and can be replayed.
+	WebhookDeliveryStatusFailed WebhookDeliveryStatus = "failed"
+)
+
+// Webhook sends the invoice lifecycle events of its types to a URL, signed with its secret. A webhook whose
+// deliveries keep failing is disabled until its user enables it again.
+type Webhook struct {
+	WebhookID  uuid.UUID          `json:"webhook_id"`
+	UserID     uuid.UUID          `json:"user_id"`
+	URL        string             `json:"url"`
+	EventTypes []WebhookEventType `json:"event_types"`
+	// Secret is only shown when the webhook is created.
+	Secret string `json:"secret,omitempty"`
+	// LastEvent is the position of the last event of the event log considered for delivery.
+	LastEvent    EventCursor `json:"-"`
+	FailureCount int         `json:"failure_count"`
+	DisabledAt   *time.Time  `json:"disabled_at"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+// WebhookDelivery is an event sent to a webhook, with the outcome of its last attempt. EventID is the ID of the
+// activity the event was recorded as, which replays of the delivery keep.
+type WebhookDelivery struct {
+	DeliveryID     uuid.UUID             `json:"delivery_id"`
+	WebhookID      uuid.UUID             `json:"webhook_id"`
+	EventID        uuid.UUID             `json:"event_id"`
+	EventType      WebhookEventType      `json:"event_type"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	NextAttemptAt  *time.Time            `json:"next_attempt_at"`
+	ResponseStatus *int                  `json:"response_status"`
+	LastError      string                `json:"last_error,omitempty"`
+	ReplayOf       *uuid.UUID            `json:"replay_of,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+	DeliveredAt    *time.Time            `json:"delivered_at"`
+}
+
+// DueWebhookDelivery is a delivery claimed to be sent, with the URL and the secret of its webhook.
+type DueWebhookDelivery struct {
+	Delivery WebhookDelivery
+	URL      string
+	Secret   string
+}
+
+// WebhookDeliveryAttempt is the outcome of sending a delivery: succeeded, pending until NextAttemptAt to be retried,
+// or failed for good.
+type WebhookDeliveryAttempt struct {
+	DeliveryID     uuid.UUID
+	WebhookID      uuid.UUID
+	Status         WebhookDeliveryStatus
+	ResponseStatus *int
+	Error          string
+	AttemptedAt    time.Time
+	NextAttemptAt  *time.Time
+}
+
+// ErrWebhookNotFound is returned when a webhook does not exist or belongs to another user.
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+// ErrWebhookDeliveryNotFound is returned when a webhook delivery does not exist or belongs to another user.
+var ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
@@ -1,10 +1,11 @@
 package models
 
+// AddInvoiceActivityRequest adds a comment to the activities of an invoice. Every other activity is recorded by the
+// system.
 type AddInvoiceActivityRequest struct {
-	InvoiceID   string `json:"invoice_id" binding:"required"`
-	UserID      string `json:"user_id" binding:"required"`
-	Title       string `json:"title" binding:"required"`
-	Description string `json:"description" binding:"required"`
+	InvoiceID string `json:"invoice_id" binding:"required"`
+	UserID    string `json:"user_id" binding:"required"`
+	Comment   string `json:"comment" binding:"required"`
 }
 
 type CreateUserRequest struct {
@@ -16,15 +17,31 @@ type CreateUserRequest struct {
 	ProfilePictureURL string `json:"profile_picture_url"`
 	PhoneNumber       string `json:"phone_number"`
 	Address           string `json:"address"`
+	BaseCurrency      string `json:"base_currency"`
 }
 
 type AddPaymentMethodRequest struct {
 	UserID        string `json:"user_id"  binding:"required"`
 	AccountName   string `json:"account_name"  binding:"required"`
+	AccountType   string `json:"account_type"`
 	AccountNumber string `json:"account_number"  binding:"required"`
+	RoutingNumber string `json:"routing_number"`
+	SortCode      string `json:"sort_code"`
 	BankName      string `json:"bank_name"  binding:"required"`
 	BankAddress   string `json:"bank_address" binding:"required"`
 	SwiftCode     string `json:"swift_code" binding:"required"`
+	IsDefault     bool   `json:"is_default"`
+}
+
+type UpdatePaymentMethodRequest struct {
+	AccountName   *string `json:"account_name"`
+	AccountType   *string `json:"account_type"`
+	AccountNumber *string `json:"account_number"`
+	RoutingNumber *string `json:"routing_number"`
+	SortCode      *string `json:"sort_code"`
+	BankName      *string `json:"bank_name"`
+	BankAddress   *string `json:"bank_address"`
+	SwiftCode     *string `json:"swift_code"`
 }
 
 type InvoiceInfo struct {
@@ -41,6 +58,7 @@ type InvoiceInfo struct {
 }
 
 type AddCustomerRequest struct {
+	UserID      string `json:"user_id" binding:"required"`
 	Name        string `json:"name" binding:"required"`
 	Email       string `json:"email" binding:"required"`
 	PhoneNumber string `json:"phone_number" binding:"required"`
@@ -48,16 +66,69 @@ type AddCustomerRequest struct {
 }
 
 type InvoiceItemDetails struct {
-	Name        string  `json:"name" binding:"required"`
-	Description string  `json:"description" binding:"required"`
-	Quantity    int     `json:"quantity" binding:"required"`
-	UnitPrice   float64 `json:"unit_price" binding:"required"`
-	TotalPrice  float64 `json:"total_price" binding:"required"`
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description" binding:"required"`
+	Quantity    int      `json:"quantity" binding:"required"`
+	UnitPrice   float64  `json:"unit_price" binding:"required"`
+	TotalPrice  float64  `json:"total_price" binding:"required"`
+	TaxRateIDs  []string `json:"tax_rate_ids"`
 }
 
 type CreateInvoiceRequest struct {
 	Invoice         InvoiceInfo          `json:"invoice" binding:"required"`
 	CustomerID      string               `json:"customer_id" binding:"required"`
-	PaymentMethodID string               `json:"payment_method_id" binding:"required"`
+	PaymentMethodID string               `json:"payment_method_id"`
 	InvoiceItems    []InvoiceItemDetails `json:"invoice_items" binding:"required"`
 }
+
+type AddReminderRuleRequest struct {
+	UserID     string `json:"user_id" binding:"required"`
+	DaysOffset *int   `json:"days_offset" binding:"required"`
+	Tone       string `json:"tone"`
+}
+
+type SetLateFeePolicyRequest struct {
+	UserID        string   `json:"user_id" binding:"required"`
+	FeeType       string   `json:"fee_type" binding:"required"`
+	FlatAmount    float64  `json:"flat_amount"`
+	InterestRate  float64  `json:"interest_rate"`
+	GraceDays     int      `json:"grace_days"`
+	MaxAmount     *float64 `json:"max_amount"`
+	MaxPercentage *float64 `json:"max_percentage"`
+	IsActive      *bool    `json:"is_active"`
+}
+
+type CreateTaxRateRequest struct {
+	UserID      string   `json:"user_id" binding:"required"`
+	Name        string   `json:"name" binding:"required"`
+	Rate        *float64 `json:"rate" binding:"required"`
+	IsInclusive bool     `json:"is_inclusive"`
+	IsCompound  bool     `json:"is_compound"`
+}
+
+type RecordPaymentRequest struct {
+	InvoiceID string  `json:"invoice_id" binding:"required"`
+	UserID    string  `json:"user_id" binding:"required"`
+	Amount    float64 `json:"amount" binding:"required"`
+	PaidOn    string  `json:"paid_on" binding:"required"`
+	Reference string  `json:"reference"`
+}
+
+// RefundPaymentRequest refunds an amount of a payment made online. The whole part of the payment not refunded yet is
+// refunded when no amount is given.
+type RefundPaymentRequest struct {
+	UserID string  `json:"user_id" binding:"required"`
+	Amount float64 `json:"amount"`
+}
+
+type ConfirmBankTransactionRequest struct {
+	InvoiceID string `json:"invoice_id" binding:"required"`
+}
+
+// CreateWebhookRequest subscribes a URL to invoice lifecycle events. A secret is generated when none is given.
+type CreateWebhookRequest struct {
+	UserID     string   `json:"user_id" binding:"required"`
+	URL        string   `json:"url" binding:"required"`
+	EventTypes []string `json:"event_types" binding:"required"`
+	Secret     string   `json:"secret"`
+}
@@ -0,0 +1,199 @@
+// Package pagination provides the list envelope and the opaque cursors shared by the list endpoints.
+//
+// Lists are paginated by keyset: a cursor holds the sort key and ID of the row a page starts or ends at, so the
+// next page starts right after that row whatever is inserted or deleted in between. Page numbers are still
+// accepted, and applied as an offset, for compatibility with existing clients.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultLimit is the number of rows in a page when a client does not ask for another limit.
+	DefaultLimit int32 = 10
+	// MaxLimit is the largest number of rows a client may ask for in a page.
+	MaxLimit int32 = 100
+)
+
+var (
+	// ErrInvalidCursor is returned when a cursor token was not issued by this package, or was issued for a list
+	// sorted another way.
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrInvalidLimit is returned when a limit is not a number between 1 and MaxLimit.
+	ErrInvalidLimit = fmt.Errorf("limit must be a number between 1 and %d", MaxLimit)
+	// ErrInvalidPage is returned when a page is not a positive number.
+	ErrInvalidPage = errors.New("page must be a positive number")
+)
+
+// Cursor marks the position of a row in a list sorted by a key and, to break ties, by a unique ID.
+// Before selects the rows preceding the position instead of those following it. Sort names the key and order of
+// the list the cursor was issued for, so that it is not applied to a list sorted another way.
+type Cursor struct {
+	Key    string    `json:"k"`
+	ID     uuid.UUID `json:"id"`
+	Sort   string    `json:"s,omitempty"`
+	Before bool      `json:"b,omitempty"`
+}
+
+// Encode returns the cursor as an opaque URL safe token.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor reads a cursor from a token returned by Encode.
+func DecodeCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// Request is the page of a list a client asked for: the rows around a cursor or, without one, a page number.
+type Request struct {
+	Limit  int32
+	Page   int32
+	Cursor *Cursor
+}
+
+// ParseRequest reads a request from the limit, page and cursor query parameters of a list endpoint. Empty
+// parameters default to the first page of DefaultLimit rows.
+func ParseRequest(limit, page, cursor string) (Request, error) {
+	request := Request{Limit: DefaultLimit, Page: 1}
+	if limit != "" {
+		value, err := strconv.ParseInt(limit, 10, 32)
+		if err != nil {
+			return request, ErrInvalidLimit
+		}
+		request.Limit = int32(value)
+	}
+	if page != "" {
+		value, err := strconv.ParseInt(page, 10, 32)
+		if err != nil {
+			return request, ErrInvalidPage
+		}
+		request.Page = int32(value)
+	}
+	if cursor != "" {
+		c, err := DecodeCursor(cursor)
+		if err != nil {
+			return request, err
+		}
+		request.Cursor = c
+	}
+	return request, request.Validate()
+}
+
+// Validate checks that the limit is between 1 and MaxLimit and that the page is positive, so that the offset
+// of the page can neither be negative nor overflow.
+func (r Request) Validate() error {
+	if r.Limit < 1 || r.Limit > MaxLimit {
+		return ErrInvalidLimit
+	}
+	if r.Page < 1 || int64(r.Page-1)*int64(r.Limit) > math.MaxInt32 {
+		return ErrInvalidPage
+	}
+	return nil
+}
+
+// Offset returns the number of rows to skip to reach the requested page. It is 0 when a cursor is set.
+func (r Request) Offset() int32 {
+	if r.Cursor != nil || r.Page < 1 {
+		return 0
+	}
+	return (r.Page - 1) * r.Limit
+}
+
+// Meta describes a page of a list: the total number of rows in the list, the limit applied, the largest limit
+// allowed, the page number when the page was not requested by cursor, and the cursors, and links built from them,
+// to the next and previous pages. Cursors and links are left empty when there is no such page.
+type Meta struct {
+	Total      int64  `json:"total"`
+	Limit      int32  `json:"limit"`
+	MaxLimit   int32  `json:"max_limit"`
+	Page       int32  `json:"page,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Next       string `json:"next,omitempty"`
+	Prev       string `json:"prev,omitempty"`
+}
+
+// Page is the envelope every list endpoint responds with: the rows of the page and, alongside them, its metadata.
+type Page[T any] struct {
+	Items []T `json:"items"`
+	Meta
+}
+
+// NewPage wraps the rows of a page and its metadata in the list envelope.
+func NewPage[T any](items []T, meta *Meta) *Page[T] {
+	return &Page[T]{Items: items, Meta: *meta}
+}
+
+// Paginate returns the rows of a page and its metadata from the rows fetched for a request. One row more than the
+// limit must be fetched, in list order or, before a cursor, in reverse order, so that Paginate can tell whether
+// another page follows. cursor returns the position of a row.
+func Paginate[T any](rows []T, req Request, total int64, cursor func(T) Cursor) ([]T, *Meta) {
+	more := len(rows) > int(req.Limit)
+	if more {
+		rows = rows[:req.Limit]
+	}
+
+	hasNext, hasPrev := more, req.Offset() > 0
+	if req.Cursor != nil {
+		hasPrev = true
+		if req.Cursor.Before {
+			slices.Reverse(rows)
+			hasNext, hasPrev = true, more
+		}
+	}
+
+	meta := &Meta{Total: total, Limit: req.Limit, MaxLimit: MaxLimit}
+	if req.Cursor == nil {
+		meta.Page = req.Page
+	}
+	if len(rows) == 0 {
+		return rows, meta
+	}
+	if hasNext {
+		meta.NextCursor = cursor(rows[len(rows)-1]).Encode()
+	}
+	if hasPrev {
+		prev := cursor(rows[0])
+		prev.Before = true
+		meta.PrevCursor = prev.Encode()
+	}
+	return rows, meta
+}
+
+// SetLinks sets the next and previous links of a page from the URL of the request that fetched it, replacing
+// its page number with the cursors.
+func (m *Meta) SetLinks(u *url.URL) {
+	link := func(cursor string) string {
+		if cursor == "" {
+			return ""
+		}
+		query := u.Query()
+		query.Del("page")
+		query.Set("cursor", cursor)
+		next := *u
+		next.RawQuery = query.Encode()
+		return next.String()
+	}
+	m.Next = link(m.NextCursor)
+	m.Prev = link(m.PrevCursor)
+}
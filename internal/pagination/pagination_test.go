@@ -0,0 +1,127 @@
+package pagination
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	cursor := Cursor{Key: "2024-06-01T10:00:00.123456Z", ID: uuid.New(), Sort: "created_at desc", Before: true}
+
+	decoded, err := DecodeCursor(cursor.Encode())
+	require.NoError(t, err)
+	require.Equal(t, cursor, *decoded)
+
+	for _, token := range []string{"not base64!", "bm90IGpzb24", Cursor{Key: "1"}.Encode()} {
+		_, err := DecodeCursor(token)
+		require.ErrorIs(t, err, ErrInvalidCursor)
+	}
+}
+
+func TestParseRequest(t *testing.T) {
+	request, err := ParseRequest("", "", "")
+	require.NoError(t, err)
+	require.Equal(t, Request{Limit: DefaultLimit, Page: 1}, request)
+
+	cursor := Cursor{Key: "1", ID: uuid.New()}
+	request, err = ParseRequest("25", "3", cursor.Encode())
+	require.NoError(t, err)
+	require.Equal(t, Request{Limit: 25, Page: 3, Cursor: &cursor}, request)
+
+	for _, tc := range []struct {
+		limit, page, cursor string
+		err                 error
+	}{
+		{"abc", "", "", ErrInvalidLimit},
+		{"0", "", "", ErrInvalidLimit},
+		{"-1", "", "", ErrInvalidLimit},
+		{strconv.Itoa(int(MaxLimit) + 1), "", "", ErrInvalidLimit},
+		{"99999999999", "", "", ErrInvalidLimit},
+		{"", "abc", "", ErrInvalidPage},
+		{"", "0", "", ErrInvalidPage},
+		{"", "-3", "", ErrInvalidPage},
+		{"100", "2147483647", "", ErrInvalidPage},
+		{"", "", "not a cursor", ErrInvalidCursor},
+	} {
+		_, err := ParseRequest(tc.limit, tc.page, tc.cursor)
+		require.ErrorIs(t, err, tc.err, "limit %q page %q cursor %q", tc.limit, tc.page, tc.cursor)
+	}
+}
+
+func TestOffset(t *testing.T) {
+	require.Equal(t, int32(0), Request{Limit: 10, Page: 1}.Offset())
+	require.Equal(t, int32(20), Request{Limit: 10, Page: 3}.Offset())
+	require.Equal(t, int32(0), Request{Limit: 10, Page: 3, Cursor: &Cursor{}}.Offset())
+}
+
+func TestPaginate(t *testing.T) {
+	ids := make([]uuid.UUID, 4)
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+	cursorOf := func(n int) Cursor { return Cursor{Key: strconv.Itoa(n), ID: ids[n]} }
+	decode := func(token string) Cursor {
+		cursor, err := DecodeCursor(token)
+		require.NoError(t, err)
+		return *cursor
+	}
+
+	t.Run("first page", func(t *testing.T) {
+		items, meta := Paginate([]int{0, 1, 2}, Request{Limit: 2, Page: 1}, 4, cursorOf)
+		require.Equal(t, []int{0, 1}, items)
+		require.Equal(t, int64(4), meta.Total)
+		require.Equal(t, int32(2), meta.Limit)
+		require.Equal(t, MaxLimit, meta.MaxLimit)
+		require.Equal(t, int32(1), meta.Page)
+		require.Equal(t, cursorOf(1), decode(meta.NextCursor))
+		require.Empty(t, meta.PrevCursor)
+	})
+
+	t.Run("page number", func(t *testing.T) {
+		items, meta := Paginate([]int{2, 3}, Request{Limit: 2, Page: 2}, 4, cursorOf)
+		require.Equal(t, []int{2, 3}, items)
+		require.Empty(t, meta.NextCursor)
+		require.Equal(t, Cursor{Key: "2", ID: ids[2], Before: true}, decode(meta.PrevCursor))
+	})
+
+	t.Run("after a cursor", func(t *testing.T) {
+		items, meta := Paginate([]int{2, 3}, Request{Limit: 2, Cursor: &Cursor{Key: "1", ID: ids[1]}}, 4, cursorOf)
+		require.Equal(t, []int{2, 3}, items)
+		require.Zero(t, meta.Page)
+		require.Empty(t, meta.NextCursor)
+		require.NotEmpty(t, meta.PrevCursor)
+	})
+
+	t.Run("before a cursor", func(t *testing.T) {
+		// rows before a cursor are fetched in reverse order
+		items, meta := Paginate([]int{1, 0}, Request{Limit: 2, Cursor: &Cursor{Key: "2", ID: ids[2], Before: true}}, 4, cursorOf)
+		require.Equal(t, []int{0, 1}, items)
+		require.Equal(t, cursorOf(1), decode(meta.NextCursor))
+		require.Empty(t, meta.PrevCursor)
+	})
+
+	t.Run("empty list", func(t *testing.T) {
+		items, meta := Paginate([]int{}, Request{Limit: 2, Page: 1}, 0, cursorOf)
+		require.Empty(t, items)
+		require.Empty(t, meta.NextCursor)
+		require.Empty(t, meta.PrevCursor)
+	})
+}
+
+func TestSetLinks(t *testing.T) {
+	u, err := url.Parse("/v1/invoices?sender_id=abc&page=2&limit=5")
+	require.NoError(t, err)
+
+	meta := &Meta{NextCursor: "next"}
+	meta.SetLinks(u)
+	require.Equal(t, "/v1/invoices?cursor=next&limit=5&sender_id=abc", meta.Next)
+	require.Empty(t, meta.Prev)
+
+	page := NewPage([]string{"a"}, meta)
+	require.Equal(t, []string{"a"}, page.Items)
+	require.Equal(t, meta.Next, page.Next)
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+type agingRepoImpl struct {
+	DBPool *pgxpool.Pool
+}
+
+// newAgingRepoImpl creates a new instance of the agingRepoImpl struct, which is used to load the outstanding
+// balances of invoices for accounts receivable aging from the database.
+func newAgingRepoImpl(dbPool *pgxpool.Pool) *agingRepoImpl {
+	return &agingRepoImpl{
+		DBPool: dbPool,
+	}
+}
+
+// GetOpenInvoices retrieves the invoices of a sender issued on or before the given date that still had a balance
+// left on that date, counting the late fees applied and the payments received up to it. The results can be limited
+// to a single customer and are ordered by customer name, currency and due date.
+func (a *agingRepoImpl) GetOpenInvoices(ctx context.Context, senderID uuid.UUID, asOf time.Time, customerID *uuid.UUID) ([]models.AgingInvoice, error) {
+	query := `
+        SELECT invoice_id, invoice_number, customer_id, customer_name, currency, issue_date, due_date, balance
+        FROM (
+            SELECT i.invoice_id, i.invoice_number, i.customer_id, c.name AS customer_name, i.currency,
+                   i.issue_date, i.due_date,
+                   i.final_amount + COALESCE(adj.total, 0) - COALESCE(p.total, 0) AS balance
+            FROM invoices i
+            JOIN customers c ON c.customer_id = i.customer_id
+            LEFT JOIN LATERAL (
+                SELECT SUM(amount) AS total FROM invoice_adjustments
+                WHERE invoice_id = i.invoice_id AND applied_on <= $2::date
+            ) adj ON true
+            LEFT JOIN LATERAL (
+                SELECT SUM(amount) AS total FROM payments
+                WHERE invoice_id = i.invoice_id AND paid_on <= $2::date
+            ) p ON true
+            WHERE i.sender_id = $1 AND i.status <> 'draft'
+              AND i.issue_date <= $2::date
+              AND ($3::uuid IS NULL OR i.customer_id = $3)
+        ) open
+        WHERE balance > 0
+        ORDER BY customer_name, customer_id, currency, due_date, invoice_number`
+
+	rows, err := a.DBPool.Query(ctx, query, senderID, asOf, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invoices := []models.AgingInvoice{}
+	for rows.Next() {
+		var invoice models.AgingInvoice
+		err := rows.Scan(
+			&invoice.InvoiceID, &invoice.InvoiceNumber, &invoice.CustomerID, &invoice.CustomerName, &invoice.Currency,
+			&invoice.IssueDate, &invoice.DueDate, &invoice.Balance,
+		)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, invoice)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return invoices, nil
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+type currencyRepoImpl struct {
+	DBPool *pgxpool.Pool
+}
+
+// newCurrencyRepoImpl creates a new instance of the currencyRepoImpl struct, which is used to interact with the
+// exchange rates and base currencies stored in the database.
+func newCurrencyRepoImpl(dbPool *pgxpool.Pool) *currencyRepoImpl {
+	return &currencyRepoImpl{
+		DBPool: dbPool,
+	}
+}
+
+// SaveExchangeRates stores the given exchange rates, replacing any rate already stored for the same currency pair
+// and effective date, and returns the number of rates saved. Issued invoices that were created before a rate for
+// their issue date was known get the rate locked into them now, as GetExchangeRate would find it.
+func (c *currencyRepoImpl) SaveExchangeRates(ctx context.Context, rates []models.ExchangeRate) (int, error) {
+	batch := &pgx.Batch{}
+	for _, rate := range rates {
+		batch.Queue(`
+            INSERT INTO exchange_rates (from_currency, to_currency, rate, effective_date, source)
+            VALUES ($1, $2, $3, $4, $5)
+            ON CONFLICT (from_currency, to_currency, effective_date) DO UPDATE
+            SET rate = EXCLUDED.rate, source = EXCLUDED.source, created_at = CURRENT_TIMESTAMP`,
+			rate.FromCurrency, rate.ToCurrency, rate.Rate, rate.EffectiveDate, rate.Source,
+		)
+	}
+
+	tx, err := c.DBPool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(ctx, `
+        UPDATE invoices i
+        SET exchange_rate = r.rate, updated_at = CURRENT_TIMESTAMP
+        FROM LATERAL (
+            SELECT rate
+            FROM (
+                SELECT rate, effective_date, 0 AS inverse
+                FROM exchange_rates
+                WHERE from_currency = i.currency AND to_currency = i.base_currency AND effective_date <= i.issue_date
+                UNION ALL
+                SELECT 1 / rate, effective_date, 1 AS inverse
+                FROM exchange_rates
+                WHERE from_currency = i.base_currency AND to_currency = i.currency AND effective_date <= i.issue_date
+            ) candidates
+            ORDER BY effective_date DESC, inverse
+            LIMIT 1
+        ) r
+        WHERE i.exchange_rate IS NULL AND i.status <> $1`,
+		models.InvoiceStatusDraft,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(rates), nil
+}
+
+// GetExchangeRate retrieves the latest rate converting the from currency into the to currency that took effect on or
+// before the given date. When only the opposite pair is stored, its inverse is returned.
+func (c *currencyRepoImpl) GetExchangeRate(ctx context.Context, from, to string, on time.Time) (*models.ExchangeRate, error) {
+	query := `
+        SELECT from_currency, to_currency, rate, effective_date, source
+        FROM (
+            SELECT from_currency, to_currency, rate, effective_date, source, 0 AS inverse
+            FROM exchange_rates
+            WHERE from_currency = $1 AND to_currency = $2 AND effective_date <= $3::date
+            UNION ALL
+            SELECT to_currency, from_currency, 1 / rate, effective_date, source, 1 AS inverse
+            FROM exchange_rates
+            WHERE from_currency = $2 AND to_currency = $1 AND effective_date <= $3::date
+        ) r
+        ORDER BY effective_date DESC, inverse
+        LIMIT 1`
+
+	var rate models.ExchangeRate
+	err := c.DBPool.QueryRow(ctx, query, from, to, on).Scan(
+		&rate.FromCurrency, &rate.ToCurrency, &rate.Rate, &rate.EffectiveDate, &rate.Source,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w from %s to %s on %s", models.ErrExchangeRateNotFound, from, to, on.Format("2006-01-02"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// GetBaseCurrency retrieves the base currency of the specified user.
+func (c *currencyRepoImpl) GetBaseCurrency(ctx context.Context, userID uuid.UUID) (string, error) {
+	var currency string
+	err := c.DBPool.QueryRow(ctx, `SELECT base_currency FROM users WHERE user_id = $1`, userID).Scan(&currency)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return "", err
+	}
+	return currency, nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+type dashboardRepoImpl struct {
+	DBPool *pgxpool.Pool
+}
+
+// newDashboardRepoImpl creates a new instance of the dashboardRepoImpl struct, which is used to load the summaries
+// shown on a sender's dashboard from the database.
+func newDashboardRepoImpl(dbPool *pgxpool.Pool) *dashboardRepoImpl {
+	return &dashboardRepoImpl{
+		DBPool: dbPool,
+	}
+}
+
+// openBalancesQuery sums the balance left on the unpaid invoices of a sender per currency, and the parts of it
+// that are past due or fall due within 7 and 30 days of the given date.
+const openBalancesQuery = `
+        WITH open AS (
+            SELECT i.currency, i.due_date,
+                   i.final_amount + COALESCE(a.total, 0) - COALESCE(p.total, 0) AS balance
+            FROM invoices i
+            LEFT JOIN LATERAL (
+                SELECT SUM(amount) AS total FROM invoice_adjustments WHERE invoice_id = i.invoice_id
+            ) a ON true
+            LEFT JOIN LATERAL (
+                SELECT SUM(amount) AS total FROM payments WHERE invoice_id = i.invoice_id
+            ) p ON true
+            WHERE i.sender_id = $1 AND i.status IN ('pending', 'overdue')
+        )
+        SELECT currency, COUNT(*),
+               COALESCE(SUM(balance), 0),
+               COALESCE(SUM(balance) FILTER (WHERE due_date < $2::date), 0),
+               COALESCE(SUM(balance) FILTER (WHERE due_date >= $2::date AND due_date <= $2::date + 7), 0),
+               COALESCE(SUM(balance) FILTER (WHERE due_date >= $2::date AND due_date <= $2::date + 30), 0)
+        FROM open
+        GROUP BY currency
+        ORDER BY currency`
+
+// GetDashboard loads the invoice totals per status, the open balances as of the given date and the latest invoices
+// and activities of a sender. All queries are sent in a single batch, so the dashboard costs one round-trip.
+func (d *dashboardRepoImpl) GetDashboard(ctx context.Context, senderID uuid.UUID, asOf time.Time, limit int32) (*models.Dashboard, error) {
+	totalsQuery, totalsArgs := totalsByStatusQuery(models.TotalsFilter{SenderID: senderID})
+
+	batch := &pgx.Batch{}
+	batch.Queue(totalsQuery, totalsArgs...)
+	batch.Queue(openBalancesQuery, senderID, asOf)
+	batch.Queue(recentInvoicesQuery, senderID, limit, 0)
+	batch.Queue(recentActivitiesQuery, senderID, limit, 0)
+
+	results := d.DBPool.SendBatch(ctx, batch)
+	defer results.Close()
+
+	dashboard := &models.Dashboard{SenderID: senderID, AsOf: asOf}
+	var err error
+
+	if dashboard.Statuses, err = readBatch(results, scanStatusTotals); err != nil {
+		return nil, err
+	}
+	if dashboard.Balances, err = readBatch(results, scanOpenBalances); err != nil {
+		return nil, err
+	}
+	if dashboard.RecentInvoices, err = readBatch(results, scanRecentInvoices); err != nil {
+		return nil, err
+	}
+	if dashboard.RecentActivities, err = readBatch(results, scanRecentActivities); err != nil {
+		return nil, err
+	}
+
+	return dashboard, nil
+}
+
+// readBatch reads the result of the next query in a batch with the given scanner.
+func readBatch[T any](results pgx.BatchResults, scan func(pgx.Rows) (T, error)) (T, error) {
+	rows, err := results.Query()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	defer rows.Close()
+
+	return scan(rows)
+}
+
+// scanOpenBalances reads the rows of openBalancesQuery into open balances.
+func scanOpenBalances(rows pgx.Rows) ([]models.OpenBalance, error) {
+	balances := []models.OpenBalance{}
+	for rows.Next() {
+		var balance models.OpenBalance
+		err := rows.Scan(
+			&balance.Currency, &balance.InvoiceCount, &balance.Outstanding, &balance.Overdue,
+			&balance.DueNext7Days, &balance.DueNext30Days,
+		)
+		if err != nil {
+			return nil, err
+		}
+		balances = append(balances, balance)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+// activityEventsChannel is the channel new activity events are notified on, by a trigger on the event log.
+const activityEventsChannel = "activity_events"
+
+type eventRepoImpl struct {
+	DBPool *pgxpool.Pool
+}
+
+// newEventRepoImpl creates a new instance of the eventRepoImpl struct, which is used to follow the activities
+// appended to the event log.
+func newEventRepoImpl(dbPool *pgxpool.Pool) *eventRepoImpl {
+	return &eventRepoImpl{
+		DBPool: dbPool,
+	}
+}
+
+// activityEventColumns are the columns an activity event is scanned from by scanActivityEvent. pgx has no codec for
+// xid8, so transaction IDs are read as numbers.
+const activityEventColumns = "sequence, xid::text::bigint, " + invoiceActivityColumns
+
+// scanActivityEvent scans a row of activityEventColumns into an activity event, and the columns selected after them
+// into extra.
+func scanActivityEvent(row pgx.Row, extra ...any) (*models.ActivityEvent, error) {
+	var event models.ActivityEvent
+	dest := append([]any{&event.Sequence, &event.Xid, &event.ActivityID, &event.InvoiceID, &event.UserID,
+		&event.EventType, &event.Payload, &event.Title, &event.Description, &event.CreatedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	event.Source = event.EventType.Source()
+	return &event, nil
+}
+
+// GetActivityEvents returns up to limit activity events of a user past the cursor, in the order of the transactions
+// that recorded them. Only the events of transactions older than every transaction still in progress are returned, so
+// no event can be recorded before the last one returned afterwards, and readers moving past it pass no event over.
+// Events recorded without an invoice before the activity feeds were merged are left out.
+func (e *eventRepoImpl) GetActivityEvents(ctx context.Context, userID uuid.UUID, after models.EventCursor, limit int32) ([]models.ActivityEvent, error) {
+	rows, err := e.DBPool.Query(ctx, `
+        SELECT `+activityEventColumns+`
+        FROM activity_events
+        WHERE user_id = $1 AND (xid, sequence) > ($2::bigint::text::xid8, $3)
+          AND xid < pg_snapshot_xmin(pg_current_snapshot()) AND invoice_id IS NOT NULL
+        ORDER BY xid, sequence
+        LIMIT $4`,
+		userID, after.Xid, after.Sequence, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.ActivityEvent{}
+	for rows.Next() {
+		event, err := scanActivityEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+	return events, rows.Err()
+}
+
+// GetActivityEventsSince returns up to limit activity events of a user recorded by transactions no older than the
+// position, in the order of their transactions, each with the position the stream resumes from once it is sent.
+// Events that are not committed yet are left out, so an event's position stops at the oldest transaction still in
+// progress, which may record events that belong before it. Events recorded without an invoice before the activity
+// feeds were merged are left out.
+func (e *eventRepoImpl) GetActivityEventsSince(ctx context.Context, userID uuid.UUID, position int64, limit int32) ([]models.ActivityEvent, error) {
+	rows, err := e.DBPool.Query(ctx, `
+        SELECT `+activityEventColumns+`, LEAST(xid, pg_snapshot_xmin(pg_current_snapshot()))::text::bigint
+        FROM activity_events
+        WHERE user_id = $1 AND xid >= $2::bigint::text::xid8 AND invoice_id IS NOT NULL
+        ORDER BY xid, sequence
+        LIMIT $3`,
+		userID, position, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.ActivityEvent{}
+	for rows.Next() {
+		var position int64
+		event, err := scanActivityEvent(rows, &position)
+		if err != nil {
+			return nil, err
+		}
+		event.Position = position
+		events = append(events, *event)
+	}
+	return events, rows.Err()
+}
+
+// GetActivityEvent returns the activity event with the sequence number, or nil when there is no such event.
+func (e *eventRepoImpl) GetActivityEvent(ctx context.Context, sequence int64) (*models.ActivityEvent, error) {
+	event, err := scanActivityEvent(e.DBPool.QueryRow(ctx, `
+        SELECT `+activityEventColumns+`
+        FROM activity_events
+        WHERE sequence = $1 AND invoice_id IS NOT NULL`,
+		sequence,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return event, err
+}
+
+// ListenActivityEvents listens for the activity events committed by any server on a connection of its own, calling
+// listening once it listens and notify with every event recorded from then on. It blocks until the context is done,
+// when it returns nil, or the connection fails.
+func (e *eventRepoImpl) ListenActivityEvents(ctx context.Context, listening func(), notify func(models.ActivityNotification)) error {
+	pooled, err := e.DBPool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// the connection listens until it is closed, so it is not given back to the pool
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+activityEventsChannel); err != nil {
+		return err
+	}
+	listening()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var event models.ActivityNotification
+		if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+			return fmt.Errorf("invalid activity notification %q: %w", notification.Payload, err)
+		}
+		notify(event)
+	}
+}
@@ -2,45 +2,110 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zde37/Numeris-Task/internal/encryption"
 	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/pagination"
 )
 
 type invoiceRepoImpl struct {
-	DBPool *pgxpool.Pool
+	DBPool  *pgxpool.Pool
+	Keyring *encryption.Keyring
 }
 
 // newInvoiceRepoImpl creates a new instance of the invoiceRepoImpl struct, which is used to interact with the
-// invoice-related data in the database.  
-func newInvoiceRepoImpl(dbPool *pgxpool.Pool) *invoiceRepoImpl {
+// invoice-related data in the database. Account numbers shown on invoices are decrypted with the keyring.
+func newInvoiceRepoImpl(dbPool *pgxpool.Pool, keyring *encryption.Keyring) *invoiceRepoImpl {
 	return &invoiceRepoImpl{
-		DBPool: dbPool,
+		DBPool:  dbPool,
+		Keyring: keyring,
 	}
 }
 
-// CreateInvoice creates a new invoice in the database, including the invoice details, invoice items, payment information, and related activities. 
-func (i *invoiceRepoImpl) CreateInvoice(ctx context.Context, invoice models.Invoice, items []models.InvoiceItem, customerID uuid.UUID, paymentInfo models.PaymentInformation) (uuid.UUID, error) {
+// CreateInvoice creates a new invoice in the database, including the invoice details, invoice items and their taxes, tax lines, payment information, and related activities. 
+func (i *invoiceRepoImpl) CreateInvoice(ctx context.Context, invoice models.Invoice, items []models.InvoiceItem, taxLines []models.InvoiceTaxLine, customerID uuid.UUID, paymentInfo models.PaymentInformation) (uuid.UUID, error) {
+	return createInvoice(ctx, i.DBPool, invoice, items, taxLines, customerID, paymentInfo)
+}
+
+// CreateInvoices creates the invoices in the database, like CreateInvoice, in a single transaction. Each invoice is
+// created in a savepoint, so that an invoice whose customer or payment method does not belong to the sender is
+// skipped, with its error at its index in the returned rejections, while the others are created. Any other error
+// rolls the whole batch back.
+func (i *invoiceRepoImpl) CreateInvoices(ctx context.Context, invoices []models.NewInvoice) ([]error, error) {
 	tx, err := i.DBPool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rejected := make([]error, len(invoices))
+	for idx, invoice := range invoices {
+		_, err := createInvoice(ctx, tx, invoice.Invoice, invoice.Items, invoice.TaxLines, invoice.Invoice.CustomerID, invoice.PaymentInfo)
+		if errors.Is(err, models.ErrCustomerNotOwned) || errors.Is(err, models.ErrPaymentMethodNotOwned) {
+			rejected[idx] = err
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return rejected, nil
+}
+
+// createInvoice inserts an invoice with all its related rows in a transaction started with the querier, which is a
+// savepoint when the querier is itself a transaction. It returns models.ErrCustomerNotOwned or
+// models.ErrPaymentMethodNotOwned when the customer or payment method does not belong to the sender.
+func createInvoice(ctx context.Context, db querier, invoice models.Invoice, items []models.InvoiceItem, taxLines []models.InvoiceTaxLine, customerID uuid.UUID, paymentInfo models.PaymentInformation) (uuid.UUID, error) {
+	tx, err := db.Begin(ctx)
 	if err != nil {
 		return uuid.Nil, err
 	}
 	defer tx.Rollback(ctx)
 
+	// check that the customer and payment method belong to the sender, locking them until the invoice is stored
+	var customerOwned, paymentMethodOwned bool
+	err = tx.QueryRow(ctx, `
+        SELECT EXISTS (SELECT 1 FROM customers WHERE customer_id = $2 AND user_id = $1 FOR SHARE),
+               EXISTS (SELECT 1 FROM user_payment_methods
+                       WHERE payment_method_id = $3 AND user_id = $1 AND deleted_at IS NULL FOR SHARE)`,
+		invoice.SenderID, customerID, paymentInfo.PaymentMethodID,
+	).Scan(&customerOwned, &paymentMethodOwned)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !customerOwned {
+		return uuid.Nil, models.ErrCustomerNotOwned
+	}
+	if !paymentMethodOwned {
+		return uuid.Nil, models.ErrPaymentMethodNotOwned
+	}
+
 	// insert invoice
 	query1 := `
         INSERT INTO invoices (invoice_id, invoice_number, sender_id, customer_id, issue_date, due_date, 
                               total_amount, discount_percentage, discounted_amount, final_amount, status, 
-                              currency, notes)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+                              currency, base_currency, exchange_rate, notes)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
         RETURNING invoice_id`
 
 	err = tx.QueryRow(ctx, query1,
 		invoice.InvoiceID, invoice.InvoiceNumber, invoice.SenderID, customerID,
 		invoice.IssueDate, invoice.DueDate, invoice.TotalAmount, invoice.DiscountPercentage,
-		invoice.DiscountedAmount, invoice.FinalAmount, invoice.Status, invoice.Currency, invoice.Notes,
+		invoice.DiscountedAmount, invoice.FinalAmount, invoice.Status, invoice.Currency, invoice.BaseCurrency,
+		invoice.ExchangeRate, invoice.Notes,
 	).Scan(&invoice.InvoiceID)
 	if err != nil {
 		return uuid.Nil, err
@@ -56,6 +121,33 @@ func (i *invoiceRepoImpl) CreateInvoice(ctx context.Context, invoice models.Invo
 		if err != nil {
 			return uuid.Nil, err
 		}
+
+		for _, tax := range item.Taxes {
+			_, err = tx.Exec(ctx, `
+                INSERT INTO invoice_item_taxes (item_tax_id, item_id, tax_rate_id, name, rate, is_inclusive, is_compound,
+                                                taxable_amount, tax_amount)
+                VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+				tax.ItemTaxID, item.ItemID, tax.TaxRateID, tax.Name, tax.Rate, tax.IsInclusive, tax.IsCompound,
+				tax.TaxableAmount, tax.TaxAmount,
+			)
+			if err != nil {
+				return uuid.Nil, err
+			}
+		}
+	}
+
+	// insert tax lines
+	for _, line := range taxLines {
+		_, err = tx.Exec(ctx, `
+            INSERT INTO invoice_tax_lines (tax_line_id, invoice_id, tax_rate_id, name, rate, is_inclusive, is_compound,
+                                           taxable_amount, tax_amount)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			line.TaxLineID, invoice.InvoiceID, line.TaxRateID, line.Name, line.Rate, line.IsInclusive, line.IsCompound,
+			line.TaxableAmount, line.TaxAmount,
+		)
+		if err != nil {
+			return uuid.Nil, err
+		}
 	}
 
 	// insert payment information
@@ -69,23 +161,13 @@ func (i *invoiceRepoImpl) CreateInvoice(ctx context.Context, invoice models.Invo
 	}
 
 	// create invoice activity
-	activityID := uuid.New()
-	_, err = tx.Exec(ctx, `
-        INSERT INTO invoice_activities (activity_id, invoice_id, user_id, title, description)
-        VALUES ($1, $2, $3, $4, $5)`,
-		activityID, invoice.InvoiceID, invoice.SenderID, "Invoice Creation", fmt.Sprintf("Created invoice %s", invoice.InvoiceNumber),
-	)
-	if err != nil {
-		return uuid.Nil, err
-	}
-
-	// add to recent activities
-	_, err = tx.Exec(ctx, `
-        INSERT INTO recent_activities (activity_id, user_id, title, description)
-        VALUES ($1, $2, $3, $4)`,
-		activityID, invoice.SenderID, "Invoice Creation", fmt.Sprintf("Created invoice %s", invoice.InvoiceNumber),
-	)
-	if err != nil {
+	activity := models.NewInvoiceActivity(invoice.InvoiceID, invoice.SenderID, models.ActivityEventInvoiceCreated,
+		models.InvoiceCreatedPayload{
+			InvoiceNumber: invoice.InvoiceNumber, Amount: invoice.FinalAmount, Currency: invoice.Currency,
+			Status: models.InvoiceStatus(invoice.Status),
+		},
+		"Invoice Creation", fmt.Sprintf("Created invoice %s", invoice.InvoiceNumber))
+	if err := recordActivity(ctx, tx, activity); err != nil {
 		return uuid.Nil, err
 	}
 
@@ -96,18 +178,22 @@ func (i *invoiceRepoImpl) CreateInvoice(ctx context.Context, invoice models.Invo
 	return invoice.InvoiceID, nil
 }
 
-// GetInvoiceDetails retrieves the details of an invoice, including the invoice information, invoice items, and invoice activities. 
+// GetInvoiceDetails retrieves the details of an invoice, including the invoice information, invoice items and their taxes,
+// tax lines, adjustments, payments and invoice activities. 
 func (i *invoiceRepoImpl) GetInvoiceDetails(ctx context.Context, invoiceID uuid.UUID) (*models.InvoiceDetails, error) {
 	var details models.InvoiceDetails
+	var account storedAccountNumber
 
 	// get invoice information
 	err := i.DBPool.QueryRow(ctx, `
         SELECT i.invoice_id, i.invoice_number, i.sender_id, i.customer_id, i.issue_date, i.due_date, 
                i.total_amount, i.discount_percentage, i.discounted_amount, i.final_amount, i.status, 
-               i.currency, i.notes, i.created_at, i.updated_at,
+               i.currency, i.base_currency, i.exchange_rate, i.notes, i.created_at, i.updated_at,
                s.first_name || ' ' || s.last_name AS sender_name, s.email AS sender_email, s.phone_number AS sender_phone_number, s.address AS sender_address,
                c.name AS customer_name, c.email AS customer_email, c.phone_number AS customer_phone_number,
-               pm.payment_method_id, pm.user_id, pm.account_name, pm.account_number, pm.bank_name, pm.bank_address, pm.swift_code
+               pm.payment_method_id, pm.user_id, pm.account_name, pm.account_number, pm.account_number_ciphertext,
+               pm.account_number_data_key, pm.account_number_key_id, '****' || pm.account_number_last4, pm.account_type,
+               COALESCE(pm.routing_number, ''), COALESCE(pm.sort_code, ''), pm.bank_name, pm.bank_address, pm.swift_code
         FROM invoices i
         JOIN users s ON i.sender_id = s.user_id
         JOIN customers c ON i.customer_id = c.customer_id
@@ -119,15 +205,21 @@ func (i *invoiceRepoImpl) GetInvoiceDetails(ctx context.Context, invoiceID uuid.
 		&details.Invoice.InvoiceID, &details.Invoice.InvoiceNumber, &details.Invoice.SenderID, &details.Invoice.CustomerID,
 		&details.Invoice.IssueDate, &details.Invoice.DueDate, &details.Invoice.TotalAmount, &details.Invoice.DiscountPercentage,
 		&details.Invoice.DiscountedAmount, &details.Invoice.FinalAmount, &details.Invoice.Status, &details.Invoice.Currency,
-		&details.Invoice.Notes, &details.Invoice.CreatedAt, &details.Invoice.UpdatedAt, &details.SenderName, &details.SenderEmail,
+		&details.Invoice.BaseCurrency, &details.Invoice.ExchangeRate, &details.Invoice.Notes, &details.Invoice.CreatedAt, &details.Invoice.UpdatedAt, &details.SenderName, &details.SenderEmail,
 		&details.SenderPhoneNumber, &details.SenderAddress, &details.CustomerName, &details.CustomerEmail, &details.CustomerPhoneNumber,
 		&details.PaymentInformation.PaymentMethodID, &details.PaymentInformation.UserID, &details.PaymentInformation.AccountName,
-		&details.PaymentInformation.AccountNumber, &details.PaymentInformation.BankName, &details.PaymentInformation.BankAddress,
+		&account.plaintext, &account.envelope.Ciphertext, &account.envelope.DataKey, &account.keyID,
+		&details.PaymentInformation.MaskedAccountNumber, &details.PaymentInformation.AccountType, &details.PaymentInformation.RoutingNumber,
+		&details.PaymentInformation.SortCode, &details.PaymentInformation.BankName, &details.PaymentInformation.BankAddress,
 		&details.PaymentInformation.SwiftCode,
 	)
 	if err != nil {
 		return nil, err
 	}
+	details.PaymentInformation.AccountNumber, err = account.decrypt(i.Keyring)
+	if err != nil {
+		return nil, err
+	}
 
 	// get invoice items
 	rows, err := i.DBPool.Query(ctx, `
@@ -154,12 +246,46 @@ func (i *invoiceRepoImpl) GetInvoiceDetails(ctx context.Context, invoiceID uuid.
 		return nil, err
 	}
 
-	// get invoice activities
+	// get invoice item taxes
 	rows, err = i.DBPool.Query(ctx, `
-        SELECT activity_id, invoice_id, user_id, title, description, created_at
-        FROM invoice_activities
+        SELECT t.item_tax_id, t.item_id, t.tax_rate_id, t.name, t.rate, t.is_inclusive, t.is_compound, t.taxable_amount, t.tax_amount
+        FROM invoice_item_taxes t
+        JOIN invoice_items it ON t.item_id = it.item_id
+        WHERE it.invoice_id = $1
+        ORDER BY t.is_compound, t.name`,
+		invoiceID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	itemIndex := make(map[uuid.UUID]int, len(details.Items))
+	for idx, item := range details.Items {
+		itemIndex[item.ItemID] = idx
+	}
+	for rows.Next() {
+		var tax models.InvoiceItemTax
+		err := rows.Scan(&tax.ItemTaxID, &tax.ItemID, &tax.TaxRateID, &tax.Name, &tax.Rate, &tax.IsInclusive, &tax.IsCompound,
+			&tax.TaxableAmount, &tax.TaxAmount)
+		if err != nil {
+			return nil, err
+		}
+		if idx, ok := itemIndex[tax.ItemID]; ok {
+			details.Items[idx].Taxes = append(details.Items[idx].Taxes, tax)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// get invoice tax lines
+	rows, err = i.DBPool.Query(ctx, `
+        SELECT tax_line_id, invoice_id, tax_rate_id, name, rate, is_inclusive, is_compound, taxable_amount, tax_amount
+        FROM invoice_tax_lines
         WHERE invoice_id = $1
-        ORDER BY created_at`,
+        ORDER BY is_compound, name`,
 		invoiceID,
 	)
 	if err != nil {
@@ -168,65 +294,473 @@ func (i *invoiceRepoImpl) GetInvoiceDetails(ctx context.Context, invoiceID uuid.
 	defer rows.Close()
 
 	for rows.Next() {
-		var activity models.InvoiceActivity
-		err := rows.Scan(&activity.ActivityID, &activity.InvoiceID, &activity.UserID, &activity.Title, &activity.Description, &activity.CreatedAt)
+		var line models.InvoiceTaxLine
+		err := rows.Scan(&line.TaxLineID, &line.InvoiceID, &line.TaxRateID, &line.Name, &line.Rate, &line.IsInclusive, &line.IsCompound,
+			&line.TaxableAmount, &line.TaxAmount)
+		if err != nil {
+			return nil, err
+		}
+		details.TaxLines = append(details.TaxLines, line)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// get invoice adjustments
+	rows, err = i.DBPool.Query(ctx, `
+        SELECT adjustment_id, invoice_id, COALESCE(policy_id, '00000000-0000-0000-0000-000000000000'), adjustment_type, amount,
+               COALESCE(description, ''), applied_on, created_at
+        FROM invoice_adjustments
+        WHERE invoice_id = $1
+        ORDER BY applied_on, created_at`,
+		invoiceID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var adjustment models.InvoiceAdjustment
+		err := rows.Scan(&adjustment.AdjustmentID, &adjustment.InvoiceID, &adjustment.PolicyID, &adjustment.AdjustmentType,
+			&adjustment.Amount, &adjustment.Description, &adjustment.AppliedOn, &adjustment.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		details.Adjustments = append(details.Adjustments, adjustment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// get invoice payments
+	rows, err = i.DBPool.Query(ctx, `
+        SELECT payment_id, invoice_id, amount, paid_on, COALESCE(reference, ''), refunded_payment_id, created_at
+        FROM payments
+        WHERE invoice_id = $1
+        ORDER BY paid_on, created_at`,
+		invoiceID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var payment models.Payment
+		err := rows.Scan(&payment.PaymentID, &payment.InvoiceID, &payment.Amount, &payment.PaidOn, &payment.Reference,
+			&payment.RefundedPaymentID, &payment.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
-		details.Activities = append(details.Activities, activity)
+		details.Payments = append(details.Payments, payment)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
+	// get invoice activities
+	rows, err = i.DBPool.Query(ctx, `
+        SELECT `+invoiceActivityColumns+`
+        FROM activity_events
+        WHERE invoice_id = $1
+        ORDER BY created_at, activity_id`,
+		invoiceID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	details.Activities, err = scanInvoiceActivities(rows)
+	if err != nil {
+		return nil, err
+	}
+
 	return &details, nil
 }
 
 // AddInvoiceActivity adds a new activity to an invoice. 
 func (i *invoiceRepoImpl) AddInvoiceActivity(ctx context.Context, activity models.InvoiceActivity) (uuid.UUID, error) {
-	query := `
-        INSERT INTO invoice_activities (activity_id, invoice_id, user_id, title, description)
-        VALUES ($1, $2, $3, $4, $5)
-		RETURNING activity_id
-	`
-	err := i.DBPool.QueryRow(ctx, query, activity.ActivityID, activity.InvoiceID, activity.UserID,
-		activity.Title, activity.Description).Scan(&activity.ActivityID)
-	if err != nil {
+	if err := recordActivity(ctx, i.DBPool, activity); err != nil {
 		return uuid.Nil, err
 	}
 	return activity.ActivityID, nil
 }
 
-// GetTotalByStatus retrieves the total amount and count of invoices with the specified status. 
-func (i *invoiceRepoImpl) GetTotalByStatus(ctx context.Context, status models.InvoiceStatus) (totalAmount float64, count int, err error) {
-	query := `SELECT COUNT(*) as count, COALESCE(SUM(final_amount), 0) as total_amount FROM invoices WHERE status = $1`
+// MarkOverdueInvoices marks up to limit pending invoices due before the given date as overdue, recording the status
+// change of each as an activity, and returns how many were marked. Invoices being marked by a concurrent call are
+// skipped. Overdue invoices only move on to paid, once recordPayment sees them paid in full.
+func (i *invoiceRepoImpl) MarkOverdueInvoices(ctx context.Context, asOf time.Time, limit int32) (int, error) {
+	tx, err := i.DBPool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
 
-	err = i.DBPool.QueryRow(ctx, query, status).Scan(&count, &totalAmount)
+	rows, err := tx.Query(ctx, `
+        UPDATE invoices
+        SET status = $1, updated_at = CURRENT_TIMESTAMP
+        WHERE invoice_id IN (
+            SELECT invoice_id
+            FROM invoices
+            WHERE status = $2 AND due_date < $3::date
+            ORDER BY due_date, invoice_id
+            LIMIT $4
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING invoice_id, sender_id, invoice_number`,
+		models.InvoiceStatusOverDue, models.InvoiceStatusPending, asOf, limit,
+	)
 	if err != nil {
-		return 0, 0, err
+		return 0, err
+	}
+	var activities []models.InvoiceActivity
+	for rows.Next() {
+		var invoiceID, senderID uuid.UUID
+		var invoiceNumber string
+		if err := rows.Scan(&invoiceID, &senderID, &invoiceNumber); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		activities = append(activities, models.NewInvoiceActivity(invoiceID, senderID, models.ActivityEventStatusChanged,
+			models.StatusChangedPayload{From: models.InvoiceStatusPending, To: models.InvoiceStatusOverDue},
+			"Status Change", fmt.Sprintf("Invoice %s was marked as overdue", invoiceNumber)))
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
 	}
 
-	return totalAmount, count, nil
+	for _, activity := range activities {
+		if err := recordActivity(ctx, tx, activity); err != nil {
+			return 0, err
+		}
+	}
+	return len(activities), tx.Commit(ctx)
 }
 
-// GetRecentInvoices retrieves a list of the most recent invoices for the specified sender, with optional pagination. 
-func (i *invoiceRepoImpl) GetRecentInvoices(ctx context.Context, senderID uuid.UUID, limit, offset int32) ([]models.Invoice, error) {
-	query := `
-        SELECT invoice_id, invoice_number, sender_id, customer_id, issue_date, due_date, 
-               total_amount, discount_percentage, discounted_amount, final_amount, status, 
-               currency, notes, created_at, updated_at 
-        FROM invoices 
-        WHERE sender_id = $1 
-        ORDER BY created_at DESC 
-        LIMIT $2 OFFSET $3`
+// invoiceActivityColumns are the columns an invoice activity is scanned from by scanInvoiceActivities.
+const invoiceActivityColumns = "activity_id, invoice_id, user_id, event_type, payload, title, description, created_at"
+
+// recordActivity appends an activity of an invoice to the event log, which both the timeline of the invoice and the
+// recent activities of the user are read from. Activities are timed by the clock rather than by the start of the
+// transaction, so the events recorded in one transaction keep their order.
+func recordActivity(ctx context.Context, db querier, activity models.InvoiceActivity) error {
+	payload := activity.Payload
+	if payload == nil {
+		payload = json.RawMessage(`{}`)
+	}
+	_, err := db.Exec(ctx, `
+        INSERT INTO activity_events (activity_id, invoice_id, user_id, event_type, payload, title, description,
+                                     created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, clock_timestamp())`,
+		activity.ActivityID, activity.InvoiceID, activity.UserID, activity.EventType, payload, activity.Title,
+		activity.Description,
+	)
+	return err
+}
 
-	rows, err := i.DBPool.Query(ctx, query, senderID, limit, offset)
+// GetTotalByStatus retrieves the count and total amount of the filtered invoices of a sender for each status,
+// grouped by currency, along with the totals converted into the sender's base currency at the rate locked on
+// every invoice. Invoices without a locked rate are left out of the converted totals. Only statuses with at least
+// one invoice are returned.
+func (i *invoiceRepoImpl) GetTotalByStatus(ctx context.Context, filter models.TotalsFilter) ([]models.StatusTotals, error) {
+	query, args := totalsByStatusQuery(filter)
+	rows, err := i.DBPool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	return scanStatusTotals(rows)
+}
+
+// totalsByStatusQuery builds the query summarizing the filtered invoices of a sender per status and currency.
+func totalsByStatusQuery(filter models.TotalsFilter) (string, []any) {
+	dateColumn := "issue_date"
+	if filter.DateField == models.TotalsDateFieldDue {
+		dateColumn = "due_date"
+	}
+
+	query := fmt.Sprintf(`
+        WITH filtered AS (
+            SELECT status, currency, base_currency, exchange_rate, final_amount
+            FROM invoices
+            WHERE sender_id = $1
+              AND ($2::uuid IS NULL OR customer_id = $2)
+              AND ($3::date IS NULL OR %[1]s >= $3)
+              AND ($4::date IS NULL OR %[1]s <= $4)
+        )
+        SELECT status, 'currency' AS kind, currency, COUNT(*), COALESCE(SUM(final_amount), 0)
+        FROM filtered
+        GROUP BY status, currency
+        UNION ALL
+        SELECT status, 'base' AS kind, base_currency, COUNT(*), COALESCE(ROUND(SUM(final_amount * exchange_rate), 2), 0)
+        FROM filtered
+        WHERE exchange_rate IS NOT NULL
+        GROUP BY status, base_currency
+        ORDER BY 1, 2, 3`, dateColumn)
+
+	return query, []any{filter.SenderID, filter.CustomerID, filter.From, filter.To}
+}
+
+// scanStatusTotals reads the rows of the query built by totalsByStatusQuery into the totals of each status.
+func scanStatusTotals(rows pgx.Rows) ([]models.StatusTotals, error) {
+	totals := []models.StatusTotals{}
+	for rows.Next() {
+		var status models.InvoiceStatus
+		var kind string
+		var total models.CurrencyTotal
+		if err := rows.Scan(&status, &kind, &total.Currency, &total.Count, &total.TotalAmount); err != nil {
+			return nil, err
+		}
+
+		if len(totals) == 0 || totals[len(totals)-1].Status != status {
+			totals = append(totals, models.StatusTotals{
+				Status:         status,
+				Currencies:     []models.CurrencyTotal{},
+				BaseCurrencies: []models.CurrencyTotal{},
+			})
+		}
+		current := &totals[len(totals)-1]
+		if kind == "base" {
+			current.BaseCurrencies = append(current.BaseCurrencies, total)
+			continue
+		}
+		current.Count += total.Count
+		current.Currencies = append(current.Currencies, total)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+// invoiceColumns are the columns of an invoice read by scanRecentInvoices.
+const invoiceColumns = `invoice_id, invoice_number, sender_id, customer_id, issue_date, due_date,
+               total_amount, discount_percentage, discounted_amount, final_amount, status,
+               currency, base_currency, exchange_rate, notes, created_at, updated_at`
+
+// recentInvoicesQuery selects the most recent invoices of a sender, with pagination.
+const recentInvoicesQuery = `
+        SELECT ` + invoiceColumns + `
+        FROM invoices
+        WHERE sender_id = $1
+        ORDER BY created_at DESC
+        LIMIT $2 OFFSET $3`
+
+// GetRecentInvoices retrieves a page of the most recent invoices for the specified sender, along with their total count.
+func (i *invoiceRepoImpl) GetRecentInvoices(ctx context.Context, senderID uuid.UUID, page pagination.Request) ([]models.Invoice, *pagination.Meta, error) {
+	query := listQuery{
+		columns:    invoiceColumns,
+		from:       "invoices",
+		conditions: []string{"sender_id = $1"},
+		args:       []any{senderID},
+		key:        "created_at",
+		keyType:    "timestamptz",
+		id:         "invoice_id",
+		descending: true,
+	}
+	return fetchPage(ctx, i.DBPool, query, page, scanRecentInvoices, func(invoice models.Invoice) pagination.Cursor {
+		return pagination.Cursor{Key: timeKey(invoice.CreatedAt), ID: invoice.InvoiceID}
+	})
+}
+
+// invoiceSortKeys maps the fields invoices can be sorted by to their column, the Postgres type of the column and
+// the value of the column in an invoice, formatted as a cursor key.
+var invoiceSortKeys = map[models.InvoiceSortField]struct {
+	column  string
+	keyType string
+	value   func(models.Invoice) string
+}{
+	models.InvoiceSortFieldDueDate: {"due_date", "date", func(invoice models.Invoice) string {
+		return invoice.DueDate.Format("2006-01-02")
+	}},
+	models.InvoiceSortFieldAmount: {"final_amount", "numeric", func(invoice models.Invoice) string {
+		return strconv.FormatFloat(invoice.FinalAmount, 'f', -1, 64)
+	}},
+	models.InvoiceSortFieldNumber: {"invoice_number", "text", func(invoice models.Invoice) string {
+		return invoice.InvoiceNumber
+	}},
+	models.InvoiceSortFieldCreatedAt: {"created_at", "timestamptz", func(invoice models.Invoice) string {
+		return timeKey(invoice.CreatedAt)
+	}},
+}
+
+// SearchInvoices retrieves a page of the invoices of a sender matching every filter that is set, sorted by the
+// requested field and order, along with the number of invoices matching. The invoice ID breaks ties so that
+// pages do not overlap.
+func (i *invoiceRepoImpl) SearchInvoices(ctx context.Context, filter models.InvoiceFilter, page pagination.Request) ([]models.Invoice, *pagination.Meta, error) {
+	conditions, args := invoiceFilterConditions(filter)
+
+	sortKey, ok := invoiceSortKeys[filter.SortBy]
+	if !ok {
+		sortKey = invoiceSortKeys[models.InvoiceSortFieldCreatedAt]
+	}
+
+	query := listQuery{
+		columns:    invoiceColumns,
+		from:       "invoices",
+		conditions: conditions,
+		args:       args,
+		key:        sortKey.column,
+		keyType:    sortKey.keyType,
+		id:         "invoice_id",
+		descending: filter.SortOrder != models.SortOrderAsc,
+	}
+	return fetchPage(ctx, i.DBPool, query, page, scanRecentInvoices, func(invoice models.Invoice) pagination.Cursor {
+		return pagination.Cursor{Key: sortKey.value(invoice), ID: invoice.InvoiceID}
+	})
+}
+
+// invoiceFilterConditions returns the conditions, on the columns of the invoices table, that select the invoices
+// matching every filter that is set, and the args they refer to by position.
+func invoiceFilterConditions(filter models.InvoiceFilter) ([]string, []any) {
+	args := []any{filter.SenderID}
+	conditions := []string{"sender_id = $1"}
+	where := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for idx, status := range filter.Statuses {
+			statuses[idx] = string(status)
+		}
+		where("status = ANY($%d)", statuses)
+	}
+	if filter.CustomerID != nil {
+		where("customer_id = $%d", *filter.CustomerID)
+	}
+	if filter.Currency != "" {
+		where("currency = $%d", filter.Currency)
+	}
+	if filter.IssueFrom != nil {
+		where("issue_date >= $%d", *filter.IssueFrom)
+	}
+	if filter.IssueTo != nil {
+		where("issue_date <= $%d", *filter.IssueTo)
+	}
+	if filter.DueFrom != nil {
+		where("due_date >= $%d", *filter.DueFrom)
+	}
+	if filter.DueTo != nil {
+		where("due_date <= $%d", *filter.DueTo)
+	}
+	if filter.MinAmount != nil {
+		where("final_amount >= $%d", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		where("final_amount <= $%d", *filter.MaxAmount)
+	}
+	if filter.NumberPrefix != "" {
+		where("invoice_number LIKE $%d", escapeLike(filter.NumberPrefix)+"%")
+	}
+	if filter.Search != "" {
+		where(`(notes ILIKE $%[1]d OR EXISTS (
+                SELECT 1 FROM invoice_items it WHERE it.invoice_id = invoices.invoice_id AND it.name ILIKE $%[1]d))`,
+			"%"+escapeLike(filter.Search)+"%")
+	}
+
+	return conditions, args
+}
+
+// ExportInvoices streams the invoices of a sender matching every filter that is set to fn, sorted like
+// SearchInvoices, each with the name of its customer. With withItems, an invoice is exported once per item, or once
+// without an item when it has none. Rows are read from the database as fn consumes them, so that an export is never
+// held in memory at once; the first error fn returns stops the export and is returned.
+func (i *invoiceRepoImpl) ExportInvoices(ctx context.Context, filter models.InvoiceFilter, withItems bool, fn func(models.InvoiceExportRow) error) error {
+	conditions, args := invoiceFilterConditions(filter)
+
+	sortKey, ok := invoiceSortKeys[filter.SortBy]
+	if !ok {
+		sortKey = invoiceSortKeys[models.InvoiceSortFieldCreatedAt]
+	}
+	order := "DESC"
+	if filter.SortOrder == models.SortOrderAsc {
+		order = "ASC"
+	}
+
+	items := "NULL::uuid, NULL::text, NULL::text, NULL::int, NULL::numeric, NULL::numeric"
+	join, itemOrder := "", ""
+	if withItems {
+		items = "it.item_id, it.name, it.description, it.quantity, it.unit_price, it.total_price"
+		join = "LEFT JOIN invoice_items it ON it.invoice_id = i.invoice_id"
+		itemOrder = ", it.created_at, it.item_id"
+	}
+
+	query := fmt.Sprintf(`
+        WITH filtered AS (
+            SELECT %s
+            FROM invoices
+            WHERE %s
+        )
+        SELECT i.*, c.name, %s
+        FROM filtered i
+        JOIN customers c ON c.customer_id = i.customer_id
+        %s
+        ORDER BY i.%s %s, i.invoice_id %[6]s%[7]s`,
+		invoiceColumns, strings.Join(conditions, " AND "), items, join, sortKey.column, order, itemOrder)
+
+	rows, err := i.DBPool.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			row         models.InvoiceExportRow
+			itemID      *uuid.UUID
+			name        *string
+			description *string
+			quantity    *int
+			unitPrice   *float64
+			totalPrice  *float64
+		)
+		err := rows.Scan(
+			&row.InvoiceID, &row.InvoiceNumber, &row.SenderID, &row.CustomerID,
+			&row.IssueDate, &row.DueDate, &row.TotalAmount, &row.DiscountPercentage,
+			&row.DiscountedAmount, &row.FinalAmount, &row.Status, &row.Currency,
+			&row.BaseCurrency, &row.ExchangeRate, &row.Notes, &row.CreatedAt, &row.UpdatedAt,
+			&row.CustomerName, &itemID, &name, &description, &quantity, &unitPrice, &totalPrice,
+		)
+		if err != nil {
+			return err
+		}
+		if itemID != nil {
+			row.Item = &models.InvoiceItem{
+				ItemID:      *itemID,
+				InvoiceID:   row.InvoiceID,
+				Name:        *name,
+				Description: *description,
+				Quantity:    *quantity,
+				UnitPrice:   *unitPrice,
+				TotalPrice:  *totalPrice,
+			}
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern so that the text is matched literally.
+func escapeLike(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(text)
+}
+
+// scanRecentInvoices reads the rows of recentInvoicesQuery, or of any query selecting the same columns, into invoices.
+func scanRecentInvoices(rows pgx.Rows) ([]models.Invoice, error) {
 	invoices := []models.Invoice{}
 	for rows.Next() {
 		var invoice models.Invoice
@@ -234,7 +768,7 @@ func (i *invoiceRepoImpl) GetRecentInvoices(ctx context.Context, senderID uuid.U
 			&invoice.InvoiceID, &invoice.InvoiceNumber, &invoice.SenderID, &invoice.CustomerID,
 			&invoice.IssueDate, &invoice.DueDate, &invoice.TotalAmount, &invoice.DiscountPercentage,
 			&invoice.DiscountedAmount, &invoice.FinalAmount, &invoice.Status, &invoice.Currency,
-			&invoice.Notes, &invoice.CreatedAt, &invoice.UpdatedAt,
+			&invoice.BaseCurrency, &invoice.ExchangeRate, &invoice.Notes, &invoice.CreatedAt, &invoice.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -249,26 +783,42 @@ func (i *invoiceRepoImpl) GetRecentInvoices(ctx context.Context, senderID uuid.U
 	return invoices, nil
 }
 
-// GetRecentActivities retrieves a list of recent activities for the specified user, with pagination. 
-func (i *invoiceRepoImpl) GetRecentActivities(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]models.RecentActivity, error) {
-	query := `
-        SELECT activity_id, user_id, title, description, created_at 
-        FROM recent_activities 
-        WHERE user_id = $1 
-        ORDER BY created_at DESC 
+// recentActivitiesQuery selects the most recent activities of a user, with pagination.
+const recentActivitiesQuery = `
+        SELECT activity_id, invoice_id, user_id, event_type, title, description, created_at
+        FROM activity_events
+        WHERE user_id = $1
+        ORDER BY created_at DESC, activity_id DESC
         LIMIT $2 OFFSET $3`
 
-	rows, err := i.DBPool.Query(ctx, query, userID, limit, offset)
-	if err != nil {
-		return nil, err
+// GetRecentActivities retrieves a page of the recent activities of the specified user, restricted to the event types
+// and source of the filter, along with their total count. They are read from the same event log as the activities of
+// each invoice, so the two never disagree.
+func (i *invoiceRepoImpl) GetRecentActivities(ctx context.Context, userID uuid.UUID, filter models.ActivityFilter, page pagination.Request) ([]models.RecentActivity, *pagination.Meta, error) {
+	query := listQuery{
+		columns:    "activity_id, invoice_id, user_id, event_type, title, description, created_at",
+		from:       "activity_events",
+		conditions: []string{"user_id = $1"},
+		args:       []any{userID},
+		key:        "created_at",
+		keyType:    "timestamptz",
+		id:         "activity_id",
+		descending: true,
 	}
-	defer rows.Close()
+	query = filterActivities(query, filter)
+	return fetchPage(ctx, i.DBPool, query, page, scanRecentActivities, func(activity models.RecentActivity) pagination.Cursor {
+		return pagination.Cursor{Key: timeKey(activity.CreatedAt), ID: activity.ActivityID}
+	})
+}
 
+// scanRecentActivities reads the rows of recentActivitiesQuery into activities.
+func scanRecentActivities(rows pgx.Rows) ([]models.RecentActivity, error) {
 	activities := []models.RecentActivity{}
 	for rows.Next() {
 		var activity models.RecentActivity
 		err := rows.Scan(
-			&activity.ActivityID, &activity.UserID, &activity.Title, &activity.Description, &activity.CreatedAt,
+			&activity.ActivityID, &activity.InvoiceID, &activity.UserID, &activity.EventType, &activity.Title,
+			&activity.Description, &activity.CreatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -283,28 +833,57 @@ func (i *invoiceRepoImpl) GetRecentActivities(ctx context.Context, userID uuid.U
 	return activities, nil
 }
 
-// GetInvoiceActivities retrieves the recent activities associated with a specific invoice for a given user. 
-func (i *invoiceRepoImpl) GetInvoiceActivities(ctx context.Context, userID, invoiceID uuid.UUID, limit, offset int32) ([]models.InvoiceActivity, error) {
-	query := `
-		SELECT activity_id, invoice_id, user_id, title, description, created_at 
-		FROM invoice_activities 
-		WHERE user_id = $1 AND invoice_id = $2
-		ORDER BY created_at DESC 
-		LIMIT $3 OFFSET $4
-	`
-
-	rows, err := i.DBPool.Query(ctx, query, userID, invoiceID, limit, offset)
-	if err != nil {
-		return nil, err
+// GetInvoiceActivities retrieves a page of the recent activities associated with a specific invoice for a given user,
+// restricted to the event types and source of the filter, along with their total count.
+func (i *invoiceRepoImpl) GetInvoiceActivities(ctx context.Context, userID, invoiceID uuid.UUID, filter models.ActivityFilter, page pagination.Request) ([]models.InvoiceActivity, *pagination.Meta, error) {
+	query := listQuery{
+		columns:    invoiceActivityColumns,
+		from:       "activity_events",
+		conditions: []string{"user_id = $1", "invoice_id = $2"},
+		args:       []any{userID, invoiceID},
+		key:        "created_at",
+		keyType:    "timestamptz",
+		id:         "activity_id",
+		descending: true,
 	}
-	defer rows.Close()
+	query = filterActivities(query, filter)
+	return fetchPage(ctx, i.DBPool, query, page, scanInvoiceActivities, func(activity models.InvoiceActivity) pagination.Cursor {
+		return pagination.Cursor{Key: timeKey(activity.CreatedAt), ID: activity.ActivityID}
+	})
+}
 
+// filterActivities restricts a list of the activity event log to the event types and source of the filter.
+func filterActivities(query listQuery, filter models.ActivityFilter) listQuery {
+	if len(filter.EventTypes) > 0 {
+		eventTypes := make([]string, len(filter.EventTypes))
+		for idx, eventType := range filter.EventTypes {
+			eventTypes[idx] = string(eventType)
+		}
+		query.args = append(query.args, eventTypes)
+		query.conditions = append(query.conditions, fmt.Sprintf("event_type = ANY($%d)", len(query.args)))
+	}
+	switch filter.Source {
+	case models.ActivitySourceUser:
+		query.args = append(query.args, models.ActivityEventComment)
+		query.conditions = append(query.conditions, fmt.Sprintf("event_type = $%d", len(query.args)))
+	case models.ActivitySourceSystem:
+		query.args = append(query.args, models.ActivityEventComment)
+		query.conditions = append(query.conditions, fmt.Sprintf("event_type <> $%d", len(query.args)))
+	}
+	return query
+}
+
+// scanInvoiceActivities reads rows of invoiceActivityColumns into activities.
+func scanInvoiceActivities(rows pgx.Rows) ([]models.InvoiceActivity, error) {
 	activities := []models.InvoiceActivity{}
 	for rows.Next() {
 		var activity models.InvoiceActivity
-		if err := rows.Scan(&activity.ActivityID, &activity.InvoiceID, &activity.UserID, &activity.Title, &activity.Description, &activity.CreatedAt); err != nil {
+		err := rows.Scan(&activity.ActivityID, &activity.InvoiceID, &activity.UserID, &activity.EventType,
+			&activity.Payload, &activity.Title, &activity.Description, &activity.CreatedAt)
+		if err != nil {
 			return nil, err
 		}
+		activity.Source = activity.EventType.Source()
 		activities = append(activities, activity)
 	}
 
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+type lateFeeRepoImpl struct {
+	DBPool *pgxpool.Pool
+}
+
+// newLateFeeRepoImpl creates a new instance of the lateFeeRepoImpl struct, which is used to interact with the
+// late fee policies and invoice adjustments stored in the database.
+func newLateFeeRepoImpl(dbPool *pgxpool.Pool) *lateFeeRepoImpl {
+	return &lateFeeRepoImpl{
+		DBPool: dbPool,
+	}
+}
+
+// SetLateFeePolicy creates the late fee policy of a sender, or replaces it if the sender already has one,
+// and returns the policy ID.
+func (l *lateFeeRepoImpl) SetLateFeePolicy(ctx context.Context, policy models.LateFeePolicy) (uuid.UUID, error) {
+	query := `
+		INSERT INTO late_fee_policies (policy_id, user_id, fee_type, flat_amount, interest_rate, grace_days,
+		                               max_amount, max_percentage, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (user_id) DO UPDATE
+		SET fee_type = EXCLUDED.fee_type, flat_amount = EXCLUDED.flat_amount, interest_rate = EXCLUDED.interest_rate,
+		    grace_days = EXCLUDED.grace_days, max_amount = EXCLUDED.max_amount, max_percentage = EXCLUDED.max_percentage,
+		    is_active = EXCLUDED.is_active, updated_at = CURRENT_TIMESTAMP
+		RETURNING policy_id
+	`
+	err := l.DBPool.QueryRow(ctx, query, policy.PolicyID, policy.UserID, policy.FeeType, policy.FlatAmount, policy.InterestRate,
+		policy.GraceDays, policy.MaxAmount, policy.MaxPercentage, policy.IsActive).Scan(&policy.PolicyID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return policy.PolicyID, nil
+}
+
+// GetLateFeePolicy retrieves the late fee policy of the specified user. It returns nil when the user has no policy.
+func (l *lateFeeRepoImpl) GetLateFeePolicy(ctx context.Context, userID uuid.UUID) (*models.LateFeePolicy, error) {
+	query := `
+		SELECT policy_id, user_id, fee_type, COALESCE(flat_amount, 0), COALESCE(interest_rate, 0), grace_days,
+		       max_amount, max_percentage, is_active, created_at, updated_at
+		FROM late_fee_policies
+		WHERE user_id = $1
+	`
+
+	var policy models.LateFeePolicy
+	err := l.DBPool.QueryRow(ctx, query, userID).Scan(
+		&policy.PolicyID, &policy.UserID, &policy.FeeType, &policy.FlatAmount, &policy.InterestRate, &policy.GraceDays,
+		&policy.MaxAmount, &policy.MaxPercentage, &policy.IsActive, &policy.CreatedAt, &policy.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// GetLateFeeCandidates retrieves unpaid invoices past their due date and grace period whose sender has an active
+// late fee policy, along with the late fees already applied to each of them. Results are ordered by invoice ID and
+// start after the given invoice ID, so callers can page through every candidate.
+func (l *lateFeeRepoImpl) GetLateFeeCandidates(ctx context.Context, asOf time.Time, after uuid.UUID, limit int32) ([]models.LateFeeCandidate, error) {
+	query := `
+		SELECT i.invoice_id, i.invoice_number, i.sender_id, i.customer_id, i.issue_date, i.due_date,
+		       i.final_amount, i.status, i.currency,
+		       p.policy_id, p.user_id, p.fee_type, COALESCE(p.flat_amount, 0), COALESCE(p.interest_rate, 0), p.grace_days,
+		       p.max_amount, p.max_percentage, p.is_active,
+		       COALESCE(a.applied, 0) AS applied_amount
+		FROM invoices i
+		JOIN late_fee_policies p ON p.user_id = i.sender_id AND p.is_active
+		LEFT JOIN LATERAL (
+		    SELECT SUM(amount) AS applied
+		    FROM invoice_adjustments
+		    WHERE invoice_id = i.invoice_id
+		) a ON true
+		WHERE i.status IN ('pending', 'overdue')
+		  AND i.due_date + p.grace_days < $1::date
+		  AND i.invoice_id > $2
+		ORDER BY i.invoice_id
+		LIMIT $3
+	`
+
+	rows, err := l.DBPool.Query(ctx, query, asOf, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	candidates := []models.LateFeeCandidate{}
+	for rows.Next() {
+		var candidate models.LateFeeCandidate
+		err := rows.Scan(
+			&candidate.Invoice.InvoiceID, &candidate.Invoice.InvoiceNumber, &candidate.Invoice.SenderID, &candidate.Invoice.CustomerID,
+			&candidate.Invoice.IssueDate, &candidate.Invoice.DueDate, &candidate.Invoice.FinalAmount, &candidate.Invoice.Status,
+			&candidate.Invoice.Currency, &candidate.Policy.PolicyID, &candidate.Policy.UserID, &candidate.Policy.FeeType,
+			&candidate.Policy.FlatAmount, &candidate.Policy.InterestRate, &candidate.Policy.GraceDays, &candidate.Policy.MaxAmount,
+			&candidate.Policy.MaxPercentage, &candidate.Policy.IsActive, &candidate.AppliedAmount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return candidates, nil
+}
+
+// ApplyLateFee stores a late fee or interest adjustment on an invoice together with the invoice activity describing it.
+// At most one adjustment of each type is stored per invoice and day; it reports whether the adjustment was stored.
+func (l *lateFeeRepoImpl) ApplyLateFee(ctx context.Context, adjustment models.InvoiceAdjustment, activity models.InvoiceActivity) (bool, error) {
+	tx, err := l.DBPool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+        INSERT INTO invoice_adjustments (adjustment_id, invoice_id, policy_id, adjustment_type, amount, description, applied_on)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        ON CONFLICT (invoice_id, adjustment_type, applied_on) DO NOTHING`,
+		adjustment.AdjustmentID, adjustment.InvoiceID, adjustment.PolicyID, adjustment.AdjustmentType, adjustment.Amount,
+		adjustment.Description, adjustment.AppliedOn,
+	)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if err := recordActivity(ctx, tx, activity); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
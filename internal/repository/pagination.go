@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zde37/Numeris-Task/internal/pagination"
+)
+
+// listQuery describes a list of rows sorted by a key column and, to break ties, a unique ID column, so that it
+// can be read a page at a time by keyset. The conditions refer to the args by position.
+type listQuery struct {
+	columns    string
+	from       string
+	conditions []string
+	args       []any
+	key        string
+	keyType    string
+	id         string
+	descending bool
+}
+
+// fetchPage reads the page of a list a request asks for in a single batch with the total number of rows in the list,
+// and returns the rows with the metadata of the page. Rows after or before a cursor are found by comparing their key
+// and ID with the cursor's, which an index on the two columns answers without scanning the rows of previous pages.
+// Cursors issued for the list sorted another way, or whose key is not of the type of the key column, are rejected
+// with pagination.ErrInvalidCursor.
+func fetchPage[T any](ctx context.Context, dbPool *pgxpool.Pool, q listQuery, req pagination.Request, scan func(pgx.Rows) ([]T, error), cursor func(T) pagination.Cursor) ([]T, *pagination.Meta, error) {
+	sort := q.sort()
+	if req.Cursor != nil && (req.Cursor.Sort != sort || !validKey(q.keyType, req.Cursor.Key)) {
+		return nil, nil, pagination.ErrInvalidCursor
+	}
+
+	conditions, args := slices.Clone(q.conditions), slices.Clone(q.args)
+	descending := q.descending
+	if req.Cursor != nil {
+		// pages before a cursor are read in reverse order, and put back in order by pagination.Paginate
+		if req.Cursor.Before {
+			descending = !descending
+		}
+		operator := ">"
+		if descending {
+			operator = "<"
+		}
+		args = append(args, req.Cursor.Key, req.Cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(%s, %s) %s ($%d::text::%s, $%d)", q.key, q.id, operator, len(args)-1, q.keyType, len(args)))
+	}
+	order := "ASC"
+	if descending {
+		order = "DESC"
+	}
+	args = append(args, req.Limit+1, req.Offset())
+
+	batch := &pgx.Batch{}
+	batch.Queue(fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s`, q.from, strings.Join(q.conditions, " AND ")), q.args...)
+	batch.Queue(fmt.Sprintf(`
+        SELECT %s
+        FROM %s
+        WHERE %s
+        ORDER BY %[4]s %[5]s, %[6]s %[5]s
+        LIMIT $%[7]d OFFSET $%[8]d`,
+		q.columns, q.from, strings.Join(conditions, " AND "), q.key, order, q.id, len(args)-1, len(args)), args...)
+
+	results := dbPool.SendBatch(ctx, batch)
+	defer results.Close()
+
+	var total int64
+	if err := results.QueryRow().Scan(&total); err != nil {
+		return nil, nil, err
+	}
+	rows, err := readBatch(results, scan)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items, meta := pagination.Paginate(rows, req, total, func(row T) pagination.Cursor {
+		position := cursor(row)
+		position.Sort = sort
+		return position
+	})
+	return items, meta, nil
+}
+
+// sort names the key column and the order of the list, as recorded in the cursors of its pages.
+func (q listQuery) sort() string {
+	if q.descending {
+		return q.key + " desc"
+	}
+	return q.key + " asc"
+}
+
+// numericKey matches the numeric cursor keys formatted by strconv.FormatFloat without an exponent.
+var numericKey = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// validKey reports whether a cursor key can be cast to the Postgres type of the key column. Postgres has no
+// year 0, which Go parses.
+func validKey(keyType, key string) bool {
+	switch keyType {
+	case "timestamptz":
+		t, err := time.Parse(time.RFC3339Nano, key)
+		return err == nil && t.Year() > 0
+	case "date":
+		t, err := time.Parse("2006-01-02", key)
+		return err == nil && t.Year() > 0
+	case "numeric":
+		return numericKey.MatchString(key)
+	default:
+		return true
+	}
+}
+
+// timeKey formats a timestamp as a cursor key, keeping the precision Postgres stores it with.
+func timeKey(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
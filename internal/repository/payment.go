@@ -0,0 +1,553 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+type paymentRepoImpl struct {
+	DBPool *pgxpool.Pool
+}
+
+// newPaymentRepoImpl creates a new instance of the paymentRepoImpl struct, which is used to interact with the
+// invoice payments stored in the database.
+func newPaymentRepoImpl(dbPool *pgxpool.Pool) *paymentRepoImpl {
+	return &paymentRepoImpl{
+		DBPool: dbPool,
+	}
+}
+
+// RecordPayment records a payment received by the specified sender on one of their invoices, together with the
+// invoice activity describing it. Once the payments cover the final amount and any late fees, the invoice is marked as paid.
+// A payment larger than the balance due is rejected with models.ErrPaymentExceedsBalance, as it is most likely a typo.
+func (p *paymentRepoImpl) RecordPayment(ctx context.Context, userID uuid.UUID, payment models.Payment) (uuid.UUID, error) {
+	tx, err := p.DBPool.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := recordPayment(ctx, tx, userID, payment, paymentsWithinBalance); err != nil {
+		return uuid.Nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return uuid.Nil, err
+	}
+	return payment.PaymentID, nil
+}
+
+// paymentPolicy decides which payments larger than the balance due recordPayment records.
+type paymentPolicy int
+
+const (
+	// paymentsWithinBalance rejects payments larger than the balance due, which are most likely typos.
+	paymentsWithinBalance paymentPolicy = iota
+	// paymentsOverBalance records payments larger than the balance due of invoices that are not paid yet, such as
+	// bank transactions, whose excess is left for the sender to refund.
+	paymentsOverBalance
+	// paymentsOnPaidInvoices also records payments on invoices that are already paid, for money the customer was
+	// already charged, such as a payment made online, which is left for the sender to refund in full with
+	// RefundPayment.
+	paymentsOnPaidInvoices
+)
+
+// recordPayment records a payment on an invoice of the sender within the transaction, as RecordPayment describes,
+// recording the payments larger than the balance due that the policy allows.
+func recordPayment(ctx context.Context, tx pgx.Tx, userID uuid.UUID, payment models.Payment, policy paymentPolicy) error {
+	// lock the invoice so concurrent payments see each other
+	var invoice models.Invoice
+	var amountDue float64
+	err := tx.QueryRow(ctx, `
+        SELECT invoice_number, status, currency,
+               final_amount + COALESCE((SELECT SUM(amount) FROM invoice_adjustments WHERE invoice_id = $1), 0) AS amount_due
+        FROM invoices
+        WHERE invoice_id = $1 AND sender_id = $2
+        FOR UPDATE`,
+		payment.InvoiceID, userID,
+	).Scan(&invoice.InvoiceNumber, &invoice.Status, &invoice.Currency, &amountDue)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ErrInvoiceNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	alreadyPaid := models.InvoiceStatus(invoice.Status) == models.InvoiceStatusPaid
+	switch {
+	case models.InvoiceStatus(invoice.Status) == models.InvoiceStatusDraft:
+		return models.ErrInvoiceIsDraft
+	case alreadyPaid && policy != paymentsOnPaidInvoices:
+		return models.ErrInvoiceAlreadyPaid
+	}
+	if policy == paymentsWithinBalance {
+		// read once the invoice is locked, so the payments recorded concurrently are counted
+		var balanceDue float64
+		err = tx.QueryRow(ctx, `
+            SELECT final_amount
+                     + COALESCE((SELECT SUM(amount) FROM invoice_adjustments WHERE invoice_id = $1), 0)
+                     - COALESCE((SELECT SUM(amount) FROM payments WHERE invoice_id = $1), 0)
+            FROM invoices
+            WHERE invoice_id = $1`,
+			payment.InvoiceID,
+		).Scan(&balanceDue)
+		if err != nil {
+			return err
+		}
+		if payment.Amount > balanceDue {
+			return fmt.Errorf("%w of %s %.2f", models.ErrPaymentExceedsBalance, invoice.Currency, balanceDue)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO payments (payment_id, invoice_id, amount, paid_on, reference)
+        VALUES ($1, $2, $3, $4, $5)`,
+		payment.PaymentID, payment.InvoiceID, payment.Amount, payment.PaidOn, payment.Reference,
+	)
+	if err != nil {
+		return err
+	}
+
+	var amountPaid float64
+	err = tx.QueryRow(ctx, `SELECT COALESCE(SUM(amount), 0) FROM payments WHERE invoice_id = $1`, payment.InvoiceID).Scan(&amountPaid)
+	if err != nil {
+		return err
+	}
+
+	// create invoice activity
+	description := fmt.Sprintf("Received payment of %s %.2f for invoice %s", invoice.Currency, payment.Amount, invoice.InvoiceNumber)
+	if alreadyPaid {
+		description += ", which was already paid; the payment is to be refunded"
+	}
+	activity := models.NewInvoiceActivity(payment.InvoiceID, userID, models.ActivityEventPaymentRecorded,
+		models.PaymentRecordedPayload{
+			PaymentID: payment.PaymentID, Amount: payment.Amount, Currency: invoice.Currency, Reference: payment.Reference,
+		},
+		"Payment Received", description)
+	if err := recordActivity(ctx, tx, activity); err != nil {
+		return err
+	}
+
+	if amountPaid >= amountDue && !alreadyPaid {
+		_, err = tx.Exec(ctx, `UPDATE invoices SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE invoice_id = $2`,
+			models.InvoiceStatusPaid, payment.InvoiceID)
+		if err != nil {
+			return err
+		}
+
+		activity := models.NewInvoiceActivity(payment.InvoiceID, userID, models.ActivityEventStatusChanged,
+			models.StatusChangedPayload{From: models.InvoiceStatus(invoice.Status), To: models.InvoiceStatusPaid},
+			"Status Change", fmt.Sprintf("Invoice %s was marked as paid", invoice.InvoiceNumber))
+		if err := recordActivity(ctx, tx, activity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPayableInvoice returns an invoice of the sender with the balance left to pay on it, or nil when the sender has no
+// such invoice.
+func (p *paymentRepoImpl) GetPayableInvoice(ctx context.Context, senderID, invoiceID uuid.UUID) (*models.PayableInvoice, error) {
+	var invoice models.PayableInvoice
+	err := p.DBPool.QueryRow(ctx, `
+        SELECT i.invoice_id, i.invoice_number, i.status, i.currency, c.name, c.email,
+               i.final_amount
+                 + COALESCE((SELECT SUM(amount) FROM invoice_adjustments WHERE invoice_id = i.invoice_id), 0)
+                 - COALESCE((SELECT SUM(amount) FROM payments WHERE invoice_id = i.invoice_id), 0) AS balance_due
+        FROM invoices i
+        JOIN customers c ON c.customer_id = i.customer_id
+        WHERE i.invoice_id = $1 AND i.sender_id = $2`,
+		invoiceID, senderID,
+	).Scan(&invoice.InvoiceID, &invoice.InvoiceNumber, &invoice.Status, &invoice.Currency, &invoice.CustomerName,
+		&invoice.CustomerEmail, &invoice.BalanceDue)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// CreatePaymentSession stores a checkout session created with a payment provider.
+func (p *paymentRepoImpl) CreatePaymentSession(ctx context.Context, session models.PaymentSession) error {
+	_, err := p.DBPool.Exec(ctx, `
+        INSERT INTO payment_sessions (payment_session_id, invoice_id, provider, provider_session_id, url, amount,
+                                      currency, status, expires_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		session.PaymentSessionID, session.InvoiceID, session.Provider, session.ProviderSessionID, session.URL,
+		session.Amount, session.Currency, session.Status, session.ExpiresAt,
+	)
+	return err
+}
+
+// paymentSessionColumns are the columns a payment session is scanned from by scanPaymentSession.
+const paymentSessionColumns = `payment_session_id, invoice_id, provider, provider_session_id, url, amount, currency,
+    status, payment_id, expires_at, created_at, updated_at`
+
+// scanPaymentSession scans a row of paymentSessionColumns into a payment session.
+func scanPaymentSession(row pgx.Row) (*models.PaymentSession, error) {
+	var session models.PaymentSession
+	err := row.Scan(&session.PaymentSessionID, &session.InvoiceID, &session.Provider, &session.ProviderSessionID,
+		&session.URL, &session.Amount, &session.Currency, &session.Status, &session.PaymentID, &session.ExpiresAt,
+		&session.CreatedAt, &session.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetPaymentSession returns the checkout session the provider knows by the session id, or nil when it was not
+// created with the provider.
+func (p *paymentRepoImpl) GetPaymentSession(ctx context.Context, provider, providerSessionID string) (*models.PaymentSession, error) {
+	session, err := scanPaymentSession(p.DBPool.QueryRow(ctx, `
+        SELECT `+paymentSessionColumns+`
+        FROM payment_sessions
+        WHERE provider = $1 AND provider_session_id = $2`,
+		provider, providerSessionID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return session, err
+}
+
+// CompletePaymentSession records the payment made on a checkout session on its invoice, as RecordPayment does for the
+// sender of the invoice, and marks the session as completed. A session that is already completed is returned as
+// is, so a payment is recorded once however often the provider reports it. The customer was charged already, so the
+// payment is recorded even when it is larger than the balance due or the invoice was paid in the meantime, for the
+// sender to refund.
+func (p *paymentRepoImpl) CompletePaymentSession(ctx context.Context, paymentSessionID uuid.UUID, payment models.Payment) (*models.PaymentSession, error) {
+	tx, err := p.DBPool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// lock the session so a callback repeated concurrently waits for the payment to be recorded
+	session, err := scanPaymentSession(tx.QueryRow(ctx, `
+        SELECT `+paymentSessionColumns+`
+        FROM payment_sessions
+        WHERE payment_session_id = $1
+        FOR UPDATE`,
+		paymentSessionID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrPaymentSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if session.Status == models.PaymentSessionStatusCompleted {
+		return session, nil
+	}
+
+	var senderID uuid.UUID
+	err = tx.QueryRow(ctx, `SELECT sender_id FROM invoices WHERE invoice_id = $1`, session.InvoiceID).Scan(&senderID)
+	if err != nil {
+		return nil, err
+	}
+
+	payment.InvoiceID = session.InvoiceID
+	if err := recordPayment(ctx, tx, senderID, payment, paymentsOnPaidInvoices); err != nil {
+		return nil, err
+	}
+
+	session, err = scanPaymentSession(tx.QueryRow(ctx, `
+        UPDATE payment_sessions
+        SET status = $2, payment_id = $3, updated_at = CURRENT_TIMESTAMP
+        WHERE payment_session_id = $1
+        RETURNING `+paymentSessionColumns,
+		paymentSessionID, models.PaymentSessionStatusCompleted, payment.PaymentID,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// FailPaymentSession marks an open checkout session as failed. Sessions that were already completed are left as
+// they are.
+func (p *paymentRepoImpl) FailPaymentSession(ctx context.Context, paymentSessionID uuid.UUID) error {
+	_, err := p.DBPool.Exec(ctx, `
+        UPDATE payment_sessions
+        SET status = $2, updated_at = CURRENT_TIMESTAMP
+        WHERE payment_session_id = $1 AND status = $3`,
+		paymentSessionID, models.PaymentSessionStatusFailed, models.PaymentSessionStatusOpen,
+	)
+	return err
+}
+
+// paymentRefundColumns are the columns a payment refund is scanned from by scanPaymentRefund.
+const paymentRefundColumns = `refund_id, payment_id, invoice_id, user_id, provider, payment_reference, amount, currency,
+    status, COALESCE(provider_refund_id, ''), COALESCE(failure_reason, ''), created_at, updated_at`
+
+// scanPaymentRefund scans a row of paymentRefundColumns into a payment refund.
+func scanPaymentRefund(row pgx.Row) (*models.PaymentRefund, error) {
+	var refund models.PaymentRefund
+	err := row.Scan(&refund.RefundID, &refund.PaymentID, &refund.InvoiceID, &refund.UserID, &refund.Provider,
+		&refund.PaymentReference, &refund.Amount, &refund.Currency, &refund.Status, &refund.ProviderRefundID,
+		&refund.FailureReason, &refund.CreatedAt, &refund.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+// CreatePaymentRefund records a pending refund of an amount of a payment made online through the provider on an
+// invoice of the sender, or of the whole part of it not refunded or being refunded yet when the amount is 0. The
+// refund is sent again from retryAt on, unless it is completed or failed by then. Pending refunds count against the
+// payment, so concurrent refunds cannot return more than was paid.
+func (p *paymentRepoImpl) CreatePaymentRefund(ctx context.Context, userID, paymentID uuid.UUID, amount float64, provider string, retryAt time.Time) (*models.PaymentRefund, error) {
+	tx, err := p.DBPool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// lock the invoice, as recordPayment does, so concurrent payments and refunds see each other
+	var currency string
+	err = tx.QueryRow(ctx, `
+        SELECT i.currency
+        FROM invoices i
+        JOIN payments p ON p.invoice_id = i.invoice_id
+        WHERE p.payment_id = $1 AND p.refunded_payment_id IS NULL AND i.sender_id = $2
+        FOR UPDATE OF i`,
+		paymentID, userID,
+	).Scan(&currency)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrPaymentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// only payments made through a checkout session can be refunded by the provider
+	refund := models.PaymentRefund{
+		RefundID:  uuid.New(),
+		PaymentID: paymentID,
+		UserID:    userID,
+		Currency:  currency,
+		Status:    models.PaymentRefundStatusPending,
+	}
+	var refundable float64
+	err = tx.QueryRow(ctx, `
+        SELECT p.invoice_id, s.provider, COALESCE(p.reference, ''),
+               p.amount - COALESCE((
+                   SELECT SUM(amount) FROM payment_refunds WHERE payment_id = p.payment_id AND status IN ($2, $3)
+               ), 0)
+        FROM payments p
+        JOIN payment_sessions s ON s.payment_id = p.payment_id
+        WHERE p.payment_id = $1`,
+		paymentID, models.PaymentRefundStatusPending, models.PaymentRefundStatusCompleted,
+	).Scan(&refund.InvoiceID, &refund.Provider, &refund.PaymentReference, &refundable)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: it was not made online", models.ErrPaymentNotRefundable)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if refund.Provider != provider {
+		return nil, fmt.Errorf("%w: it was made with the %s provider", models.ErrPaymentNotRefundable, refund.Provider)
+	}
+	if refundable <= 0 {
+		return nil, fmt.Errorf("%w: it is refunded in full already", models.ErrPaymentNotRefundable)
+	}
+	if amount == 0 {
+		amount = refundable
+	}
+	if amount > refundable {
+		return nil, fmt.Errorf("%w of %s %.2f", models.ErrRefundExceedsPayment, currency, refundable)
+	}
+	refund.Amount = amount
+
+	created, err := scanPaymentRefund(tx.QueryRow(ctx, `
+        INSERT INTO payment_refunds (refund_id, payment_id, invoice_id, user_id, provider, payment_reference, amount,
+                                     currency, status, next_attempt_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        RETURNING `+paymentRefundColumns,
+		refund.RefundID, refund.PaymentID, refund.InvoiceID, refund.UserID, refund.Provider, refund.PaymentReference,
+		refund.Amount, refund.Currency, refund.Status, retryAt,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// CompletePaymentRefund records a pending refund the provider made as the refund with the provider refund ID. The
+// refund is recorded on its invoice as a payment of the negative amount, together with the invoice activity describing
+// it, and an invoice that is no longer paid in full is marked as pending again. A refund that is already completed is
+// returned as is, so it is recorded once however often it is completed.
+func (p *paymentRepoImpl) CompletePaymentRefund(ctx context.Context, refundID uuid.UUID, providerRefundID string) (*models.PaymentRefund, error) {
+	tx, err := p.DBPool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	refund, err := scanPaymentRefund(tx.QueryRow(ctx, `
+        SELECT `+paymentRefundColumns+`
+        FROM payment_refunds
+        WHERE refund_id = $1
+        FOR UPDATE`,
+		refundID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrPaymentRefundNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch refund.Status {
+	case models.PaymentRefundStatusCompleted:
+		return refund, nil
+	case models.PaymentRefundStatusFailed:
+		return nil, fmt.Errorf("refund %s was rejected already", refundID)
+	}
+
+	// lock the invoice, as recordPayment does, so concurrent payments and refunds see each other
+	var invoice models.Invoice
+	var amountDue float64
+	err = tx.QueryRow(ctx, `
+        SELECT invoice_number, status,
+               final_amount + COALESCE((SELECT SUM(amount) FROM invoice_adjustments WHERE invoice_id = $1), 0)
+        FROM invoices
+        WHERE invoice_id = $1
+        FOR UPDATE`,
+		refund.InvoiceID,
+	).Scan(&invoice.InvoiceNumber, &invoice.Status, &amountDue)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO payments (payment_id, invoice_id, amount, paid_on, reference, refunded_payment_id)
+        VALUES ($1, $2, $3, CURRENT_DATE, $4, $5)`,
+		refund.RefundID, refund.InvoiceID, -refund.Amount, providerRefundID, refund.PaymentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	activity := models.NewInvoiceActivity(refund.InvoiceID, refund.UserID, models.ActivityEventPaymentRefunded,
+		models.PaymentRefundedPayload{
+			RefundID: refund.RefundID, PaymentID: refund.PaymentID, Amount: refund.Amount, Currency: refund.Currency,
+			Reference: providerRefundID,
+		},
+		"Payment Refunded",
+		fmt.Sprintf("Refunded %s %.2f of a payment for invoice %s", refund.Currency, refund.Amount, invoice.InvoiceNumber))
+	if err := recordActivity(ctx, tx, activity); err != nil {
+		return nil, err
+	}
+
+	var amountPaid float64
+	err = tx.QueryRow(ctx, `SELECT COALESCE(SUM(amount), 0) FROM payments WHERE invoice_id = $1`, refund.InvoiceID).Scan(&amountPaid)
+	if err != nil {
+		return nil, err
+	}
+	// invoices past their due date are marked as overdue again by MarkOverdueInvoices
+	if models.InvoiceStatus(invoice.Status) == models.InvoiceStatusPaid && amountPaid < amountDue {
+		_, err = tx.Exec(ctx, `UPDATE invoices SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE invoice_id = $2`,
+			models.InvoiceStatusPending, refund.InvoiceID)
+		if err != nil {
+			return nil, err
+		}
+
+		activity := models.NewInvoiceActivity(refund.InvoiceID, refund.UserID, models.ActivityEventStatusChanged,
+			models.StatusChangedPayload{From: models.InvoiceStatusPaid, To: models.InvoiceStatusPending},
+			"Status Change", fmt.Sprintf("Invoice %s was marked as pending after a refund", invoice.InvoiceNumber))
+		if err := recordActivity(ctx, tx, activity); err != nil {
+			return nil, err
+		}
+	}
+
+	refund, err = scanPaymentRefund(tx.QueryRow(ctx, `
+        UPDATE payment_refunds
+        SET status = $2, provider_refund_id = $3, updated_at = CURRENT_TIMESTAMP
+        WHERE refund_id = $1
+        RETURNING `+paymentRefundColumns,
+		refundID, models.PaymentRefundStatusCompleted, providerRefundID,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+// FailPaymentRefund marks a pending refund the provider rejected as failed for the reason, so its amount can be
+// refunded again. Refunds that were already completed or failed are returned as they are.
+func (p *paymentRepoImpl) FailPaymentRefund(ctx context.Context, refundID uuid.UUID, reason string) (*models.PaymentRefund, error) {
+	_, err := p.DBPool.Exec(ctx, `
+        UPDATE payment_refunds
+        SET status = $2, failure_reason = $3, updated_at = CURRENT_TIMESTAMP
+        WHERE refund_id = $1 AND status = $4`,
+		refundID, models.PaymentRefundStatusFailed, reason, models.PaymentRefundStatusPending,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	refund, err := scanPaymentRefund(p.DBPool.QueryRow(ctx, `
+        SELECT `+paymentRefundColumns+`
+        FROM payment_refunds
+        WHERE refund_id = $1`,
+		refundID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrPaymentRefundNotFound
+	}
+	return refund, err
+}
+
+// ClaimPendingPaymentRefunds claims up to limit pending refunds due to be sent again as of the given time, oldest
+// first, and returns them. Claimed refunds are not due again until the lease runs out, so other servers do not send
+// them meanwhile, and refunds claimed by another server are skipped.
+func (p *paymentRepoImpl) ClaimPendingPaymentRefunds(ctx context.Context, asOf time.Time, lease time.Duration, limit int32) ([]models.PaymentRefund, error) {
+	rows, err := p.DBPool.Query(ctx, `
+        UPDATE payment_refunds
+        SET next_attempt_at = $2
+        WHERE refund_id IN (
+            SELECT refund_id
+            FROM payment_refunds
+            WHERE status = $3 AND next_attempt_at <= $1
+            ORDER BY next_attempt_at, refund_id
+            LIMIT $4
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING `+paymentRefundColumns,
+		asOf, asOf.Add(lease), models.PaymentRefundStatusPending, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refunds := []models.PaymentRefund{}
+	for rows.Next() {
+		refund, err := scanPaymentRefund(rows)
+		if err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, *refund)
+	}
+	return refunds, rows.Err()
+}
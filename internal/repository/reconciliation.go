@@ -0,0 +1,293 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/pagination"
+)
+
+type reconciliationRepoImpl struct {
+	DBPool *pgxpool.Pool
+}
+
+// newReconciliationRepoImpl creates a new instance of the reconciliationRepoImpl struct, which is used to interact
+// with the bank transactions imported from bank statements stored in the database.
+func newReconciliationRepoImpl(dbPool *pgxpool.Pool) *reconciliationRepoImpl {
+	return &reconciliationRepoImpl{
+		DBPool: dbPool,
+	}
+}
+
+// bankTransactionColumns are the columns a bank transaction is scanned from by scanBankTransaction.
+const bankTransactionColumns = `bank_transaction_id, user_id, external_id, booking_date, amount, currency,
+    counterparty_name, remittance_info, status, invoice_id, payment_id, suggested_invoice_ids, created_at, updated_at`
+
+// scanBankTransaction scans a row of bankTransactionColumns into a bank transaction.
+func scanBankTransaction(row pgx.Row) (*models.BankTransaction, error) {
+	var transaction models.BankTransaction
+	err := row.Scan(&transaction.BankTransactionID, &transaction.UserID, &transaction.ExternalID,
+		&transaction.BookingDate, &transaction.Amount, &transaction.Currency, &transaction.CounterpartyName,
+		&transaction.RemittanceInfo, &transaction.Status, &transaction.InvoiceID, &transaction.PaymentID,
+		&transaction.SuggestedInvoiceIDs, &transaction.CreatedAt, &transaction.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// GetUnpaidInvoices returns the invoices of the sender that are neither drafts nor paid, with the balance left to
+// pay on each, which bank transactions are matched against.
+func (r *reconciliationRepoImpl) GetUnpaidInvoices(ctx context.Context, senderID uuid.UUID) ([]models.PayableInvoice, error) {
+	rows, err := r.DBPool.Query(ctx, `
+        SELECT i.invoice_id, i.invoice_number, i.status, i.currency, c.name, c.email,
+               i.final_amount
+                 + COALESCE((SELECT SUM(amount) FROM invoice_adjustments WHERE invoice_id = i.invoice_id), 0)
+                 - COALESCE((SELECT SUM(amount) FROM payments WHERE invoice_id = i.invoice_id), 0) AS balance_due
+        FROM invoices i
+        JOIN customers c ON c.customer_id = i.customer_id
+        WHERE i.sender_id = $1 AND i.status NOT IN ($2, $3)
+        ORDER BY i.due_date, i.invoice_number`,
+		senderID, models.InvoiceStatusDraft, models.InvoiceStatusPaid,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invoices := []models.PayableInvoice{}
+	for rows.Next() {
+		var invoice models.PayableInvoice
+		err := rows.Scan(&invoice.InvoiceID, &invoice.InvoiceNumber, &invoice.Status, &invoice.Currency,
+			&invoice.CustomerName, &invoice.CustomerEmail, &invoice.BalanceDue)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, invoice)
+	}
+	return invoices, rows.Err()
+}
+
+// AddBankTransaction stores a bank transaction imported by a user, and returns it as stored, or nil when the user
+// imported it before. When a payment is given, it is recorded on the invoice of the payment as RecordPayment does and
+// the transaction is stored as applied. Should the payment be rejected, for instance because the invoice was paid in
+// the meantime, the transaction is stored as pending instead, to be reconciled by the user.
+func (r *reconciliationRepoImpl) AddBankTransaction(ctx context.Context, transaction models.BankTransaction, payment *models.Payment) (*models.BankTransaction, error) {
+	tx, err := r.DBPool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if transaction.SuggestedInvoiceIDs == nil {
+		transaction.SuggestedInvoiceIDs = []uuid.UUID{}
+	}
+	stored, err := scanBankTransaction(tx.QueryRow(ctx, `
+        INSERT INTO bank_transactions (bank_transaction_id, user_id, external_id, booking_date, amount, currency,
+                                       counterparty_name, remittance_info, status, suggested_invoice_ids)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        ON CONFLICT (user_id, external_id) DO NOTHING
+        RETURNING `+bankTransactionColumns,
+		transaction.BankTransactionID, transaction.UserID, transaction.ExternalID, transaction.BookingDate,
+		transaction.Amount, transaction.Currency, transaction.CounterpartyName, transaction.RemittanceInfo,
+		models.BankTransactionStatusPending, transaction.SuggestedInvoiceIDs,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if payment != nil {
+		applied, err := applyPayment(ctx, tx, stored, *payment)
+		if err != nil {
+			return nil, err
+		}
+		if applied != nil {
+			stored = applied
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// applyPayment records the payment of a bank transaction in a savepoint of the transaction, and marks the bank
+// transaction as applied to the invoice paid. It returns nil when the invoice cannot be paid, because it is a draft,
+// is paid already or is not found, leaving the bank transaction as it was; any other error is returned.
+func applyPayment(ctx context.Context, tx pgx.Tx, transaction *models.BankTransaction, payment models.Payment) (*models.BankTransaction, error) {
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer savepoint.Rollback(ctx)
+
+	err = recordPayment(ctx, savepoint, transaction.UserID, payment, paymentsOverBalance)
+	if errors.Is(err, models.ErrInvoiceIsDraft) || errors.Is(err, models.ErrInvoiceAlreadyPaid) ||
+		errors.Is(err, models.ErrInvoiceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	applied, err := scanBankTransaction(savepoint.QueryRow(ctx, `
+        UPDATE bank_transactions
+        SET status = $2, invoice_id = $3, payment_id = $4, updated_at = CURRENT_TIMESTAMP
+        WHERE bank_transaction_id = $1
+        RETURNING `+bankTransactionColumns,
+		transaction.BankTransactionID, models.BankTransactionStatusApplied, payment.InvoiceID, payment.PaymentID,
+	))
+	if err != nil {
+		return nil, err
+	}
+	if err := savepoint.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+// GetBankTransactions returns a page of the bank transactions of a user with the status, oldest booking first, along
+// with the number of such transactions. The bank transaction ID breaks ties so that pages do not overlap.
+func (r *reconciliationRepoImpl) GetBankTransactions(ctx context.Context, userID uuid.UUID, status models.BankTransactionStatus, page pagination.Request) ([]models.BankTransaction, *pagination.Meta, error) {
+	query := listQuery{
+		columns:    bankTransactionColumns,
+		from:       "bank_transactions",
+		conditions: []string{"user_id = $1", "status = $2"},
+		args:       []any{userID, status},
+		key:        "booking_date",
+		keyType:    "date",
+		id:         "bank_transaction_id",
+	}
+	return fetchPage(ctx, r.DBPool, query, page, scanBankTransactions, func(transaction models.BankTransaction) pagination.Cursor {
+		return pagination.Cursor{Key: transaction.BookingDate.Format("2006-01-02"), ID: transaction.BankTransactionID}
+	})
+}
+
+// scanBankTransactions reads rows of bankTransactionColumns into bank transactions.
+func scanBankTransactions(rows pgx.Rows) ([]models.BankTransaction, error) {
+	transactions := []models.BankTransaction{}
+	for rows.Next() {
+		transaction, err := scanBankTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, *transaction)
+	}
+	return transactions, rows.Err()
+}
+
+// GetBankTransaction returns a bank transaction of the user, or nil when the user has no such transaction.
+func (r *reconciliationRepoImpl) GetBankTransaction(ctx context.Context, userID, bankTransactionID uuid.UUID) (*models.BankTransaction, error) {
+	transaction, err := scanBankTransaction(r.DBPool.QueryRow(ctx, `
+        SELECT `+bankTransactionColumns+`
+        FROM bank_transactions
+        WHERE bank_transaction_id = $1 AND user_id = $2`,
+		bankTransactionID, userID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return transaction, err
+}
+
+// getPendingBankTransaction locks a bank transaction of the user that waits in the reconciliation inbox.
+func getPendingBankTransaction(ctx context.Context, tx pgx.Tx, userID, bankTransactionID uuid.UUID) (*models.BankTransaction, error) {
+	transaction, err := scanBankTransaction(tx.QueryRow(ctx, `
+        SELECT `+bankTransactionColumns+`
+        FROM bank_transactions
+        WHERE bank_transaction_id = $1 AND user_id = $2
+        FOR UPDATE`,
+		bankTransactionID, userID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrBankTransactionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if transaction.Status != models.BankTransactionStatusPending {
+		return nil, models.ErrBankTransactionReconciled
+	}
+	return transaction, nil
+}
+
+// ConfirmBankTransaction records a bank transaction waiting in the reconciliation inbox of a user as a payment on
+// the invoice of the payment, which must be an invoice of the user in the currency of the transaction, and marks the
+// transaction as confirmed.
+func (r *reconciliationRepoImpl) ConfirmBankTransaction(ctx context.Context, userID, bankTransactionID uuid.UUID, payment models.Payment) (*models.BankTransaction, error) {
+	tx, err := r.DBPool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	transaction, err := getPendingBankTransaction(ctx, tx, userID, bankTransactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var currency string
+	err = tx.QueryRow(ctx, `SELECT currency FROM invoices WHERE invoice_id = $1 AND sender_id = $2`,
+		payment.InvoiceID, userID).Scan(&currency)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrInvoiceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if currency != transaction.Currency {
+		return nil, fmt.Errorf("%w: the transaction is in %s and the invoice in %s", models.ErrCurrencyMismatch,
+			transaction.Currency, currency)
+	}
+
+	if err := recordPayment(ctx, tx, userID, payment, paymentsOverBalance); err != nil {
+		return nil, err
+	}
+	confirmed, err := scanBankTransaction(tx.QueryRow(ctx, `
+        UPDATE bank_transactions
+        SET status = $2, invoice_id = $3, payment_id = $4, updated_at = CURRENT_TIMESTAMP
+        WHERE bank_transaction_id = $1
+        RETURNING `+bankTransactionColumns,
+		bankTransactionID, models.BankTransactionStatusConfirmed, payment.InvoiceID, payment.PaymentID,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return confirmed, nil
+}
+
+// DismissBankTransaction removes a bank transaction from the reconciliation inbox of a user without recording a
+// payment, for credits that do not pay an invoice.
+func (r *reconciliationRepoImpl) DismissBankTransaction(ctx context.Context, userID, bankTransactionID uuid.UUID) error {
+	tx, err := r.DBPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := getPendingBankTransaction(ctx, tx, userID, bankTransactionID); err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+        UPDATE bank_transactions
+        SET status = $2, updated_at = CURRENT_TIMESTAMP
+        WHERE bank_transaction_id = $1`,
+		bankTransactionID, models.BankTransactionStatusDismissed,
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+type reminderRepoImpl struct {
+	DBPool *pgxpool.Pool
+}
+
+// newReminderRepoImpl creates a new instance of the reminderRepoImpl struct, which is used to interact with the
+// reminder rules and sent reminders stored in the database.
+func newReminderRepoImpl(dbPool *pgxpool.Pool) *reminderRepoImpl {
+	return &reminderRepoImpl{
+		DBPool: dbPool,
+	}
+}
+
+// AddReminderRule creates a new reminder rule for a sender and returns the generated rule ID.
+func (r *reminderRepoImpl) AddReminderRule(ctx context.Context, rule models.ReminderRule) (uuid.UUID, error) {
+	query := `
+		INSERT INTO reminder_rules (rule_id, user_id, days_offset, tone)
+		VALUES ($1, $2, $3, $4)
+		RETURNING rule_id
+	`
+	err := r.DBPool.QueryRow(ctx, query, rule.RuleID, rule.UserID, rule.DaysOffset, rule.Tone).Scan(&rule.RuleID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return rule.RuleID, nil
+}
+
+// GetReminderRules retrieves the reminder rules configured by the specified user, ordered by their offset from the due date.
+func (r *reminderRepoImpl) GetReminderRules(ctx context.Context, userID uuid.UUID) ([]models.ReminderRule, error) {
+	query := `
+		SELECT rule_id, user_id, days_offset, tone, is_active, created_at, updated_at
+		FROM reminder_rules
+		WHERE user_id = $1
+		ORDER BY days_offset
+	`
+
+	rows, err := r.DBPool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []models.ReminderRule{}
+	for rows.Next() {
+		var rule models.ReminderRule
+		err := rows.Scan(&rule.RuleID, &rule.UserID, &rule.DaysOffset, &rule.Tone, &rule.IsActive, &rule.CreatedAt, &rule.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// DeleteReminderRule deletes a reminder rule owned by the specified user. Reminders already sent for the rule are kept.
+func (r *reminderRepoImpl) DeleteReminderRule(ctx context.Context, userID, ruleID uuid.UUID) error {
+	tag, err := r.DBPool.Exec(ctx, `DELETE FROM reminder_rules WHERE rule_id = $1 AND user_id = $2`, ruleID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("reminder rule not found")
+	}
+	return nil
+}
+
+// ClaimDueReminders claims up to limit reminders due as of the given date, oldest first, and returns them. Unpaid
+// invoices that reached a reminder rule get a pending reminder for the furthest step reached, unless one was already
+// queued at or after that step, so an invoice never receives the same (or a milder) reminder twice; pending reminders
+// of an earlier step that are not being sent are replaced. Claimed reminders are not due again until the lease runs
+// out, so other servers do not send them meanwhile, and reminders claimed by another server are skipped.
+func (r *reminderRepoImpl) ClaimDueReminders(ctx context.Context, asOf time.Time, lease time.Duration, limit int32) ([]models.DueReminder, error) {
+	tx, err := r.DBPool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		WITH due AS (
+		    SELECT DISTINCT ON (i.invoice_id) i.invoice_id, r.rule_id, r.days_offset, r.tone, c.email
+		    FROM invoices i
+		    JOIN reminder_rules r ON r.user_id = i.sender_id AND r.is_active
+		    JOIN customers c ON i.customer_id = c.customer_id
+		    WHERE i.status IN ('pending', 'overdue')
+		      AND i.due_date + r.days_offset <= $1::date
+		      AND NOT EXISTS (
+		          SELECT 1 FROM invoice_reminders ir
+		          WHERE ir.invoice_id = i.invoice_id AND ir.days_offset >= r.days_offset
+		      )
+		    ORDER BY i.invoice_id, r.days_offset DESC
+		),
+		replaced AS (
+		    DELETE FROM invoice_reminders ir
+		    USING due
+		    WHERE ir.invoice_id = due.invoice_id AND ir.days_offset < due.days_offset
+		      AND ir.status = $2 AND ir.next_attempt_at <= $1
+		)
+		INSERT INTO invoice_reminders (reminder_id, invoice_id, rule_id, days_offset, tone, sent_to, status, next_attempt_at)
+		SELECT gen_random_uuid(), invoice_id, rule_id, days_offset, tone, email, $2, $1
+		FROM due
+		ON CONFLICT (invoice_id, days_offset) DO NOTHING
+	`, asOf, models.ReminderStatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `
+		WITH claimed AS (
+		    UPDATE invoice_reminders ir
+		    SET next_attempt_at = $3
+		    WHERE ir.reminder_id IN (
+		        SELECT ir.reminder_id
+		        FROM invoice_reminders ir
+		        JOIN invoices i ON i.invoice_id = ir.invoice_id
+		        JOIN reminder_rules r ON r.rule_id = ir.rule_id AND r.is_active
+		        WHERE ir.status = $2 AND ir.next_attempt_at <= $1
+		          AND i.status IN ('pending', 'overdue')
+		          AND NOT EXISTS (
+		              SELECT 1 FROM invoice_reminders later
+		              WHERE later.invoice_id = ir.invoice_id AND later.days_offset > ir.days_offset
+		          )
+		        ORDER BY ir.next_attempt_at, ir.reminder_id
+		        LIMIT $4
+		        FOR UPDATE OF ir SKIP LOCKED
+		    )
+		    RETURNING ir.reminder_id, ir.invoice_id, ir.rule_id, ir.days_offset, ir.tone, ir.attempts, ir.next_attempt_at
+		)
+		SELECT cl.reminder_id, cl.attempts,
+		       i.invoice_id, i.invoice_number, i.sender_id, i.customer_id, i.issue_date, i.due_date,
+		       i.final_amount, i.status, i.currency,
+		       i.final_amount
+		         + COALESCE((SELECT SUM(amount) FROM invoice_adjustments WHERE invoice_id = i.invoice_id), 0)
+		         - COALESCE((SELECT SUM(amount) FROM payments WHERE invoice_id = i.invoice_id), 0) AS balance_due,
+		       cl.rule_id, i.sender_id, cl.days_offset, cl.tone,
+		       s.first_name || ' ' || s.last_name AS sender_name, s.email AS sender_email,
+		       c.name AS customer_name, c.email AS customer_email
+		FROM claimed cl
+		JOIN invoices i ON i.invoice_id = cl.invoice_id
+		JOIN users s ON i.sender_id = s.user_id
+		JOIN customers c ON i.customer_id = c.customer_id
+		ORDER BY cl.next_attempt_at, cl.reminder_id
+	`, asOf, models.ReminderStatusPending, asOf.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reminders := []models.DueReminder{}
+	for rows.Next() {
+		var reminder models.DueReminder
+		err := rows.Scan(
+			&reminder.ReminderID, &reminder.Attempts,
+			&reminder.Invoice.InvoiceID, &reminder.Invoice.InvoiceNumber, &reminder.Invoice.SenderID, &reminder.Invoice.CustomerID,
+			&reminder.Invoice.IssueDate, &reminder.Invoice.DueDate, &reminder.Invoice.FinalAmount, &reminder.Invoice.Status,
+			&reminder.Invoice.Currency, &reminder.BalanceDue, &reminder.Rule.RuleID, &reminder.Rule.UserID, &reminder.Rule.DaysOffset, &reminder.Rule.Tone,
+			&reminder.SenderName, &reminder.SenderEmail, &reminder.CustomerName, &reminder.CustomerEmail,
+		)
+		if err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, reminder)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	return reminders, tx.Commit(ctx)
+}
+
+// RecordReminder marks a claimed reminder as sent, together with the invoice activity describing it.
+func (r *reminderRepoImpl) RecordReminder(ctx context.Context, reminder models.InvoiceReminder, activity models.InvoiceActivity) error {
+	tx, err := r.DBPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+        UPDATE invoice_reminders
+        SET status = $2, sent_to = $3, sent_at = CURRENT_TIMESTAMP, attempts = attempts + 1,
+            next_attempt_at = NULL, last_error = ''
+        WHERE reminder_id = $1`,
+		reminder.ReminderID, models.ReminderStatusSent, reminder.SentTo,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := recordActivity(ctx, tx, activity); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RecordReminderFailure records a failed attempt to send a claimed reminder. The reminder is due again at
+// nextAttemptAt, or fails for good when it is nil, which lets the next step of the invoice be sent once reached.
+func (r *reminderRepoImpl) RecordReminderFailure(ctx context.Context, reminderID uuid.UUID, lastError string, nextAttemptAt *time.Time) error {
+	status := models.ReminderStatusPending
+	if nextAttemptAt == nil {
+		status = models.ReminderStatusFailed
+	}
+	_, err := r.DBPool.Exec(ctx, `
+        UPDATE invoice_reminders
+        SET status = $2, attempts = attempts + 1, next_attempt_at = $3, last_error = $4
+        WHERE reminder_id = $1`,
+		reminderID, status, nextAttemptAt, lastError,
+	)
+	return err
+}
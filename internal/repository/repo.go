@@ -2,39 +2,172 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zde37/Numeris-Task/internal/encryption"
 	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/pagination"
 )
 
+// querier runs statements either on the pool or inside a transaction, so that the statements of a repository
+// method can also run as part of a larger transaction. Begin starts a transaction on the pool and a savepoint
+// inside a transaction.
+type querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 type UserRepository interface {
 	CreateUser(ctx context.Context, user models.User) (uuid.UUID, error)
 	AddCustomer(ctx context.Context, customer models.Customer) (uuid.UUID, error)
+	AddCustomers(ctx context.Context, customers []models.Customer) error
+	GetOwnedCustomerIDs(ctx context.Context, userID uuid.UUID, customerIDs []uuid.UUID) ([]uuid.UUID, error)
 	AddPaymentMethod(ctx context.Context, paymentMethod models.UserPaymentMethod) (uuid.UUID, error)
+	GetPaymentMethods(ctx context.Context, userID uuid.UUID) ([]models.UserPaymentMethod, error)
+	GetDefaultPaymentMethod(ctx context.Context, userID uuid.UUID) (*models.UserPaymentMethod, error)
+	UpdatePaymentMethod(ctx context.Context, userID, paymentMethodID uuid.UUID, update models.PaymentMethodUpdate) (*models.UserPaymentMethod, error)
+	DeletePaymentMethod(ctx context.Context, userID, paymentMethodID uuid.UUID) error
+	SetDefaultPaymentMethod(ctx context.Context, userID, paymentMethodID uuid.UUID) error
+	ReencryptAccountNumbers(ctx context.Context, limit int32) (int, error)
 }
 
 type InvoiceRepository interface {
-	GetTotalByStatus(ctx context.Context, status models.InvoiceStatus) (float64, int, error)
-	CreateInvoice(ctx context.Context, invoice models.Invoice, items []models.InvoiceItem, customer uuid.UUID, paymentInfo models.PaymentInformation) (uuid.UUID, error)
+	GetTotalByStatus(ctx context.Context, filter models.TotalsFilter) ([]models.StatusTotals, error)
+	CreateInvoice(ctx context.Context, invoice models.Invoice, items []models.InvoiceItem, taxLines []models.InvoiceTaxLine, customer uuid.UUID, paymentInfo models.PaymentInformation) (uuid.UUID, error)
+	CreateInvoices(ctx context.Context, invoices []models.NewInvoice) ([]error, error)
 	GetInvoiceDetails(ctx context.Context, invoiceID uuid.UUID) (*models.InvoiceDetails, error)
 	AddInvoiceActivity(ctx context.Context, activity models.InvoiceActivity) (uuid.UUID, error)
-	GetRecentInvoices(ctx context.Context, senderID uuid.UUID, limit, offset int32) ([]models.Invoice, error)
-	GetRecentActivities(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]models.RecentActivity, error)
-	GetInvoiceActivities(ctx context.Context, userID, invoiceID uuid.UUID, limit, offset int32) ([]models.InvoiceActivity, error)
+	GetRecentInvoices(ctx context.Context, senderID uuid.UUID, page pagination.Request) ([]models.Invoice, *pagination.Meta, error)
+	SearchInvoices(ctx context.Context, filter models.InvoiceFilter, page pagination.Request) ([]models.Invoice, *pagination.Meta, error)
+	ExportInvoices(ctx context.Context, filter models.InvoiceFilter, withItems bool, fn func(models.InvoiceExportRow) error) error
+	GetRecentActivities(ctx context.Context, userID uuid.UUID, filter models.ActivityFilter, page pagination.Request) ([]models.RecentActivity, *pagination.Meta, error)
+	GetInvoiceActivities(ctx context.Context, userID, invoiceID uuid.UUID, filter models.ActivityFilter, page pagination.Request) ([]models.InvoiceActivity, *pagination.Meta, error)
+	MarkOverdueInvoices(ctx context.Context, asOf time.Time, limit int32) (int, error)
+}
+
+type ReminderRepository interface {
+	AddReminderRule(ctx context.Context, rule models.ReminderRule) (uuid.UUID, error)
+	GetReminderRules(ctx context.Context, userID uuid.UUID) ([]models.ReminderRule, error)
+	DeleteReminderRule(ctx context.Context, userID, ruleID uuid.UUID) error
+	ClaimDueReminders(ctx context.Context, asOf time.Time, lease time.Duration, limit int32) ([]models.DueReminder, error)
+	RecordReminder(ctx context.Context, reminder models.InvoiceReminder, activity models.InvoiceActivity) error
+	RecordReminderFailure(ctx context.Context, reminderID uuid.UUID, lastError string, nextAttemptAt *time.Time) error
+}
+
+type LateFeeRepository interface {
+	SetLateFeePolicy(ctx context.Context, policy models.LateFeePolicy) (uuid.UUID, error)
+	GetLateFeePolicy(ctx context.Context, userID uuid.UUID) (*models.LateFeePolicy, error)
+	GetLateFeeCandidates(ctx context.Context, asOf time.Time, after uuid.UUID, limit int32) ([]models.LateFeeCandidate, error)
+	ApplyLateFee(ctx context.Context, adjustment models.InvoiceAdjustment, activity models.InvoiceActivity) (bool, error)
+}
+
+type TaxRepository interface {
+	CreateTaxRate(ctx context.Context, taxRate models.TaxRate) (uuid.UUID, error)
+	GetTaxRates(ctx context.Context, userID uuid.UUID) ([]models.TaxRate, error)
+	DeactivateTaxRate(ctx context.Context, userID, taxRateID uuid.UUID) error
+	GetTaxReport(ctx context.Context, senderID uuid.UUID, from, to time.Time, basis models.TaxReportBasis) ([]models.TaxReportLine, error)
+}
+
+type PaymentRepository interface {
+	RecordPayment(ctx context.Context, userID uuid.UUID, payment models.Payment) (uuid.UUID, error)
+	GetPayableInvoice(ctx context.Context, senderID, invoiceID uuid.UUID) (*models.PayableInvoice, error)
+	CreatePaymentSession(ctx context.Context, session models.PaymentSession) error
+	GetPaymentSession(ctx context.Context, provider, providerSessionID string) (*models.PaymentSession, error)
+	CompletePaymentSession(ctx context.Context, paymentSessionID uuid.UUID, payment models.Payment) (*models.PaymentSession, error)
+	FailPaymentSession(ctx context.Context, paymentSessionID uuid.UUID) error
+	CreatePaymentRefund(ctx context.Context, userID, paymentID uuid.UUID, amount float64, provider string, retryAt time.Time) (*models.PaymentRefund, error)
+	CompletePaymentRefund(ctx context.Context, refundID uuid.UUID, providerRefundID string) (*models.PaymentRefund, error)
+	FailPaymentRefund(ctx context.Context, refundID uuid.UUID, reason string) (*models.PaymentRefund, error)
+	ClaimPendingPaymentRefunds(ctx context.Context, asOf time.Time, lease time.Duration, limit int32) ([]models.PaymentRefund, error)
+}
+
+type ReconciliationRepository interface {
+	GetUnpaidInvoices(ctx context.Context, senderID uuid.UUID) ([]models.PayableInvoice, error)
+	AddBankTransaction(ctx context.Context, transaction models.BankTransaction, payment *models.Payment) (*models.BankTransaction, error)
+	GetBankTransactions(ctx context.Context, userID uuid.UUID, status models.BankTransactionStatus, page pagination.Request) ([]models.BankTransaction, *pagination.Meta, error)
+	GetBankTransaction(ctx context.Context, userID, bankTransactionID uuid.UUID) (*models.BankTransaction, error)
+	ConfirmBankTransaction(ctx context.Context, userID, bankTransactionID uuid.UUID, payment models.Payment) (*models.BankTransaction, error)
+	DismissBankTransaction(ctx context.Context, userID, bankTransactionID uuid.UUID) error
+}
+
+type EventRepository interface {
+	GetActivityEvents(ctx context.Context, userID uuid.UUID, after models.EventCursor, limit int32) ([]models.ActivityEvent, error)
+	GetActivityEventsSince(ctx context.Context, userID uuid.UUID, position int64, limit int32) ([]models.ActivityEvent, error)
+	GetActivityEvent(ctx context.Context, sequence int64) (*models.ActivityEvent, error)
+	ListenActivityEvents(ctx context.Context, listening func(), notify func(models.ActivityNotification)) error
+}
+
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, webhook models.Webhook) (uuid.UUID, error)
+	GetWebhooks(ctx context.Context, userID uuid.UUID) ([]models.Webhook, error)
+	GetActiveWebhooks(ctx context.Context) ([]models.Webhook, error)
+	GetWebhook(ctx context.Context, userID, webhookID uuid.UUID) (*models.Webhook, error)
+	DeleteWebhook(ctx context.Context, userID, webhookID uuid.UUID) error
+	EnableWebhook(ctx context.Context, userID, webhookID uuid.UUID) (*models.Webhook, error)
+	QueueWebhookDeliveries(ctx context.Context, webhookID uuid.UUID, deliveries []models.WebhookDelivery, last models.EventCursor) error
+	ClaimDueWebhookDeliveries(ctx context.Context, asOf time.Time, lease time.Duration, limit int32) ([]models.DueWebhookDelivery, error)
+	RecordWebhookDeliveryAttempt(ctx context.Context, attempt models.WebhookDeliveryAttempt, maxFailures int) error
+	GetWebhookDeliveries(ctx context.Context, webhookID uuid.UUID, page pagination.Request) ([]models.WebhookDelivery, *pagination.Meta, error)
+	ReplayWebhookDelivery(ctx context.Context, userID, deliveryID, replayID uuid.UUID, asOf time.Time) (*models.WebhookDelivery, error)
+}
+
+type CurrencyRepository interface {
+	SaveExchangeRates(ctx context.Context, rates []models.ExchangeRate) (int, error)
+	GetExchangeRate(ctx context.Context, from, to string, on time.Time) (*models.ExchangeRate, error)
+	GetBaseCurrency(ctx context.Context, userID uuid.UUID) (string, error)
+}
+
+type DashboardRepository interface {
+	GetDashboard(ctx context.Context, senderID uuid.UUID, asOf time.Time, limit int32) (*models.Dashboard, error)
+}
+
+type AgingRepository interface {
+	GetOpenInvoices(ctx context.Context, senderID uuid.UUID, asOf time.Time, customerID *uuid.UUID) ([]models.AgingInvoice, error)
+}
+
+type ReportRepository interface {
+	GetSeries(ctx context.Context, filter models.SeriesFilter) ([]models.Series, error)
 }
 
 type Repository struct {
-	User    UserRepository
-	Invoice InvoiceRepository
+	User           UserRepository
+	Invoice        InvoiceRepository
+	Reminder       ReminderRepository
+	LateFee        LateFeeRepository
+	Tax            TaxRepository
+	Payment        PaymentRepository
+	Reconciliation ReconciliationRepository
+	Currency       CurrencyRepository
+	Dashboard      DashboardRepository
+	Aging          AgingRepository
+	Report         ReportRepository
+	Event          EventRepository
+	Webhook        WebhookRepository
 }
 
-// NewRepository creates a new Repository instance that provides access to the User and Invoice repositories.
+// NewRepository creates a new Repository instance that provides access to the User, Invoice, Reminder, LateFee, Tax, Payment, Reconciliation, Currency, Dashboard, Aging, Report, Event and Webhook repositories.
 // The Repository struct is the main entry point for interacting with the application's data storage.
-// It takes a *pgxpool.Pool as a parameter, which is used to create the underlying repository implementations.
-func NewRepository(dbPool *pgxpool.Pool) *Repository {
+// It takes a *pgxpool.Pool as a parameter, which is used to create the underlying repository implementations, and
+// the keyring account numbers and webhook secrets are encrypted with.
+func NewRepository(dbPool *pgxpool.Pool, keyring *encryption.Keyring) *Repository {
 	return &Repository{
-		User:    newUserRepoImpl(dbPool),
-		Invoice: newInvoiceRepoImpl(dbPool),
+		User:           newUserRepoImpl(dbPool, keyring),
+		Invoice:        newInvoiceRepoImpl(dbPool, keyring),
+		Reminder:       newReminderRepoImpl(dbPool),
+		LateFee:        newLateFeeRepoImpl(dbPool),
+		Tax:            newTaxRepoImpl(dbPool),
+		Payment:        newPaymentRepoImpl(dbPool),
+		Reconciliation: newReconciliationRepoImpl(dbPool),
+		Currency:       newCurrencyRepoImpl(dbPool),
+		Dashboard:      newDashboardRepoImpl(dbPool),
+		Aging:          newAgingRepoImpl(dbPool),
+		Report:         newReportRepoImpl(dbPool),
+		Event:          newEventRepoImpl(dbPool),
+		Webhook:        newWebhookRepoImpl(dbPool, keyring),
 	}
 }
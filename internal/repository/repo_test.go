@@ -1,7 +1,10 @@
 package repository
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -15,8 +18,10 @@ import (
 	"github.com/stretchr/testify/suite"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
+	"github.com/zde37/Numeris-Task/internal/encryption"
 	"github.com/zde37/Numeris-Task/internal/helpers"
 	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/pagination"
 )
 
 type testID struct {
@@ -35,6 +40,8 @@ type InvoiceRepoTestSuite struct {
 	migrationURL       string
 	repo               *Repository
 	ids                testID
+	// keys are the master keys of the repository's keyring, whose active key is test-1
+	keys map[string][]byte
 }
 
 func (suite *InvoiceRepoTestSuite) SetupSuite() {
@@ -78,7 +85,10 @@ func (suite *InvoiceRepoTestSuite) SetupSuite() {
 	suite.pgConnectionString = connStr
 	suite.dbPool = dbPool
 	suite.migrationURL = "file://../../migrations"
-	suite.repo = NewRepository(suite.dbPool)
+	suite.keys = map[string][]byte{"test-1": bytes.Repeat([]byte{1}, 32), "test-2": bytes.Repeat([]byte{2}, 32)}
+	keyring, err := encryption.NewKeyring("test-1", suite.keys)
+	suite.Require().NoError(err)
+	suite.repo = NewRepository(suite.dbPool, keyring)
 
 	migration, err := migrate.New(suite.migrationURL, suite.pgConnectionString)
 	suite.NoError(err)
@@ -100,19 +110,6 @@ func (suite *InvoiceRepoTestSuite) TearDownSuite() {
 }
 
 func (suite *InvoiceRepoTestSuite) setupTestData() {
-	// create new customer
-	customer := models.Customer{
-		CustomerID:  uuid.New(),
-		Name:        "Name 1",
-		Email:       "Email 1",
-		PhoneNumber: "Phone Number 1",
-		Address:     "Address 1",
-	}
-	customerID, err := suite.repo.User.AddCustomer(suite.ctx, customer)
-	suite.NoError(err)
-	suite.Equal(customerID, customer.CustomerID)
-	suite.ids.customerID = customerID
-
 	// create user
 	user := models.User{
 		UserID:            uuid.New(),
@@ -124,12 +121,27 @@ func (suite *InvoiceRepoTestSuite) setupTestData() {
 		ProfilePictureURL: "Profile pic 2",
 		PhoneNumber:       "Phone number 2",
 		Address:           "Address",
+		BaseCurrency:      "NGN",
 	}
 	userID, err := suite.repo.User.CreateUser(suite.ctx, user)
 	suite.NoError(err)
 	suite.Equal(userID, user.UserID)
 	suite.ids.senderID = userID
 
+	// create new customer
+	customer := models.Customer{
+		CustomerID:  uuid.New(),
+		UserID:      suite.ids.senderID,
+		Name:        "Name 1",
+		Email:       "Email 1",
+		PhoneNumber: "Phone Number 1",
+		Address:     "Address 1",
+	}
+	customerID, err := suite.repo.User.AddCustomer(suite.ctx, customer)
+	suite.NoError(err)
+	suite.Equal(customerID, customer.CustomerID)
+	suite.ids.customerID = customerID
+
 	// add payment method
 	paymentMethod := models.UserPaymentMethod{
 		PaymentMethodID: uuid.New(),
@@ -147,6 +159,7 @@ func (suite *InvoiceRepoTestSuite) setupTestData() {
 
 	// create invoice
 	invoiceID := uuid.New()
+	sameCurrencyRate := 1.0
 	invoice := models.Invoice{
 		InvoiceID:          invoiceID,
 		InvoiceNumber:      helpers.RandomNumber(1000000000, 9999999999),
@@ -160,6 +173,8 @@ func (suite *InvoiceRepoTestSuite) setupTestData() {
 		FinalAmount:        9000,
 		Status:             string(models.InvoiceStatusPaid),
 		Currency:           "NGN",
+		BaseCurrency:       "NGN",
+		ExchangeRate:       &sameCurrencyRate,
 		Notes:              "Thanks for your patronage",
 	}
 
@@ -180,35 +195,74 @@ func (suite *InvoiceRepoTestSuite) setupTestData() {
 		InvoiceID:       invoiceID,
 		PaymentMethodID: suite.ids.paymentMethodID,
 	}
-	id, err := suite.repo.Invoice.CreateInvoice(suite.ctx, invoice, items, suite.ids.customerID, paymentInfo)
+	id, err := suite.repo.Invoice.CreateInvoice(suite.ctx, invoice, items, nil, suite.ids.customerID, paymentInfo)
 	suite.NoError(err)
 	suite.Equal(id, invoiceID)
 	suite.ids.invoiceID = id
 
 	// add invoice activity
-	activity := models.InvoiceActivity{
-		ActivityID:  uuid.New(),
-		InvoiceID:   suite.ids.invoiceID,
-		UserID:      suite.ids.senderID,
-		Title:       "Payment Confirmed",
-		Description: "You confirmed payment",
-	}
+	activity := models.NewInvoiceActivity(suite.ids.invoiceID, suite.ids.senderID, models.ActivityEventComment, nil,
+		"Comment", "You confirmed payment")
 	activityID, err := suite.repo.Invoice.AddInvoiceActivity(suite.ctx, activity)
 	suite.NoError(err)
 	suite.Equal(activityID, activity.ActivityID)
 }
 
 func (suite *InvoiceRepoTestSuite) TestGetTotalByStatus() {
-	totalAmount, count, err := suite.repo.Invoice.GetTotalByStatus(suite.ctx, models.InvoiceStatusPaid)
+	totals, err := suite.repo.Invoice.GetTotalByStatus(suite.ctx, models.TotalsFilter{SenderID: suite.ids.senderID})
+	suite.Require().NoError(err)
+	suite.Require().Len(totals, 1)
+	suite.Equal(models.InvoiceStatusPaid, totals[0].Status)
+	suite.Equal(1, totals[0].Count)
+	suite.Equal([]models.CurrencyTotal{{Currency: "NGN", Count: 1, TotalAmount: 9000}}, totals[0].Currencies)
+	suite.Equal([]models.CurrencyTotal{{Currency: "NGN", Count: 1, TotalAmount: 9000}}, totals[0].BaseCurrencies)
+
+	// totals only include the invoices of the sender matching the filter
+	ids := suite.createTestSender()
+	otherCustomer := suite.createTestSender()
+	otherCustomer.senderID, otherCustomer.paymentMethodID = ids.senderID, ids.paymentMethodID
+	jan := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	suite.createTestInvoice(ids, models.InvoiceStatusPending, jan, mar, 100, "NGN")
+	suite.createTestInvoice(ids, models.InvoiceStatusPending, jan, jan.AddDate(0, 0, 30), 50, "USD")
+	suite.createTestInvoice(ids, models.InvoiceStatusOverDue, mar, mar.AddDate(0, 0, 30), 300, "NGN")
+	suite.createTestInvoice(otherCustomer, models.InvoiceStatusPending, mar, mar.AddDate(0, 0, 30), 400, "NGN")
+
+	totals, err = suite.repo.Invoice.GetTotalByStatus(suite.ctx, models.TotalsFilter{SenderID: ids.senderID})
+	suite.Require().NoError(err)
+	suite.Require().Len(totals, 2)
+	suite.Equal(models.InvoiceStatusOverDue, totals[0].Status)
+	suite.Equal(models.InvoiceStatusPending, totals[1].Status)
+	suite.Equal(3, totals[1].Count)
+	suite.Equal([]models.CurrencyTotal{{Currency: "NGN", Count: 2, TotalAmount: 500}, {Currency: "USD", Count: 1, TotalAmount: 50}}, totals[1].Currencies)
+	// the USD invoice has no locked rate, so it is left out of the converted total
+	suite.Equal([]models.CurrencyTotal{{Currency: "NGN", Count: 2, TotalAmount: 500}}, totals[1].BaseCurrencies)
+
+	from, to := jan, jan.AddDate(0, 1, 0)
+	totals, err = suite.repo.Invoice.GetTotalByStatus(suite.ctx, models.TotalsFilter{SenderID: ids.senderID, DateField: models.TotalsDateFieldIssue, From: &from, To: &to})
+	suite.Require().NoError(err)
+	suite.Require().Len(totals, 1)
+	suite.Equal(2, totals[0].Count)
+
+	totals, err = suite.repo.Invoice.GetTotalByStatus(suite.ctx, models.TotalsFilter{SenderID: ids.senderID, DateField: models.TotalsDateFieldDue, From: &from, To: &to})
+	suite.Require().NoError(err)
+	suite.Require().Len(totals, 1)
+	suite.Equal([]models.CurrencyTotal{{Currency: "USD", Count: 1, TotalAmount: 50}}, totals[0].Currencies)
+
+	totals, err = suite.repo.Invoice.GetTotalByStatus(suite.ctx, models.TotalsFilter{SenderID: ids.senderID, CustomerID: &otherCustomer.customerID})
 	suite.Require().NoError(err)
-	suite.Equal(float64(9000), totalAmount)
-	suite.Equal(1, count)
+	suite.Require().Len(totals, 1)
+	suite.Equal(1, totals[0].Count)
+	suite.Equal(400.0, totals[0].Currencies[0].TotalAmount)
 }
 
 func (suite *InvoiceRepoTestSuite) TestGetRecentInvoices() {
-	invoices, err := suite.repo.Invoice.GetRecentInvoices(suite.ctx, suite.ids.senderID, 5, 0)
+	invoices, meta, err := suite.repo.Invoice.GetRecentInvoices(suite.ctx, suite.ids.senderID, pagination.Request{Limit: 5, Page: 1})
 	suite.Require().NoError(err)
 	suite.Len(invoices, 1)
+	suite.Equal(int64(1), meta.Total)
+	suite.Empty(meta.NextCursor)
 	suite.NotEmpty(invoices[0])
 	suite.Equal(suite.ids.customerID, invoices[0].CustomerID)
 	suite.Equal(suite.ids.senderID, invoices[0].SenderID)
@@ -222,6 +276,64 @@ func (suite *InvoiceRepoTestSuite) TestGetRecentInvoices() {
 	suite.Equal("Thanks for your patronage", invoices[0].Notes)
 }
 
+func (suite *InvoiceRepoTestSuite) TestInvoiceCursorPagination() {
+	ids := suite.createTestSender()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	created := make([]uuid.UUID, 5)
+	for i := range created {
+		created[len(created)-1-i] = suite.createTestInvoice(ids, models.InvoiceStatusPending, today, today.AddDate(0, 0, 30), 100, "NGN")
+	}
+
+	first, meta, err := suite.repo.Invoice.GetRecentInvoices(suite.ctx, ids.senderID, pagination.Request{Limit: 2, Page: 1})
+	suite.Require().NoError(err)
+	suite.Equal(int64(5), meta.Total)
+	suite.Equal(created[:2], invoiceIDs(first))
+	suite.Empty(meta.PrevCursor)
+
+	// an invoice created between page loads does not shift the next page
+	suite.createTestInvoice(ids, models.InvoiceStatusPending, today, today.AddDate(0, 0, 30), 100, "NGN")
+
+	next, err := pagination.DecodeCursor(meta.NextCursor)
+	suite.Require().NoError(err)
+	second, meta, err := suite.repo.Invoice.GetRecentInvoices(suite.ctx, ids.senderID, pagination.Request{Limit: 2, Cursor: next})
+	suite.Require().NoError(err)
+	suite.Equal(int64(6), meta.Total)
+	suite.Equal(created[2:4], invoiceIDs(second))
+
+	prev, err := pagination.DecodeCursor(meta.PrevCursor)
+	suite.Require().NoError(err)
+	back, _, err := suite.repo.Invoice.GetRecentInvoices(suite.ctx, ids.senderID, pagination.Request{Limit: 2, Cursor: prev})
+	suite.Require().NoError(err)
+	suite.Equal(created[:2], invoiceIDs(back))
+
+	// a cursor of the list sorted another way, or with a key of another type, is rejected
+	filter := models.InvoiceFilter{SenderID: ids.senderID, SortBy: models.InvoiceSortFieldAmount, SortOrder: models.SortOrderAsc}
+	_, _, err = suite.repo.Invoice.SearchInvoices(suite.ctx, filter, pagination.Request{Limit: 2, Cursor: next})
+	suite.ErrorIs(err, pagination.ErrInvalidCursor)
+
+	byAmount, meta, err := suite.repo.Invoice.SearchInvoices(suite.ctx, filter, pagination.Request{Limit: 2, Page: 1})
+	suite.Require().NoError(err)
+	suite.Len(byAmount, 2)
+	next, err = pagination.DecodeCursor(meta.NextCursor)
+	suite.Require().NoError(err)
+	_, _, err = suite.repo.Invoice.SearchInvoices(suite.ctx, filter, pagination.Request{Limit: 2, Cursor: next})
+	suite.Require().NoError(err)
+
+	forged := *next
+	forged.Key = "2024-01-01T00:00:00Z"
+	_, _, err = suite.repo.Invoice.SearchInvoices(suite.ctx, filter, pagination.Request{Limit: 2, Cursor: &forged})
+	suite.ErrorIs(err, pagination.ErrInvalidCursor)
+}
+
+// invoiceIDs returns the IDs of the given invoices, in order.
+func invoiceIDs(invoices []models.Invoice) []uuid.UUID {
+	ids := make([]uuid.UUID, len(invoices))
+	for i, invoice := range invoices {
+		ids[i] = invoice.InvoiceID
+	}
+	return ids
+}
+
 func (suite *InvoiceRepoTestSuite) TestGetInvoiceDetails() {
 	invoice, err := suite.repo.Invoice.GetInvoiceDetails(suite.ctx, suite.ids.invoiceID)
 	suite.Require().NoError(err)
@@ -237,27 +349,1693 @@ func (suite *InvoiceRepoTestSuite) TestGetInvoiceDetails() {
 	suite.Equal(float64(9000), invoice.Invoice.FinalAmount)
 	suite.Equal(models.InvoiceStatusPaid, models.InvoiceStatus(invoice.Invoice.Status))
 	suite.Equal("NGN", invoice.Invoice.Currency)
+	suite.Equal("NGN", invoice.Invoice.BaseCurrency)
+	suite.Require().NotNil(invoice.Invoice.ExchangeRate)
+	suite.Equal(float64(1), *invoice.Invoice.ExchangeRate)
 	suite.Equal("Thanks for your patronage", invoice.Invoice.Notes)
+	suite.Equal("Account Number 2", invoice.PaymentInformation.AccountNumber)
+	suite.Equal("****er 2", invoice.PaymentInformation.MaskedAccountNumber)
 }
 
 func (suite *InvoiceRepoTestSuite) TestGetRecentActivities() {
-	activities, err := suite.repo.Invoice.GetRecentActivities(suite.ctx, suite.ids.senderID, 5, 0)
+	activities, _, err := suite.repo.Invoice.GetRecentActivities(suite.ctx, suite.ids.senderID, models.ActivityFilter{}, pagination.Request{Limit: 5, Page: 1})
 	suite.Require().NoError(err)
-	suite.Len(activities, 1)
-	suite.NotEmpty(activities[0])
+	// comments are in the feed as well as the events recorded by the system
+	suite.Require().Len(activities, 2)
 	suite.Equal(suite.ids.senderID, activities[0].UserID)
-	suite.Equal("Invoice Creation", activities[0].Title)
+	suite.Equal(models.ActivityEventComment, activities[0].EventType)
+	suite.Equal("Invoice Creation", activities[1].Title)
+	suite.Equal(&suite.ids.invoiceID, activities[1].InvoiceID)
+
+	// the feed is filtered like the activities of an invoice
+	filtered, meta, err := suite.repo.Invoice.GetRecentActivities(suite.ctx, suite.ids.senderID,
+		models.ActivityFilter{Source: models.ActivitySourceSystem}, pagination.Request{Limit: 5, Page: 1})
+	suite.Require().NoError(err)
+	suite.Equal(int64(1), meta.Total)
+	suite.Require().Len(filtered, 1)
+	suite.Equal(activities[1].ActivityID, filtered[0].ActivityID)
+	filtered, _, err = suite.repo.Invoice.GetRecentActivities(suite.ctx, suite.ids.senderID,
+		models.ActivityFilter{EventTypes: []models.ActivityEventType{models.ActivityEventComment}}, pagination.Request{Limit: 5, Page: 1})
+	suite.Require().NoError(err)
+	suite.Require().Len(filtered, 1)
+	suite.Equal(activities[0].ActivityID, filtered[0].ActivityID)
+
+	// the event log is append-only
+	_, err = suite.dbPool.Exec(suite.ctx, `UPDATE activity_events SET title = 'Edited' WHERE activity_id = $1`, activities[1].ActivityID)
+	suite.ErrorContains(err, "append-only")
+	_, err = suite.dbPool.Exec(suite.ctx, `DELETE FROM activity_events WHERE activity_id = $1`, activities[1].ActivityID)
+	suite.ErrorContains(err, "append-only")
 }
 
 func (suite *InvoiceRepoTestSuite) TestGetInvoiceActivities() {
-	activities, err := suite.repo.Invoice.GetInvoiceActivities(suite.ctx, suite.ids.senderID, suite.ids.invoiceID, 5, 0)
+	activities, _, err := suite.repo.Invoice.GetInvoiceActivities(suite.ctx, suite.ids.senderID, suite.ids.invoiceID, models.ActivityFilter{}, pagination.Request{Limit: 5, Page: 1})
 	suite.Require().NoError(err)
 	suite.Len(activities, 2)
 	suite.NotEmpty(activities[0])
 	suite.NotEmpty(activities[1])
 	suite.Equal(suite.ids.invoiceID, activities[1].InvoiceID)
-	suite.Equal("Payment Confirmed", activities[0].Title)
+	suite.Equal(models.ActivityEventComment, activities[0].EventType)
+	suite.Equal(models.ActivitySourceUser, activities[0].Source)
+	suite.Equal("You confirmed payment", activities[0].Description)
 	suite.Equal("Invoice Creation", activities[1].Title)
+	suite.Equal(models.ActivityEventInvoiceCreated, activities[1].EventType)
+	var created models.InvoiceCreatedPayload
+	suite.Require().NoError(json.Unmarshal(activities[1].Payload, &created))
+	suite.Equal("NGN", created.Currency)
+
+	// the activities are filtered by event type and source
+	activities, _, err = suite.repo.Invoice.GetInvoiceActivities(suite.ctx, suite.ids.senderID, suite.ids.invoiceID,
+		models.ActivityFilter{EventTypes: []models.ActivityEventType{models.ActivityEventInvoiceCreated}}, pagination.Request{Limit: 5, Page: 1})
+	suite.Require().NoError(err)
+	suite.Require().Len(activities, 1)
+	suite.Equal(models.ActivityEventInvoiceCreated, activities[0].EventType)
+
+	activities, _, err = suite.repo.Invoice.GetInvoiceActivities(suite.ctx, suite.ids.senderID, suite.ids.invoiceID,
+		models.ActivityFilter{Source: models.ActivitySourceUser}, pagination.Request{Limit: 5, Page: 1})
+	suite.Require().NoError(err)
+	suite.Require().Len(activities, 1)
+	suite.Equal(models.ActivityEventComment, activities[0].EventType)
+}
+
+func (suite *InvoiceRepoTestSuite) TestActivityEvents() {
+	ids := suite.createTestSender()
+
+	ctx, cancel := context.WithCancel(suite.ctx)
+	listening := make(chan struct{})
+	notifications := make(chan models.ActivityNotification, 10)
+	done := make(chan error)
+	go func() {
+		done <- suite.repo.Event.ListenActivityEvents(ctx, func() { close(listening) }, func(notification models.ActivityNotification) {
+			notifications <- notification
+		})
+	}()
+	<-listening
+
+	// every server is notified of the events recorded by any of them
+	invoiceID := suite.createTestInvoice(ids, models.InvoiceStatusPending, time.Now(), time.Now().AddDate(0, 0, 30), 100, "NGN")
+	var notification models.ActivityNotification
+	select {
+	case notification = <-notifications:
+	case <-time.After(5 * time.Second):
+		suite.FailNow("no notification received")
+	}
+	suite.Equal(ids.senderID, notification.UserID)
+
+	event, err := suite.repo.Event.GetActivityEvent(suite.ctx, notification.Sequence)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(event)
+	suite.Equal(invoiceID, event.InvoiceID)
+	suite.Equal(models.ActivityEventInvoiceCreated, event.EventType)
+	created := event
+
+	event, err = suite.repo.Event.GetActivityEvent(suite.ctx, notification.Sequence+1000000)
+	suite.Require().NoError(err)
+	suite.Nil(event)
+
+	// a stream resumes after the last event its client received
+	commentID, err := suite.repo.Invoice.AddInvoiceActivity(suite.ctx, models.NewInvoiceActivity(invoiceID, ids.senderID,
+		models.ActivityEventComment, nil, "Comment", "Sent by post"))
+	suite.Require().NoError(err)
+	events, err := suite.repo.Event.GetActivityEvents(suite.ctx, ids.senderID, created.Cursor(), 10)
+	suite.Require().NoError(err)
+	suite.Require().Len(events, 1)
+	suite.Equal(commentID, events[0].ActivityID)
+	suite.Greater(events[0].Sequence, notification.Sequence)
+
+	// a stream resumes from the position of the last event its client received
+	suite.Positive(notification.Position)
+	suite.LessOrEqual(notification.Position, created.Xid)
+	events, err = suite.repo.Event.GetActivityEventsSince(suite.ctx, ids.senderID, created.Xid+1, 10)
+	suite.Require().NoError(err)
+	suite.Require().Len(events, 1)
+	suite.Equal(commentID, events[0].ActivityID)
+	suite.Equal(events[0].Xid, events[0].Position)
+
+	// an event that commits after a newer one is still sent to streams resuming from the newer one
+	tx, err := suite.dbPool.Begin(suite.ctx)
+	suite.Require().NoError(err)
+	defer tx.Rollback(suite.ctx)
+	late := models.NewInvoiceActivity(invoiceID, ids.senderID, models.ActivityEventComment, nil, "Comment", "Late")
+	suite.Require().NoError(recordActivity(suite.ctx, tx, late))
+
+	newerID, err := suite.repo.Invoice.AddInvoiceActivity(suite.ctx, models.NewInvoiceActivity(invoiceID, ids.senderID,
+		models.ActivityEventComment, nil, "Comment", "Newer"))
+	suite.Require().NoError(err)
+	events, err = suite.repo.Event.GetActivityEventsSince(suite.ctx, ids.senderID, events[0].Position+1, 10)
+	suite.Require().NoError(err)
+	suite.Require().Len(events, 1)
+	suite.Equal(newerID, events[0].ActivityID)
+	// the position stops at the transaction still in progress
+	suite.Less(events[0].Position, events[0].Xid)
+
+	suite.Require().NoError(tx.Commit(suite.ctx))
+	events, err = suite.repo.Event.GetActivityEventsSince(suite.ctx, ids.senderID, events[0].Position, 10)
+	suite.Require().NoError(err)
+	suite.Require().Len(events, 2)
+	suite.Equal(late.ActivityID, events[0].ActivityID)
+	suite.Equal(newerID, events[1].ActivityID)
+
+	cancel()
+	suite.NoError(<-done)
+}
+
+// createTestSender creates a new sender together with a customer and a payment method, so tests that need their
+// own invoices do not affect the totals and counts asserted against the shared test data.
+func (suite *InvoiceRepoTestSuite) createTestSender() testID {
+	var ids testID
+	unique := uuid.NewString()
+
+	userID, err := suite.repo.User.CreateUser(suite.ctx, models.User{
+		UserID:       uuid.New(),
+		Username:     "user-" + unique,
+		Email:        unique + "@example.com",
+		Password:     "Password",
+		FirstName:    "First name",
+		LastName:     "Last name",
+		BaseCurrency: "NGN",
+	})
+	suite.Require().NoError(err)
+	ids.senderID = userID
+
+	customerID, err := suite.repo.User.AddCustomer(suite.ctx, models.Customer{
+		CustomerID: uuid.New(),
+		UserID:     userID,
+		Name:       "Customer " + unique,
+		Email:      "customer-" + unique + "@example.com",
+	})
+	suite.Require().NoError(err)
+	ids.customerID = customerID
+
+	paymentMethodID, err := suite.repo.User.AddPaymentMethod(suite.ctx, models.UserPaymentMethod{
+		PaymentMethodID: uuid.New(),
+		UserID:          userID,
+		AccountName:     "Account Name",
+		AccountNumber:   "0123456789",
+		BankName:        "Bank Name",
+	})
+	suite.Require().NoError(err)
+	ids.paymentMethodID = paymentMethodID
+
+	return ids
+}
+
+// createTestInvoice creates a single item invoice for the given sender and returns its ID. Invoices in the sender's
+// base currency get an exchange rate of 1; others are created without a locked rate.
+func (suite *InvoiceRepoTestSuite) createTestInvoice(ids testID, status models.InvoiceStatus, issueDate, dueDate time.Time, amount float64, currency string) uuid.UUID {
+	var exchangeRate *float64
+	if currency == "NGN" {
+		rate := 1.0
+		exchangeRate = &rate
+	}
+
+	invoiceID := uuid.New()
+	invoice := models.Invoice{
+		InvoiceID:     invoiceID,
+		InvoiceNumber: helpers.RandomNumber(1000000000, 9999999999),
+		SenderID:      ids.senderID,
+		CustomerID:    ids.customerID,
+		IssueDate:     issueDate,
+		DueDate:       dueDate,
+		TotalAmount:   amount,
+		FinalAmount:   amount,
+		Status:        string(status),
+		Currency:      currency,
+		BaseCurrency:  "NGN",
+		ExchangeRate:  exchangeRate,
+	}
+	items := []models.InvoiceItem{
+		{ItemID: uuid.New(), InvoiceID: invoiceID, Name: "Item", Description: "Description", Quantity: 1, UnitPrice: amount, TotalPrice: amount},
+	}
+	paymentInfo := models.PaymentInformation{PaymentInfoID: uuid.New(), InvoiceID: invoiceID, PaymentMethodID: ids.paymentMethodID}
+
+	id, err := suite.repo.Invoice.CreateInvoice(suite.ctx, invoice, items, nil, ids.customerID, paymentInfo)
+	suite.Require().NoError(err)
+	return id
+}
+
+func (suite *InvoiceRepoTestSuite) TestBatchInserts() {
+	ids := suite.createTestSender()
+
+	customers := []models.Customer{
+		{CustomerID: uuid.New(), UserID: ids.senderID, Name: "Batch customer 1", Email: "batch1@example.com"},
+		{CustomerID: uuid.New(), UserID: ids.senderID, Name: "Batch customer 2", Email: "batch2@example.com"},
+	}
+	suite.Require().NoError(suite.repo.User.AddCustomers(suite.ctx, customers))
+
+	// a batch failing on its last customer stores none of them
+	err := suite.repo.User.AddCustomers(suite.ctx, []models.Customer{
+		{CustomerID: uuid.New(), UserID: ids.senderID, Name: "Batch customer 3", Email: "batch3@example.com"},
+		customers[0],
+	})
+	suite.Error(err)
+
+	var count int
+	err = suite.dbPool.QueryRow(suite.ctx, `SELECT COUNT(*) FROM customers WHERE name LIKE 'Batch customer %'`).Scan(&count)
+	suite.Require().NoError(err)
+	suite.Equal(2, count)
+
+	newInvoice := func(number string) models.NewInvoice {
+		invoiceID := uuid.New()
+		return models.NewInvoice{
+			Invoice: models.Invoice{
+				InvoiceID: invoiceID, InvoiceNumber: number, SenderID: ids.senderID, CustomerID: ids.customerID,
+				IssueDate: time.Now(), DueDate: time.Now().AddDate(0, 0, 30), TotalAmount: 100, FinalAmount: 100,
+				Status: string(models.InvoiceStatusPending), Currency: "NGN", BaseCurrency: "NGN",
+			},
+			Items: []models.InvoiceItem{
+				{ItemID: uuid.New(), InvoiceID: invoiceID, Name: "Item", Description: "Description", Quantity: 1, UnitPrice: 100, TotalPrice: 100},
+			},
+			PaymentInfo: models.PaymentInformation{PaymentInfoID: uuid.New(), InvoiceID: invoiceID, PaymentMethodID: ids.paymentMethodID},
+		}
+	}
+
+	first, second := newInvoice("BATCH-001"), newInvoice("BATCH-002")
+	rejected, err := suite.repo.Invoice.CreateInvoices(suite.ctx, []models.NewInvoice{first, second})
+	suite.Require().NoError(err)
+	suite.Equal([]error{nil, nil}, rejected)
+
+	details, err := suite.repo.Invoice.GetInvoiceDetails(suite.ctx, second.Invoice.InvoiceID)
+	suite.Require().NoError(err)
+	suite.Equal("BATCH-002", details.Invoice.InvoiceNumber)
+	suite.Len(details.Items, 1)
+
+	// an invoice for a customer of another sender is rejected on its own, while the rest of the batch is stored
+	other := suite.createTestSender()
+	foreign, fourth := newInvoice("BATCH-FOREIGN"), newInvoice("BATCH-004")
+	foreign.Invoice.CustomerID = other.customerID
+	rejected, err = suite.repo.Invoice.CreateInvoices(suite.ctx, []models.NewInvoice{foreign, fourth})
+	suite.Require().NoError(err)
+	suite.Require().Len(rejected, 2)
+	suite.ErrorIs(rejected[0], models.ErrCustomerNotOwned)
+	suite.NoError(rejected[1])
+	_, err = suite.repo.Invoice.GetInvoiceDetails(suite.ctx, foreign.Invoice.InvoiceID)
+	suite.Error(err)
+	_, err = suite.repo.Invoice.GetInvoiceDetails(suite.ctx, fourth.Invoice.InvoiceID)
+	suite.NoError(err)
+
+	// any other error rolls the whole batch back
+	third := newInvoice("BATCH-003")
+	_, err = suite.repo.Invoice.CreateInvoices(suite.ctx, []models.NewInvoice{third, newInvoice("BATCH-001")})
+	suite.Error(err)
+	_, err = suite.repo.Invoice.GetInvoiceDetails(suite.ctx, third.Invoice.InvoiceID)
+	suite.Error(err)
+}
+
+func (suite *InvoiceRepoTestSuite) TestInvoiceOwnership() {
+	ids, other := suite.createTestSender(), suite.createTestSender()
+	createInvoice := func(customerID, paymentMethodID uuid.UUID) (uuid.UUID, error) {
+		invoiceID := uuid.New()
+		invoice := models.Invoice{
+			InvoiceID: invoiceID, InvoiceNumber: helpers.RandomNumber(1000000000, 9999999999), SenderID: ids.senderID,
+			CustomerID: customerID, IssueDate: time.Now(), DueDate: time.Now().AddDate(0, 0, 30), TotalAmount: 100,
+			FinalAmount: 100, Status: string(models.InvoiceStatusPending), Currency: "NGN", BaseCurrency: "NGN",
+		}
+		items := []models.InvoiceItem{
+			{ItemID: uuid.New(), InvoiceID: invoiceID, Name: "Item", Description: "Description", Quantity: 1, UnitPrice: 100, TotalPrice: 100},
+		}
+		paymentInfo := models.PaymentInformation{PaymentInfoID: uuid.New(), InvoiceID: invoiceID, PaymentMethodID: paymentMethodID}
+		return suite.repo.Invoice.CreateInvoice(suite.ctx, invoice, items, nil, customerID, paymentInfo)
+	}
+	invoiceCount := func() int {
+		var count int
+		err := suite.dbPool.QueryRow(suite.ctx, `SELECT COUNT(*) FROM invoices WHERE sender_id = $1`, ids.senderID).Scan(&count)
+		suite.Require().NoError(err)
+		return count
+	}
+
+	_, err := createInvoice(ids.customerID, ids.paymentMethodID)
+	suite.Require().NoError(err)
+
+	_, err = createInvoice(other.customerID, ids.paymentMethodID)
+	suite.ErrorIs(err, models.ErrCustomerNotOwned)
+	_, err = createInvoice(ids.customerID, other.paymentMethodID)
+	suite.ErrorIs(err, models.ErrPaymentMethodNotOwned)
+	_, err = createInvoice(uuid.New(), ids.paymentMethodID)
+	suite.ErrorIs(err, models.ErrCustomerNotOwned)
+	suite.Equal(1, invoiceCount())
+
+	// every customer has an owner, who must exist
+	_, err = suite.dbPool.Exec(suite.ctx, `INSERT INTO customers (customer_id, name, email) VALUES ($1, 'Unowned', 'unowned@example.com')`, uuid.New())
+	suite.Error(err)
+	_, err = suite.repo.User.AddCustomer(suite.ctx, models.Customer{CustomerID: uuid.New(), UserID: uuid.New(), Name: "Orphan", Email: "orphan@example.com"})
+	suite.ErrorIs(err, models.ErrUserNotFound)
+
+	// deleted payment methods cannot be used for new invoices
+	deleted, err := suite.repo.User.AddPaymentMethod(suite.ctx, models.UserPaymentMethod{
+		PaymentMethodID: uuid.New(), UserID: ids.senderID, AccountName: "Account Name", AccountNumber: "0123456789", BankName: "Bank",
+	})
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.repo.User.DeletePaymentMethod(suite.ctx, ids.senderID, deleted))
+	_, err = createInvoice(ids.customerID, deleted)
+	suite.ErrorIs(err, models.ErrPaymentMethodNotOwned)
+	suite.Equal(1, invoiceCount())
+
+	owned, err := suite.repo.User.GetOwnedCustomerIDs(suite.ctx, ids.senderID, []uuid.UUID{ids.customerID, other.customerID, uuid.New()})
+	suite.Require().NoError(err)
+	suite.Equal([]uuid.UUID{ids.customerID}, owned)
+}
+
+func (suite *InvoiceRepoTestSuite) TestPaymentMethods() {
+	ids := suite.createTestSender()
+	addPaymentMethod := func(bankName string, isDefault bool) uuid.UUID {
+		id, err := suite.repo.User.AddPaymentMethod(suite.ctx, models.UserPaymentMethod{
+			PaymentMethodID: uuid.New(),
+			UserID:          ids.senderID,
+			AccountName:     "Account Name",
+			AccountNumber:   "0123456789",
+			BankName:        bankName,
+			IsDefault:       isDefault,
+		})
+		suite.Require().NoError(err)
+		return id
+	}
+	defaultID := func() uuid.UUID {
+		paymentMethod, err := suite.repo.User.GetDefaultPaymentMethod(suite.ctx, ids.senderID)
+		suite.Require().NoError(err)
+		suite.Require().NotNil(paymentMethod)
+		return paymentMethod.PaymentMethodID
+	}
+
+	// the first payment method of a sender becomes their default
+	suite.Equal(ids.paymentMethodID, defaultID())
+	second := addPaymentMethod("Second Bank", false)
+	suite.Equal(ids.paymentMethodID, defaultID())
+	third := addPaymentMethod("Third Bank", true)
+	suite.Equal(third, defaultID())
+
+	suite.Require().NoError(suite.repo.User.SetDefaultPaymentMethod(suite.ctx, ids.senderID, second))
+	paymentMethods, err := suite.repo.User.GetPaymentMethods(suite.ctx, ids.senderID)
+	suite.Require().NoError(err)
+	suite.Require().Len(paymentMethods, 3)
+	suite.Equal(second, paymentMethods[0].PaymentMethodID)
+	suite.True(paymentMethods[0].IsDefault)
+	suite.False(paymentMethods[1].IsDefault)
+	suite.False(paymentMethods[2].IsDefault)
+
+	other := suite.createTestSender()
+	err = suite.repo.User.SetDefaultPaymentMethod(suite.ctx, other.senderID, second)
+	suite.ErrorIs(err, models.ErrPaymentMethodNotFound)
+
+	bankName := "Renamed Bank"
+	updated, err := suite.repo.User.UpdatePaymentMethod(suite.ctx, ids.senderID, third, models.PaymentMethodUpdate{BankName: &bankName})
+	suite.Require().NoError(err)
+	suite.Equal(bankName, updated.BankName)
+	suite.Equal("****6789", updated.MaskedAccountNumber)
+	suite.Equal(models.AccountTypeOther, updated.AccountType)
+
+	// the routing number and sort code are replaced along with the account number
+	accountType, accountNumber, routingNumber := models.AccountTypeUS, "000123456789", "021000021"
+	updated, err = suite.repo.User.UpdatePaymentMethod(suite.ctx, ids.senderID, third, models.PaymentMethodUpdate{
+		AccountType: &accountType, AccountNumber: &accountNumber, RoutingNumber: &routingNumber,
+	})
+	suite.Require().NoError(err)
+	suite.Equal(models.AccountTypeUS, updated.AccountType)
+	suite.Equal(routingNumber, updated.RoutingNumber)
+	accountType, accountNumber, sortCode := models.AccountTypeUK, "12345678", "123456"
+	updated, err = suite.repo.User.UpdatePaymentMethod(suite.ctx, ids.senderID, third, models.PaymentMethodUpdate{
+		AccountType: &accountType, AccountNumber: &accountNumber, SortCode: &sortCode,
+	})
+	suite.Require().NoError(err)
+	suite.Empty(updated.RoutingNumber)
+	suite.Equal(sortCode, updated.SortCode)
+	suite.Equal("****5678", updated.MaskedAccountNumber)
+	updated, err = suite.repo.User.UpdatePaymentMethod(suite.ctx, ids.senderID, third, models.PaymentMethodUpdate{BankName: &bankName})
+	suite.Require().NoError(err)
+	suite.Equal(sortCode, updated.SortCode)
+
+	// a payment method invoices were sent with is only marked as deleted, and the oldest method becomes the default
+	invoiceID := suite.createTestInvoice(testID{senderID: ids.senderID, customerID: ids.customerID, paymentMethodID: second},
+		models.InvoiceStatusPending, time.Now(), time.Now().AddDate(0, 0, 30), 100, "NGN")
+
+	// a payment method invoices were sent with cannot be changed
+	_, err = suite.repo.User.UpdatePaymentMethod(suite.ctx, ids.senderID, second, models.PaymentMethodUpdate{BankName: &bankName})
+	suite.ErrorIs(err, models.ErrPaymentMethodInUse)
+	details, err := suite.repo.Invoice.GetInvoiceDetails(suite.ctx, invoiceID)
+	suite.Require().NoError(err)
+	suite.Equal("Second Bank", details.PaymentInformation.BankName)
+
+	suite.Require().NoError(suite.repo.User.DeletePaymentMethod(suite.ctx, ids.senderID, second))
+	suite.Equal(ids.paymentMethodID, defaultID())
+	details, err = suite.repo.Invoice.GetInvoiceDetails(suite.ctx, invoiceID)
+	suite.Require().NoError(err)
+	suite.Equal(second, details.PaymentInformation.PaymentMethodID)
+
+	suite.Require().NoError(suite.repo.User.DeletePaymentMethod(suite.ctx, ids.senderID, third))
+	var count int
+	err = suite.dbPool.QueryRow(suite.ctx, `SELECT COUNT(*) FROM user_payment_methods WHERE payment_method_id = $1`, third).Scan(&count)
+	suite.Require().NoError(err)
+	suite.Zero(count)
+
+	paymentMethods, err = suite.repo.User.GetPaymentMethods(suite.ctx, ids.senderID)
+	suite.Require().NoError(err)
+	suite.Len(paymentMethods, 1)
+	err = suite.repo.User.DeletePaymentMethod(suite.ctx, ids.senderID, second)
+	suite.ErrorIs(err, models.ErrPaymentMethodNotFound)
+	_, err = suite.repo.User.UpdatePaymentMethod(suite.ctx, ids.senderID, second, models.PaymentMethodUpdate{BankName: &bankName})
+	suite.ErrorIs(err, models.ErrPaymentMethodNotFound)
+}
+
+func (suite *InvoiceRepoTestSuite) TestAccountNumberEncryption() {
+	ids := suite.createTestSender()
+	invoiceID := suite.createTestInvoice(ids, models.InvoiceStatusPending, time.Now(), time.Now().AddDate(0, 0, 30), 100, "NGN")
+
+	var plaintext *string
+	var keyID string
+	var ciphertext []byte
+	err := suite.dbPool.QueryRow(suite.ctx, `
+        SELECT account_number, account_number_key_id, account_number_ciphertext
+        FROM user_payment_methods WHERE payment_method_id = $1`,
+		ids.paymentMethodID,
+	).Scan(&plaintext, &keyID, &ciphertext)
+	suite.Require().NoError(err)
+	suite.Nil(plaintext)
+	suite.Equal("test-1", keyID)
+	suite.NotContains(string(ciphertext), "0123456789")
+
+	// an account number stored before account numbers were encrypted
+	legacy := suite.createTestSender()
+	_, err = suite.dbPool.Exec(suite.ctx, `
+        UPDATE user_payment_methods
+        SET account_number = '9876543210', account_number_ciphertext = NULL, account_number_data_key = NULL,
+            account_number_key_id = NULL, account_number_last4 = '3210'
+        WHERE payment_method_id = $1`,
+		legacy.paymentMethodID,
+	)
+	suite.Require().NoError(err)
+
+	rotated, err := encryption.NewKeyring("test-2", suite.keys)
+	suite.Require().NoError(err)
+	repo := NewRepository(suite.dbPool, rotated)
+	total := 0
+	for {
+		count, err := repo.User.ReencryptAccountNumbers(suite.ctx, 2)
+		suite.Require().NoError(err)
+		if count == 0 {
+			break
+		}
+		total += count
+	}
+	suite.GreaterOrEqual(total, 2)
+
+	err = suite.dbPool.QueryRow(suite.ctx, `
+        SELECT account_number, account_number_key_id FROM user_payment_methods WHERE payment_method_id = $1`,
+		legacy.paymentMethodID,
+	).Scan(&plaintext, &keyID)
+	suite.Require().NoError(err)
+	suite.Nil(plaintext)
+	suite.Equal("test-2", keyID)
+
+	details, err := repo.Invoice.GetInvoiceDetails(suite.ctx, invoiceID)
+	suite.Require().NoError(err)
+	suite.Equal("0123456789", details.PaymentInformation.AccountNumber)
+	suite.Equal("****6789", details.PaymentInformation.MaskedAccountNumber)
+
+	paymentMethods, err := repo.User.GetPaymentMethods(suite.ctx, legacy.senderID)
+	suite.Require().NoError(err)
+	suite.Require().Len(paymentMethods, 1)
+	suite.Empty(paymentMethods[0].AccountNumber)
+	suite.Equal("****3210", paymentMethods[0].MaskedAccountNumber)
+}
+
+func (suite *InvoiceRepoTestSuite) TestExchangeRates() {
+	june1 := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	june3 := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+
+	saved, err := suite.repo.Currency.SaveExchangeRates(suite.ctx, []models.ExchangeRate{
+		{FromCurrency: "CHF", ToCurrency: "JPY", Rate: 170, EffectiveDate: june1, Source: "file"},
+		{FromCurrency: "CHF", ToCurrency: "JPY", Rate: 172, EffectiveDate: june3, Source: "file"},
+	})
+	suite.Require().NoError(err)
+	suite.Equal(2, saved)
+
+	// saving a rate again replaces it
+	_, err = suite.repo.Currency.SaveExchangeRates(suite.ctx, []models.ExchangeRate{
+		{FromCurrency: "CHF", ToCurrency: "JPY", Rate: 160, EffectiveDate: june1, Source: "api"},
+	})
+	suite.Require().NoError(err)
+
+	// the latest rate on or before the date applies
+	rate, err := suite.repo.Currency.GetExchangeRate(suite.ctx, "CHF", "JPY", june1.AddDate(0, 0, 1))
+	suite.Require().NoError(err)
+	suite.Equal(160.0, rate.Rate)
+	suite.Equal("api", rate.Source)
+	suite.Equal(june1, rate.EffectiveDate)
+
+	rate, err = suite.repo.Currency.GetExchangeRate(suite.ctx, "CHF", "JPY", june3.AddDate(0, 1, 0))
+	suite.Require().NoError(err)
+	suite.Equal(172.0, rate.Rate)
+
+	// the opposite pair is converted with the inverse rate
+	rate, err = suite.repo.Currency.GetExchangeRate(suite.ctx, "JPY", "CHF", june3)
+	suite.Require().NoError(err)
+	suite.Equal("JPY", rate.FromCurrency)
+	suite.Equal("CHF", rate.ToCurrency)
+	suite.InDelta(1.0/172, rate.Rate, 1e-8)
+
+	_, err = suite.repo.Currency.GetExchangeRate(suite.ctx, "CHF", "JPY", june1.AddDate(0, 0, -1))
+	suite.ErrorIs(err, models.ErrExchangeRateNotFound)
+	suite.ErrorContains(err, "no exchange rate from CHF to JPY on 2024-05-31")
+
+	// issued invoices created before their rate was known get it locked once it is saved, drafts do not
+	ids := suite.createTestSender()
+	issuedID := suite.createTestInvoice(ids, models.InvoiceStatusPending, june3, june3.AddDate(0, 0, 30), 100, "CAD")
+	draftID := suite.createTestInvoice(ids, models.InvoiceStatusDraft, june3, june3.AddDate(0, 0, 30), 100, "CAD")
+	_, err = suite.repo.Currency.SaveExchangeRates(suite.ctx, []models.ExchangeRate{
+		{FromCurrency: "NGN", ToCurrency: "CAD", Rate: 0.001, EffectiveDate: june1, Source: "file"},
+	})
+	suite.Require().NoError(err)
+
+	details, err := suite.repo.Invoice.GetInvoiceDetails(suite.ctx, issuedID)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(details.Invoice.ExchangeRate)
+	suite.InDelta(1000.0, *details.Invoice.ExchangeRate, 1e-6)
+	details, err = suite.repo.Invoice.GetInvoiceDetails(suite.ctx, draftID)
+	suite.Require().NoError(err)
+	suite.Nil(details.Invoice.ExchangeRate)
+
+	baseCurrency, err := suite.repo.Currency.GetBaseCurrency(suite.ctx, suite.ids.senderID)
+	suite.Require().NoError(err)
+	suite.Equal("NGN", baseCurrency)
+
+	_, err = suite.repo.Currency.GetBaseCurrency(suite.ctx, uuid.New())
+	suite.ErrorContains(err, "user not found")
+}
+
+func (suite *InvoiceRepoTestSuite) TestReminderRules() {
+	ids := suite.createTestSender()
+
+	for _, offset := range []int{7, -3, 0} {
+		_, err := suite.repo.Reminder.AddReminderRule(suite.ctx, models.ReminderRule{
+			RuleID:     uuid.New(),
+			UserID:     ids.senderID,
+			DaysOffset: offset,
+			Tone:       models.ReminderToneFriendly,
+		})
+		suite.Require().NoError(err)
+	}
+
+	// offsets are unique per sender
+	_, err := suite.repo.Reminder.AddReminderRule(suite.ctx, models.ReminderRule{
+		RuleID: uuid.New(), UserID: ids.senderID, DaysOffset: 7, Tone: models.ReminderToneFirm,
+	})
+	suite.Error(err)
+
+	rules, err := suite.repo.Reminder.GetReminderRules(suite.ctx, ids.senderID)
+	suite.Require().NoError(err)
+	suite.Require().Len(rules, 3)
+	suite.Equal(-3, rules[0].DaysOffset)
+	suite.Equal(0, rules[1].DaysOffset)
+	suite.Equal(7, rules[2].DaysOffset)
+	suite.True(rules[0].IsActive)
+
+	suite.Require().NoError(suite.repo.Reminder.DeleteReminderRule(suite.ctx, ids.senderID, rules[0].RuleID))
+	suite.Error(suite.repo.Reminder.DeleteReminderRule(suite.ctx, uuid.New(), rules[1].RuleID))
+
+	rules, err = suite.repo.Reminder.GetReminderRules(suite.ctx, ids.senderID)
+	suite.Require().NoError(err)
+	suite.Len(rules, 2)
+}
+
+func (suite *InvoiceRepoTestSuite) TestDashboard() {
+	ids := suite.createTestSender()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	dueSoonID := suite.createTestInvoice(ids, models.InvoiceStatusPending, today, today.AddDate(0, 0, 5), 1000, "NGN")
+	suite.createTestInvoice(ids, models.InvoiceStatusOverDue, today.AddDate(0, 0, -40), today.AddDate(0, 0, -10), 300, "NGN")
+	suite.createTestInvoice(ids, models.InvoiceStatusPending, today, today.AddDate(0, 0, 20), 200, "NGN")
+	suite.createTestInvoice(ids, models.InvoiceStatusDraft, today, today.AddDate(0, 0, 30), 999, "NGN")
+
+	_, err := suite.repo.Payment.RecordPayment(suite.ctx, ids.senderID, models.Payment{
+		PaymentID: uuid.New(), InvoiceID: dueSoonID, Amount: 400, PaidOn: today,
+	})
+	suite.Require().NoError(err)
+
+	dashboard, err := suite.repo.Dashboard.GetDashboard(suite.ctx, ids.senderID, today, 3)
+	suite.Require().NoError(err)
+	suite.Equal(ids.senderID, dashboard.SenderID)
+
+	suite.Require().Len(dashboard.Statuses, 3)
+	suite.Equal(models.InvoiceStatusDraft, dashboard.Statuses[0].Status)
+	suite.Equal(models.InvoiceStatusPending, dashboard.Statuses[2].Status)
+	suite.Equal(2, dashboard.Statuses[2].Count)
+
+	// drafts are not owed yet and payments reduce the balance
+	suite.Equal([]models.OpenBalance{
+		{Currency: "NGN", InvoiceCount: 3, Outstanding: 1100, Overdue: 300, DueNext7Days: 600, DueNext30Days: 800},
+	}, dashboard.Balances)
+
+	suite.Len(dashboard.RecentInvoices, 3)
+	suite.Len(dashboard.RecentActivities, 3)
+	suite.Equal("Payment Received", dashboard.RecentActivities[0].Title)
+}
+
+func (suite *InvoiceRepoTestSuite) TestAgingInvoices() {
+	ids := suite.createTestSender()
+	asOf := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	overdueID := suite.createTestInvoice(ids, models.InvoiceStatusOverDue, asOf.AddDate(0, 0, -60), asOf.AddDate(0, 0, -45), 500, "NGN")
+	paidLaterID := suite.createTestInvoice(ids, models.InvoiceStatusPending, asOf.AddDate(0, 0, -20), asOf.AddDate(0, 0, -5), 200, "NGN")
+	suite.createTestInvoice(ids, models.InvoiceStatusDraft, asOf.AddDate(0, 0, -20), asOf.AddDate(0, 0, -5), 999, "NGN")
+	suite.createTestInvoice(ids, models.InvoiceStatusPending, asOf.AddDate(0, 0, 1), asOf.AddDate(0, 0, 30), 300, "NGN")
+
+	// a partial payment before the aging date and the full payment of the other invoice after it
+	_, err := suite.repo.Payment.RecordPayment(suite.ctx, ids.senderID, models.Payment{
+		PaymentID: uuid.New(), InvoiceID: overdueID, Amount: 150, PaidOn: asOf.AddDate(0, 0, -1),
+	})
+	suite.Require().NoError(err)
+	_, err = suite.repo.Payment.RecordPayment(suite.ctx, ids.senderID, models.Payment{
+		PaymentID: uuid.New(), InvoiceID: paidLaterID, Amount: 200, PaidOn: asOf.AddDate(0, 0, 2),
+	})
+	suite.Require().NoError(err)
+
+	invoices, err := suite.repo.Aging.GetOpenInvoices(suite.ctx, ids.senderID, asOf, nil)
+	suite.Require().NoError(err)
+	suite.Require().Len(invoices, 2)
+	suite.Equal(overdueID, invoices[0].InvoiceID)
+	suite.Equal(350.0, invoices[0].Balance)
+	suite.Equal(ids.customerID, invoices[0].CustomerID)
+	suite.Equal(paidLaterID, invoices[1].InvoiceID)
+	suite.Equal(200.0, invoices[1].Balance)
+
+	otherCustomer := uuid.New()
+	invoices, err = suite.repo.Aging.GetOpenInvoices(suite.ctx, ids.senderID, asOf, &otherCustomer)
+	suite.Require().NoError(err)
+	suite.Empty(invoices)
+}
+
+func (suite *InvoiceRepoTestSuite) TestSeries() {
+	ids := suite.createTestSender()
+	jan := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+
+	janID := suite.createTestInvoice(ids, models.InvoiceStatusPending, jan, jan.AddDate(0, 0, 30), 400, "NGN")
+	suite.createTestInvoice(ids, models.InvoiceStatusPending, mar, mar.AddDate(0, 0, 30), 100, "NGN")
+	suite.createTestInvoice(ids, models.InvoiceStatusPending, mar, mar.AddDate(0, 0, 30), 20, "USD")
+	suite.createTestInvoice(ids, models.InvoiceStatusDraft, mar, mar.AddDate(0, 0, 30), 999, "NGN")
+
+	_, err := suite.repo.Payment.RecordPayment(suite.ctx, ids.senderID, models.Payment{
+		PaymentID: uuid.New(), InvoiceID: janID, Amount: 150, PaidOn: mar,
+	})
+	suite.Require().NoError(err)
+
+	filter := models.SeriesFilter{
+		SenderID: ids.senderID,
+		Metric:   models.SeriesMetricInvoiced,
+		Interval: models.SeriesIntervalMonth,
+		From:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	// the USD invoice has no locked rate, so it is left out of the base currency series
+	series, err := suite.repo.Report.GetSeries(suite.ctx, filter)
+	suite.Require().NoError(err)
+	suite.Require().Len(series, 1)
+	suite.Equal("NGN", series[0].Currency)
+	suite.Equal([]models.SeriesPoint{
+		{Period: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Count: 1, Amount: 400},
+		{Period: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Count: 1, Amount: 100},
+	}, series[0].Points)
+
+	filter.GroupBy = models.SeriesGroupByCurrency
+	series, err = suite.repo.Report.GetSeries(suite.ctx, filter)
+	suite.Require().NoError(err)
+	suite.Require().Len(series, 2)
+	suite.Equal("USD", series[1].Currency)
+	suite.Equal(20.0, series[1].Points[0].Amount)
+
+	filter.GroupBy = models.SeriesGroupByCustomer
+	filter.Metric = models.SeriesMetricCollected
+	series, err = suite.repo.Report.GetSeries(suite.ctx, filter)
+	suite.Require().NoError(err)
+	suite.Require().Len(series, 1)
+	suite.Equal(ids.customerID, *series[0].CustomerID)
+	suite.Equal([]models.SeriesPoint{
+		{Period: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Count: 1, Amount: 150},
+	}, series[0].Points)
+}
+
+func (suite *InvoiceRepoTestSuite) TestSearchInvoices() {
+	ids := suite.createTestSender()
+	jan := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	smallID := suite.createTestInvoice(ids, models.InvoiceStatusPending, jan, jan.AddDate(0, 0, 30), 100, "NGN")
+	largeID := suite.createTestInvoice(ids, models.InvoiceStatusOverDue, jan, jan.AddDate(0, 0, 10), 900, "NGN")
+	usdID := suite.createTestInvoice(ids, models.InvoiceStatusPaid, jan.AddDate(0, 1, 0), jan.AddDate(0, 2, 0), 500, "USD")
+
+	_, err := suite.dbPool.Exec(suite.ctx, `UPDATE invoices SET notes = 'Logo DESIGN work', invoice_number = 'SRCH_001' WHERE invoice_id = $1`, smallID)
+	suite.Require().NoError(err)
+	_, err = suite.dbPool.Exec(suite.ctx, `UPDATE invoice_items SET name = 'Website redesign' WHERE invoice_id = $1`, largeID)
+	suite.Require().NoError(err)
+
+	search := func(filter models.InvoiceFilter) []uuid.UUID {
+		filter.SenderID = ids.senderID
+		invoices, _, err := suite.repo.Invoice.SearchInvoices(suite.ctx, filter, pagination.Request{Limit: 10, Page: 1})
+		suite.Require().NoError(err)
+		found := []uuid.UUID{}
+		for _, invoice := range invoices {
+			found = append(found, invoice.InvoiceID)
+		}
+		return found
+	}
+
+	minAmount, maxAmount := 200.0, 900.0
+	dueTo := jan.AddDate(0, 0, 20)
+	suite.Equal([]uuid.UUID{smallID, usdID, largeID}, search(models.InvoiceFilter{SortBy: models.InvoiceSortFieldAmount, SortOrder: models.SortOrderAsc}))
+	suite.Equal([]uuid.UUID{largeID, smallID}, search(models.InvoiceFilter{Statuses: []models.InvoiceStatus{models.InvoiceStatusPending, models.InvoiceStatusOverDue}, SortBy: models.InvoiceSortFieldDueDate, SortOrder: models.SortOrderAsc}))
+	suite.Equal([]uuid.UUID{usdID}, search(models.InvoiceFilter{Currency: "USD"}))
+	suite.Equal([]uuid.UUID{largeID, usdID}, search(models.InvoiceFilter{MinAmount: &minAmount, MaxAmount: &maxAmount, SortBy: models.InvoiceSortFieldAmount}))
+	suite.Equal([]uuid.UUID{largeID}, search(models.InvoiceFilter{DueTo: &dueTo}))
+	suite.Equal([]uuid.UUID{smallID}, search(models.InvoiceFilter{NumberPrefix: "SRCH_"}))
+	suite.Empty(search(models.InvoiceFilter{NumberPrefix: "SRCH%"}))
+	suite.ElementsMatch([]uuid.UUID{smallID, largeID}, search(models.InvoiceFilter{Search: "design"}))
+}
+
+func (suite *InvoiceRepoTestSuite) TestExportInvoices() {
+	ids := suite.createTestSender()
+	jan := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	firstID := suite.createTestInvoice(ids, models.InvoiceStatusPending, jan, jan.AddDate(0, 0, 10), 100, "NGN")
+	secondID := suite.createTestInvoice(ids, models.InvoiceStatusPending, jan, jan.AddDate(0, 0, 20), 300, "NGN")
+	suite.createTestInvoice(ids, models.InvoiceStatusPaid, jan, jan.AddDate(0, 0, 30), 500, "NGN")
+	_, err := suite.dbPool.Exec(suite.ctx, `
+        INSERT INTO invoice_items (item_id, invoice_id, name, description, quantity, unit_price, total_price)
+        VALUES ($1, $2, 'Second item', 'Description', 2, 100, 200)`, uuid.New(), secondID)
+	suite.Require().NoError(err)
+
+	export := func(withItems bool) []models.InvoiceExportRow {
+		rows := []models.InvoiceExportRow{}
+		err := suite.repo.Invoice.ExportInvoices(suite.ctx, models.InvoiceFilter{
+			SenderID:  ids.senderID,
+			Statuses:  []models.InvoiceStatus{models.InvoiceStatusPending},
+			SortBy:    models.InvoiceSortFieldDueDate,
+			SortOrder: models.SortOrderAsc,
+		}, withItems, func(row models.InvoiceExportRow) error {
+			rows = append(rows, row)
+			return nil
+		})
+		suite.Require().NoError(err)
+		return rows
+	}
+
+	rows := export(false)
+	suite.Require().Len(rows, 2)
+	suite.Equal(firstID, rows[0].InvoiceID)
+	suite.Equal(secondID, rows[1].InvoiceID)
+	suite.Contains(rows[0].CustomerName, "Customer ")
+	suite.Nil(rows[0].Item)
+
+	rows = export(true)
+	suite.Require().Len(rows, 3)
+	suite.Equal(firstID, rows[0].InvoiceID)
+	suite.Equal("Item", rows[0].Item.Name)
+	suite.Equal(secondID, rows[1].InvoiceID)
+	suite.Equal(secondID, rows[2].InvoiceID)
+	suite.ElementsMatch([]string{"Item", "Second item"}, []string{rows[1].Item.Name, rows[2].Item.Name})
+
+	// an error returned for a row stops the export
+	stop := errors.New("stop")
+	calls := 0
+	err = suite.repo.Invoice.ExportInvoices(suite.ctx, models.InvoiceFilter{SenderID: ids.senderID}, true, func(models.InvoiceExportRow) error {
+		calls++
+		return stop
+	})
+	suite.ErrorIs(err, stop)
+	suite.Equal(1, calls)
+}
+
+func (suite *InvoiceRepoTestSuite) TestDueReminders() {
+	ids := suite.createTestSender()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	for offset, tone := range map[int]models.ReminderTone{-3: models.ReminderToneFriendly, 1: models.ReminderToneFirm, 30: models.ReminderToneFinal} {
+		_, err := suite.repo.Reminder.AddReminderRule(suite.ctx, models.ReminderRule{
+			RuleID: uuid.New(), UserID: ids.senderID, DaysOffset: offset, Tone: tone,
+		})
+		suite.Require().NoError(err)
+	}
+
+	overdueID := suite.createTestInvoice(ids, models.InvoiceStatusPending, today.AddDate(0, 0, -10), today.AddDate(0, 0, -2), 500, "NGN")
+	upcomingID := suite.createTestInvoice(ids, models.InvoiceStatusPending, today, today.AddDate(0, 0, 10), 500, "NGN")
+	draftID := suite.createTestInvoice(ids, models.InvoiceStatusDraft, today.AddDate(0, 0, -10), today.AddDate(0, 0, -2), 500, "NGN")
+
+	claim := func(asOf time.Time) map[uuid.UUID]models.DueReminder {
+		reminders, err := suite.repo.Reminder.ClaimDueReminders(suite.ctx, asOf, time.Hour, 1000)
+		suite.Require().NoError(err)
+		found := make(map[uuid.UUID]models.DueReminder)
+		for _, reminder := range reminders {
+			found[reminder.Invoice.InvoiceID] = reminder
+		}
+		return found
+	}
+
+	due := claim(today)
+	suite.Require().Contains(due, overdueID)
+	suite.NotContains(due, upcomingID)
+	suite.NotContains(due, draftID)
+
+	// only the furthest step reached is due
+	reminder := due[overdueID]
+	suite.Equal(1, reminder.Rule.DaysOffset)
+	suite.Equal(models.ReminderToneFirm, reminder.Rule.Tone)
+	suite.Equal("First name Last name", reminder.SenderName)
+	suite.NotEmpty(reminder.CustomerEmail)
+	suite.Zero(reminder.Attempts)
+	suite.Equal(500.0, reminder.BalanceDue)
+
+	// a claimed reminder is not claimed again by another server until its lease runs out
+	suite.NotContains(claim(today), overdueID)
+
+	// a failed reminder is due again after its delay, without holding back the other invoices
+	retryAt := today.Add(2 * time.Hour)
+	err := suite.repo.Reminder.RecordReminderFailure(suite.ctx, reminder.ReminderID, "smtp unavailable", &retryAt)
+	suite.Require().NoError(err)
+	suite.NotContains(claim(today.Add(time.Hour)), overdueID)
+	due = claim(retryAt)
+	suite.Require().Contains(due, overdueID)
+	suite.Equal(reminder.ReminderID, due[overdueID].ReminderID)
+	suite.Equal(1, due[overdueID].Attempts)
+
+	err = suite.repo.Reminder.RecordReminder(suite.ctx, models.InvoiceReminder{
+		ReminderID: reminder.ReminderID,
+		InvoiceID:  overdueID,
+		RuleID:     reminder.Rule.RuleID,
+		DaysOffset: reminder.Rule.DaysOffset,
+		Tone:       reminder.Rule.Tone,
+		SentTo:     reminder.CustomerEmail,
+	}, models.NewInvoiceActivity(overdueID, ids.senderID, models.ActivityEventEmailSent,
+		models.EmailSentPayload{To: reminder.CustomerEmail, Kind: "reminder", Tone: reminder.Rule.Tone},
+		"Payment Reminder", "Sent firm reminder"))
+	suite.Require().NoError(err)
+
+	// the reminder is not sent twice, and the earlier friendly step is skipped
+	suite.NotContains(claim(today.Add(24*time.Hour)), overdueID)
+
+	activities, _, err := suite.repo.Invoice.GetInvoiceActivities(suite.ctx, ids.senderID, overdueID, models.ActivityFilter{}, pagination.Request{Limit: 5, Page: 1})
+	suite.Require().NoError(err)
+	suite.Len(activities, 2)
+	suite.Equal("Payment Reminder", activities[0].Title)
+	suite.Equal(models.ActivityEventEmailSent, activities[0].EventType)
+}
+
+func (suite *InvoiceRepoTestSuite) TestLateFees() {
+	ids := suite.createTestSender()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	policy, err := suite.repo.LateFee.GetLateFeePolicy(suite.ctx, ids.senderID)
+	suite.Require().NoError(err)
+	suite.Nil(policy)
+
+	maxPercentage := 10.0
+	policyID, err := suite.repo.LateFee.SetLateFeePolicy(suite.ctx, models.LateFeePolicy{
+		PolicyID: uuid.New(), UserID: ids.senderID, FeeType: models.LateFeeTypeFlat, FlatAmount: 25, GraceDays: 5, IsActive: true,
+	})
+	suite.Require().NoError(err)
+
+	// replacing the policy keeps its ID
+	replacedID, err := suite.repo.LateFee.SetLateFeePolicy(suite.ctx, models.LateFeePolicy{
+		PolicyID: uuid.New(), UserID: ids.senderID, FeeType: models.LateFeeTypeDailyInterest, InterestRate: 0.5, GraceDays: 3,
+		MaxPercentage: &maxPercentage, IsActive: true,
+	})
+	suite.Require().NoError(err)
+	suite.Equal(policyID, replacedID)
+
+	policy, err = suite.repo.LateFee.GetLateFeePolicy(suite.ctx, ids.senderID)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(policy)
+	suite.Equal(models.LateFeeTypeDailyInterest, policy.FeeType)
+	suite.Equal(3, policy.GraceDays)
+	suite.Nil(policy.MaxAmount)
+	suite.Require().NotNil(policy.MaxPercentage)
+	suite.Equal(maxPercentage, *policy.MaxPercentage)
+
+	overdueID := suite.createTestInvoice(ids, models.InvoiceStatusOverDue, today.AddDate(0, 0, -20), today.AddDate(0, 0, -10), 1000, "NGN")
+	graceID := suite.createTestInvoice(ids, models.InvoiceStatusPending, today.AddDate(0, 0, -10), today.AddDate(0, 0, -2), 1000, "NGN")
+	draftID := suite.createTestInvoice(ids, models.InvoiceStatusDraft, today.AddDate(0, 0, -20), today.AddDate(0, 0, -10), 1000, "NGN")
+
+	candidatesFor := func() map[uuid.UUID]models.LateFeeCandidate {
+		candidates, err := suite.repo.LateFee.GetLateFeeCandidates(suite.ctx, today, uuid.Nil, 1000)
+		suite.Require().NoError(err)
+		found := make(map[uuid.UUID]models.LateFeeCandidate)
+		for _, candidate := range candidates {
+			found[candidate.Invoice.InvoiceID] = candidate
+		}
+		return found
+	}
+
+	candidates := candidatesFor()
+	suite.Require().Contains(candidates, overdueID)
+	suite.NotContains(candidates, graceID)
+	suite.NotContains(candidates, draftID)
+	suite.Equal(policyID, candidates[overdueID].Policy.PolicyID)
+	suite.Zero(candidates[overdueID].AppliedAmount)
+
+	adjustment := models.InvoiceAdjustment{
+		AdjustmentID:   uuid.New(),
+		InvoiceID:      overdueID,
+		PolicyID:       policyID,
+		AdjustmentType: models.AdjustmentTypeInterest,
+		Amount:         35,
+		Description:    "Interest charged",
+		AppliedOn:      today,
+	}
+	activity := models.NewInvoiceActivity(overdueID, ids.senderID, models.ActivityEventLateFeeApplied,
+		models.LateFeeAppliedPayload{AdjustmentID: adjustment.AdjustmentID, AdjustmentType: adjustment.AdjustmentType, Amount: 35},
+		"Late Fee Applied", "Interest charged")
+	ok, err := suite.repo.LateFee.ApplyLateFee(suite.ctx, adjustment, activity)
+	suite.Require().NoError(err)
+	suite.True(ok)
+
+	// a second adjustment of the same type on the same day is ignored
+	adjustment.AdjustmentID, activity.ActivityID = uuid.New(), uuid.New()
+	ok, err = suite.repo.LateFee.ApplyLateFee(suite.ctx, adjustment, activity)
+	suite.Require().NoError(err)
+	suite.False(ok)
+
+	suite.Equal(35.0, candidatesFor()[overdueID].AppliedAmount)
+
+	details, err := suite.repo.Invoice.GetInvoiceDetails(suite.ctx, overdueID)
+	suite.Require().NoError(err)
+	suite.Require().Len(details.Adjustments, 1)
+	suite.Equal(models.AdjustmentTypeInterest, details.Adjustments[0].AdjustmentType)
+	suite.Equal(35.0, details.Adjustments[0].Amount)
+
+	// inactive policies stop accruing fees
+	policy.IsActive = false
+	_, err = suite.repo.LateFee.SetLateFeePolicy(suite.ctx, *policy)
+	suite.Require().NoError(err)
+	suite.NotContains(candidatesFor(), overdueID)
+}
+
+func (suite *InvoiceRepoTestSuite) TestTaxRates() {
+	ids := suite.createTestSender()
+
+	vatID, err := suite.repo.Tax.CreateTaxRate(suite.ctx, models.TaxRate{
+		TaxRateID: uuid.New(), UserID: ids.senderID, Name: "VAT", Rate: 7.5,
+	})
+	suite.Require().NoError(err)
+	levyID, err := suite.repo.Tax.CreateTaxRate(suite.ctx, models.TaxRate{
+		TaxRateID: uuid.New(), UserID: ids.senderID, Name: "Levy", Rate: 1, IsCompound: true,
+	})
+	suite.Require().NoError(err)
+
+	// active names are unique per sender
+	_, err = suite.repo.Tax.CreateTaxRate(suite.ctx, models.TaxRate{TaxRateID: uuid.New(), UserID: ids.senderID, Name: "VAT", Rate: 5})
+	suite.Error(err)
+
+	taxRates, err := suite.repo.Tax.GetTaxRates(suite.ctx, ids.senderID)
+	suite.Require().NoError(err)
+	suite.Require().Len(taxRates, 2)
+	suite.Equal("Levy", taxRates[0].Name)
+	suite.True(taxRates[0].IsCompound)
+	suite.Equal(7.5, taxRates[1].Rate)
+
+	invoiceID, itemID := uuid.New(), uuid.New()
+	invoice := models.Invoice{
+		InvoiceID:     invoiceID,
+		InvoiceNumber: helpers.RandomNumber(1000000000, 9999999999),
+		SenderID:      ids.senderID,
+		CustomerID:    ids.customerID,
+		IssueDate:     time.Now(),
+		DueDate:       time.Now().AddDate(0, 0, 30),
+		TotalAmount:   1000,
+		FinalAmount:   1085.75,
+		Status:        string(models.InvoiceStatusDraft),
+		Currency:      "NGN",
+	}
+	items := []models.InvoiceItem{{
+		ItemID: itemID, InvoiceID: invoiceID, Name: "Item", Description: "Description", Quantity: 1, UnitPrice: 1000, TotalPrice: 1000,
+		Taxes: []models.InvoiceItemTax{
+			{ItemTaxID: uuid.New(), TaxRateID: vatID, Name: "VAT", Rate: 7.5, TaxableAmount: 1000, TaxAmount: 75},
+			{ItemTaxID: uuid.New(), TaxRateID: levyID, Name: "Levy", Rate: 1, IsCompound: true, TaxableAmount: 1075, TaxAmount: 10.75},
+		},
+	}}
+	taxLines := []models.InvoiceTaxLine{
+		{TaxLineID: uuid.New(), TaxRateID: vatID, Name: "VAT", Rate: 7.5, TaxableAmount: 1000, TaxAmount: 75},
+		{TaxLineID: uuid.New(), TaxRateID: levyID, Name: "Levy", Rate: 1, IsCompound: true, TaxableAmount: 1075, TaxAmount: 10.75},
+	}
+	paymentInfo := models.PaymentInformation{PaymentInfoID: uuid.New(), InvoiceID: invoiceID, PaymentMethodID: ids.paymentMethodID}
+	_, err = suite.repo.Invoice.CreateInvoice(suite.ctx, invoice, items, taxLines, ids.customerID, paymentInfo)
+	suite.Require().NoError(err)
+
+	details, err := suite.repo.Invoice.GetInvoiceDetails(suite.ctx, invoiceID)
+	suite.Require().NoError(err)
+	suite.Require().Len(details.Items, 1)
+	suite.Require().Len(details.Items[0].Taxes, 2)
+	suite.Equal("VAT", details.Items[0].Taxes[0].Name)
+	suite.Equal(10.75, details.Items[0].Taxes[1].TaxAmount)
+	suite.Require().Len(details.TaxLines, 2)
+	suite.Equal(invoiceID, details.TaxLines[0].InvoiceID)
+	suite.Equal(75.0, details.TaxLines[0].TaxAmount)
+	suite.True(details.TaxLines[1].IsCompound)
+
+	// deactivated rates are hidden but remain on issued invoices
+	suite.Require().NoError(suite.repo.Tax.DeactivateTaxRate(suite.ctx, ids.senderID, vatID))
+	suite.Error(suite.repo.Tax.DeactivateTaxRate(suite.ctx, ids.senderID, vatID))
+	suite.Error(suite.repo.Tax.DeactivateTaxRate(suite.ctx, uuid.New(), levyID))
+
+	taxRates, err = suite.repo.Tax.GetTaxRates(suite.ctx, ids.senderID)
+	suite.Require().NoError(err)
+	suite.Len(taxRates, 1)
+
+	details, err = suite.repo.Invoice.GetInvoiceDetails(suite.ctx, invoiceID)
+	suite.Require().NoError(err)
+	suite.Len(details.TaxLines, 2)
+}
+
+func (suite *InvoiceRepoTestSuite) TestPayments() {
+	ids := suite.createTestSender()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	invoiceID := suite.createTestInvoice(ids, models.InvoiceStatusPending, today, today.AddDate(0, 0, 30), 1000, "NGN")
+	draftID := suite.createTestInvoice(ids, models.InvoiceStatusDraft, today, today.AddDate(0, 0, 30), 1000, "NGN")
+
+	newPayment := func(invoiceID uuid.UUID, amount float64) models.Payment {
+		return models.Payment{PaymentID: uuid.New(), InvoiceID: invoiceID, Amount: amount, PaidOn: today, Reference: "TRF"}
+	}
+
+	_, err := suite.repo.Payment.RecordPayment(suite.ctx, uuid.New(), newPayment(invoiceID, 400))
+	suite.ErrorContains(err, "invoice not found")
+	_, err = suite.repo.Payment.RecordPayment(suite.ctx, ids.senderID, newPayment(draftID, 400))
+	suite.ErrorIs(err, models.ErrInvoiceIsDraft)
+	_, err = suite.repo.Payment.RecordPayment(suite.ctx, ids.senderID, newPayment(invoiceID, 1000.01))
+	suite.ErrorIs(err, models.ErrPaymentExceedsBalance)
+
+	// a partial payment leaves the invoice unpaid
+	payment := newPayment(invoiceID, 400)
+	paymentID, err := suite.repo.Payment.RecordPayment(suite.ctx, ids.senderID, payment)
+	suite.Require().NoError(err)
+	suite.Equal(payment.PaymentID, paymentID)
+
+	details, err := suite.repo.Invoice.GetInvoiceDetails(suite.ctx, invoiceID)
+	suite.Require().NoError(err)
+	suite.Equal(string(models.InvoiceStatusPending), details.Invoice.Status)
+	suite.Require().Len(details.Payments, 1)
+	suite.Equal(400.0, details.Payments[0].Amount)
+	suite.Equal("TRF", details.Payments[0].Reference)
+
+	// settling the balance marks the invoice as paid
+	_, err = suite.repo.Payment.RecordPayment(suite.ctx, ids.senderID, newPayment(invoiceID, 600.01))
+	suite.ErrorIs(err, models.ErrPaymentExceedsBalance)
+	_, err = suite.repo.Payment.RecordPayment(suite.ctx, ids.senderID, newPayment(invoiceID, 600))
+	suite.Require().NoError(err)
+
+	details, err = suite.repo.Invoice.GetInvoiceDetails(suite.ctx, invoiceID)
+	suite.Require().NoError(err)
+	suite.Equal(string(models.InvoiceStatusPaid), details.Invoice.Status)
+	suite.Len(details.Payments, 2)
+	last := details.Activities[len(details.Activities)-1]
+	suite.Equal(models.ActivityEventStatusChanged, last.EventType)
+	var changed models.StatusChangedPayload
+	suite.Require().NoError(json.Unmarshal(last.Payload, &changed))
+	suite.Equal(models.StatusChangedPayload{From: models.InvoiceStatusPending, To: models.InvoiceStatusPaid}, changed)
+	suite.Equal(models.ActivityEventPaymentRecorded, details.Activities[len(details.Activities)-2].EventType)
+
+	_, err = suite.repo.Payment.RecordPayment(suite.ctx, ids.senderID, newPayment(invoiceID, 1))
+	suite.ErrorIs(err, models.ErrInvoiceAlreadyPaid)
+}
+
+func (suite *InvoiceRepoTestSuite) TestPaymentSessions() {
+	ids := suite.createTestSender()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	invoiceID := suite.createTestInvoice(ids, models.InvoiceStatusPending, today, today.AddDate(0, 0, 30), 1000, "NGN")
+
+	_, err := suite.repo.Payment.RecordPayment(suite.ctx, ids.senderID, models.Payment{
+		PaymentID: uuid.New(), InvoiceID: invoiceID, Amount: 250, PaidOn: today, Reference: "TRF",
+	})
+	suite.Require().NoError(err)
+
+	invoice, err := suite.repo.Payment.GetPayableInvoice(suite.ctx, ids.senderID, invoiceID)
+	suite.Require().NoError(err)
+	suite.Equal(750.0, invoice.BalanceDue)
+	suite.Equal(models.InvoiceStatusPending, invoice.Status)
+	suite.Contains(invoice.CustomerEmail, "@example.com")
+
+	invoice, err = suite.repo.Payment.GetPayableInvoice(suite.ctx, uuid.New(), invoiceID)
+	suite.Require().NoError(err)
+	suite.Nil(invoice)
+
+	newSession := func(providerSessionID string) models.PaymentSession {
+		session := models.PaymentSession{
+			PaymentSessionID:  uuid.New(),
+			InvoiceID:         invoiceID,
+			Provider:          "fake",
+			ProviderSessionID: providerSessionID,
+			URL:               "http://localhost:8080/checkout/" + providerSessionID,
+			Amount:            750,
+			Currency:          "NGN",
+			Status:            models.PaymentSessionStatusOpen,
+			ExpiresAt:         time.Now().Add(time.Hour),
+		}
+		suite.Require().NoError(suite.repo.Payment.CreatePaymentSession(suite.ctx, session))
+		return session
+	}
+	failed := newSession("fake_cs_" + uuid.NewString())
+	paid := newSession("fake_cs_" + uuid.NewString())
+
+	session, err := suite.repo.Payment.GetPaymentSession(suite.ctx, "fake", paid.ProviderSessionID)
+	suite.Require().NoError(err)
+	suite.Equal(paid.PaymentSessionID, session.PaymentSessionID)
+	suite.Equal(models.PaymentSessionStatusOpen, session.Status)
+
+	session, err = suite.repo.Payment.GetPaymentSession(suite.ctx, "other", paid.ProviderSessionID)
+	suite.Require().NoError(err)
+	suite.Nil(session)
+
+	suite.Require().NoError(suite.repo.Payment.FailPaymentSession(suite.ctx, failed.PaymentSessionID))
+	session, err = suite.repo.Payment.GetPaymentSession(suite.ctx, "fake", failed.ProviderSessionID)
+	suite.Require().NoError(err)
+	suite.Equal(models.PaymentSessionStatusFailed, session.Status)
+
+	// completing the session pays the invoice, and completing it again records nothing more
+	payment := models.Payment{PaymentID: uuid.New(), Amount: 750, PaidOn: today, Reference: "PAY-1"}
+	session, err = suite.repo.Payment.CompletePaymentSession(suite.ctx, paid.PaymentSessionID, payment)
+	suite.Require().NoError(err)
+	suite.Equal(models.PaymentSessionStatusCompleted, session.Status)
+	suite.Equal(&payment.PaymentID, session.PaymentID)
+
+	session, err = suite.repo.Payment.CompletePaymentSession(suite.ctx, paid.PaymentSessionID,
+		models.Payment{PaymentID: uuid.New(), Amount: 750, PaidOn: today, Reference: "PAY-1"})
+	suite.Require().NoError(err)
+	suite.Equal(&payment.PaymentID, session.PaymentID)
+
+	details, err := suite.repo.Invoice.GetInvoiceDetails(suite.ctx, invoiceID)
+	suite.Require().NoError(err)
+	suite.Equal(string(models.InvoiceStatusPaid), details.Invoice.Status)
+	suite.Len(details.Payments, 2)
+
+	// a session paid after the invoice was paid in full is still completed, recording the payment to refund
+	late := newSession("fake_cs_" + uuid.NewString())
+	latePayment := models.Payment{PaymentID: uuid.New(), Amount: 750, PaidOn: today, Reference: "PAY-2"}
+	session, err = suite.repo.Payment.CompletePaymentSession(suite.ctx, late.PaymentSessionID, latePayment)
+	suite.Require().NoError(err)
+	suite.Equal(models.PaymentSessionStatusCompleted, session.Status)
+	suite.Equal(&latePayment.PaymentID, session.PaymentID)
+
+	details, err = suite.repo.Invoice.GetInvoiceDetails(suite.ctx, invoiceID)
+	suite.Require().NoError(err)
+	suite.Equal(string(models.InvoiceStatusPaid), details.Invoice.Status)
+	suite.Len(details.Payments, 3)
+	var statusChanges int
+	err = suite.dbPool.QueryRow(suite.ctx, `
+        SELECT COUNT(*) FROM activity_events WHERE invoice_id = $1 AND event_type = $2`,
+		invoiceID, models.ActivityEventStatusChanged,
+	).Scan(&statusChanges)
+	suite.Require().NoError(err)
+	suite.Equal(1, statusChanges)
+
+	// a completed session is not failed afterwards
+	suite.Require().NoError(suite.repo.Payment.FailPaymentSession(suite.ctx, paid.PaymentSessionID))
+	session, err = suite.repo.Payment.GetPaymentSession(suite.ctx, "fake", paid.ProviderSessionID)
+	suite.Require().NoError(err)
+	suite.Equal(models.PaymentSessionStatusCompleted, session.Status)
+
+	_, err = suite.repo.Payment.CompletePaymentSession(suite.ctx, uuid.New(), payment)
+	suite.ErrorIs(err, models.ErrPaymentSessionNotFound)
+}
+
+func (suite *InvoiceRepoTestSuite) TestPaymentRefunds() {
+	ids := suite.createTestSender()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	invoiceID := suite.createTestInvoice(ids, models.InvoiceStatusPending, today, today.AddDate(0, 0, 30), 1000, "NGN")
+
+	transferID, err := suite.repo.Payment.RecordPayment(suite.ctx, ids.senderID, models.Payment{
+		PaymentID: uuid.New(), InvoiceID: invoiceID, Amount: 250, PaidOn: today, Reference: "TRF",
+	})
+	suite.Require().NoError(err)
+
+	payOnline := func(amount float64, reference string) uuid.UUID {
+		providerSessionID := "fake_cs_" + uuid.NewString()
+		session := models.PaymentSession{
+			PaymentSessionID:  uuid.New(),
+			InvoiceID:         invoiceID,
+			Provider:          "fake",
+			ProviderSessionID: providerSessionID,
+			URL:               "http://localhost:8080/checkout/" + providerSessionID,
+			Amount:            amount,
+			Currency:          "NGN",
+			Status:            models.PaymentSessionStatusOpen,
+			ExpiresAt:         time.Now().Add(time.Hour),
+		}
+		suite.Require().NoError(suite.repo.Payment.CreatePaymentSession(suite.ctx, session))
+		payment := models.Payment{PaymentID: uuid.New(), Amount: amount, PaidOn: today, Reference: reference}
+		_, err := suite.repo.Payment.CompletePaymentSession(suite.ctx, session.PaymentSessionID, payment)
+		suite.Require().NoError(err)
+		return payment.PaymentID
+	}
+	// the second online payment is made after the invoice was paid in full, so it is to be refunded
+	paidID := payOnline(750, "PAY-1")
+	lateID := payOnline(750, "PAY-2")
+
+	retryAt := time.Now().Add(time.Hour)
+
+	_, err = suite.repo.Payment.CreatePaymentRefund(suite.ctx, ids.senderID, transferID, 0, "fake", retryAt)
+	suite.ErrorIs(err, models.ErrPaymentNotRefundable)
+	_, err = suite.repo.Payment.CreatePaymentRefund(suite.ctx, uuid.New(), lateID, 0, "fake", retryAt)
+	suite.ErrorIs(err, models.ErrPaymentNotFound)
+	_, err = suite.repo.Payment.CreatePaymentRefund(suite.ctx, ids.senderID, lateID, 0, "stripe", retryAt)
+	suite.ErrorIs(err, models.ErrPaymentNotRefundable)
+	_, err = suite.repo.Payment.CreatePaymentRefund(suite.ctx, ids.senderID, lateID, 800, "fake", retryAt)
+	suite.ErrorIs(err, models.ErrRefundExceedsPayment)
+
+	// a pending refund counts against the payment before the provider made it
+	pending, err := suite.repo.Payment.CreatePaymentRefund(suite.ctx, ids.senderID, lateID, 0, "fake", retryAt)
+	suite.Require().NoError(err)
+	suite.Equal(models.PaymentRefundStatusPending, pending.Status)
+	suite.Equal(750.0, pending.Amount)
+	suite.Equal(lateID, pending.PaymentID)
+	suite.Equal(invoiceID, pending.InvoiceID)
+	suite.Equal("PAY-2", pending.PaymentReference)
+	suite.Equal("NGN", pending.Currency)
+	_, err = suite.repo.Payment.CreatePaymentRefund(suite.ctx, ids.senderID, lateID, 0, "fake", retryAt)
+	suite.ErrorIs(err, models.ErrPaymentNotRefundable)
+
+	details, err := suite.repo.Invoice.GetInvoiceDetails(suite.ctx, invoiceID)
+	suite.Require().NoError(err)
+	suite.Len(details.Payments, 3)
+
+	// pending refunds are claimed once they are due, and not again until the lease runs out
+	claimed, err := suite.repo.Payment.ClaimPendingPaymentRefunds(suite.ctx, time.Now(), time.Minute, 10)
+	suite.Require().NoError(err)
+	suite.Empty(claimed)
+	claimed, err = suite.repo.Payment.ClaimPendingPaymentRefunds(suite.ctx, retryAt, time.Minute, 10)
+	suite.Require().NoError(err)
+	suite.Require().Len(claimed, 1)
+	suite.Equal(pending.RefundID, claimed[0].RefundID)
+	claimed, err = suite.repo.Payment.ClaimPendingPaymentRefunds(suite.ctx, retryAt, time.Minute, 10)
+	suite.Require().NoError(err)
+	suite.Empty(claimed)
+
+	// completing the refund of the late payment in full leaves the invoice paid, however often it is completed
+	completed, err := suite.repo.Payment.CompletePaymentRefund(suite.ctx, pending.RefundID, "fake_re_1")
+	suite.Require().NoError(err)
+	suite.Equal(models.PaymentRefundStatusCompleted, completed.Status)
+	suite.Equal("fake_re_1", completed.ProviderRefundID)
+	completed, err = suite.repo.Payment.CompletePaymentRefund(suite.ctx, pending.RefundID, "fake_re_1")
+	suite.Require().NoError(err)
+	suite.Equal(models.PaymentRefundStatusCompleted, completed.Status)
+	failed, err := suite.repo.Payment.FailPaymentRefund(suite.ctx, pending.RefundID, "rejected")
+	suite.Require().NoError(err)
+	suite.Equal(models.PaymentRefundStatusCompleted, failed.Status)
+
+	details, err = suite.repo.Invoice.GetInvoiceDetails(suite.ctx, invoiceID)
+	suite.Require().NoError(err)
+	suite.Equal(string(models.InvoiceStatusPaid), details.Invoice.Status)
+	suite.Require().Len(details.Payments, 4)
+	var refundPayment *models.Payment
+	for i := range details.Payments {
+		if details.Payments[i].PaymentID == pending.RefundID {
+			refundPayment = &details.Payments[i]
+		}
+	}
+	suite.Require().NotNil(refundPayment)
+	suite.Equal(-750.0, refundPayment.Amount)
+	suite.Equal("fake_re_1", refundPayment.Reference)
+	suite.Equal(&lateID, refundPayment.RefundedPaymentID)
+
+	_, err = suite.repo.Payment.CreatePaymentRefund(suite.ctx, ids.senderID, pending.RefundID, 0, "fake", retryAt)
+	suite.ErrorIs(err, models.ErrPaymentNotFound)
+
+	// a failed refund frees its amount to be refunded again, and is never completed
+	rejected, err := suite.repo.Payment.CreatePaymentRefund(suite.ctx, ids.senderID, paidID, 700, "fake", retryAt)
+	suite.Require().NoError(err)
+	failed, err = suite.repo.Payment.FailPaymentRefund(suite.ctx, rejected.RefundID, "card expired")
+	suite.Require().NoError(err)
+	suite.Equal(models.PaymentRefundStatusFailed, failed.Status)
+	suite.Equal("card expired", failed.FailureReason)
+	_, err = suite.repo.Payment.CompletePaymentRefund(suite.ctx, rejected.RefundID, "fake_re_2")
+	suite.Error(err)
+	_, err = suite.repo.Payment.FailPaymentRefund(suite.ctx, uuid.New(), "card expired")
+	suite.ErrorIs(err, models.ErrPaymentRefundNotFound)
+	_, err = suite.repo.Payment.CompletePaymentRefund(suite.ctx, uuid.New(), "fake_re_2")
+	suite.ErrorIs(err, models.ErrPaymentRefundNotFound)
+
+	// refunding part of a payment the invoice needed leaves a balance due, so the invoice is pending again
+	partial, err := suite.repo.Payment.CreatePaymentRefund(suite.ctx, ids.senderID, paidID, 100, "fake", retryAt)
+	suite.Require().NoError(err)
+	_, err = suite.repo.Payment.CompletePaymentRefund(suite.ctx, partial.RefundID, "fake_re_3")
+	suite.Require().NoError(err)
+
+	invoice, err := suite.repo.Payment.GetPayableInvoice(suite.ctx, ids.senderID, invoiceID)
+	suite.Require().NoError(err)
+	suite.Equal(models.InvoiceStatusPending, invoice.Status)
+	suite.Equal(100.0, invoice.BalanceDue)
+
+	_, err = suite.repo.Payment.CreatePaymentRefund(suite.ctx, ids.senderID, paidID, 700, "fake", retryAt)
+	suite.ErrorIs(err, models.ErrRefundExceedsPayment)
+
+	var refunds int
+	err = suite.dbPool.QueryRow(suite.ctx, `
+        SELECT COUNT(*) FROM activity_events WHERE invoice_id = $1 AND event_type = $2`,
+		invoiceID, models.ActivityEventPaymentRefunded,
+	).Scan(&refunds)
+	suite.Require().NoError(err)
+	suite.Equal(2, refunds)
+}
+
+func (suite *InvoiceRepoTestSuite) TestBankTransactions() {
+	ids := suite.createTestSender()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	invoiceID := suite.createTestInvoice(ids, models.InvoiceStatusPending, today, today.AddDate(0, 0, 30), 500, "NGN")
+	suite.createTestInvoice(ids, models.InvoiceStatusDraft, today, today.AddDate(0, 0, 30), 500, "NGN")
+
+	invoices, err := suite.repo.Reconciliation.GetUnpaidInvoices(suite.ctx, ids.senderID)
+	suite.Require().NoError(err)
+	suite.Require().Len(invoices, 1)
+	suite.Equal(invoiceID, invoices[0].InvoiceID)
+	suite.Equal(500.0, invoices[0].BalanceDue)
+
+	newTransaction := func(externalID string, amount float64) models.BankTransaction {
+		return models.BankTransaction{
+			BankTransactionID: uuid.New(),
+			UserID:            ids.senderID,
+			ExternalID:        externalID,
+			BookingDate:       today,
+			Amount:            amount,
+			Currency:          "NGN",
+			CounterpartyName:  "Customer",
+			RemittanceInfo:    "Invoice payment",
+		}
+	}
+	newPayment := func(amount float64) *models.Payment {
+		return &models.Payment{PaymentID: uuid.New(), InvoiceID: invoiceID, Amount: amount, PaidOn: today, Reference: "TX"}
+	}
+
+	// an applied transaction records its payment
+	applied, err := suite.repo.Reconciliation.AddBankTransaction(suite.ctx, newTransaction("TX-1", 200), newPayment(200))
+	suite.Require().NoError(err)
+	suite.Equal(models.BankTransactionStatusApplied, applied.Status)
+	suite.Equal(&invoiceID, applied.InvoiceID)
+
+	duplicate, err := suite.repo.Reconciliation.AddBankTransaction(suite.ctx, newTransaction("TX-1", 200), newPayment(200))
+	suite.Require().NoError(err)
+	suite.Nil(duplicate)
+
+	pending := newTransaction("TX-2", 300)
+	pending.SuggestedInvoiceIDs = []uuid.UUID{invoiceID}
+	stored, err := suite.repo.Reconciliation.AddBankTransaction(suite.ctx, pending, nil)
+	suite.Require().NoError(err)
+	suite.Equal(models.BankTransactionStatusPending, stored.Status)
+	suite.Equal([]uuid.UUID{invoiceID}, stored.SuggestedInvoiceIDs)
+
+	dismissed, err := suite.repo.Reconciliation.AddBankTransaction(suite.ctx, newTransaction("TX-3", 50), nil)
+	suite.Require().NoError(err)
+
+	inbox, meta, err := suite.repo.Reconciliation.GetBankTransactions(suite.ctx, ids.senderID, models.BankTransactionStatusPending, pagination.Request{Limit: 1, Page: 1})
+	suite.Require().NoError(err)
+	suite.Len(inbox, 1)
+	suite.Equal(int64(2), meta.Total)
+	suite.Require().NotEmpty(meta.NextCursor)
+	next, err := pagination.DecodeCursor(meta.NextCursor)
+	suite.Require().NoError(err)
+	rest, _, err := suite.repo.Reconciliation.GetBankTransactions(suite.ctx, ids.senderID, models.BankTransactionStatusPending, pagination.Request{Limit: 1, Cursor: next})
+	suite.Require().NoError(err)
+	suite.Require().Len(rest, 1)
+	suite.NotEqual(inbox[0].BankTransactionID, rest[0].BankTransactionID)
+
+	suite.Require().NoError(suite.repo.Reconciliation.DismissBankTransaction(suite.ctx, ids.senderID, dismissed.BankTransactionID))
+	suite.ErrorIs(suite.repo.Reconciliation.DismissBankTransaction(suite.ctx, ids.senderID, dismissed.BankTransactionID),
+		models.ErrBankTransactionReconciled)
+	suite.ErrorIs(suite.repo.Reconciliation.DismissBankTransaction(suite.ctx, uuid.New(), pending.BankTransactionID),
+		models.ErrBankTransactionNotFound)
+
+	// confirming the pending transaction settles the invoice
+	confirmed, err := suite.repo.Reconciliation.ConfirmBankTransaction(suite.ctx, ids.senderID, pending.BankTransactionID,
+		*newPayment(300))
+	suite.Require().NoError(err)
+	suite.Equal(models.BankTransactionStatusConfirmed, confirmed.Status)
+
+	details, err := suite.repo.Invoice.GetInvoiceDetails(suite.ctx, invoiceID)
+	suite.Require().NoError(err)
+	suite.Equal(string(models.InvoiceStatusPaid), details.Invoice.Status)
+	suite.Len(details.Payments, 2)
+
+	// a payment rejected on import leaves the transaction pending
+	rejected, err := suite.repo.Reconciliation.AddBankTransaction(suite.ctx, newTransaction("TX-4", 10), newPayment(10))
+	suite.Require().NoError(err)
+	suite.Equal(models.BankTransactionStatusPending, rejected.Status)
+	suite.Nil(rejected.PaymentID)
+
+	euroInvoiceID := suite.createTestInvoice(ids, models.InvoiceStatusPending, today, today.AddDate(0, 0, 30), 10, "EUR")
+	payment := *newPayment(10)
+	payment.InvoiceID = euroInvoiceID
+	_, err = suite.repo.Reconciliation.ConfirmBankTransaction(suite.ctx, ids.senderID, rejected.BankTransactionID, payment)
+	suite.ErrorIs(err, models.ErrCurrencyMismatch)
+
+	// a payment failing for any other reason than the invoice fails the import instead of leaving it pending
+	failing := newTransaction("TX-5", 10)
+	reused := *newPayment(10)
+	reused.InvoiceID, reused.PaymentID = euroInvoiceID, *confirmed.PaymentID
+	failing.Currency = "EUR"
+	_, err = suite.repo.Reconciliation.AddBankTransaction(suite.ctx, failing, &reused)
+	suite.Error(err)
+	stored, err = suite.repo.Reconciliation.GetBankTransaction(suite.ctx, ids.senderID, failing.BankTransactionID)
+	suite.Require().NoError(err)
+	suite.Nil(stored)
+}
+
+func (suite *InvoiceRepoTestSuite) TestTaxReport() {
+	ids := suite.createTestSender()
+
+	vatID, err := suite.repo.Tax.CreateTaxRate(suite.ctx, models.TaxRate{TaxRateID: uuid.New(), UserID: ids.senderID, Name: "VAT", Rate: 7.5})
+	suite.Require().NoError(err)
+
+	createTaxedInvoice := func(status models.InvoiceStatus, issueDate time.Time) uuid.UUID {
+		invoiceID := uuid.New()
+		invoice := models.Invoice{
+			InvoiceID:     invoiceID,
+			InvoiceNumber: helpers.RandomNumber(1000000000, 9999999999),
+			SenderID:      ids.senderID,
+			CustomerID:    ids.customerID,
+			IssueDate:     issueDate,
+			DueDate:       issueDate.AddDate(0, 0, 30),
+			TotalAmount:   1000,
+			FinalAmount:   1075,
+			Status:        string(status),
+			Currency:      "NGN",
+		}
+		items := []models.InvoiceItem{{ItemID: uuid.New(), InvoiceID: invoiceID, Name: "Item", Description: "Description", Quantity: 1, UnitPrice: 1000, TotalPrice: 1000}}
+		taxLines := []models.InvoiceTaxLine{{TaxLineID: uuid.New(), TaxRateID: vatID, Name: "VAT", Rate: 7.5, TaxableAmount: 1000, TaxAmount: 75}}
+		paymentInfo := models.PaymentInformation{PaymentInfoID: uuid.New(), InvoiceID: invoiceID, PaymentMethodID: ids.paymentMethodID}
+
+		_, err := suite.repo.Invoice.CreateInvoice(suite.ctx, invoice, items, taxLines, ids.customerID, paymentInfo)
+		suite.Require().NoError(err)
+		return invoiceID
+	}
+
+	q1From, q1To := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	q2From, q2To := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	januaryID := createTaxedInvoice(models.InvoiceStatusPending, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+	createTaxedInvoice(models.InvoiceStatusPending, time.Date(2024, 4, 10, 0, 0, 0, 0, time.UTC))
+	createTaxedInvoice(models.InvoiceStatusDraft, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+
+	// invoice basis counts issued invoices in full and skips drafts
+	lines, err := suite.repo.Tax.GetTaxReport(suite.ctx, ids.senderID, q1From, q1To, models.TaxReportBasisInvoice)
+	suite.Require().NoError(err)
+	suite.Require().Len(lines, 1)
+	suite.Equal(vatID, lines[0].TaxRateID)
+	suite.Equal("NGN", lines[0].Currency)
+	suite.Equal(1000.0, lines[0].TaxableAmount)
+	suite.Equal(75.0, lines[0].TaxAmount)
+	suite.Equal(1, lines[0].InvoiceCount)
+
+	// cash basis counts the share paid within the period
+	_, err = suite.repo.Payment.RecordPayment(suite.ctx, ids.senderID, models.Payment{
+		PaymentID: uuid.New(), InvoiceID: januaryID, Amount: 537.5, PaidOn: time.Date(2024, 4, 5, 0, 0, 0, 0, time.UTC),
+	})
+	suite.Require().NoError(err)
+
+	lines, err = suite.repo.Tax.GetTaxReport(suite.ctx, ids.senderID, q1From, q1To, models.TaxReportBasisCash)
+	suite.Require().NoError(err)
+	suite.Empty(lines)
+
+	lines, err = suite.repo.Tax.GetTaxReport(suite.ctx, ids.senderID, q2From, q2To, models.TaxReportBasisCash)
+	suite.Require().NoError(err)
+	suite.Require().Len(lines, 1)
+	suite.Equal(500.0, lines[0].TaxableAmount)
+	suite.Equal(37.5, lines[0].TaxAmount)
+	suite.Equal(1, lines[0].InvoiceCount)
+
+	// other senders never see the report
+	lines, err = suite.repo.Tax.GetTaxReport(suite.ctx, uuid.New(), q1From, q1To, models.TaxReportBasisInvoice)
+	suite.Require().NoError(err)
+	suite.Empty(lines)
+}
+
+func (suite *InvoiceRepoTestSuite) TestMarkOverdueInvoices() {
+	ids := suite.createTestSender()
+	// dates long past, so the invoices of the other tests are not marked
+	dueDate := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+	pendingID := suite.createTestInvoice(ids, models.InvoiceStatusPending, dueDate.AddDate(0, -1, 0), dueDate, 100, "NGN")
+	draftID := suite.createTestInvoice(ids, models.InvoiceStatusDraft, dueDate.AddDate(0, -1, 0), dueDate, 100, "NGN")
+	notDueID := suite.createTestInvoice(ids, models.InvoiceStatusPending, dueDate.AddDate(0, -1, 0), dueDate.AddDate(0, 0, 1), 100, "NGN")
+
+	marked, err := suite.repo.Invoice.MarkOverdueInvoices(suite.ctx, dueDate.AddDate(0, 0, 1), 100)
+	suite.Require().NoError(err)
+	suite.Equal(1, marked)
+
+	for invoiceID, status := range map[uuid.UUID]models.InvoiceStatus{
+		pendingID: models.InvoiceStatusOverDue,
+		draftID:   models.InvoiceStatusDraft,
+		notDueID:  models.InvoiceStatusPending,
+	} {
+		details, err := suite.repo.Invoice.GetInvoiceDetails(suite.ctx, invoiceID)
+		suite.Require().NoError(err)
+		suite.Equal(string(status), details.Invoice.Status)
+	}
+
+	activities, _, err := suite.repo.Invoice.GetInvoiceActivities(suite.ctx, ids.senderID, pendingID,
+		models.ActivityFilter{EventTypes: []models.ActivityEventType{models.ActivityEventStatusChanged}}, pagination.Request{Limit: 10, Page: 1})
+	suite.Require().NoError(err)
+	suite.Require().Len(activities, 1)
+	suite.JSONEq(`{"from":"pending","to":"overdue"}`, string(activities[0].Payload))
+
+	// invoices already marked are not marked again
+	marked, err = suite.repo.Invoice.MarkOverdueInvoices(suite.ctx, dueDate.AddDate(0, 0, 1), 100)
+	suite.Require().NoError(err)
+	suite.Zero(marked)
+}
+
+func (suite *InvoiceRepoTestSuite) TestWebhooks() {
+	ids := suite.createTestSender()
+	webhookID, err := suite.repo.Webhook.CreateWebhook(suite.ctx, models.Webhook{
+		WebhookID:  uuid.New(),
+		UserID:     ids.senderID,
+		URL:        "https://example.com/hooks",
+		EventTypes: []models.WebhookEventType{models.WebhookEventInvoiceCreated, models.WebhookEventInvoicePaid},
+		Secret:     "whsec_secret",
+	})
+	suite.Require().NoError(err)
+
+	webhook, err := suite.repo.Webhook.GetWebhook(suite.ctx, ids.senderID, webhookID)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(webhook)
+	suite.Equal([]models.WebhookEventType{models.WebhookEventInvoiceCreated, models.WebhookEventInvoicePaid}, webhook.EventTypes)
+	suite.Empty(webhook.Secret)
+	suite.Nil(webhook.DisabledAt)
+
+	webhook, err = suite.repo.Webhook.GetWebhook(suite.ctx, uuid.New(), webhookID)
+	suite.Require().NoError(err)
+	suite.Nil(webhook)
+
+	// only the events recorded after the webhook was created are delivered to it
+	invoiceID := suite.createTestInvoice(ids, models.InvoiceStatusPending, time.Now(), time.Now().AddDate(0, 0, 30), 100, "NGN")
+	webhook, err = suite.repo.Webhook.GetWebhook(suite.ctx, ids.senderID, webhookID)
+	suite.Require().NoError(err)
+	events, err := suite.repo.Event.GetActivityEvents(suite.ctx, ids.senderID, webhook.LastEvent, 10)
+	suite.Require().NoError(err)
+	suite.Require().Len(events, 1)
+	suite.Equal(invoiceID, events[0].InvoiceID)
+
+	// an event committed after a newer one is not passed over: the newer one waits until the older transaction ends
+	tx, err := suite.dbPool.Begin(suite.ctx)
+	suite.Require().NoError(err)
+	defer tx.Rollback(suite.ctx)
+	late := models.NewInvoiceActivity(invoiceID, ids.senderID, models.ActivityEventComment, nil, "Comment", "Late")
+	suite.Require().NoError(recordActivity(suite.ctx, tx, late))
+	newerID, err := suite.repo.Invoice.AddInvoiceActivity(suite.ctx, models.NewInvoiceActivity(invoiceID, ids.senderID,
+		models.ActivityEventComment, nil, "Comment", "Newer"))
+	suite.Require().NoError(err)
+
+	settled, err := suite.repo.Event.GetActivityEvents(suite.ctx, ids.senderID, events[0].Cursor(), 10)
+	suite.Require().NoError(err)
+	suite.Empty(settled)
+
+	suite.Require().NoError(tx.Commit(suite.ctx))
+	settled, err = suite.repo.Event.GetActivityEvents(suite.ctx, ids.senderID, events[0].Cursor(), 10)
+	suite.Require().NoError(err)
+	suite.Require().Len(settled, 2)
+	suite.Equal(late.ActivityID, settled[0].ActivityID)
+	suite.Equal(newerID, settled[1].ActivityID)
+	suite.Less(settled[0].Sequence, settled[1].Sequence)
+
+	now := time.Now()
+	delivery := models.WebhookDelivery{
+		DeliveryID:    uuid.New(),
+		WebhookID:     webhookID,
+		EventID:       uuid.New(),
+		EventType:     models.WebhookEventInvoiceCreated,
+		Payload:       json.RawMessage(`{"type":"invoice.created"}`),
+		Status:        models.WebhookDeliveryStatusPending,
+		NextAttemptAt: &now,
+	}
+	suite.Require().NoError(suite.repo.Webhook.QueueWebhookDeliveries(suite.ctx, webhookID, []models.WebhookDelivery{delivery}, events[0].Cursor()))
+
+	// events queued by another server are skipped
+	again := delivery
+	again.DeliveryID = uuid.New()
+	suite.Require().NoError(suite.repo.Webhook.QueueWebhookDeliveries(suite.ctx, webhookID, []models.WebhookDelivery{again}, events[0].Cursor()))
+
+	deliveries, meta, err := suite.repo.Webhook.GetWebhookDeliveries(suite.ctx, webhookID, pagination.Request{Limit: 10, Page: 1})
+	suite.Require().NoError(err)
+	suite.Equal(int64(1), meta.Total)
+	suite.Require().Len(deliveries, 1)
+	suite.Equal(delivery.DeliveryID, deliveries[0].DeliveryID)
+
+	// a claimed delivery is not claimed again until its lease runs out
+	due, err := suite.repo.Webhook.ClaimDueWebhookDeliveries(suite.ctx, now, time.Minute, 10)
+	suite.Require().NoError(err)
+	suite.Require().Len(due, 1)
+	suite.Equal("https://example.com/hooks", due[0].URL)
+	suite.Equal("whsec_secret", due[0].Secret)
+	due, err = suite.repo.Webhook.ClaimDueWebhookDeliveries(suite.ctx, now, time.Minute, 10)
+	suite.Require().NoError(err)
+	suite.Empty(due)
+
+	// a delivery that failed for good disables the webhook once it reaches the failure limit
+	status := 500
+	suite.Require().NoError(suite.repo.Webhook.RecordWebhookDeliveryAttempt(suite.ctx, models.WebhookDeliveryAttempt{
+		DeliveryID:     delivery.DeliveryID,
+		WebhookID:      webhookID,
+		Status:         models.WebhookDeliveryStatusFailed,
+		ResponseStatus: &status,
+		Error:          "webhook responded with status 500",
+		AttemptedAt:    now,
+	}, 1))
+
+	webhook, err = suite.repo.Webhook.GetWebhook(suite.ctx, ids.senderID, webhookID)
+	suite.Require().NoError(err)
+	suite.Equal(1, webhook.FailureCount)
+	suite.NotNil(webhook.DisabledAt)
+	active, err := suite.repo.Webhook.GetActiveWebhooks(suite.ctx)
+	suite.Require().NoError(err)
+	for _, hook := range active {
+		suite.NotEqual(webhookID, hook.WebhookID)
+	}
+
+	deliveries, _, err = suite.repo.Webhook.GetWebhookDeliveries(suite.ctx, webhookID, pagination.Request{Limit: 10, Page: 1})
+	suite.Require().NoError(err)
+	suite.Equal(models.WebhookDeliveryStatusFailed, deliveries[0].Status)
+	suite.Equal(1, deliveries[0].Attempts)
+	suite.Equal(&status, deliveries[0].ResponseStatus)
+	suite.Nil(deliveries[0].NextAttemptAt)
+
+	// replays wait until the webhook is enabled again
+	replay, err := suite.repo.Webhook.ReplayWebhookDelivery(suite.ctx, ids.senderID, delivery.DeliveryID, uuid.New(), now)
+	suite.Require().NoError(err)
+	suite.Equal(delivery.EventID, replay.EventID)
+	suite.Equal(&delivery.DeliveryID, replay.ReplayOf)
+	suite.Equal(models.WebhookDeliveryStatusPending, replay.Status)
+	due, err = suite.repo.Webhook.ClaimDueWebhookDeliveries(suite.ctx, now, time.Minute, 10)
+	suite.Require().NoError(err)
+	suite.Empty(due)
+
+	_, err = suite.repo.Webhook.ReplayWebhookDelivery(suite.ctx, uuid.New(), delivery.DeliveryID, uuid.New(), now)
+	suite.ErrorIs(err, models.ErrWebhookDeliveryNotFound)
+
+	webhook, err = suite.repo.Webhook.EnableWebhook(suite.ctx, ids.senderID, webhookID)
+	suite.Require().NoError(err)
+	suite.Nil(webhook.DisabledAt)
+	suite.Zero(webhook.FailureCount)
+	due, err = suite.repo.Webhook.ClaimDueWebhookDeliveries(suite.ctx, now, time.Minute, 10)
+	suite.Require().NoError(err)
+	suite.Require().Len(due, 1)
+	suite.Equal(replay.DeliveryID, due[0].Delivery.DeliveryID)
+
+	suite.Require().NoError(suite.repo.Webhook.DeleteWebhook(suite.ctx, ids.senderID, webhookID))
+	suite.ErrorIs(suite.repo.Webhook.DeleteWebhook(suite.ctx, ids.senderID, webhookID), models.ErrWebhookNotFound)
+	_, err = suite.repo.Webhook.EnableWebhook(suite.ctx, ids.senderID, webhookID)
+	suite.ErrorIs(err, models.ErrWebhookNotFound)
 }
 
 func TestInvoiceRepoSuite(t *testing.T) {
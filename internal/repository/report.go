@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+type reportRepoImpl struct {
+	DBPool *pgxpool.Pool
+}
+
+// newReportRepoImpl creates a new instance of the reportRepoImpl struct, which is used to aggregate invoices
+// and payments over time for the revenue and cash flow reports.
+func newReportRepoImpl(dbPool *pgxpool.Pool) *reportRepoImpl {
+	return &reportRepoImpl{
+		DBPool: dbPool,
+	}
+}
+
+// GetSeries sums the invoices a sender issued, or the payments they received, between the from and to dates
+// of the filter per day, week or month. Drafts are not counted as invoiced. Amounts are converted into the
+// base currency with the exchange rate locked into each invoice, unless the series are grouped by currency;
+// invoices without a locked rate are left out of the converted series. Only periods with activity are returned,
+// with the series ordered by customer and currency and their points by period.
+func (r *reportRepoImpl) GetSeries(ctx context.Context, filter models.SeriesFilter) ([]models.Series, error) {
+	source := `
+            SELECT i.issue_date AS day, i.customer_id, i.currency, i.base_currency, i.exchange_rate, i.final_amount AS amount
+            FROM invoices i
+            WHERE i.sender_id = $1 AND i.status <> 'draft' AND i.issue_date BETWEEN $2::date AND $3::date`
+	if filter.Metric == models.SeriesMetricCollected {
+		source = `
+            SELECT p.paid_on AS day, i.customer_id, i.currency, i.base_currency, i.exchange_rate, p.amount
+            FROM payments p
+            JOIN invoices i ON i.invoice_id = p.invoice_id
+            WHERE i.sender_id = $1 AND p.paid_on BETWEEN $2::date AND $3::date`
+	}
+
+	// customer, customer name, currency and amount columns, and the filter, of each grouping
+	columns := "NULL::uuid AS customer_id, NULL::text AS customer_name, s.base_currency AS currency, s.amount * s.exchange_rate AS amount"
+	join, where := "", "WHERE s.exchange_rate IS NOT NULL"
+	switch filter.GroupBy {
+	case models.SeriesGroupByCurrency:
+		columns = "NULL::uuid AS customer_id, NULL::text AS customer_name, s.currency, s.amount"
+		where = ""
+	case models.SeriesGroupByCustomer:
+		columns = "s.customer_id, c.name AS customer_name, s.base_currency AS currency, s.amount * s.exchange_rate AS amount"
+		join = "JOIN customers c ON c.customer_id = s.customer_id"
+	}
+
+	query := fmt.Sprintf(`
+        WITH source AS (%s
+        ), grouped AS (
+            SELECT date_trunc($4, s.day::timestamp)::date AS period, %s
+            FROM source s
+            %s
+            %s
+        )
+        SELECT g.period, g.customer_id, g.customer_name, g.currency, COUNT(*), ROUND(SUM(g.amount), 2)
+        FROM grouped g
+        GROUP BY g.period, g.customer_id, g.customer_name, g.currency
+        ORDER BY g.customer_name, g.customer_id, g.currency, g.period`, source, columns, join, where)
+
+	rows, err := r.DBPool.Query(ctx, query, filter.SenderID, filter.From, filter.To, string(filter.Interval))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	series := []models.Series{}
+	for rows.Next() {
+		var point models.SeriesPoint
+		var customerID *uuid.UUID
+		var customerName *string
+		var currency string
+		if err := rows.Scan(&point.Period, &customerID, &customerName, &currency, &point.Count, &point.Amount); err != nil {
+			return nil, err
+		}
+
+		last := len(series) - 1
+		if last < 0 || series[last].Currency != currency || !sameCustomer(series[last].CustomerID, customerID) {
+			series = append(series, models.Series{CustomerID: customerID, Currency: currency, Points: []models.SeriesPoint{}})
+			last++
+			if customerName != nil {
+				series[last].CustomerName = *customerName
+			}
+		}
+		series[last].Points = append(series[last].Points, point)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
+
+// sameCustomer reports whether two optional customer IDs are both empty or the same.
+func sameCustomer(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
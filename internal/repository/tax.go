@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+type taxRepoImpl struct {
+	DBPool *pgxpool.Pool
+}
+
+// newTaxRepoImpl creates a new instance of the taxRepoImpl struct, which is used to interact with the
+// tax rates stored in the database.
+func newTaxRepoImpl(dbPool *pgxpool.Pool) *taxRepoImpl {
+	return &taxRepoImpl{
+		DBPool: dbPool,
+	}
+}
+
+// CreateTaxRate creates a new tax rate for a sender and returns the generated tax rate ID.
+func (t *taxRepoImpl) CreateTaxRate(ctx context.Context, taxRate models.TaxRate) (uuid.UUID, error) {
+	query := `
+		INSERT INTO tax_rates (tax_rate_id, user_id, name, rate, is_inclusive, is_compound)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING tax_rate_id
+	`
+	err := t.DBPool.QueryRow(ctx, query, taxRate.TaxRateID, taxRate.UserID, taxRate.Name, taxRate.Rate,
+		taxRate.IsInclusive, taxRate.IsCompound).Scan(&taxRate.TaxRateID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return taxRate.TaxRateID, nil
+}
+
+// GetTaxRates retrieves the active tax rates of the specified user, ordered by name.
+func (t *taxRepoImpl) GetTaxRates(ctx context.Context, userID uuid.UUID) ([]models.TaxRate, error) {
+	query := `
+		SELECT tax_rate_id, user_id, name, rate, is_inclusive, is_compound, is_active, created_at, updated_at
+		FROM tax_rates
+		WHERE user_id = $1 AND is_active
+		ORDER BY name
+	`
+
+	rows, err := t.DBPool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	taxRates := []models.TaxRate{}
+	for rows.Next() {
+		var taxRate models.TaxRate
+		err := rows.Scan(&taxRate.TaxRateID, &taxRate.UserID, &taxRate.Name, &taxRate.Rate, &taxRate.IsInclusive,
+			&taxRate.IsCompound, &taxRate.IsActive, &taxRate.CreatedAt, &taxRate.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		taxRates = append(taxRates, taxRate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return taxRates, nil
+}
+
+// DeactivateTaxRate stops a tax rate owned by the specified user from being used on new invoices.
+// Invoices already taxed at the rate are left unchanged.
+func (t *taxRepoImpl) DeactivateTaxRate(ctx context.Context, userID, taxRateID uuid.UUID) error {
+	tag, err := t.DBPool.Exec(ctx, `
+        UPDATE tax_rates SET is_active = false, updated_at = CURRENT_TIMESTAMP
+        WHERE tax_rate_id = $1 AND user_id = $2 AND is_active`,
+		taxRateID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("tax rate not found")
+	}
+	return nil
+}
+
+// GetTaxReport aggregates the tax lines of the specified sender's issued invoices per tax rate and currency.
+// On an invoice basis, invoices issued within the period are counted in full. On a cash basis, each payment
+// received within the period counts for the share of its invoice's amount due that it covers.
+func (t *taxRepoImpl) GetTaxReport(ctx context.Context, senderID uuid.UUID, from, to time.Time, basis models.TaxReportBasis) ([]models.TaxReportLine, error) {
+	query := `
+		SELECT tl.tax_rate_id, tl.name, tl.rate, i.currency,
+		       SUM(tl.taxable_amount), SUM(tl.tax_amount), COUNT(DISTINCT i.invoice_id)
+		FROM invoice_tax_lines tl
+		JOIN invoices i ON tl.invoice_id = i.invoice_id
+		WHERE i.sender_id = $1 AND i.status <> 'draft'
+		  AND i.issue_date BETWEEN $2::date AND $3::date
+		GROUP BY tl.tax_rate_id, tl.name, tl.rate, i.currency
+		ORDER BY i.currency, tl.name
+	`
+	if basis == models.TaxReportBasisCash {
+		query = `
+		WITH paid_shares AS (
+		    SELECT p.invoice_id, SUM(p.amount) / NULLIF(MAX(i.final_amount) + COALESCE(MAX(a.adjusted), 0), 0) AS share
+		    FROM payments p
+		    JOIN invoices i ON p.invoice_id = i.invoice_id
+		    LEFT JOIN LATERAL (
+		        SELECT SUM(amount) AS adjusted FROM invoice_adjustments WHERE invoice_id = i.invoice_id
+		    ) a ON true
+		    WHERE i.sender_id = $1 AND p.paid_on BETWEEN $2::date AND $3::date
+		    GROUP BY p.invoice_id
+		)
+		SELECT tl.tax_rate_id, tl.name, tl.rate, i.currency,
+		       ROUND(SUM(tl.taxable_amount * LEAST(ps.share, 1)), 2), ROUND(SUM(tl.tax_amount * LEAST(ps.share, 1)), 2),
+		       COUNT(DISTINCT i.invoice_id)
+		FROM paid_shares ps
+		JOIN invoices i ON ps.invoice_id = i.invoice_id
+		JOIN invoice_tax_lines tl ON tl.invoice_id = i.invoice_id
+		WHERE ps.share IS NOT NULL
+		GROUP BY tl.tax_rate_id, tl.name, tl.rate, i.currency
+		ORDER BY i.currency, tl.name
+	`
+	}
+
+	rows, err := t.DBPool.Query(ctx, query, senderID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []models.TaxReportLine{}
+	for rows.Next() {
+		var line models.TaxReportLine
+		err := rows.Scan(&line.TaxRateID, &line.Name, &line.Rate, &line.Currency, &line.TaxableAmount, &line.TaxAmount, &line.InvoiceCount)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
@@ -2,65 +2,465 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zde37/Numeris-Task/internal/encryption"
 	"github.com/zde37/Numeris-Task/internal/models"
 )
 
 type userRepoImpl struct {
-	DBPool *pgxpool.Pool
+	DBPool  *pgxpool.Pool
+	Keyring *encryption.Keyring
 }
 
-// newUserRepoImpl creates a new instance of the userRepoImpl struct, which is used to interact with the user-related data in the database. 
-func newUserRepoImpl(dbPool *pgxpool.Pool) *userRepoImpl {
+// newUserRepoImpl creates a new instance of the userRepoImpl struct, which is used to interact with the user-related data in the database.
+// Account numbers of payment methods are encrypted with the keyring.
+func newUserRepoImpl(dbPool *pgxpool.Pool, keyring *encryption.Keyring) *userRepoImpl {
 	return &userRepoImpl{
-		DBPool: dbPool,
+		DBPool:  dbPool,
+		Keyring: keyring,
 	}
 }
  
 // CreateUser creates a new user in the database and returns the generated user ID. 
 func (u *userRepoImpl) CreateUser(ctx context.Context, user models.User) (uuid.UUID, error) {
 	query := `
-		INSERT INTO users (user_id, username, email, password, first_name, last_name, profile_picture_url, phone_number, address)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO users (user_id, username, email, password, first_name, last_name, profile_picture_url, phone_number, address, base_currency)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING user_id
 	` 
 	err := u.DBPool.QueryRow(ctx, query, user.UserID, user.Username, user.Email, user.Password, user.FirstName, user.LastName, 
-		user.ProfilePictureURL, user.PhoneNumber, user.Address).Scan(&user.UserID)
+		user.ProfilePictureURL, user.PhoneNumber, user.Address, user.BaseCurrency).Scan(&user.UserID)
 	if err != nil {
 		return uuid.Nil, err
 	}
 	return user.UserID, nil
 }
 
-// AddCustomer creates a new customer in the database and returns the generated customer ID.
+// AddCustomer creates a new customer of a user in the database and returns the generated customer ID.
 func (u *userRepoImpl) AddCustomer(ctx context.Context, customer models.Customer) (uuid.UUID, error) {
+	return addCustomer(ctx, u.DBPool, customer)
+}
+
+// AddCustomers creates the customers in the database in a single transaction, so that either all or none of them are created.
+func (u *userRepoImpl) AddCustomers(ctx context.Context, customers []models.Customer) error {
+	tx, err := u.DBPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, customer := range customers {
+		if _, err := addCustomer(ctx, tx, customer); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetOwnedCustomerIDs returns those of the given customers that belong to the specified user.
+func (u *userRepoImpl) GetOwnedCustomerIDs(ctx context.Context, userID uuid.UUID, customerIDs []uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := u.DBPool.Query(ctx, `
+        SELECT customer_id
+        FROM customers
+        WHERE user_id = $1 AND customer_id = ANY($2)`,
+		userID, customerIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+}
+
+// foreignKeyViolation is the SQLSTATE of an insert referencing a row that does not exist.
+const foreignKeyViolation = "23503"
+
+// addCustomer inserts a customer with the querier and returns its ID. It returns ErrUserNotFound when the owner of the
+// customer does not exist.
+func addCustomer(ctx context.Context, db querier, customer models.Customer) (uuid.UUID, error) {
 	query := `
-        INSERT INTO customers (customer_id, name, email, phone_number, address)
-        VALUES ($1, $2, $3, $4, $5)
+        INSERT INTO customers (customer_id, user_id, name, email, phone_number, address)
+        VALUES ($1, $2, $3, $4, $5, $6)
         RETURNING customer_id`
 
-	err := u.DBPool.QueryRow(ctx, query,
-		customer.CustomerID, customer.Name, customer.Email, customer.PhoneNumber,
+	err := db.QueryRow(ctx, query,
+		customer.CustomerID, customer.UserID, customer.Name, customer.Email, customer.PhoneNumber,
 		customer.Address).Scan(&customer.CustomerID)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation && pgErr.ConstraintName == "customers_user_id_fkey" {
+		return uuid.Nil, models.ErrUserNotFound
+	}
 	if err != nil {
 		return uuid.Nil, err
 	}
 	return customer.CustomerID, nil
 }
  
-// AddPaymentMethod creates a new payment method for a user in the database and returns the generated payment method ID. 
+// AddPaymentMethod creates a new payment method for a user in the database and returns the generated payment method ID.
+// The account number is stored encrypted. The first payment method of a user becomes their default, as does a payment
+// method added with IsDefault set.
 func (u *userRepoImpl) AddPaymentMethod(ctx context.Context, paymentMethod models.UserPaymentMethod) (uuid.UUID, error) {
+	envelope, last4, err := encryptAccountNumber(u.Keyring, paymentMethod.AccountNumber)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	tx, err := u.DBPool.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if paymentMethod.IsDefault {
+		if _, err := tx.Exec(ctx, `
+            UPDATE user_payment_methods SET is_default = false, updated_at = CURRENT_TIMESTAMP
+            WHERE user_id = $1 AND is_default`,
+			paymentMethod.UserID,
+		); err != nil {
+			return uuid.Nil, err
+		}
+	}
+
 	query := `
-		INSERT INTO user_payment_methods (payment_method_id, user_id, account_name, account_number, bank_name, bank_address, swift_code)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO user_payment_methods (payment_method_id, user_id, account_name, account_type, account_number_ciphertext,
+		                                  account_number_data_key, account_number_key_id, account_number_last4, routing_number,
+		                                  sort_code, bank_name, bank_address, swift_code, is_default)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), NULLIF($10, ''), $11, $12, $13,
+		        $14 OR NOT EXISTS (SELECT 1 FROM user_payment_methods WHERE user_id = $2 AND is_default))
 		RETURNING payment_method_id
 	`
-	err := u.DBPool.QueryRow(ctx, query, paymentMethod.PaymentMethodID, paymentMethod.UserID, paymentMethod.AccountName, paymentMethod.AccountNumber,
-		paymentMethod.BankName, paymentMethod.BankAddress, paymentMethod.SwiftCode).Scan(&paymentMethod.PaymentMethodID)
+	err = tx.QueryRow(ctx, query, paymentMethod.PaymentMethodID, paymentMethod.UserID, paymentMethod.AccountName, paymentMethod.AccountType,
+		envelope.Ciphertext, envelope.DataKey, envelope.KeyID, last4, paymentMethod.RoutingNumber, paymentMethod.SortCode,
+		paymentMethod.BankName, paymentMethod.BankAddress, paymentMethod.SwiftCode, paymentMethod.IsDefault).Scan(&paymentMethod.PaymentMethodID)
 	if err != nil {
 		return uuid.Nil, err
 	}
-	return paymentMethod.PaymentMethodID, nil
+	return paymentMethod.PaymentMethodID, tx.Commit(ctx)
+}
+
+// paymentMethodColumns are the columns a payment method is scanned from by scanPaymentMethod. Only the last four
+// digits of the account number are read.
+const paymentMethodColumns = `payment_method_id, user_id, account_name, account_type, '****' || account_number_last4,
+        COALESCE(routing_number, ''), COALESCE(sort_code, ''), bank_name, COALESCE(bank_address, ''), COALESCE(swift_code, ''),
+        is_default, created_at, updated_at`
+
+// scanPaymentMethod scans a row of paymentMethodColumns into a payment method, whose account number is masked.
+func scanPaymentMethod(row pgx.Row) (models.UserPaymentMethod, error) {
+	var paymentMethod models.UserPaymentMethod
+	err := row.Scan(&paymentMethod.PaymentMethodID, &paymentMethod.UserID, &paymentMethod.AccountName, &paymentMethod.AccountType,
+		&paymentMethod.MaskedAccountNumber, &paymentMethod.RoutingNumber, &paymentMethod.SortCode, &paymentMethod.BankName, &paymentMethod.BankAddress, &paymentMethod.SwiftCode, &paymentMethod.IsDefault,
+		&paymentMethod.CreatedAt, &paymentMethod.UpdatedAt)
+	return paymentMethod, err
+}
+
+// GetPaymentMethods retrieves the payment methods of the specified user that were not deleted, the default first
+// and the others from the oldest.
+func (u *userRepoImpl) GetPaymentMethods(ctx context.Context, userID uuid.UUID) ([]models.UserPaymentMethod, error) {
+	rows, err := u.DBPool.Query(ctx, `
+        SELECT `+paymentMethodColumns+`
+        FROM user_payment_methods
+        WHERE user_id = $1 AND deleted_at IS NULL
+        ORDER BY is_default DESC, created_at, payment_method_id`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	paymentMethods := make([]models.UserPaymentMethod, 0)
+	for rows.Next() {
+		paymentMethod, err := scanPaymentMethod(rows)
+		if err != nil {
+			return nil, err
+		}
+		paymentMethods = append(paymentMethods, paymentMethod)
+	}
+	return paymentMethods, rows.Err()
+}
+
+// GetDefaultPaymentMethod retrieves the default payment method of the specified user, or nil when they have none.
+func (u *userRepoImpl) GetDefaultPaymentMethod(ctx context.Context, userID uuid.UUID) (*models.UserPaymentMethod, error) {
+	paymentMethod, err := scanPaymentMethod(u.DBPool.QueryRow(ctx, `
+        SELECT `+paymentMethodColumns+`
+        FROM user_payment_methods
+        WHERE user_id = $1 AND is_default`,
+		userID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &paymentMethod, nil
+}
+
+// UpdatePaymentMethod changes the fields set in the update on a payment method of the specified user, and returns
+// the updated payment method. The routing number and sort code are replaced along with the account number. A payment
+// method that invoices were sent with is not updated and ErrPaymentMethodInUse is returned, so that the invoices keep
+// showing the details they were sent with.
+func (u *userRepoImpl) UpdatePaymentMethod(ctx context.Context, userID, paymentMethodID uuid.UUID, update models.PaymentMethodUpdate) (*models.UserPaymentMethod, error) {
+	var envelope encryption.Envelope
+	var keyID, last4 *string
+	if update.AccountNumber != nil {
+		var err error
+		var digits string
+		envelope, digits, err = encryptAccountNumber(u.Keyring, *update.AccountNumber)
+		if err != nil {
+			return nil, err
+		}
+		keyID, last4 = &envelope.KeyID, &digits
+	}
+
+	paymentMethod, err := scanPaymentMethod(u.DBPool.QueryRow(ctx, `
+        UPDATE user_payment_methods SET
+            account_name = COALESCE($3, account_name),
+            account_type = COALESCE($11, account_type),
+            routing_number = CASE WHEN $4::bytea IS NULL THEN routing_number ELSE $12 END,
+            sort_code = CASE WHEN $4::bytea IS NULL THEN sort_code ELSE $13 END,
+            account_number = CASE WHEN $4::bytea IS NULL THEN account_number END,
+            account_number_ciphertext = COALESCE($4, account_number_ciphertext),
+            account_number_data_key = COALESCE($5, account_number_data_key),
+            account_number_key_id = COALESCE($6, account_number_key_id),
+            account_number_last4 = COALESCE($7, account_number_last4),
+            bank_name = COALESCE($8, bank_name),
+            bank_address = COALESCE($9, bank_address),
+            swift_code = COALESCE($10, swift_code),
+            updated_at = CURRENT_TIMESTAMP
+        WHERE payment_method_id = $1 AND user_id = $2 AND deleted_at IS NULL
+            AND NOT EXISTS (SELECT 1 FROM payment_information WHERE payment_method_id = $1)
+        RETURNING `+paymentMethodColumns,
+		paymentMethodID, userID, update.AccountName, envelope.Ciphertext, envelope.DataKey, keyID, last4,
+		update.BankName, update.BankAddress, update.SwiftCode, update.AccountType, update.RoutingNumber, update.SortCode,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		var exists bool
+		err := u.DBPool.QueryRow(ctx, `
+            SELECT EXISTS (
+                SELECT 1 FROM user_payment_methods
+                WHERE payment_method_id = $1 AND user_id = $2 AND deleted_at IS NULL
+            )`,
+			paymentMethodID, userID,
+		).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, models.ErrPaymentMethodInUse
+		}
+		return nil, models.ErrPaymentMethodNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &paymentMethod, nil
+}
+
+// DeletePaymentMethod deletes a payment method of the specified user. A payment method that invoices were sent with
+// is only marked as deleted, so those invoices keep showing it. When the default payment method is deleted, the
+// oldest remaining payment method of the user becomes the default.
+func (u *userRepoImpl) DeletePaymentMethod(ctx context.Context, userID, paymentMethodID uuid.UUID) error {
+	tx, err := u.DBPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockPaymentMethods(ctx, tx, userID); err != nil {
+		return err
+	}
+
+	var isDefault, referenced bool
+	err = tx.QueryRow(ctx, `
+        SELECT is_default, EXISTS (SELECT 1 FROM payment_information WHERE payment_method_id = $1)
+        FROM user_payment_methods
+        WHERE payment_method_id = $1 AND user_id = $2 AND deleted_at IS NULL`,
+		paymentMethodID, userID,
+	).Scan(&isDefault, &referenced)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ErrPaymentMethodNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if referenced {
+		_, err = tx.Exec(ctx, `
+            UPDATE user_payment_methods
+            SET deleted_at = CURRENT_TIMESTAMP, is_default = false, updated_at = CURRENT_TIMESTAMP
+            WHERE payment_method_id = $1`,
+			paymentMethodID,
+		)
+	} else {
+		_, err = tx.Exec(ctx, `DELETE FROM user_payment_methods WHERE payment_method_id = $1`, paymentMethodID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if isDefault {
+		if _, err := tx.Exec(ctx, `
+            UPDATE user_payment_methods SET is_default = true, updated_at = CURRENT_TIMESTAMP
+            WHERE payment_method_id = (
+                SELECT payment_method_id FROM user_payment_methods
+                WHERE user_id = $1 AND deleted_at IS NULL
+                ORDER BY created_at, payment_method_id
+                LIMIT 1
+            )`,
+			userID,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// SetDefaultPaymentMethod makes a payment method of the specified user their default, in place of their previous
+// default payment method.
+func (u *userRepoImpl) SetDefaultPaymentMethod(ctx context.Context, userID, paymentMethodID uuid.UUID) error {
+	tx, err := u.DBPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockPaymentMethods(ctx, tx, userID); err != nil {
+		return err
+	}
+
+	// the previous default is unset first, since the unique index allows a single default per user at any time
+	if _, err := tx.Exec(ctx, `
+        UPDATE user_payment_methods SET is_default = false, updated_at = CURRENT_TIMESTAMP
+        WHERE user_id = $1 AND is_default AND payment_method_id <> $2`,
+		userID, paymentMethodID,
+	); err != nil {
+		return err
+	}
+	tag, err := tx.Exec(ctx, `
+        UPDATE user_payment_methods SET is_default = true, updated_at = CURRENT_TIMESTAMP
+        WHERE payment_method_id = $1 AND user_id = $2 AND deleted_at IS NULL`,
+		paymentMethodID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrPaymentMethodNotFound
+	}
+
+	return tx.Commit(ctx)
+}
+
+// lockPaymentMethods locks the payment methods of the specified user for the rest of the transaction, so that
+// concurrent changes of their default payment method happen one after the other.
+func lockPaymentMethods(ctx context.Context, tx pgx.Tx, userID uuid.UUID) error {
+	_, err := tx.Exec(ctx, `SELECT 1 FROM user_payment_methods WHERE user_id = $1 FOR UPDATE`, userID)
+	return err
+}
+
+// ReencryptAccountNumbers encrypts up to limit account numbers that are not encrypted with the active key of the
+// keyring with it, including account numbers stored in plaintext before account numbers were encrypted, and returns
+// how many were encrypted. Account numbers being encrypted by a concurrent call are skipped.
+func (u *userRepoImpl) ReencryptAccountNumbers(ctx context.Context, limit int32) (int, error) {
+	tx, err := u.DBPool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+        SELECT payment_method_id, account_number, account_number_ciphertext, account_number_data_key, account_number_key_id
+        FROM user_payment_methods
+        WHERE account_number IS NOT NULL OR account_number_key_id <> $1
+        ORDER BY payment_method_id
+        LIMIT $2
+        FOR UPDATE SKIP LOCKED`,
+		u.Keyring.ActiveKeyID(), limit,
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	paymentMethodIDs := make([]uuid.UUID, 0, limit)
+	accounts := make([]storedAccountNumber, 0, limit)
+	for rows.Next() {
+		var paymentMethodID uuid.UUID
+		var account storedAccountNumber
+		err := rows.Scan(&paymentMethodID, &account.plaintext, &account.envelope.Ciphertext, &account.envelope.DataKey, &account.keyID)
+		if err != nil {
+			return 0, err
+		}
+		paymentMethodIDs = append(paymentMethodIDs, paymentMethodID)
+		accounts = append(accounts, account)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for idx, account := range accounts {
+		number, err := account.decrypt(u.Keyring)
+		if err != nil {
+			return 0, fmt.Errorf("payment method %s: %w", paymentMethodIDs[idx], err)
+		}
+		envelope, last4, err := encryptAccountNumber(u.Keyring, number)
+		if err != nil {
+			return 0, err
+		}
+		_, err = tx.Exec(ctx, `
+            UPDATE user_payment_methods
+            SET account_number = NULL, account_number_ciphertext = $2, account_number_data_key = $3,
+                account_number_key_id = $4, account_number_last4 = $5
+            WHERE payment_method_id = $1`,
+			paymentMethodIDs[idx], envelope.Ciphertext, envelope.DataKey, envelope.KeyID, last4,
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(accounts), tx.Commit(ctx)
+}
+
+// storedAccountNumber is an account number as stored: encrypted, or in plaintext when it was stored before account
+// numbers were encrypted and has not been encrypted by a key rotation since.
+type storedAccountNumber struct {
+	plaintext *string
+	keyID     *string
+	envelope  encryption.Envelope
+}
+
+// decrypt returns the account number, decrypted with the keyring unless it is stored in plaintext.
+func (a storedAccountNumber) decrypt(keyring *encryption.Keyring) (string, error) {
+	if a.plaintext != nil {
+		return *a.plaintext, nil
+	}
+	if a.keyID == nil {
+		return "", nil
+	}
+	a.envelope.KeyID = *a.keyID
+	number, err := keyring.Decrypt(a.envelope)
+	if err != nil {
+		return "", err
+	}
+	return string(number), nil
+}
+
+// encryptAccountNumber encrypts an account number with the active key of the keyring, and returns it along with
+// its last four digits, which are stored in plaintext to show the account number masked.
+func encryptAccountNumber(keyring *encryption.Keyring, number string) (encryption.Envelope, string, error) {
+	envelope, err := keyring.Encrypt([]byte(number))
+	if err != nil {
+		return encryption.Envelope{}, "", err
+	}
+	digits := []rune(number)
+	return envelope, string(digits[max(len(digits)-4, 0):]), nil
 }
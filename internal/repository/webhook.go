@@ -0,0 +1,373 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zde37/Numeris-Task/internal/encryption"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/pagination"
+)
+
+type webhookRepoImpl struct {
+	DBPool  *pgxpool.Pool
+	Keyring *encryption.Keyring
+}
+
+// newWebhookRepoImpl creates a new instance of the webhookRepoImpl struct, which is used to interact with the
+// webhooks and their deliveries stored in the database. The secrets of webhooks are encrypted with the keyring.
+func newWebhookRepoImpl(dbPool *pgxpool.Pool, keyring *encryption.Keyring) *webhookRepoImpl {
+	return &webhookRepoImpl{
+		DBPool:  dbPool,
+		Keyring: keyring,
+	}
+}
+
+// webhookColumns are the columns a webhook is scanned from by scanWebhook.
+const webhookColumns = `webhook_id, user_id, url, event_types, last_xid::text::bigint, last_sequence, failure_count,
+    disabled_at, created_at`
+
+// scanWebhook scans a row of webhookColumns into a webhook.
+func scanWebhook(row pgx.Row) (*models.Webhook, error) {
+	var webhook models.Webhook
+	var eventTypes []string
+	err := row.Scan(&webhook.WebhookID, &webhook.UserID, &webhook.URL, &eventTypes, &webhook.LastEvent.Xid,
+		&webhook.LastEvent.Sequence, &webhook.FailureCount, &webhook.DisabledAt, &webhook.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	webhook.EventTypes = make([]models.WebhookEventType, len(eventTypes))
+	for idx, eventType := range eventTypes {
+		webhook.EventTypes[idx] = models.WebhookEventType(eventType)
+	}
+	return &webhook, nil
+}
+
+// webhookDeliveryColumns are the columns a webhook delivery is scanned from by webhookDeliveryFields.
+const webhookDeliveryColumns = `delivery_id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+    response_status, last_error, replay_of, created_at, delivered_at`
+
+// webhookDeliveryFields returns the fields a row of webhookDeliveryColumns is scanned into.
+func webhookDeliveryFields(delivery *models.WebhookDelivery) []any {
+	return []any{
+		&delivery.DeliveryID, &delivery.WebhookID, &delivery.EventID, &delivery.EventType, &delivery.Payload,
+		&delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.ResponseStatus, &delivery.LastError,
+		&delivery.ReplayOf, &delivery.CreatedAt, &delivery.DeliveredAt,
+	}
+}
+
+// CreateWebhook stores a new webhook with its secret encrypted and returns its ID. Only the events recorded from now
+// on are delivered to it, from the oldest transaction still in progress.
+func (w *webhookRepoImpl) CreateWebhook(ctx context.Context, webhook models.Webhook) (uuid.UUID, error) {
+	envelope, err := w.Keyring.Encrypt([]byte(webhook.Secret))
+	if err != nil {
+		return uuid.Nil, err
+	}
+	eventTypes := make([]string, len(webhook.EventTypes))
+	for idx, eventType := range webhook.EventTypes {
+		eventTypes[idx] = string(eventType)
+	}
+
+	err = w.DBPool.QueryRow(ctx, `
+        INSERT INTO webhooks (webhook_id, user_id, url, event_types, secret_ciphertext, secret_data_key, secret_key_id,
+                              last_xid, last_sequence)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, pg_snapshot_xmin(pg_current_snapshot()), 0)
+        RETURNING webhook_id`,
+		webhook.WebhookID, webhook.UserID, webhook.URL, eventTypes, envelope.Ciphertext, envelope.DataKey,
+		envelope.KeyID,
+	).Scan(&webhook.WebhookID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return webhook.WebhookID, nil
+}
+
+// GetWebhooks returns the webhooks of a user, newest first, without their secrets.
+func (w *webhookRepoImpl) GetWebhooks(ctx context.Context, userID uuid.UUID) ([]models.Webhook, error) {
+	return w.queryWebhooks(ctx, `
+        SELECT `+webhookColumns+`
+        FROM webhooks
+        WHERE user_id = $1
+        ORDER BY created_at DESC, webhook_id`,
+		userID,
+	)
+}
+
+// GetActiveWebhooks returns the webhooks of every user that are not disabled, without their secrets.
+func (w *webhookRepoImpl) GetActiveWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	return w.queryWebhooks(ctx, `
+        SELECT `+webhookColumns+`
+        FROM webhooks
+        WHERE disabled_at IS NULL
+        ORDER BY webhook_id`,
+	)
+}
+
+// queryWebhooks returns the webhooks a query of webhookColumns reads.
+func (w *webhookRepoImpl) queryWebhooks(ctx context.Context, query string, args ...any) ([]models.Webhook, error) {
+	rows, err := w.DBPool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, *webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+// GetWebhook returns a webhook of the user without its secret, or nil when the user has no such webhook.
+func (w *webhookRepoImpl) GetWebhook(ctx context.Context, userID, webhookID uuid.UUID) (*models.Webhook, error) {
+	webhook, err := scanWebhook(w.DBPool.QueryRow(ctx, `
+        SELECT `+webhookColumns+`
+        FROM webhooks
+        WHERE webhook_id = $1 AND user_id = $2`,
+		webhookID, userID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return webhook, err
+}
+
+// DeleteWebhook deletes a webhook of the user along with its deliveries. It returns models.ErrWebhookNotFound when
+// the user has no such webhook.
+func (w *webhookRepoImpl) DeleteWebhook(ctx context.Context, userID, webhookID uuid.UUID) error {
+	result, err := w.DBPool.Exec(ctx, `DELETE FROM webhooks WHERE webhook_id = $1 AND user_id = $2`, webhookID, userID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return models.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// EnableWebhook enables a disabled webhook of the user again, and returns it. The events recorded while it was
+// disabled are not delivered, but its deliveries still pending are. It returns models.ErrWebhookNotFound when the user
+// has no such webhook.
+func (w *webhookRepoImpl) EnableWebhook(ctx context.Context, userID, webhookID uuid.UUID) (*models.Webhook, error) {
+	webhook, err := scanWebhook(w.DBPool.QueryRow(ctx, `
+        UPDATE webhooks
+        SET disabled_at = NULL, failure_count = 0, updated_at = CURRENT_TIMESTAMP,
+            last_xid = CASE WHEN disabled_at IS NULL THEN last_xid ELSE pg_snapshot_xmin(pg_current_snapshot()) END,
+            last_sequence = CASE WHEN disabled_at IS NULL THEN last_sequence ELSE 0 END
+        WHERE webhook_id = $1 AND user_id = $2
+        RETURNING `+webhookColumns,
+		webhookID, userID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrWebhookNotFound
+	}
+	return webhook, err
+}
+
+// QueueWebhookDeliveries queues the deliveries of the events of the log up to the last one for a webhook, and moves it
+// past them. Deliveries of events another server queued already are skipped by their event ID, so no event is queued
+// twice for a webhook; the events are still delivered at least once, as the URL may not answer a delivery it took.
+func (w *webhookRepoImpl) QueueWebhookDeliveries(ctx context.Context, webhookID uuid.UUID, deliveries []models.WebhookDelivery, last models.EventCursor) error {
+	tx, err := w.DBPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var queued models.EventCursor
+	err = tx.QueryRow(ctx, `SELECT last_xid::text::bigint, last_sequence FROM webhooks WHERE webhook_id = $1 FOR UPDATE`,
+		webhookID).Scan(&queued.Xid, &queued.Sequence)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// the webhook was deleted in the meantime
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !last.After(queued) {
+		return nil
+	}
+
+	for _, delivery := range deliveries {
+		_, err := tx.Exec(ctx, `
+            INSERT INTO webhook_deliveries (delivery_id, webhook_id, event_id, event_type, payload, status, next_attempt_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7)
+            ON CONFLICT (webhook_id, event_id) WHERE replay_of IS NULL DO NOTHING`,
+			delivery.DeliveryID, webhookID, delivery.EventID, delivery.EventType, delivery.Payload,
+			models.WebhookDeliveryStatusPending, delivery.NextAttemptAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+        UPDATE webhooks
+        SET last_xid = $2::bigint::text::xid8, last_sequence = $3, updated_at = CURRENT_TIMESTAMP
+        WHERE webhook_id = $1`,
+		webhookID, last.Xid, last.Sequence,
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ClaimDueWebhookDeliveries claims up to limit pending deliveries of enabled webhooks due as of the given time, with
+// the URL and the decrypted secret of their webhook. Claimed deliveries are not due again until the lease runs out, so
+// other servers do not send them meanwhile, and deliveries claimed by another server are skipped.
+func (w *webhookRepoImpl) ClaimDueWebhookDeliveries(ctx context.Context, asOf time.Time, lease time.Duration, limit int32) ([]models.DueWebhookDelivery, error) {
+	rows, err := w.DBPool.Query(ctx, `
+        WITH claimed AS (
+            UPDATE webhook_deliveries
+            SET next_attempt_at = $2
+            WHERE delivery_id IN (
+                SELECT d.delivery_id
+                FROM webhook_deliveries d
+                JOIN webhooks w ON w.webhook_id = d.webhook_id
+                WHERE d.status = $4 AND d.next_attempt_at <= $1 AND w.disabled_at IS NULL
+                ORDER BY d.next_attempt_at
+                LIMIT $3
+                FOR UPDATE OF d SKIP LOCKED
+            )
+            RETURNING *
+        )
+        SELECT `+webhookDeliveryColumns+`, url, secret_ciphertext, secret_data_key, secret_key_id
+        FROM claimed
+        JOIN (SELECT webhook_id, url, secret_ciphertext, secret_data_key, secret_key_id FROM webhooks) w USING (webhook_id)
+        ORDER BY next_attempt_at`,
+		asOf, asOf.Add(lease), limit, models.WebhookDeliveryStatusPending,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	due := []models.DueWebhookDelivery{}
+	for rows.Next() {
+		var delivery models.DueWebhookDelivery
+		var envelope encryption.Envelope
+		fields := append(webhookDeliveryFields(&delivery.Delivery), &delivery.URL, &envelope.Ciphertext,
+			&envelope.DataKey, &envelope.KeyID)
+		if err := rows.Scan(fields...); err != nil {
+			return nil, err
+		}
+		secret, err := w.Keyring.Decrypt(envelope)
+		if err != nil {
+			return nil, fmt.Errorf("webhook %s: %w", delivery.Delivery.WebhookID, err)
+		}
+		delivery.Secret = string(secret)
+		due = append(due, delivery)
+	}
+	return due, rows.Err()
+}
+
+// RecordWebhookDeliveryAttempt records the outcome of sending a delivery. A successful delivery clears the failures of
+// its webhook, and a delivery that failed for good adds one, disabling the webhook once it reaches maxFailures.
+func (w *webhookRepoImpl) RecordWebhookDeliveryAttempt(ctx context.Context, attempt models.WebhookDeliveryAttempt, maxFailures int) error {
+	tx, err := w.DBPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var deliveredAt *time.Time
+	if attempt.Status == models.WebhookDeliveryStatusSucceeded {
+		deliveredAt = &attempt.AttemptedAt
+	}
+	_, err = tx.Exec(ctx, `
+        UPDATE webhook_deliveries
+        SET status = $2, attempts = attempts + 1, next_attempt_at = $3, response_status = $4, last_error = $5,
+            delivered_at = $6
+        WHERE delivery_id = $1`,
+		attempt.DeliveryID, attempt.Status, attempt.NextAttemptAt, attempt.ResponseStatus, attempt.Error, deliveredAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	switch attempt.Status {
+	case models.WebhookDeliveryStatusSucceeded:
+		_, err = tx.Exec(ctx, `
+            UPDATE webhooks
+            SET failure_count = 0, updated_at = CURRENT_TIMESTAMP
+            WHERE webhook_id = $1 AND failure_count > 0`,
+			attempt.WebhookID,
+		)
+	case models.WebhookDeliveryStatusFailed:
+		_, err = tx.Exec(ctx, `
+            UPDATE webhooks
+            SET failure_count = failure_count + 1, updated_at = CURRENT_TIMESTAMP,
+                disabled_at = CASE WHEN failure_count + 1 >= $2 THEN COALESCE(disabled_at, $3) ELSE disabled_at END
+            WHERE webhook_id = $1`,
+			attempt.WebhookID, maxFailures, attempt.AttemptedAt,
+		)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// GetWebhookDeliveries retrieves a page of the deliveries of a webhook, newest first, along with their total count.
+func (w *webhookRepoImpl) GetWebhookDeliveries(ctx context.Context, webhookID uuid.UUID, page pagination.Request) ([]models.WebhookDelivery, *pagination.Meta, error) {
+	query := listQuery{
+		columns:    webhookDeliveryColumns,
+		from:       "webhook_deliveries",
+		conditions: []string{"webhook_id = $1"},
+		args:       []any{webhookID},
+		key:        "created_at",
+		keyType:    "timestamptz",
+		id:         "delivery_id",
+		descending: true,
+	}
+	return fetchPage(ctx, w.DBPool, query, page, scanWebhookDeliveries, func(delivery models.WebhookDelivery) pagination.Cursor {
+		return pagination.Cursor{Key: timeKey(delivery.CreatedAt), ID: delivery.DeliveryID}
+	})
+}
+
+// scanWebhookDeliveries reads rows of webhookDeliveryColumns into webhook deliveries.
+func scanWebhookDeliveries(rows pgx.Rows) ([]models.WebhookDelivery, error) {
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		if err := rows.Scan(webhookDeliveryFields(&delivery)...); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// ReplayWebhookDelivery queues a delivery of a webhook of the user again as a new delivery with the given ID, due as
+// of the given time, and returns it. Replays of a disabled webhook wait until it is enabled. It returns
+// models.ErrWebhookDeliveryNotFound when the user has no such delivery.
+func (w *webhookRepoImpl) ReplayWebhookDelivery(ctx context.Context, userID, deliveryID, replayID uuid.UUID, asOf time.Time) (*models.WebhookDelivery, error) {
+	var replay models.WebhookDelivery
+	err := w.DBPool.QueryRow(ctx, `
+        INSERT INTO webhook_deliveries (delivery_id, webhook_id, event_id, event_type, payload, status, next_attempt_at,
+                                        replay_of)
+        SELECT $3, d.webhook_id, d.event_id, d.event_type, d.payload, $4, $5, d.delivery_id
+        FROM webhook_deliveries d
+        JOIN webhooks w ON w.webhook_id = d.webhook_id
+        WHERE d.delivery_id = $1 AND w.user_id = $2
+        RETURNING `+webhookDeliveryColumns,
+		deliveryID, userID, replayID, models.WebhookDeliveryStatusPending, asOf,
+	).Scan(webhookDeliveryFields(&replay)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &replay, nil
+}
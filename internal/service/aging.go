@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zde37/Numeris-Task/internal/helpers"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/repository"
+)
+
+type agingServiceImpl struct {
+	aging repository.AgingRepository
+}
+
+// newAgingServiceImpl creates a new instance of the agingServiceImpl struct, which implements the AgingService interface.
+// It takes an AgingRepository implementation as a dependency.
+func newAgingServiceImpl(aging repository.AgingRepository) *agingServiceImpl {
+	return &agingServiceImpl{
+		aging: aging,
+	}
+}
+
+// GetAgingReport retrieves the accounts receivable aging of a sender as of the given date. Outstanding balances are
+// bucketed by the days past their due date into current, 1-30, 31-60, 61-90 and 90+ days, per customer and currency
+// and in total per currency. Amounts in different currencies are never added together.
+func (s *agingServiceImpl) GetAgingReport(ctx context.Context, senderID uuid.UUID, asOf time.Time, customerID *uuid.UUID) (*models.AgingReport, error) {
+	asOf = time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+
+	invoices, err := s.openInvoices(ctx, senderID, asOf, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.AgingReport{
+		SenderID:  senderID,
+		AsOf:      asOf,
+		Customers: []models.AgingLine{},
+		Totals:    []models.AgingTotal{},
+	}
+
+	// invoices are ordered by customer and currency, so each customer line only needs comparing with the last one
+	totals := map[string]int{}
+	for _, invoice := range invoices {
+		last := len(report.Customers) - 1
+		if last < 0 || report.Customers[last].CustomerID != invoice.CustomerID || report.Customers[last].Currency != invoice.Currency {
+			report.Customers = append(report.Customers, models.AgingLine{
+				CustomerID:   invoice.CustomerID,
+				CustomerName: invoice.CustomerName,
+				Currency:     invoice.Currency,
+			})
+			last++
+		}
+		line := &report.Customers[last]
+		line.InvoiceCount++
+		addToAgingBalances(&line.AgingBalances, invoice)
+
+		i, ok := totals[invoice.Currency]
+		if !ok {
+			i = len(report.Totals)
+			totals[invoice.Currency] = i
+			report.Totals = append(report.Totals, models.AgingTotal{Currency: invoice.Currency})
+		}
+		report.Totals[i].InvoiceCount++
+		addToAgingBalances(&report.Totals[i].AgingBalances, invoice)
+	}
+
+	for i := range report.Customers {
+		roundAgingBalances(&report.Customers[i].AgingBalances)
+	}
+	for i := range report.Totals {
+		roundAgingBalances(&report.Totals[i].AgingBalances)
+	}
+	return report, nil
+}
+
+// GetAgingInvoices retrieves the invoices of a sender with a balance left as of the given date that fall in the
+// specified aging bucket, optionally limited to a single customer.
+func (s *agingServiceImpl) GetAgingInvoices(ctx context.Context, senderID uuid.UUID, asOf time.Time, bucket models.AgingBucket, customerID *uuid.UUID) ([]models.AgingInvoice, error) {
+	if err := helpers.ValidateAgingBucket(string(bucket)); err != nil {
+		return nil, err
+	}
+	asOf = time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+
+	invoices, err := s.openInvoices(ctx, senderID, asOf, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	inBucket := []models.AgingInvoice{}
+	for _, invoice := range invoices {
+		if invoice.Bucket == bucket {
+			inBucket = append(inBucket, invoice)
+		}
+	}
+	return inBucket, nil
+}
+
+// openInvoices loads the invoices with a balance left as of the given date and works out how many days past due
+// each one is and the aging bucket it falls in.
+func (s *agingServiceImpl) openInvoices(ctx context.Context, senderID uuid.UUID, asOf time.Time, customerID *uuid.UUID) ([]models.AgingInvoice, error) {
+	invoices, err := s.aging.GetOpenInvoices(ctx, senderID, asOf, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range invoices {
+		invoices[i].DaysPastDue = max(helpers.DaysBetween(invoices[i].DueDate, asOf), 0)
+		invoices[i].Bucket = agingBucket(invoices[i].DaysPastDue)
+	}
+	return invoices, nil
+}
+
+// agingBucket returns the aging bucket of a balance that is the given number of days past due.
+func agingBucket(daysPastDue int) models.AgingBucket {
+	switch {
+	case daysPastDue <= 0:
+		return models.AgingBucketCurrent
+	case daysPastDue <= 30:
+		return models.AgingBucket1To30
+	case daysPastDue <= 60:
+		return models.AgingBucket31To60
+	case daysPastDue <= 90:
+		return models.AgingBucket61To90
+	default:
+		return models.AgingBucketOver90
+	}
+}
+
+// addToAgingBalances adds the balance of an invoice to its aging bucket and to the total.
+func addToAgingBalances(balances *models.AgingBalances, invoice models.AgingInvoice) {
+	switch invoice.Bucket {
+	case models.AgingBucketCurrent:
+		balances.Current += invoice.Balance
+	case models.AgingBucket1To30:
+		balances.Days1To30 += invoice.Balance
+	case models.AgingBucket31To60:
+		balances.Days31To60 += invoice.Balance
+	case models.AgingBucket61To90:
+		balances.Days61To90 += invoice.Balance
+	case models.AgingBucketOver90:
+		balances.Over90 += invoice.Balance
+	}
+	balances.Total += invoice.Balance
+}
+
+// roundAgingBalances rounds every bucket of the aging balances to two decimal places.
+func roundAgingBalances(balances *models.AgingBalances) {
+	balances.Current = helpers.RoundAmount(balances.Current)
+	balances.Days1To30 = helpers.RoundAmount(balances.Days1To30)
+	balances.Days31To60 = helpers.RoundAmount(balances.Days31To60)
+	balances.Days61To90 = helpers.RoundAmount(balances.Days61To90)
+	balances.Over90 = helpers.RoundAmount(balances.Over90)
+	balances.Total = helpers.RoundAmount(balances.Total)
+}
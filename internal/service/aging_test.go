@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetAgingReport(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockAgingRepository(ctrl)
+	service := newAgingServiceImpl(repo)
+
+	senderID := uuid.New()
+	acme, globex := uuid.New(), uuid.New()
+	now := time.Date(2024, 6, 30, 15, 30, 0, 0, time.UTC)
+	today := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	due := func(daysAgo int) time.Time { return today.AddDate(0, 0, -daysAgo) }
+
+	t.Run("successful retrieval", func(t *testing.T) {
+		repo.EXPECT().
+			GetOpenInvoices(gomock.Any(), senderID, today, (*uuid.UUID)(nil)).
+			Return([]models.AgingInvoice{
+				{CustomerID: acme, CustomerName: "Acme", Currency: "NGN", DueDate: due(120), Balance: 100.10},
+				{CustomerID: acme, CustomerName: "Acme", Currency: "NGN", DueDate: due(30), Balance: 200.20},
+				{CustomerID: acme, CustomerName: "Acme", Currency: "NGN", DueDate: due(-5), Balance: 50},
+				{CustomerID: acme, CustomerName: "Acme", Currency: "USD", DueDate: due(31), Balance: 10},
+				{CustomerID: globex, CustomerName: "Globex", Currency: "NGN", DueDate: due(90), Balance: 300},
+				{CustomerID: globex, CustomerName: "Globex", Currency: "NGN", DueDate: due(0), Balance: 25},
+			}, nil)
+
+		report, err := service.GetAgingReport(ctx, senderID, now, nil)
+		require.NoError(t, err)
+		require.Equal(t, senderID, report.SenderID)
+		require.Equal(t, today, report.AsOf)
+		require.Equal(t, []models.AgingLine{
+			{CustomerID: acme, CustomerName: "Acme", Currency: "NGN", InvoiceCount: 3, AgingBalances: models.AgingBalances{
+				Current: 50, Days1To30: 200.20, Over90: 100.10, Total: 350.30,
+			}},
+			{CustomerID: acme, CustomerName: "Acme", Currency: "USD", InvoiceCount: 1, AgingBalances: models.AgingBalances{
+				Days31To60: 10, Total: 10,
+			}},
+			{CustomerID: globex, CustomerName: "Globex", Currency: "NGN", InvoiceCount: 2, AgingBalances: models.AgingBalances{
+				Current: 25, Days61To90: 300, Total: 325,
+			}},
+		}, report.Customers)
+		require.Equal(t, []models.AgingTotal{
+			{Currency: "NGN", InvoiceCount: 5, AgingBalances: models.AgingBalances{
+				Current: 75, Days1To30: 200.20, Days61To90: 300, Over90: 100.10, Total: 675.30,
+			}},
+			{Currency: "USD", InvoiceCount: 1, AgingBalances: models.AgingBalances{Days31To60: 10, Total: 10}},
+		}, report.Totals)
+	})
+
+	t.Run("no open invoices", func(t *testing.T) {
+		repo.EXPECT().GetOpenInvoices(gomock.Any(), senderID, today, &acme).Return([]models.AgingInvoice{}, nil)
+
+		report, err := service.GetAgingReport(ctx, senderID, now, &acme)
+		require.NoError(t, err)
+		require.Empty(t, report.Customers)
+		require.Empty(t, report.Totals)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repo.EXPECT().GetOpenInvoices(gomock.Any(), senderID, today, (*uuid.UUID)(nil)).Return(nil, errors.New("database error"))
+
+		report, err := service.GetAgingReport(ctx, senderID, now, nil)
+		require.Error(t, err)
+		require.Nil(t, report)
+	})
+}
+
+func TestGetAgingInvoices(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockAgingRepository(ctrl)
+	service := newAgingServiceImpl(repo)
+
+	senderID := uuid.New()
+	today := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	first, second, third := uuid.New(), uuid.New(), uuid.New()
+
+	t.Run("successful retrieval", func(t *testing.T) {
+		repo.EXPECT().
+			GetOpenInvoices(gomock.Any(), senderID, today, (*uuid.UUID)(nil)).
+			Return([]models.AgingInvoice{
+				{InvoiceID: first, DueDate: today.AddDate(0, 0, -45), Balance: 100},
+				{InvoiceID: second, DueDate: today.AddDate(0, 0, -10), Balance: 200},
+				{InvoiceID: third, DueDate: today.AddDate(0, 0, -31), Balance: 300},
+			}, nil)
+
+		invoices, err := service.GetAgingInvoices(ctx, senderID, today, models.AgingBucket31To60, nil)
+		require.NoError(t, err)
+		require.Len(t, invoices, 2)
+		require.Equal(t, first, invoices[0].InvoiceID)
+		require.Equal(t, 45, invoices[0].DaysPastDue)
+		require.Equal(t, models.AgingBucket31To60, invoices[0].Bucket)
+		require.Equal(t, third, invoices[1].InvoiceID)
+		require.Equal(t, 31, invoices[1].DaysPastDue)
+	})
+
+	t.Run("invalid bucket", func(t *testing.T) {
+		invoices, err := service.GetAgingInvoices(ctx, senderID, today, "120+", nil)
+		require.ErrorContains(t, err, "invalid aging bucket")
+		require.Nil(t, invoices)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repo.EXPECT().GetOpenInvoices(gomock.Any(), senderID, today, (*uuid.UUID)(nil)).Return(nil, errors.New("database error"))
+
+		invoices, err := service.GetAgingInvoices(ctx, senderID, today, models.AgingBucketCurrent, nil)
+		require.Error(t, err)
+		require.Nil(t, invoices)
+	})
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zde37/Numeris-Task/internal/exchangerate"
+	"github.com/zde37/Numeris-Task/internal/helpers"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/repository"
+)
+
+type currencyServiceImpl struct {
+	currency repository.CurrencyRepository
+	source   exchangerate.Source
+}
+
+// newCurrencyServiceImpl creates a new instance of the currencyServiceImpl struct, which implements the CurrencyService interface.
+// It takes a CurrencyRepository implementation and the Source exchange rates are refreshed from as dependencies.
+// The source may be nil when no rates provider is configured.
+func newCurrencyServiceImpl(currency repository.CurrencyRepository, source exchangerate.Source) *currencyServiceImpl {
+	return &currencyServiceImpl{
+		currency: currency,
+		source:   source,
+	}
+}
+
+// RefreshExchangeRates fetches the latest exchange rates from the configured source and stores them.
+// It returns the number of rates stored.
+func (s *currencyServiceImpl) RefreshExchangeRates(ctx context.Context) (int, error) {
+	if s.source == nil {
+		return 0, fmt.Errorf("no exchange rate source configured")
+	}
+
+	rates, err := s.source.Fetch(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(rates) == 0 {
+		return 0, nil
+	}
+	return s.currency.SaveExchangeRates(ctx, rates)
+}
+
+// GetExchangeRate retrieves the rate converting the from currency into the to currency on the given date.
+func (s *currencyServiceImpl) GetExchangeRate(ctx context.Context, from, to string, on time.Time) (*models.ExchangeRate, error) {
+	if err := helpers.ValidateCurrency(from); err != nil {
+		return nil, err
+	}
+	if err := helpers.ValidateCurrency(to); err != nil {
+		return nil, err
+	}
+	return findExchangeRate(ctx, s.currency, from, to, on)
+}
+
+// findExchangeRate looks up the rate converting the from currency into the to currency on the given date.
+// Converting a currency into itself always uses a rate of 1.
+func findExchangeRate(ctx context.Context, currency repository.CurrencyRepository, from, to string, on time.Time) (*models.ExchangeRate, error) {
+	if from == to {
+		return &models.ExchangeRate{FromCurrency: from, ToCurrency: to, Rate: 1, EffectiveDate: on}, nil
+	}
+	return currency.GetExchangeRate(ctx, from, to, on)
+}
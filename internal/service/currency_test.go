@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"go.uber.org/mock/gomock"
+)
+
+type staticSource struct {
+	rates []models.ExchangeRate
+	err   error
+}
+
+func (s staticSource) Fetch(_ context.Context) ([]models.ExchangeRate, error) {
+	return s.rates, s.err
+}
+
+func TestRefreshExchangeRates(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockCurrencyRepository(ctrl)
+	date := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("successful refresh", func(t *testing.T) {
+		rates := []models.ExchangeRate{
+			{FromCurrency: "USD", ToCurrency: "NGN", Rate: 1480.5, EffectiveDate: date, Source: "file"},
+			{FromCurrency: "USD", ToCurrency: "EUR", Rate: 0.92, EffectiveDate: date, Source: "file"},
+		}
+		repo.EXPECT().
+			SaveExchangeRates(gomock.Any(), rates).
+			Return(2, nil)
+
+		service := newCurrencyServiceImpl(repo, staticSource{rates: rates})
+		saved, err := service.RefreshExchangeRates(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 2, saved)
+	})
+
+	t.Run("no rates", func(t *testing.T) {
+		service := newCurrencyServiceImpl(repo, staticSource{})
+		saved, err := service.RefreshExchangeRates(ctx)
+		require.NoError(t, err)
+		require.Zero(t, saved)
+	})
+
+	t.Run("source error", func(t *testing.T) {
+		service := newCurrencyServiceImpl(repo, staticSource{err: errors.New("failed to read exchange rates")})
+		saved, err := service.RefreshExchangeRates(ctx)
+		require.ErrorContains(t, err, "failed to read exchange rates")
+		require.Zero(t, saved)
+	})
+
+	t.Run("no source configured", func(t *testing.T) {
+		service := newCurrencyServiceImpl(repo, nil)
+		_, err := service.RefreshExchangeRates(ctx)
+		require.ErrorContains(t, err, "no exchange rate source configured")
+	})
+}
+
+func TestGetExchangeRate(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockCurrencyRepository(ctrl)
+	service := newCurrencyServiceImpl(repo, nil)
+	on := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+
+	t.Run("stored rate", func(t *testing.T) {
+		expected := &models.ExchangeRate{FromCurrency: "USD", ToCurrency: "NGN", Rate: 1480.5, EffectiveDate: on.AddDate(0, 0, -2)}
+		repo.EXPECT().
+			GetExchangeRate(gomock.Any(), "USD", "NGN", on).
+			Return(expected, nil)
+
+		rate, err := service.GetExchangeRate(ctx, "USD", "NGN", on)
+		require.NoError(t, err)
+		require.Equal(t, expected, rate)
+	})
+
+	t.Run("same currency", func(t *testing.T) {
+		rate, err := service.GetExchangeRate(ctx, "NGN", "NGN", on)
+		require.NoError(t, err)
+		require.Equal(t, 1.0, rate.Rate)
+	})
+
+	t.Run("invalid currency", func(t *testing.T) {
+		_, err := service.GetExchangeRate(ctx, "USD", "ABC", on)
+		require.ErrorContains(t, err, "invalid currency: ABC")
+	})
+
+	t.Run("missing rate", func(t *testing.T) {
+		repo.EXPECT().
+			GetExchangeRate(gomock.Any(), "EUR", "NGN", on).
+			Return(nil, errors.New("no exchange rate from EUR to NGN on 2024-06-03"))
+
+		_, err := service.GetExchangeRate(ctx, "EUR", "NGN", on)
+		require.ErrorContains(t, err, "no exchange rate")
+	})
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/repository"
+)
+
+// dashboardRecentLimit is the number of recent invoices and activities shown on the dashboard.
+const dashboardRecentLimit = 5
+
+type dashboardServiceImpl struct {
+	dashboard repository.DashboardRepository
+}
+
+// newDashboardServiceImpl creates a new instance of the dashboardServiceImpl struct, which implements the DashboardService interface.
+// It takes a DashboardRepository implementation as a dependency.
+func newDashboardServiceImpl(dashboard repository.DashboardRepository) *dashboardServiceImpl {
+	return &dashboardServiceImpl{
+		dashboard: dashboard,
+	}
+}
+
+// GetDashboard retrieves the dashboard of a sender as of the given date: the invoice totals of every status, the
+// outstanding and overdue balances with the amounts due in the next 7 and 30 days, and the latest invoices and activities.
+func (s *dashboardServiceImpl) GetDashboard(ctx context.Context, senderID uuid.UUID, asOf time.Time) (*models.Dashboard, error) {
+	asOf = time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+
+	dashboard, err := s.dashboard.GetDashboard(ctx, senderID, asOf, dashboardRecentLimit)
+	if err != nil {
+		return nil, err
+	}
+	dashboard.Statuses = completeStatusTotals(dashboard.Statuses)
+	return dashboard, nil
+}
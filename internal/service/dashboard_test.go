@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetDashboard(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockDashboardRepository(ctrl)
+	service := newDashboardServiceImpl(repo)
+
+	t.Run("successful retrieval", func(t *testing.T) {
+		senderID := uuid.New()
+		now := time.Date(2024, 6, 1, 15, 30, 0, 0, time.UTC)
+		today := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+		pending := models.StatusTotals{
+			Status:     models.InvoiceStatusPending,
+			Count:      2,
+			Currencies: []models.CurrencyTotal{{Currency: "NGN", Count: 2, TotalAmount: 700}},
+		}
+		balances := []models.OpenBalance{{Currency: "NGN", InvoiceCount: 2, Outstanding: 650, Overdue: 150, DueNext7Days: 500, DueNext30Days: 500}}
+
+		repo.EXPECT().
+			GetDashboard(gomock.Any(), senderID, today, int32(dashboardRecentLimit)).
+			Return(&models.Dashboard{
+				SenderID: senderID,
+				AsOf:     today,
+				Statuses: []models.StatusTotals{pending},
+				Balances: balances,
+			}, nil)
+
+		dashboard, err := service.GetDashboard(ctx, senderID, now)
+		require.NoError(t, err)
+		require.Equal(t, today, dashboard.AsOf)
+		require.Len(t, dashboard.Statuses, 4)
+		require.Equal(t, models.InvoiceStatusDraft, dashboard.Statuses[0].Status)
+		require.Equal(t, pending, dashboard.Statuses[1])
+		require.Equal(t, 0, dashboard.Statuses[3].Count)
+		require.Equal(t, balances, dashboard.Balances)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		expectedErr := errors.New("database error")
+		repo.EXPECT().
+			GetDashboard(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, expectedErr)
+
+		dashboard, err := service.GetDashboard(ctx, uuid.New(), time.Now())
+		require.Equal(t, expectedErr, err)
+		require.Nil(t, dashboard)
+	})
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/repository"
+)
+
+const (
+	// maxMissedEvents is the largest number of missed events sent when a stream resumes. Clients that missed more
+	// resume again from the position of the last one sent.
+	maxMissedEvents = 500
+	// subscriptionBuffer is how many events may wait for a slow stream before it is ended, for its client to resume.
+	subscriptionBuffer = 64
+)
+
+type eventServiceImpl struct {
+	events repository.EventRepository
+
+	mu          sync.Mutex
+	listening   bool
+	closed      bool
+	subscribers map[uuid.UUID]map[chan models.ActivityEvent]struct{}
+}
+
+// newEventServiceImpl creates a new instance of the eventServiceImpl struct, which implements the EventService
+// interface. It takes an EventRepository implementation as a dependency.
+func newEventServiceImpl(events repository.EventRepository) *eventServiceImpl {
+	return &eventServiceImpl{
+		events:      events,
+		subscribers: make(map[uuid.UUID]map[chan models.ActivityEvent]struct{}),
+	}
+}
+
+// Listen follows the activity events recorded by every server and sends them to the subscriptions of their users. It
+// blocks until the context is done or the connection to the database fails. Events recorded while nobody listens
+// would be lost, so every subscription ends when it returns.
+func (s *eventServiceImpl) Listen(ctx context.Context) error {
+	defer s.endSubscriptions(false)
+
+	return s.events.ListenActivityEvents(ctx,
+		func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.listening = true
+		},
+		func(notification models.ActivityNotification) {
+			s.publish(ctx, notification)
+		},
+	)
+}
+
+// Close ends every subscription and refuses new ones, for the server to shut down.
+func (s *eventServiceImpl) Close() {
+	s.endSubscriptions(true)
+}
+
+// endSubscriptions ends every subscription and stops accepting new ones until the service listens again, or for good
+// when it is closed.
+func (s *eventServiceImpl) endSubscriptions(closed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.listening = false
+	s.closed = s.closed || closed
+	for userID, subscribers := range s.subscribers {
+		for events := range subscribers {
+			close(events)
+		}
+		delete(s.subscribers, userID)
+	}
+}
+
+// Subscribe subscribes to the activity events of a user. When lastEventID is positive, it is the position the client
+// resumes from, and the events recorded since are returned as missed, so a client that lost its connection resumes
+// where it left off.
+func (s *eventServiceImpl) Subscribe(ctx context.Context, userID uuid.UUID, lastEventID int64) (*models.EventSubscription, error) {
+	events := make(chan models.ActivityEvent, subscriptionBuffer)
+
+	s.mu.Lock()
+	if !s.listening || s.closed {
+		s.mu.Unlock()
+		return nil, models.ErrEventStreamUnavailable
+	}
+	if s.subscribers[userID] == nil {
+		s.subscribers[userID] = make(map[chan models.ActivityEvent]struct{})
+	}
+	s.subscribers[userID][events] = struct{}{}
+	s.mu.Unlock()
+
+	subscription := &models.EventSubscription{
+		Events: events,
+		Close:  func() { s.unsubscribe(userID, events) },
+	}
+	// the subscription starts before the missed events are read, so no event falls in between; the stream skips the
+	// events it receives twice
+	if lastEventID > 0 {
+		missed, err := s.events.GetActivityEventsSince(ctx, userID, lastEventID, maxMissedEvents+1)
+		if err != nil {
+			subscription.Close()
+			return nil, err
+		}
+		if len(missed) > maxMissedEvents {
+			missed, subscription.More = missed[:maxMissedEvents], true
+		}
+		subscription.Missed = missed
+	}
+	return subscription, nil
+}
+
+// unsubscribe ends a subscription of a user, unless it already ended.
+func (s *eventServiceImpl) unsubscribe(userID uuid.UUID, events chan models.ActivityEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(userID, events)
+}
+
+// remove ends a subscription of a user, unless it already ended. The caller holds the lock.
+func (s *eventServiceImpl) remove(userID uuid.UUID, events chan models.ActivityEvent) {
+	if _, ok := s.subscribers[userID][events]; !ok {
+		return
+	}
+	close(events)
+	delete(s.subscribers[userID], events)
+	if len(s.subscribers[userID]) == 0 {
+		delete(s.subscribers, userID)
+	}
+}
+
+// publish sends a new activity event to the subscriptions of its user, at the position it was notified with. The event
+// is only read when the user has a subscription on this server. Subscriptions that cannot take the event end, as do all
+// the subscriptions of the user when the event cannot be read, for their clients to resume.
+func (s *eventServiceImpl) publish(ctx context.Context, notification models.ActivityNotification) {
+	s.mu.Lock()
+	subscribed := len(s.subscribers[notification.UserID]) > 0
+	s.mu.Unlock()
+	if !subscribed {
+		return
+	}
+
+	event, err := s.events.GetActivityEvent(ctx, notification.Sequence)
+	if event != nil {
+		event.Position = notification.Position
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for events := range s.subscribers[notification.UserID] {
+		if err != nil {
+			s.remove(notification.UserID, events)
+			continue
+		}
+		if event == nil {
+			return
+		}
+		select {
+		case events <- *event:
+		default:
+			s.remove(notification.UserID, events)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"go.uber.org/mock/gomock"
+)
+
+// listen runs the event service in the background until the test ends, with notify delivering notifications as the
+// database would.
+func listen(t *testing.T, service *eventServiceImpl, repo *mocked.MockEventRepository) (notify func(models.ActivityNotification)) {
+	t.Helper()
+	notifications := make(chan models.ActivityNotification)
+	delivered := make(chan struct{})
+	ready := make(chan struct{})
+
+	repo.EXPECT().
+		ListenActivityEvents(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, listening func(), notify func(models.ActivityNotification)) error {
+			listening()
+			close(ready)
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case notification := <-notifications:
+					notify(notification)
+					delivered <- struct{}{}
+				}
+			}
+		})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- service.Listen(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+	<-ready
+
+	return func(notification models.ActivityNotification) {
+		notifications <- notification
+		<-delivered
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("not listening", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		service := newEventServiceImpl(mocked.NewMockEventRepository(ctrl))
+
+		subscription, err := service.Subscribe(ctx, uuid.New(), 0)
+		require.ErrorIs(t, err, models.ErrEventStreamUnavailable)
+		require.Nil(t, subscription)
+	})
+
+	t.Run("new events", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := mocked.NewMockEventRepository(ctrl)
+		service := newEventServiceImpl(repo)
+		notify := listen(t, service, repo)
+
+		userID := uuid.New()
+		subscription, err := service.Subscribe(ctx, userID, 0)
+		require.NoError(t, err)
+		defer subscription.Close()
+		require.Empty(t, subscription.Missed)
+
+		event := &models.ActivityEvent{Sequence: 7, InvoiceActivity: models.InvoiceActivity{UserID: userID, EventType: models.ActivityEventStatusChanged}}
+		repo.EXPECT().GetActivityEvent(gomock.Any(), int64(7)).Return(event, nil)
+
+		notify(models.ActivityNotification{UserID: userID, Sequence: 7, Position: 5})
+		// the events of other users are not read
+		notify(models.ActivityNotification{UserID: uuid.New(), Sequence: 8, Position: 5})
+
+		received := <-subscription.Events
+		require.Equal(t, int64(5), received.Position)
+		require.Equal(t, event.ActivityID, received.ActivityID)
+		require.Empty(t, subscription.Events)
+	})
+
+	t.Run("resume from the last position", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := mocked.NewMockEventRepository(ctrl)
+		service := newEventServiceImpl(repo)
+		listen(t, service, repo)
+
+		userID := uuid.New()
+		missed := []models.ActivityEvent{{Sequence: 4, Position: 4}, {Sequence: 5, Position: 4}}
+		repo.EXPECT().GetActivityEventsSince(gomock.Any(), userID, int64(3), int32(maxMissedEvents+1)).Return(missed, nil)
+
+		subscription, err := service.Subscribe(ctx, userID, 3)
+		require.NoError(t, err)
+		defer subscription.Close()
+		require.Equal(t, missed, subscription.Missed)
+		require.False(t, subscription.More)
+	})
+
+	t.Run("more missed events than sent", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := mocked.NewMockEventRepository(ctrl)
+		service := newEventServiceImpl(repo)
+		listen(t, service, repo)
+
+		repo.EXPECT().GetActivityEventsSince(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(make([]models.ActivityEvent, maxMissedEvents+1), nil)
+
+		subscription, err := service.Subscribe(ctx, uuid.New(), 1)
+		require.NoError(t, err)
+		defer subscription.Close()
+		require.Len(t, subscription.Missed, maxMissedEvents)
+		require.True(t, subscription.More)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := mocked.NewMockEventRepository(ctrl)
+		service := newEventServiceImpl(repo)
+		listen(t, service, repo)
+
+		expectedErr := errors.New("database error")
+		repo.EXPECT().GetActivityEventsSince(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, expectedErr)
+
+		subscription, err := service.Subscribe(ctx, uuid.New(), 1)
+		require.ErrorIs(t, err, expectedErr)
+		require.Nil(t, subscription)
+		require.Empty(t, service.subscribers)
+	})
+
+	t.Run("slow subscription ends", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := mocked.NewMockEventRepository(ctrl)
+		service := newEventServiceImpl(repo)
+		notify := listen(t, service, repo)
+
+		userID := uuid.New()
+		subscription, err := service.Subscribe(ctx, userID, 0)
+		require.NoError(t, err)
+		defer subscription.Close()
+
+		repo.EXPECT().GetActivityEvent(gomock.Any(), gomock.Any()).
+			Return(&models.ActivityEvent{}, nil).
+			Times(subscriptionBuffer + 1)
+		for sequence := int64(1); sequence <= subscriptionBuffer+1; sequence++ {
+			notify(models.ActivityNotification{UserID: userID, Sequence: sequence})
+		}
+
+		received := 0
+		for range subscription.Events {
+			received++
+		}
+		require.Equal(t, subscriptionBuffer, received)
+	})
+
+	t.Run("event cannot be read", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := mocked.NewMockEventRepository(ctrl)
+		service := newEventServiceImpl(repo)
+		notify := listen(t, service, repo)
+
+		userID := uuid.New()
+		subscription, err := service.Subscribe(ctx, userID, 0)
+		require.NoError(t, err)
+		defer subscription.Close()
+
+		repo.EXPECT().GetActivityEvent(gomock.Any(), int64(1)).Return(nil, errors.New("database error"))
+		notify(models.ActivityNotification{UserID: userID, Sequence: 1})
+
+		_, ok := <-subscription.Events
+		require.False(t, ok)
+	})
+}
+
+func TestEventServiceListen(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("subscriptions end when the connection fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := mocked.NewMockEventRepository(ctrl)
+		service := newEventServiceImpl(repo)
+
+		expectedErr := errors.New("connection lost")
+		subscribed := make(chan struct{})
+		fail := make(chan struct{})
+		repo.EXPECT().
+			ListenActivityEvents(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, listening func(), notify func(models.ActivityNotification)) error {
+				listening()
+				close(subscribed)
+				<-fail
+				return expectedErr
+			})
+
+		done := make(chan error)
+		go func() { done <- service.Listen(ctx) }()
+		<-subscribed
+
+		subscription, err := service.Subscribe(ctx, uuid.New(), 0)
+		require.NoError(t, err)
+		close(fail)
+		require.ErrorIs(t, <-done, expectedErr)
+
+		select {
+		case _, ok := <-subscription.Events:
+			require.False(t, ok)
+		case <-time.After(time.Second):
+			t.Fatal("subscription did not end")
+		}
+		_, err = service.Subscribe(ctx, uuid.New(), 0)
+		require.ErrorIs(t, err, models.ErrEventStreamUnavailable)
+		subscription.Close()
+	})
+
+	t.Run("close refuses new subscriptions", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := mocked.NewMockEventRepository(ctrl)
+		service := newEventServiceImpl(repo)
+		listen(t, service, repo)
+
+		subscription, err := service.Subscribe(ctx, uuid.New(), 0)
+		require.NoError(t, err)
+
+		service.Close()
+		_, ok := <-subscription.Events
+		require.False(t, ok)
+		subscription.Close()
+
+		_, err = service.Subscribe(ctx, uuid.New(), 0)
+		require.ErrorIs(t, err, models.ErrEventStreamUnavailable)
+	})
+}
@@ -0,0 +1,387 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zde37/Numeris-Task/internal/helpers"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/repository"
+)
+
+const (
+	// importBatchSize is the number of customers or invoices stored per transaction by an import.
+	importBatchSize = 100
+	// maxImportRows is the largest number of rows an imported file may have.
+	maxImportRows = 10000
+)
+
+// ErrInvalidImportFile is returned when an imported file cannot be read as a whole, as opposed to rows of it
+// being invalid, which are reported in the import result.
+var ErrInvalidImportFile = errors.New("invalid import file")
+
+type importServiceImpl struct {
+	user    repository.UserRepository
+	invoice repository.InvoiceRepository
+	// invoices validates and prepares imported invoices like the invoices created one at a time.
+	invoices *invoiceServiceImpl
+}
+
+// newImportServiceImpl creates a new instance of the importServiceImpl struct, which implements the ImportService
+// interface. It takes the UserRepository customers are stored with, the InvoiceRepository invoices are stored with
+// and the invoice service whose validation imported invoices go through.
+func newImportServiceImpl(user repository.UserRepository, invoice repository.InvoiceRepository, invoices *invoiceServiceImpl) *importServiceImpl {
+	return &importServiceImpl{
+		user:     user,
+		invoice:  invoice,
+		invoices: invoices,
+	}
+}
+
+// ImportCustomers imports customers of a user from a CSV file with name and email columns, and optional
+// phone_number and address columns. Every row is validated, and the valid rows are stored in batches, each in a
+// transaction, unless dryRun is set.
+func (s *importServiceImpl) ImportCustomers(ctx context.Context, userID uuid.UUID, file io.Reader, dryRun bool) (*models.ImportResult, error) {
+	rows, err := readImportRows(file, []string{"name", "email"})
+	if err != nil {
+		return nil, err
+	}
+	result := &models.ImportResult{DryRun: dryRun, Rows: len(rows), Errors: []models.ImportError{}}
+
+	customers := make([]models.Customer, 0, len(rows))
+	lines := make([]int, 0, len(rows))
+	for _, row := range rows {
+		customer, err := importCustomer(userID, row)
+		if err != nil {
+			result.Errors = append(result.Errors, models.ImportError{Line: row.line, Error: err.Error()})
+			continue
+		}
+		customers = append(customers, customer)
+		lines = append(lines, row.line)
+	}
+	result.Valid = len(customers)
+
+	if !dryRun {
+		importInBatches(result, customers, lines, func(batch []models.Customer) ([]error, error) {
+			return nil, s.user.AddCustomers(ctx, batch)
+		})
+	}
+	sortImportErrors(result)
+	return result, nil
+}
+
+// importCustomer validates a row of a customer import and returns the customer of the user it describes.
+func importCustomer(userID uuid.UUID, row importRow) (models.Customer, error) {
+	customer := models.Customer{
+		CustomerID:  uuid.New(),
+		UserID:      userID,
+		Name:        row.fields["name"],
+		Email:       row.fields["email"],
+		PhoneNumber: row.fields["phone_number"],
+		Address:     row.fields["address"],
+	}
+	if customer.Name == "" {
+		return customer, fmt.Errorf("name is required")
+	}
+	if len(customer.Name) > 100 {
+		return customer, fmt.Errorf("name must be at most 100 characters")
+	}
+	if _, err := mail.ParseAddress(customer.Email); err != nil {
+		return customer, fmt.Errorf("invalid email: %s", customer.Email)
+	}
+	if len(customer.Email) > 100 {
+		return customer, fmt.Errorf("email must be at most 100 characters")
+	}
+	if len(customer.PhoneNumber) > 20 {
+		return customer, fmt.Errorf("phone_number must be at most 20 characters")
+	}
+	return customer, nil
+}
+
+// ImportInvoices imports the invoices of a sender from a CSV file with one row per invoice item. Rows with the same
+// reference belong to the same invoice, whose customer_id, payment_method_id, issue_date, due_date, status,
+// currency, discount_percentage and notes are taken from its first row; invoices without a payment_method_id are
+// sent with the sender's default payment method. Each row has the item_name, item_description, quantity,
+// unit_price and tax_rate_ids, separated by semicolons, of an item. Invoices are
+// validated and prepared like the invoices created one at a time, including that their customer and payment method
+// belong to the sender, and the valid invoices are stored in batches, each in a transaction, unless dryRun is set.
+func (s *importServiceImpl) ImportInvoices(ctx context.Context, senderID uuid.UUID, file io.Reader, dryRun bool) (*models.ImportResult, error) {
+	rows, err := readImportRows(file, []string{
+		"reference", "customer_id", "issue_date", "due_date", "currency",
+		"item_name", "quantity", "unit_price",
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := &models.ImportResult{DryRun: dryRun, Rows: len(rows), Errors: []models.ImportError{}}
+
+	invoices := make([]models.NewInvoice, 0)
+	lines := make([]int, 0)
+	for _, group := range groupInvoiceRows(rows) {
+		request, errs := importInvoiceRequest(senderID, group)
+		if len(errs) > 0 {
+			result.Errors = append(result.Errors, errs...)
+			continue
+		}
+		invoice, err := s.invoices.prepareInvoice(ctx, request)
+		if err != nil {
+			result.Errors = append(result.Errors, models.ImportError{Line: group[0].line, Error: err.Error()})
+			continue
+		}
+		invoices = append(invoices, *invoice)
+		lines = append(lines, group[0].line)
+	}
+	invoices, lines, err = s.checkInvoiceOwnership(ctx, senderID, result, invoices, lines)
+	if err != nil {
+		return nil, err
+	}
+	result.Valid = len(invoices)
+
+	if !dryRun {
+		importInBatches(result, invoices, lines, func(batch []models.NewInvoice) ([]error, error) {
+			return s.invoice.CreateInvoices(ctx, batch)
+		})
+	}
+	sortImportErrors(result)
+	return result, nil
+}
+
+// checkInvoiceOwnership reports the imported invoices whose customer or payment method does not belong to the
+// sender on their lines, and returns the others with their lines, so that a dry run reports them too.
+func (s *importServiceImpl) checkInvoiceOwnership(ctx context.Context, senderID uuid.UUID, result *models.ImportResult, invoices []models.NewInvoice, lines []int) ([]models.NewInvoice, []int, error) {
+	if len(invoices) == 0 {
+		return invoices, lines, nil
+	}
+	customerIDs := make([]uuid.UUID, 0, len(invoices))
+	for _, invoice := range invoices {
+		if !slices.Contains(customerIDs, invoice.Invoice.CustomerID) {
+			customerIDs = append(customerIDs, invoice.Invoice.CustomerID)
+		}
+	}
+	customers, err := s.user.GetOwnedCustomerIDs(ctx, senderID, customerIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	paymentMethods, err := s.user.GetPaymentMethods(ctx, senderID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	owned := make([]models.NewInvoice, 0, len(invoices))
+	ownedLines := make([]int, 0, len(lines))
+	for i, invoice := range invoices {
+		var err error
+		switch {
+		case !slices.Contains(customers, invoice.Invoice.CustomerID):
+			err = models.ErrCustomerNotOwned
+		case !slices.ContainsFunc(paymentMethods, func(method models.UserPaymentMethod) bool {
+			return method.PaymentMethodID == invoice.PaymentInfo.PaymentMethodID
+		}):
+			err = models.ErrPaymentMethodNotOwned
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, models.ImportError{Line: lines[i], Error: err.Error()})
+			continue
+		}
+		owned = append(owned, invoice)
+		ownedLines = append(ownedLines, lines[i])
+	}
+	return owned, ownedLines, nil
+}
+
+// groupInvoiceRows groups the rows of an invoice import by reference, in the order the references first appear in.
+// A row without a reference is a group of its own, which fails validation.
+func groupInvoiceRows(rows []importRow) [][]importRow {
+	groups := make([][]importRow, 0)
+	index := make(map[string]int)
+	for _, row := range rows {
+		reference := row.fields["reference"]
+		i, ok := index[reference]
+		if !ok || reference == "" {
+			i = len(groups)
+			index[reference] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], row)
+	}
+	return groups
+}
+
+// importInvoiceRequest builds the request creating an invoice from the rows of an invoice import holding it.
+// Amounts are calculated from the items and the discount. It returns the errors of every invalid row.
+func importInvoiceRequest(senderID uuid.UUID, rows []importRow) (models.CreateInvoiceRequest, []models.ImportError) {
+	first := rows[0].fields
+	request := models.CreateInvoiceRequest{
+		Invoice: models.InvoiceInfo{
+			SenderID:  senderID.String(),
+			IssueDate: first["issue_date"],
+			DueDate:   first["due_date"],
+			Status:    first["status"],
+			Currency:  strings.ToUpper(first["currency"]),
+			Notes:     first["notes"],
+		},
+		CustomerID:      first["customer_id"],
+		PaymentMethodID: first["payment_method_id"],
+	}
+	if request.Invoice.Status == "" {
+		request.Invoice.Status = string(models.InvoiceStatusPending)
+	}
+
+	errs := make([]models.ImportError, 0)
+	fail := func(line int, format string, args ...any) {
+		errs = append(errs, models.ImportError{Line: line, Error: fmt.Sprintf(format, args...)})
+	}
+
+	if first["reference"] == "" {
+		fail(rows[0].line, "reference is required")
+	}
+	issueDate, issueErr := time.Parse("2006-01-02", request.Invoice.IssueDate)
+	if issueErr != nil {
+		fail(rows[0].line, "issue_date has invalid date format")
+	}
+	dueDate, dueErr := time.Parse("2006-01-02", request.Invoice.DueDate)
+	if dueErr != nil {
+		fail(rows[0].line, "due_date has invalid date format")
+	}
+	if issueErr == nil && dueErr == nil && dueDate.Before(issueDate) {
+		fail(rows[0].line, "due_date must not be before issue_date")
+	}
+	if value := first["discount_percentage"]; value != "" {
+		discount, err := strconv.ParseFloat(value, 64)
+		if err != nil || discount < 0 || discount > 100 {
+			fail(rows[0].line, "discount_percentage must be a number between 0 and 100")
+		}
+		request.Invoice.DiscountPercentage = discount
+	}
+
+	total := 0.0
+	for _, row := range rows {
+		item := models.InvoiceItemDetails{
+			Name:        row.fields["item_name"],
+			Description: row.fields["item_description"],
+		}
+		if item.Name == "" {
+			fail(row.line, "item_name is required")
+		}
+		quantity, err := strconv.Atoi(row.fields["quantity"])
+		if err != nil || quantity < 1 {
+			fail(row.line, "quantity must be a positive whole number")
+		}
+		unitPrice, err := strconv.ParseFloat(row.fields["unit_price"], 64)
+		if err != nil || unitPrice < 0 {
+			fail(row.line, "unit_price must be a non-negative number")
+		}
+		if taxRates := row.fields["tax_rate_ids"]; taxRates != "" {
+			for _, id := range strings.Split(taxRates, ";") {
+				item.TaxRateIDs = append(item.TaxRateIDs, strings.TrimSpace(id))
+			}
+		}
+
+		item.Quantity = quantity
+		item.UnitPrice = unitPrice
+		item.TotalPrice = helpers.RoundAmount(float64(quantity) * unitPrice)
+		total += item.TotalPrice
+		request.InvoiceItems = append(request.InvoiceItems, item)
+	}
+
+	request.Invoice.TotalAmount = helpers.RoundAmount(total)
+	request.Invoice.DiscountedAmount = helpers.RoundAmount(total * request.Invoice.DiscountPercentage / 100)
+	request.Invoice.FinalAmount = helpers.RoundAmount(request.Invoice.TotalAmount - request.Invoice.DiscountedAmount)
+	return request, errs
+}
+
+// importInBatches stores the valid records of an import in batches with store, counting the records stored. store
+// may reject single records, returning their errors at their index in the batch, while storing the others. When a
+// batch fails, none of its records are stored and the error is reported on the line of each of them.
+func importInBatches[T any](result *models.ImportResult, records []T, lines []int, store func([]T) ([]error, error)) {
+	for start := 0; start < len(records); start += importBatchSize {
+		end := min(start+importBatchSize, len(records))
+		rejected, err := store(records[start:end])
+		if err != nil {
+			for _, line := range lines[start:end] {
+				result.Errors = append(result.Errors, models.ImportError{Line: line, Error: err.Error()})
+			}
+			continue
+		}
+		for i, line := range lines[start:end] {
+			if i < len(rejected) && rejected[i] != nil {
+				result.Errors = append(result.Errors, models.ImportError{Line: line, Error: rejected[i].Error()})
+				continue
+			}
+			result.Imported++
+		}
+	}
+}
+
+// sortImportErrors orders the errors of an import result by line.
+func sortImportErrors(result *models.ImportResult) {
+	slices.SortStableFunc(result.Errors, func(a, b models.ImportError) int {
+		return a.Line - b.Line
+	})
+}
+
+// importRow is a row of an imported file: the line it is on and its fields by column name.
+type importRow struct {
+	line   int
+	fields map[string]string
+}
+
+// readImportRows reads the rows of an imported CSV file, whose first row names the columns. Column names are case
+// insensitive, fields are trimmed and missing trailing fields are empty. It returns an error wrapping
+// ErrInvalidImportFile when the file is not valid CSV, is missing a required column or has too many rows.
+func readImportRows(file io.Reader, required []string) ([]importRow, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: the file is empty", ErrInvalidImportFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// spreadsheet applications may start the file with a byte order mark
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	for _, name := range required {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing column %s", ErrInvalidImportFile, name)
+		}
+	}
+
+	rows := make([]importRow, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+		}
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("%w: more than %d rows", ErrInvalidImportFile, maxImportRows)
+		}
+
+		line, _ := reader.FieldPos(0)
+		fields := make(map[string]string, len(columns))
+		for name, i := range columns {
+			if i < len(record) {
+				fields[name] = strings.TrimSpace(record[i])
+			}
+		}
+		rows = append(rows, importRow{line: line, fields: fields})
+	}
+	return rows, nil
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"go.uber.org/mock/gomock"
+)
+
+func TestImportCustomers(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := mocked.NewMockUserRepository(ctrl)
+	service := newImportServiceImpl(userRepo, nil, nil)
+	userID := uuid.New()
+
+	file := "Name,Email,Phone_Number,Address\n" +
+		"Acme,billing@acme.com,+2348000000000,1 Main St\n" +
+		",missing@name.com\n" +
+		"Globex,not-an-email\n" +
+		"Initech,ap@initech.com\n"
+
+	t.Run("successful import", func(t *testing.T) {
+		userRepo.EXPECT().
+			AddCustomers(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ context.Context, customers []models.Customer) error {
+				require.Len(t, customers, 2)
+				require.Equal(t, "Acme", customers[0].Name)
+				require.Equal(t, "+2348000000000", customers[0].PhoneNumber)
+				require.Equal(t, "ap@initech.com", customers[1].Email)
+				require.NotEqual(t, uuid.Nil, customers[1].CustomerID)
+				require.Equal(t, userID, customers[0].UserID)
+				return nil
+			})
+
+		result, err := service.ImportCustomers(ctx, userID, strings.NewReader(file), false)
+		require.NoError(t, err)
+		require.Equal(t, &models.ImportResult{
+			Rows:     4,
+			Valid:    2,
+			Imported: 2,
+			Errors: []models.ImportError{
+				{Line: 3, Error: "name is required"},
+				{Line: 4, Error: "invalid email: not-an-email"},
+			},
+		}, result)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		result, err := service.ImportCustomers(ctx, userID, strings.NewReader(file), true)
+		require.NoError(t, err)
+		require.True(t, result.DryRun)
+		require.Equal(t, 2, result.Valid)
+		require.Zero(t, result.Imported)
+		require.Len(t, result.Errors, 2)
+	})
+
+	t.Run("failed batch", func(t *testing.T) {
+		var rows strings.Builder
+		rows.WriteString("name,email\n")
+		for i := 0; i < importBatchSize+1; i++ {
+			fmt.Fprintf(&rows, "Customer %d,customer%d@example.com\n", i, i)
+		}
+
+		gomock.InOrder(
+			userRepo.EXPECT().AddCustomers(gomock.Any(), gomock.Len(importBatchSize)).Return(nil),
+			userRepo.EXPECT().AddCustomers(gomock.Any(), gomock.Len(1)).Return(errors.New("duplicate key")),
+		)
+
+		result, err := service.ImportCustomers(ctx, userID, strings.NewReader(rows.String()), false)
+		require.NoError(t, err)
+		require.Equal(t, importBatchSize+1, result.Valid)
+		require.Equal(t, importBatchSize, result.Imported)
+		require.Equal(t, []models.ImportError{{Line: importBatchSize + 2, Error: "duplicate key"}}, result.Errors)
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		for name, file := range map[string]string{
+			"empty file":     "",
+			"missing column": "name,phone_number\nAcme,+2348000000000\n",
+			"invalid csv":    "name,email\n\"Acme,billing@acme.com\n",
+		} {
+			t.Run(name, func(t *testing.T) {
+				result, err := service.ImportCustomers(ctx, userID, strings.NewReader(file), false)
+				require.ErrorIs(t, err, ErrInvalidImportFile)
+				require.Nil(t, result)
+			})
+		}
+	})
+}
+
+func TestImportInvoices(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	invoiceRepo := mocked.NewMockInvoiceRepository(ctrl)
+	userRepo := mocked.NewMockUserRepository(ctrl)
+	taxRepo := mocked.NewMockTaxRepository(ctrl)
+	currencyRepo := mocked.NewMockCurrencyRepository(ctrl)
+	service := newImportServiceImpl(userRepo, invoiceRepo, newInvoiceServiceImpl(invoiceRepo, taxRepo, currencyRepo, userRepo))
+
+	senderID := uuid.New()
+	customerID, paymentMethodID := uuid.New(), uuid.New()
+	header := "reference,customer_id,payment_method_id,issue_date,due_date,status,currency,discount_percentage,notes,item_name,item_description,quantity,unit_price,tax_rate_ids\n"
+	row := func(reference, issueDate, dueDate, item, quantity, unitPrice string) string {
+		return fmt.Sprintf("%s,%s,%s,%s,%s,pending,ngn,10,Imported,%s,Description,%s,%s,\n",
+			reference, customerID, paymentMethodID, issueDate, dueDate, item, quantity, unitPrice)
+	}
+	file := header +
+		row("A-1", "2024-06-01", "2024-06-30", "Design", "2", "100") +
+		row("B-1", "2024-06-01", "2024-05-01", "Hosting", "1", "50") +
+		row("A-1", "", "", "Support", "1", "50.5") +
+		row("C-1", "2024-06-01", "2024-06-30", "Audit", "zero", "100")
+	expectOwnership := func(customers []uuid.UUID, paymentMethods ...uuid.UUID) {
+		userRepo.EXPECT().GetOwnedCustomerIDs(gomock.Any(), senderID, gomock.Any()).Return(customers, nil)
+		methods := []models.UserPaymentMethod{}
+		for _, id := range paymentMethods {
+			methods = append(methods, models.UserPaymentMethod{PaymentMethodID: id, UserID: senderID})
+		}
+		userRepo.EXPECT().GetPaymentMethods(gomock.Any(), senderID).Return(methods, nil)
+	}
+
+	t.Run("successful import", func(t *testing.T) {
+		currencyRepo.EXPECT().GetBaseCurrency(gomock.Any(), senderID).Return("NGN", nil)
+		expectOwnership([]uuid.UUID{customerID}, paymentMethodID)
+		invoiceRepo.EXPECT().
+			CreateInvoices(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ context.Context, invoices []models.NewInvoice) ([]error, error) {
+				require.Len(t, invoices, 1)
+				invoice := invoices[0]
+				require.Equal(t, senderID, invoice.Invoice.SenderID)
+				require.Equal(t, customerID, invoice.Invoice.CustomerID)
+				require.Equal(t, "NGN", invoice.Invoice.Currency)
+				require.Equal(t, "Imported", invoice.Invoice.Notes)
+				require.Equal(t, 250.5, invoice.Invoice.TotalAmount)
+				require.Equal(t, 25.05, invoice.Invoice.DiscountedAmount)
+				require.Equal(t, 225.45, invoice.Invoice.FinalAmount)
+				require.Len(t, invoice.Items, 2)
+				require.Equal(t, 200.0, invoice.Items[0].TotalPrice)
+				require.Equal(t, "Support", invoice.Items[1].Name)
+				require.Equal(t, paymentMethodID, invoice.PaymentInfo.PaymentMethodID)
+				return make([]error, len(invoices)), nil
+			})
+
+		result, err := service.ImportInvoices(ctx, senderID, strings.NewReader(file), false)
+		require.NoError(t, err)
+		require.Equal(t, &models.ImportResult{
+			Rows:     4,
+			Valid:    1,
+			Imported: 1,
+			Errors: []models.ImportError{
+				{Line: 3, Error: "due_date must not be before issue_date"},
+				{Line: 5, Error: "quantity must be a positive whole number"},
+			},
+		}, result)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		currencyRepo.EXPECT().GetBaseCurrency(gomock.Any(), senderID).Return("NGN", nil)
+		expectOwnership([]uuid.UUID{customerID}, paymentMethodID)
+
+		result, err := service.ImportInvoices(ctx, senderID, strings.NewReader(file), true)
+		require.NoError(t, err)
+		require.True(t, result.DryRun)
+		require.Equal(t, 1, result.Valid)
+		require.Zero(t, result.Imported)
+	})
+
+	t.Run("dry run reports customers and payment methods of other users", func(t *testing.T) {
+		foreignCustomerID := uuid.New()
+		foreign := header +
+			row("A-1", "2024-06-01", "2024-06-30", "Design", "1", "100") +
+			fmt.Sprintf("B-1,%s,%s,2024-06-01,2024-06-30,pending,NGN,,,Design,,1,100,\n", foreignCustomerID, paymentMethodID) +
+			fmt.Sprintf("C-1,%s,%s,2024-06-01,2024-06-30,pending,NGN,,,Design,,1,100,\n", customerID, uuid.New())
+
+		currencyRepo.EXPECT().GetBaseCurrency(gomock.Any(), senderID).Return("NGN", nil).Times(3)
+		userRepo.EXPECT().
+			GetOwnedCustomerIDs(gomock.Any(), senderID, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uuid.UUID, customers []uuid.UUID) ([]uuid.UUID, error) {
+				require.ElementsMatch(t, []uuid.UUID{customerID, foreignCustomerID}, customers)
+				return []uuid.UUID{customerID}, nil
+			})
+		userRepo.EXPECT().
+			GetPaymentMethods(gomock.Any(), senderID).
+			Return([]models.UserPaymentMethod{{PaymentMethodID: paymentMethodID, UserID: senderID}}, nil)
+
+		result, err := service.ImportInvoices(ctx, senderID, strings.NewReader(foreign), true)
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Valid)
+		require.Equal(t, []models.ImportError{
+			{Line: 3, Error: models.ErrCustomerNotOwned.Error()},
+			{Line: 4, Error: models.ErrPaymentMethodNotOwned.Error()},
+		}, result.Errors)
+	})
+
+	t.Run("invoice rejected while storing", func(t *testing.T) {
+		batch := header +
+			row("A-1", "2024-06-01", "2024-06-30", "Design", "1", "100") +
+			row("B-1", "2024-06-01", "2024-06-30", "Hosting", "1", "50")
+
+		currencyRepo.EXPECT().GetBaseCurrency(gomock.Any(), senderID).Return("NGN", nil).Times(2)
+		expectOwnership([]uuid.UUID{customerID}, paymentMethodID)
+		invoiceRepo.EXPECT().
+			CreateInvoices(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, invoices []models.NewInvoice) ([]error, error) {
+				require.Len(t, invoices, 2)
+				return []error{models.ErrCustomerNotOwned, nil}, nil
+			})
+
+		result, err := service.ImportInvoices(ctx, senderID, strings.NewReader(batch), false)
+		require.NoError(t, err)
+		require.Equal(t, 2, result.Valid)
+		require.Equal(t, 1, result.Imported)
+		require.Equal(t, []models.ImportError{{Line: 2, Error: models.ErrCustomerNotOwned.Error()}}, result.Errors)
+	})
+
+	t.Run("invoice rejected by validation", func(t *testing.T) {
+		invalid := header + fmt.Sprintf("A-1,not-a-uuid,%s,2024-06-01,2024-06-30,pending,NGN,,,Design,,1,100,\n", paymentMethodID)
+
+		result, err := service.ImportInvoices(ctx, senderID, strings.NewReader(invalid), false)
+		require.NoError(t, err)
+		require.Zero(t, result.Valid)
+		require.Equal(t, []models.ImportError{{Line: 2, Error: "invalid customer id"}}, result.Errors)
+	})
+
+	t.Run("default payment method", func(t *testing.T) {
+		defaultMethodID := uuid.New()
+		withoutMethod := "reference,customer_id,issue_date,due_date,currency,item_name,quantity,unit_price\n" +
+			fmt.Sprintf("A-1,%s,2024-06-01,2024-06-30,NGN,Design,1,100\n", customerID)
+
+		userRepo.EXPECT().
+			GetDefaultPaymentMethod(gomock.Any(), senderID).
+			Return(&models.UserPaymentMethod{PaymentMethodID: defaultMethodID, UserID: senderID, IsDefault: true}, nil)
+		currencyRepo.EXPECT().GetBaseCurrency(gomock.Any(), senderID).Return("NGN", nil)
+		expectOwnership([]uuid.UUID{customerID}, defaultMethodID)
+		invoiceRepo.EXPECT().
+			CreateInvoices(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, invoices []models.NewInvoice) ([]error, error) {
+				require.Len(t, invoices, 1)
+				require.Equal(t, defaultMethodID, invoices[0].PaymentInfo.PaymentMethodID)
+				return make([]error, len(invoices)), nil
+			})
+
+		result, err := service.ImportInvoices(ctx, senderID, strings.NewReader(withoutMethod), false)
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Imported)
+	})
+
+	t.Run("missing column", func(t *testing.T) {
+		result, err := service.ImportInvoices(ctx, senderID, strings.NewReader("reference,customer_id\nA-1,"+customerID.String()+"\n"), false)
+		require.ErrorIs(t, err, ErrInvalidImportFile)
+		require.ErrorContains(t, err, "missing column issue_date")
+		require.Nil(t, result)
+	})
+}
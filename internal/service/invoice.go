@@ -2,52 +2,81 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/zde37/Numeris-Task/internal/helpers"
 	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/pagination"
 	"github.com/zde37/Numeris-Task/internal/repository"
 )
 
+// overdueBatchSize is the number of invoices marked as overdue at a time.
+const overdueBatchSize = 100
+
 type invoiceServiceImpl struct {
-	invoice repository.InvoiceRepository
+	invoice  repository.InvoiceRepository
+	tax      repository.TaxRepository
+	currency repository.CurrencyRepository
+	user     repository.UserRepository
 }
 
 // newInvoiceServiceImpl creates a new instance of the invoiceServiceImpl struct, which implements the InvoiceService interface.
 // The invoiceServiceImpl struct is responsible for handling invoice-related operations, and it takes an InvoiceRepository
-// implementation as a dependency.
-func newInvoiceServiceImpl(invoice repository.InvoiceRepository) *invoiceServiceImpl {
+// implementation, the TaxRepository used to look up the tax rates of invoice items, the CurrencyRepository used to
+// lock exchange rates and the UserRepository used to look up the sender's default payment method as dependencies.
+func newInvoiceServiceImpl(invoice repository.InvoiceRepository, tax repository.TaxRepository, currency repository.CurrencyRepository, user repository.UserRepository) *invoiceServiceImpl {
 	return &invoiceServiceImpl{
-		invoice: invoice,
+		invoice:  invoice,
+		tax:      tax,
+		currency: currency,
+		user:     user,
 	}
 }
 
-// CreateInvoice creates a new invoice with the provided data. 
+// CreateInvoice creates a new invoice with the provided data. When any item is taxed, the invoice amounts are
+// calculated from the items, the discount and the taxes instead of being taken from the request. The exchange rate
+// into the sender's base currency on the issue date is locked on issued invoices, so later rate changes do not alter it.
+// Invoices created without a payment method are sent with the sender's default payment method.
 func (s *invoiceServiceImpl) CreateInvoice(ctx context.Context, data models.CreateInvoiceRequest) (uuid.UUID, error) {
+	invoice, err := s.prepareInvoice(ctx, data)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return s.invoice.CreateInvoice(ctx, invoice.Invoice, invoice.Items, invoice.TaxLines, invoice.Invoice.CustomerID, invoice.PaymentInfo)
+}
+
+// prepareInvoice validates the data of a new invoice and builds the invoice to store from it, with its items, taxes,
+// payment information and locked exchange rate.
+func (s *invoiceServiceImpl) prepareInvoice(ctx context.Context, data models.CreateInvoiceRequest) (*models.NewInvoice, error) {
 	invoiceID := uuid.New()
 	senderID, err := uuid.Parse(data.Invoice.SenderID)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("invalid sender id")
+		return nil, fmt.Errorf("invalid sender id")
 	}
 	customerID, err := uuid.Parse(data.CustomerID)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("invalid customer id")
+		return nil, fmt.Errorf("invalid customer id")
 	}
 
 	if err := helpers.ValidateInvoiceStatus(data.Invoice.Status); err != nil {
-		return uuid.Nil, err
+		return nil, err
+	}
+	if err := helpers.ValidateCurrency(data.Invoice.Currency); err != nil {
+		return nil, err
 	}
 
 	layout := "2006-01-02"
 	issueDate, err := time.Parse(layout, data.Invoice.IssueDate)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("issue date has invalid date format")
+		return nil, fmt.Errorf("issue date has invalid date format")
 	}
 	dueDate, err := time.Parse(layout, data.Invoice.DueDate)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("due date has invalid date format")
+		return nil, fmt.Errorf("due date has invalid date format")
 	}
 	invoice := models.Invoice{
 		InvoiceID:          invoiceID,
@@ -80,10 +109,15 @@ func (s *invoiceServiceImpl) CreateInvoice(ctx context.Context, data models.Crea
 		items = append(items, item)
 	}
 
+	taxLines, err := s.applyTaxes(ctx, &invoice, items, data.InvoiceItems)
+	if err != nil {
+		return nil, err
+	}
+
 	paymentInfoID := uuid.New()
-	paymentMethodID, err := uuid.Parse(data.PaymentMethodID)
+	paymentMethodID, err := s.paymentMethodID(ctx, senderID, data.PaymentMethodID)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("invalid payment method id")
+		return nil, err
 	}
 	paymentInfo := models.PaymentInformation{
 		PaymentInfoID:   paymentInfoID,
@@ -91,15 +125,125 @@ func (s *invoiceServiceImpl) CreateInvoice(ctx context.Context, data models.Crea
 		PaymentMethodID: paymentMethodID,
 	}
 
-	return s.invoice.CreateInvoice(ctx, invoice, items, customerID, paymentInfo)
+	if err := s.lockExchangeRate(ctx, &invoice); err != nil {
+		return nil, err
+	}
+
+	return &models.NewInvoice{Invoice: invoice, Items: items, TaxLines: taxLines, PaymentInfo: paymentInfo}, nil
 }
 
-// GetInvoiceDetails retrieves the details of an invoice by the given invoice ID. 
+// paymentMethodID returns the ID of the payment method a new invoice is sent with: the requested payment method,
+// or the sender's default payment method when none is requested.
+func (s *invoiceServiceImpl) paymentMethodID(ctx context.Context, senderID uuid.UUID, requested string) (uuid.UUID, error) {
+	if requested != "" {
+		paymentMethodID, err := uuid.Parse(requested)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("invalid payment method id")
+		}
+		return paymentMethodID, nil
+	}
+
+	paymentMethod, err := s.user.GetDefaultPaymentMethod(ctx, senderID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if paymentMethod == nil {
+		return uuid.Nil, fmt.Errorf("payment method id is required when the sender has no default payment method")
+	}
+	return paymentMethod.PaymentMethodID, nil
+}
+
+// applyTaxes charges the tax rates requested for each item, using the sender's active tax rates, and updates
+// the invoice amounts to include them. It returns the tax lines of the invoice, or nil when no item is taxed.
+func (s *invoiceServiceImpl) applyTaxes(ctx context.Context, invoice *models.Invoice, items []models.InvoiceItem, requested []models.InvoiceItemDetails) ([]models.InvoiceTaxLine, error) {
+	taxed := false
+	for _, item := range requested {
+		taxed = taxed || len(item.TaxRateIDs) > 0
+	}
+	if !taxed {
+		return nil, nil
+	}
+
+	available, err := s.tax.GetTaxRates(ctx, invoice.SenderID)
+	if err != nil {
+		return nil, err
+	}
+	taxRates := make(map[uuid.UUID]models.TaxRate, len(available))
+	for _, rate := range available {
+		taxRates[rate.TaxRateID] = rate
+	}
+
+	subtotal, exclusiveTax := 0.0, 0.0
+	for idx := range items {
+		rates := make([]models.TaxRate, 0, len(requested[idx].TaxRateIDs))
+		seen := make(map[uuid.UUID]bool)
+		for _, id := range requested[idx].TaxRateIDs {
+			taxRateID, err := uuid.Parse(id)
+			if err != nil {
+				return nil, fmt.Errorf("invalid tax rate id")
+			}
+			rate, ok := taxRates[taxRateID]
+			if !ok {
+				return nil, fmt.Errorf("tax rate %s not found", id)
+			}
+			if seen[taxRateID] {
+				return nil, fmt.Errorf("tax rate %s applied twice to item %s", id, items[idx].Name)
+			}
+			seen[taxRateID] = true
+			rates = append(rates, rate)
+		}
+
+		items[idx].Taxes = calculateItemTaxes(items[idx].TotalPrice*(1-invoice.DiscountPercentage/100), rates)
+		for _, tax := range items[idx].Taxes {
+			if !tax.IsInclusive {
+				exclusiveTax += tax.TaxAmount
+			}
+		}
+		subtotal += items[idx].TotalPrice
+	}
+
+	invoice.TotalAmount = helpers.RoundAmount(subtotal)
+	invoice.DiscountedAmount = helpers.RoundAmount(subtotal * invoice.DiscountPercentage / 100)
+	invoice.FinalAmount = helpers.RoundAmount(invoice.TotalAmount - invoice.DiscountedAmount + exclusiveTax)
+
+	return summarizeTaxes(invoice.InvoiceID, items), nil
+}
+
+// lockExchangeRate sets the base currency of the invoice to the sender's and, once the invoice is issued, locks the
+// exchange rate into it that applies on the issue date. Drafts and invoices issued before a rate for their issue date
+// is known are left without a rate, which the next exchange rate refresh that finds one locks into issued invoices.
+func (s *invoiceServiceImpl) lockExchangeRate(ctx context.Context, invoice *models.Invoice) error {
+	baseCurrency, err := s.currency.GetBaseCurrency(ctx, invoice.SenderID)
+	if err != nil {
+		return err
+	}
+	invoice.BaseCurrency = baseCurrency
+	if invoice.Status == string(models.InvoiceStatusDraft) {
+		return nil
+	}
+
+	rate, err := findExchangeRate(ctx, s.currency, invoice.Currency, baseCurrency, invoice.IssueDate)
+	if errors.Is(err, models.ErrExchangeRateNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	invoice.ExchangeRate = &rate.Rate
+	return nil
+}
+
+// GetInvoiceDetails retrieves the details of an invoice by the given invoice ID, along with the breakdown of its totals. 
 func (s *invoiceServiceImpl) GetInvoiceDetails(ctx context.Context, invoiceID uuid.UUID) (*models.InvoiceDetails, error) {
-	return s.invoice.GetInvoiceDetails(ctx, invoiceID)
+	details, err := s.invoice.GetInvoiceDetails(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	details.Totals = calculateInvoiceTotals(details)
+	return details, nil
 }
 
-// AddInvoiceActivity creates a new invoice activity record. 
+// AddInvoiceActivity adds a comment of the user to the activities of an invoice.
 func (s *invoiceServiceImpl) AddInvoiceActivity(ctx context.Context, activity models.AddInvoiceActivityRequest) (uuid.UUID, error) {
 	invoiceID, err := uuid.Parse(activity.InvoiceID)
 	if err != nil {
@@ -111,34 +255,174 @@ func (s *invoiceServiceImpl) AddInvoiceActivity(ctx context.Context, activity mo
 		return uuid.Nil, fmt.Errorf("invalid user id")
 	}
 
-	return s.invoice.AddInvoiceActivity(ctx, models.InvoiceActivity{
-		ActivityID:  uuid.New(),
-		InvoiceID:   invoiceID,
-		UserID:      userID,
-		Title:       activity.Title,
-		Description: activity.Description,
-	})
+	comment := strings.TrimSpace(activity.Comment)
+	if comment == "" {
+		return uuid.Nil, fmt.Errorf("comment is required")
+	}
+
+	return s.invoice.AddInvoiceActivity(ctx, models.NewInvoiceActivity(invoiceID, userID, models.ActivityEventComment, nil,
+		"Comment", comment))
 }
 
-// GetTotalByStatus retrieves the total amount and count of invoices with the given status.
-func (s *invoiceServiceImpl) GetTotalByStatus(ctx context.Context, status models.InvoiceStatus) (totalAmount float64, count int, err error) {
-	return s.invoice.GetTotalByStatus(ctx, status)
+// summaryStatuses is the order in which statuses are listed in an invoice summary.
+var summaryStatuses = []models.InvoiceStatus{
+	models.InvoiceStatusDraft,
+	models.InvoiceStatusPending,
+	models.InvoiceStatusOverDue,
+	models.InvoiceStatusPaid,
 }
 
-// GetRecentInvoices retrieves the most recent invoices for the given sender ID, paginated by the provided page and limit. 
-func (s *invoiceServiceImpl) GetRecentInvoices(ctx context.Context, senderID uuid.UUID, page, limit int32) ([]models.Invoice, error) {
-	offset := (page - 1) * limit
-	return s.invoice.GetRecentInvoices(ctx, senderID, limit, offset)
+// GetTotalByStatus summarizes the invoices of a sender matching the filter for every status, with their totals per
+// currency and in the sender's base currency. Statuses without invoices are included with zero totals. Invoices are
+// filtered on their issue date unless the filter asks for the due date.
+func (s *invoiceServiceImpl) GetTotalByStatus(ctx context.Context, filter models.TotalsFilter) (*models.InvoiceSummary, error) {
+	if filter.DateField == "" {
+		filter.DateField = models.TotalsDateFieldIssue
+	}
+	if err := helpers.ValidateTotalsDateField(string(filter.DateField)); err != nil {
+		return nil, err
+	}
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return nil, fmt.Errorf("from must not be after to")
+	}
+
+	totals, err := s.invoice.GetTotalByStatus(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.InvoiceSummary{
+		SenderID:   filter.SenderID,
+		CustomerID: filter.CustomerID,
+		DateField:  filter.DateField,
+		From:       filter.From,
+		To:         filter.To,
+		Statuses:   completeStatusTotals(totals),
+	}, nil
 }
 
-// GetRecentActivities retrieves the most recent activities for the given user ID, paginated by the provided page and limit. 
-func (s *invoiceServiceImpl) GetRecentActivities(ctx context.Context, userID uuid.UUID, page, limit int32) ([]models.RecentActivity, error) {
-	offset := (page - 1) * limit
-	return s.invoice.GetRecentActivities(ctx, userID, limit, offset)
+// completeStatusTotals lists the totals of every status in summary order, adding zero totals for the statuses
+// without invoices.
+func completeStatusTotals(totals []models.StatusTotals) []models.StatusTotals {
+	byStatus := make(map[models.InvoiceStatus]models.StatusTotals, len(totals))
+	for _, total := range totals {
+		byStatus[total.Status] = total
+	}
+
+	complete := make([]models.StatusTotals, 0, len(summaryStatuses))
+	for _, status := range summaryStatuses {
+		total, ok := byStatus[status]
+		if !ok {
+			total = models.StatusTotals{
+				Status:         status,
+				Currencies:     []models.CurrencyTotal{},
+				BaseCurrencies: []models.CurrencyTotal{},
+			}
+		}
+		complete = append(complete, total)
+	}
+	return complete
+}
+
+// GetRecentInvoices retrieves a page of the most recent invoices for the given sender ID.
+func (s *invoiceServiceImpl) GetRecentInvoices(ctx context.Context, senderID uuid.UUID, page pagination.Request) ([]models.Invoice, *pagination.Meta, error) {
+	if err := page.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return s.invoice.GetRecentInvoices(ctx, senderID, page)
+}
+
+// SearchInvoices retrieves a page of the invoices of a sender matching the filter.
+// Invoices are sorted by creation date, newest first, unless another field or order is requested.
+func (s *invoiceServiceImpl) SearchInvoices(ctx context.Context, filter models.InvoiceFilter, page pagination.Request) ([]models.Invoice, *pagination.Meta, error) {
+	filter, err := validateInvoiceFilter(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := page.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	return s.invoice.SearchInvoices(ctx, filter, page)
+}
+
+// ExportInvoices streams the invoices of a sender matching the filter to fn, with one row per line item when
+// withItems is set. Invoices are sorted like SearchInvoices.
+func (s *invoiceServiceImpl) ExportInvoices(ctx context.Context, filter models.InvoiceFilter, withItems bool, fn func(models.InvoiceExportRow) error) error {
+	filter, err := validateInvoiceFilter(filter)
+	if err != nil {
+		return err
+	}
+	return s.invoice.ExportInvoices(ctx, filter, withItems, fn)
 }
 
-// GetInvoiceActivities retrieves the invoice activities for the given user ID and invoice ID, paginated by the provided page and limit. 
-func (s *invoiceServiceImpl) GetInvoiceActivities(ctx context.Context, userID, invoiceID uuid.UUID, page, limit int32) ([]models.InvoiceActivity, error) {
-	offset := (page - 1) * limit
-	return s.invoice.GetInvoiceActivities(ctx, userID, invoiceID, limit, offset)
+// validateInvoiceFilter checks the statuses, currency, sort and ranges of a filter, and returns it with the
+// sort defaulted to the newest invoices first.
+func validateInvoiceFilter(filter models.InvoiceFilter) (models.InvoiceFilter, error) {
+	for _, status := range filter.Statuses {
+		if err := helpers.ValidateInvoiceStatus(string(status)); err != nil {
+			return filter, err
+		}
+	}
+	if filter.Currency != "" {
+		if err := helpers.ValidateCurrency(filter.Currency); err != nil {
+			return filter, err
+		}
+	}
+
+	if filter.SortBy == "" {
+		filter.SortBy = models.InvoiceSortFieldCreatedAt
+	}
+	if err := helpers.ValidateInvoiceSortField(string(filter.SortBy)); err != nil {
+		return filter, err
+	}
+	if filter.SortOrder == "" {
+		filter.SortOrder = models.SortOrderDesc
+	}
+	if err := helpers.ValidateSortOrder(string(filter.SortOrder)); err != nil {
+		return filter, err
+	}
+
+	if filter.IssueFrom != nil && filter.IssueTo != nil && filter.IssueTo.Before(*filter.IssueFrom) {
+		return filter, fmt.Errorf("issue_from must not be after issue_to")
+	}
+	if filter.DueFrom != nil && filter.DueTo != nil && filter.DueTo.Before(*filter.DueFrom) {
+		return filter, fmt.Errorf("due_from must not be after due_to")
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MaxAmount < *filter.MinAmount {
+		return filter, fmt.Errorf("min_amount must not be greater than max_amount")
+	}
+
+	return filter, nil
+}
+
+// GetRecentActivities retrieves a page of the most recent activities for the given user ID, restricted to the event
+// types and source of the filter.
+func (s *invoiceServiceImpl) GetRecentActivities(ctx context.Context, userID uuid.UUID, filter models.ActivityFilter, page pagination.Request) ([]models.RecentActivity, *pagination.Meta, error) {
+	if err := page.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return s.invoice.GetRecentActivities(ctx, userID, filter, page)
+}
+
+// GetInvoiceActivities retrieves a page of the invoice activities for the given user ID and invoice ID, restricted to
+// the event types and source of the filter.
+func (s *invoiceServiceImpl) GetInvoiceActivities(ctx context.Context, userID, invoiceID uuid.UUID, filter models.ActivityFilter, page pagination.Request) ([]models.InvoiceActivity, *pagination.Meta, error) {
+	if err := page.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return s.invoice.GetInvoiceActivities(ctx, userID, invoiceID, filter, page)
+}
+
+// MarkOverdueInvoices marks every pending invoice due before the given time as overdue, a batch at a time, recording
+// the status change of each as an activity. It returns the number of invoices marked.
+func (s *invoiceServiceImpl) MarkOverdueInvoices(ctx context.Context, asOf time.Time) (int, error) {
+	marked := 0
+	for {
+		count, err := s.invoice.MarkOverdueInvoices(ctx, asOf, overdueBatchSize)
+		marked += count
+		if err != nil || count < overdueBatchSize {
+			return marked, err
+		}
+	}
 }
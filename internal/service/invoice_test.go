@@ -4,12 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	mocked "github.com/zde37/Numeris-Task/internal/mock"
 	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/pagination"
 	"go.uber.org/mock/gomock"
 )
 
@@ -40,7 +43,7 @@ func TestGetInvoiceDetails(t *testing.T) {
 			Times(1).
 			Return(mockInvoiceDetails, nil)
 
-		service := newInvoiceServiceImpl(repo)
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
 		details, err := service.GetInvoiceDetails(ctx, invoiceID)
 		require.NoError(t, err)
 		require.NotNil(t, details)
@@ -53,7 +56,7 @@ func TestGetInvoiceDetails(t *testing.T) {
 			Times(1).
 			Return(nil, sql.ErrNoRows)
 
-		service := newInvoiceServiceImpl(repo)
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
 		details, err := service.GetInvoiceDetails(ctx, invoiceID)
 		require.Error(t, err)
 		require.Nil(t, details)
@@ -67,7 +70,7 @@ func TestGetInvoiceDetails(t *testing.T) {
 			Times(1).
 			Return(nil, expectedErr)
 
-		service := newInvoiceServiceImpl(repo)
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
 		details, err := service.GetInvoiceDetails(ctx, invoiceID)
 		require.Error(t, err)
 		require.Nil(t, details)
@@ -81,7 +84,7 @@ func TestGetInvoiceDetails(t *testing.T) {
 			Times(1).
 			Return(nil, errors.New("invalid invoice ID"))
 
-		service := newInvoiceServiceImpl(repo)
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
 		details, err := service.GetInvoiceDetails(ctx, invalidID)
 		require.Error(t, err)
 		require.Nil(t, details)
@@ -97,61 +100,97 @@ func TestGetTotalByStatus(t *testing.T) {
 	repo := mocked.NewMockInvoiceRepository(ctrl)
 
 	t.Run("successful retrieval", func(t *testing.T) {
-		expectedTotal := 1000.0
-		expectedCount := 5
+		filter := models.TotalsFilter{SenderID: uuid.New()}
+		paid := models.StatusTotals{
+			Status: models.InvoiceStatusPaid,
+			Count:  5,
+			Currencies: []models.CurrencyTotal{
+				{Currency: "NGN", Count: 3, TotalAmount: 1000},
+				{Currency: "USD", Count: 2, TotalAmount: 20},
+			},
+			BaseCurrencies: []models.CurrencyTotal{{Currency: "NGN", Count: 5, TotalAmount: 30610}},
+		}
 		repo.EXPECT().
-			GetTotalByStatus(gomock.Any(), models.InvoiceStatusPaid).
+			GetTotalByStatus(gomock.Any(), models.TotalsFilter{SenderID: filter.SenderID, DateField: models.TotalsDateFieldIssue}).
 			Times(1).
-			Return(expectedTotal, expectedCount, nil)
+			Return([]models.StatusTotals{paid}, nil)
 
-		service := newInvoiceServiceImpl(repo)
-		total, count, err := service.GetTotalByStatus(ctx, models.InvoiceStatusPaid)
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
+		summary, err := service.GetTotalByStatus(ctx, filter)
 		require.NoError(t, err)
-		require.Equal(t, expectedTotal, total)
-		require.Equal(t, expectedCount, count)
+		require.Equal(t, filter.SenderID, summary.SenderID)
+		require.Equal(t, models.TotalsDateFieldIssue, summary.DateField)
+		require.Len(t, summary.Statuses, 4)
+		require.Equal(t, models.InvoiceStatusDraft, summary.Statuses[0].Status)
+		require.Equal(t, models.InvoiceStatusPending, summary.Statuses[1].Status)
+		require.Equal(t, models.InvoiceStatusOverDue, summary.Statuses[2].Status)
+		require.Equal(t, paid, summary.Statuses[3])
 	})
 
 	t.Run("zero invoices", func(t *testing.T) {
 		repo.EXPECT().
-			GetTotalByStatus(gomock.Any(), models.InvoiceStatusPending).
+			GetTotalByStatus(gomock.Any(), gomock.Any()).
 			Times(1).
-			Return(0.0, 0, nil)
+			Return([]models.StatusTotals{}, nil)
 
-		service := newInvoiceServiceImpl(repo)
-		total, count, err := service.GetTotalByStatus(ctx, models.InvoiceStatusPending)
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
+		summary, err := service.GetTotalByStatus(ctx, models.TotalsFilter{SenderID: uuid.New()})
 		require.NoError(t, err)
-		require.Equal(t, 0.0, total)
-		require.Equal(t, 0, count)
+		require.Len(t, summary.Statuses, 4)
+		for _, status := range summary.Statuses {
+			require.Equal(t, 0, status.Count)
+			require.Empty(t, status.Currencies)
+			require.Empty(t, status.BaseCurrencies)
+		}
 	})
 
-	t.Run("database error", func(t *testing.T) {
-		expectedErr := errors.New("database connection error")
+	t.Run("filtered by due date and customer", func(t *testing.T) {
+		customerID := uuid.New()
+		from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+		filter := models.TotalsFilter{SenderID: uuid.New(), CustomerID: &customerID, DateField: models.TotalsDateFieldDue, From: &from, To: &to}
 		repo.EXPECT().
-			GetTotalByStatus(gomock.Any(), models.InvoiceStatusOverDue).
+			GetTotalByStatus(gomock.Any(), filter).
 			Times(1).
-			Return(0.0, 0, expectedErr)
+			Return([]models.StatusTotals{}, nil)
 
-		service := newInvoiceServiceImpl(repo)
-		total, count, err := service.GetTotalByStatus(ctx, models.InvoiceStatusOverDue)
-		require.Error(t, err)
-		require.Equal(t, 0.0, total)
-		require.Equal(t, 0, count)
-		require.Equal(t, expectedErr, err)
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
+		summary, err := service.GetTotalByStatus(ctx, filter)
+		require.NoError(t, err)
+		require.Equal(t, &customerID, summary.CustomerID)
+		require.Equal(t, &from, summary.From)
+		require.Equal(t, &to, summary.To)
+	})
+
+	t.Run("invalid date field", func(t *testing.T) {
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
+		summary, err := service.GetTotalByStatus(ctx, models.TotalsFilter{SenderID: uuid.New(), DateField: "created_at"})
+		require.ErrorContains(t, err, "invalid date field")
+		require.Nil(t, summary)
 	})
 
-	t.Run("invalid status", func(t *testing.T) {
-		invalidStatus := models.InvoiceStatus("INVALID")
+	t.Run("from after to", func(t *testing.T) {
+		from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		to := from.AddDate(0, 0, -1)
+
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
+		summary, err := service.GetTotalByStatus(ctx, models.TotalsFilter{SenderID: uuid.New(), From: &from, To: &to})
+		require.ErrorContains(t, err, "from must not be after to")
+		require.Nil(t, summary)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		expectedErr := errors.New("database connection error")
 		repo.EXPECT().
-			GetTotalByStatus(gomock.Any(), invalidStatus).
+			GetTotalByStatus(gomock.Any(), gomock.Any()).
 			Times(1).
-			Return(0.0, 0, errors.New("invalid status"))
+			Return(nil, expectedErr)
 
-		service := newInvoiceServiceImpl(repo)
-		total, count, err := service.GetTotalByStatus(ctx, invalidStatus)
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
+		summary, err := service.GetTotalByStatus(ctx, models.TotalsFilter{SenderID: uuid.New()})
 		require.Error(t, err)
-		require.Equal(t, 0.0, total)
-		require.Equal(t, 0, count)
-		require.Contains(t, err.Error(), "invalid status")
+		require.Nil(t, summary)
+		require.Equal(t, expectedErr, err)
 	})
 }
 
@@ -169,24 +208,24 @@ func TestGetRecentInvoices(t *testing.T) {
 			{InvoiceID: uuid.New(), SenderID: senderID},
 		}
 		repo.EXPECT().
-			GetRecentInvoices(gomock.Any(), senderID, int32(10), int32(0)).
+			GetRecentInvoices(gomock.Any(), senderID, pagination.Request{Limit: 10, Page: 1}).
 			Times(1).
-			Return(expectedInvoices, nil)
+			Return(expectedInvoices, &pagination.Meta{Total: int64(len(expectedInvoices)), Limit: 10}, nil)
 
-		service := newInvoiceServiceImpl(repo)
-		invoices, err := service.GetRecentInvoices(ctx, senderID, 1, 10)
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
+		invoices, _, err := service.GetRecentInvoices(ctx, senderID, pagination.Request{Limit: 10, Page: 1})
 		require.NoError(t, err)
 		require.Equal(t, expectedInvoices, invoices)
 	})
 
 	t.Run("empty result", func(t *testing.T) {
 		repo.EXPECT().
-			GetRecentInvoices(gomock.Any(), senderID, int32(10), int32(90)).
+			GetRecentInvoices(gomock.Any(), senderID, pagination.Request{Limit: 10, Page: 10}).
 			Times(1).
-			Return([]models.Invoice{}, nil)
+			Return([]models.Invoice{}, &pagination.Meta{Limit: 10}, nil)
 
-		service := newInvoiceServiceImpl(repo)
-		invoices, err := service.GetRecentInvoices(ctx, senderID, 10, 10)
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
+		invoices, _, err := service.GetRecentInvoices(ctx, senderID, pagination.Request{Limit: 10, Page: 10})
 		require.NoError(t, err)
 		require.Empty(t, invoices)
 	})
@@ -194,16 +233,130 @@ func TestGetRecentInvoices(t *testing.T) {
 	t.Run("database error", func(t *testing.T) {
 		expectedErr := errors.New("database connection error")
 		repo.EXPECT().
-			GetRecentInvoices(gomock.Any(), senderID, int32(10), int32(0)).
+			GetRecentInvoices(gomock.Any(), senderID, pagination.Request{Limit: 10, Page: 1}).
 			Times(1).
-			Return(nil, expectedErr)
+			Return(nil, nil, expectedErr)
 
-		service := newInvoiceServiceImpl(repo)
-		invoices, err := service.GetRecentInvoices(ctx, senderID, 1, 10)
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
+		invoices, _, err := service.GetRecentInvoices(ctx, senderID, pagination.Request{Limit: 10, Page: 1})
 		require.Error(t, err)
 		require.Nil(t, invoices)
 		require.Equal(t, expectedErr, err)
 	})
+
+	t.Run("invalid page request", func(t *testing.T) {
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
+		invoices, _, err := service.GetRecentInvoices(ctx, senderID, pagination.Request{Limit: pagination.MaxLimit + 1, Page: 1})
+		require.ErrorIs(t, err, pagination.ErrInvalidLimit)
+		require.Nil(t, invoices)
+
+		invoices, _, err = service.GetRecentInvoices(ctx, senderID, pagination.Request{Limit: 10, Page: -1})
+		require.ErrorIs(t, err, pagination.ErrInvalidPage)
+		require.Nil(t, invoices)
+	})
+}
+
+func TestSearchInvoices(t *testing.T) {
+	ctx := context.Background()
+	senderID := uuid.New()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockInvoiceRepository(ctrl)
+	service := newInvoiceServiceImpl(repo, nil, nil, nil)
+
+	t.Run("defaults to newest first", func(t *testing.T) {
+		expectedInvoices := []models.Invoice{{InvoiceID: uuid.New(), SenderID: senderID}}
+		repo.EXPECT().
+			SearchInvoices(gomock.Any(), models.InvoiceFilter{
+				SenderID:  senderID,
+				Statuses:  []models.InvoiceStatus{models.InvoiceStatusPaid},
+				SortBy:    models.InvoiceSortFieldCreatedAt,
+				SortOrder: models.SortOrderDesc,
+			}, pagination.Request{Limit: 10, Page: 3}).
+			Times(1).
+			Return(expectedInvoices, &pagination.Meta{Total: int64(len(expectedInvoices)), Limit: 10}, nil)
+
+		invoices, _, err := service.SearchInvoices(ctx, models.InvoiceFilter{
+			SenderID: senderID,
+			Statuses: []models.InvoiceStatus{models.InvoiceStatusPaid},
+		}, pagination.Request{Limit: 10, Page: 3})
+		require.NoError(t, err)
+		require.Equal(t, expectedInvoices, invoices)
+	})
+
+	t.Run("invalid filters", func(t *testing.T) {
+		later := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+		earlier := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		low, high := 10.0, 100.0
+		for name, filter := range map[string]models.InvoiceFilter{
+			"invalid status":       {Statuses: []models.InvoiceStatus{"void"}},
+			"invalid currency":     {Currency: "XYZ"},
+			"invalid sort field":   {SortBy: "customer"},
+			"invalid sort order":   {SortOrder: "up"},
+			"reversed issue dates": {IssueFrom: &later, IssueTo: &earlier},
+			"reversed due dates":   {DueFrom: &later, DueTo: &earlier},
+			"reversed amounts":     {MinAmount: &high, MaxAmount: &low},
+		} {
+			t.Run(name, func(t *testing.T) {
+				filter.SenderID = senderID
+				invoices, _, err := service.SearchInvoices(ctx, filter, pagination.Request{Limit: 10, Page: 1})
+				require.Error(t, err)
+				require.Nil(t, invoices)
+			})
+		}
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		expectedErr := errors.New("database connection error")
+		repo.EXPECT().
+			SearchInvoices(gomock.Any(), gomock.Any(), pagination.Request{Limit: 10, Page: 1}).
+			Times(1).
+			Return(nil, nil, expectedErr)
+
+		invoices, _, err := service.SearchInvoices(ctx, models.InvoiceFilter{SenderID: senderID}, pagination.Request{Limit: 10, Page: 1})
+		require.Equal(t, expectedErr, err)
+		require.Nil(t, invoices)
+	})
+}
+
+func TestExportInvoices(t *testing.T) {
+	ctx := context.Background()
+	senderID := uuid.New()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockInvoiceRepository(ctrl)
+	service := newInvoiceServiceImpl(repo, nil, nil, nil)
+
+	t.Run("defaults to newest first", func(t *testing.T) {
+		row := models.InvoiceExportRow{Invoice: models.Invoice{InvoiceID: uuid.New()}, CustomerName: "Acme"}
+		repo.EXPECT().
+			ExportInvoices(gomock.Any(), models.InvoiceFilter{
+				SenderID:  senderID,
+				SortBy:    models.InvoiceSortFieldCreatedAt,
+				SortOrder: models.SortOrderDesc,
+			}, true, gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ context.Context, _ models.InvoiceFilter, _ bool, fn func(models.InvoiceExportRow) error) error {
+				return fn(row)
+			})
+
+		rows := []models.InvoiceExportRow{}
+		err := service.ExportInvoices(ctx, models.InvoiceFilter{SenderID: senderID}, true, func(row models.InvoiceExportRow) error {
+			rows = append(rows, row)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []models.InvoiceExportRow{row}, rows)
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		err := service.ExportInvoices(ctx, models.InvoiceFilter{SenderID: senderID, Currency: "XYZ"}, false, func(models.InvoiceExportRow) error {
+			return nil
+		})
+		require.Error(t, err)
+	})
 }
 
 func TestGetRecentActivities(t *testing.T) {
@@ -220,24 +373,24 @@ func TestGetRecentActivities(t *testing.T) {
 			{ActivityID: uuid.New(), UserID: userID},
 		}
 		repo.EXPECT().
-			GetRecentActivities(gomock.Any(), userID, int32(10), int32(0)).
+			GetRecentActivities(gomock.Any(), userID, models.ActivityFilter{}, pagination.Request{Limit: 10, Page: 1}).
 			Times(1).
-			Return(expectedActivities, nil)
+			Return(expectedActivities, &pagination.Meta{Total: int64(len(expectedActivities)), Limit: 10}, nil)
 
-		service := newInvoiceServiceImpl(repo)
-		activities, err := service.GetRecentActivities(ctx, userID, 1, 10)
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
+		activities, _, err := service.GetRecentActivities(ctx, userID, models.ActivityFilter{}, pagination.Request{Limit: 10, Page: 1})
 		require.NoError(t, err)
 		require.Equal(t, expectedActivities, activities)
 	})
 
 	t.Run("empty result", func(t *testing.T) {
 		repo.EXPECT().
-			GetRecentActivities(gomock.Any(), userID, int32(10), int32(90)).
+			GetRecentActivities(gomock.Any(), userID, models.ActivityFilter{}, pagination.Request{Limit: 10, Page: 10}).
 			Times(1).
-			Return([]models.RecentActivity{}, nil)
+			Return([]models.RecentActivity{}, &pagination.Meta{Limit: 10}, nil)
 
-		service := newInvoiceServiceImpl(repo)
-		activities, err := service.GetRecentActivities(ctx, userID, 10, 10)
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
+		activities, _, err := service.GetRecentActivities(ctx, userID, models.ActivityFilter{}, pagination.Request{Limit: 10, Page: 10})
 		require.NoError(t, err)
 		require.Empty(t, activities)
 	})
@@ -245,12 +398,12 @@ func TestGetRecentActivities(t *testing.T) {
 	t.Run("database error", func(t *testing.T) {
 		expectedErr := errors.New("database connection error")
 		repo.EXPECT().
-			GetRecentActivities(gomock.Any(), userID, int32(10), int32(0)).
+			GetRecentActivities(gomock.Any(), userID, models.ActivityFilter{}, pagination.Request{Limit: 10, Page: 1}).
 			Times(1).
-			Return(nil, expectedErr)
+			Return(nil, nil, expectedErr)
 
-		service := newInvoiceServiceImpl(repo)
-		activities, err := service.GetRecentActivities(ctx, userID, 1, 10)
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
+		activities, _, err := service.GetRecentActivities(ctx, userID, models.ActivityFilter{}, pagination.Request{Limit: 10, Page: 1})
 		require.Error(t, err)
 		require.Nil(t, activities)
 		require.Equal(t, expectedErr, err)
@@ -272,24 +425,24 @@ func TestGetInvoiceActivities(t *testing.T) {
 			{ActivityID: uuid.New(), InvoiceID: invoiceID, UserID: userID},
 		}
 		repo.EXPECT().
-			GetInvoiceActivities(gomock.Any(), userID, invoiceID, int32(10), int32(0)).
+			GetInvoiceActivities(gomock.Any(), userID, invoiceID, models.ActivityFilter{}, pagination.Request{Limit: 10, Page: 1}).
 			Times(1).
-			Return(expectedActivities, nil)
+			Return(expectedActivities, &pagination.Meta{Total: int64(len(expectedActivities)), Limit: 10}, nil)
 
-		service := newInvoiceServiceImpl(repo)
-		activities, err := service.GetInvoiceActivities(ctx, userID, invoiceID, 1, 10)
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
+		activities, _, err := service.GetInvoiceActivities(ctx, userID, invoiceID, models.ActivityFilter{}, pagination.Request{Limit: 10, Page: 1})
 		require.NoError(t, err)
 		require.Equal(t, expectedActivities, activities)
 	})
 
 	t.Run("empty result", func(t *testing.T) {
 		repo.EXPECT().
-			GetInvoiceActivities(gomock.Any(), userID, invoiceID, int32(10), int32(90)).
+			GetInvoiceActivities(gomock.Any(), userID, invoiceID, models.ActivityFilter{}, pagination.Request{Limit: 10, Page: 10}).
 			Times(1).
-			Return([]models.InvoiceActivity{}, nil)
+			Return([]models.InvoiceActivity{}, &pagination.Meta{Limit: 10}, nil)
 
-		service := newInvoiceServiceImpl(repo)
-		activities, err := service.GetInvoiceActivities(ctx, userID, invoiceID, 10, 10)
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
+		activities, _, err := service.GetInvoiceActivities(ctx, userID, invoiceID, models.ActivityFilter{}, pagination.Request{Limit: 10, Page: 10})
 		require.NoError(t, err)
 		require.Empty(t, activities)
 	})
@@ -297,12 +450,12 @@ func TestGetInvoiceActivities(t *testing.T) {
 	t.Run("database error", func(t *testing.T) {
 		expectedErr := errors.New("database connection error")
 		repo.EXPECT().
-			GetInvoiceActivities(gomock.Any(), userID, invoiceID, int32(10), int32(0)).
+			GetInvoiceActivities(gomock.Any(), userID, invoiceID, models.ActivityFilter{}, pagination.Request{Limit: 10, Page: 1}).
 			Times(1).
-			Return(nil, expectedErr)
+			Return(nil, nil, expectedErr)
 
-		service := newInvoiceServiceImpl(repo)
-		activities, err := service.GetInvoiceActivities(ctx, userID, invoiceID, 1, 10)
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
+		activities, _, err := service.GetInvoiceActivities(ctx, userID, invoiceID, models.ActivityFilter{}, pagination.Request{Limit: 10, Page: 1})
 		require.Error(t, err)
 		require.Nil(t, activities)
 		require.Equal(t, expectedErr, err)
@@ -311,12 +464,12 @@ func TestGetInvoiceActivities(t *testing.T) {
 	t.Run("invalid user ID", func(t *testing.T) {
 		invalidUserID := uuid.Nil
 		repo.EXPECT().
-			GetInvoiceActivities(gomock.Any(), invalidUserID, invoiceID, int32(10), int32(0)).
+			GetInvoiceActivities(gomock.Any(), invalidUserID, invoiceID, models.ActivityFilter{}, pagination.Request{Limit: 10, Page: 1}).
 			Times(1).
-			Return(nil, errors.New("invalid user ID"))
+			Return(nil, nil, errors.New("invalid user ID"))
 
-		service := newInvoiceServiceImpl(repo)
-		activities, err := service.GetInvoiceActivities(ctx, invalidUserID, invoiceID, 1, 10)
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
+		activities, _, err := service.GetInvoiceActivities(ctx, invalidUserID, invoiceID, models.ActivityFilter{}, pagination.Request{Limit: 10, Page: 1})
 		require.Error(t, err)
 		require.Nil(t, activities)
 		require.Contains(t, err.Error(), "invalid user ID")
@@ -325,12 +478,12 @@ func TestGetInvoiceActivities(t *testing.T) {
 	t.Run("invalid invoice ID", func(t *testing.T) {
 		invalidInvoiceID := uuid.Nil
 		repo.EXPECT().
-			GetInvoiceActivities(gomock.Any(), userID, invalidInvoiceID, int32(10), int32(0)).
+			GetInvoiceActivities(gomock.Any(), userID, invalidInvoiceID, models.ActivityFilter{}, pagination.Request{Limit: 10, Page: 1}).
 			Times(1).
-			Return(nil, errors.New("invalid invoice ID"))
+			Return(nil, nil, errors.New("invalid invoice ID"))
 
-		service := newInvoiceServiceImpl(repo)
-		activities, err := service.GetInvoiceActivities(ctx, userID, invalidInvoiceID, 1, 10)
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
+		activities, _, err := service.GetInvoiceActivities(ctx, userID, invalidInvoiceID, models.ActivityFilter{}, pagination.Request{Limit: 10, Page: 1})
 		require.Error(t, err)
 		require.Nil(t, activities)
 		require.Contains(t, err.Error(), "invalid invoice ID")
@@ -350,10 +503,9 @@ func TestAddInvoiceActivity(t *testing.T) {
 		expectedActivityID := uuid.New()
 
 		request := models.AddInvoiceActivityRequest{
-			InvoiceID:   validInvoiceID.String(),
-			UserID:      validUserID.String(),
-			Title:       "Test Activity",
-			Description: "Test Description",
+			InvoiceID: validInvoiceID.String(),
+			UserID:    validUserID.String(),
+			Comment:   "Test Comment",
 		}
 
 		repo.EXPECT().
@@ -361,12 +513,13 @@ func TestAddInvoiceActivity(t *testing.T) {
 			DoAndReturn(func(_ context.Context, activity models.InvoiceActivity) (uuid.UUID, error) {
 				require.Equal(t, validInvoiceID, activity.InvoiceID)
 				require.Equal(t, validUserID, activity.UserID)
-				require.Equal(t, request.Title, activity.Title)
-				require.Equal(t, request.Description, activity.Description)
+				require.Equal(t, models.ActivityEventComment, activity.EventType)
+				require.Equal(t, models.ActivitySourceUser, activity.Source)
+				require.Equal(t, request.Comment, activity.Description)
 				return expectedActivityID, nil
 			})
 
-		service := newInvoiceServiceImpl(repo)
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
 		activityID, err := service.AddInvoiceActivity(ctx, request)
 		require.NoError(t, err)
 		require.Equal(t, expectedActivityID, activityID)
@@ -374,13 +527,12 @@ func TestAddInvoiceActivity(t *testing.T) {
 
 	t.Run("invalid invoice ID", func(t *testing.T) {
 		request := models.AddInvoiceActivityRequest{
-			InvoiceID:   "invalid-uuid",
-			UserID:      uuid.New().String(),
-			Title:       "Test Activity",
-			Description: "Test Description",
+			InvoiceID: "invalid-uuid",
+			UserID:    uuid.New().String(),
+			Comment:   "Test Comment",
 		}
 
-		service := newInvoiceServiceImpl(repo)
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
 		activityID, err := service.AddInvoiceActivity(ctx, request)
 		require.Error(t, err)
 		require.Equal(t, uuid.Nil, activityID)
@@ -389,36 +541,46 @@ func TestAddInvoiceActivity(t *testing.T) {
 
 	t.Run("invalid user ID", func(t *testing.T) {
 		request := models.AddInvoiceActivityRequest{
-			InvoiceID:   uuid.New().String(),
-			UserID:      "invalid-uuid",
-			Title:       "Test Activity",
-			Description: "Test Description",
+			InvoiceID: uuid.New().String(),
+			UserID:    "invalid-uuid",
+			Comment:   "Test Comment",
 		}
 
-		service := newInvoiceServiceImpl(repo)
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
 		activityID, err := service.AddInvoiceActivity(ctx, request)
 		require.Error(t, err)
 		require.Equal(t, uuid.Nil, activityID)
 		require.Contains(t, err.Error(), "invalid user id")
 	})
 
+	t.Run("blank comment", func(t *testing.T) {
+		request := models.AddInvoiceActivityRequest{
+			InvoiceID: uuid.New().String(),
+			UserID:    uuid.New().String(),
+			Comment:   "  ",
+		}
+
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
+		_, err := service.AddInvoiceActivity(ctx, request)
+		require.ErrorContains(t, err, "comment is required")
+	})
+
 	t.Run("repository error", func(t *testing.T) {
 		validInvoiceID := uuid.New()
 		validUserID := uuid.New()
 		expectedError := errors.New("repository error")
 
 		request := models.AddInvoiceActivityRequest{
-			InvoiceID:   validInvoiceID.String(),
-			UserID:      validUserID.String(),
-			Title:       "Test Activity",
-			Description: "Test Description",
+			InvoiceID: validInvoiceID.String(),
+			UserID:    validUserID.String(),
+			Comment:   "Test Comment",
 		}
 
 		repo.EXPECT().
 			AddInvoiceActivity(gomock.Any(), gomock.Any()).
 			Return(uuid.Nil, expectedError)
 
-		service := newInvoiceServiceImpl(repo)
+		service := newInvoiceServiceImpl(repo, nil, nil, nil)
 		activityID, err := service.AddInvoiceActivity(ctx, request)
 		require.Error(t, err)
 		require.Equal(t, uuid.Nil, activityID)
@@ -432,6 +594,8 @@ func TestCreateInvoice(t *testing.T) {
 	defer ctrl.Finish()
 
 	repo := mocked.NewMockInvoiceRepository(ctrl)
+	taxRepo := mocked.NewMockTaxRepository(ctrl)
+	currencyRepo := mocked.NewMockCurrencyRepository(ctrl)
 
 	t.Run("successful creation", func(t *testing.T) {
 		expectedInvoiceID := uuid.New()
@@ -461,16 +625,173 @@ func TestCreateInvoice(t *testing.T) {
 			},
 		}
 
+		issueDate := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+		currencyRepo.EXPECT().
+			GetBaseCurrency(gomock.Any(), uuid.MustParse(validRequest.Invoice.SenderID)).
+			Return("NGN", nil)
+		currencyRepo.EXPECT().
+			GetExchangeRate(gomock.Any(), "USD", "NGN", issueDate).
+			Return(&models.ExchangeRate{FromCurrency: "USD", ToCurrency: "NGN", Rate: 1480.5, EffectiveDate: issueDate}, nil)
 		repo.EXPECT().
-			CreateInvoice(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-			Return(expectedInvoiceID, nil)
+			CreateInvoice(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, invoice models.Invoice, _ []models.InvoiceItem, _ []models.InvoiceTaxLine,
+				_ uuid.UUID, _ models.PaymentInformation) (uuid.UUID, error) {
+				require.Equal(t, "USD", invoice.Currency)
+				require.Equal(t, "NGN", invoice.BaseCurrency)
+				require.NotNil(t, invoice.ExchangeRate)
+				require.Equal(t, 1480.5, *invoice.ExchangeRate)
+				return expectedInvoiceID, nil
+			})
 
-		service := newInvoiceServiceImpl(repo)
+		service := newInvoiceServiceImpl(repo, taxRepo, currencyRepo, nil)
 		invoiceID, err := service.CreateInvoice(ctx, validRequest)
 		require.NoError(t, err)
 		require.Equal(t, expectedInvoiceID, invoiceID)
 	})
 
+	t.Run("taxed invoice", func(t *testing.T) {
+		senderID := uuid.New()
+		vat := models.TaxRate{TaxRateID: uuid.New(), UserID: senderID, Name: "VAT", Rate: 7.5}
+		levy := models.TaxRate{TaxRateID: uuid.New(), UserID: senderID, Name: "Levy", Rate: 2, IsCompound: true}
+		request := models.CreateInvoiceRequest{
+			Invoice: models.InvoiceInfo{
+				SenderID:           senderID.String(),
+				TotalAmount:        1,
+				DiscountPercentage: 10,
+				FinalAmount:        1,
+				Status:             string(models.InvoiceStatusPending),
+				Currency:           "NGN",
+				IssueDate:          "2023-05-01",
+				DueDate:            "2023-05-31",
+			},
+			CustomerID:      uuid.New().String(),
+			PaymentMethodID: uuid.New().String(),
+			InvoiceItems: []models.InvoiceItemDetails{
+				{Name: "Item 1", Quantity: 2, UnitPrice: 500, TotalPrice: 1000, TaxRateIDs: []string{vat.TaxRateID.String(), levy.TaxRateID.String()}},
+				{Name: "Item 2", Quantity: 1, UnitPrice: 200, TotalPrice: 200},
+			},
+		}
+
+		taxRepo.EXPECT().
+			GetTaxRates(gomock.Any(), senderID).
+			Return([]models.TaxRate{levy, vat}, nil)
+		currencyRepo.EXPECT().
+			GetBaseCurrency(gomock.Any(), senderID).
+			Return("NGN", nil)
+		repo.EXPECT().
+			CreateInvoice(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, invoice models.Invoice, items []models.InvoiceItem, taxLines []models.InvoiceTaxLine,
+				_ uuid.UUID, _ models.PaymentInformation) (uuid.UUID, error) {
+				require.Equal(t, 1200.0, invoice.TotalAmount)
+				require.Equal(t, 120.0, invoice.DiscountedAmount)
+				require.Equal(t, 1166.85, invoice.FinalAmount)
+				require.Equal(t, 1.0, *invoice.ExchangeRate)
+
+				require.Len(t, items[0].Taxes, 2)
+				require.Equal(t, 67.5, items[0].Taxes[0].TaxAmount)
+				require.Equal(t, 19.35, items[0].Taxes[1].TaxAmount)
+				require.Empty(t, items[1].Taxes)
+
+				require.Len(t, taxLines, 2)
+				require.Equal(t, invoice.InvoiceID, taxLines[0].InvoiceID)
+				require.Equal(t, vat.TaxRateID, taxLines[0].TaxRateID)
+				return invoice.InvoiceID, nil
+			})
+
+		service := newInvoiceServiceImpl(repo, taxRepo, currencyRepo, nil)
+		invoiceID, err := service.CreateInvoice(ctx, request)
+		require.NoError(t, err)
+		require.NotEqual(t, uuid.Nil, invoiceID)
+	})
+
+	t.Run("unknown tax rate", func(t *testing.T) {
+		senderID := uuid.New()
+		request := models.CreateInvoiceRequest{
+			Invoice: models.InvoiceInfo{
+				SenderID:  senderID.String(),
+				Status:    string(models.InvoiceStatusPending),
+				Currency:  "NGN",
+				IssueDate: "2023-05-01",
+				DueDate:   "2023-05-31",
+			},
+			CustomerID:      uuid.New().String(),
+			PaymentMethodID: uuid.New().String(),
+			InvoiceItems: []models.InvoiceItemDetails{
+				{Name: "Item 1", Quantity: 1, UnitPrice: 100, TotalPrice: 100, TaxRateIDs: []string{uuid.New().String()}},
+			},
+		}
+
+		taxRepo.EXPECT().
+			GetTaxRates(gomock.Any(), senderID).
+			Return([]models.TaxRate{}, nil)
+
+		service := newInvoiceServiceImpl(repo, taxRepo, currencyRepo, nil)
+		invoiceID, err := service.CreateInvoice(ctx, request)
+		require.Error(t, err)
+		require.Equal(t, uuid.Nil, invoiceID)
+		require.Contains(t, err.Error(), "not found")
+	})
+
+	t.Run("default payment method", func(t *testing.T) {
+		senderID := uuid.New()
+		defaultMethod := models.UserPaymentMethod{PaymentMethodID: uuid.New(), UserID: senderID, IsDefault: true}
+		request := models.CreateInvoiceRequest{
+			Invoice: models.InvoiceInfo{
+				SenderID:  senderID.String(),
+				Status:    string(models.InvoiceStatusPending),
+				Currency:  "NGN",
+				IssueDate: "2023-05-01",
+				DueDate:   "2023-05-31",
+			},
+			CustomerID: uuid.New().String(),
+		}
+
+		userRepo := mocked.NewMockUserRepository(ctrl)
+		userRepo.EXPECT().
+			GetDefaultPaymentMethod(gomock.Any(), senderID).
+			Return(&defaultMethod, nil)
+		currencyRepo.EXPECT().
+			GetBaseCurrency(gomock.Any(), senderID).
+			Return("NGN", nil)
+		repo.EXPECT().
+			CreateInvoice(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, invoice models.Invoice, _ []models.InvoiceItem, _ []models.InvoiceTaxLine,
+				_ uuid.UUID, paymentInfo models.PaymentInformation) (uuid.UUID, error) {
+				require.Equal(t, defaultMethod.PaymentMethodID, paymentInfo.PaymentMethodID)
+				return invoice.InvoiceID, nil
+			})
+
+		service := newInvoiceServiceImpl(repo, taxRepo, currencyRepo, userRepo)
+		invoiceID, err := service.CreateInvoice(ctx, request)
+		require.NoError(t, err)
+		require.NotEqual(t, uuid.Nil, invoiceID)
+	})
+
+	t.Run("no default payment method", func(t *testing.T) {
+		senderID := uuid.New()
+		request := models.CreateInvoiceRequest{
+			Invoice: models.InvoiceInfo{
+				SenderID:  senderID.String(),
+				Status:    string(models.InvoiceStatusPending),
+				Currency:  "NGN",
+				IssueDate: "2023-05-01",
+				DueDate:   "2023-05-31",
+			},
+			CustomerID: uuid.New().String(),
+		}
+
+		userRepo := mocked.NewMockUserRepository(ctrl)
+		userRepo.EXPECT().
+			GetDefaultPaymentMethod(gomock.Any(), senderID).
+			Return(nil, nil)
+
+		service := newInvoiceServiceImpl(repo, taxRepo, currencyRepo, userRepo)
+		invoiceID, err := service.CreateInvoice(ctx, request)
+		require.Error(t, err)
+		require.Equal(t, uuid.Nil, invoiceID)
+		require.Contains(t, err.Error(), "no default payment method")
+	})
+
 	t.Run("invalid sender ID", func(t *testing.T) {
 		invalidRequest := models.CreateInvoiceRequest{
 			Invoice: models.InvoiceInfo{
@@ -480,7 +801,7 @@ func TestCreateInvoice(t *testing.T) {
 			PaymentMethodID: uuid.New().String(),
 		}
 
-		service := newInvoiceServiceImpl(repo)
+		service := newInvoiceServiceImpl(repo, taxRepo, currencyRepo, nil)
 		invoiceID, err := service.CreateInvoice(ctx, invalidRequest)
 		require.Error(t, err)
 		require.Equal(t, uuid.Nil, invoiceID)
@@ -496,7 +817,7 @@ func TestCreateInvoice(t *testing.T) {
 			PaymentMethodID: uuid.New().String(),
 		}
 
-		service := newInvoiceServiceImpl(repo)
+		service := newInvoiceServiceImpl(repo, taxRepo, currencyRepo, nil)
 		invoiceID, err := service.CreateInvoice(ctx, invalidRequest)
 		require.Error(t, err)
 		require.Equal(t, uuid.Nil, invoiceID)
@@ -513,18 +834,130 @@ func TestCreateInvoice(t *testing.T) {
 			PaymentMethodID: uuid.New().String(),
 		}
 
-		service := newInvoiceServiceImpl(repo)
+		service := newInvoiceServiceImpl(repo, taxRepo, currencyRepo, nil)
 		invoiceID, err := service.CreateInvoice(ctx, invalidRequest)
 		require.Error(t, err)
 		require.Equal(t, uuid.Nil, invoiceID)
 		require.Contains(t, err.Error(), "invalid invoice status")
 	})
 
+	t.Run("invalid currency", func(t *testing.T) {
+		invalidRequest := models.CreateInvoiceRequest{
+			Invoice: models.InvoiceInfo{
+				SenderID: uuid.New().String(),
+				Status:   string(models.InvoiceStatusPending),
+				Currency: "usd",
+			},
+			CustomerID:      uuid.New().String(),
+			PaymentMethodID: uuid.New().String(),
+		}
+
+		service := newInvoiceServiceImpl(repo, taxRepo, currencyRepo, nil)
+		invoiceID, err := service.CreateInvoice(ctx, invalidRequest)
+		require.Error(t, err)
+		require.Equal(t, uuid.Nil, invoiceID)
+		require.Contains(t, err.Error(), "invalid currency")
+	})
+
+	t.Run("missing exchange rate", func(t *testing.T) {
+		request := models.CreateInvoiceRequest{
+			Invoice: models.InvoiceInfo{
+				SenderID:  uuid.New().String(),
+				Status:    string(models.InvoiceStatusPending),
+				Currency:  "EUR",
+				IssueDate: "2023-05-01",
+				DueDate:   "2023-05-31",
+			},
+			CustomerID:      uuid.New().String(),
+			PaymentMethodID: uuid.New().String(),
+		}
+
+		expectedInvoiceID := uuid.New()
+		currencyRepo.EXPECT().
+			GetBaseCurrency(gomock.Any(), gomock.Any()).
+			Return("NGN", nil)
+		currencyRepo.EXPECT().
+			GetExchangeRate(gomock.Any(), "EUR", "NGN", gomock.Any()).
+			Return(nil, fmt.Errorf("%w from EUR to NGN on 2023-05-01", models.ErrExchangeRateNotFound))
+		repo.EXPECT().
+			CreateInvoice(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, invoice models.Invoice, _ []models.InvoiceItem, _ []models.InvoiceTaxLine,
+				_ uuid.UUID, _ models.PaymentInformation) (uuid.UUID, error) {
+				require.Equal(t, "NGN", invoice.BaseCurrency)
+				require.Nil(t, invoice.ExchangeRate)
+				return expectedInvoiceID, nil
+			})
+
+		service := newInvoiceServiceImpl(repo, taxRepo, currencyRepo, nil)
+		invoiceID, err := service.CreateInvoice(ctx, request)
+		require.NoError(t, err)
+		require.Equal(t, expectedInvoiceID, invoiceID)
+	})
+
+	t.Run("exchange rate lookup error", func(t *testing.T) {
+		request := models.CreateInvoiceRequest{
+			Invoice: models.InvoiceInfo{
+				SenderID:  uuid.New().String(),
+				Status:    string(models.InvoiceStatusPending),
+				Currency:  "EUR",
+				IssueDate: "2023-05-01",
+				DueDate:   "2023-05-31",
+			},
+			CustomerID:      uuid.New().String(),
+			PaymentMethodID: uuid.New().String(),
+		}
+
+		currencyRepo.EXPECT().
+			GetBaseCurrency(gomock.Any(), gomock.Any()).
+			Return("NGN", nil)
+		currencyRepo.EXPECT().
+			GetExchangeRate(gomock.Any(), "EUR", "NGN", gomock.Any()).
+			Return(nil, errors.New("connection refused"))
+
+		service := newInvoiceServiceImpl(repo, taxRepo, currencyRepo, nil)
+		invoiceID, err := service.CreateInvoice(ctx, request)
+		require.Error(t, err)
+		require.Equal(t, uuid.Nil, invoiceID)
+	})
+
+	t.Run("draft without exchange rate", func(t *testing.T) {
+		request := models.CreateInvoiceRequest{
+			Invoice: models.InvoiceInfo{
+				SenderID:  uuid.New().String(),
+				Status:    string(models.InvoiceStatusDraft),
+				Currency:  "EUR",
+				IssueDate: "2023-05-01",
+				DueDate:   "2023-05-31",
+			},
+			CustomerID:      uuid.New().String(),
+			PaymentMethodID: uuid.New().String(),
+		}
+
+		expectedInvoiceID := uuid.New()
+		currencyRepo.EXPECT().
+			GetBaseCurrency(gomock.Any(), gomock.Any()).
+			Return("NGN", nil)
+		repo.EXPECT().
+			CreateInvoice(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, invoice models.Invoice, _ []models.InvoiceItem, _ []models.InvoiceTaxLine,
+				_ uuid.UUID, _ models.PaymentInformation) (uuid.UUID, error) {
+				require.Equal(t, "NGN", invoice.BaseCurrency)
+				require.Nil(t, invoice.ExchangeRate)
+				return expectedInvoiceID, nil
+			})
+
+		service := newInvoiceServiceImpl(repo, taxRepo, currencyRepo, nil)
+		invoiceID, err := service.CreateInvoice(ctx, request)
+		require.NoError(t, err)
+		require.Equal(t, expectedInvoiceID, invoiceID)
+	})
+
 	t.Run("invalid issue date format", func(t *testing.T) {
 		invalidRequest := models.CreateInvoiceRequest{
 			Invoice: models.InvoiceInfo{
 				SenderID:  uuid.New().String(),
 				Status:    string(models.InvoiceStatusPending),
+				Currency:  "NGN",
 				IssueDate: "01-05-2023",
 				DueDate:   "2023-05-31",
 			},
@@ -532,7 +965,7 @@ func TestCreateInvoice(t *testing.T) {
 			PaymentMethodID: uuid.New().String(),
 		}
 
-		service := newInvoiceServiceImpl(repo)
+		service := newInvoiceServiceImpl(repo, taxRepo, currencyRepo, nil)
 		invoiceID, err := service.CreateInvoice(ctx, invalidRequest)
 		require.Error(t, err)
 		require.Equal(t, uuid.Nil, invoiceID)
@@ -544,6 +977,7 @@ func TestCreateInvoice(t *testing.T) {
 			Invoice: models.InvoiceInfo{
 				SenderID:  uuid.New().String(),
 				Status:    string(models.InvoiceStatusPending),
+				Currency:  "NGN",
 				IssueDate: "2023-05-01",
 				DueDate:   "31-05-2023",
 			},
@@ -551,7 +985,7 @@ func TestCreateInvoice(t *testing.T) {
 			PaymentMethodID: uuid.New().String(),
 		}
 
-		service := newInvoiceServiceImpl(repo)
+		service := newInvoiceServiceImpl(repo, taxRepo, currencyRepo, nil)
 		invoiceID, err := service.CreateInvoice(ctx, invalidRequest)
 		require.Error(t, err)
 		require.Equal(t, uuid.Nil, invoiceID)
@@ -563,6 +997,7 @@ func TestCreateInvoice(t *testing.T) {
 			Invoice: models.InvoiceInfo{
 				SenderID:  uuid.New().String(),
 				Status:    string(models.InvoiceStatusPending),
+				Currency:  "NGN",
 				IssueDate: "2023-05-01",
 				DueDate:   "2023-05-31",
 			},
@@ -570,7 +1005,7 @@ func TestCreateInvoice(t *testing.T) {
 			PaymentMethodID: "invalid-uuid",
 		}
 
-		service := newInvoiceServiceImpl(repo)
+		service := newInvoiceServiceImpl(repo, taxRepo, currencyRepo, nil)
 		invoiceID, err := service.CreateInvoice(ctx, invalidRequest)
 		require.Error(t, err)
 		require.Equal(t, uuid.Nil, invoiceID)
@@ -582,6 +1017,7 @@ func TestCreateInvoice(t *testing.T) {
 			Invoice: models.InvoiceInfo{
 				SenderID:  uuid.New().String(),
 				Status:    string(models.InvoiceStatusPending),
+				Currency:  "NGN",
 				IssueDate: "2023-05-01",
 				DueDate:   "2023-05-31",
 			},
@@ -590,14 +1026,46 @@ func TestCreateInvoice(t *testing.T) {
 		}
 
 		expectedError := errors.New("repository error")
+		currencyRepo.EXPECT().
+			GetBaseCurrency(gomock.Any(), gomock.Any()).
+			Return("NGN", nil)
 		repo.EXPECT().
-			CreateInvoice(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			CreateInvoice(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 			Return(uuid.Nil, expectedError)
 
-		service := newInvoiceServiceImpl(repo)
+		service := newInvoiceServiceImpl(repo, taxRepo, currencyRepo, nil)
 		invoiceID, err := service.CreateInvoice(ctx, validRequest)
 		require.Error(t, err)
 		require.Equal(t, uuid.Nil, invoiceID)
 		require.Equal(t, expectedError, err)
 	})
 }
+
+func TestMarkOverdueInvoices(t *testing.T) {
+	ctx := context.Background()
+	asOf := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockInvoiceRepository(ctrl)
+	service := newInvoiceServiceImpl(repo, nil, nil, nil)
+
+	t.Run("marks batches until none are left", func(t *testing.T) {
+		gomock.InOrder(
+			repo.EXPECT().MarkOverdueInvoices(gomock.Any(), asOf, int32(overdueBatchSize)).Return(overdueBatchSize, nil),
+			repo.EXPECT().MarkOverdueInvoices(gomock.Any(), asOf, int32(overdueBatchSize)).Return(3, nil),
+		)
+
+		marked, err := service.MarkOverdueInvoices(ctx, asOf)
+		require.NoError(t, err)
+		require.Equal(t, overdueBatchSize+3, marked)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repo.EXPECT().MarkOverdueInvoices(gomock.Any(), asOf, gomock.Any()).Return(0, errors.New("database error"))
+
+		marked, err := service.MarkOverdueInvoices(ctx, asOf)
+		require.Error(t, err)
+		require.Zero(t, marked)
+	})
+}
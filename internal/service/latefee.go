@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zde37/Numeris-Task/internal/helpers"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/repository"
+)
+
+// lateFeeBatchSize is the number of overdue invoices loaded at a time while applying late fees.
+const lateFeeBatchSize = 100
+
+// daysPerInterestMonth is the length of a month when charging monthly interest.
+const daysPerInterestMonth = 30
+
+type lateFeeServiceImpl struct {
+	lateFee repository.LateFeeRepository
+}
+
+// newLateFeeServiceImpl creates a new instance of the lateFeeServiceImpl struct, which implements the LateFeeService interface.
+// It takes a LateFeeRepository implementation as a dependency.
+func newLateFeeServiceImpl(lateFee repository.LateFeeRepository) *lateFeeServiceImpl {
+	return &lateFeeServiceImpl{
+		lateFee: lateFee,
+	}
+}
+
+// SetLateFeePolicy creates or replaces the late fee policy of the given user. Policies are active unless explicitly disabled.
+func (s *lateFeeServiceImpl) SetLateFeePolicy(ctx context.Context, data models.SetLateFeePolicyRequest) (uuid.UUID, error) {
+	userID, err := uuid.Parse(data.UserID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid user id")
+	}
+
+	if err := helpers.ValidateLateFeePolicy(data); err != nil {
+		return uuid.Nil, err
+	}
+
+	isActive := true
+	if data.IsActive != nil {
+		isActive = *data.IsActive
+	}
+
+	return s.lateFee.SetLateFeePolicy(ctx, models.LateFeePolicy{
+		PolicyID:      uuid.New(),
+		UserID:        userID,
+		FeeType:       models.LateFeeType(data.FeeType),
+		FlatAmount:    data.FlatAmount,
+		InterestRate:  data.InterestRate,
+		GraceDays:     data.GraceDays,
+		MaxAmount:     data.MaxAmount,
+		MaxPercentage: data.MaxPercentage,
+		IsActive:      isActive,
+	})
+}
+
+// GetLateFeePolicy retrieves the late fee policy of the given user.
+func (s *lateFeeServiceImpl) GetLateFeePolicy(ctx context.Context, userID uuid.UUID) (*models.LateFeePolicy, error) {
+	return s.lateFee.GetLateFeePolicy(ctx, userID)
+}
+
+// ApplyLateFees brings the late fees and interest of every overdue invoice up to date as of the given time.
+// Each run only adds the difference between what the policy allows so far and what was already applied,
+// so running it repeatedly never charges twice. It returns the number of adjustments applied.
+func (s *lateFeeServiceImpl) ApplyLateFees(ctx context.Context, asOf time.Time) (int, error) {
+	applied := 0
+	var errs []error
+	after := uuid.Nil
+
+	for {
+		candidates, err := s.lateFee.GetLateFeeCandidates(ctx, asOf, after, lateFeeBatchSize)
+		if err != nil {
+			return applied, err
+		}
+
+		for _, candidate := range candidates {
+			amount := helpers.RoundAmount(calculateLateFee(candidate.Policy, candidate.Invoice, asOf) - candidate.AppliedAmount)
+			if amount <= 0 {
+				continue
+			}
+
+			adjustment := newLateFeeAdjustment(candidate, amount, asOf)
+			activity := models.NewInvoiceActivity(candidate.Invoice.InvoiceID, candidate.Invoice.SenderID,
+				models.ActivityEventLateFeeApplied,
+				models.LateFeeAppliedPayload{
+					AdjustmentID: adjustment.AdjustmentID, AdjustmentType: adjustment.AdjustmentType, Amount: adjustment.Amount,
+				},
+				"Late Fee Applied", adjustment.Description)
+			ok, err := s.lateFee.ApplyLateFee(ctx, adjustment, activity)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invoice %s: %w", candidate.Invoice.InvoiceNumber, err))
+				continue
+			}
+			if ok {
+				applied++
+			}
+		}
+
+		if len(candidates) < lateFeeBatchSize {
+			break
+		}
+		after = candidates[len(candidates)-1].Invoice.InvoiceID
+	}
+
+	return applied, errors.Join(errs...)
+}
+
+// calculateLateFee returns the total late fee or interest the policy allows for the invoice as of the given time,
+// capped by the policy's limits. Interest is simple interest on the invoice's final amount, counted from the end
+// of the grace period; monthly interest is charged for each started month.
+func calculateLateFee(policy models.LateFeePolicy, invoice models.Invoice, asOf time.Time) float64 {
+	daysLate := helpers.DaysBetween(invoice.DueDate, asOf) - policy.GraceDays
+	if daysLate <= 0 {
+		return 0
+	}
+
+	var fee float64
+	switch policy.FeeType {
+	case models.LateFeeTypeFlat:
+		fee = policy.FlatAmount
+	case models.LateFeeTypeDailyInterest:
+		fee = invoice.FinalAmount * policy.InterestRate / 100 * float64(daysLate)
+	case models.LateFeeTypeMonthlyInterest:
+		months := (daysLate + daysPerInterestMonth - 1) / daysPerInterestMonth
+		fee = invoice.FinalAmount * policy.InterestRate / 100 * float64(months)
+	}
+
+	if policy.MaxAmount != nil {
+		fee = math.Min(fee, *policy.MaxAmount)
+	}
+	if policy.MaxPercentage != nil {
+		fee = math.Min(fee, invoice.FinalAmount**policy.MaxPercentage/100)
+	}
+	return helpers.RoundAmount(fee)
+}
+
+// newLateFeeAdjustment builds the system generated adjustment recording a late fee or interest charge.
+func newLateFeeAdjustment(candidate models.LateFeeCandidate, amount float64, asOf time.Time) models.InvoiceAdjustment {
+	adjustmentType := models.AdjustmentTypeInterest
+	description := fmt.Sprintf("Interest of %s %.2f charged on overdue invoice %s (%.3f%% %s)", candidate.Invoice.Currency, amount,
+		candidate.Invoice.InvoiceNumber, candidate.Policy.InterestRate, candidate.Policy.FeeType)
+	if candidate.Policy.FeeType == models.LateFeeTypeFlat {
+		adjustmentType = models.AdjustmentTypeLateFee
+		description = fmt.Sprintf("Late fee of %s %.2f charged on overdue invoice %s", candidate.Invoice.Currency, amount,
+			candidate.Invoice.InvoiceNumber)
+	}
+
+	return models.InvoiceAdjustment{
+		AdjustmentID:   uuid.New(),
+		InvoiceID:      candidate.Invoice.InvoiceID,
+		PolicyID:       candidate.Policy.PolicyID,
+		AdjustmentType: adjustmentType,
+		Amount:         amount,
+		Description:    description,
+		AppliedOn:      time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC),
+	}
+}
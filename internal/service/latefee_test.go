@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSetLateFeePolicy(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockLateFeeRepository(ctrl)
+	userID := uuid.New()
+
+	t.Run("successful policy creation", func(t *testing.T) {
+		expectedPolicyID := uuid.New()
+		repo.EXPECT().
+			SetLateFeePolicy(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, policy models.LateFeePolicy) (uuid.UUID, error) {
+				require.Equal(t, userID, policy.UserID)
+				require.Equal(t, models.LateFeeTypeFlat, policy.FeeType)
+				require.Equal(t, 25.0, policy.FlatAmount)
+				require.True(t, policy.IsActive)
+				return expectedPolicyID, nil
+			})
+
+		service := newLateFeeServiceImpl(repo)
+		policyID, err := service.SetLateFeePolicy(ctx, models.SetLateFeePolicyRequest{
+			UserID:     userID.String(),
+			FeeType:    string(models.LateFeeTypeFlat),
+			FlatAmount: 25,
+		})
+		require.NoError(t, err)
+		require.Equal(t, expectedPolicyID, policyID)
+	})
+
+	t.Run("disabled policy", func(t *testing.T) {
+		isActive := false
+		repo.EXPECT().
+			SetLateFeePolicy(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, policy models.LateFeePolicy) (uuid.UUID, error) {
+				require.False(t, policy.IsActive)
+				return policy.PolicyID, nil
+			})
+
+		service := newLateFeeServiceImpl(repo)
+		_, err := service.SetLateFeePolicy(ctx, models.SetLateFeePolicyRequest{
+			UserID:       userID.String(),
+			FeeType:      string(models.LateFeeTypeDailyInterest),
+			InterestRate: 0.1,
+			IsActive:     &isActive,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid user id", func(t *testing.T) {
+		service := newLateFeeServiceImpl(repo)
+		policyID, err := service.SetLateFeePolicy(ctx, models.SetLateFeePolicyRequest{UserID: "invalid", FeeType: "flat", FlatAmount: 10})
+		require.Error(t, err)
+		require.Equal(t, uuid.Nil, policyID)
+		require.Contains(t, err.Error(), "invalid user id")
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		service := newLateFeeServiceImpl(repo)
+		_, err := service.SetLateFeePolicy(ctx, models.SetLateFeePolicyRequest{UserID: userID.String(), FeeType: "monthly_interest"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "interest_rate")
+	})
+}
+
+func TestApplyLateFees(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockLateFeeRepository(ctrl)
+	asOf := time.Date(2024, 3, 31, 9, 0, 0, 0, time.UTC)
+
+	newCandidate := func(number string, applied float64) models.LateFeeCandidate {
+		return models.LateFeeCandidate{
+			Invoice: models.Invoice{
+				InvoiceID:     uuid.New(),
+				InvoiceNumber: number,
+				SenderID:      uuid.New(),
+				DueDate:       asOf.AddDate(0, 0, -10),
+				FinalAmount:   1000,
+				Currency:      "NGN",
+			},
+			Policy: models.LateFeePolicy{
+				PolicyID:     uuid.New(),
+				FeeType:      models.LateFeeTypeDailyInterest,
+				InterestRate: 1,
+				IsActive:     true,
+			},
+			AppliedAmount: applied,
+		}
+	}
+
+	t.Run("applies the outstanding difference", func(t *testing.T) {
+		fresh := newCandidate("INV-001", 0)
+		partial := newCandidate("INV-002", 90)
+		settled := newCandidate("INV-003", 100)
+
+		repo.EXPECT().
+			GetLateFeeCandidates(gomock.Any(), asOf, uuid.Nil, int32(lateFeeBatchSize)).
+			Return([]models.LateFeeCandidate{fresh, partial, settled}, nil)
+		repo.EXPECT().
+			ApplyLateFee(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, adjustment models.InvoiceAdjustment, activity models.InvoiceActivity) (bool, error) {
+				require.Equal(t, fresh.Invoice.InvoiceID, adjustment.InvoiceID)
+				require.Equal(t, fresh.Policy.PolicyID, adjustment.PolicyID)
+				require.Equal(t, models.AdjustmentTypeInterest, adjustment.AdjustmentType)
+				require.Equal(t, 100.0, adjustment.Amount)
+				require.Equal(t, time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), adjustment.AppliedOn)
+				require.Equal(t, fresh.Invoice.SenderID, activity.UserID)
+				require.Equal(t, "Late Fee Applied", activity.Title)
+				require.Equal(t, models.ActivityEventLateFeeApplied, activity.EventType)
+				var payload models.LateFeeAppliedPayload
+				require.NoError(t, json.Unmarshal(activity.Payload, &payload))
+				require.Equal(t, models.LateFeeAppliedPayload{
+					AdjustmentID: adjustment.AdjustmentID, AdjustmentType: models.AdjustmentTypeInterest, Amount: 100,
+				}, payload)
+				return true, nil
+			})
+		repo.EXPECT().
+			ApplyLateFee(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, adjustment models.InvoiceAdjustment, _ models.InvoiceActivity) (bool, error) {
+				require.Equal(t, partial.Invoice.InvoiceID, adjustment.InvoiceID)
+				require.Equal(t, 10.0, adjustment.Amount)
+				return false, nil
+			})
+
+		service := newLateFeeServiceImpl(repo)
+		applied, err := service.ApplyLateFees(ctx, asOf)
+		require.NoError(t, err)
+		require.Equal(t, 1, applied)
+	})
+
+	t.Run("pages through candidates", func(t *testing.T) {
+		batch := make([]models.LateFeeCandidate, lateFeeBatchSize)
+		for i := range batch {
+			batch[i] = newCandidate("INV-100", 100)
+		}
+		last := batch[len(batch)-1].Invoice.InvoiceID
+
+		gomock.InOrder(
+			repo.EXPECT().GetLateFeeCandidates(gomock.Any(), asOf, uuid.Nil, int32(lateFeeBatchSize)).Return(batch, nil),
+			repo.EXPECT().GetLateFeeCandidates(gomock.Any(), asOf, last, int32(lateFeeBatchSize)).Return([]models.LateFeeCandidate{}, nil),
+		)
+
+		service := newLateFeeServiceImpl(repo)
+		applied, err := service.ApplyLateFees(ctx, asOf)
+		require.NoError(t, err)
+		require.Zero(t, applied)
+	})
+
+	t.Run("failed adjustments are reported", func(t *testing.T) {
+		repo.EXPECT().
+			GetLateFeeCandidates(gomock.Any(), asOf, uuid.Nil, int32(lateFeeBatchSize)).
+			Return([]models.LateFeeCandidate{newCandidate("INV-004", 0), newCandidate("INV-005", 0)}, nil)
+		repo.EXPECT().ApplyLateFee(gomock.Any(), gomock.Any(), gomock.Any()).Return(false, errors.New("db error"))
+		repo.EXPECT().ApplyLateFee(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil)
+
+		service := newLateFeeServiceImpl(repo)
+		applied, err := service.ApplyLateFees(ctx, asOf)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "INV-004")
+		require.Equal(t, 1, applied)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repo.EXPECT().
+			GetLateFeeCandidates(gomock.Any(), asOf, uuid.Nil, int32(lateFeeBatchSize)).
+			Return(nil, errors.New("db error"))
+
+		service := newLateFeeServiceImpl(repo)
+		applied, err := service.ApplyLateFees(ctx, asOf)
+		require.Error(t, err)
+		require.Zero(t, applied)
+	})
+}
+
+func TestCalculateLateFee(t *testing.T) {
+	asOf := time.Date(2024, 3, 31, 18, 0, 0, 0, time.UTC)
+	invoice := models.Invoice{DueDate: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), FinalAmount: 2000}
+	maxAmount, maxPercentage := 50.0, 5.0
+
+	tests := []struct {
+		name   string
+		policy models.LateFeePolicy
+		want   float64
+	}{
+		{"flat fee", models.LateFeePolicy{FeeType: models.LateFeeTypeFlat, FlatAmount: 25}, 25},
+		{"within grace period", models.LateFeePolicy{FeeType: models.LateFeeTypeFlat, FlatAmount: 25, GraceDays: 30}, 0},
+		{"daily interest", models.LateFeePolicy{FeeType: models.LateFeeTypeDailyInterest, InterestRate: 0.1}, 60},
+		{"daily interest after grace", models.LateFeePolicy{FeeType: models.LateFeeTypeDailyInterest, InterestRate: 0.1, GraceDays: 10}, 40},
+		{"monthly interest counts started months", models.LateFeePolicy{FeeType: models.LateFeeTypeMonthlyInterest, InterestRate: 1.5}, 30},
+		{"monthly interest first month", models.LateFeePolicy{FeeType: models.LateFeeTypeMonthlyInterest, InterestRate: 1.5, GraceDays: 25}, 30},
+		{"capped by amount", models.LateFeePolicy{FeeType: models.LateFeeTypeDailyInterest, InterestRate: 0.1, MaxAmount: &maxAmount}, 50},
+		{"capped by percentage", models.LateFeePolicy{FeeType: models.LateFeeTypeFlat, FlatAmount: 500, MaxPercentage: &maxPercentage}, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, calculateLateFee(tt.policy, invoice, asOf))
+		})
+	}
+}
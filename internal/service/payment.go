@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zde37/Numeris-Task/internal/gateway"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/repository"
+)
+
+// ErrPaymentsNotConfigured is returned when invoices are to be paid online but no payment provider is configured.
+var ErrPaymentsNotConfigured = errors.New("no payment provider configured")
+
+// ErrInvoiceNotPayable is returned when a payment link is requested for an invoice that cannot be paid.
+var ErrInvoiceNotPayable = errors.New("invoice cannot be paid")
+
+const (
+	// refundBatchSize is the number of pending refunds claimed at a time while sending them again.
+	refundBatchSize = 10
+	// refundLease is how long a refund is kept from being sent again once it is requested or claimed, while it is sent
+	// to the payment provider and its outcome recorded.
+	refundLease = 15 * time.Minute
+)
+
+type paymentServiceImpl struct {
+	payment  repository.PaymentRepository
+	provider gateway.PaymentProvider
+}
+
+// newPaymentServiceImpl creates a new instance of the paymentServiceImpl struct, which implements the PaymentService interface.
+// It takes a PaymentRepository implementation and the provider invoices are paid online with as dependencies.
+// The provider may be nil when invoices cannot be paid online.
+func newPaymentServiceImpl(payment repository.PaymentRepository, provider gateway.PaymentProvider) *paymentServiceImpl {
+	return &paymentServiceImpl{
+		payment:  payment,
+		provider: provider,
+	}
+}
+
+// RecordPayment records a payment received on an invoice of the given user.
+func (s *paymentServiceImpl) RecordPayment(ctx context.Context, data models.RecordPaymentRequest) (uuid.UUID, error) {
+	invoiceID, err := uuid.Parse(data.InvoiceID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid invoice id")
+	}
+	userID, err := uuid.Parse(data.UserID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid user id")
+	}
+
+	if data.Amount <= 0 {
+		return uuid.Nil, fmt.Errorf("amount must be greater than 0")
+	}
+	paidOn, err := time.Parse("2006-01-02", data.PaidOn)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("paid on date has invalid date format")
+	}
+
+	return s.payment.RecordPayment(ctx, userID, models.Payment{
+		PaymentID: uuid.New(),
+		InvoiceID: invoiceID,
+		Amount:    data.Amount,
+		PaidOn:    paidOn,
+		Reference: data.Reference,
+	})
+}
+
+// CreatePaymentLink creates a checkout session with the payment provider, on which the customer of an invoice of the
+// given user pays the balance due online.
+func (s *paymentServiceImpl) CreatePaymentLink(ctx context.Context, userID, invoiceID uuid.UUID) (*models.PaymentSession, error) {
+	if s.provider == nil {
+		return nil, ErrPaymentsNotConfigured
+	}
+
+	invoice, err := s.payment.GetPayableInvoice(ctx, userID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice == nil {
+		return nil, models.ErrInvoiceNotFound
+	}
+	switch invoice.Status {
+	case models.InvoiceStatusDraft:
+		return nil, fmt.Errorf("%w: invoice is a draft", ErrInvoiceNotPayable)
+	case models.InvoiceStatusPaid:
+		return nil, fmt.Errorf("%w: invoice is already paid", ErrInvoiceNotPayable)
+	}
+	if invoice.BalanceDue <= 0 {
+		return nil, fmt.Errorf("%w: invoice has no balance due", ErrInvoiceNotPayable)
+	}
+
+	checkout, err := s.provider.CreateCheckoutSession(ctx, gateway.CheckoutRequest{
+		InvoiceID:     invoice.InvoiceID,
+		InvoiceNumber: invoice.InvoiceNumber,
+		CustomerEmail: invoice.CustomerEmail,
+		Amount:        invoice.BalanceDue,
+		Currency:      invoice.Currency,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create checkout session: %w", err)
+	}
+
+	session := models.PaymentSession{
+		PaymentSessionID:  uuid.New(),
+		InvoiceID:         invoice.InvoiceID,
+		Provider:          s.provider.Name(),
+		ProviderSessionID: checkout.SessionID,
+		URL:               checkout.URL,
+		Amount:            invoice.BalanceDue,
+		Currency:          invoice.Currency,
+		Status:            models.PaymentSessionStatusOpen,
+		ExpiresAt:         checkout.ExpiresAt,
+	}
+	if err := s.payment.CreatePaymentSession(ctx, session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// HandlePaymentCallback verifies a callback of the payment provider about a checkout session, and records the payment
+// it reports on the invoice of the session, which is marked as paid once settled. Failed sessions are marked as
+// failed, and callbacks repeated for a paid session are ignored.
+func (s *paymentServiceImpl) HandlePaymentCallback(ctx context.Context, payload []byte, header http.Header) error {
+	if s.provider == nil {
+		return ErrPaymentsNotConfigured
+	}
+
+	event, err := s.provider.HandleCallback(ctx, payload, header)
+	if err != nil {
+		return err
+	}
+
+	session, err := s.payment.GetPaymentSession(ctx, s.provider.Name(), event.SessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		return models.ErrPaymentSessionNotFound
+	}
+
+	if event.Status == gateway.CallbackStatusFailed {
+		return s.payment.FailPaymentSession(ctx, session.PaymentSessionID)
+	}
+	if event.Currency != session.Currency {
+		return fmt.Errorf("%w: paid in %s instead of %s", gateway.ErrInvalidCallback, event.Currency, session.Currency)
+	}
+
+	paidOn := event.PaidAt
+	if paidOn.IsZero() {
+		paidOn = time.Now()
+	}
+	reference := event.Reference
+	if reference == "" {
+		reference = event.SessionID
+	}
+
+	_, err = s.payment.CompletePaymentSession(ctx, session.PaymentSessionID, models.Payment{
+		PaymentID: uuid.New(),
+		Amount:    event.Amount,
+		PaidOn:    paidOn,
+		Reference: reference,
+	})
+	return err
+}
+
+// RefundPayment refunds an amount of a payment made online on an invoice of the given user through the payment
+// provider, or the whole part of it not refunded or being refunded yet when the amount is 0. The refund is recorded as
+// pending before it is sent, and is completed once the provider made it. A refund whose outcome is unknown, because
+// the provider could not be reached or the outcome could not be recorded, is returned pending, and is sent again by
+// SendPendingRefunds with the same idempotency key. A refund the provider rejected is failed, and its error wraps
+// gateway.ErrRefundRejected.
+func (s *paymentServiceImpl) RefundPayment(ctx context.Context, userID, paymentID uuid.UUID, amount float64) (*models.PaymentRefund, error) {
+	if s.provider == nil {
+		return nil, ErrPaymentsNotConfigured
+	}
+	if amount < 0 {
+		return nil, fmt.Errorf("amount must be greater than 0")
+	}
+
+	refund, err := s.payment.CreatePaymentRefund(ctx, userID, paymentID, amount, s.provider.Name(), time.Now().Add(refundLease))
+	if err != nil {
+		return nil, err
+	}
+
+	sent, err := s.sendRefund(ctx, *refund)
+	if errors.Is(err, gateway.ErrRefundRejected) {
+		return nil, err
+	}
+	if err != nil {
+		return refund, nil
+	}
+	return sent, nil
+}
+
+// SendPendingRefunds sends every pending refund due to be sent again as of the given time to the payment provider,
+// with the same idempotency key as before, and returns the number of refunds completed. Refunds the provider rejects
+// are failed, and refunds that cannot be sent are reported in the returned error and sent again once their lease runs
+// out.
+func (s *paymentServiceImpl) SendPendingRefunds(ctx context.Context, asOf time.Time) (int, error) {
+	if s.provider == nil {
+		return 0, nil
+	}
+
+	completed := 0
+	var errs []error
+	for {
+		refunds, err := s.payment.ClaimPendingPaymentRefunds(ctx, asOf, refundLease, refundBatchSize)
+		if err != nil {
+			return completed, err
+		}
+
+		for _, refund := range refunds {
+			sent, err := s.sendRefund(ctx, refund)
+			if ctx.Err() != nil {
+				// the claim runs out and the refund is sent again on the next run
+				return completed, errors.Join(errs...)
+			}
+			if err != nil && !errors.Is(err, gateway.ErrRefundRejected) {
+				errs = append(errs, fmt.Errorf("refund %s: %w", refund.RefundID, err))
+				continue
+			}
+			if sent.Status == models.PaymentRefundStatusCompleted {
+				completed++
+			}
+		}
+
+		if len(refunds) < refundBatchSize {
+			return completed, errors.Join(errs...)
+		}
+	}
+}
+
+// sendRefund sends a pending refund to the payment provider with its ID as the idempotency key, and records the
+// refund as completed once the provider made it, or as failed when the provider rejected it, in which case the
+// returned error wraps gateway.ErrRefundRejected. The refund is left pending on any other error.
+func (s *paymentServiceImpl) sendRefund(ctx context.Context, refund models.PaymentRefund) (*models.PaymentRefund, error) {
+	if refund.Provider != s.provider.Name() {
+		return nil, fmt.Errorf("refund was made with the %s provider", refund.Provider)
+	}
+
+	made, err := s.provider.Refund(ctx, gateway.RefundRequest{
+		IdempotencyKey: refund.RefundID.String(),
+		Reference:      refund.PaymentReference,
+		Amount:         refund.Amount,
+		Currency:       refund.Currency,
+	})
+	if errors.Is(err, gateway.ErrRefundRejected) {
+		failed, failErr := s.payment.FailPaymentRefund(ctx, refund.RefundID, err.Error())
+		if failErr != nil {
+			return nil, failErr
+		}
+		return failed, fmt.Errorf("failed to refund payment: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to refund payment: %w", err)
+	}
+
+	return s.payment.CompletePaymentRefund(ctx, refund.RefundID, made.RefundID)
+}
@@ -0,0 +1,454 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Numeris-Task/internal/gateway"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRecordPayment(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockPaymentRepository(ctrl)
+	invoiceID, userID := uuid.New(), uuid.New()
+
+	t.Run("successful payment", func(t *testing.T) {
+		expectedPaymentID := uuid.New()
+		repo.EXPECT().
+			RecordPayment(gomock.Any(), userID, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uuid.UUID, payment models.Payment) (uuid.UUID, error) {
+				require.Equal(t, invoiceID, payment.InvoiceID)
+				require.Equal(t, 250.5, payment.Amount)
+				require.Equal(t, time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC), payment.PaidOn)
+				require.Equal(t, "TRF-001", payment.Reference)
+				return expectedPaymentID, nil
+			})
+
+		service := newPaymentServiceImpl(repo, nil)
+		paymentID, err := service.RecordPayment(ctx, models.RecordPaymentRequest{
+			InvoiceID: invoiceID.String(),
+			UserID:    userID.String(),
+			Amount:    250.5,
+			PaidOn:    "2024-02-15",
+			Reference: "TRF-001",
+		})
+		require.NoError(t, err)
+		require.Equal(t, expectedPaymentID, paymentID)
+	})
+
+	t.Run("invalid invoice id", func(t *testing.T) {
+		service := newPaymentServiceImpl(repo, nil)
+		paymentID, err := service.RecordPayment(ctx, models.RecordPaymentRequest{
+			InvoiceID: "invalid", UserID: userID.String(), Amount: 10, PaidOn: "2024-02-15",
+		})
+		require.Error(t, err)
+		require.Equal(t, uuid.Nil, paymentID)
+		require.Contains(t, err.Error(), "invalid invoice id")
+	})
+
+	t.Run("invalid user id", func(t *testing.T) {
+		service := newPaymentServiceImpl(repo, nil)
+		_, err := service.RecordPayment(ctx, models.RecordPaymentRequest{
+			InvoiceID: invoiceID.String(), UserID: "invalid", Amount: 10, PaidOn: "2024-02-15",
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid user id")
+	})
+
+	t.Run("non positive amount", func(t *testing.T) {
+		service := newPaymentServiceImpl(repo, nil)
+		_, err := service.RecordPayment(ctx, models.RecordPaymentRequest{
+			InvoiceID: invoiceID.String(), UserID: userID.String(), Amount: -5, PaidOn: "2024-02-15",
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "amount must be greater than 0")
+	})
+
+	t.Run("invalid paid on date", func(t *testing.T) {
+		service := newPaymentServiceImpl(repo, nil)
+		_, err := service.RecordPayment(ctx, models.RecordPaymentRequest{
+			InvoiceID: invoiceID.String(), UserID: userID.String(), Amount: 10, PaidOn: "15-02-2024",
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "paid on date has invalid date format")
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repo.EXPECT().
+			RecordPayment(gomock.Any(), userID, gomock.Any()).
+			Return(uuid.Nil, models.ErrInvoiceAlreadyPaid)
+
+		service := newPaymentServiceImpl(repo, nil)
+		paymentID, err := service.RecordPayment(ctx, models.RecordPaymentRequest{
+			InvoiceID: invoiceID.String(), UserID: userID.String(), Amount: 10, PaidOn: "2024-02-15",
+		})
+		require.ErrorIs(t, err, models.ErrInvoiceAlreadyPaid)
+		require.Equal(t, uuid.Nil, paymentID)
+	})
+}
+
+func TestCreatePaymentLink(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockPaymentRepository(ctrl)
+	provider := gateway.NewFakeProvider("http://localhost:8080/", []byte("secret"))
+	invoiceID, userID := uuid.New(), uuid.New()
+	payable := func(status models.InvoiceStatus, balanceDue float64) *models.PayableInvoice {
+		return &models.PayableInvoice{
+			InvoiceID:     invoiceID,
+			InvoiceNumber: "INV-001",
+			Status:        status,
+			Currency:      "NGN",
+			CustomerEmail: "customer@example.com",
+			BalanceDue:    balanceDue,
+		}
+	}
+
+	t.Run("successful link", func(t *testing.T) {
+		repo.EXPECT().GetPayableInvoice(gomock.Any(), userID, invoiceID).Return(payable(models.InvoiceStatusPending, 120.5), nil)
+		repo.EXPECT().
+			CreatePaymentSession(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, session models.PaymentSession) error {
+				require.Equal(t, invoiceID, session.InvoiceID)
+				require.Equal(t, "fake", session.Provider)
+				require.Equal(t, 120.5, session.Amount)
+				require.Equal(t, "NGN", session.Currency)
+				require.Equal(t, models.PaymentSessionStatusOpen, session.Status)
+				return nil
+			})
+
+		service := newPaymentServiceImpl(repo, provider)
+		session, err := service.CreatePaymentLink(ctx, userID, invoiceID)
+		require.NoError(t, err)
+		require.Equal(t, "http://localhost:8080/checkout/"+session.ProviderSessionID, session.URL)
+		require.True(t, session.ExpiresAt.After(time.Now()))
+	})
+
+	t.Run("provider not configured", func(t *testing.T) {
+		service := newPaymentServiceImpl(repo, nil)
+		_, err := service.CreatePaymentLink(ctx, userID, invoiceID)
+		require.ErrorIs(t, err, ErrPaymentsNotConfigured)
+	})
+
+	t.Run("invoice not found", func(t *testing.T) {
+		repo.EXPECT().GetPayableInvoice(gomock.Any(), userID, invoiceID).Return(nil, nil)
+
+		service := newPaymentServiceImpl(repo, provider)
+		_, err := service.CreatePaymentLink(ctx, userID, invoiceID)
+		require.ErrorIs(t, err, models.ErrInvoiceNotFound)
+	})
+
+	for _, tc := range []struct {
+		name    string
+		invoice *models.PayableInvoice
+		message string
+	}{
+		{"draft invoice", payable(models.InvoiceStatusDraft, 100), "invoice is a draft"},
+		{"paid invoice", payable(models.InvoiceStatusPaid, 0), "invoice is already paid"},
+		{"no balance due", payable(models.InvoiceStatusPending, 0), "invoice has no balance due"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			repo.EXPECT().GetPayableInvoice(gomock.Any(), userID, invoiceID).Return(tc.invoice, nil)
+
+			service := newPaymentServiceImpl(repo, provider)
+			_, err := service.CreatePaymentLink(ctx, userID, invoiceID)
+			require.ErrorIs(t, err, ErrInvoiceNotPayable)
+			require.Contains(t, err.Error(), tc.message)
+		})
+	}
+}
+
+func TestHandlePaymentCallback(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockPaymentRepository(ctrl)
+	secret := []byte("secret")
+	provider := gateway.NewFakeProvider("http://localhost:8080", secret)
+	session := &models.PaymentSession{
+		PaymentSessionID:  uuid.New(),
+		InvoiceID:         uuid.New(),
+		Provider:          "fake",
+		ProviderSessionID: "fake_cs_1",
+		Amount:            150,
+		Currency:          "NGN",
+		Status:            models.PaymentSessionStatusOpen,
+	}
+	signed := func(payload string) ([]byte, http.Header) {
+		header := http.Header{}
+		header.Set(gateway.FakeSignatureHeader, gateway.SignFakeCallback(secret, []byte(payload)))
+		return []byte(payload), header
+	}
+
+	t.Run("paid session", func(t *testing.T) {
+		payload, header := signed(`{"session_id": "fake_cs_1", "status": "paid", "amount": 150, "currency": "ngn",
+			"reference": "PAY-1", "paid_at": "2024-06-01T10:00:00Z"}`)
+		repo.EXPECT().GetPaymentSession(gomock.Any(), "fake", "fake_cs_1").Return(session, nil)
+		repo.EXPECT().
+			CompletePaymentSession(gomock.Any(), session.PaymentSessionID, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uuid.UUID, payment models.Payment) (*models.PaymentSession, error) {
+				require.Equal(t, 150.0, payment.Amount)
+				require.Equal(t, "PAY-1", payment.Reference)
+				require.Equal(t, time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC), payment.PaidOn)
+				return session, nil
+			})
+
+		service := newPaymentServiceImpl(repo, provider)
+		require.NoError(t, service.HandlePaymentCallback(ctx, payload, header))
+	})
+
+	t.Run("failed session", func(t *testing.T) {
+		payload, header := signed(`{"session_id": "fake_cs_1", "status": "failed"}`)
+		repo.EXPECT().GetPaymentSession(gomock.Any(), "fake", "fake_cs_1").Return(session, nil)
+		repo.EXPECT().FailPaymentSession(gomock.Any(), session.PaymentSessionID).Return(nil)
+
+		service := newPaymentServiceImpl(repo, provider)
+		require.NoError(t, service.HandlePaymentCallback(ctx, payload, header))
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		_, header := signed(`{"session_id": "fake_cs_1", "status": "paid", "amount": 150, "currency": "NGN"}`)
+		payload := []byte(`{"session_id": "fake_cs_1", "status": "paid", "amount": 15000, "currency": "NGN"}`)
+
+		service := newPaymentServiceImpl(repo, provider)
+		err := service.HandlePaymentCallback(ctx, payload, header)
+		require.ErrorIs(t, err, gateway.ErrInvalidSignature)
+	})
+
+	t.Run("unknown session", func(t *testing.T) {
+		payload, header := signed(`{"session_id": "fake_cs_2", "status": "paid", "amount": 150, "currency": "NGN"}`)
+		repo.EXPECT().GetPaymentSession(gomock.Any(), "fake", "fake_cs_2").Return(nil, nil)
+
+		service := newPaymentServiceImpl(repo, provider)
+		err := service.HandlePaymentCallback(ctx, payload, header)
+		require.ErrorIs(t, err, models.ErrPaymentSessionNotFound)
+	})
+
+	t.Run("currency mismatch", func(t *testing.T) {
+		payload, header := signed(`{"session_id": "fake_cs_1", "status": "paid", "amount": 150, "currency": "USD"}`)
+		repo.EXPECT().GetPaymentSession(gomock.Any(), "fake", "fake_cs_1").Return(session, nil)
+
+		service := newPaymentServiceImpl(repo, provider)
+		err := service.HandlePaymentCallback(ctx, payload, header)
+		require.ErrorIs(t, err, gateway.ErrInvalidCallback)
+	})
+
+	t.Run("provider not configured", func(t *testing.T) {
+		service := newPaymentServiceImpl(repo, nil)
+		err := service.HandlePaymentCallback(ctx, []byte("{}"), http.Header{})
+		require.ErrorIs(t, err, ErrPaymentsNotConfigured)
+	})
+}
+
+// refundProvider is a payment provider whose refunds fail with err, and are made by the fake provider otherwise.
+type refundProvider struct {
+	gateway.PaymentProvider
+	err error
+}
+
+func (p *refundProvider) Refund(ctx context.Context, req gateway.RefundRequest) (*gateway.Refund, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.PaymentProvider.Refund(ctx, req)
+}
+
+func TestRefundPayment(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockPaymentRepository(ctrl)
+	fake := gateway.NewFakeProvider("http://localhost:8080", []byte("secret"))
+	userID, paymentID := uuid.New(), uuid.New()
+	pending := &models.PaymentRefund{
+		RefundID:         uuid.New(),
+		PaymentID:        paymentID,
+		InvoiceID:        uuid.New(),
+		UserID:           userID,
+		Provider:         "fake",
+		PaymentReference: "PAY-1",
+		Amount:           50,
+		Currency:         "NGN",
+		Status:           models.PaymentRefundStatusPending,
+	}
+	expectCreate := func(amount float64) {
+		repo.EXPECT().
+			CreatePaymentRefund(gomock.Any(), userID, paymentID, amount, "fake", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _ uuid.UUID, _ float64, _ string, retryAt time.Time) (*models.PaymentRefund, error) {
+				require.WithinDuration(t, time.Now().Add(refundLease), retryAt, time.Minute)
+				return pending, nil
+			})
+	}
+
+	t.Run("successful refund", func(t *testing.T) {
+		expectCreate(50)
+		repo.EXPECT().
+			CompletePaymentRefund(gomock.Any(), pending.RefundID, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uuid.UUID, providerRefundID string) (*models.PaymentRefund, error) {
+				require.Contains(t, providerRefundID, "fake_re_")
+				completed := *pending
+				completed.Status = models.PaymentRefundStatusCompleted
+				completed.ProviderRefundID = providerRefundID
+				return &completed, nil
+			})
+
+		service := newPaymentServiceImpl(repo, fake)
+		refund, err := service.RefundPayment(ctx, userID, paymentID, 50)
+		require.NoError(t, err)
+		require.Equal(t, models.PaymentRefundStatusCompleted, refund.Status)
+
+		// the provider refund is the one made with the refund's ID as the idempotency key
+		made, err := fake.Refund(ctx, gateway.RefundRequest{
+			IdempotencyKey: pending.RefundID.String(), Reference: "PAY-1", Amount: 50, Currency: "NGN",
+		})
+		require.NoError(t, err)
+		require.Equal(t, made.RefundID, refund.ProviderRefundID)
+	})
+
+	t.Run("refund rejected by the provider", func(t *testing.T) {
+		expectCreate(0)
+		repo.EXPECT().
+			FailPaymentRefund(gomock.Any(), pending.RefundID, gomock.Any()).
+			Return(&models.PaymentRefund{RefundID: pending.RefundID, Status: models.PaymentRefundStatusFailed}, nil)
+
+		provider := &refundProvider{PaymentProvider: fake, err: fmt.Errorf("%w: card expired", gateway.ErrRefundRejected)}
+		service := newPaymentServiceImpl(repo, provider)
+		_, err := service.RefundPayment(ctx, userID, paymentID, 0)
+		require.ErrorIs(t, err, gateway.ErrRefundRejected)
+	})
+
+	t.Run("provider unreachable", func(t *testing.T) {
+		expectCreate(50)
+
+		provider := &refundProvider{PaymentProvider: fake, err: errors.New("connection reset")}
+		service := newPaymentServiceImpl(repo, provider)
+		refund, err := service.RefundPayment(ctx, userID, paymentID, 50)
+		require.NoError(t, err)
+		require.Equal(t, models.PaymentRefundStatusPending, refund.Status)
+	})
+
+	t.Run("refund not recorded", func(t *testing.T) {
+		expectCreate(50)
+		repo.EXPECT().
+			CompletePaymentRefund(gomock.Any(), pending.RefundID, gomock.Any()).
+			Return(nil, errors.New("database error"))
+
+		service := newPaymentServiceImpl(repo, fake)
+		refund, err := service.RefundPayment(ctx, userID, paymentID, 50)
+		require.NoError(t, err)
+		require.Equal(t, models.PaymentRefundStatusPending, refund.Status)
+	})
+
+	t.Run("payment not found", func(t *testing.T) {
+		repo.EXPECT().
+			CreatePaymentRefund(gomock.Any(), userID, paymentID, 0.0, "fake", gomock.Any()).
+			Return(nil, models.ErrPaymentNotFound)
+
+		service := newPaymentServiceImpl(repo, fake)
+		_, err := service.RefundPayment(ctx, userID, paymentID, 0)
+		require.ErrorIs(t, err, models.ErrPaymentNotFound)
+	})
+
+	t.Run("negative amount", func(t *testing.T) {
+		service := newPaymentServiceImpl(repo, fake)
+		_, err := service.RefundPayment(ctx, userID, paymentID, -5)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "amount must be greater than 0")
+	})
+
+	t.Run("provider not configured", func(t *testing.T) {
+		service := newPaymentServiceImpl(repo, nil)
+		_, err := service.RefundPayment(ctx, userID, paymentID, 50)
+		require.ErrorIs(t, err, ErrPaymentsNotConfigured)
+	})
+}
+
+func TestSendPendingRefunds(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockPaymentRepository(ctrl)
+	fake := gateway.NewFakeProvider("http://localhost:8080", []byte("secret"))
+	asOf := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	newRefund := func() models.PaymentRefund {
+		return models.PaymentRefund{
+			RefundID:         uuid.New(),
+			PaymentID:        uuid.New(),
+			Provider:         "fake",
+			PaymentReference: "PAY-1",
+			Amount:           50,
+			Currency:         "NGN",
+			Status:           models.PaymentRefundStatusPending,
+		}
+	}
+
+	t.Run("completes the refunds the provider makes", func(t *testing.T) {
+		first, second := newRefund(), newRefund()
+		repo.EXPECT().
+			ClaimPendingPaymentRefunds(gomock.Any(), asOf, refundLease, int32(refundBatchSize)).
+			Return([]models.PaymentRefund{first, second}, nil)
+		for _, refund := range []models.PaymentRefund{first, second} {
+			completed := refund
+			completed.Status = models.PaymentRefundStatusCompleted
+			repo.EXPECT().CompletePaymentRefund(gomock.Any(), refund.RefundID, gomock.Any()).Return(&completed, nil)
+		}
+
+		service := newPaymentServiceImpl(repo, fake)
+		completed, err := service.SendPendingRefunds(ctx, asOf)
+		require.NoError(t, err)
+		require.Equal(t, 2, completed)
+	})
+
+	t.Run("reports refunds that cannot be sent", func(t *testing.T) {
+		refund := newRefund()
+		repo.EXPECT().
+			ClaimPendingPaymentRefunds(gomock.Any(), asOf, refundLease, int32(refundBatchSize)).
+			Return([]models.PaymentRefund{refund}, nil)
+
+		provider := &refundProvider{PaymentProvider: fake, err: errors.New("connection reset")}
+		service := newPaymentServiceImpl(repo, provider)
+		completed, err := service.SendPendingRefunds(ctx, asOf)
+		require.ErrorContains(t, err, refund.RefundID.String())
+		require.Zero(t, completed)
+	})
+
+	t.Run("fails the refunds the provider rejects", func(t *testing.T) {
+		refund := newRefund()
+		repo.EXPECT().
+			ClaimPendingPaymentRefunds(gomock.Any(), asOf, refundLease, int32(refundBatchSize)).
+			Return([]models.PaymentRefund{refund}, nil)
+		repo.EXPECT().
+			FailPaymentRefund(gomock.Any(), refund.RefundID, gomock.Any()).
+			Return(&models.PaymentRefund{RefundID: refund.RefundID, Status: models.PaymentRefundStatusFailed}, nil)
+
+		provider := &refundProvider{PaymentProvider: fake, err: gateway.ErrRefundRejected}
+		service := newPaymentServiceImpl(repo, provider)
+		completed, err := service.SendPendingRefunds(ctx, asOf)
+		require.NoError(t, err)
+		require.Zero(t, completed)
+	})
+
+	t.Run("provider not configured", func(t *testing.T) {
+		service := newPaymentServiceImpl(repo, nil)
+		completed, err := service.SendPendingRefunds(ctx, asOf)
+		require.NoError(t, err)
+		require.Zero(t, completed)
+	})
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/pagination"
+	"github.com/zde37/Numeris-Task/internal/repository"
+	"github.com/zde37/Numeris-Task/internal/statement"
+)
+
+const (
+	// matchScoreInvoiceNumber, matchScoreAmount and matchScoreCustomerName are what the invoice number appearing in
+	// the remittance text, the amount paying the balance due and the payer being the customer add to the score of
+	// an invoice a bank transaction may pay.
+	matchScoreInvoiceNumber = 50
+	matchScoreAmount        = 30
+	matchScoreCustomerName  = 20
+	// autoApplyScore is the lowest score of an invoice a bank transaction is applied to without confirmation: the
+	// invoice number together with the amount or the customer name.
+	autoApplyScore = matchScoreInvoiceNumber + matchScoreCustomerName
+	// minInvoiceNumberMatch is the shortest invoice number looked for in remittance texts, as shorter ones appear in
+	// them by chance.
+	minInvoiceNumberMatch = 4
+	// maxSuggestedInvoices is the largest number of invoices suggested for a bank transaction in the inbox.
+	maxSuggestedInvoices = 5
+	// maxPaymentReference is the length of the longest payment reference.
+	maxPaymentReference = 100
+)
+
+type reconciliationServiceImpl struct {
+	reconciliation repository.ReconciliationRepository
+}
+
+// newReconciliationServiceImpl creates a new instance of the reconciliationServiceImpl struct, which implements the
+// ReconciliationService interface. It takes a ReconciliationRepository implementation as a dependency.
+func newReconciliationServiceImpl(reconciliation repository.ReconciliationRepository) *reconciliationServiceImpl {
+	return &reconciliationServiceImpl{
+		reconciliation: reconciliation,
+	}
+}
+
+// invoiceMatch is an unpaid invoice a bank transaction may pay, by its index in the unpaid invoices.
+type invoiceMatch struct {
+	index int
+	score int
+}
+
+// ImportStatement imports the incoming credits of a bank statement of a user, in the format, or in the format
+// detected from the statement when empty. Each credit is matched against the unpaid invoices of the user in its
+// currency by the invoice number in the remittance text, the amount and the payer's name. Credits that match one
+// invoice confidently are applied to it as payments, and the others are queued in the reconciliation inbox with the
+// invoices they may pay. Credits imported before are skipped, so a statement that failed to import part way can be
+// imported again.
+func (s *reconciliationServiceImpl) ImportStatement(ctx context.Context, userID uuid.UUID, format string, file io.Reader) (*models.ReconciliationResult, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	statementFormat, credits, err := statement.Parse(data, statement.Format(strings.ToLower(format)))
+	if err != nil {
+		return nil, err
+	}
+
+	invoices, err := s.reconciliation.GetUnpaidInvoices(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ReconciliationResult{
+		Format:       string(statementFormat),
+		Credits:      len(credits),
+		Transactions: []models.BankTransaction{},
+	}
+	for _, credit := range credits {
+		credit.BankTransactionID = uuid.New()
+		credit.UserID = userID
+
+		matches := matchInvoices(credit, invoices)
+		credit.SuggestedInvoiceIDs = make([]uuid.UUID, 0, len(matches))
+		for _, match := range matches {
+			credit.SuggestedInvoiceIDs = append(credit.SuggestedInvoiceIDs, invoices[match.index].InvoiceID)
+		}
+		var payment *models.Payment
+		confident, ok := confidentMatch(credit, invoices, matches)
+		if ok {
+			applied := bankPayment(credit, invoices[confident].InvoiceID)
+			payment = &applied
+		}
+
+		stored, err := s.reconciliation.AddBankTransaction(ctx, credit, payment)
+		if err != nil {
+			return nil, err
+		}
+		if stored == nil {
+			result.Duplicates++
+			continue
+		}
+		if stored.Status == models.BankTransactionStatusApplied {
+			result.Applied++
+			// later credits are matched against what is left to pay
+			invoices[confident].BalanceDue -= stored.Amount
+		} else {
+			result.Pending++
+		}
+		result.Transactions = append(result.Transactions, *stored)
+	}
+	return result, nil
+}
+
+// matchInvoices scores the unpaid invoices in the currency of a bank transaction it may pay, and returns the ones
+// with a score, best first.
+func matchInvoices(transaction models.BankTransaction, invoices []models.PayableInvoice) []invoiceMatch {
+	remittance := normalizeMatchText(transaction.RemittanceInfo)
+	payer := normalizeMatchText(transaction.CounterpartyName)
+
+	matches := make([]invoiceMatch, 0)
+	for i, invoice := range invoices {
+		if invoice.Currency != transaction.Currency || invoice.BalanceDue <= 0 {
+			continue
+		}
+
+		score := 0
+		number := normalizeMatchText(invoice.InvoiceNumber)
+		if len(number) >= minInvoiceNumberMatch && strings.Contains(remittance, number) {
+			score += matchScoreInvoiceNumber
+		}
+		if math.Abs(invoice.BalanceDue-transaction.Amount) < 0.005 {
+			score += matchScoreAmount
+		}
+		customer := normalizeMatchText(invoice.CustomerName)
+		if payer != "" && customer != "" && (strings.Contains(payer, customer) || strings.Contains(customer, payer)) {
+			score += matchScoreCustomerName
+		}
+
+		if score > 0 {
+			matches = append(matches, invoiceMatch{index: i, score: score})
+		}
+	}
+
+	// invoices with the same score stay in order of their due dates
+	slices.SortStableFunc(matches, func(a, b invoiceMatch) int { return b.score - a.score })
+	if len(matches) > maxSuggestedInvoices {
+		matches = matches[:maxSuggestedInvoices]
+	}
+	return matches
+}
+
+// confidentMatch returns the index of the invoice a bank transaction is applied to without confirmation: the best
+// match, when it scores at least autoApplyScore, no other invoice scores as well, and the transaction does not pay
+// more than its balance due.
+func confidentMatch(transaction models.BankTransaction, invoices []models.PayableInvoice, matches []invoiceMatch) (int, bool) {
+	if len(matches) == 0 || matches[0].score < autoApplyScore {
+		return 0, false
+	}
+	if len(matches) > 1 && matches[1].score == matches[0].score {
+		return 0, false
+	}
+	best := matches[0].index
+	if transaction.Amount > invoices[best].BalanceDue+0.005 {
+		return 0, false
+	}
+	return best, true
+}
+
+// normalizeMatchText lower cases the text and drops everything but letters and digits from it, so invoice numbers
+// and names are found however they are punctuated.
+func normalizeMatchText(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, text)
+}
+
+// bankPayment returns the payment a bank transaction makes on an invoice. It is referenced by the bank's id of the
+// transaction, or by its remittance text when the statement gave it no id.
+func bankPayment(transaction models.BankTransaction, invoiceID uuid.UUID) models.Payment {
+	reference := transaction.ExternalID
+	if strings.HasPrefix(reference, "sha256:") {
+		reference = transaction.RemittanceInfo
+	}
+	if runes := []rune(reference); len(runes) > maxPaymentReference {
+		reference = string(runes[:maxPaymentReference])
+	}
+
+	return models.Payment{
+		PaymentID: uuid.New(),
+		InvoiceID: invoiceID,
+		Amount:    transaction.Amount,
+		PaidOn:    transaction.BookingDate,
+		Reference: reference,
+	}
+}
+
+// GetReconciliationInbox returns a page of the bank transactions of a user waiting to be confirmed or dismissed,
+// oldest booking first.
+func (s *reconciliationServiceImpl) GetReconciliationInbox(ctx context.Context, userID uuid.UUID, page pagination.Request) ([]models.BankTransaction, *pagination.Meta, error) {
+	if err := page.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return s.reconciliation.GetBankTransactions(ctx, userID, models.BankTransactionStatusPending, page)
+}
+
+// ConfirmBankTransaction records a bank transaction in the reconciliation inbox of a user as a payment on the invoice
+// in the request, which need not be one of the suggested invoices.
+func (s *reconciliationServiceImpl) ConfirmBankTransaction(ctx context.Context, userID, bankTransactionID uuid.UUID, data models.ConfirmBankTransactionRequest) (*models.BankTransaction, error) {
+	invoiceID, err := uuid.Parse(data.InvoiceID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid invoice id")
+	}
+
+	transaction, err := s.reconciliation.GetBankTransaction(ctx, userID, bankTransactionID)
+	if err != nil {
+		return nil, err
+	}
+	if transaction == nil {
+		return nil, models.ErrBankTransactionNotFound
+	}
+	if transaction.Status != models.BankTransactionStatusPending {
+		return nil, models.ErrBankTransactionReconciled
+	}
+
+	return s.reconciliation.ConfirmBankTransaction(ctx, userID, bankTransactionID, bankPayment(*transaction, invoiceID))
+}
+
+// DismissBankTransaction removes a bank transaction from the reconciliation inbox of a user without recording a
+// payment.
+func (s *reconciliationServiceImpl) DismissBankTransaction(ctx context.Context, userID, bankTransactionID uuid.UUID) error {
+	return s.reconciliation.DismissBankTransaction(ctx, userID, bankTransactionID)
+}
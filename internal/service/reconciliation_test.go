@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/statement"
+	"go.uber.org/mock/gomock"
+)
+
+func TestImportStatement(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockReconciliationRepository(ctrl)
+	userID := uuid.New()
+	acme := models.PayableInvoice{
+		InvoiceID: uuid.New(), InvoiceNumber: "INV-1001", Status: models.InvoiceStatusPending, Currency: "NGN",
+		CustomerName: "Acme Ltd", BalanceDue: 1000,
+	}
+	globexFirst := models.PayableInvoice{
+		InvoiceID: uuid.New(), InvoiceNumber: "INV-1002", Status: models.InvoiceStatusOverDue, Currency: "NGN",
+		CustomerName: "Globex", BalanceDue: 250,
+	}
+	globexSecond := models.PayableInvoice{
+		InvoiceID: uuid.New(), InvoiceNumber: "INV-1003", Status: models.InvoiceStatusPending, Currency: "NGN",
+		CustomerName: "Globex", BalanceDue: 250,
+	}
+	euro := models.PayableInvoice{
+		InvoiceID: uuid.New(), InvoiceNumber: "INV-1004", Status: models.InvoiceStatusPending, Currency: "EUR",
+		CustomerName: "Initech", BalanceDue: 80,
+	}
+
+	// stored returns what the repository stores for a transaction: applied when a payment is given
+	stored := func(_ context.Context, transaction models.BankTransaction, payment *models.Payment) (*models.BankTransaction, error) {
+		transaction.Status = models.BankTransactionStatusPending
+		if payment != nil {
+			transaction.Status = models.BankTransactionStatusApplied
+			transaction.InvoiceID, transaction.PaymentID = &payment.InvoiceID, &payment.PaymentID
+		}
+		return &transaction, nil
+	}
+
+	t.Run("matches credits to invoices", func(t *testing.T) {
+		data := "date,amount,currency,transaction_id,name,description\n" +
+			// the invoice number and the amount match, so it is applied
+			"2024-06-01,600,NGN,TX-1,ACME LTD,Part payment for inv 1001\n" +
+			"2024-06-02,400,NGN,TX-2,Acme Ltd,INV1001 balance\n" +
+			// the amount and name match two invoices equally, so it waits for confirmation
+			"2024-06-03,250,NGN,TX-3,Globex,June\n" +
+			// a credit in another currency than the invoice it names waits too
+			"2024-06-04,80,USD,TX-4,Initech,INV-1004\n" +
+			// a credit for more than the balance due is not applied
+			"2024-06-05,100,EUR,TX-5,Initech,INV-1004\n" +
+			"2024-06-06,10,NGN,TX-6,Somebody,Gift\n"
+
+		repo.EXPECT().GetUnpaidInvoices(gomock.Any(), userID).
+			Return([]models.PayableInvoice{acme, globexFirst, globexSecond, euro}, nil)
+		var payments []models.Payment
+		repo.EXPECT().
+			AddBankTransaction(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, transaction models.BankTransaction, payment *models.Payment) (*models.BankTransaction, error) {
+				require.Equal(t, userID, transaction.UserID)
+				if payment != nil {
+					payments = append(payments, *payment)
+				}
+				return stored(ctx, transaction, payment)
+			}).
+			Times(6)
+
+		service := newReconciliationServiceImpl(repo)
+		result, err := service.ImportStatement(ctx, userID, "", strings.NewReader(data))
+		require.NoError(t, err)
+		require.Equal(t, "csv", result.Format)
+		require.Equal(t, 6, result.Credits)
+		require.Equal(t, 2, result.Applied)
+		require.Equal(t, 4, result.Pending)
+		require.Len(t, result.Transactions, 6)
+
+		require.Len(t, payments, 2)
+		require.Equal(t, acme.InvoiceID, payments[0].InvoiceID)
+		require.Equal(t, 600.0, payments[0].Amount)
+		require.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), payments[0].PaidOn)
+		require.Equal(t, "TX-1", payments[0].Reference)
+		// the second part pays the balance left by the first
+		require.Equal(t, acme.InvoiceID, payments[1].InvoiceID)
+
+		require.Equal(t, []uuid.UUID{globexFirst.InvoiceID, globexSecond.InvoiceID}, result.Transactions[2].SuggestedInvoiceIDs)
+		require.Empty(t, result.Transactions[3].SuggestedInvoiceIDs)
+		require.Equal(t, []uuid.UUID{euro.InvoiceID}, result.Transactions[4].SuggestedInvoiceIDs)
+		require.Empty(t, result.Transactions[5].SuggestedInvoiceIDs)
+	})
+
+	t.Run("skips credits imported before", func(t *testing.T) {
+		repo.EXPECT().GetUnpaidInvoices(gomock.Any(), userID).Return([]models.PayableInvoice{}, nil)
+		repo.EXPECT().AddBankTransaction(gomock.Any(), gomock.Any(), nil).Return(nil, nil)
+
+		service := newReconciliationServiceImpl(repo)
+		result, err := service.ImportStatement(ctx, userID, "CSV", strings.NewReader("date,amount,currency\n2024-06-01,10,NGN\n"))
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Duplicates)
+		require.Empty(t, result.Transactions)
+	})
+
+	t.Run("invalid statement", func(t *testing.T) {
+		service := newReconciliationServiceImpl(repo)
+		_, err := service.ImportStatement(ctx, userID, "ofx", strings.NewReader("date,amount,currency\n"))
+		require.ErrorIs(t, err, statement.ErrInvalidStatement)
+	})
+}
+
+func TestConfirmBankTransaction(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockReconciliationRepository(ctrl)
+	userID, bankTransactionID, invoiceID := uuid.New(), uuid.New(), uuid.New()
+	transaction := &models.BankTransaction{
+		BankTransactionID: bankTransactionID,
+		UserID:            userID,
+		ExternalID:        "sha256:abc",
+		BookingDate:       time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		Amount:            150,
+		Currency:          "NGN",
+		RemittanceInfo:    "June invoice",
+		Status:            models.BankTransactionStatusPending,
+	}
+
+	t.Run("successful confirmation", func(t *testing.T) {
+		repo.EXPECT().GetBankTransaction(gomock.Any(), userID, bankTransactionID).Return(transaction, nil)
+		repo.EXPECT().
+			ConfirmBankTransaction(gomock.Any(), userID, bankTransactionID, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _ uuid.UUID, payment models.Payment) (*models.BankTransaction, error) {
+				require.Equal(t, invoiceID, payment.InvoiceID)
+				require.Equal(t, 150.0, payment.Amount)
+				require.Equal(t, transaction.BookingDate, payment.PaidOn)
+				require.Equal(t, "June invoice", payment.Reference)
+				return &models.BankTransaction{Status: models.BankTransactionStatusConfirmed}, nil
+			})
+
+		service := newReconciliationServiceImpl(repo)
+		confirmed, err := service.ConfirmBankTransaction(ctx, userID, bankTransactionID,
+			models.ConfirmBankTransactionRequest{InvoiceID: invoiceID.String()})
+		require.NoError(t, err)
+		require.Equal(t, models.BankTransactionStatusConfirmed, confirmed.Status)
+	})
+
+	t.Run("invalid invoice id", func(t *testing.T) {
+		service := newReconciliationServiceImpl(repo)
+		_, err := service.ConfirmBankTransaction(ctx, userID, bankTransactionID,
+			models.ConfirmBankTransactionRequest{InvoiceID: "invalid"})
+		require.ErrorContains(t, err, "invalid invoice id")
+	})
+
+	t.Run("unknown bank transaction", func(t *testing.T) {
+		repo.EXPECT().GetBankTransaction(gomock.Any(), userID, bankTransactionID).Return(nil, nil)
+
+		service := newReconciliationServiceImpl(repo)
+		_, err := service.ConfirmBankTransaction(ctx, userID, bankTransactionID,
+			models.ConfirmBankTransactionRequest{InvoiceID: invoiceID.String()})
+		require.ErrorIs(t, err, models.ErrBankTransactionNotFound)
+	})
+
+	t.Run("already reconciled", func(t *testing.T) {
+		dismissed := *transaction
+		dismissed.Status = models.BankTransactionStatusDismissed
+		repo.EXPECT().GetBankTransaction(gomock.Any(), userID, bankTransactionID).Return(&dismissed, nil)
+
+		service := newReconciliationServiceImpl(repo)
+		_, err := service.ConfirmBankTransaction(ctx, userID, bankTransactionID,
+			models.ConfirmBankTransactionRequest{InvoiceID: invoiceID.String()})
+		require.ErrorIs(t, err, models.ErrBankTransactionReconciled)
+	})
+}
+
+func TestNormalizeMatchText(t *testing.T) {
+	require.Equal(t, "inv1001", normalizeMatchText("INV-1001"))
+	require.Equal(t, "acmesonsltd", normalizeMatchText(" ACME & Sons, Ltd. "))
+	require.Equal(t, "", normalizeMatchText("--"))
+}
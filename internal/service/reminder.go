@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zde37/Numeris-Task/internal/helpers"
+	"github.com/zde37/Numeris-Task/internal/mailer"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/repository"
+)
+
+const (
+	// reminderBatchSize is the maximum number of reminders sent in a single run.
+	reminderBatchSize = 100
+	// reminderLease is how long claimed reminders are kept from other servers while a batch of them is sent.
+	reminderLease = time.Hour
+	// maxReminderAttempts is the number of times a reminder is sent before it fails for good.
+	maxReminderAttempts = 5
+	// reminderRetryDelay is the delay before a failed reminder is sent again, which doubles with every retry after it.
+	reminderRetryDelay = time.Hour
+)
+
+type reminderTemplate struct {
+	subject string
+	opening string
+	closing string
+}
+
+// reminderTemplates holds the wording for each reminder tone, from the mildest to the most severe.
+var reminderTemplates = map[models.ReminderTone]reminderTemplate{
+	models.ReminderToneFriendly: {
+		subject: "Reminder: invoice %s",
+		opening: "This is a friendly reminder about invoice %s, which has a balance of %s %.2f due.",
+		closing: "If you have already paid, please disregard this message. Thank you for your business!",
+	},
+	models.ReminderToneFirm: {
+		subject: "Payment overdue: invoice %s",
+		opening: "Our records show that invoice %s has not been paid yet, with a balance of %s %.2f due.",
+		closing: "Please arrange payment as soon as possible or let us know if there is an issue with the invoice.",
+	},
+	models.ReminderToneFinal: {
+		subject: "Final notice: invoice %s",
+		opening: "This is a final notice regarding invoice %s, which still has a balance of %s %.2f unpaid.",
+		closing: "Please settle the outstanding balance immediately to avoid further action.",
+	},
+}
+
+type reminderServiceImpl struct {
+	reminder repository.ReminderRepository
+	mailer   mailer.Mailer
+}
+
+// newReminderServiceImpl creates a new instance of the reminderServiceImpl struct, which implements the ReminderService interface.
+// It takes a ReminderRepository implementation and the Mailer used to deliver reminders as dependencies.
+func newReminderServiceImpl(reminder repository.ReminderRepository, mailer mailer.Mailer) *reminderServiceImpl {
+	return &reminderServiceImpl{
+		reminder: reminder,
+		mailer:   mailer,
+	}
+}
+
+// AddReminderRule creates a new reminder rule. When no tone is provided, the tone is picked from the rule's offset
+// so that reminders escalate the longer an invoice stays unpaid.
+func (s *reminderServiceImpl) AddReminderRule(ctx context.Context, data models.AddReminderRuleRequest) (uuid.UUID, error) {
+	userID, err := uuid.Parse(data.UserID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid user id")
+	}
+
+	tone := models.ReminderTone(data.Tone)
+	if tone == "" {
+		tone = defaultReminderTone(*data.DaysOffset)
+	} else if err := helpers.ValidateReminderTone(data.Tone); err != nil {
+		return uuid.Nil, err
+	}
+
+	return s.reminder.AddReminderRule(ctx, models.ReminderRule{
+		RuleID:     uuid.New(),
+		UserID:     userID,
+		DaysOffset: *data.DaysOffset,
+		Tone:       tone,
+	})
+}
+
+// GetReminderRules retrieves the reminder rules configured by the given user.
+func (s *reminderServiceImpl) GetReminderRules(ctx context.Context, userID uuid.UUID) ([]models.ReminderRule, error) {
+	return s.reminder.GetReminderRules(ctx, userID)
+}
+
+// DeleteReminderRule deletes the given reminder rule owned by the given user.
+func (s *reminderServiceImpl) DeleteReminderRule(ctx context.Context, userID, ruleID uuid.UUID) error {
+	return s.reminder.DeleteReminderRule(ctx, userID, ruleID)
+}
+
+// SendDueReminders emails the customers of every unpaid invoice that has reached a reminder rule as of the given
+// time and records each reminder as an invoice activity. It returns the number of reminders sent. Reminders are
+// claimed before they are sent, so every server may run this at once. Reminders that fail are reported in the
+// returned error and retried with a delay that doubles every time, until they fail for good after
+// maxReminderAttempts attempts, so they do not hold back the reminders of other invoices.
+func (s *reminderServiceImpl) SendDueReminders(ctx context.Context, asOf time.Time) (int, error) {
+	reminders, err := s.reminder.ClaimDueReminders(ctx, asOf, reminderLease, reminderBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var errs []error
+	for _, due := range reminders {
+		if err := s.mailer.Send(ctx, composeReminder(due, asOf)); err != nil {
+			errs = append(errs, fmt.Errorf("invoice %s: %w", due.Invoice.InvoiceNumber, err))
+			if err := s.reminder.RecordReminderFailure(ctx, due.ReminderID, err.Error(), nextReminderAttempt(due, asOf)); err != nil {
+				errs = append(errs, fmt.Errorf("invoice %s: %w", due.Invoice.InvoiceNumber, err))
+			}
+			continue
+		}
+
+		reminder := models.InvoiceReminder{
+			ReminderID: due.ReminderID,
+			InvoiceID:  due.Invoice.InvoiceID,
+			RuleID:     due.Rule.RuleID,
+			DaysOffset: due.Rule.DaysOffset,
+			Tone:       due.Rule.Tone,
+			SentTo:     due.CustomerEmail,
+		}
+		activity := models.NewInvoiceActivity(due.Invoice.InvoiceID, due.Invoice.SenderID, models.ActivityEventEmailSent,
+			models.EmailSentPayload{To: due.CustomerEmail, Kind: "reminder", Tone: due.Rule.Tone},
+			"Payment Reminder",
+			fmt.Sprintf("Sent %s reminder for invoice %s to %s", due.Rule.Tone, due.Invoice.InvoiceNumber, due.CustomerEmail))
+		if err := s.reminder.RecordReminder(ctx, reminder, activity); err != nil {
+			errs = append(errs, fmt.Errorf("invoice %s: %w", due.Invoice.InvoiceNumber, err))
+			continue
+		}
+		sent++
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+// nextReminderAttempt returns when a reminder that failed to send is sent again, or nil when it failed for good.
+func nextReminderAttempt(due models.DueReminder, asOf time.Time) *time.Time {
+	attempts := due.Attempts + 1
+	if attempts >= maxReminderAttempts {
+		return nil
+	}
+	next := asOf.Add(reminderRetryDelay << (attempts - 1))
+	return &next
+}
+
+// defaultReminderTone returns the tone used for a rule that does not specify one: friendly up to the due date,
+// firm for the first two weeks after it and final afterwards.
+func defaultReminderTone(daysOffset int) models.ReminderTone {
+	switch {
+	case daysOffset <= 0:
+		return models.ReminderToneFriendly
+	case daysOffset <= 14:
+		return models.ReminderToneFirm
+	default:
+		return models.ReminderToneFinal
+	}
+}
+
+// composeReminder builds the reminder email for a due invoice using the wording of the rule's tone. The amount quoted
+// is the balance left to pay, with late fees and interest and without the payments already received.
+func composeReminder(due models.DueReminder, asOf time.Time) mailer.Message {
+	tmpl, ok := reminderTemplates[due.Rule.Tone]
+	if !ok {
+		tmpl = reminderTemplates[models.ReminderToneFriendly]
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Dear %s,\n\n", due.CustomerName)
+	fmt.Fprintf(&body, tmpl.opening+"\n", due.Invoice.InvoiceNumber, due.Invoice.Currency, due.BalanceDue)
+	fmt.Fprintf(&body, "%s\n\n", describeDueDate(due.Invoice.DueDate, asOf))
+	fmt.Fprintf(&body, "%s\n\n", tmpl.closing)
+	fmt.Fprintf(&body, "Kind regards,\n%s\n%s\n", due.SenderName, due.SenderEmail)
+
+	return mailer.Message{
+		To:      due.CustomerEmail,
+		Subject: fmt.Sprintf(tmpl.subject, due.Invoice.InvoiceNumber),
+		Body:    body.String(),
+	}
+}
+
+// describeDueDate describes the due date relative to the given time, in whole calendar days.
+func describeDueDate(dueDate, asOf time.Time) string {
+	due := dueDate.Format("2 January 2006")
+	days := helpers.DaysBetween(dueDate, asOf)
+
+	switch {
+	case days < 0:
+		return fmt.Sprintf("It is due on %s.", due)
+	case days == 0:
+		return "It is due today."
+	case days == 1:
+		return fmt.Sprintf("It was due on %s, 1 day ago.", due)
+	default:
+		return fmt.Sprintf("It was due on %s, %d days ago.", due, days)
+	}
+}
@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Numeris-Task/internal/mailer"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAddReminderRule(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockReminderRepository(ctrl)
+	mail := mocked.NewMockMailer(ctrl)
+	userID := uuid.New()
+
+	t.Run("successful rule creation", func(t *testing.T) {
+		offset := 7
+		expectedRuleID := uuid.New()
+		repo.EXPECT().
+			AddReminderRule(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, rule models.ReminderRule) (uuid.UUID, error) {
+				require.Equal(t, userID, rule.UserID)
+				require.Equal(t, 7, rule.DaysOffset)
+				require.Equal(t, models.ReminderToneFinal, rule.Tone)
+				return expectedRuleID, nil
+			})
+
+		service := newReminderServiceImpl(repo, mail)
+		ruleID, err := service.AddReminderRule(ctx, models.AddReminderRuleRequest{
+			UserID:     userID.String(),
+			DaysOffset: &offset,
+			Tone:       string(models.ReminderToneFinal),
+		})
+		require.NoError(t, err)
+		require.Equal(t, expectedRuleID, ruleID)
+	})
+
+	t.Run("default tone escalates with offset", func(t *testing.T) {
+		for offset, tone := range map[int]models.ReminderTone{
+			-3: models.ReminderToneFriendly,
+			0:  models.ReminderToneFriendly,
+			7:  models.ReminderToneFirm,
+			30: models.ReminderToneFinal,
+		} {
+			offset := offset
+			repo.EXPECT().
+				AddReminderRule(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, rule models.ReminderRule) (uuid.UUID, error) {
+					require.Equal(t, tone, rule.Tone)
+					return rule.RuleID, nil
+				})
+
+			service := newReminderServiceImpl(repo, mail)
+			_, err := service.AddReminderRule(ctx, models.AddReminderRuleRequest{UserID: userID.String(), DaysOffset: &offset})
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("invalid user id", func(t *testing.T) {
+		offset := 0
+		service := newReminderServiceImpl(repo, mail)
+		ruleID, err := service.AddReminderRule(ctx, models.AddReminderRuleRequest{UserID: "invalid", DaysOffset: &offset})
+		require.Error(t, err)
+		require.Equal(t, uuid.Nil, ruleID)
+		require.Contains(t, err.Error(), "invalid user id")
+	})
+
+	t.Run("invalid tone", func(t *testing.T) {
+		offset := 0
+		service := newReminderServiceImpl(repo, mail)
+		ruleID, err := service.AddReminderRule(ctx, models.AddReminderRuleRequest{UserID: userID.String(), DaysOffset: &offset, Tone: "rude"})
+		require.Error(t, err)
+		require.Equal(t, uuid.Nil, ruleID)
+		require.Contains(t, err.Error(), "invalid reminder tone")
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		offset := 3
+		expectedErr := errors.New("database error")
+		repo.EXPECT().
+			AddReminderRule(gomock.Any(), gomock.Any()).
+			Return(uuid.Nil, expectedErr)
+
+		service := newReminderServiceImpl(repo, mail)
+		ruleID, err := service.AddReminderRule(ctx, models.AddReminderRuleRequest{UserID: userID.String(), DaysOffset: &offset})
+		require.Equal(t, expectedErr, err)
+		require.Equal(t, uuid.Nil, ruleID)
+	})
+}
+
+func TestGetReminderRules(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockReminderRepository(ctrl)
+	userID := uuid.New()
+
+	t.Run("successful retrieval", func(t *testing.T) {
+		expectedRules := []models.ReminderRule{
+			{RuleID: uuid.New(), UserID: userID, DaysOffset: -3, Tone: models.ReminderToneFriendly},
+			{RuleID: uuid.New(), UserID: userID, DaysOffset: 7, Tone: models.ReminderToneFirm},
+		}
+		repo.EXPECT().
+			GetReminderRules(gomock.Any(), userID).
+			Return(expectedRules, nil)
+
+		service := newReminderServiceImpl(repo, nil)
+		rules, err := service.GetReminderRules(ctx, userID)
+		require.NoError(t, err)
+		require.Equal(t, expectedRules, rules)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		expectedErr := errors.New("database error")
+		repo.EXPECT().
+			GetReminderRules(gomock.Any(), userID).
+			Return(nil, expectedErr)
+
+		service := newReminderServiceImpl(repo, nil)
+		rules, err := service.GetReminderRules(ctx, userID)
+		require.Equal(t, expectedErr, err)
+		require.Nil(t, rules)
+	})
+}
+
+func TestDeleteReminderRule(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockReminderRepository(ctrl)
+	userID, ruleID := uuid.New(), uuid.New()
+
+	t.Run("successful deletion", func(t *testing.T) {
+		repo.EXPECT().
+			DeleteReminderRule(gomock.Any(), userID, ruleID).
+			Return(nil)
+
+		service := newReminderServiceImpl(repo, nil)
+		require.NoError(t, service.DeleteReminderRule(ctx, userID, ruleID))
+	})
+
+	t.Run("rule not found", func(t *testing.T) {
+		repo.EXPECT().
+			DeleteReminderRule(gomock.Any(), userID, ruleID).
+			Return(errors.New("reminder rule not found"))
+
+		service := newReminderServiceImpl(repo, nil)
+		err := service.DeleteReminderRule(ctx, userID, ruleID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not found")
+	})
+}
+
+func TestSendDueReminders(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockReminderRepository(ctrl)
+	mail := mocked.NewMockMailer(ctrl)
+	asOf := time.Date(2024, 8, 20, 9, 0, 0, 0, time.UTC)
+
+	dueReminder := func(number string, offset int, tone models.ReminderTone) models.DueReminder {
+		return models.DueReminder{
+			ReminderID: uuid.New(),
+			Invoice: models.Invoice{
+				InvoiceID:     uuid.New(),
+				InvoiceNumber: number,
+				SenderID:      uuid.New(),
+				DueDate:       time.Date(2024, 8, 20, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -offset),
+				FinalAmount:   1500,
+				Currency:      "NGN",
+			},
+			BalanceDue:    1500,
+			Rule:          models.ReminderRule{RuleID: uuid.New(), DaysOffset: offset, Tone: tone},
+			SenderName:    "Ada Lovelace",
+			SenderEmail:   "ada@example.com",
+			CustomerName:  "Charles Babbage",
+			CustomerEmail: "charles@example.com",
+		}
+	}
+
+	t.Run("sends and records reminders", func(t *testing.T) {
+		first := dueReminder("1000000001", -3, models.ReminderToneFriendly)
+		second := dueReminder("1000000002", 30, models.ReminderToneFinal)
+		// a late fee was added and part of the invoice paid
+		second.BalanceDue = 1025.5
+
+		repo.EXPECT().
+			ClaimDueReminders(gomock.Any(), asOf, reminderLease, int32(reminderBatchSize)).
+			Return([]models.DueReminder{first, second}, nil)
+		mail.EXPECT().
+			Send(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, msg mailer.Message) error {
+				require.Equal(t, "charles@example.com", msg.To)
+				require.Equal(t, "Reminder: invoice 1000000001", msg.Subject)
+				require.Contains(t, msg.Body, "friendly reminder")
+				require.Contains(t, msg.Body, "a balance of NGN 1500.00 due")
+				require.Contains(t, msg.Body, "It is due on 23 August 2024.")
+				return nil
+			})
+		mail.EXPECT().
+			Send(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, msg mailer.Message) error {
+				require.Equal(t, "Final notice: invoice 1000000002", msg.Subject)
+				require.Contains(t, msg.Body, "30 days ago")
+				require.Contains(t, msg.Body, "a balance of NGN 1025.50 unpaid")
+				return nil
+			})
+		repo.EXPECT().
+			RecordReminder(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, reminder models.InvoiceReminder, activity models.InvoiceActivity) error {
+				require.Equal(t, first.ReminderID, reminder.ReminderID)
+				require.Equal(t, first.Invoice.InvoiceID, reminder.InvoiceID)
+				require.Equal(t, first.Rule.RuleID, reminder.RuleID)
+				require.Equal(t, -3, reminder.DaysOffset)
+				require.Equal(t, "charles@example.com", reminder.SentTo)
+				require.Equal(t, first.Invoice.InvoiceID, activity.InvoiceID)
+				require.Equal(t, first.Invoice.SenderID, activity.UserID)
+				require.Equal(t, "Payment Reminder", activity.Title)
+				require.Equal(t, models.ActivityEventEmailSent, activity.EventType)
+				require.JSONEq(t, `{"to":"charles@example.com","kind":"reminder","tone":"`+string(reminder.Tone)+`"}`, string(activity.Payload))
+				return nil
+			})
+		repo.EXPECT().
+			RecordReminder(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil)
+
+		service := newReminderServiceImpl(repo, mail)
+		sent, err := service.SendDueReminders(ctx, asOf)
+		require.NoError(t, err)
+		require.Equal(t, 2, sent)
+	})
+
+	t.Run("email failure is retried later", func(t *testing.T) {
+		first := dueReminder("1000000003", 0, models.ReminderToneFriendly)
+		first.Attempts = 2
+		second := dueReminder("1000000004", 7, models.ReminderToneFirm)
+
+		repo.EXPECT().
+			ClaimDueReminders(gomock.Any(), asOf, reminderLease, int32(reminderBatchSize)).
+			Return([]models.DueReminder{first, second}, nil)
+		mail.EXPECT().
+			Send(gomock.Any(), gomock.Any()).
+			Return(errors.New("smtp unavailable"))
+		// the third attempt waits four times the first retry delay
+		next := asOf.Add(4 * reminderRetryDelay)
+		repo.EXPECT().
+			RecordReminderFailure(gomock.Any(), first.ReminderID, "smtp unavailable", &next).
+			Return(nil)
+		mail.EXPECT().
+			Send(gomock.Any(), gomock.Any()).
+			Return(nil)
+		repo.EXPECT().
+			RecordReminder(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, reminder models.InvoiceReminder, _ models.InvoiceActivity) error {
+				require.Equal(t, second.Invoice.InvoiceID, reminder.InvoiceID)
+				return nil
+			})
+
+		service := newReminderServiceImpl(repo, mail)
+		sent, err := service.SendDueReminders(ctx, asOf)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "1000000003")
+		require.Contains(t, err.Error(), "smtp unavailable")
+		require.Equal(t, 1, sent)
+	})
+
+	t.Run("email failure after the last attempt", func(t *testing.T) {
+		due := dueReminder("1000000005", 7, models.ReminderToneFirm)
+		due.Attempts = maxReminderAttempts - 1
+
+		repo.EXPECT().
+			ClaimDueReminders(gomock.Any(), asOf, reminderLease, int32(reminderBatchSize)).
+			Return([]models.DueReminder{due}, nil)
+		mail.EXPECT().
+			Send(gomock.Any(), gomock.Any()).
+			Return(errors.New("mailbox unavailable"))
+		repo.EXPECT().
+			RecordReminderFailure(gomock.Any(), due.ReminderID, "mailbox unavailable", nil).
+			Return(nil)
+
+		service := newReminderServiceImpl(repo, mail)
+		sent, err := service.SendDueReminders(ctx, asOf)
+		require.ErrorContains(t, err, "mailbox unavailable")
+		require.Zero(t, sent)
+	})
+
+	t.Run("nothing due", func(t *testing.T) {
+		repo.EXPECT().
+			ClaimDueReminders(gomock.Any(), asOf, reminderLease, int32(reminderBatchSize)).
+			Return([]models.DueReminder{}, nil)
+
+		service := newReminderServiceImpl(repo, mail)
+		sent, err := service.SendDueReminders(ctx, asOf)
+		require.NoError(t, err)
+		require.Zero(t, sent)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		expectedErr := errors.New("database error")
+		repo.EXPECT().
+			ClaimDueReminders(gomock.Any(), asOf, reminderLease, int32(reminderBatchSize)).
+			Return(nil, expectedErr)
+
+		service := newReminderServiceImpl(repo, mail)
+		sent, err := service.SendDueReminders(ctx, asOf)
+		require.Equal(t, expectedErr, err)
+		require.Zero(t, sent)
+	})
+}
+
+func TestDescribeDueDate(t *testing.T) {
+	dueDate := time.Date(2024, 8, 20, 0, 0, 0, 0, time.UTC)
+
+	require.Equal(t, "It is due on 20 August 2024.", describeDueDate(dueDate, dueDate.AddDate(0, 0, -3)))
+	require.Equal(t, "It is due today.", describeDueDate(dueDate, dueDate.Add(15*time.Hour)))
+	require.Equal(t, "It was due on 20 August 2024, 1 day ago.", describeDueDate(dueDate, dueDate.AddDate(0, 0, 1)))
+	require.Equal(t, "It was due on 20 August 2024, 14 days ago.", describeDueDate(dueDate, dueDate.AddDate(0, 0, 14)))
+}
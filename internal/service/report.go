@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zde37/Numeris-Task/internal/helpers"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/repository"
+)
+
+// maxSeriesPoints is the number of periods a time series report can span, which keeps chart payloads small.
+const maxSeriesPoints = 1000
+
+type reportServiceImpl struct {
+	report repository.ReportRepository
+}
+
+// newReportServiceImpl creates a new instance of the reportServiceImpl struct, which implements the ReportService interface.
+// It takes a ReportRepository implementation as a dependency.
+func newReportServiceImpl(report repository.ReportRepository) *reportServiceImpl {
+	return &reportServiceImpl{
+		report: report,
+	}
+}
+
+// GetSeriesReport retrieves the amount a sender invoiced or collected per day, week or month between two dates.
+// Weeks start on Monday. Every series has a point for each period of the range, with zero amounts for the periods
+// without activity, so it can be charted as is.
+func (s *reportServiceImpl) GetSeriesReport(ctx context.Context, filter models.SeriesFilter) (*models.SeriesReport, error) {
+	if filter.Metric != models.SeriesMetricInvoiced && filter.Metric != models.SeriesMetricCollected {
+		return nil, fmt.Errorf("invalid metric: %s", filter.Metric)
+	}
+	if err := helpers.ValidateSeriesInterval(string(filter.Interval)); err != nil {
+		return nil, err
+	}
+	if err := helpers.ValidateSeriesGroupBy(string(filter.GroupBy)); err != nil {
+		return nil, err
+	}
+	if filter.To.Before(filter.From) {
+		return nil, fmt.Errorf("from must not be after to")
+	}
+
+	periods := seriesPeriods(filter.Interval, filter.From, filter.To)
+	if len(periods) > maxSeriesPoints {
+		return nil, fmt.Errorf("date range spans more than %d %ss", maxSeriesPoints, filter.Interval)
+	}
+
+	series, err := s.report.GetSeries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range series {
+		amounts := make(map[time.Time]models.SeriesPoint, len(series[i].Points))
+		for _, point := range series[i].Points {
+			amounts[point.Period] = point
+		}
+
+		points := make([]models.SeriesPoint, len(periods))
+		total := 0.0
+		for j, period := range periods {
+			point := amounts[period]
+			point.Period = period
+			points[j] = point
+			total += point.Amount
+		}
+		series[i].Points = points
+		series[i].Total = helpers.RoundAmount(total)
+	}
+
+	return &models.SeriesReport{
+		SenderID: filter.SenderID,
+		Metric:   filter.Metric,
+		Interval: filter.Interval,
+		GroupBy:  filter.GroupBy,
+		From:     filter.From,
+		To:       filter.To,
+		Series:   series,
+	}, nil
+}
+
+// seriesPeriods returns the start of every day, week or month that overlaps the range between two dates, the
+// same way Postgres date_trunc truncates them. It stops one period past maxSeriesPoints.
+func seriesPeriods(interval models.SeriesInterval, from, to time.Time) []time.Time {
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case models.SeriesIntervalWeek:
+		start = start.AddDate(0, 0, -(int(start.Weekday())+6)%7)
+	case models.SeriesIntervalMonth:
+		start = start.AddDate(0, 0, 1-start.Day())
+	}
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+
+	periods := []time.Time{}
+	for period := start; !period.After(end) && len(periods) <= maxSeriesPoints; {
+		periods = append(periods, period)
+		switch interval {
+		case models.SeriesIntervalDay:
+			period = period.AddDate(0, 0, 1)
+		case models.SeriesIntervalWeek:
+			period = period.AddDate(0, 0, 7)
+		default:
+			period = period.AddDate(0, 1, 0)
+		}
+	}
+	return periods
+}
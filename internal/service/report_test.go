@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetSeriesReport(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockReportRepository(ctrl)
+	service := newReportServiceImpl(repo)
+
+	senderID := uuid.New()
+	date := func(month time.Month, day int) time.Time { return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC) }
+
+	t.Run("fills in empty months", func(t *testing.T) {
+		filter := models.SeriesFilter{
+			SenderID: senderID,
+			Metric:   models.SeriesMetricInvoiced,
+			Interval: models.SeriesIntervalMonth,
+			From:     date(1, 15),
+			To:       date(4, 10),
+		}
+		repo.EXPECT().GetSeries(gomock.Any(), filter).Return([]models.Series{
+			{Currency: "NGN", Points: []models.SeriesPoint{
+				{Period: date(1, 1), Count: 2, Amount: 100.10},
+				{Period: date(3, 1), Count: 1, Amount: 50.20},
+			}},
+		}, nil)
+
+		report, err := service.GetSeriesReport(ctx, filter)
+		require.NoError(t, err)
+		require.Equal(t, models.SeriesMetricInvoiced, report.Metric)
+		require.Len(t, report.Series, 1)
+		require.Equal(t, 150.30, report.Series[0].Total)
+		require.Equal(t, []models.SeriesPoint{
+			{Period: date(1, 1), Count: 2, Amount: 100.10},
+			{Period: date(2, 1)},
+			{Period: date(3, 1), Count: 1, Amount: 50.20},
+			{Period: date(4, 1)},
+		}, report.Series[0].Points)
+	})
+
+	t.Run("weeks start on monday", func(t *testing.T) {
+		filter := models.SeriesFilter{
+			SenderID: senderID,
+			Metric:   models.SeriesMetricCollected,
+			Interval: models.SeriesIntervalWeek,
+			GroupBy:  models.SeriesGroupByCurrency,
+			From:     date(6, 5),
+			To:       date(6, 17),
+		}
+		repo.EXPECT().GetSeries(gomock.Any(), filter).Return([]models.Series{}, nil)
+
+		report, err := service.GetSeriesReport(ctx, filter)
+		require.NoError(t, err)
+		require.Empty(t, report.Series)
+		require.Equal(t, []time.Time{date(6, 3), date(6, 10), date(6, 17)}, seriesPeriods(filter.Interval, filter.From, filter.To))
+	})
+
+	t.Run("invalid filters", func(t *testing.T) {
+		valid := models.SeriesFilter{SenderID: senderID, Metric: models.SeriesMetricInvoiced, Interval: models.SeriesIntervalDay, From: date(1, 1), To: date(1, 31)}
+		for name, update := range map[string]func(*models.SeriesFilter){
+			"invalid metric":   func(f *models.SeriesFilter) { f.Metric = "billed" },
+			"invalid interval": func(f *models.SeriesFilter) { f.Interval = "year" },
+			"invalid group by": func(f *models.SeriesFilter) { f.GroupBy = "status" },
+			"reversed range":   func(f *models.SeriesFilter) { f.From, f.To = f.To, f.From },
+			"too many points":  func(f *models.SeriesFilter) { f.From = date(1, 1).AddDate(-3, 0, 0) },
+		} {
+			t.Run(name, func(t *testing.T) {
+				filter := valid
+				update(&filter)
+				report, err := service.GetSeriesReport(ctx, filter)
+				require.Error(t, err)
+				require.Nil(t, report)
+			})
+		}
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		filter := models.SeriesFilter{SenderID: senderID, Metric: models.SeriesMetricInvoiced, Interval: models.SeriesIntervalDay, From: date(1, 1), To: date(1, 31)}
+		repo.EXPECT().GetSeries(gomock.Any(), filter).Return(nil, errors.New("database error"))
+
+		report, err := service.GetSeriesReport(ctx, filter)
+		require.Error(t, err)
+		require.Nil(t, report)
+	})
+}
@@ -2,39 +2,158 @@ package service
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/zde37/Numeris-Task/internal/exchangerate"
+	"github.com/zde37/Numeris-Task/internal/gateway"
+	"github.com/zde37/Numeris-Task/internal/mailer"
 	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/pagination"
 	"github.com/zde37/Numeris-Task/internal/repository"
+	"github.com/zde37/Numeris-Task/internal/webhook"
 )
 
 type UserService interface {
 	CreateUser(ctx context.Context, data models.CreateUserRequest) (uuid.UUID, error)
 	AddCustomer(ctx context.Context, data models.AddCustomerRequest) (uuid.UUID, error)
 	AddPaymentMethod(ctx context.Context, data models.AddPaymentMethodRequest) (uuid.UUID, error)
+	GetPaymentMethods(ctx context.Context, userID uuid.UUID) ([]models.UserPaymentMethod, error)
+	UpdatePaymentMethod(ctx context.Context, userID, paymentMethodID uuid.UUID, data models.UpdatePaymentMethodRequest) (*models.UserPaymentMethod, error)
+	DeletePaymentMethod(ctx context.Context, userID, paymentMethodID uuid.UUID) error
+	SetDefaultPaymentMethod(ctx context.Context, userID, paymentMethodID uuid.UUID) error
 }
 
 type InvoiceService interface {
 	CreateInvoice(ctx context.Context, data models.CreateInvoiceRequest) (uuid.UUID, error)
 	GetInvoiceDetails(ctx context.Context, invoiceID uuid.UUID) (*models.InvoiceDetails, error)
 	AddInvoiceActivity(ctx context.Context, activity models.AddInvoiceActivityRequest) (uuid.UUID, error)
-	GetTotalByStatus(ctx context.Context, status models.InvoiceStatus) (totalAmount float64, count int, err error)
-	GetRecentInvoices(ctx context.Context, senderID uuid.UUID, page, limit int32) ([]models.Invoice, error)
-	GetRecentActivities(ctx context.Context, userID uuid.UUID, page, limit int32) ([]models.RecentActivity, error)
-	GetInvoiceActivities(ctx context.Context, userID, invoiceID uuid.UUID, page, limit int32) ([]models.InvoiceActivity, error)
+	GetTotalByStatus(ctx context.Context, filter models.TotalsFilter) (*models.InvoiceSummary, error)
+	GetRecentInvoices(ctx context.Context, senderID uuid.UUID, page pagination.Request) ([]models.Invoice, *pagination.Meta, error)
+	SearchInvoices(ctx context.Context, filter models.InvoiceFilter, page pagination.Request) ([]models.Invoice, *pagination.Meta, error)
+	ExportInvoices(ctx context.Context, filter models.InvoiceFilter, withItems bool, fn func(models.InvoiceExportRow) error) error
+	GetRecentActivities(ctx context.Context, userID uuid.UUID, filter models.ActivityFilter, page pagination.Request) ([]models.RecentActivity, *pagination.Meta, error)
+	GetInvoiceActivities(ctx context.Context, userID, invoiceID uuid.UUID, filter models.ActivityFilter, page pagination.Request) ([]models.InvoiceActivity, *pagination.Meta, error)
+	MarkOverdueInvoices(ctx context.Context, asOf time.Time) (int, error)
+}
+
+type ReminderService interface {
+	AddReminderRule(ctx context.Context, data models.AddReminderRuleRequest) (uuid.UUID, error)
+	GetReminderRules(ctx context.Context, userID uuid.UUID) ([]models.ReminderRule, error)
+	DeleteReminderRule(ctx context.Context, userID, ruleID uuid.UUID) error
+	SendDueReminders(ctx context.Context, asOf time.Time) (int, error)
+}
+
+type LateFeeService interface {
+	SetLateFeePolicy(ctx context.Context, data models.SetLateFeePolicyRequest) (uuid.UUID, error)
+	GetLateFeePolicy(ctx context.Context, userID uuid.UUID) (*models.LateFeePolicy, error)
+	ApplyLateFees(ctx context.Context, asOf time.Time) (int, error)
+}
+
+type TaxService interface {
+	CreateTaxRate(ctx context.Context, data models.CreateTaxRateRequest) (uuid.UUID, error)
+	GetTaxRates(ctx context.Context, userID uuid.UUID) ([]models.TaxRate, error)
+	DeactivateTaxRate(ctx context.Context, userID, taxRateID uuid.UUID) error
+	GetTaxReport(ctx context.Context, senderID uuid.UUID, from, to time.Time, basis models.TaxReportBasis) (*models.TaxReport, error)
+}
+
+type PaymentService interface {
+	RecordPayment(ctx context.Context, data models.RecordPaymentRequest) (uuid.UUID, error)
+	CreatePaymentLink(ctx context.Context, userID, invoiceID uuid.UUID) (*models.PaymentSession, error)
+	HandlePaymentCallback(ctx context.Context, payload []byte, header http.Header) error
+	RefundPayment(ctx context.Context, userID, paymentID uuid.UUID, amount float64) (*models.PaymentRefund, error)
+	SendPendingRefunds(ctx context.Context, asOf time.Time) (int, error)
+}
+
+type ReconciliationService interface {
+	ImportStatement(ctx context.Context, userID uuid.UUID, format string, file io.Reader) (*models.ReconciliationResult, error)
+	GetReconciliationInbox(ctx context.Context, userID uuid.UUID, page pagination.Request) ([]models.BankTransaction, *pagination.Meta, error)
+	ConfirmBankTransaction(ctx context.Context, userID, bankTransactionID uuid.UUID, data models.ConfirmBankTransactionRequest) (*models.BankTransaction, error)
+	DismissBankTransaction(ctx context.Context, userID, bankTransactionID uuid.UUID) error
+}
+
+type CurrencyService interface {
+	RefreshExchangeRates(ctx context.Context) (int, error)
+	GetExchangeRate(ctx context.Context, from, to string, on time.Time) (*models.ExchangeRate, error)
+}
+
+type DashboardService interface {
+	GetDashboard(ctx context.Context, senderID uuid.UUID, asOf time.Time) (*models.Dashboard, error)
+}
+
+type AgingService interface {
+	GetAgingReport(ctx context.Context, senderID uuid.UUID, asOf time.Time, customerID *uuid.UUID) (*models.AgingReport, error)
+	GetAgingInvoices(ctx context.Context, senderID uuid.UUID, asOf time.Time, bucket models.AgingBucket, customerID *uuid.UUID) ([]models.AgingInvoice, error)
+}
+
+type ReportService interface {
+	GetSeriesReport(ctx context.Context, filter models.SeriesFilter) (*models.SeriesReport, error)
+}
+
+type ImportService interface {
+	ImportCustomers(ctx context.Context, userID uuid.UUID, file io.Reader, dryRun bool) (*models.ImportResult, error)
+	ImportInvoices(ctx context.Context, senderID uuid.UUID, file io.Reader, dryRun bool) (*models.ImportResult, error)
+}
+
+type EventService interface {
+	Subscribe(ctx context.Context, userID uuid.UUID, lastEventID int64) (*models.EventSubscription, error)
+	Listen(ctx context.Context) error
+	Close()
+}
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, data models.CreateWebhookRequest) (*models.Webhook, error)
+	GetWebhooks(ctx context.Context, userID uuid.UUID) ([]models.Webhook, error)
+	DeleteWebhook(ctx context.Context, userID, webhookID uuid.UUID) error
+	EnableWebhook(ctx context.Context, userID, webhookID uuid.UUID) (*models.Webhook, error)
+	GetWebhookDeliveries(ctx context.Context, userID, webhookID uuid.UUID, page pagination.Request) ([]models.WebhookDelivery, *pagination.Meta, error)
+	ReplayWebhookDelivery(ctx context.Context, userID, deliveryID uuid.UUID) (*models.WebhookDelivery, error)
+	QueueWebhookDeliveries(ctx context.Context) (int, error)
+	SendDueWebhookDeliveries(ctx context.Context, asOf time.Time) (int, error)
 }
 
 type Service struct {
-	User    UserService
-	Invoice InvoiceService
+	User           UserService
+	Invoice        InvoiceService
+	Reminder       ReminderService
+	LateFee        LateFeeService
+	Tax            TaxService
+	Payment        PaymentService
+	Reconciliation ReconciliationService
+	Currency       CurrencyService
+	Dashboard      DashboardService
+	Aging          AgingService
+	Report         ReportService
+	Import         ImportService
+	Event          EventService
+	Webhook        WebhookService
 }
 
 // NewService creates a new instance of the Service struct, which provides access to the
-// UserService and InvoiceService implementations. The Service struct is the main entry
-// point for interacting with the application's business logic.
-func NewService(repo *repository.Repository) *Service {
+// UserService, InvoiceService, ReminderService, LateFeeService, TaxService, PaymentService, ReconciliationService,
+// CurrencyService, DashboardService, AgingService, ReportService, ImportService, EventService and WebhookService implementations.
+// The Service struct is the main entry point for interacting with the application's business logic.
+// The rates source and payment provider may be nil when they are not configured, and webhook deliveries are sent with
+// the hooks sender.
+func NewService(repo *repository.Repository, mail mailer.Mailer, rates exchangerate.Source,
+	payments gateway.PaymentProvider, hooks webhook.Sender) *Service {
+	invoice := newInvoiceServiceImpl(repo.Invoice, repo.Tax, repo.Currency, repo.User)
 	return &Service{
-		User:    newUserServiceImpl(repo.User),
-		Invoice: newInvoiceServiceImpl(repo.Invoice),
+		User:           newUserServiceImpl(repo.User),
+		Invoice:        invoice,
+		Reminder:       newReminderServiceImpl(repo.Reminder, mail),
+		LateFee:        newLateFeeServiceImpl(repo.LateFee),
+		Tax:            newTaxServiceImpl(repo.Tax),
+		Payment:        newPaymentServiceImpl(repo.Payment, payments),
+		Reconciliation: newReconciliationServiceImpl(repo.Reconciliation),
+		Currency:       newCurrencyServiceImpl(repo.Currency, rates),
+		Dashboard:      newDashboardServiceImpl(repo.Dashboard),
+		Aging:          newAgingServiceImpl(repo.Aging),
+		Report:         newReportServiceImpl(repo.Report),
+		Import:         newImportServiceImpl(repo.User, repo.Invoice, invoice),
+		Event:          newEventServiceImpl(repo.Event),
+		Webhook:        newWebhookServiceImpl(repo.Webhook, repo.Event, hooks),
 	}
 }
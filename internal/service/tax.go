@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zde37/Numeris-Task/internal/helpers"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/repository"
+)
+
+type taxServiceImpl struct {
+	tax repository.TaxRepository
+}
+
+// newTaxServiceImpl creates a new instance of the taxServiceImpl struct, which implements the TaxService interface.
+// It takes a TaxRepository implementation as a dependency.
+func newTaxServiceImpl(tax repository.TaxRepository) *taxServiceImpl {
+	return &taxServiceImpl{
+		tax: tax,
+	}
+}
+
+// CreateTaxRate creates a new tax rate that the given user can apply to invoice items.
+func (s *taxServiceImpl) CreateTaxRate(ctx context.Context, data models.CreateTaxRateRequest) (uuid.UUID, error) {
+	userID, err := uuid.Parse(data.UserID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid user id")
+	}
+
+	if err := helpers.ValidateTaxRate(data); err != nil {
+		return uuid.Nil, err
+	}
+
+	return s.tax.CreateTaxRate(ctx, models.TaxRate{
+		TaxRateID:   uuid.New(),
+		UserID:      userID,
+		Name:        strings.TrimSpace(data.Name),
+		Rate:        *data.Rate,
+		IsInclusive: data.IsInclusive,
+		IsCompound:  data.IsCompound,
+	})
+}
+
+// GetTaxRates retrieves the active tax rates of the given user.
+func (s *taxServiceImpl) GetTaxRates(ctx context.Context, userID uuid.UUID) ([]models.TaxRate, error) {
+	return s.tax.GetTaxRates(ctx, userID)
+}
+
+// DeactivateTaxRate stops the given tax rate owned by the given user from being used on new invoices.
+func (s *taxServiceImpl) DeactivateTaxRate(ctx context.Context, userID, taxRateID uuid.UUID) error {
+	return s.tax.DeactivateTaxRate(ctx, userID, taxRateID)
+}
+
+// calculateItemTaxes returns the taxes charged on an item whose discounted price is the given amount.
+// Inclusive taxes are already part of the price, so every tax is charged on the price without them;
+// compound taxes are charged on that price plus the other taxes of the item.
+func calculateItemTaxes(amount float64, rates []models.TaxRate) []models.InvoiceItemTax {
+	inclusiveRate := 0.0
+	for _, rate := range rates {
+		if rate.IsInclusive {
+			inclusiveRate += rate.Rate
+		}
+	}
+	net := amount / (1 + inclusiveRate/100)
+
+	taxes := make([]models.InvoiceItemTax, 0, len(rates))
+	simpleTax := 0.0
+	for _, rate := range rates {
+		if rate.IsCompound {
+			continue
+		}
+		tax := newItemTax(rate, net)
+		simpleTax += tax.TaxAmount
+		taxes = append(taxes, tax)
+	}
+	for _, rate := range rates {
+		if rate.IsCompound {
+			taxes = append(taxes, newItemTax(rate, net+simpleTax))
+		}
+	}
+	return taxes
+}
+
+// newItemTax builds the tax charged at the given rate on the given taxable amount.
+func newItemTax(rate models.TaxRate, taxable float64) models.InvoiceItemTax {
+	return models.InvoiceItemTax{
+		ItemTaxID:     uuid.New(),
+		TaxRateID:     rate.TaxRateID,
+		Name:          rate.Name,
+		Rate:          rate.Rate,
+		IsInclusive:   rate.IsInclusive,
+		IsCompound:    rate.IsCompound,
+		TaxableAmount: helpers.RoundAmount(taxable),
+		TaxAmount:     helpers.RoundAmount(taxable * rate.Rate / 100),
+	}
+}
+
+// summarizeTaxes groups the taxes of every item by tax rate into the tax lines of an invoice,
+// in the order the rates first appear on the items.
+func summarizeTaxes(invoiceID uuid.UUID, items []models.InvoiceItem) []models.InvoiceTaxLine {
+	lines := []models.InvoiceTaxLine{}
+	index := make(map[uuid.UUID]int)
+	for _, item := range items {
+		for _, tax := range item.Taxes {
+			idx, ok := index[tax.TaxRateID]
+			if !ok {
+				idx = len(lines)
+				index[tax.TaxRateID] = idx
+				lines = append(lines, models.InvoiceTaxLine{
+					TaxLineID:   uuid.New(),
+					InvoiceID:   invoiceID,
+					TaxRateID:   tax.TaxRateID,
+					Name:        tax.Name,
+					Rate:        tax.Rate,
+					IsInclusive: tax.IsInclusive,
+					IsCompound:  tax.IsCompound,
+				})
+			}
+			lines[idx].TaxableAmount = helpers.RoundAmount(lines[idx].TaxableAmount + tax.TaxableAmount)
+			lines[idx].TaxAmount = helpers.RoundAmount(lines[idx].TaxAmount + tax.TaxAmount)
+		}
+	}
+	return lines
+}
+
+// calculateInvoiceTotals breaks the amount due on an invoice down into subtotal, discount, tax and adjustments.
+// The grand total is the invoice's final amount plus any late fees or interest applied since it was issued,
+// and the balance due is what remains of it after the payments received.
+func calculateInvoiceTotals(details *models.InvoiceDetails) models.InvoiceTotals {
+	totals := models.InvoiceTotals{
+		Subtotal: details.Invoice.TotalAmount,
+		Discount: details.Invoice.DiscountedAmount,
+	}
+	for _, line := range details.TaxLines {
+		totals.Tax += line.TaxAmount
+	}
+	for _, adjustment := range details.Adjustments {
+		totals.Adjustments += adjustment.Amount
+	}
+	for _, payment := range details.Payments {
+		totals.AmountPaid += payment.Amount
+	}
+	totals.Tax = helpers.RoundAmount(totals.Tax)
+	totals.Adjustments = helpers.RoundAmount(totals.Adjustments)
+	totals.GrandTotal = helpers.RoundAmount(details.Invoice.FinalAmount + totals.Adjustments)
+	totals.AmountPaid = helpers.RoundAmount(totals.AmountPaid)
+	totals.BalanceDue = helpers.RoundAmount(math.Max(totals.GrandTotal-totals.AmountPaid, 0))
+	return totals
+}
+
+// GetTaxReport aggregates the tax collected by the given sender between the two dates, inclusive, on the given basis.
+func (s *taxServiceImpl) GetTaxReport(ctx context.Context, senderID uuid.UUID, from, to time.Time, basis models.TaxReportBasis) (*models.TaxReport, error) {
+	if err := helpers.ValidateTaxReportBasis(string(basis)); err != nil {
+		return nil, err
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("from must not be after to")
+	}
+
+	lines, err := s.tax.GetTaxReport(ctx, senderID, from, to, basis)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TaxReport{
+		SenderID: senderID,
+		From:     from,
+		To:       to,
+		Basis:    basis,
+		Lines:    lines,
+	}, nil
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateTaxRate(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockTaxRepository(ctrl)
+	userID := uuid.New()
+	rate := 7.5
+
+	t.Run("successful creation", func(t *testing.T) {
+		expectedTaxRateID := uuid.New()
+		repo.EXPECT().
+			CreateTaxRate(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, taxRate models.TaxRate) (uuid.UUID, error) {
+				require.Equal(t, userID, taxRate.UserID)
+				require.Equal(t, "VAT", taxRate.Name)
+				require.Equal(t, 7.5, taxRate.Rate)
+				require.True(t, taxRate.IsInclusive)
+				return expectedTaxRateID, nil
+			})
+
+		service := newTaxServiceImpl(repo)
+		taxRateID, err := service.CreateTaxRate(ctx, models.CreateTaxRateRequest{
+			UserID: userID.String(), Name: " VAT ", Rate: &rate, IsInclusive: true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, expectedTaxRateID, taxRateID)
+	})
+
+	t.Run("invalid user id", func(t *testing.T) {
+		service := newTaxServiceImpl(repo)
+		taxRateID, err := service.CreateTaxRate(ctx, models.CreateTaxRateRequest{UserID: "invalid", Name: "VAT", Rate: &rate})
+		require.Error(t, err)
+		require.Equal(t, uuid.Nil, taxRateID)
+		require.Contains(t, err.Error(), "invalid user id")
+	})
+
+	t.Run("invalid tax rate", func(t *testing.T) {
+		service := newTaxServiceImpl(repo)
+		_, err := service.CreateTaxRate(ctx, models.CreateTaxRateRequest{
+			UserID: userID.String(), Name: "VAT", Rate: &rate, IsInclusive: true, IsCompound: true,
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "inclusive and compound")
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repo.EXPECT().
+			CreateTaxRate(gomock.Any(), gomock.Any()).
+			Return(uuid.Nil, errors.New("db error"))
+
+		service := newTaxServiceImpl(repo)
+		_, err := service.CreateTaxRate(ctx, models.CreateTaxRateRequest{UserID: userID.String(), Name: "VAT", Rate: &rate})
+		require.Error(t, err)
+	})
+}
+
+func TestCalculateItemTaxes(t *testing.T) {
+	vat := models.TaxRate{TaxRateID: uuid.New(), Name: "VAT", Rate: 7.5}
+	levy := models.TaxRate{TaxRateID: uuid.New(), Name: "Levy", Rate: 2, IsCompound: true}
+	gst := models.TaxRate{TaxRateID: uuid.New(), Name: "GST", Rate: 15, IsInclusive: true}
+
+	t.Run("exclusive tax", func(t *testing.T) {
+		taxes := calculateItemTaxes(900, []models.TaxRate{vat})
+		require.Len(t, taxes, 1)
+		require.Equal(t, vat.TaxRateID, taxes[0].TaxRateID)
+		require.Equal(t, 900.0, taxes[0].TaxableAmount)
+		require.Equal(t, 67.5, taxes[0].TaxAmount)
+	})
+
+	t.Run("compound tax is charged on the other taxes", func(t *testing.T) {
+		taxes := calculateItemTaxes(900, []models.TaxRate{levy, vat})
+		require.Len(t, taxes, 2)
+		require.Equal(t, "VAT", taxes[0].Name)
+		require.Equal(t, "Levy", taxes[1].Name)
+		require.Equal(t, 967.5, taxes[1].TaxableAmount)
+		require.Equal(t, 19.35, taxes[1].TaxAmount)
+	})
+
+	t.Run("inclusive tax is extracted from the price", func(t *testing.T) {
+		taxes := calculateItemTaxes(1150, []models.TaxRate{gst})
+		require.Len(t, taxes, 1)
+		require.True(t, taxes[0].IsInclusive)
+		require.Equal(t, 1000.0, taxes[0].TaxableAmount)
+		require.Equal(t, 150.0, taxes[0].TaxAmount)
+	})
+
+	t.Run("exclusive tax on a price including tax", func(t *testing.T) {
+		taxes := calculateItemTaxes(1150, []models.TaxRate{gst, vat})
+		require.Len(t, taxes, 2)
+		require.Equal(t, 1000.0, taxes[1].TaxableAmount)
+		require.Equal(t, 75.0, taxes[1].TaxAmount)
+	})
+
+	t.Run("no taxes", func(t *testing.T) {
+		require.Empty(t, calculateItemTaxes(100, nil))
+	})
+}
+
+func TestSummarizeTaxes(t *testing.T) {
+	invoiceID := uuid.New()
+	vatID, levyID := uuid.New(), uuid.New()
+	items := []models.InvoiceItem{
+		{Taxes: []models.InvoiceItemTax{
+			{TaxRateID: vatID, Name: "VAT", Rate: 7.5, TaxableAmount: 100, TaxAmount: 7.5},
+		}},
+		{},
+		{Taxes: []models.InvoiceItemTax{
+			{TaxRateID: vatID, Name: "VAT", Rate: 7.5, TaxableAmount: 200, TaxAmount: 15},
+			{TaxRateID: levyID, Name: "Levy", Rate: 2, IsCompound: true, TaxableAmount: 215, TaxAmount: 4.3},
+		}},
+	}
+
+	lines := summarizeTaxes(invoiceID, items)
+	require.Len(t, lines, 2)
+	require.Equal(t, invoiceID, lines[0].InvoiceID)
+	require.Equal(t, vatID, lines[0].TaxRateID)
+	require.Equal(t, 300.0, lines[0].TaxableAmount)
+	require.Equal(t, 22.5, lines[0].TaxAmount)
+	require.Equal(t, levyID, lines[1].TaxRateID)
+	require.True(t, lines[1].IsCompound)
+	require.Equal(t, 4.3, lines[1].TaxAmount)
+}
+
+func TestCalculateInvoiceTotals(t *testing.T) {
+	details := &models.InvoiceDetails{
+		Invoice: models.Invoice{TotalAmount: 1150, DiscountedAmount: 115, FinalAmount: 1112.63},
+		TaxLines: []models.InvoiceTaxLine{
+			{Name: "GST", IsInclusive: true, TaxAmount: 135},
+			{Name: "VAT", TaxAmount: 67.5},
+			{Name: "Levy", IsCompound: true, TaxAmount: 10.13},
+		},
+		Adjustments: []models.InvoiceAdjustment{{Amount: 25}, {Amount: 12.5}},
+		Payments:    []models.Payment{{Amount: 500}, {Amount: 150.13}},
+	}
+
+	totals := calculateInvoiceTotals(details)
+	require.Equal(t, models.InvoiceTotals{
+		Subtotal:    1150,
+		Discount:    115,
+		Tax:         212.63,
+		Adjustments: 37.5,
+		GrandTotal:  1150.13,
+		AmountPaid:  650.13,
+		BalanceDue:  500,
+	}, totals)
+
+	t.Run("overpaid invoice has no balance due", func(t *testing.T) {
+		details.Payments = append(details.Payments, models.Payment{Amount: 600})
+		totals := calculateInvoiceTotals(details)
+		require.Equal(t, 1250.13, totals.AmountPaid)
+		require.Zero(t, totals.BalanceDue)
+	})
+}
+
+func TestGetTaxReport(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockTaxRepository(ctrl)
+	senderID := uuid.New()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	t.Run("successful report", func(t *testing.T) {
+		lines := []models.TaxReportLine{
+			{TaxRateID: uuid.New(), Name: "VAT", Rate: 7.5, Currency: "NGN", TaxableAmount: 1000, TaxAmount: 75, InvoiceCount: 2},
+		}
+		repo.EXPECT().
+			GetTaxReport(gomock.Any(), senderID, from, to, models.TaxReportBasisCash).
+			Return(lines, nil)
+
+		service := newTaxServiceImpl(repo)
+		report, err := service.GetTaxReport(ctx, senderID, from, to, models.TaxReportBasisCash)
+		require.NoError(t, err)
+		require.Equal(t, &models.TaxReport{
+			SenderID: senderID, From: from, To: to, Basis: models.TaxReportBasisCash, Lines: lines,
+		}, report)
+	})
+
+	t.Run("invalid basis", func(t *testing.T) {
+		service := newTaxServiceImpl(repo)
+		report, err := service.GetTaxReport(ctx, senderID, from, to, "accrual")
+		require.Error(t, err)
+		require.Nil(t, report)
+	})
+
+	t.Run("invalid period", func(t *testing.T) {
+		service := newTaxServiceImpl(repo)
+		report, err := service.GetTaxReport(ctx, senderID, to, from, models.TaxReportBasisInvoice)
+		require.Error(t, err)
+		require.Nil(t, report)
+		require.Contains(t, err.Error(), "from must not be after to")
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repo.EXPECT().
+			GetTaxReport(gomock.Any(), senderID, from, to, models.TaxReportBasisInvoice).
+			Return(nil, errors.New("db error"))
+
+		service := newTaxServiceImpl(repo)
+		report, err := service.GetTaxReport(ctx, senderID, from, to, models.TaxReportBasisInvoice)
+		require.Error(t, err)
+		require.Nil(t, report)
+	})
+}
@@ -8,8 +8,12 @@ import (
 	"github.com/zde37/Numeris-Task/internal/helpers"
 	"github.com/zde37/Numeris-Task/internal/models"
 	"github.com/zde37/Numeris-Task/internal/repository"
+	"github.com/zde37/Numeris-Task/internal/validation"
 )
 
+// defaultBaseCurrency is the base currency of users who do not choose one.
+const defaultBaseCurrency = "NGN"
+
 type userServiceImpl struct {
 	User repository.UserRepository
 }
@@ -23,8 +27,15 @@ func newUserServiceImpl(user repository.UserRepository) *userServiceImpl {
 	}
 }
 
-// CreateUser creates a new user in the user repository with the provided data. 
+// CreateUser creates a new user in the user repository with the provided data. Users without a base currency get NGN. 
 func (u *userServiceImpl) CreateUser(ctx context.Context, data models.CreateUserRequest) (uuid.UUID, error) {
+	baseCurrency := data.BaseCurrency
+	if baseCurrency == "" {
+		baseCurrency = defaultBaseCurrency
+	} else if err := helpers.ValidateCurrency(baseCurrency); err != nil {
+		return uuid.Nil, err
+	}
+
 	hashedPassword, err := helpers.HashPassword(data.Password)
 	if err != nil {
 		return uuid.Nil, err
@@ -40,31 +51,87 @@ func (u *userServiceImpl) CreateUser(ctx context.Context, data models.CreateUser
 		ProfilePictureURL: data.ProfilePictureURL,
 		PhoneNumber:       data.PhoneNumber,
 		Address:           data.Address,
+		BaseCurrency:      baseCurrency,
 	})
 }
 
-// AddPaymentMethod creates a new payment method for the specified user in the user repository. 
+// AddPaymentMethod creates a new payment method for the specified user in the user repository, once its bank details
+// are valid for its account type. Invalid bank details are returned as validation.Errors.
 func (u *userServiceImpl) AddPaymentMethod(ctx context.Context, data models.AddPaymentMethodRequest) (uuid.UUID, error) {
 	userID, err := uuid.Parse(data.UserID)
 	if err != nil {
 		return uuid.Nil, fmt.Errorf("invalid invoice id")
 	}
 
-	return u.User.AddPaymentMethod(ctx, models.UserPaymentMethod{
+	paymentMethod := models.UserPaymentMethod{
 		PaymentMethodID: uuid.New(),
 		UserID:          userID,
 		AccountName:     data.AccountName,
+		AccountType:     models.AccountType(data.AccountType),
 		AccountNumber:   data.AccountNumber,
+		RoutingNumber:   data.RoutingNumber,
+		SortCode:        data.SortCode,
 		BankName:        data.BankName,
 		BankAddress:     data.BankAddress,
 		SwiftCode:       data.SwiftCode,
-	})
+		IsDefault:       data.IsDefault,
+	}
+	if err := validation.PaymentMethod(&paymentMethod); err != nil {
+		return uuid.Nil, err
+	}
+	return u.User.AddPaymentMethod(ctx, paymentMethod)
+}
+
+// GetPaymentMethods retrieves the payment methods of the specified user, the default first.
+func (u *userServiceImpl) GetPaymentMethods(ctx context.Context, userID uuid.UUID) ([]models.UserPaymentMethod, error) {
+	return u.User.GetPaymentMethods(ctx, userID)
 }
 
-// AddCustomer creates a new customer in the user repository with the provided data. 
+// UpdatePaymentMethod changes the fields set in the request on a payment method of the specified user. Invalid bank
+// details are returned as validation.Errors, and ErrPaymentMethodInUse is returned for a payment method that invoices
+// were sent with.
+func (u *userServiceImpl) UpdatePaymentMethod(ctx context.Context, userID, paymentMethodID uuid.UUID, data models.UpdatePaymentMethodRequest) (*models.UserPaymentMethod, error) {
+	update := models.PaymentMethodUpdate{
+		AccountName:   data.AccountName,
+		AccountNumber: data.AccountNumber,
+		RoutingNumber: data.RoutingNumber,
+		SortCode:      data.SortCode,
+		BankName:      data.BankName,
+		BankAddress:   data.BankAddress,
+		SwiftCode:     data.SwiftCode,
+	}
+	if data.AccountType != nil {
+		accountType := models.AccountType(*data.AccountType)
+		update.AccountType = &accountType
+	}
+	if err := validation.PaymentMethodUpdate(&update); err != nil {
+		return nil, err
+	}
+	return u.User.UpdatePaymentMethod(ctx, userID, paymentMethodID, update)
+}
+
+// DeletePaymentMethod deletes a payment method of the specified user. Invoices already sent with it keep showing it.
+func (u *userServiceImpl) DeletePaymentMethod(ctx context.Context, userID, paymentMethodID uuid.UUID) error {
+	return u.User.DeletePaymentMethod(ctx, userID, paymentMethodID)
+}
+
+// SetDefaultPaymentMethod makes a payment method of the specified user the one their invoices are sent with when
+// none is given.
+func (u *userServiceImpl) SetDefaultPaymentMethod(ctx context.Context, userID, paymentMethodID uuid.UUID) error {
+	return u.User.SetDefaultPaymentMethod(ctx, userID, paymentMethodID)
+}
+
+// AddCustomer creates a new customer of the specified user in the user repository with the provided data. It returns
+// ErrUserNotFound when the user does not exist.
 func (u *userServiceImpl) AddCustomer(ctx context.Context, data models.AddCustomerRequest) (uuid.UUID, error) {
+	userID, err := uuid.Parse(data.UserID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid user id")
+	}
+
 	return u.User.AddCustomer(ctx, models.Customer{
 		CustomerID:  uuid.New(),
+		UserID:      userID,
 		Name:        data.Name,
 		Email:       data.Email,
 		PhoneNumber: data.PhoneNumber,
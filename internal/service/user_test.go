@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 	mocked "github.com/zde37/Numeris-Task/internal/mock"
 	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/validation"
 	"go.uber.org/mock/gomock"
 )
 
@@ -43,6 +44,7 @@ func TestCreateUser(t *testing.T) {
 				require.Equal(t, createUserRequest.ProfilePictureURL, user.ProfilePictureURL)
 				require.Equal(t, createUserRequest.PhoneNumber, user.PhoneNumber)
 				require.Equal(t, createUserRequest.Address, user.Address)
+				require.Equal(t, "NGN", user.BaseCurrency)
 				return expectedUserID, nil
 			})
 
@@ -52,6 +54,40 @@ func TestCreateUser(t *testing.T) {
 		require.Equal(t, expectedUserID, userID)
 	})
 
+	t.Run("custom base currency", func(t *testing.T) {
+		createUserRequest := models.CreateUserRequest{
+			Username:     "testuser",
+			Email:        "test@example.com",
+			Password:     "password123",
+			BaseCurrency: "USD",
+		}
+
+		repo.EXPECT().
+			CreateUser(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, user models.User) (uuid.UUID, error) {
+				require.Equal(t, "USD", user.BaseCurrency)
+				return user.UserID, nil
+			})
+
+		service := newUserServiceImpl(repo)
+		_, err := service.CreateUser(ctx, createUserRequest)
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid base currency", func(t *testing.T) {
+		createUserRequest := models.CreateUserRequest{
+			Username:     "testuser",
+			Email:        "test@example.com",
+			Password:     "password123",
+			BaseCurrency: "DOLLAR",
+		}
+
+		service := newUserServiceImpl(repo)
+		userID, err := service.CreateUser(ctx, createUserRequest)
+		require.ErrorContains(t, err, "invalid currency")
+		require.Equal(t, uuid.Nil, userID)
+	})
+
 	t.Run("repository error", func(t *testing.T) {
 		createUserRequest := models.CreateUserRequest{
 			Username: "testuser",
@@ -126,7 +162,7 @@ func TestAddPaymentMethod(t *testing.T) {
 			AccountNumber: "1234567890",
 			BankName:      "Test Bank",
 			BankAddress:   "123 Bank St",
-			SwiftCode:     "TESTSWIFT",
+			SwiftCode:     "TESTUS33",
 		}
 
 		repo.EXPECT().
@@ -155,7 +191,7 @@ func TestAddPaymentMethod(t *testing.T) {
 			AccountNumber: "1234567890",
 			BankName:      "Test Bank",
 			BankAddress:   "123 Bank St",
-			SwiftCode:     "TESTSWIFT",
+			SwiftCode:     "TESTUS33",
 		}
 
 		expectedError := errors.New("database error")
@@ -178,7 +214,7 @@ func TestAddPaymentMethod(t *testing.T) {
 			AccountNumber: "1234567890",
 			BankName:      "Test Bank",
 			BankAddress:   "123 Bank St",
-			SwiftCode:     "TESTSWIFT",
+			SwiftCode:     "TESTUS33",
 		}
 
 		repo.EXPECT().
@@ -200,7 +236,7 @@ func TestAddPaymentMethod(t *testing.T) {
 			AccountNumber: "",
 			BankName:      "Test Bank",
 			BankAddress:   "123 Bank St",
-			SwiftCode:     "TESTSWIFT",
+			SwiftCode:     "TESTUS33",
 		}
 
 		repo.EXPECT().
@@ -213,6 +249,52 @@ func TestAddPaymentMethod(t *testing.T) {
 		require.Equal(t, uuid.Nil, paymentMethodID)
 		require.Contains(t, err.Error(), "account number cannot be empty")
 	})
+
+	t.Run("iban account", func(t *testing.T) {
+		addPaymentMethodRequest := models.AddPaymentMethodRequest{
+			UserID:        uuid.New().String(),
+			AccountName:   "John Doe",
+			AccountType:   "iban",
+			AccountNumber: "gb82 west 1234 5698 7654 32",
+			BankName:      "Test Bank",
+			BankAddress:   "123 Bank St",
+			SwiftCode:     "westgb2l",
+		}
+
+		repo.EXPECT().
+			AddPaymentMethod(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, paymentMethod models.UserPaymentMethod) (uuid.UUID, error) {
+				require.Equal(t, models.AccountTypeIBAN, paymentMethod.AccountType)
+				require.Equal(t, "GB82WEST12345698765432", paymentMethod.AccountNumber)
+				require.Equal(t, "WESTGB2L", paymentMethod.SwiftCode)
+				return paymentMethod.PaymentMethodID, nil
+			})
+
+		service := newUserServiceImpl(repo)
+		_, err := service.AddPaymentMethod(ctx, addPaymentMethodRequest)
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid bank details", func(t *testing.T) {
+		addPaymentMethodRequest := models.AddPaymentMethodRequest{
+			UserID:        uuid.New().String(),
+			AccountName:   "John Doe",
+			AccountType:   "us",
+			AccountNumber: "1234567890",
+			RoutingNumber: "021000022",
+			BankName:      "Test Bank",
+			BankAddress:   "123 Bank St",
+			SwiftCode:     "TESTSWIFT",
+		}
+
+		service := newUserServiceImpl(repo)
+		paymentMethodID, err := service.AddPaymentMethod(ctx, addPaymentMethodRequest)
+		require.Equal(t, uuid.Nil, paymentMethodID)
+		require.Equal(t, validation.Errors{
+			{Field: "routing_number", Message: "has an invalid checksum"},
+			{Field: "swift_code", Message: "must be 8 or 11 characters"},
+		}, validation.FieldErrors(err))
+	})
 }
 
 func TestAddCustomer(t *testing.T) {
@@ -221,10 +303,12 @@ func TestAddCustomer(t *testing.T) {
 	defer ctrl.Finish()
 
 	repo := mocked.NewMockUserRepository(ctrl)
+	userID := uuid.New()
 
 	t.Run("successful customer addition", func(t *testing.T) {
 		expectedCustomerID := uuid.New()
 		addCustomerRequest := models.AddCustomerRequest{
+			UserID:      userID.String(),
 			Name:        "John Doe",
 			Email:       "john@example.com",
 			PhoneNumber: "1234567890",
@@ -239,6 +323,7 @@ func TestAddCustomer(t *testing.T) {
 				require.Equal(t, addCustomerRequest.PhoneNumber, customer.PhoneNumber)
 				require.Equal(t, addCustomerRequest.Address, customer.Address)
 				require.NotEqual(t, uuid.Nil, customer.CustomerID)
+				require.Equal(t, userID, customer.UserID)
 				return expectedCustomerID, nil
 			})
 
@@ -250,8 +335,9 @@ func TestAddCustomer(t *testing.T) {
 
 	t.Run("repository error", func(t *testing.T) {
 		addCustomerRequest := models.AddCustomerRequest{
-			Name:  "Jane Doe",
-			Email: "jane@example.com",
+			UserID: userID.String(),
+			Name:   "Jane Doe",
+			Email:  "jane@example.com",
 		}
 
 		expectedError := errors.New("database error")
@@ -268,8 +354,9 @@ func TestAddCustomer(t *testing.T) {
 
 	t.Run("empty name", func(t *testing.T) {
 		addCustomerRequest := models.AddCustomerRequest{
-			Name:  "",
-			Email: "empty@example.com",
+			UserID: userID.String(),
+			Name:   "",
+			Email:  "empty@example.com",
 		}
 
 		repo.EXPECT().
@@ -285,8 +372,9 @@ func TestAddCustomer(t *testing.T) {
 
 	t.Run("invalid email", func(t *testing.T) {
 		addCustomerRequest := models.AddCustomerRequest{
-			Name:  "Invalid Email",
-			Email: "invalid-email",
+			UserID: userID.String(),
+			Name:   "Invalid Email",
+			Email:  "invalid-email",
 		}
 
 		repo.EXPECT().
@@ -299,4 +387,80 @@ func TestAddCustomer(t *testing.T) {
 		require.Equal(t, uuid.Nil, customerID)
 		require.Contains(t, err.Error(), "invalid email format")
 	})
+
+	t.Run("invalid user id", func(t *testing.T) {
+		service := newUserServiceImpl(repo)
+		customerID, err := service.AddCustomer(ctx, models.AddCustomerRequest{UserID: "invalid", Name: "John Doe"})
+		require.EqualError(t, err, "invalid user id")
+		require.Equal(t, uuid.Nil, customerID)
+	})
+}
+
+func TestUpdatePaymentMethod(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockUserRepository(ctrl)
+	service := newUserServiceImpl(repo)
+	userID, paymentMethodID := uuid.New(), uuid.New()
+	bankName := "New Bank"
+
+	t.Run("successful update", func(t *testing.T) {
+		expected := &models.UserPaymentMethod{PaymentMethodID: paymentMethodID, UserID: userID, BankName: bankName}
+		repo.EXPECT().
+			UpdatePaymentMethod(gomock.Any(), userID, paymentMethodID, models.PaymentMethodUpdate{BankName: &bankName}).
+			Return(expected, nil)
+
+		paymentMethod, err := service.UpdatePaymentMethod(ctx, userID, paymentMethodID, models.UpdatePaymentMethodRequest{BankName: &bankName})
+		require.NoError(t, err)
+		require.Equal(t, expected, paymentMethod)
+	})
+
+	t.Run("payment method not found", func(t *testing.T) {
+		repo.EXPECT().
+			UpdatePaymentMethod(gomock.Any(), userID, paymentMethodID, gomock.Any()).
+			Return(nil, models.ErrPaymentMethodNotFound)
+
+		paymentMethod, err := service.UpdatePaymentMethod(ctx, userID, paymentMethodID, models.UpdatePaymentMethodRequest{BankName: &bankName})
+		require.ErrorIs(t, err, models.ErrPaymentMethodNotFound)
+		require.Nil(t, paymentMethod)
+	})
+
+	t.Run("account change", func(t *testing.T) {
+		accountType, accountNumber, sortCode := "uk", "12345678", "12-34-56"
+		expectedType, expectedSortCode := models.AccountTypeUK, "123456"
+		repo.EXPECT().
+			UpdatePaymentMethod(gomock.Any(), userID, paymentMethodID, models.PaymentMethodUpdate{
+				AccountType: &expectedType, AccountNumber: &accountNumber, SortCode: &expectedSortCode,
+			}).
+			Return(&models.UserPaymentMethod{}, nil)
+
+		_, err := service.UpdatePaymentMethod(ctx, userID, paymentMethodID, models.UpdatePaymentMethodRequest{
+			AccountType: &accountType, AccountNumber: &accountNumber, SortCode: &sortCode,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("account number without account type", func(t *testing.T) {
+		accountNumber := "12345678"
+		_, err := service.UpdatePaymentMethod(ctx, userID, paymentMethodID, models.UpdatePaymentMethodRequest{AccountNumber: &accountNumber})
+		require.Equal(t, "account_type", validation.FieldErrors(err)[0].Field)
+	})
+}
+
+func TestSetDefaultPaymentMethod(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockUserRepository(ctrl)
+	service := newUserServiceImpl(repo)
+	userID, paymentMethodID := uuid.New(), uuid.New()
+
+	repo.EXPECT().SetDefaultPaymentMethod(gomock.Any(), userID, paymentMethodID).Return(nil)
+	require.NoError(t, service.SetDefaultPaymentMethod(ctx, userID, paymentMethodID))
+
+	repo.EXPECT().SetDefaultPaymentMethod(gomock.Any(), userID, paymentMethodID).Return(models.ErrPaymentMethodNotFound)
+	require.ErrorIs(t, service.SetDefaultPaymentMethod(ctx, userID, paymentMethodID), models.ErrPaymentMethodNotFound)
 }
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zde37/Numeris-Task/internal/helpers"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/pagination"
+	"github.com/zde37/Numeris-Task/internal/repository"
+	"github.com/zde37/Numeris-Task/internal/webhook"
+)
+
+const (
+	// webhookEventBatchSize is the number of events of the event log read at a time while queueing deliveries.
+	webhookEventBatchSize = 100
+	// webhookDeliveryBatchSize is the number of due deliveries claimed at a time while sending them, one after the
+	// other.
+	webhookDeliveryBatchSize = 10
+	// webhookDeliveryLease is how long a claimed delivery is kept from other servers while it is sent. It outlasts a
+	// batch whose every delivery times out, with a margin to record their outcomes, so no delivery is claimed again
+	// while it may still be sent.
+	webhookDeliveryLease = webhookDeliveryBatchSize*webhook.RequestTimeout + time.Minute
+	// maxWebhookAttempts is the number of times a delivery is sent before it fails for good.
+	maxWebhookAttempts = 8
+	// webhookRetryDelay is the delay before the first retry of a delivery, which doubles with every retry after it.
+	webhookRetryDelay = 30 * time.Second
+	// maxWebhookFailures is the number of deliveries in a row that may fail for good before their webhook is
+	// disabled.
+	maxWebhookFailures = 5
+)
+
+type webhookServiceImpl struct {
+	webhook repository.WebhookRepository
+	events  repository.EventRepository
+	sender  webhook.Sender
+}
+
+// newWebhookServiceImpl creates a new instance of the webhookServiceImpl struct, which implements the WebhookService
+// interface. It takes a WebhookRepository implementation, the EventRepository the delivered events are read from and
+// the Sender deliveries are sent with as dependencies.
+func newWebhookServiceImpl(webhooks repository.WebhookRepository, events repository.EventRepository, sender webhook.Sender) *webhookServiceImpl {
+	return &webhookServiceImpl{
+		webhook: webhooks,
+		events:  events,
+		sender:  sender,
+	}
+}
+
+// CreateWebhook subscribes a URL to the invoice lifecycle events of a user, and returns the webhook with the secret
+// its deliveries are signed with, which is not shown again. A secret is generated when none is given.
+func (s *webhookServiceImpl) CreateWebhook(ctx context.Context, data models.CreateWebhookRequest) (*models.Webhook, error) {
+	userID, err := uuid.Parse(data.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user id")
+	}
+	if err := helpers.ValidateWebhook(data); err != nil {
+		return nil, err
+	}
+
+	secret := data.Secret
+	if secret == "" {
+		if secret, err = webhook.NewSecret(); err != nil {
+			return nil, err
+		}
+	}
+	eventTypes := []models.WebhookEventType{}
+	for _, eventType := range data.EventTypes {
+		if !slices.Contains(eventTypes, models.WebhookEventType(eventType)) {
+			eventTypes = append(eventTypes, models.WebhookEventType(eventType))
+		}
+	}
+
+	webhookID, err := s.webhook.CreateWebhook(ctx, models.Webhook{
+		WebhookID:  uuid.New(),
+		UserID:     userID,
+		URL:        data.URL,
+		EventTypes: eventTypes,
+		Secret:     secret,
+	})
+	if err != nil {
+		return nil, err
+	}
+	created, err := s.webhook.GetWebhook(ctx, userID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if created == nil {
+		return nil, models.ErrWebhookNotFound
+	}
+	created.Secret = secret
+	return created, nil
+}
+
+// GetWebhooks retrieves the webhooks of the given user.
+func (s *webhookServiceImpl) GetWebhooks(ctx context.Context, userID uuid.UUID) ([]models.Webhook, error) {
+	return s.webhook.GetWebhooks(ctx, userID)
+}
+
+// DeleteWebhook deletes the given webhook owned by the given user, along with its deliveries.
+func (s *webhookServiceImpl) DeleteWebhook(ctx context.Context, userID, webhookID uuid.UUID) error {
+	return s.webhook.DeleteWebhook(ctx, userID, webhookID)
+}
+
+// EnableWebhook enables the given webhook owned by the given user again after it was disabled for failing.
+func (s *webhookServiceImpl) EnableWebhook(ctx context.Context, userID, webhookID uuid.UUID) (*models.Webhook, error) {
+	return s.webhook.EnableWebhook(ctx, userID, webhookID)
+}
+
+// GetWebhookDeliveries retrieves a page of the deliveries of the given webhook owned by the given user, newest first.
+func (s *webhookServiceImpl) GetWebhookDeliveries(ctx context.Context, userID, webhookID uuid.UUID, page pagination.Request) ([]models.WebhookDelivery, *pagination.Meta, error) {
+	if err := page.Validate(); err != nil {
+		return nil, nil, err
+	}
+	owned, err := s.webhook.GetWebhook(ctx, userID, webhookID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if owned == nil {
+		return nil, nil, models.ErrWebhookNotFound
+	}
+	return s.webhook.GetWebhookDeliveries(ctx, webhookID, page)
+}
+
+// ReplayWebhookDelivery sends a delivery of a webhook owned by the given user again, as a new delivery of the same
+// event sent on the next run of the background worker.
+func (s *webhookServiceImpl) ReplayWebhookDelivery(ctx context.Context, userID, deliveryID uuid.UUID) (*models.WebhookDelivery, error) {
+	return s.webhook.ReplayWebhookDelivery(ctx, userID, deliveryID, uuid.New(), time.Now())
+}
+
+// QueueWebhookDeliveries queues a delivery to every enabled webhook for each event of its types recorded since it was
+// last queued, reading the events from the event log once every older transaction finished, so events committed late
+// are not passed over. It returns the number of deliveries queued. Webhooks that fail are reported in the returned
+// error and queued again on the next run.
+func (s *webhookServiceImpl) QueueWebhookDeliveries(ctx context.Context) (int, error) {
+	webhooks, err := s.webhook.GetActiveWebhooks(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	var errs []error
+	for _, hook := range webhooks {
+		count, err := s.queueDeliveries(ctx, hook)
+		queued += count
+		if err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", hook.WebhookID, err))
+		}
+	}
+	return queued, errors.Join(errs...)
+}
+
+// queueDeliveries queues the deliveries of the events recorded for the user of a webhook since it was last queued, a
+// batch of events at a time, and returns how many were queued.
+func (s *webhookServiceImpl) queueDeliveries(ctx context.Context, hook models.Webhook) (int, error) {
+	queued := 0
+	after := hook.LastEvent
+	for {
+		events, err := s.events.GetActivityEvents(ctx, hook.UserID, after, webhookEventBatchSize)
+		if err != nil {
+			return queued, err
+		}
+		if len(events) == 0 {
+			return queued, nil
+		}
+
+		deliveries, err := newWebhookDeliveries(hook, events, time.Now())
+		if err != nil {
+			return queued, err
+		}
+		after = events[len(events)-1].Cursor()
+		if err := s.webhook.QueueWebhookDeliveries(ctx, hook.WebhookID, deliveries, after); err != nil {
+			return queued, err
+		}
+		queued += len(deliveries)
+
+		if len(events) < webhookEventBatchSize {
+			return queued, nil
+		}
+	}
+}
+
+// newWebhookDeliveries returns the deliveries of the events of the types of a webhook among activity events, due as
+// of the given time.
+func newWebhookDeliveries(hook models.Webhook, events []models.ActivityEvent, asOf time.Time) ([]models.WebhookDelivery, error) {
+	deliveries := []models.WebhookDelivery{}
+	for _, activity := range events {
+		for _, event := range webhook.NewEvents(activity.InvoiceActivity) {
+			if !slices.Contains(hook.EventTypes, event.Type) {
+				continue
+			}
+			body, err := json.Marshal(event)
+			if err != nil {
+				return nil, err
+			}
+			deliveries = append(deliveries, models.WebhookDelivery{
+				DeliveryID:    uuid.New(),
+				WebhookID:     hook.WebhookID,
+				EventID:       event.ID,
+				EventType:     event.Type,
+				Payload:       body,
+				Status:        models.WebhookDeliveryStatusPending,
+				NextAttemptAt: &asOf,
+			})
+		}
+	}
+	return deliveries, nil
+}
+
+// SendDueWebhookDeliveries sends every delivery due as of the given time, and returns the number of deliveries the
+// webhook URLs accepted. Deliveries that are not accepted are retried with a delay that doubles every time, until they
+// fail for good after maxWebhookAttempts attempts; webhooks with maxWebhookFailures such deliveries in a row are
+// disabled. Only failures to record the outcome of a delivery are reported in the returned error.
+func (s *webhookServiceImpl) SendDueWebhookDeliveries(ctx context.Context, asOf time.Time) (int, error) {
+	delivered := 0
+	var errs []error
+	for {
+		due, err := s.webhook.ClaimDueWebhookDeliveries(ctx, asOf, webhookDeliveryLease, webhookDeliveryBatchSize)
+		if err != nil {
+			return delivered, err
+		}
+
+		for _, delivery := range due {
+			attempt := s.send(ctx, delivery, asOf)
+			if ctx.Err() != nil {
+				// the claim runs out and the delivery is sent again on the next run
+				return delivered, errors.Join(errs...)
+			}
+			if err := s.webhook.RecordWebhookDeliveryAttempt(ctx, attempt, maxWebhookFailures); err != nil {
+				errs = append(errs, fmt.Errorf("delivery %s: %w", delivery.Delivery.DeliveryID, err))
+				continue
+			}
+			if attempt.Status == models.WebhookDeliveryStatusSucceeded {
+				delivered++
+			}
+		}
+
+		if len(due) < webhookDeliveryBatchSize {
+			return delivered, errors.Join(errs...)
+		}
+	}
+}
+
+// send sends a due delivery to its webhook URL and returns the outcome of the attempt.
+func (s *webhookServiceImpl) send(ctx context.Context, due models.DueWebhookDelivery, asOf time.Time) models.WebhookDeliveryAttempt {
+	delivery := due.Delivery
+	status, err := s.sender.Send(ctx, webhook.Request{
+		URL:        due.URL,
+		Secret:     due.Secret,
+		DeliveryID: delivery.DeliveryID,
+		EventType:  delivery.EventType,
+		Body:       delivery.Payload,
+	})
+
+	attempt := models.WebhookDeliveryAttempt{
+		DeliveryID:  delivery.DeliveryID,
+		WebhookID:   delivery.WebhookID,
+		Status:      models.WebhookDeliveryStatusSucceeded,
+		AttemptedAt: asOf,
+	}
+	if status != 0 {
+		attempt.ResponseStatus = &status
+	}
+	if err == nil {
+		return attempt
+	}
+
+	attempt.Error = err.Error()
+	attempts := delivery.Attempts + 1
+	if attempts >= maxWebhookAttempts {
+		attempt.Status = models.WebhookDeliveryStatusFailed
+		return attempt
+	}
+	next := asOf.Add(webhookRetryDelay << (attempts - 1))
+	attempt.Status = models.WebhookDeliveryStatusPending
+	attempt.NextAttemptAt = &next
+	return attempt
+}
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mocked "github.com/zde37/Numeris-Task/internal/mock"
+	"github.com/zde37/Numeris-Task/internal/models"
+	"github.com/zde37/Numeris-Task/internal/pagination"
+	"github.com/zde37/Numeris-Task/internal/webhook"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockWebhookRepository(ctrl)
+	service := newWebhookServiceImpl(repo, mocked.NewMockEventRepository(ctrl), mocked.NewMockSender(ctrl))
+	userID := uuid.New()
+
+	t.Run("generated secret", func(t *testing.T) {
+		webhookID := uuid.New()
+		var secret string
+		repo.EXPECT().
+			CreateWebhook(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, hook models.Webhook) (uuid.UUID, error) {
+				require.Equal(t, userID, hook.UserID)
+				require.Equal(t, "https://example.com/hooks", hook.URL)
+				require.Equal(t, []models.WebhookEventType{models.WebhookEventInvoicePaid, models.WebhookEventInvoiceOverdue}, hook.EventTypes)
+				require.True(t, strings.HasPrefix(hook.Secret, "whsec_"))
+				secret = hook.Secret
+				return webhookID, nil
+			})
+		repo.EXPECT().
+			GetWebhook(gomock.Any(), userID, webhookID).
+			Return(&models.Webhook{WebhookID: webhookID, UserID: userID}, nil)
+
+		created, err := service.CreateWebhook(ctx, models.CreateWebhookRequest{
+			UserID:     userID.String(),
+			URL:        "https://example.com/hooks",
+			EventTypes: []string{"invoice.paid", "invoice.overdue", "invoice.paid"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, webhookID, created.WebhookID)
+		require.Equal(t, secret, created.Secret)
+	})
+
+	t.Run("given secret", func(t *testing.T) {
+		webhookID := uuid.New()
+		repo.EXPECT().
+			CreateWebhook(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, hook models.Webhook) (uuid.UUID, error) {
+				require.Equal(t, "a-secret-of-my-own", hook.Secret)
+				return webhookID, nil
+			})
+		repo.EXPECT().
+			GetWebhook(gomock.Any(), userID, webhookID).
+			Return(&models.Webhook{WebhookID: webhookID}, nil)
+
+		created, err := service.CreateWebhook(ctx, models.CreateWebhookRequest{
+			UserID:     userID.String(),
+			URL:        "https://example.com/hooks",
+			EventTypes: []string{"invoice.created"},
+			Secret:     "a-secret-of-my-own",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "a-secret-of-my-own", created.Secret)
+	})
+
+	t.Run("invalid user id", func(t *testing.T) {
+		_, err := service.CreateWebhook(ctx, models.CreateWebhookRequest{UserID: "invalid"})
+		require.EqualError(t, err, "invalid user id")
+	})
+
+	t.Run("invalid event type", func(t *testing.T) {
+		_, err := service.CreateWebhook(ctx, models.CreateWebhookRequest{
+			UserID:     userID.String(),
+			URL:        "https://example.com/hooks",
+			EventTypes: []string{"invoice.deleted"},
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestGetWebhookDeliveries(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockWebhookRepository(ctrl)
+	service := newWebhookServiceImpl(repo, mocked.NewMockEventRepository(ctrl), mocked.NewMockSender(ctrl))
+	userID, webhookID := uuid.New(), uuid.New()
+	page := pagination.Request{Limit: 10, Page: 1}
+
+	t.Run("deliveries of an owned webhook", func(t *testing.T) {
+		deliveries := []models.WebhookDelivery{{DeliveryID: uuid.New(), WebhookID: webhookID}}
+		repo.EXPECT().GetWebhook(gomock.Any(), userID, webhookID).Return(&models.Webhook{WebhookID: webhookID}, nil)
+		repo.EXPECT().GetWebhookDeliveries(gomock.Any(), webhookID, page).Return(deliveries, &pagination.Meta{}, nil)
+
+		result, meta, err := service.GetWebhookDeliveries(ctx, userID, webhookID, page)
+		require.NoError(t, err)
+		require.NotNil(t, meta)
+		require.Equal(t, deliveries, result)
+	})
+
+	t.Run("webhook of another user", func(t *testing.T) {
+		repo.EXPECT().GetWebhook(gomock.Any(), userID, webhookID).Return(nil, nil)
+
+		_, _, err := service.GetWebhookDeliveries(ctx, userID, webhookID, page)
+		require.ErrorIs(t, err, models.ErrWebhookNotFound)
+	})
+}
+
+func TestQueueWebhookDeliveries(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockWebhookRepository(ctrl)
+	events := mocked.NewMockEventRepository(ctrl)
+	service := newWebhookServiceImpl(repo, events, mocked.NewMockSender(ctrl))
+
+	userID := uuid.New()
+	hook := models.Webhook{
+		WebhookID:  uuid.New(),
+		UserID:     userID,
+		EventTypes: []models.WebhookEventType{models.WebhookEventInvoicePaid, models.WebhookEventInvoiceSent},
+		LastEvent:  models.EventCursor{Xid: 100, Sequence: 10},
+	}
+	statusChanged := func(sequence int64, from, to models.InvoiceStatus) models.ActivityEvent {
+		payload, err := json.Marshal(models.StatusChangedPayload{From: from, To: to})
+		require.NoError(t, err)
+		return models.ActivityEvent{
+			Sequence: sequence,
+			Xid:      100,
+			InvoiceActivity: models.InvoiceActivity{
+				ActivityID: uuid.New(),
+				UserID:     userID,
+				EventType:  models.ActivityEventStatusChanged,
+				Payload:    payload,
+			},
+		}
+	}
+
+	t.Run("events of the webhook types", func(t *testing.T) {
+		paid := statusChanged(11, models.InvoiceStatusPending, models.InvoiceStatusPaid)
+		repo.EXPECT().GetActiveWebhooks(gomock.Any()).Return([]models.Webhook{hook}, nil)
+		events.EXPECT().
+			GetActivityEvents(gomock.Any(), userID, hook.LastEvent, int32(webhookEventBatchSize)).
+			Return([]models.ActivityEvent{
+				paid,
+				statusChanged(12, models.InvoiceStatusPending, models.InvoiceStatusOverDue),
+				// the last event was recorded by a newer transaction with a lower sequence number
+				{Sequence: 9, Xid: 101, InvoiceActivity: models.InvoiceActivity{EventType: models.ActivityEventComment}},
+			}, nil)
+		repo.EXPECT().
+			QueueWebhookDeliveries(gomock.Any(), hook.WebhookID, gomock.Any(), models.EventCursor{Xid: 101, Sequence: 9}).
+			DoAndReturn(func(_ context.Context, _ uuid.UUID, deliveries []models.WebhookDelivery, _ models.EventCursor) error {
+				require.Len(t, deliveries, 1)
+				require.Equal(t, models.WebhookEventInvoicePaid, deliveries[0].EventType)
+				require.Equal(t, models.WebhookDeliveryStatusPending, deliveries[0].Status)
+				require.Equal(t, uuid.NewSHA1(paid.ActivityID, []byte(models.WebhookEventInvoicePaid)), deliveries[0].EventID)
+
+				var event webhook.Event
+				require.NoError(t, json.Unmarshal(deliveries[0].Payload, &event))
+				require.Equal(t, deliveries[0].EventID, event.ID)
+				require.Equal(t, paid.ActivityID, event.Data.ActivityID)
+				return nil
+			})
+
+		queued, err := service.QueueWebhookDeliveries(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, queued)
+	})
+
+	t.Run("no new events", func(t *testing.T) {
+		repo.EXPECT().GetActiveWebhooks(gomock.Any()).Return([]models.Webhook{hook}, nil)
+		events.EXPECT().GetActivityEvents(gomock.Any(), userID, hook.LastEvent, gomock.Any()).Return(nil, nil)
+
+		queued, err := service.QueueWebhookDeliveries(ctx)
+		require.NoError(t, err)
+		require.Zero(t, queued)
+	})
+
+	t.Run("failing webhook does not stop the others", func(t *testing.T) {
+		other := hook
+		other.WebhookID, other.UserID = uuid.New(), uuid.New()
+		repo.EXPECT().GetActiveWebhooks(gomock.Any()).Return([]models.Webhook{hook, other}, nil)
+		events.EXPECT().GetActivityEvents(gomock.Any(), userID, gomock.Any(), gomock.Any()).Return(nil, errors.New("database error"))
+		events.EXPECT().
+			GetActivityEvents(gomock.Any(), other.UserID, gomock.Any(), gomock.Any()).
+			Return([]models.ActivityEvent{statusChanged(20, models.InvoiceStatusDraft, models.InvoiceStatusPending)}, nil)
+		repo.EXPECT().QueueWebhookDeliveries(gomock.Any(), other.WebhookID, gomock.Len(1), models.EventCursor{Xid: 100, Sequence: 20}).Return(nil)
+
+		queued, err := service.QueueWebhookDeliveries(ctx)
+		require.ErrorContains(t, err, "database error")
+		require.Equal(t, 1, queued)
+	})
+}
+
+func TestSendDueWebhookDeliveries(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocked.NewMockWebhookRepository(ctrl)
+	sender := mocked.NewMockSender(ctrl)
+	service := newWebhookServiceImpl(repo, mocked.NewMockEventRepository(ctrl), sender)
+	asOf := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	due := func(attempts int) models.DueWebhookDelivery {
+		return models.DueWebhookDelivery{
+			Delivery: models.WebhookDelivery{
+				DeliveryID: uuid.New(),
+				WebhookID:  uuid.New(),
+				EventType:  models.WebhookEventInvoicePaid,
+				Payload:    json.RawMessage(`{"type":"invoice.paid"}`),
+				Attempts:   attempts,
+			},
+			URL:    "https://example.com/hooks",
+			Secret: "whsec_secret",
+		}
+	}
+
+	t.Run("outcomes", func(t *testing.T) {
+		succeeded, retried, failed := due(0), due(2), due(maxWebhookAttempts-1)
+		repo.EXPECT().
+			ClaimDueWebhookDeliveries(gomock.Any(), asOf, webhookDeliveryLease, int32(webhookDeliveryBatchSize)).
+			Return([]models.DueWebhookDelivery{succeeded, retried, failed}, nil)
+
+		sender.EXPECT().
+			Send(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, req webhook.Request) (int, error) {
+				require.Equal(t, succeeded.URL, req.URL)
+				require.Equal(t, succeeded.Secret, req.Secret)
+				require.Equal(t, succeeded.Delivery.DeliveryID, req.DeliveryID)
+				require.JSONEq(t, string(succeeded.Delivery.Payload), string(req.Body))
+				return 200, nil
+			})
+		sender.EXPECT().Send(gomock.Any(), gomock.Any()).Return(500, errors.New("webhook responded with status 500"))
+		sender.EXPECT().Send(gomock.Any(), gomock.Any()).Return(0, errors.New("connection refused"))
+
+		var attempts []models.WebhookDeliveryAttempt
+		repo.EXPECT().
+			RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Any(), maxWebhookFailures).
+			DoAndReturn(func(_ context.Context, attempt models.WebhookDeliveryAttempt, _ int) error {
+				attempts = append(attempts, attempt)
+				return nil
+			}).
+			Times(3)
+
+		delivered, err := service.SendDueWebhookDeliveries(ctx, asOf)
+		require.NoError(t, err)
+		require.Equal(t, 1, delivered)
+		require.Len(t, attempts, 3)
+
+		require.Equal(t, models.WebhookDeliveryStatusSucceeded, attempts[0].Status)
+		require.Equal(t, 200, *attempts[0].ResponseStatus)
+		require.Nil(t, attempts[0].NextAttemptAt)
+
+		// the third attempt waits four times the first retry delay
+		require.Equal(t, models.WebhookDeliveryStatusPending, attempts[1].Status)
+		require.Equal(t, 500, *attempts[1].ResponseStatus)
+		require.Equal(t, asOf.Add(4*webhookRetryDelay), *attempts[1].NextAttemptAt)
+		require.Contains(t, attempts[1].Error, "status 500")
+
+		require.Equal(t, models.WebhookDeliveryStatusFailed, attempts[2].Status)
+		require.Nil(t, attempts[2].ResponseStatus)
+		require.Nil(t, attempts[2].NextAttemptAt)
+		require.Equal(t, "connection refused", attempts[2].Error)
+	})
+
+	t.Run("claims batches until none are left", func(t *testing.T) {
+		batch := make([]models.DueWebhookDelivery, webhookDeliveryBatchSize)
+		for i := range batch {
+			batch[i] = due(0)
+		}
+		gomock.InOrder(
+			repo.EXPECT().ClaimDueWebhookDeliveries(gomock.Any(), asOf, gomock.Any(), gomock.Any()).Return(batch, nil),
+			repo.EXPECT().ClaimDueWebhookDeliveries(gomock.Any(), asOf, gomock.Any(), gomock.Any()).Return(nil, nil),
+		)
+		sender.EXPECT().Send(gomock.Any(), gomock.Any()).Return(204, nil).Times(webhookDeliveryBatchSize)
+		repo.EXPECT().RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(webhookDeliveryBatchSize)
+
+		delivered, err := service.SendDueWebhookDeliveries(ctx, asOf)
+		require.NoError(t, err)
+		require.Equal(t, webhookDeliveryBatchSize, delivered)
+	})
+
+	t.Run("record error", func(t *testing.T) {
+		repo.EXPECT().ClaimDueWebhookDeliveries(gomock.Any(), asOf, gomock.Any(), gomock.Any()).Return([]models.DueWebhookDelivery{due(0)}, nil)
+		sender.EXPECT().Send(gomock.Any(), gomock.Any()).Return(200, nil)
+		repo.EXPECT().RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("database error"))
+
+		delivered, err := service.SendDueWebhookDeliveries(ctx, asOf)
+		require.ErrorContains(t, err, "database error")
+		require.Zero(t, delivered)
+	})
+
+	t.Run("claim error", func(t *testing.T) {
+		repo.EXPECT().ClaimDueWebhookDeliveries(gomock.Any(), asOf, gomock.Any(), gomock.Any()).Return(nil, errors.New("database error"))
+
+		_, err := service.SendDueWebhookDeliveries(ctx, asOf)
+		require.Error(t, err)
+	})
+}
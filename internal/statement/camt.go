@@ -0,0 +1,118 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"slices"
+
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+// camtDocument is an ISO 20022 bank to customer statement (camt.053). The elements are matched in any namespace, so
+// every version of the message is read.
+type camtDocument struct {
+	Statements []struct {
+		Entries []camtEntry `xml:"Ntry"`
+	} `xml:"BkToCstmrStmt>Stmt"`
+}
+
+// camtEntry is an entry of a statement, which holds the details of one transaction, or of each transaction of a
+// batch booked as a whole.
+type camtEntry struct {
+	Amount      camtAmount `xml:"Amt"`
+	CreditDebit string     `xml:"CdtDbtInd"`
+	Status      struct {
+		Text string `xml:",chardata"`
+		Code string `xml:"Cd"`
+	} `xml:"Sts"`
+	BookingDate struct {
+		Date     string `xml:"Dt"`
+		DateTime string `xml:"DtTm"`
+	} `xml:"BookgDt"`
+	Reference      string            `xml:"AcctSvcrRef"`
+	Transactions   []camtTransaction `xml:"NtryDtls>TxDtls"`
+	AdditionalInfo string            `xml:"AddtlNtryInf"`
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+// camtTransaction is the details of a transaction of an entry. The amount is only given for the transactions of a
+// batch, and the debtor's name is nested in a party from version 8 of the message on.
+type camtTransaction struct {
+	Reference       string      `xml:"Refs>AcctSvcrRef"`
+	EndToEndID      string      `xml:"Refs>EndToEndId"`
+	Amount          *camtAmount `xml:"Amt"`
+	DebtorName      string      `xml:"RltdPties>Dbtr>Nm"`
+	DebtorPartyName string      `xml:"RltdPties>Dbtr>Pty>Nm"`
+	Unstructured    []string    `xml:"RmtInf>Ustrd"`
+	Structured      []string    `xml:"RmtInf>Strd>CdtrRefInf>Ref"`
+}
+
+// parseCAMT053 reads the credits of the booked entries of a camt.053 statement. The transactions of a batch entry
+// are read as credits of their own when each has an amount.
+func parseCAMT053(data []byte) ([]models.BankTransaction, error) {
+	var document camtDocument
+	if err := xml.NewDecoder(bytes.NewReader(data)).Decode(&document); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidStatement, err)
+	}
+
+	credits := make([]models.BankTransaction, 0)
+	for _, statement := range document.Statements {
+		for _, entry := range statement.Entries {
+			status := entry.Status.Code
+			if status == "" {
+				status = entry.Status.Text
+			}
+			if entry.CreditDebit != "CRDT" || (status != "" && status != "BOOK") {
+				continue
+			}
+			bookingDate := entry.BookingDate.Date
+			if bookingDate == "" {
+				bookingDate = entry.BookingDate.DateTime
+			}
+
+			transactions := entry.Transactions
+			batch := len(transactions) > 1
+			for _, transaction := range transactions {
+				batch = batch && transaction.Amount != nil
+			}
+			if !batch {
+				transaction := camtTransaction{}
+				if len(transactions) > 0 {
+					transaction = transactions[0]
+				}
+				transaction.Reference, transaction.Amount = entry.Reference, &entry.Amount
+				transactions = []camtTransaction{transaction}
+			}
+
+			for i, transaction := range transactions {
+				id := transaction.Reference
+				if id == "" && entry.Reference != "" {
+					id = fmt.Sprintf("%s/%d", entry.Reference, i+1)
+				}
+				name := transaction.DebtorName
+				if name == "" {
+					name = transaction.DebtorPartyName
+				}
+				remittance := slices.Concat(transaction.Structured, transaction.Unstructured)
+				if len(remittance) == 0 {
+					remittance = []string{entry.AdditionalInfo}
+				}
+
+				credit, err := newCredit(id, bookingDate, transaction.Amount.Value, transaction.Amount.Currency, name,
+					remittance...)
+				if err != nil {
+					return nil, fmt.Errorf("%w: entry %s: %v", ErrInvalidStatement, entry.Reference, err)
+				}
+				if credit.Amount > 0 {
+					credits = append(credits, credit)
+				}
+			}
+		}
+	}
+	return credits, nil
+}
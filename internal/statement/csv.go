@@ -0,0 +1,67 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+// csvColumns are the columns every CSV statement has. It may also have transaction_id, name, description and
+// reference columns.
+var csvColumns = []string{"date", "amount", "currency"}
+
+// parseCSV reads the credits of a CSV statement with a row per transaction, whose amount is negative for debits.
+func parseCSV(data []byte) ([]models.BankTransaction, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: the file is empty", ErrInvalidStatement)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidStatement, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range csvColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing column %s", ErrInvalidStatement, name)
+		}
+	}
+
+	credits := make([]models.BankTransaction, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidStatement, err)
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return record[i]
+			}
+			return ""
+		}
+		credit, err := newCredit(field("transaction_id"), field("date"), field("amount"), field("currency"),
+			field("name"), field("description"), field("reference"))
+		if err != nil {
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidStatement, line, err)
+		}
+		if credit.Amount > 0 {
+			credits = append(credits, credit)
+		}
+	}
+	return credits, nil
+}
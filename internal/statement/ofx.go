@@ -0,0 +1,84 @@
+package statement
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+// parseOFX reads the credits of an OFX statement. Both the SGML syntax of OFX 1.x, whose elements need not be
+// closed, and the XML syntax of OFX 2.x are read, by reading every element as a tag followed by its value. The
+// currency of a transaction is the default currency of its statement unless the transaction names another one.
+func parseOFX(data []byte) ([]models.BankTransaction, error) {
+	text := string(data)
+	start := strings.Index(strings.ToUpper(text), "<OFX>")
+	if start < 0 {
+		return nil, fmt.Errorf("%w: missing OFX element", ErrInvalidStatement)
+	}
+
+	credits := make([]models.BankTransaction, 0)
+	var currency string
+	var transaction map[string]string
+	addTransaction := func() error {
+		if transaction == nil {
+			return nil
+		}
+		transactionCurrency := transaction["CURSYM"]
+		if transactionCurrency == "" {
+			transactionCurrency = currency
+		}
+		credit, err := newCredit(transaction["FITID"], transaction["DTPOSTED"], transaction["TRNAMT"],
+			transactionCurrency, transaction["NAME"], transaction["MEMO"])
+		if err != nil {
+			return fmt.Errorf("%w: transaction %s: %v", ErrInvalidStatement, transaction["FITID"], err)
+		}
+		if credit.Amount > 0 {
+			credits = append(credits, credit)
+		}
+		transaction = nil
+		return nil
+	}
+
+	for rest := text[start:]; ; {
+		open := strings.IndexByte(rest, '<')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[open:], '>')
+		if end < 0 {
+			return nil, fmt.Errorf("%w: unterminated element", ErrInvalidStatement)
+		}
+		tag := strings.ToUpper(strings.TrimSpace(rest[open+1 : open+end]))
+		rest = rest[open+end+1:]
+		value := rest
+		if next := strings.IndexByte(rest, '<'); next >= 0 {
+			value = rest[:next]
+		}
+		value = strings.TrimSpace(html.UnescapeString(value))
+
+		switch {
+		case tag == "STMTTRN":
+			if err := addTransaction(); err != nil {
+				return nil, err
+			}
+			transaction = make(map[string]string)
+		case tag == "/STMTTRN":
+			if err := addTransaction(); err != nil {
+				return nil, err
+			}
+		case tag == "CURDEF":
+			currency = value
+		case transaction != nil && !strings.HasPrefix(tag, "/") && value != "":
+			// the first element wins, so the name of a transaction is not taken from its payee's address
+			if _, ok := transaction[tag]; !ok {
+				transaction[tag] = value
+			}
+		}
+	}
+	if err := addTransaction(); err != nil {
+		return nil, err
+	}
+	return credits, nil
+}
@@ -0,0 +1,163 @@
+package statement
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zde37/Numeris-Task/internal/helpers"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+// Format is a bank statement file format.
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatOFX     Format = "ofx"
+	FormatCAMT053 Format = "camt.053"
+)
+
+// maxCredits is the largest number of credits a statement may hold.
+const maxCredits = 10000
+
+// ErrInvalidStatement is returned when a bank statement cannot be read.
+var ErrInvalidStatement = errors.New("invalid bank statement")
+
+// Parse reads the incoming credits of a bank statement in the format, or in the format detected from its content
+// when the format is empty, and returns them as bank transactions along with the format read. Debits are skipped.
+// Credits without an id of the bank are given a fingerprint of their details as external id.
+func Parse(data []byte, format Format) (Format, []models.BankTransaction, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	if format == "" {
+		format = detect(data)
+	}
+
+	var credits []models.BankTransaction
+	var err error
+	switch format {
+	case FormatCSV:
+		credits, err = parseCSV(data)
+	case FormatOFX:
+		credits, err = parseOFX(data)
+	case FormatCAMT053:
+		credits, err = parseCAMT053(data)
+	default:
+		return "", nil, fmt.Errorf("%w: unknown format %q, must be csv, ofx or camt.053", ErrInvalidStatement, format)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	if len(credits) > maxCredits {
+		return "", nil, fmt.Errorf("%w: more than %d credits", ErrInvalidStatement, maxCredits)
+	}
+
+	fingerprint(credits)
+	return format, credits, nil
+}
+
+// detect guesses the format of a statement from its content: camt.053 and OFX files are recognised by their root
+// elements, and anything else is read as CSV.
+func detect(data []byte) Format {
+	head := data[:min(len(data), 4096)]
+	switch {
+	case bytes.Contains(head, []byte("BkToCstmrStmt")):
+		return FormatCAMT053
+	case bytes.Contains(head, []byte("OFXHEADER")) || bytes.Contains(head, []byte("<OFX>")):
+		return FormatOFX
+	default:
+		return FormatCSV
+	}
+}
+
+// newCredit validates the details of a credit read from a statement and returns it as a bank transaction.
+func newCredit(externalID, bookingDate, amount, currency, name string, remittance ...string) (models.BankTransaction, error) {
+	date, err := parseDate(bookingDate)
+	if err != nil {
+		return models.BankTransaction{}, err
+	}
+	value, err := parseAmount(amount)
+	if err != nil {
+		return models.BankTransaction{}, err
+	}
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if err := helpers.ValidateCurrency(currency); err != nil {
+		return models.BankTransaction{}, err
+	}
+
+	return models.BankTransaction{
+		ExternalID:       strings.TrimSpace(externalID),
+		BookingDate:      date,
+		Amount:           value,
+		Currency:         currency,
+		CounterpartyName: collapse(name),
+		RemittanceInfo:   collapse(strings.Join(remittance, " ")),
+	}, nil
+}
+
+// parseDate parses the date of a date or timestamp, either ISO 8601 formatted (2024-06-01, 2024-06-01T10:00:00)
+// as in CSV and camt.053 statements, or compact (20240601, 20240601100000[-5:EST]) as in OFX statements.
+func parseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	var date time.Time
+	var err error
+	switch {
+	case len(value) >= 10 && value[4] == '-':
+		date, err = time.Parse("2006-01-02", value[:10])
+	case len(value) >= 8:
+		date, err = time.Parse("20060102", value[:8])
+	default:
+		err = fmt.Errorf("too short")
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return date, nil
+}
+
+// parseAmount parses an amount with a decimal point or comma, rounded to cents. When the amount has both, the last
+// one is the decimal separator and the other separates thousands.
+func parseAmount(value string) (float64, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), " ", "")
+	point, comma := strings.LastIndex(value, "."), strings.LastIndex(value, ",")
+	switch {
+	case point >= 0 && comma > point:
+		value = strings.ReplaceAll(strings.ReplaceAll(value, ".", ""), ",", ".")
+	case comma >= 0 && point > comma:
+		value = strings.ReplaceAll(value, ",", "")
+	default:
+		value = strings.ReplaceAll(value, ",", ".")
+	}
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	return math.Round(amount*100) / 100, nil
+}
+
+// collapse trims the value and replaces every run of whitespace in it with a single space.
+func collapse(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// fingerprint gives the credits without an external id a hash of their details as external id. Identical credits
+// are told apart by the order they appear in, so they keep their fingerprints when the statement is imported again.
+func fingerprint(credits []models.BankTransaction) {
+	seen := make(map[string]int)
+	for i := range credits {
+		if credits[i].ExternalID != "" {
+			continue
+		}
+		details := fmt.Sprintf("%s|%.2f|%s|%s|%s", credits[i].BookingDate.Format("2006-01-02"), credits[i].Amount,
+			credits[i].Currency, credits[i].CounterpartyName, credits[i].RemittanceInfo)
+		seen[details]++
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d", details, seen[details])))
+		credits[i].ExternalID = "sha256:" + hex.EncodeToString(sum[:])
+	}
+}
@@ -0,0 +1,230 @@
+package statement
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+const ofxStatement = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>NGN
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20240601120000[+1:WAT]
+<TRNAMT>1,500.00
+<FITID>FIT-1
+<NAME>ACME &amp; SONS LTD
+<MEMO>Payment for INV-1001
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20240602
+<TRNAMT>-200.00
+<FITID>FIT-2
+<NAME>Landlord
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20240603
+<TRNAMT>75.5
+<FITID>FIT-3
+<NAME>Globex
+<CURRENCY><CURRATE>1.0<CURSYM>USD</CURRENCY>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>`
+
+const camtStatement = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.08">
+<BkToCstmrStmt>
+<Stmt>
+<Ntry>
+  <Amt Ccy="EUR">250.00</Amt>
+  <CdtDbtInd>CRDT</CdtDbtInd>
+  <Sts><Cd>BOOK</Cd></Sts>
+  <BookgDt><Dt>2024-06-01</Dt></BookgDt>
+  <AcctSvcrRef>REF-1</AcctSvcrRef>
+  <NtryDtls><TxDtls>
+    <RltdPties><Dbtr><Pty><Nm>Initech GmbH</Nm></Pty></Dbtr></RltdPties>
+    <RmtInf><Ustrd>Invoice INV-2001</Ustrd></RmtInf>
+  </TxDtls></NtryDtls>
+</Ntry>
+<Ntry>
+  <Amt Ccy="EUR">90.00</Amt>
+  <CdtDbtInd>DBIT</CdtDbtInd>
+  <Sts><Cd>BOOK</Cd></Sts>
+  <BookgDt><Dt>2024-06-01</Dt></BookgDt>
+  <AcctSvcrRef>REF-2</AcctSvcrRef>
+</Ntry>
+<Ntry>
+  <Amt Ccy="EUR">40.00</Amt>
+  <CdtDbtInd>CRDT</CdtDbtInd>
+  <Sts><Cd>PDNG</Cd></Sts>
+  <BookgDt><Dt>2024-06-02</Dt></BookgDt>
+  <AcctSvcrRef>REF-3</AcctSvcrRef>
+</Ntry>
+<Ntry>
+  <Amt Ccy="EUR">300.00</Amt>
+  <CdtDbtInd>CRDT</CdtDbtInd>
+  <Sts><Cd>BOOK</Cd></Sts>
+  <BookgDt><DtTm>2024-06-03T09:30:00+01:00</DtTm></BookgDt>
+  <AcctSvcrRef>REF-4</AcctSvcrRef>
+  <NtryDtls>
+    <TxDtls>
+      <Amt Ccy="EUR">100.00</Amt>
+      <RltdPties><Dbtr><Nm>Hooli</Nm></Dbtr></RltdPties>
+      <RmtInf><Strd><CdtrRefInf><Ref>RF18INV2002</Ref></CdtrRefInf></Strd></RmtInf>
+    </TxDtls>
+    <TxDtls>
+      <Refs><AcctSvcrRef>REF-4-B</AcctSvcrRef></Refs>
+      <Amt Ccy="EUR">200.00</Amt>
+      <RltdPties><Dbtr><Nm>Umbrella</Nm></Dbtr></RltdPties>
+    </TxDtls>
+  </NtryDtls>
+</Ntry>
+</Stmt>
+</BkToCstmrStmt>
+</Document>`
+
+func TestParseCSV(t *testing.T) {
+	data := "\ufeffDate,Amount,Currency,Transaction_ID,Name,Description,Reference\n" +
+		"2024-06-01,\"1,250.00\",ngn,TX-1,  Acme   Ltd ,Invoice INV-1001,\n" +
+		"2024-06-02,-40,NGN,TX-2,Bank,Fees,\n" +
+		"2024-06-03,99.99,NGN,,Globex,Payment,REF 7\n"
+
+	format, credits, err := Parse([]byte(data), "")
+	require.NoError(t, err)
+	require.Equal(t, FormatCSV, format)
+	require.Len(t, credits, 2)
+
+	require.Equal(t, models.BankTransaction{
+		ExternalID:       "TX-1",
+		BookingDate:      time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		Amount:           1250,
+		Currency:         "NGN",
+		CounterpartyName: "Acme Ltd",
+		RemittanceInfo:   "Invoice INV-1001",
+	}, credits[0])
+	require.Equal(t, "Payment REF 7", credits[1].RemittanceInfo)
+	require.True(t, strings.HasPrefix(credits[1].ExternalID, "sha256:"))
+
+	t.Run("invalid rows", func(t *testing.T) {
+		for _, data := range []string{
+			"",
+			"date,amount\n2024-06-01,10\n",
+			"date,amount,currency\n01/06/2024,10,NGN\n",
+			"date,amount,currency\n2024-06-01,ten,NGN\n",
+			"date,amount,currency\n2024-06-01,10,XYZ\n",
+		} {
+			_, _, err := Parse([]byte(data), FormatCSV)
+			require.ErrorIs(t, err, ErrInvalidStatement, data)
+		}
+	})
+}
+
+func TestParseOFX(t *testing.T) {
+	format, credits, err := Parse([]byte(ofxStatement), "")
+	require.NoError(t, err)
+	require.Equal(t, FormatOFX, format)
+	require.Equal(t, []models.BankTransaction{
+		{
+			ExternalID:       "FIT-1",
+			BookingDate:      time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			Amount:           1500,
+			Currency:         "NGN",
+			CounterpartyName: "ACME & SONS LTD",
+			RemittanceInfo:   "Payment for INV-1001",
+		},
+		{
+			ExternalID:       "FIT-3",
+			BookingDate:      time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC),
+			Amount:           75.5,
+			Currency:         "USD",
+			CounterpartyName: "Globex",
+		},
+	}, credits)
+
+	_, _, err = Parse([]byte("<OFX><STMTTRN><TRNAMT>10<DTPOSTED>20240601</STMTTRN></OFX>"), FormatOFX)
+	require.ErrorIs(t, err, ErrInvalidStatement)
+	_, _, err = Parse([]byte("not an ofx file"), FormatOFX)
+	require.ErrorIs(t, err, ErrInvalidStatement)
+}
+
+func TestParseCAMT053(t *testing.T) {
+	format, credits, err := Parse([]byte(camtStatement), "")
+	require.NoError(t, err)
+	require.Equal(t, FormatCAMT053, format)
+	require.Equal(t, []models.BankTransaction{
+		{
+			ExternalID:       "REF-1",
+			BookingDate:      time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			Amount:           250,
+			Currency:         "EUR",
+			CounterpartyName: "Initech GmbH",
+			RemittanceInfo:   "Invoice INV-2001",
+		},
+		{
+			ExternalID:       "REF-4/1",
+			BookingDate:      time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC),
+			Amount:           100,
+			Currency:         "EUR",
+			CounterpartyName: "Hooli",
+			RemittanceInfo:   "RF18INV2002",
+		},
+		{
+			ExternalID:       "REF-4-B",
+			BookingDate:      time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC),
+			Amount:           200,
+			Currency:         "EUR",
+			CounterpartyName: "Umbrella",
+		},
+	}, credits)
+
+	_, _, err = Parse([]byte("<Document><BkToCstmrStmt>"), FormatCAMT053)
+	require.ErrorIs(t, err, ErrInvalidStatement)
+}
+
+func TestParse(t *testing.T) {
+	t.Run("unknown format", func(t *testing.T) {
+		_, _, err := Parse([]byte("date,amount,currency\n"), "mt940")
+		require.ErrorIs(t, err, ErrInvalidStatement)
+	})
+
+	t.Run("fingerprints tell identical credits apart", func(t *testing.T) {
+		data := []byte("date,amount,currency,name\n2024-06-01,10,NGN,Acme\n2024-06-01,10,NGN,Acme\n")
+
+		_, first, err := Parse(data, FormatCSV)
+		require.NoError(t, err)
+		require.Len(t, first, 2)
+		require.NotEqual(t, first[0].ExternalID, first[1].ExternalID)
+
+		_, again, err := Parse(data, FormatCSV)
+		require.NoError(t, err)
+		require.Equal(t, first, again)
+	})
+
+	t.Run("amounts", func(t *testing.T) {
+		for value, expected := range map[string]float64{
+			"1500":      1500,
+			"1500.5":    1500.5,
+			"1500,5":    1500.5,
+			"1,500.25":  1500.25,
+			"1.500,25":  1500.25,
+			" 12.345 ":  12.35,
+			"-1 000.00": -1000,
+		} {
+			amount, err := parseAmount(value)
+			require.NoError(t, err, value)
+			require.Equal(t, expected, amount, value)
+		}
+	})
+}
@@ -0,0 +1,220 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+// ibanLengths is the length of the IBANs of every country in the IBAN registry, by country code.
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16, "BG": 22, "BH": 22, "BI": 27,
+	"BR": 29, "BY": 28, "CH": 21, "CR": 22, "CY": 28, "CZ": 24, "DE": 22, "DJ": 27, "DK": 18, "DO": 28,
+	"EE": 20, "EG": 29, "ES": 24, "FI": 18, "FK": 18, "FO": 18, "FR": 27, "GB": 22, "GE": 22, "GI": 23,
+	"GL": 18, "GR": 27, "GT": 28, "HR": 21, "HU": 28, "IE": 22, "IL": 23, "IQ": 23, "IS": 26, "IT": 27,
+	"JO": 30, "KW": 30, "KZ": 20, "LB": 28, "LC": 32, "LI": 21, "LT": 20, "LU": 20, "LV": 21, "LY": 25,
+	"MC": 27, "MD": 24, "ME": 22, "MK": 19, "MN": 20, "MR": 27, "MT": 31, "MU": 30, "NI": 28, "NL": 18,
+	"NO": 15, "OM": 23, "PK": 24, "PL": 28, "PS": 29, "PT": 25, "QA": 29, "RO": 24, "RS": 22, "RU": 33,
+	"SA": 24, "SC": 31, "SD": 18, "SE": 24, "SI": 19, "SK": 24, "SM": 27, "SO": 23, "ST": 25, "SV": 28,
+	"TL": 23, "TN": 24, "TR": 26, "UA": 29, "VA": 22, "VG": 24, "XK": 20,
+}
+
+// IBAN checks that the value, without spaces, is an International Bank Account Number of a country in the IBAN
+// registry, with that country's length and a valid ISO 7064 mod 97-10 checksum.
+func IBAN(value string) error {
+	iban := strings.ToUpper(compact(value))
+	if len(iban) < 4 || !isUpperLetter(iban[0]) || !isUpperLetter(iban[1]) || !isDigit(iban[2]) || !isDigit(iban[3]) {
+		return fmt.Errorf("must start with a country code and two check digits")
+	}
+	length, ok := ibanLengths[iban[:2]]
+	if !ok {
+		return fmt.Errorf("country %s does not use IBANs", iban[:2])
+	}
+	if len(iban) != length {
+		return fmt.Errorf("must be %d characters for country %s", length, iban[:2])
+	}
+
+	// the country code and check digits are moved to the end, and letters are counted as the numbers 10 to 35
+	remainder := 0
+	for _, char := range iban[4:] + iban[:4] {
+		switch {
+		case char >= '0' && char <= '9':
+			remainder = (remainder*10 + int(char-'0')) % 97
+		case char >= 'A' && char <= 'Z':
+			remainder = (remainder*100 + int(char-'A') + 10) % 97
+		default:
+			return fmt.Errorf("must only hold letters and digits")
+		}
+	}
+	if remainder != 1 {
+		return fmt.Errorf("has an invalid checksum")
+	}
+	return nil
+}
+
+// BIC checks that the value is a Business Identifier Code (SWIFT code): a four letter institution code, a two letter
+// country code, a two character location code and an optional three character branch code.
+func BIC(value string) error {
+	bic := strings.ToUpper(strings.TrimSpace(value))
+	if len(bic) != 8 && len(bic) != 11 {
+		return fmt.Errorf("must be 8 or 11 characters")
+	}
+	for i := 0; i < len(bic); i++ {
+		switch {
+		case i < 6 && !isUpperLetter(bic[i]):
+			return fmt.Errorf("must start with a four letter institution code and a two letter country code")
+		case !isUpperLetter(bic[i]) && !isDigit(bic[i]):
+			return fmt.Errorf("must only hold letters and digits")
+		}
+	}
+	return nil
+}
+
+// ABARoutingNumber checks that the value is a US ABA routing transit number: nine digits starting with a Federal
+// Reserve routing symbol, whose weighted checksum is a multiple of 10.
+func ABARoutingNumber(value string) error {
+	if err := digits(value, 9, 9); err != nil {
+		return err
+	}
+	prefix := int(value[0]-'0')*10 + int(value[1]-'0')
+	if !(prefix <= 12 || (prefix >= 21 && prefix <= 32) || (prefix >= 61 && prefix <= 72) || prefix == 80) {
+		return fmt.Errorf("must start with a valid Federal Reserve routing symbol")
+	}
+
+	weights := [3]int{3, 7, 1}
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(value[i]-'0') * weights[i%3]
+	}
+	if sum%10 != 0 {
+		return fmt.Errorf("has an invalid checksum")
+	}
+	return nil
+}
+
+// SortCode checks that the value is a UK sort code: six digits, which may be grouped in pairs by dashes or spaces.
+func SortCode(value string) error {
+	return digits(compact(value), 6, 6)
+}
+
+// PaymentMethod normalizes the bank details of a payment method and checks them against the rules of its account
+// type, before it is stored:
+//   - iban accounts have an IBAN as account number;
+//   - us accounts have an account number of 4 to 17 digits and an ABA routing number;
+//   - uk accounts have an account number of 8 digits and a sort code;
+//   - other accounts, the default, have an account number in any format.
+//
+// Routing numbers and sort codes are only given for the account types that use them, and the SWIFT code must be a
+// BIC unless it is empty. IBANs, SWIFT codes and sort codes are stored upper cased and without spaces or dashes. It
+// returns Errors listing every invalid field.
+func PaymentMethod(method *models.UserPaymentMethod) error {
+	var errs Errors
+	if method.AccountType == "" {
+		method.AccountType = models.AccountTypeOther
+	}
+	method.RoutingNumber = strings.TrimSpace(method.RoutingNumber)
+	method.SortCode = compact(method.SortCode)
+	method.SwiftCode = strings.ToUpper(strings.TrimSpace(method.SwiftCode))
+
+	switch method.AccountType {
+	case models.AccountTypeIBAN:
+		method.AccountNumber = strings.ToUpper(compact(method.AccountNumber))
+		errs.add("account_number", IBAN(method.AccountNumber))
+	case models.AccountTypeUS:
+		method.AccountNumber = strings.TrimSpace(method.AccountNumber)
+		errs.add("account_number", digits(method.AccountNumber, 4, 17))
+		if method.RoutingNumber == "" {
+			errs.add("routing_number", fmt.Errorf("is required for us accounts"))
+		} else {
+			errs.add("routing_number", ABARoutingNumber(method.RoutingNumber))
+		}
+	case models.AccountTypeUK:
+		method.AccountNumber = compact(method.AccountNumber)
+		errs.add("account_number", digits(method.AccountNumber, 8, 8))
+		if method.SortCode == "" {
+			errs.add("sort_code", fmt.Errorf("is required for uk accounts"))
+		} else {
+			errs.add("sort_code", SortCode(method.SortCode))
+		}
+	case models.AccountTypeOther:
+	default:
+		errs.add("account_type", fmt.Errorf("must be one of iban, us, uk or other"))
+	}
+
+	if method.RoutingNumber != "" && method.AccountType != models.AccountTypeUS {
+		errs.add("routing_number", fmt.Errorf("is only used by us accounts"))
+	}
+	if method.SortCode != "" && method.AccountType != models.AccountTypeUK {
+		errs.add("sort_code", fmt.Errorf("is only used by uk accounts"))
+	}
+	if method.SwiftCode != "" {
+		errs.add("swift_code", BIC(method.SwiftCode))
+	}
+	return errs.err()
+}
+
+// PaymentMethodUpdate checks the bank details set in an update of a payment method, and normalizes them as
+// PaymentMethod does. Since the rules for the account number depend on the account type, the account type and
+// account number are updated together, along with the routing number and sort code of the new account.
+func PaymentMethodUpdate(update *models.PaymentMethodUpdate) error {
+	accountChanged := update.AccountType != nil || update.AccountNumber != nil || update.RoutingNumber != nil ||
+		update.SortCode != nil
+	if accountChanged {
+		var errs Errors
+		if update.AccountType == nil {
+			errs.add("account_type", fmt.Errorf("is required to change the account number, routing number or sort code"))
+		}
+		if update.AccountNumber == nil {
+			errs.add("account_number", fmt.Errorf("is required to change the account type, routing number or sort code"))
+		}
+		if len(errs) > 0 {
+			return errs
+		}
+	}
+
+	method := models.UserPaymentMethod{AccountType: models.AccountTypeOther}
+	if accountChanged {
+		method.AccountType, method.AccountNumber = *update.AccountType, *update.AccountNumber
+		method.RoutingNumber, method.SortCode = valueOf(update.RoutingNumber), valueOf(update.SortCode)
+	}
+	method.SwiftCode = valueOf(update.SwiftCode)
+	if err := PaymentMethod(&method); err != nil {
+		return err
+	}
+
+	if accountChanged {
+		update.AccountType, update.AccountNumber = &method.AccountType, &method.AccountNumber
+		update.RoutingNumber, update.SortCode = nonEmpty(method.RoutingNumber), nonEmpty(method.SortCode)
+	}
+	if update.SwiftCode != nil {
+		update.SwiftCode = &method.SwiftCode
+	}
+	return nil
+}
+
+// valueOf returns the value pointed to, or an empty string for nil.
+func valueOf(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// nonEmpty returns a pointer to the value, or nil when it is empty.
+func nonEmpty(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// isUpperLetter reports whether the character is an upper case ASCII letter.
+func isUpperLetter(char byte) bool {
+	return char >= 'A' && char <= 'Z'
+}
+
+// isDigit reports whether the character is an ASCII digit.
+func isDigit(char byte) bool {
+	return char >= '0' && char <= '9'
+}
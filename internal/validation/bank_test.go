@@ -0,0 +1,126 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+func TestIBAN(t *testing.T) {
+	require.NoError(t, IBAN("GB82WEST12345698765432"))
+	require.NoError(t, IBAN("de89 3704 0044 0532 0130 00"))
+	require.NoError(t, IBAN("NO9386011117947"))
+
+	require.EqualError(t, IBAN("GB82WEST12345698765433"), "has an invalid checksum")
+	require.EqualError(t, IBAN("GB82WEST1234569876543"), "must be 22 characters for country GB")
+	require.EqualError(t, IBAN("US82WEST12345698765432"), "country US does not use IBANs")
+	require.EqualError(t, IBAN("1234567890"), "must start with a country code and two check digits")
+	require.EqualError(t, IBAN("GB82WEST1234569876543!"), "must only hold letters and digits")
+}
+
+func TestBIC(t *testing.T) {
+	require.NoError(t, BIC("DEUTDEFF"))
+	require.NoError(t, BIC("deutdeff500"))
+
+	require.EqualError(t, BIC("DEUTDEF"), "must be 8 or 11 characters")
+	require.EqualError(t, BIC("DEU1DEFF"), "must start with a four letter institution code and a two letter country code")
+	require.EqualError(t, BIC("DEUTDEFF50!"), "must only hold letters and digits")
+}
+
+func TestABARoutingNumber(t *testing.T) {
+	require.NoError(t, ABARoutingNumber("021000021"))
+	require.NoError(t, ABARoutingNumber("111000025"))
+
+	require.EqualError(t, ABARoutingNumber("021000022"), "has an invalid checksum")
+	require.EqualError(t, ABARoutingNumber("02100002"), "must be 9 digits")
+	require.EqualError(t, ABARoutingNumber("02100002A"), "must be 9 digits")
+	require.EqualError(t, ABARoutingNumber("500000005"), "must start with a valid Federal Reserve routing symbol")
+}
+
+func TestSortCode(t *testing.T) {
+	require.NoError(t, SortCode("123456"))
+	require.NoError(t, SortCode("12-34-56"))
+	require.NoError(t, SortCode("12 34 56"))
+
+	require.EqualError(t, SortCode("12-34-5"), "must be 6 digits")
+	require.EqualError(t, SortCode("12-34-5A"), "must be 6 digits")
+}
+
+func TestPaymentMethod(t *testing.T) {
+	t.Run("iban account", func(t *testing.T) {
+		method := models.UserPaymentMethod{AccountType: models.AccountTypeIBAN, AccountNumber: "gb82 west 1234 5698 7654 32", SwiftCode: " westgb2l "}
+		require.NoError(t, PaymentMethod(&method))
+		require.Equal(t, "GB82WEST12345698765432", method.AccountNumber)
+		require.Equal(t, "WESTGB2L", method.SwiftCode)
+	})
+
+	t.Run("us account", func(t *testing.T) {
+		method := models.UserPaymentMethod{AccountType: models.AccountTypeUS, AccountNumber: "000123456789", RoutingNumber: "021000021"}
+		require.NoError(t, PaymentMethod(&method))
+
+		method = models.UserPaymentMethod{AccountType: models.AccountTypeUS, AccountNumber: "12-34"}
+		require.Equal(t, Errors{
+			{Field: "account_number", Message: "must be 4 to 17 digits"},
+			{Field: "routing_number", Message: "is required for us accounts"},
+		}, PaymentMethod(&method))
+	})
+
+	t.Run("uk account", func(t *testing.T) {
+		method := models.UserPaymentMethod{AccountType: models.AccountTypeUK, AccountNumber: "1234 5678", SortCode: "12-34-56"}
+		require.NoError(t, PaymentMethod(&method))
+		require.Equal(t, "12345678", method.AccountNumber)
+		require.Equal(t, "123456", method.SortCode)
+
+		method = models.UserPaymentMethod{AccountType: models.AccountTypeUK, AccountNumber: "1234567", RoutingNumber: "021000021"}
+		require.Equal(t, Errors{
+			{Field: "account_number", Message: "must be 8 digits"},
+			{Field: "sort_code", Message: "is required for uk accounts"},
+			{Field: "routing_number", Message: "is only used by us accounts"},
+		}, PaymentMethod(&method))
+	})
+
+	t.Run("other account", func(t *testing.T) {
+		method := models.UserPaymentMethod{AccountNumber: "Account 1", SwiftCode: "TESTSWIFT"}
+		err := PaymentMethod(&method)
+		require.Equal(t, models.AccountTypeOther, method.AccountType)
+		require.EqualError(t, err, "swift_code: must be 8 or 11 characters")
+	})
+
+	t.Run("invalid account type", func(t *testing.T) {
+		method := models.UserPaymentMethod{AccountType: "savings", AccountNumber: "1234"}
+		require.Equal(t, Errors{{Field: "account_type", Message: "must be one of iban, us, uk or other"}}, PaymentMethod(&method))
+	})
+}
+
+func TestPaymentMethodUpdate(t *testing.T) {
+	t.Run("account change", func(t *testing.T) {
+		accountType, accountNumber, routingNumber := models.AccountTypeUS, " 123456789 ", "021000021"
+		update := models.PaymentMethodUpdate{AccountType: &accountType, AccountNumber: &accountNumber, RoutingNumber: &routingNumber}
+		require.NoError(t, PaymentMethodUpdate(&update))
+		require.Equal(t, "123456789", *update.AccountNumber)
+		require.Equal(t, "021000021", *update.RoutingNumber)
+		require.Nil(t, update.SortCode)
+	})
+
+	t.Run("partial account change", func(t *testing.T) {
+		sortCode := "12-34-56"
+		update := models.PaymentMethodUpdate{SortCode: &sortCode}
+		require.Equal(t, Errors{
+			{Field: "account_type", Message: "is required to change the account number, routing number or sort code"},
+			{Field: "account_number", Message: "is required to change the account type, routing number or sort code"},
+		}, PaymentMethodUpdate(&update))
+	})
+
+	t.Run("swift code", func(t *testing.T) {
+		swiftCode, bankName := "deutdeff", "New Bank"
+		update := models.PaymentMethodUpdate{BankName: &bankName, SwiftCode: &swiftCode}
+		require.NoError(t, PaymentMethodUpdate(&update))
+		require.Equal(t, "DEUTDEFF", *update.SwiftCode)
+		require.Nil(t, update.AccountType)
+
+		swiftCode = "DEUT"
+		update = models.PaymentMethodUpdate{SwiftCode: &swiftCode}
+		require.EqualError(t, PaymentMethodUpdate(&update), "swift_code: must be 8 or 11 characters")
+	})
+}
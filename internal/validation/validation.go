@@ -0,0 +1,65 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FieldError is the reason a single field of a request is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors are the invalid fields of a request, in the order the fields were checked in.
+type Errors []FieldError
+
+// Error joins the field errors into a single message.
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Field + ": " + fieldErr.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// add records err as the error of the field, unless it is nil.
+func (e *Errors) add(field string, err error) {
+	if err != nil {
+		*e = append(*e, FieldError{Field: field, Message: err.Error()})
+	}
+}
+
+// err returns the field errors as an error, or nil when there are none.
+func (e Errors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// FieldErrors returns the field errors err holds, or nil when it holds none.
+func FieldErrors(err error) Errors {
+	var fieldErrs Errors
+	if errors.As(err, &fieldErrs) {
+		return fieldErrs
+	}
+	return nil
+}
+
+// digits checks that the value only holds between min and max decimal digits.
+func digits(value string, min, max int) error {
+	if len(value) < min || len(value) > max || strings.Trim(value, "0123456789") != "" {
+		if min == max {
+			return fmt.Errorf("must be %d digits", min)
+		}
+		return fmt.Errorf("must be %d to %d digits", min, max)
+	}
+	return nil
+}
+
+// compact removes the spaces and dashes bank details are often written with.
+func compact(value string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(value))
+}
@@ -0,0 +1,18 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldErrors(t *testing.T) {
+	errs := Errors{{Field: "account_number", Message: "has an invalid checksum"}, {Field: "swift_code", Message: "must be 8 or 11 characters"}}
+	require.EqualError(t, errs, "account_number: has an invalid checksum; swift_code: must be 8 or 11 characters")
+
+	require.Equal(t, errs, FieldErrors(fmt.Errorf("failed to add payment method: %w", errs)))
+	require.Nil(t, FieldErrors(errors.New("database error")))
+	require.Nil(t, FieldErrors(nil))
+}
@@ -0,0 +1,237 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+const (
+	// SignatureHeader holds the signature of a delivery, which receivers compute again with Sign to check that the
+	// delivery comes from this server and was not altered.
+	SignatureHeader = "X-Webhook-Signature"
+	// TimestampHeader holds the Unix time a delivery was sent at, which is signed along with the body so receivers
+	// can reject old deliveries sent again.
+	TimestampHeader = "X-Webhook-Timestamp"
+	// EventHeader holds the type of event of a delivery.
+	EventHeader = "X-Webhook-Event"
+	// DeliveryHeader holds the ID of a delivery, which differs for every replay of an event.
+	DeliveryHeader = "X-Webhook-Delivery"
+
+	// RequestTimeout is how long a webhook URL is given to respond to a delivery, from connecting to the response.
+	RequestTimeout = 10 * time.Second
+	// secretSize is the number of random bytes of a generated secret.
+	secretSize = 32
+)
+
+// ErrPrivateAddress is returned when sending a delivery to a webhook URL that resolves to an address that is not
+// public, such as one of the servers' own network or a cloud metadata endpoint.
+var ErrPrivateAddress = errors.New("webhook address is not public")
+
+// nonPublicPrefixes are the ranges of special purpose addresses that are not public, besides the private, loopback,
+// link-local, multicast and unspecified ones.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// PublicAddress tells whether an address is reachable on the internet, so deliveries cannot reach the servers' own
+// network, such as the cloud metadata endpoint at 169.254.169.254.
+func PublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// Event is the body of a delivery: an invoice lifecycle event, with the activity it was recorded as. The ID of an
+// event is the same in every delivery of it, so receivers can tell deliveries of an event they already handled.
+type Event struct {
+	ID        uuid.UUID               `json:"id"`
+	Type      models.WebhookEventType `json:"type"`
+	CreatedAt time.Time               `json:"created_at"`
+	Data      EventData               `json:"data"`
+}
+
+// EventData is what an event is about: the invoice, its sender and the activity the event was recorded as.
+type EventData struct {
+	InvoiceID   uuid.UUID       `json:"invoice_id"`
+	UserID      uuid.UUID       `json:"user_id"`
+	ActivityID  uuid.UUID       `json:"activity_id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+// NewEvents returns the invoice lifecycle events an activity is, if any. A created invoice that is not a draft is
+// also sent, so its activity is two events.
+func NewEvents(activity models.InvoiceActivity) []Event {
+	var types []models.WebhookEventType
+	switch activity.EventType {
+	case models.ActivityEventInvoiceCreated:
+		types = append(types, models.WebhookEventInvoiceCreated)
+		var payload models.InvoiceCreatedPayload
+		if json.Unmarshal(activity.Payload, &payload) == nil && payload.Status != "" &&
+			payload.Status != models.InvoiceStatusDraft {
+			types = append(types, models.WebhookEventInvoiceSent)
+		}
+	case models.ActivityEventStatusChanged:
+		var payload models.StatusChangedPayload
+		if json.Unmarshal(activity.Payload, &payload) != nil {
+			return nil
+		}
+		switch {
+		case payload.To == models.InvoiceStatusPaid:
+			types = append(types, models.WebhookEventInvoicePaid)
+		case payload.To == models.InvoiceStatusOverDue:
+			types = append(types, models.WebhookEventInvoiceOverdue)
+		case payload.From == models.InvoiceStatusDraft && payload.To == models.InvoiceStatusPending:
+			types = append(types, models.WebhookEventInvoiceSent)
+		}
+	}
+
+	events := make([]Event, 0, len(types))
+	for _, eventType := range types {
+		events = append(events, Event{
+			// derived from the activity, so every server queueing the event gives it the same ID
+			ID:        uuid.NewSHA1(activity.ActivityID, []byte(eventType)),
+			Type:      eventType,
+			CreatedAt: activity.CreatedAt,
+			Data: EventData{
+				InvoiceID:   activity.InvoiceID,
+				UserID:      activity.UserID,
+				ActivityID:  activity.ActivityID,
+				Title:       activity.Title,
+				Description: activity.Description,
+				Payload:     activity.Payload,
+			},
+		})
+	}
+	return events
+}
+
+// Sign returns the signature of a delivery body sent at the Unix time timestamp: the hex encoded HMAC-SHA256, under
+// the secret of the webhook, of the timestamp and the body joined by a dot.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewSecret returns a random secret to sign the deliveries of a webhook with.
+func NewSecret() (string, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
+
+// Request is a delivery to send to the URL of a webhook.
+type Request struct {
+	URL        string
+	Secret     string
+	DeliveryID uuid.UUID
+	EventType  models.WebhookEventType
+	Body       []byte
+}
+
+// Sender sends deliveries to the URLs of webhooks.
+type Sender interface {
+	Send(ctx context.Context, req Request) (int, error)
+}
+
+type httpSender struct {
+	client *http.Client
+}
+
+// NewSender returns a Sender that posts deliveries to webhook URLs over HTTP, only to public addresses.
+func NewSender() Sender {
+	return newSender(PublicAddress)
+}
+
+// newSender returns a Sender that posts deliveries to the addresses allowed. Addresses are checked when connecting
+// rather than when webhooks are created, as host names may resolve to other addresses by the time deliveries are
+// sent. Redirects are not followed, and proxies are not used, so deliveries only connect to addresses checked.
+func newSender(allowed func(netip.Addr) bool) Sender {
+	dialer := &net.Dialer{
+		Timeout: RequestTimeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !allowed(addrPort.Addr()) {
+				return ErrPrivateAddress
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &httpSender{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   RequestTimeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Send posts a signed delivery to its webhook URL and returns the status code of the response, or 0 when there was
+// no response. Responses with a status code other than 2xx, redirects included, are reported as errors. Their body is
+// left out, so the responses of other servers are not shown to the owners of webhooks.
+func (s *httpSender) Send(ctx context.Context, req Request) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "Numeris-Book-Webhooks")
+	request.Header.Set(EventHeader, string(req.EventType))
+	request.Header.Set(DeliveryHeader, req.DeliveryID.String())
+	request.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(SignatureHeader, Sign(req.Secret, timestamp, req.Body))
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
@@ -0,0 +1,200 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/zde37/Numeris-Task/internal/models"
+)
+
+func activity(t *testing.T, eventType models.ActivityEventType, payload any) models.InvoiceActivity {
+	data, err := json.Marshal(payload)
+	require.NoError(t, err)
+	return models.InvoiceActivity{
+		ActivityID: uuid.New(),
+		InvoiceID:  uuid.New(),
+		UserID:     uuid.New(),
+		EventType:  eventType,
+		Payload:    data,
+		Title:      "Activity",
+		CreatedAt:  time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func eventTypes(events []Event) []models.WebhookEventType {
+	types := []models.WebhookEventType{}
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func TestNewEvents(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		eventType models.ActivityEventType
+		payload   any
+		want      []models.WebhookEventType
+	}{
+		{"draft created", models.ActivityEventInvoiceCreated, models.InvoiceCreatedPayload{Status: models.InvoiceStatusDraft}, []models.WebhookEventType{models.WebhookEventInvoiceCreated}},
+		{"created and sent", models.ActivityEventInvoiceCreated, models.InvoiceCreatedPayload{Status: models.InvoiceStatusPending}, []models.WebhookEventType{models.WebhookEventInvoiceCreated, models.WebhookEventInvoiceSent}},
+		{"sent", models.ActivityEventStatusChanged, models.StatusChangedPayload{From: models.InvoiceStatusDraft, To: models.InvoiceStatusPending}, []models.WebhookEventType{models.WebhookEventInvoiceSent}},
+		{"paid", models.ActivityEventStatusChanged, models.StatusChangedPayload{From: models.InvoiceStatusPending, To: models.InvoiceStatusPaid}, []models.WebhookEventType{models.WebhookEventInvoicePaid}},
+		{"overdue", models.ActivityEventStatusChanged, models.StatusChangedPayload{From: models.InvoiceStatusPending, To: models.InvoiceStatusOverDue}, []models.WebhookEventType{models.WebhookEventInvoiceOverdue}},
+		{"other status change", models.ActivityEventStatusChanged, models.StatusChangedPayload{From: models.InvoiceStatusOverDue, To: models.InvoiceStatusPending}, []models.WebhookEventType{}},
+		{"comment", models.ActivityEventComment, map[string]string{"text": "hello"}, []models.WebhookEventType{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, eventTypes(NewEvents(activity(t, tc.eventType, tc.payload))))
+		})
+	}
+
+	t.Run("event IDs are derived from the activity", func(t *testing.T) {
+		created := activity(t, models.ActivityEventInvoiceCreated, models.InvoiceCreatedPayload{Status: models.InvoiceStatusPending})
+
+		events := NewEvents(created)
+		require.Len(t, events, 2)
+		require.NotEqual(t, events[0].ID, events[1].ID)
+		require.Equal(t, events, NewEvents(created))
+
+		require.Equal(t, created.ActivityID, events[0].Data.ActivityID)
+		require.Equal(t, created.InvoiceID, events[0].Data.InvoiceID)
+		require.Equal(t, created.UserID, events[0].Data.UserID)
+		require.Equal(t, created.CreatedAt, events[0].CreatedAt)
+	})
+}
+
+func TestSign(t *testing.T) {
+	signature := Sign("secret", 1700000000, []byte(`{"id":"1"}`))
+	require.Len(t, signature, 64)
+	require.Equal(t, signature, Sign("secret", 1700000000, []byte(`{"id":"1"}`)))
+	require.NotEqual(t, signature, Sign("other", 1700000000, []byte(`{"id":"1"}`)))
+	require.NotEqual(t, signature, Sign("secret", 1700000001, []byte(`{"id":"1"}`)))
+	require.NotEqual(t, signature, Sign("secret", 1700000000, []byte(`{"id":"2"}`)))
+}
+
+func TestNewSecret(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(secret, "whsec_"))
+	require.Len(t, secret, len("whsec_")+2*secretSize)
+
+	other, err := NewSecret()
+	require.NoError(t, err)
+	require.NotEqual(t, secret, other)
+}
+
+func TestPublicAddress(t *testing.T) {
+	for _, addr := range []string{"93.184.216.34", "8.8.8.8", "2606:4700:4700::1111"} {
+		require.True(t, PublicAddress(netip.MustParseAddr(addr)), addr)
+	}
+	for _, addr := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "0.0.0.0",
+		"100.64.0.1", "224.0.0.1", "255.255.255.255", "::1", "::", "fe80::1", "fd00::1", "::ffff:127.0.0.1",
+		"64:ff9b::a00:1"} {
+		require.False(t, PublicAddress(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestSend(t *testing.T) {
+	ctx := context.Background()
+	// test servers listen on the loopback address, which deliveries are not sent to
+	sender := newSender(func(netip.Addr) bool { return true })
+	req := Request{
+		Secret:     "whsec_secret",
+		DeliveryID: uuid.New(),
+		EventType:  models.WebhookEventInvoicePaid,
+		Body:       []byte(`{"type":"invoice.paid"}`),
+	}
+
+	t.Run("signed delivery", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.Equal(t, http.MethodPost, r.Method)
+			require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			require.Equal(t, string(models.WebhookEventInvoicePaid), r.Header.Get(EventHeader))
+			require.Equal(t, req.DeliveryID.String(), r.Header.Get(DeliveryHeader))
+
+			timestamp, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+			require.NoError(t, err)
+			require.Equal(t, Sign(req.Secret, timestamp, body), r.Header.Get(SignatureHeader))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		req := req
+		req.URL = server.URL
+		status, err := sender.Send(ctx, req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNoContent, status)
+	})
+
+	t.Run("error response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "unknown event", http.StatusUnprocessableEntity)
+		}))
+		defer server.Close()
+
+		req := req
+		req.URL = server.URL
+		status, err := sender.Send(ctx, req)
+		// the response body is not kept
+		require.EqualError(t, err, "webhook responded with status 422")
+		require.Equal(t, http.StatusUnprocessableEntity, status)
+	})
+
+	t.Run("redirects are not followed", func(t *testing.T) {
+		redirected := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/internal" {
+				redirected = true
+				return
+			}
+			http.Redirect(w, r, "/internal", http.StatusTemporaryRedirect)
+		}))
+		defer server.Close()
+
+		req := req
+		req.URL = server.URL
+		status, err := sender.Send(ctx, req)
+		require.EqualError(t, err, "webhook responded with status 307")
+		require.Equal(t, http.StatusTemporaryRedirect, status)
+		require.False(t, redirected)
+	})
+
+	t.Run("private address", func(t *testing.T) {
+		received := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = true
+		}))
+		defer server.Close()
+
+		req := req
+		req.URL = server.URL
+		status, err := NewSender().Send(ctx, req)
+		require.ErrorIs(t, err, ErrPrivateAddress)
+		require.Zero(t, status)
+		require.False(t, received)
+	})
+
+	t.Run("no response", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		req := req
+		req.URL = server.URL
+		status, err := sender.Send(ctx, req)
+		require.Error(t, err)
+		require.Zero(t, status)
+	})
+}
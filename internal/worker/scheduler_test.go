@@ -0,0 +1,76 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler(t *testing.T) {
+	t.Run("runs jobs until cancelled", func(t *testing.T) {
+		var runs int32
+		scheduler := NewScheduler(Job{
+			Name:     "counter",
+			Interval: 10 * time.Millisecond,
+			Run: func(ctx context.Context) error {
+				atomic.AddInt32(&runs, 1)
+				return nil
+			},
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		scheduler.Start(ctx)
+		require.Eventually(t, func() bool { return atomic.LoadInt32(&runs) >= 3 }, time.Second, 5*time.Millisecond)
+
+		cancel()
+		scheduler.Wait()
+		stopped := atomic.LoadInt32(&runs)
+		time.Sleep(30 * time.Millisecond)
+		require.Equal(t, stopped, atomic.LoadInt32(&runs))
+	})
+
+	t.Run("keeps running after a failure", func(t *testing.T) {
+		var runs int32
+		scheduler := NewScheduler(Job{
+			Name:     "failing",
+			Interval: 10 * time.Millisecond,
+			Run: func(ctx context.Context) error {
+				atomic.AddInt32(&runs, 1)
+				return errors.New("job error")
+			},
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		scheduler.Start(ctx)
+		require.Eventually(t, func() bool { return atomic.LoadInt32(&runs) >= 2 }, time.Second, 5*time.Millisecond)
+
+		cancel()
+		scheduler.Wait()
+	})
+
+	t.Run("runs jobs immediately", func(t *testing.T) {
+		ran := make(chan struct{}, 1)
+		scheduler := NewScheduler(Job{
+			Name:     "hourly",
+			Interval: time.Hour,
+			Run: func(ctx context.Context) error {
+				ran <- struct{}{}
+				return nil
+			},
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		scheduler.Start(ctx)
+
+		select {
+		case <-ran:
+		case <-time.After(time.Second):
+			t.Fatal("job did not run on start")
+		}
+	})
+}
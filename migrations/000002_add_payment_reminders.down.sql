@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_invoices_due_date;
+DROP TABLE IF EXISTS "invoice_reminders";
+DROP TABLE IF EXISTS "reminder_rules";
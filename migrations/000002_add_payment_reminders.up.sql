@@ -0,0 +1,29 @@
+-- Reminder Rules table
+CREATE TABLE reminder_rules (
+    rule_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    days_offset INT NOT NULL,
+    tone VARCHAR(10) NOT NULL CHECK (tone IN ('friendly', 'firm', 'final')),
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(user_id),
+    UNIQUE (user_id, days_offset)
+);
+
+-- Invoice Reminders table
+CREATE TABLE invoice_reminders (
+    reminder_id UUID PRIMARY KEY,
+    invoice_id UUID NOT NULL,
+    rule_id UUID,
+    days_offset INT NOT NULL,
+    tone VARCHAR(10) NOT NULL,
+    sent_to VARCHAR(100) NOT NULL,
+    sent_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (invoice_id) REFERENCES invoices(invoice_id),
+    FOREIGN KEY (rule_id) REFERENCES reminder_rules(rule_id) ON DELETE SET NULL,
+    UNIQUE (invoice_id, days_offset)
+);
+
+CREATE INDEX idx_reminder_rules_user_id ON reminder_rules(user_id);
+CREATE INDEX idx_invoices_due_date ON invoices(due_date);
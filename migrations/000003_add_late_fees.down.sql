@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "invoice_adjustments";
+DROP TABLE IF EXISTS "late_fee_policies";
@@ -0,0 +1,30 @@
+-- Late Fee Policies table
+CREATE TABLE late_fee_policies (
+    policy_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL UNIQUE,
+    fee_type VARCHAR(20) NOT NULL CHECK (fee_type IN ('flat', 'daily_interest', 'monthly_interest')),
+    flat_amount NUMERIC(10, 2) DEFAULT 0,
+    interest_rate NUMERIC(6, 3) DEFAULT 0,
+    grace_days INT NOT NULL DEFAULT 0,
+    max_amount NUMERIC(10, 2),
+    max_percentage NUMERIC(5, 2),
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(user_id)
+);
+
+-- Invoice Adjustments table
+CREATE TABLE invoice_adjustments (
+    adjustment_id UUID PRIMARY KEY,
+    invoice_id UUID NOT NULL,
+    policy_id UUID,
+    adjustment_type VARCHAR(20) NOT NULL CHECK (adjustment_type IN ('late_fee', 'interest')),
+    amount NUMERIC(10, 2) NOT NULL,
+    description TEXT,
+    applied_on DATE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (invoice_id) REFERENCES invoices(invoice_id),
+    FOREIGN KEY (policy_id) REFERENCES late_fee_policies(policy_id) ON DELETE SET NULL,
+    UNIQUE (invoice_id, adjustment_type, applied_on)
+);
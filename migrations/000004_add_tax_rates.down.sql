@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "invoice_tax_lines";
+DROP TABLE IF EXISTS "invoice_item_taxes";
+DROP TABLE IF EXISTS "tax_rates";
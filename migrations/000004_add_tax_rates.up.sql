@@ -0,0 +1,53 @@
+-- Tax Rates table
+CREATE TABLE tax_rates (
+    tax_rate_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    rate NUMERIC(6, 3) NOT NULL CHECK (rate >= 0 AND rate <= 100),
+    is_inclusive BOOLEAN NOT NULL DEFAULT false,
+    is_compound BOOLEAN NOT NULL DEFAULT false,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(user_id),
+    CHECK (NOT (is_inclusive AND is_compound))
+);
+
+-- Invoice Item Taxes table
+CREATE TABLE invoice_item_taxes (
+    item_tax_id UUID PRIMARY KEY,
+    item_id UUID NOT NULL,
+    tax_rate_id UUID NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    rate NUMERIC(6, 3) NOT NULL,
+    is_inclusive BOOLEAN NOT NULL,
+    is_compound BOOLEAN NOT NULL,
+    taxable_amount NUMERIC(10, 2) NOT NULL,
+    tax_amount NUMERIC(10, 2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (item_id) REFERENCES invoice_items(item_id),
+    FOREIGN KEY (tax_rate_id) REFERENCES tax_rates(tax_rate_id),
+    UNIQUE (item_id, tax_rate_id)
+);
+
+-- Invoice Tax Lines table
+CREATE TABLE invoice_tax_lines (
+    tax_line_id UUID PRIMARY KEY,
+    invoice_id UUID NOT NULL,
+    tax_rate_id UUID NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    rate NUMERIC(6, 3) NOT NULL,
+    is_inclusive BOOLEAN NOT NULL,
+    is_compound BOOLEAN NOT NULL,
+    taxable_amount NUMERIC(10, 2) NOT NULL,
+    tax_amount NUMERIC(10, 2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (invoice_id) REFERENCES invoices(invoice_id),
+    FOREIGN KEY (tax_rate_id) REFERENCES tax_rates(tax_rate_id),
+    UNIQUE (invoice_id, tax_rate_id)
+);
+
+-- Active tax rate names are unique per user; deactivated rates keep their name for past invoices
+CREATE UNIQUE INDEX idx_tax_rates_user_id_name ON tax_rates(user_id, name) WHERE is_active;
+CREATE INDEX idx_invoice_item_taxes_item_id ON invoice_item_taxes(item_id);
+CREATE INDEX idx_invoice_tax_lines_invoice_id ON invoice_tax_lines(invoice_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "idx_invoices_issue_date";
+DROP TABLE IF EXISTS "payments";
@@ -0,0 +1,14 @@
+-- Payments table
+CREATE TABLE payments (
+    payment_id UUID PRIMARY KEY,
+    invoice_id UUID NOT NULL,
+    amount NUMERIC(10, 2) NOT NULL CHECK (amount > 0),
+    paid_on DATE NOT NULL,
+    reference VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (invoice_id) REFERENCES invoices(invoice_id)
+);
+
+CREATE INDEX idx_payments_invoice_id ON payments(invoice_id);
+CREATE INDEX idx_payments_paid_on ON payments(paid_on);
+CREATE INDEX idx_invoices_issue_date ON invoices(issue_date);
@@ -0,0 +1,4 @@
+ALTER TABLE invoices DROP COLUMN IF EXISTS exchange_rate;
+ALTER TABLE invoices DROP COLUMN IF EXISTS base_currency;
+ALTER TABLE users DROP COLUMN IF EXISTS base_currency;
+DROP TABLE IF EXISTS "exchange_rates";
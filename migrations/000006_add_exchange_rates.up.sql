@@ -0,0 +1,26 @@
+-- Exchange Rates table
+CREATE TABLE exchange_rates (
+    from_currency VARCHAR(3) NOT NULL,
+    to_currency VARCHAR(3) NOT NULL,
+    rate NUMERIC(18, 8) NOT NULL CHECK (rate > 0),
+    effective_date DATE NOT NULL,
+    source VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (from_currency, to_currency, effective_date),
+    CHECK (from_currency <> to_currency)
+);
+
+-- Base currency of each user, in which their reports are converted
+ALTER TABLE users ADD COLUMN base_currency VARCHAR(3) NOT NULL DEFAULT 'NGN';
+
+-- Exchange rate from the invoice currency to the sender's base currency, locked when the invoice is issued
+ALTER TABLE invoices ADD COLUMN base_currency VARCHAR(3);
+ALTER TABLE invoices ADD COLUMN exchange_rate NUMERIC(18, 8) CHECK (exchange_rate > 0);
+
+UPDATE invoices i
+SET base_currency = u.base_currency,
+    exchange_rate = CASE WHEN i.currency = u.base_currency THEN 1 END
+FROM users u
+WHERE u.user_id = i.sender_id;
+
+ALTER TABLE invoices ALTER COLUMN base_currency SET NOT NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "idx_payments_paid_on";
+CREATE INDEX idx_payments_paid_on ON payments(paid_on);
+DROP INDEX IF EXISTS "idx_invoices_sender_id_issue_date";
@@ -0,0 +1,7 @@
+-- Covering index for the revenue report, which sums a sender's invoices over an issue date range
+CREATE INDEX idx_invoices_sender_id_issue_date ON invoices(sender_id, issue_date)
+    INCLUDE (customer_id, status, currency, base_currency, exchange_rate, final_amount);
+
+-- Covering index for the cash flow report, which sums the payments received over a date range
+DROP INDEX IF EXISTS "idx_payments_paid_on";
+CREATE INDEX idx_payments_paid_on ON payments(paid_on) INCLUDE (invoice_id, amount);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "idx_invoices_sender_id_invoice_number";
+DROP INDEX IF EXISTS "idx_invoice_items_name_trgm";
+DROP INDEX IF EXISTS "idx_invoices_notes_trgm";
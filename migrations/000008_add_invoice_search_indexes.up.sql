@@ -0,0 +1,7 @@
+-- Trigram indexes for the case-insensitive free text search over invoice notes and item names
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX idx_invoices_notes_trgm ON invoices USING GIN (notes gin_trgm_ops);
+CREATE INDEX idx_invoice_items_name_trgm ON invoice_items USING GIN (name gin_trgm_ops);
+
+-- Index for searching a sender's invoices by invoice number prefix
+CREATE INDEX idx_invoices_sender_id_invoice_number ON invoices(sender_id, invoice_number text_pattern_ops);
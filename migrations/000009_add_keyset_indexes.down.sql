@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "idx_invoice_activities_invoice_id_created_at";
+DROP INDEX IF EXISTS "idx_recent_activities_user_id_created_at";
+DROP INDEX IF EXISTS "idx_invoices_sender_id_created_at";
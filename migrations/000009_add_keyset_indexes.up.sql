@@ -0,0 +1,4 @@
+-- Indexes matching the sort keys of the paginated lists, so a page after a cursor is read without scanning the previous pages
+CREATE INDEX idx_invoices_sender_id_created_at ON invoices(sender_id, created_at, invoice_id);
+CREATE INDEX idx_recent_activities_user_id_created_at ON recent_activities(user_id, created_at, activity_id);
+CREATE INDEX idx_invoice_activities_invoice_id_created_at ON invoice_activities(invoice_id, user_id, created_at, activity_id);
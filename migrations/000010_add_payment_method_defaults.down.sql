@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "idx_user_payment_methods_default";
+ALTER TABLE user_payment_methods ALTER COLUMN is_default DROP NOT NULL;
+ALTER TABLE user_payment_methods DROP COLUMN IF EXISTS deleted_at;
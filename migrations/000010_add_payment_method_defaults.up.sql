@@ -0,0 +1,13 @@
+ALTER TABLE user_payment_methods ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+UPDATE user_payment_methods SET is_default = false WHERE is_default IS NULL;
+ALTER TABLE user_payment_methods ALTER COLUMN is_default SET NOT NULL;
+
+-- Payment methods were added without a default, so the oldest method of every user becomes it
+UPDATE user_payment_methods SET is_default = payment_method_id IN (
+    SELECT DISTINCT ON (user_id) payment_method_id
+    FROM user_payment_methods
+    ORDER BY user_id, is_default DESC, created_at, payment_method_id
+);
+
+-- Every user has at most one default payment method; deleted methods are never the default
+CREATE UNIQUE INDEX idx_user_payment_methods_default ON user_payment_methods(user_id) WHERE is_default;
@@ -0,0 +1,7 @@
+-- Fails while account numbers are only stored encrypted, since they cannot be decrypted here
+ALTER TABLE user_payment_methods ALTER COLUMN account_number SET NOT NULL;
+ALTER TABLE user_payment_methods
+    DROP COLUMN IF EXISTS account_number_last4,
+    DROP COLUMN IF EXISTS account_number_key_id,
+    DROP COLUMN IF EXISTS account_number_data_key,
+    DROP COLUMN IF EXISTS account_number_ciphertext;
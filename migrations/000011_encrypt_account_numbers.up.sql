@@ -0,0 +1,11 @@
+-- Account numbers are encrypted with a data key of their own, stored encrypted with the master key named by account_number_key_id
+ALTER TABLE user_payment_methods
+    ADD COLUMN account_number_ciphertext BYTEA,
+    ADD COLUMN account_number_data_key BYTEA,
+    ADD COLUMN account_number_key_id VARCHAR(50),
+    ADD COLUMN account_number_last4 VARCHAR(4);
+
+-- Account numbers stored before keep their plaintext until the rotate-keys command encrypts them
+ALTER TABLE user_payment_methods ALTER COLUMN account_number DROP NOT NULL;
+UPDATE user_payment_methods SET account_number_last4 = RIGHT(account_number, 4);
+ALTER TABLE user_payment_methods ALTER COLUMN account_number_last4 SET NOT NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE user_payment_methods
+    DROP COLUMN IF EXISTS sort_code,
+    DROP COLUMN IF EXISTS routing_number,
+    DROP COLUMN IF EXISTS account_type;
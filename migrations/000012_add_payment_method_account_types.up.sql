@@ -0,0 +1,5 @@
+-- The account type decides how the account number is validated; us accounts have a routing number and uk accounts a sort code
+ALTER TABLE user_payment_methods
+    ADD COLUMN account_type VARCHAR(20) NOT NULL DEFAULT 'other',
+    ADD COLUMN routing_number VARCHAR(9),
+    ADD COLUMN sort_code VARCHAR(6);
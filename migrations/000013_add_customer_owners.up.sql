@@ -0,0 +1,9 @@
+-- Customers belong to the user who added them, and invoices can only be sent to the sender's own customers
+ALTER TABLE customers ADD COLUMN user_id UUID REFERENCES users(user_id);
+
+-- Customers added before are owned by the sender of their first invoice; customers never invoiced are left without an owner
+UPDATE customers c SET user_id = (
+    SELECT i.sender_id FROM invoices i WHERE i.customer_id = c.customer_id ORDER BY i.created_at, i.invoice_id LIMIT 1
+);
+
+CREATE INDEX idx_customers_user_id ON customers(user_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "idx_payment_sessions_invoice_id";
+DROP TABLE IF EXISTS "payment_sessions";
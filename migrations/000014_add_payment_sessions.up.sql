@@ -0,0 +1,21 @@
+-- Hosted checkout sessions of a payment provider, which customers pay invoices online with
+CREATE TABLE payment_sessions (
+    payment_session_id UUID PRIMARY KEY,
+    invoice_id UUID NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    provider_session_id VARCHAR(255) NOT NULL,
+    url TEXT NOT NULL,
+    amount NUMERIC(10, 2) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    -- the payment recorded once the session is paid
+    payment_id UUID,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (invoice_id) REFERENCES invoices(invoice_id),
+    FOREIGN KEY (payment_id) REFERENCES payments(payment_id),
+    UNIQUE (provider, provider_session_id)
+);
+
+CREATE INDEX idx_payment_sessions_invoice_id ON payment_sessions(invoice_id);
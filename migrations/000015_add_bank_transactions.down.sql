@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "idx_bank_transactions_user_id_status";
+DROP TABLE IF EXISTS "bank_transactions";
@@ -0,0 +1,24 @@
+-- Incoming credits of imported bank statements, reconciled against the invoices they pay
+CREATE TABLE bank_transactions (
+    bank_transaction_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    -- the bank's id of the transaction, or a fingerprint of it, so statements can be imported again
+    external_id VARCHAR(255) NOT NULL,
+    booking_date DATE NOT NULL,
+    amount NUMERIC(10, 2) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    counterparty_name TEXT NOT NULL DEFAULT '',
+    remittance_info TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    invoice_id UUID,
+    payment_id UUID,
+    suggested_invoice_ids UUID[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(user_id),
+    FOREIGN KEY (invoice_id) REFERENCES invoices(invoice_id),
+    FOREIGN KEY (payment_id) REFERENCES payments(payment_id),
+    UNIQUE (user_id, external_id)
+);
+
+CREATE INDEX idx_bank_transactions_user_id_status ON bank_transactions(user_id, status);
@@ -0,0 +1,3 @@
+ALTER TABLE invoice_activities DROP CONSTRAINT IF EXISTS invoice_activities_event_type_check;
+ALTER TABLE invoice_activities DROP COLUMN IF EXISTS payload;
+ALTER TABLE invoice_activities DROP COLUMN IF EXISTS event_type;
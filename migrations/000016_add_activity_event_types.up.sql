@@ -0,0 +1,20 @@
+-- Type of event of each invoice activity: comments written by users, and events recorded by the system with a
+-- payload describing them
+ALTER TABLE invoice_activities ADD COLUMN event_type VARCHAR(30) NOT NULL DEFAULT 'comment';
+ALTER TABLE invoice_activities ADD COLUMN payload JSONB NOT NULL DEFAULT '{}';
+
+-- Activities recorded by the system before event types were introduced are known by their title, and keep an empty
+-- payload
+UPDATE invoice_activities
+SET event_type = CASE title
+    WHEN 'Invoice Creation' THEN 'invoice_created'
+    WHEN 'Payment Received' THEN 'payment_recorded'
+    WHEN 'Payment Reminder' THEN 'email_sent'
+    WHEN 'Late Fee Applied' THEN 'late_fee_applied'
+    ELSE 'comment'
+END;
+
+ALTER TABLE invoice_activities ALTER COLUMN event_type DROP DEFAULT;
+ALTER TABLE invoice_activities ADD CONSTRAINT invoice_activities_event_type_check CHECK (event_type IN (
+    'comment', 'invoice_created', 'status_changed', 'payment_recorded', 'email_sent', 'late_fee_applied'
+));
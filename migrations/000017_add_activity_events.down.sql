@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS invoice_activities (
+    activity_id UUID PRIMARY KEY,
+    invoice_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    title VARCHAR(100) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    event_type VARCHAR(30) NOT NULL CONSTRAINT invoice_activities_event_type_check CHECK (event_type IN (
+        'comment', 'invoice_created', 'status_changed', 'payment_recorded', 'email_sent', 'late_fee_applied'
+    )),
+    payload JSONB NOT NULL DEFAULT '{}',
+    FOREIGN KEY (invoice_id) REFERENCES invoices(invoice_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id)
+);
+
+CREATE TABLE IF NOT EXISTS recent_activities (
+    activity_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    title VARCHAR(100) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoice_activities_invoice_id ON invoice_activities(invoice_id);
+CREATE INDEX IF NOT EXISTS idx_invoice_activities_user_id ON invoice_activities(user_id);
+CREATE INDEX IF NOT EXISTS idx_invoice_activities_invoice_id_created_at ON invoice_activities(invoice_id, user_id, created_at, activity_id);
+CREATE INDEX IF NOT EXISTS idx_recent_activities_user_id ON recent_activities(user_id);
+CREATE INDEX IF NOT EXISTS idx_recent_activities_user_id_created_at ON recent_activities(user_id, created_at, activity_id);
+
+INSERT INTO invoice_activities (activity_id, invoice_id, user_id, title, description, created_at, event_type, payload)
+SELECT activity_id, invoice_id, user_id, title, description, created_at, event_type, payload
+FROM activity_events
+WHERE invoice_id IS NOT NULL;
+
+INSERT INTO recent_activities (activity_id, user_id, title, description, created_at)
+SELECT activity_id, user_id, title, description, created_at
+FROM activity_events;
+
+DROP TRIGGER IF EXISTS activity_events_append_only ON activity_events;
+DROP FUNCTION IF EXISTS reject_activity_event_changes();
+DROP INDEX IF EXISTS "idx_activity_events_user_id_created_at";
+DROP INDEX IF EXISTS "idx_activity_events_invoice_id_created_at";
+DROP TABLE IF EXISTS "activity_events";
@@ -0,0 +1,52 @@
+-- Append-only log of the events of every invoice, which serves both the timeline of each invoice and the recent
+-- activity feed of each user, replacing invoice_activities and recent_activities
+CREATE TABLE activity_events (
+    activity_id UUID PRIMARY KEY,
+    -- NULL for feed entries recorded without an invoice before the two tables were merged
+    invoice_id UUID,
+    user_id UUID NOT NULL,
+    event_type VARCHAR(30) NOT NULL CHECK (event_type IN (
+        'comment', 'invoice_created', 'status_changed', 'payment_recorded', 'email_sent', 'late_fee_applied'
+    )),
+    payload JSONB NOT NULL DEFAULT '{}',
+    title VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (invoice_id) REFERENCES invoices(invoice_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id)
+);
+
+CREATE INDEX idx_activity_events_invoice_id_created_at ON activity_events(invoice_id, user_id, created_at, activity_id);
+CREATE INDEX idx_activity_events_user_id_created_at ON activity_events(user_id, created_at, activity_id);
+
+CREATE FUNCTION reject_activity_event_changes() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'activity events are append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER activity_events_append_only
+    BEFORE UPDATE OR DELETE ON activity_events
+    FOR EACH ROW EXECUTE FUNCTION reject_activity_event_changes();
+
+-- Activities recorded in both tables share their id, so each is merged once
+INSERT INTO activity_events (activity_id, invoice_id, user_id, event_type, payload, title, description, created_at)
+SELECT activity_id, invoice_id, user_id, event_type, payload, title, COALESCE(description, ''),
+       COALESCE(created_at, CURRENT_TIMESTAMP)
+FROM invoice_activities;
+
+INSERT INTO activity_events (activity_id, user_id, event_type, title, description, created_at)
+SELECT r.activity_id, r.user_id,
+       CASE r.title
+           WHEN 'Invoice Creation' THEN 'invoice_created'
+           WHEN 'Payment Received' THEN 'payment_recorded'
+           WHEN 'Payment Reminder' THEN 'email_sent'
+           WHEN 'Late Fee Applied' THEN 'late_fee_applied'
+           ELSE 'comment'
+       END,
+       r.title, COALESCE(r.description, ''), COALESCE(r.created_at, CURRENT_TIMESTAMP)
+FROM recent_activities r
+WHERE NOT EXISTS (SELECT 1 FROM activity_events e WHERE e.activity_id = r.activity_id);
+
+DROP TABLE invoice_activities;
+DROP TABLE recent_activities;
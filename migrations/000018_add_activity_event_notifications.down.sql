@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS activity_events_notify ON activity_events;
+DROP FUNCTION IF EXISTS notify_activity_event();
+DROP INDEX IF EXISTS "idx_activity_events_user_id_sequence";
+ALTER TABLE activity_events DROP CONSTRAINT IF EXISTS activity_events_sequence_key;
+ALTER TABLE activity_events DROP COLUMN IF EXISTS sequence;
@@ -0,0 +1,18 @@
+-- Position of each event in the log, which clients of the event stream resume from
+ALTER TABLE activity_events ADD COLUMN sequence BIGINT GENERATED ALWAYS AS IDENTITY;
+ALTER TABLE activity_events ADD CONSTRAINT activity_events_sequence_key UNIQUE (sequence);
+
+CREATE INDEX idx_activity_events_user_id_sequence ON activity_events(user_id, sequence);
+
+-- Every server listening on the activity_events channel is told of new events once they are committed, so the event
+-- stream works whichever server an event was recorded on
+CREATE FUNCTION notify_activity_event() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('activity_events', json_build_object('user_id', NEW.user_id, 'sequence', NEW.sequence)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER activity_events_notify
+    AFTER INSERT ON activity_events
+    FOR EACH ROW EXECUTE FUNCTION notify_activity_event();
@@ -0,0 +1,46 @@
+-- Webhooks deliver the invoice lifecycle events of a user to a URL. The secret signing the deliveries is encrypted like
+-- account numbers, and last_sequence is the last event of the log considered for delivery
+CREATE TABLE webhooks (
+    webhook_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    url TEXT NOT NULL,
+    event_types TEXT[] NOT NULL,
+    secret_ciphertext BYTEA NOT NULL,
+    secret_data_key BYTEA NOT NULL,
+    secret_key_id VARCHAR(50) NOT NULL,
+    last_sequence BIGINT NOT NULL DEFAULT 0,
+    -- deliveries that failed for good since the last successful one; the webhook is disabled once there are too many
+    failure_count INT NOT NULL DEFAULT 0,
+    disabled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(user_id)
+);
+
+CREATE INDEX idx_webhooks_user_id ON webhooks(user_id);
+
+-- Every event sent to a webhook, with the outcome of its last attempt. Pending deliveries are sent once
+-- next_attempt_at is reached
+CREATE TABLE webhook_deliveries (
+    delivery_id UUID PRIMARY KEY,
+    webhook_id UUID NOT NULL,
+    -- the activity the event was recorded as
+    event_id UUID NOT NULL,
+    event_type VARCHAR(30) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE,
+    response_status INT,
+    last_error TEXT NOT NULL DEFAULT '',
+    replay_of UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    FOREIGN KEY (webhook_id) REFERENCES webhooks(webhook_id) ON DELETE CASCADE,
+    FOREIGN KEY (replay_of) REFERENCES webhook_deliveries(delivery_id) ON DELETE SET NULL
+);
+
+-- each event is queued once per webhook, however many servers queue it, and replayed any number of times
+CREATE UNIQUE INDEX idx_webhook_deliveries_webhook_id_event_id ON webhook_deliveries(webhook_id, event_id) WHERE replay_of IS NULL;
+CREATE INDEX idx_webhook_deliveries_webhook_id_created_at ON webhook_deliveries(webhook_id, created_at, delivery_id);
+CREATE INDEX idx_webhook_deliveries_next_attempt_at ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
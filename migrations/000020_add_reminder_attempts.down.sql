@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "idx_invoice_reminders_next_attempt_at";
+DELETE FROM invoice_reminders WHERE status <> 'sent';
+ALTER TABLE invoice_reminders ALTER COLUMN sent_at SET DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE invoice_reminders DROP COLUMN IF EXISTS last_error;
+ALTER TABLE invoice_reminders DROP COLUMN IF EXISTS next_attempt_at;
+ALTER TABLE invoice_reminders DROP COLUMN IF EXISTS attempts;
+ALTER TABLE invoice_reminders DROP COLUMN IF EXISTS status;
@@ -0,0 +1,14 @@
+-- Reminders are claimed before they are sent, so a reminder is sent by a single server: a pending reminder waits for
+-- next_attempt_at, which is pushed back while a server sends it and after each failed attempt
+ALTER TABLE invoice_reminders ADD COLUMN status VARCHAR(10) NOT NULL DEFAULT 'sent'
+    CHECK (status IN ('pending', 'sent', 'failed'));
+ALTER TABLE invoice_reminders ADD COLUMN attempts INT NOT NULL DEFAULT 1;
+ALTER TABLE invoice_reminders ADD COLUMN next_attempt_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE invoice_reminders ADD COLUMN last_error TEXT NOT NULL DEFAULT '';
+
+-- pending reminders are not sent yet
+ALTER TABLE invoice_reminders ALTER COLUMN sent_at DROP DEFAULT;
+ALTER TABLE invoice_reminders ALTER COLUMN status SET DEFAULT 'pending';
+ALTER TABLE invoice_reminders ALTER COLUMN attempts SET DEFAULT 0;
+
+CREATE INDEX idx_invoice_reminders_next_attempt_at ON invoice_reminders(next_attempt_at) WHERE status = 'pending';
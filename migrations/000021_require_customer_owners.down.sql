@@ -0,0 +1,6 @@
+ALTER TABLE customers ALTER COLUMN user_id DROP NOT NULL;
+
+INSERT INTO customers (customer_id, name, email, phone_number, address, created_at, updated_at, user_id)
+SELECT customer_id, name, email, phone_number, address, created_at, updated_at, user_id FROM unowned_customers;
+
+DROP TABLE IF EXISTS "unowned_customers";
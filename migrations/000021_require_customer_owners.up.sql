@@ -0,0 +1,23 @@
+-- Customers never invoiced before customers had owners were left without one, so that nobody can invoice them.
+-- Any of them invoiced since is owned by the sender of its first invoice, like the customers of migration 000013
+UPDATE customers c SET user_id = (
+    SELECT i.sender_id FROM invoices i WHERE i.customer_id = c.customer_id ORDER BY i.created_at, i.invoice_id LIMIT 1
+)
+WHERE c.user_id IS NULL;
+
+-- the rest are moved to an archive, for operators to give each of them an owner and move it back:
+--   INSERT INTO customers (customer_id, name, email, phone_number, address, created_at, updated_at, user_id)
+--   SELECT customer_id, name, email, phone_number, address, created_at, updated_at, '<owner user_id>'
+--   FROM unowned_customers WHERE customer_id = '<customer_id>';
+--   DELETE FROM unowned_customers WHERE customer_id = '<customer_id>';
+CREATE TABLE unowned_customers (LIKE customers INCLUDING DEFAULTS);
+ALTER TABLE unowned_customers ADD PRIMARY KEY (customer_id);
+ALTER TABLE unowned_customers ADD COLUMN archived_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+WITH archived AS (
+    DELETE FROM customers WHERE user_id IS NULL RETURNING *
+)
+INSERT INTO unowned_customers (customer_id, name, email, phone_number, address, created_at, updated_at, user_id)
+SELECT customer_id, name, email, phone_number, address, created_at, updated_at, user_id FROM archived;
+
+ALTER TABLE customers ALTER COLUMN user_id SET NOT NULL;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS "idx_bank_transactions_user_id_status_booking_date";
@@ -0,0 +1,2 @@
+-- Index matching the sort key of the reconciliation inbox, so a page after a cursor is read without scanning the previous pages
+CREATE INDEX idx_bank_transactions_user_id_status_booking_date ON bank_transactions(user_id, status, booking_date, bank_transaction_id);
@@ -0,0 +1,9 @@
+CREATE OR REPLACE FUNCTION notify_activity_event() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('activity_events', json_build_object('user_id', NEW.user_id, 'sequence', NEW.sequence)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS "idx_activity_events_user_id_xid";
+ALTER TABLE activity_events DROP COLUMN IF EXISTS xid;
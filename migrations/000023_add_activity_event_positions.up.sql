@@ -0,0 +1,18 @@
+-- Transaction that recorded each event. Sequence numbers are taken when events are inserted, not when they commit, so
+-- an event can become visible after events with higher sequence numbers; streams resume from transaction IDs instead
+ALTER TABLE activity_events ADD COLUMN xid xid8 NOT NULL DEFAULT pg_current_xact_id();
+
+CREATE INDEX idx_activity_events_user_id_xid ON activity_events(user_id, xid, sequence);
+
+-- Notifications also carry the position of the event in the stream: every transaction older than it had finished
+-- when the event was recorded, so its events were notified before this one
+CREATE OR REPLACE FUNCTION notify_activity_event() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('activity_events', json_build_object(
+        'user_id', NEW.user_id,
+        'sequence', NEW.sequence,
+        'position', pg_snapshot_xmin(pg_current_snapshot())::text::bigint
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
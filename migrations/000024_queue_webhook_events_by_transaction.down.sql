@@ -0,0 +1,7 @@
+UPDATE webhooks SET last_sequence = (
+    SELECT COALESCE(MAX(sequence), 0)
+    FROM activity_events
+    WHERE (xid, sequence) <= (webhooks.last_xid, webhooks.last_sequence)
+);
+
+ALTER TABLE webhooks DROP COLUMN IF EXISTS last_xid;
@@ -0,0 +1,13 @@
+-- Webhooks move past the events of the log in the order of the transactions that recorded them, and only past the
+-- transactions that finished, so an event committed after events with higher sequence numbers is not passed over.
+-- last_sequence is now the last event considered within the last_xid transaction
+ALTER TABLE webhooks ADD COLUMN last_xid xid8;
+
+-- the events after the last sequence considered are considered again, and those already queued are skipped by their
+-- activity ID
+UPDATE webhooks SET
+    last_xid = COALESCE((SELECT MIN(xid) FROM activity_events WHERE sequence > webhooks.last_sequence),
+                        pg_snapshot_xmin(pg_current_snapshot())),
+    last_sequence = 0;
+
+ALTER TABLE webhooks ALTER COLUMN last_xid SET NOT NULL;
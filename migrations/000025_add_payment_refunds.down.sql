@@ -0,0 +1,19 @@
+-- the log is append-only, so the activities of refunds are removed with its trigger disabled
+ALTER TABLE activity_events DISABLE TRIGGER activity_events_append_only;
+DELETE FROM activity_events WHERE event_type = 'payment_refunded';
+ALTER TABLE activity_events ENABLE TRIGGER activity_events_append_only;
+
+ALTER TABLE activity_events DROP CONSTRAINT IF EXISTS activity_events_event_type_check;
+ALTER TABLE activity_events ADD CONSTRAINT activity_events_event_type_check CHECK (event_type IN (
+    'comment', 'invoice_created', 'status_changed', 'payment_recorded', 'email_sent', 'late_fee_applied'
+));
+
+DELETE FROM payments WHERE refunded_payment_id IS NOT NULL;
+DROP INDEX IF EXISTS "idx_payments_refunded_payment_id";
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_amount_check;
+ALTER TABLE payments DROP COLUMN IF EXISTS refunded_payment_id;
+ALTER TABLE payments ADD CONSTRAINT payments_amount_check CHECK (amount > 0);
+
+DROP INDEX IF EXISTS "idx_payment_refunds_status_next_attempt_at";
+DROP INDEX IF EXISTS "idx_payment_refunds_payment_id";
+DROP TABLE IF EXISTS "payment_refunds";
@@ -0,0 +1,41 @@
+-- Refunds of payments made online. A refund is recorded as pending before it is sent to the payment provider, with
+-- its ID as the idempotency key, so a refund interrupted before it was recorded as completed or failed is sent again
+-- without returning the money twice
+CREATE TABLE payment_refunds (
+    refund_id UUID PRIMARY KEY,
+    payment_id UUID NOT NULL,
+    invoice_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    payment_reference VARCHAR(100) NOT NULL,
+    amount NUMERIC(10, 2) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    provider_refund_id VARCHAR(255),
+    failure_reason TEXT,
+    -- pending refunds are sent again from this time on, unless they are completed or failed by then
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (payment_id) REFERENCES payments(payment_id),
+    FOREIGN KEY (invoice_id) REFERENCES invoices(invoice_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id)
+);
+
+CREATE INDEX idx_payment_refunds_payment_id ON payment_refunds(payment_id);
+CREATE INDEX idx_payment_refunds_status_next_attempt_at ON payment_refunds(status, next_attempt_at);
+
+-- Completed refunds are recorded as payments of a negative amount, on the date they were made, so the balances, aging
+-- and cash flow summed from the payments account for the money returned to the customer
+ALTER TABLE payments ADD COLUMN refunded_payment_id UUID REFERENCES payments(payment_id);
+ALTER TABLE payments DROP CONSTRAINT payments_amount_check;
+ALTER TABLE payments ADD CONSTRAINT payments_amount_check
+    CHECK ((refunded_payment_id IS NULL AND amount > 0) OR (refunded_payment_id IS NOT NULL AND amount < 0));
+
+CREATE INDEX idx_payments_refunded_payment_id ON payments(refunded_payment_id);
+
+ALTER TABLE activity_events DROP CONSTRAINT activity_events_event_type_check;
+ALTER TABLE activity_events ADD CONSTRAINT activity_events_event_type_check CHECK (event_type IN (
+    'comment', 'invoice_created', 'status_changed', 'payment_recorded', 'payment_refunded', 'email_sent',
+    'late_fee_applied'
+));